@@ -0,0 +1,319 @@
+// Package bridge mirrors subjects from one MiniToolStream deployment to
+// another. Messages are read from the source Egress and republished on the
+// destination Ingress with their data and headers.
+//
+// Mirrored messages carry the name of the deployment they were first
+// published on and the number of bridges they passed. A bridge never sends a
+// message back to its origin and drops messages that exceeded MaxHops, so
+// bridges may be set up in both directions without messages looping.
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	publisherUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+	subscriberUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/subscriber"
+)
+
+// Headers attached to mirrored messages
+const (
+	// HeaderOrigin names the deployment a message was first published on
+	HeaderOrigin = "bridge-origin"
+	// HeaderHops counts the bridges a message passed
+	HeaderHops = "bridge-hops"
+	// HeaderOriginSequence carries the sequence of the message on its origin
+	HeaderOriginSequence = "bridge-origin-sequence"
+)
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// Config represents bridge configuration
+type Config struct {
+	// Source is the client subjects are read from
+	Source domain.EgressClient
+	// Destination is the client subjects are mirrored to
+	Destination domain.IngressClient
+	// Subjects lists the subjects to mirror
+	Subjects []string
+
+	// SourceName and DestinationName identify the two deployments in the
+	// loop-prevention headers; they are required and must differ
+	SourceName      string
+	DestinationName string
+
+	// DurableName is the consumer on the source (default
+	// "bridge-<source>-to-<destination>"), so a restarted bridge resumes
+	DurableName string
+	// StartPosition overrides where mirroring starts; by default the durable
+	// consumer resumes where it left off
+	StartPosition domain.StartPosition
+	// BatchSize is the source fetch batch size (default 100)
+	BatchSize int32
+	// MaxHops drops messages that passed this many bridges (default 8)
+	MaxHops int
+	// MaxRetries is the number of additional publish attempts on the destination
+	MaxRetries int
+	// RetryInterval is the pause before a batch that failed to mirror is
+	// fetched again (default 1s)
+	RetryInterval time.Duration
+
+	Clock  domain.Clock
+	Logger Logger
+}
+
+// SubjectStats is the mirroring progress of one subject
+type SubjectStats struct {
+	Subject string
+	// Mirrored is the number of messages published on the destination
+	Mirrored int64
+	// Skipped is the number of messages not mirrored to prevent a loop
+	Skipped int64
+	// LastSequence is the source sequence of the last handled message
+	LastSequence uint64
+	// Lag is the number of source messages after the last handled one; it
+	// stays 0 until the first message is handled
+	Lag uint64
+	// Delay is how long the last handled message took to be mirrored
+	Delay time.Duration
+}
+
+// Bridge mirrors subjects from a source to a destination deployment
+type Bridge struct {
+	source      domain.EgressClient
+	subjects    []string
+	origin      string
+	destination string
+	maxHops     int
+	clock       domain.Clock
+	logger      Logger
+
+	pub *publisherUsecase.SimplePublisher
+	sub *subscriberUsecase.MultiSubject
+
+	mu    sync.Mutex
+	stats map[string]*SubjectStats
+}
+
+// New creates a new bridge
+func New(config *Config) (*Bridge, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Source == nil || config.Destination == nil {
+		return nil, fmt.Errorf("source and destination clients cannot be nil")
+	}
+
+	if len(config.Subjects) == 0 {
+		return nil, fmt.Errorf("at least one subject is required")
+	}
+
+	if config.SourceName == "" || config.DestinationName == "" {
+		return nil, fmt.Errorf("source and destination names are required")
+	}
+
+	if config.SourceName == config.DestinationName {
+		return nil, fmt.Errorf("source and destination names must differ, got %q", config.SourceName)
+	}
+
+	if config.MaxHops < 0 || config.MaxRetries < 0 {
+		return nil, fmt.Errorf("max hops and max retries cannot be negative")
+	}
+
+	b := &Bridge{
+		source:      config.Source,
+		subjects:    append([]string(nil), config.Subjects...),
+		origin:      config.SourceName,
+		destination: config.DestinationName,
+		maxHops:     config.MaxHops,
+		clock:       domain.ClockOrSystem(config.Clock),
+		logger:      config.Logger,
+		stats:       make(map[string]*SubjectStats),
+	}
+
+	if b.maxHops == 0 {
+		b.maxHops = 8
+	}
+	if b.logger == nil {
+		b.logger = domain.DefaultLogger{}
+	}
+
+	durableName := config.DurableName
+	if durableName == "" {
+		durableName = "bridge-" + config.SourceName + "-to-" + config.DestinationName
+	}
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	retryInterval := config.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = time.Second
+	}
+
+	pub, err := publisherUsecase.New(&publisherUsecase.Config{
+		Client:     nopCloseIngress{config.Destination},
+		MaxRetries: config.MaxRetries,
+		Clock:      config.Clock,
+		Logger:     discardLogger{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create publisher: %w", err)
+	}
+
+	// Strict mode keeps a message that could not be mirrored from being
+	// acknowledged; its batch is fetched again after RetryInterval
+	sub, err := subscriberUsecase.New(&subscriberUsecase.Config{
+		Client:        nopCloseEgress{config.Source},
+		DurableName:   durableName,
+		BatchSize:     batchSize,
+		StartPosition: config.StartPosition,
+		StrictMode:    true,
+		RetryInterval: retryInterval,
+		Clock:         config.Clock,
+		Logger:        discardLogger{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscriber: %w", err)
+	}
+	sub.SetErrorHandler(func(subject string, msg *domain.ReceivedMessage, err error) {
+		b.logger.Printf("[%s] ✗ Failed to mirror message %d: %v", subject, msg.Sequence, err)
+	})
+
+	b.pub = pub
+	b.sub = sub
+	for _, subject := range b.subjects {
+		b.stats[subject] = &SubjectStats{Subject: subject}
+		sub.RegisterHandler(subject, domain.MessageHandlerFunc(b.mirror))
+	}
+
+	return b, nil
+}
+
+// Start starts mirroring. The clients are not closed by the bridge.
+func (b *Bridge) Start(ctx context.Context) error {
+	if err := b.sub.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start bridge: %w", err)
+	}
+	b.logger.Printf("✓ Bridge %s -> %s started for %d subjects", b.origin, b.destination, len(b.subjects))
+	return nil
+}
+
+// Stop stops mirroring without waiting for running publishes
+func (b *Bridge) Stop() {
+	b.sub.Stop()
+}
+
+// Drain stops fetching and waits until fetched messages are mirrored or ctx is done
+func (b *Bridge) Drain(ctx context.Context) error {
+	return b.sub.Drain(ctx)
+}
+
+// Wait blocks until the bridge stops
+func (b *Bridge) Wait() {
+	b.sub.Wait()
+}
+
+// mirror publishes one source message on the destination unless that would
+// send it around a loop
+func (b *Bridge) mirror(ctx context.Context, msg *domain.ReceivedMessage) error {
+	origin := msg.Headers[HeaderOrigin]
+	if origin == "" {
+		origin = b.origin
+	}
+	hops, _ := strconv.Atoi(msg.Headers[HeaderHops])
+
+	if origin == b.destination || hops >= b.maxHops {
+		b.record(msg, false)
+		return nil
+	}
+
+	headers := make(map[string]string, len(msg.Headers)+3)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[HeaderOrigin] = origin
+	headers[HeaderHops] = strconv.Itoa(hops + 1)
+	if _, ok := headers[HeaderOriginSequence]; !ok {
+		headers[HeaderOriginSequence] = strconv.FormatUint(msg.Sequence, 10)
+	}
+
+	err := b.pub.Publish(ctx, domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{Subject: msg.Subject, Data: msg.Data, Headers: headers}, nil
+	}))
+	if err != nil {
+		return err
+	}
+
+	b.record(msg, true)
+	return nil
+}
+
+// record updates the stats of the message's subject
+func (b *Bridge) record(msg *domain.ReceivedMessage, mirrored bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := b.stats[msg.Subject]
+	if mirrored {
+		stats.Mirrored++
+	} else {
+		stats.Skipped++
+	}
+	if msg.Sequence > stats.LastSequence {
+		stats.LastSequence = msg.Sequence
+	}
+	if !msg.Timestamp.IsZero() {
+		stats.Delay = b.clock.Now().Sub(msg.Timestamp)
+	}
+}
+
+// Stats returns the progress of every subject, sorted by subject. Lag is
+// computed from the last sequence on the source.
+func (b *Bridge) Stats(ctx context.Context) ([]SubjectStats, error) {
+	b.mu.Lock()
+	stats := make([]SubjectStats, 0, len(b.stats))
+	for _, s := range b.stats {
+		stats = append(stats, *s)
+	}
+	b.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Subject < stats[j].Subject })
+
+	for i := range stats {
+		last, err := b.source.GetLastSequence(ctx, stats[i].Subject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last sequence of %s: %w", stats[i].Subject, err)
+		}
+		if stats[i].LastSequence > 0 && last > stats[i].LastSequence {
+			stats[i].Lag = last - stats[i].LastSequence
+		}
+	}
+	return stats, nil
+}
+
+// discardLogger silences the publisher and subscriber, which log every message
+type discardLogger struct{}
+
+func (discardLogger) Printf(format string, v ...interface{}) {}
+
+// nopCloseIngress keeps the publisher from closing the caller's client
+type nopCloseIngress struct {
+	domain.IngressClient
+}
+
+func (nopCloseIngress) Close() error { return nil }
+
+// nopCloseEgress keeps the subscriber from closing the caller's client
+type nopCloseEgress struct {
+	domain.EgressClient
+}
+
+func (nopCloseEgress) Close() error { return nil }
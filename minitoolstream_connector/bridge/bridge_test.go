@@ -0,0 +1,236 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/localbroker"
+)
+
+type testLogger struct{}
+
+func (testLogger) Printf(format string, v ...interface{}) {}
+
+func newBroker(t *testing.T) *localbroker.Broker {
+	t.Helper()
+	b, err := localbroker.New(&localbroker.Config{Dir: t.TempDir(), PollInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	return b
+}
+
+func publish(t *testing.T, b *localbroker.Broker, subject, data string, headers map[string]string) {
+	t.Helper()
+	if _, err := b.Publish(context.Background(), &domain.PublishMessage{Subject: subject, Data: []byte(data), Headers: headers}); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+}
+
+// readers numbers the durable consumers readAll reads with
+var readers atomic.Int64
+
+// readAll returns every message of the subject
+func readAll(t *testing.T, b *localbroker.Broker, subject string) []*domain.ReceivedMessage {
+	t.Helper()
+	config := &domain.SubscriptionConfig{Subject: subject, DurableName: fmt.Sprintf("read-%d", readers.Add(1)), BatchSize: 1000}
+	stream, err := b.Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	var messages []*domain.ReceivedMessage
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return messages
+		}
+		messages = append(messages, msg)
+	}
+}
+
+func startBridge(t *testing.T, config *Config) *Bridge {
+	t.Helper()
+	config.Logger = testLogger{}
+	b, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create bridge: %v", err)
+	}
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start bridge: %v", err)
+	}
+	t.Cleanup(b.Stop)
+	return b
+}
+
+func waitForLast(t *testing.T, b *localbroker.Broker, subject string, want uint64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		last, _ := b.GetLastSequence(context.Background(), subject)
+		if last == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d messages on %s, got %d", want, subject, last)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// waitForStats polls the stats of the bridge's only subject until cond holds
+func waitForStats(t *testing.T, b *Bridge, cond func(s SubjectStats) bool) SubjectStats {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stats, err := b.Stats(context.Background())
+		if err != nil {
+			t.Fatalf("Stats failed: %v", err)
+		}
+		if cond(stats[0]) {
+			return stats[0]
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected stats: %+v", stats[0])
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	src, dst := newBroker(t), newBroker(t)
+	valid := func() *Config {
+		return &Config{Source: src, Destination: dst, Subjects: []string{"orders"}, SourceName: "eu", DestinationName: "us"}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string
+	}{
+		{"missing source", func(c *Config) { c.Source = nil }, "clients cannot be nil"},
+		{"no subjects", func(c *Config) { c.Subjects = nil }, "subject is required"},
+		{"missing name", func(c *Config) { c.DestinationName = "" }, "names are required"},
+		{"same names", func(c *Config) { c.DestinationName = "eu" }, "must differ"},
+		{"negative hops", func(c *Config) { c.MaxHops = -1 }, "cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid()
+			tt.modify(config)
+			_, err := New(config)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := New(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+	if _, err := New(valid()); err != nil {
+		t.Errorf("expected valid config, got %v", err)
+	}
+}
+
+func TestBridge_MirrorsSubjects(t *testing.T) {
+	src, dst := newBroker(t), newBroker(t)
+	publish(t, src, "orders", "one", map[string]string{"content-type": "text/plain"})
+	publish(t, src, "orders", "two", nil)
+	publish(t, src, "ignored", "x", nil)
+
+	b := startBridge(t, &Config{Source: src, Destination: dst, Subjects: []string{"orders"}, SourceName: "eu", DestinationName: "us"})
+	waitForLast(t, dst, "orders", 2)
+
+	messages := readAll(t, dst, "orders")
+	if string(messages[0].Data) != "one" || string(messages[1].Data) != "two" {
+		t.Fatalf("unexpected mirrored data: %q, %q", messages[0].Data, messages[1].Data)
+	}
+	headers := messages[0].Headers
+	if headers["content-type"] != "text/plain" {
+		t.Errorf("expected original headers to be kept, got %v", headers)
+	}
+	if headers[HeaderOrigin] != "eu" || headers[HeaderHops] != "1" || headers[HeaderOriginSequence] != "1" {
+		t.Errorf("unexpected bridge headers: %v", headers)
+	}
+	if last, _ := dst.GetLastSequence(context.Background(), "ignored"); last != 0 {
+		t.Errorf("expected unselected subject not to be mirrored, got %d messages", last)
+	}
+
+	publish(t, src, "orders", "three", nil)
+	waitForLast(t, dst, "orders", 3)
+
+	waitForStats(t, b, func(s SubjectStats) bool {
+		return s.Mirrored == 3 && s.LastSequence == 3 && s.Lag == 0
+	})
+}
+
+func TestBridge_BidirectionalDoesNotLoop(t *testing.T) {
+	eu, us := newBroker(t), newBroker(t)
+	subjects := []string{"orders"}
+
+	euToUS := startBridge(t, &Config{Source: eu, Destination: us, Subjects: subjects, SourceName: "eu", DestinationName: "us"})
+	usToEU := startBridge(t, &Config{Source: us, Destination: eu, Subjects: subjects, SourceName: "us", DestinationName: "eu"})
+
+	publish(t, eu, "orders", "from-eu", nil)
+	publish(t, us, "orders", "from-us", nil)
+
+	waitForLast(t, us, "orders", 2)
+	waitForLast(t, eu, "orders", 2)
+
+	// Both bridges see the other's copy and must skip it
+	for _, b := range []*Bridge{euToUS, usToEU} {
+		waitForStats(t, b, func(s SubjectStats) bool { return s.Mirrored == 1 && s.Skipped == 1 })
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	for _, broker := range []*localbroker.Broker{eu, us} {
+		if last, _ := broker.GetLastSequence(context.Background(), "orders"); last != 2 {
+			t.Errorf("expected no message to loop, got %d messages", last)
+		}
+	}
+}
+
+func TestBridge_MaxHops(t *testing.T) {
+	src, dst := newBroker(t), newBroker(t)
+	publish(t, src, "orders", "far", map[string]string{HeaderOrigin: "apac", HeaderHops: "2"})
+	publish(t, src, "orders", "near", map[string]string{HeaderOrigin: "apac", HeaderHops: "1"})
+
+	b := startBridge(t, &Config{Source: src, Destination: dst, Subjects: []string{"orders"}, SourceName: "eu", DestinationName: "us", MaxHops: 2})
+	waitForLast(t, dst, "orders", 1)
+
+	messages := readAll(t, dst, "orders")
+	if string(messages[0].Data) != "near" {
+		t.Fatalf("expected only the message under the hop limit, got %q", messages[0].Data)
+	}
+	if messages[0].Headers[HeaderOrigin] != "apac" || messages[0].Headers[HeaderHops] != "2" {
+		t.Errorf("expected origin to be kept and hops incremented, got %v", messages[0].Headers)
+	}
+
+	waitForStats(t, b, func(s SubjectStats) bool { return s.Skipped == 1 && s.Mirrored == 1 })
+}
+
+func TestBridge_StatsLag(t *testing.T) {
+	src, dst := newBroker(t), newBroker(t)
+	publish(t, src, "orders", "one", nil)
+
+	b := startBridge(t, &Config{Source: src, Destination: dst, Subjects: []string{"orders"}, SourceName: "eu", DestinationName: "us"})
+	waitForStats(t, b, func(s SubjectStats) bool { return s.Mirrored == 1 })
+	b.Stop()
+	b.Wait()
+
+	publish(t, src, "orders", "two", nil)
+	publish(t, src, "orders", "three", nil)
+
+	stats, err := b.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats[0].LastSequence != 1 || stats[0].Lag != 2 {
+		t.Errorf("expected lag of 2 after sequence 1, got %+v", stats[0])
+	}
+}
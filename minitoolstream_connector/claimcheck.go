@@ -0,0 +1,27 @@
+package minitoolstream_connector
+
+import (
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/claimcheck"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// ObjectStore re-exports domain.ObjectStore
+type ObjectStore = domain.ObjectStore
+
+// PayloadFetcher re-exports domain.PayloadFetcher
+type PayloadFetcher = domain.PayloadFetcher
+
+// Claim check
+type (
+	ClaimCheck       = claimcheck.ClaimCheck
+	ClaimCheckConfig = claimcheck.Config
+)
+
+// Claim check constructors and object stores
+var (
+	NewClaimCheck        = claimcheck.New
+	NewMemoryObjectStore = claimcheck.NewMemoryStore
+	NewDirObjectStore    = claimcheck.NewDirStore
+	// ErrObjectNotFound reports a key an object store does not hold
+	ErrObjectNotFound = claimcheck.ErrNotFound
+)
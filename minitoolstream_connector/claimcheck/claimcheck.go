@@ -0,0 +1,157 @@
+// Package claimcheck publishes oversized payloads by reference. The
+// publisher writes the payload to an object store and publishes a pointer
+// message naming the object in the claim-check header; subscribers fetch
+// the payload back before handlers see the message.
+package claimcheck
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"strconv"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// defaultThreshold is the payload size above which payloads are offloaded
+const defaultThreshold = 1 << 20
+
+// Config configures a ClaimCheck
+type Config struct {
+	// Store holds the offloaded payloads
+	Store domain.ObjectStore
+	// Threshold is the payload size in bytes above which payloads are
+	// offloaded (default 1 MiB)
+	Threshold int
+	// KeyFunc names the object of a payload (default "<subject>/<random id>")
+	KeyFunc func(msg *domain.PublishMessage) string
+}
+
+// ClaimCheck offloads payloads to an object store and fetches them back. It
+// implements domain.PayloadFetcher.
+type ClaimCheck struct {
+	store     domain.ObjectStore
+	threshold int
+	keyFunc   func(msg *domain.PublishMessage) string
+}
+
+// New creates a claim check
+func New(config *Config) (*ClaimCheck, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if config.Store == nil {
+		return nil, fmt.Errorf("store is required")
+	}
+	if config.Threshold < 0 {
+		return nil, fmt.Errorf("threshold cannot be negative, got %d", config.Threshold)
+	}
+
+	c := &ClaimCheck{
+		store:     config.Store,
+		threshold: config.Threshold,
+		keyFunc:   config.KeyFunc,
+	}
+	if c.threshold == 0 {
+		c.threshold = defaultThreshold
+	}
+	if c.keyFunc == nil {
+		c.keyFunc = defaultKey
+	}
+	return c, nil
+}
+
+// Threshold returns the payload size in bytes above which payloads are
+// offloaded
+func (c *ClaimCheck) Threshold() int {
+	return c.threshold
+}
+
+// Offload writes the payload of msg to the store and returns the pointer
+// message to publish in its place, with the headers of msg. Payloads within
+// the threshold are returned as is. Offload matches the publisher's
+// oversize handler.
+func (c *ClaimCheck) Offload(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishMessage, error) {
+	if len(msg.Data) <= c.threshold {
+		return msg, nil
+	}
+
+	key := c.keyFunc(msg)
+	if key == "" {
+		return nil, fmt.Errorf("claim check key is required")
+	}
+	if err := c.store.Put(ctx, key, msg.Data); err != nil {
+		return nil, fmt.Errorf("failed to store payload %s: %w", key, err)
+	}
+
+	digest := sha256.Sum256(msg.Data)
+	pointer := &domain.PublishMessage{
+		Subject:  msg.Subject,
+		Headers:  maps.Clone(msg.Headers),
+		DedupKey: msg.DedupKey,
+	}
+	if pointer.Headers == nil {
+		pointer.Headers = make(map[string]string, 3)
+	}
+	pointer.Headers[domain.HeaderClaimCheck] = key
+	pointer.Headers[domain.HeaderClaimCheckSize] = strconv.Itoa(len(msg.Data))
+	pointer.Headers[domain.HeaderClaimCheckDigest] = hex.EncodeToString(digest[:])
+	return pointer, nil
+}
+
+// Fetch returns a copy of msg with the referenced payload fetched from the
+// store and the claim-check headers removed. Messages carrying their payload
+// inline are returned as is. Payloads that do not match their digest fail
+// with domain.ErrDecode; store errors are returned as they are, so the
+// message can be redelivered.
+func (c *ClaimCheck) Fetch(ctx context.Context, msg *domain.ReceivedMessage) (*domain.ReceivedMessage, error) {
+	key := msg.Headers[domain.HeaderClaimCheck]
+	if key == "" {
+		return msg, nil
+	}
+
+	data, err := c.store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payload %s: %w", key, err)
+	}
+	if size := msg.Headers[domain.HeaderClaimCheckSize]; size != "" && size != strconv.Itoa(len(data)) {
+		return nil, fmt.Errorf("%w: sequence %d: payload %s has %d bytes, expected %s", domain.ErrDecode, msg.Sequence, key, len(data), size)
+	}
+	if want := msg.Headers[domain.HeaderClaimCheckDigest]; want != "" {
+		digest := sha256.Sum256(data)
+		if hex.EncodeToString(digest[:]) != want {
+			return nil, fmt.Errorf("%w: sequence %d: payload %s does not match its digest", domain.ErrDecode, msg.Sequence, key)
+		}
+	}
+
+	full := *msg
+	full.Data = data
+	full.Headers = maps.Clone(msg.Headers)
+	delete(full.Headers, domain.HeaderClaimCheck)
+	delete(full.Headers, domain.HeaderClaimCheckSize)
+	delete(full.Headers, domain.HeaderClaimCheckDigest)
+	return &full, nil
+}
+
+// Handler wraps next so it receives fetched payloads
+func (c *ClaimCheck) Handler(next domain.MessageHandler) domain.MessageHandler {
+	return domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		full, err := c.Fetch(ctx, msg)
+		if err != nil {
+			return err
+		}
+		return next.Handle(ctx, full)
+	})
+}
+
+// defaultKey names objects after the subject and a random ID
+func defaultKey(msg *domain.PublishMessage) string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	return msg.Subject + "/" + hex.EncodeToString(b[:])
+}
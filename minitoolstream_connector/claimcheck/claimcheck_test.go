@@ -0,0 +1,107 @@
+package claimcheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestClaimCheck_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	cc, err := New(&Config{Store: store, Threshold: 10})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	small := &domain.PublishMessage{Subject: "files", Data: []byte("tiny")}
+	if got, err := cc.Offload(ctx, small); err != nil || got != small {
+		t.Errorf("expected a small payload to stay inline, got %+v, %v", got, err)
+	}
+
+	payload := []byte(strings.Repeat("large payload ", 10))
+	msg := &domain.PublishMessage{Subject: "files", Data: payload, Headers: map[string]string{"content-type": "text/plain"}}
+	pointer, err := cc.Offload(ctx, msg)
+	if err != nil {
+		t.Fatalf("Offload failed: %v", err)
+	}
+	key := pointer.Headers[domain.HeaderClaimCheck]
+	if len(pointer.Data) != 0 || !strings.HasPrefix(key, "files/") || pointer.Headers["content-type"] != "text/plain" {
+		t.Errorf("unexpected pointer %+v", pointer)
+	}
+	if _, ok := msg.Headers[domain.HeaderClaimCheck]; ok {
+		t.Error("Offload modified the original message")
+	}
+
+	received := &domain.ReceivedMessage{Subject: "files", Sequence: 3, Headers: pointer.Headers}
+	var got *domain.ReceivedMessage
+	handler := cc.Handler(domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		got = msg
+		return nil
+	}))
+	if err := handler.Handle(ctx, received); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if !bytes.Equal(got.Data, payload) || got.Sequence != 3 || got.Headers["content-type"] != "text/plain" {
+		t.Errorf("unexpected message %+v", got)
+	}
+	if _, ok := got.Headers[domain.HeaderClaimCheck]; ok {
+		t.Error("claim-check header was not removed")
+	}
+
+	inline := &domain.ReceivedMessage{Data: []byte("inline")}
+	if got, err := cc.Fetch(ctx, inline); err != nil || got != inline {
+		t.Errorf("expected an inline message to pass through, got %v", err)
+	}
+
+	store.Put(ctx, key, []byte("tampered"))
+	if _, err := cc.Fetch(ctx, received); !errors.Is(err, domain.ErrDecode) {
+		t.Errorf("expected ErrDecode for a tampered payload, got %v", err)
+	}
+	missing := &domain.ReceivedMessage{Headers: map[string]string{domain.HeaderClaimCheck: "files/missing"}}
+	if _, err := cc.Fetch(ctx, missing); !errors.Is(err, ErrNotFound) || errors.Is(err, domain.ErrDecode) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	for _, config := range []*Config{nil, {}, {Store: NewMemoryStore(), Threshold: -1}} {
+		if _, err := New(config); err == nil {
+			t.Errorf("expected error for %+v", config)
+		}
+	}
+	cc, _ := New(&Config{Store: NewMemoryStore()})
+	if cc.Threshold() != defaultThreshold {
+		t.Errorf("Threshold() = %d, want %d", cc.Threshold(), defaultThreshold)
+	}
+}
+
+func TestDirStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDirStore failed: %v", err)
+	}
+
+	if err := store.Put(ctx, "images/raw/1", []byte("v1")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if err := store.Put(ctx, "images/raw/1", []byte("v2")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if data, err := store.Get(ctx, "images/raw/1"); err != nil || string(data) != "v2" {
+		t.Errorf("Get = %q, %v", data, err)
+	}
+	if _, err := store.Get(ctx, "images/raw/2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	for _, key := range []string{"../escape", "/etc/passwd", ""} {
+		if err := store.Put(ctx, key, nil); err == nil {
+			t.Errorf("Put(%q) expected error", key)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/localbroker"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/pipeline"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+	subscriberUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/subscriber"
+)
+
+// runDaemon runs the daemon command
+func runDaemon(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	config := fs.String("config", "pipelines.yaml", "pipeline file")
+	check := fs.Bool("check", false, "validate the pipeline file and exit")
+	quiet := fs.Bool("quiet", false, "do not log progress")
+	tls := addTLSFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	spec, err := pipeline.Load(*config)
+	if err != nil {
+		return err
+	}
+	if *check {
+		fmt.Fprintf(stdout, "✓ %s: %d pipelines\n", *config, len(spec.Pipelines))
+		return nil
+	}
+
+	opts, err := tls.dialOptions()
+	if err != nil {
+		return err
+	}
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	if *quiet {
+		logger = log.New(io.Discard, "", 0)
+	}
+
+	var ingress domain.IngressClient
+	var egress domain.EgressClient
+	if spec.Local != "" {
+		broker, err := localbroker.New(&localbroker.Config{Dir: spec.Local})
+		if err != nil {
+			return err
+		}
+		defer broker.Close()
+		ingress, egress = broker, broker
+	} else {
+		if spec.HasSources() {
+			client, err := grpcClient.NewIngressClient(orDefault(spec.Ingress, "localhost:50051"), opts...)
+			if err != nil {
+				return fmt.Errorf("failed to create ingress client: %w", err)
+			}
+			defer client.Close()
+			ingress = client
+		}
+		if spec.HasSinks() {
+			client, err := grpcClient.NewEgressClient(orDefault(spec.Egress, "localhost:50052"), opts...)
+			if err != nil {
+				return fmt.Errorf("failed to create egress client: %w", err)
+			}
+			defer client.Close()
+			egress = client
+		}
+	}
+
+	daemonConfig := &pipeline.Config{Spec: spec, Logger: logger}
+	if spec.HasSources() {
+		pub, err := publisher.New(&publisher.Config{Client: ingress, Logger: logger})
+		if err != nil {
+			return err
+		}
+		daemonConfig.Publisher = pub
+	}
+	if spec.HasSinks() {
+		sub, err := subscriberUsecase.New(&subscriberUsecase.Config{
+			Client:      egress,
+			DurableName: orDefault(spec.Durable, "minitoolstream-daemon"),
+			Logger:      logger,
+		})
+		if err != nil {
+			return err
+		}
+		daemonConfig.Subscriber = sub
+	}
+
+	daemon, err := pipeline.New(daemonConfig)
+	if err != nil {
+		return err
+	}
+	return daemon.Run(ctx)
+}
+
+// orDefault returns value, or fallback when value is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
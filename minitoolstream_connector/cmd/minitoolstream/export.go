@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/dump"
+	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
+)
+
+// runExport runs the export command
+func runExport(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	server := fs.String("server", "localhost:50052", "egress server address")
+	subject := fs.String("subject", "", "subject to export")
+	from := fs.Uint64("from", 1, "first sequence to export")
+	to := fs.Uint64("to", 0, "last sequence to export (default the last sequence of the subject)")
+	format := fs.String("format", dump.FormatNDJSON, "dump format: ndjson or dir")
+	out := fs.String("out", "-", "output file for ndjson (- is stdout) or directory for dir")
+	batch := fs.Int("batch", 100, "fetch batch size")
+	quiet := fs.Bool("quiet", false, "do not log progress")
+	tls := addTLSFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if *format != dump.FormatNDJSON && *format != dump.FormatDir {
+		return fmt.Errorf("unknown format %q: must be %s or %s", *format, dump.FormatNDJSON, dump.FormatDir)
+	}
+	if *format == dump.FormatDir && *out == "-" {
+		return fmt.Errorf("-out must be a directory for the dir format")
+	}
+
+	opts, err := tls.dialOptions()
+	if err != nil {
+		return err
+	}
+
+	// Logs go to stderr so an NDJSON dump can be written to stdout
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	if *quiet {
+		logger = log.New(io.Discard, "", 0)
+	}
+
+	egress, err := grpcClient.NewEgressClient(*server, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create egress client: %w", err)
+	}
+	defer egress.Close()
+
+	exporter, err := dump.NewExporter(&dump.ExporterConfig{
+		Client:       egress,
+		Subject:      *subject,
+		FromSequence: *from,
+		ToSequence:   *to,
+		BatchSize:    int32(*batch),
+		Logger:       logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *format == dump.FormatDir {
+		_, err = exporter.WriteDir(ctx, *out)
+		return err
+	}
+
+	if *out == "-" {
+		_, err = exporter.WriteNDJSON(ctx, stdout)
+		return err
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", *out, err)
+	}
+	if _, err := exporter.WriteNDJSON(ctx, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/dump"
+	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+)
+
+// subjectMap is a repeatable old=new flag
+type subjectMap map[string]string
+
+func (m subjectMap) String() string {
+	pairs := make([]string, 0, len(m))
+	for from, to := range m {
+		pairs = append(pairs, from+"="+to)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m subjectMap) Set(value string) error {
+	from, to, ok := strings.Cut(value, "=")
+	if !ok || from == "" || to == "" {
+		return fmt.Errorf("expected old=new, got %q", value)
+	}
+	m[from] = to
+	return nil
+}
+
+// runImport runs the import command
+func runImport(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	server := fs.String("server", "localhost:50051", "ingress server address")
+	in := fs.String("in", "-", "NDJSON dump file (- is stdin) or dump directory")
+	subject := fs.String("subject", "", "republish every message to this subject")
+	rename := subjectMap{}
+	fs.Var(rename, "rename", "republish a subject under a new name, as old=new (repeatable)")
+	rate := fs.Float64("rate", 0, "publishes per second (0 is unlimited)")
+	retries := fs.Int("retries", 3, "publish retries per message")
+	quiet := fs.Bool("quiet", false, "do not log progress")
+	tls := addTLSFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	opts, err := tls.dialOptions()
+	if err != nil {
+		return err
+	}
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	if *quiet {
+		logger = log.New(io.Discard, "", 0)
+	}
+
+	ingress, err := grpcClient.NewIngressClient(*server, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create ingress client: %w", err)
+	}
+
+	pub, err := publisher.New(&publisher.Config{
+		Client:     ingress,
+		Logger:     logger,
+		MaxRetries: *retries,
+		RateLimit:  *rate,
+		LogLevel:   domain.LogLevelInfo,
+	})
+	if err != nil {
+		ingress.Close()
+		return err
+	}
+	defer pub.Close()
+
+	importer, err := dump.NewImporter(&dump.ImporterConfig{
+		Publisher: pub,
+		Subject:   *subject,
+		Subjects:  rename,
+		Logger:    logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *in == "-" {
+		_, err = importer.ReadNDJSON(ctx, os.Stdin)
+		return err
+	}
+
+	info, err := os.Stat(*in)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		_, err = importer.ReadDir(ctx, *in)
+		return err
+	}
+
+	f, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = importer.ReadNDJSON(ctx, f)
+	return err
+}
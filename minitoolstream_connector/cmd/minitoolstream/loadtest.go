@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/loadtest"
+)
+
+// runLoadtest runs the loadtest command
+func runLoadtest(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+	server := fs.String("server", "localhost:50051", "ingress server address")
+	egressServer := fs.String("egress-server", "", "egress server address (defaults to -server)")
+	subject := fs.String("subject", "loadtest", "subject to publish to")
+	messages := fs.Int("messages", 0, "number of messages to publish (default 1000 unless -duration is set)")
+	duration := fs.Duration("duration", 0, "length of the publish phase")
+	rate := fs.Float64("rate", 0, "publishes per second across all workers (0 is unlimited)")
+	concurrency := fs.Int("concurrency", 1, "number of publishing workers")
+	size := fs.Int("size", 1024, "payload size in bytes")
+	batch := fs.Int("batch", 100, "subscriber fetch batch size")
+	publishOnly := fs.Bool("publish-only", false, "do not subscribe or measure end-to-end latency")
+	drain := fs.Duration("drain-timeout", 10*time.Second, "how long to wait for published messages to be received")
+	progress := fs.Duration("progress", 5*time.Second, "progress log interval (0 disables)")
+	tls := addTLSFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	opts, err := tls.dialOptions()
+	if err != nil {
+		return err
+	}
+
+	ingress, err := grpcClient.NewIngressClient(*server, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create ingress client: %w", err)
+	}
+	defer ingress.Close()
+
+	config := &loadtest.Config{
+		Ingress:          ingress,
+		Subject:          *subject,
+		Messages:         *messages,
+		Duration:         *duration,
+		Rate:             *rate,
+		Concurrency:      *concurrency,
+		PayloadSize:      *size,
+		BatchSize:        int32(*batch),
+		DrainTimeout:     *drain,
+		ProgressInterval: *progress,
+		Logger:           log.New(io.Discard, "", 0),
+	}
+	if *progress > 0 {
+		config.Logger = log.Default()
+	}
+
+	if !*publishOnly {
+		if *egressServer == "" {
+			*egressServer = *server
+		}
+		egress, err := grpcClient.NewEgressClient(*egressServer, opts...)
+		if err != nil {
+			return fmt.Errorf("failed to create egress client: %w", err)
+		}
+		defer egress.Close()
+		config.Egress = egress
+	}
+
+	runner, err := loadtest.New(config)
+	if err != nil {
+		return err
+	}
+
+	report, err := runner.Run(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(stdout, report)
+	return nil
+}
@@ -0,0 +1,78 @@
+// Command minitoolstream provides tools for working with a MiniToolStream server.
+//
+// Usage:
+//
+//	minitoolstream <command> [flags]
+//
+// Commands:
+//
+//	loadtest   publish and subscribe a synthetic workload and report throughput and latency
+//	export     dump the messages of a subject to NDJSON or a directory
+//	import     republish the messages of a dump
+//	migrate    copy the history of subjects to another cluster, resumably
+//	daemon     run the ingest and export pipelines of a YAML file
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// command is a CLI subcommand
+type command struct {
+	name        string
+	description string
+	run         func(ctx context.Context, args []string, stdout io.Writer) error
+}
+
+var commands = []command{
+	{"loadtest", "publish and subscribe a synthetic workload and report throughput and latency", runLoadtest},
+	{"export", "dump the messages of a subject to NDJSON or a directory", runExport},
+	{"import", "republish the messages of a dump", runImport},
+	{"migrate", "copy the history of subjects to another cluster, resumably", runMigrate},
+	{"daemon", "run the ingest and export pipelines of a YAML file", runDaemon},
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// run dispatches args to a subcommand
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(stderr)
+		return flag.ErrHelp
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(ctx, args[1:], stdout)
+		}
+	}
+
+	usage(stderr)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: minitoolstream <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.description)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	var stderr bytes.Buffer
+	if err := run(ctx, nil, &bytes.Buffer{}, &stderr); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("expected ErrHelp, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), "loadtest") {
+		t.Errorf("expected usage to list loadtest, got %q", stderr.String())
+	}
+
+	if err := run(ctx, []string{"nope"}, &bytes.Buffer{}, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "unknown command") {
+		t.Errorf("expected unknown command error, got %v", err)
+	}
+}
+
+func TestRunLoadtest_Flags(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown flag", []string{"-nope"}, "flag provided but not defined"},
+		{"extra arguments", []string{"extra"}, "unexpected arguments"},
+		{"partial mtls", []string{"-cert", "c.pem"}, "must be set together"},
+		{"empty subject", []string{"-subject", "", "-publish-only"}, "subject is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(ctx, append([]string{"loadtest"}, tt.args...), &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRunExport_Flags(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown flag", []string{"-nope"}, "flag provided but not defined"},
+		{"extra arguments", []string{"-subject", "orders", "extra"}, "unexpected arguments"},
+		{"unknown format", []string{"-subject", "orders", "-format", "csv"}, "unknown format"},
+		{"dir to stdout", []string{"-subject", "orders", "-format", "dir"}, "must be a directory"},
+		{"partial mtls", []string{"-subject", "orders", "-ca", "ca.pem"}, "must be set together"},
+		{"empty subject", []string{"-quiet"}, "subject is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(ctx, append([]string{"export"}, tt.args...), &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRunImport_Flags(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown flag", []string{"-nope"}, "flag provided but not defined"},
+		{"extra arguments", []string{"extra"}, "unexpected arguments"},
+		{"bad rename", []string{"-rename", "orders"}, "expected old=new"},
+		{"partial mtls", []string{"-key", "k.pem"}, "must be set together"},
+		{"missing input", []string{"-quiet", "-in", "does-not-exist.ndjson"}, "no such file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(ctx, append([]string{"import"}, tt.args...), &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRunMigrate_Flags(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown flag", []string{"-nope"}, "flag provided but not defined"},
+		{"extra arguments", []string{"-from", "a:1", "-to", "b:1", "-subjects", "orders", "extra"}, "unexpected arguments"},
+		{"missing destination", []string{"-from", "a:1", "-subjects", "orders"}, "are required"},
+		{"missing subjects", []string{"-from", "a:1", "-to", "b:1"}, "are required"},
+		{"partial mtls", []string{"-from", "a:1", "-to", "b:1", "-subjects", "orders", "-cert", "c.pem"}, "must be set together"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(ctx, append([]string{"migrate"}, tt.args...), &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRunDaemon_Flags(t *testing.T) {
+	ctx := context.Background()
+	invalid := filepath.Join(t.TempDir(), "invalid.yaml")
+	if err := os.WriteFile(invalid, []byte("pipelines:\n  - name: a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown flag", []string{"-nope"}, "flag provided but not defined"},
+		{"extra arguments", []string{"extra"}, "unexpected arguments"},
+		{"missing config", []string{"-config", "does-not-exist.yaml"}, "no such file"},
+		{"invalid config", []string{"-config", invalid}, "subject is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(ctx, append([]string{"daemon"}, tt.args...), &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRunDaemon_Check(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pipelines.yaml")
+	spec := "pipelines:\n  - name: a\n    subject: s\n    sinks: [log: {}]\n"
+	if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	if err := run(context.Background(), []string{"daemon", "-check", "-config", path}, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "1 pipelines") {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/checkpoint"
+	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/migrate"
+)
+
+// runMigrate runs the migrate command
+func runMigrate(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	from := fs.String("from", "", "egress address of the source cluster")
+	to := fs.String("to", "", "ingress address of the destination cluster")
+	subjects := fs.String("subjects", "", "comma-separated subjects to copy")
+	progress := fs.String("progress", "migrate-progress.json", "file tracking the copied sequences, for resuming")
+	name := fs.String("name", "migrate", "name keying this migration's progress")
+	batch := fs.Int("batch", 100, "fetch batch size")
+	retries := fs.Int("retries", 3, "publish retries per message")
+	quiet := fs.Bool("quiet", false, "do not log progress")
+	tls := addTLSFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if *from == "" || *to == "" || *subjects == "" {
+		return fmt.Errorf("-from, -to and -subjects are required")
+	}
+
+	opts, err := tls.dialOptions()
+	if err != nil {
+		return err
+	}
+
+	logger := log.New(os.Stderr, "", log.LstdFlags)
+	if *quiet {
+		logger = log.New(io.Discard, "", 0)
+	}
+
+	store, err := checkpoint.NewFileStore(*progress)
+	if err != nil {
+		return err
+	}
+
+	egress, err := grpcClient.NewEgressClient(*from, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create egress client: %w", err)
+	}
+	defer egress.Close()
+
+	ingress, err := grpcClient.NewIngressClient(*to, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to create ingress client: %w", err)
+	}
+	defer ingress.Close()
+
+	m, err := migrate.New(&migrate.Config{
+		Source:      egress,
+		Destination: ingress,
+		Subjects:    strings.Split(*subjects, ","),
+		Progress:    store,
+		Name:        *name,
+		BatchSize:   int32(*batch),
+		MaxRetries:  *retries,
+		Logger:      logger,
+	})
+	if err != nil {
+		return err
+	}
+
+	results, err := m.Run(ctx)
+	for _, r := range results {
+		fmt.Fprintf(stdout, "%-30s copied %d messages (%d bytes), sequences %d-%d\n", r.Subject, r.Copied, r.Bytes, r.FromSequence, r.ToSequence)
+	}
+	return err
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/dialopts"
+	"google.golang.org/grpc"
+)
+
+// tlsFlags holds the transport security flags shared by the commands
+type tlsFlags struct {
+	useTLS *bool
+	cert   *string
+	key    *string
+	ca     *string
+}
+
+// addTLSFlags registers the transport security flags on fs
+func addTLSFlags(fs *flag.FlagSet) *tlsFlags {
+	return &tlsFlags{
+		useTLS: fs.Bool("tls", false, "connect with TLS using the system roots"),
+		cert:   fs.String("cert", "", "client certificate file for mutual TLS"),
+		key:    fs.String("key", "", "client key file for mutual TLS"),
+		ca:     fs.String("ca", "", "CA certificate file for mutual TLS"),
+	}
+}
+
+// dialOptions builds the transport dial option from the flags
+func (f *tlsFlags) dialOptions() ([]grpc.DialOption, error) {
+	switch {
+	case *f.cert != "" || *f.key != "" || *f.ca != "":
+		if *f.cert == "" || *f.key == "" || *f.ca == "" {
+			return nil, fmt.Errorf("-cert, -key and -ca must be set together")
+		}
+		opt, err := dialopts.MTLSFromFiles(*f.cert, *f.key, *f.ca)
+		if err != nil {
+			return nil, err
+		}
+		return []grpc.DialOption{opt}, nil
+	case *f.useTLS:
+		return []grpc.DialOption{dialopts.TLS(nil)}, nil
+	default:
+		return []grpc.DialOption{dialopts.Insecure()}, nil
+	}
+}
@@ -0,0 +1,642 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// avroMagic starts an Avro single-object encoding, followed by the
+// little-endian fingerprint of the writer schema
+var avroMagic = []byte{0xc3, 0x01}
+
+// AvroSchemaRegistry resolves writer schemas by fingerprint, e.g. from a
+// schema registry service
+type AvroSchemaRegistry interface {
+	LookupSchema(fingerprint uint64) (*AvroSchema, error)
+}
+
+// AvroSchemas is an in-memory AvroSchemaRegistry. It is safe for concurrent
+// use.
+type AvroSchemas struct {
+	mu      sync.RWMutex
+	schemas map[uint64]*AvroSchema
+}
+
+// NewAvroSchemas creates a registry holding schemas
+func NewAvroSchemas(schemas ...*AvroSchema) *AvroSchemas {
+	r := &AvroSchemas{schemas: make(map[uint64]*AvroSchema)}
+	for _, s := range schemas {
+		r.Add(s)
+	}
+	return r
+}
+
+// Add registers a schema under its fingerprint
+func (r *AvroSchemas) Add(s *AvroSchema) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schemas[s.Fingerprint()] = s
+}
+
+// LookupSchema returns the schema with a fingerprint
+func (r *AvroSchemas) LookupSchema(fingerprint uint64) (*AvroSchema, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if s, ok := r.schemas[fingerprint]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("avro: unknown schema fingerprint %016x", fingerprint)
+}
+
+// AvroConfig holds the configuration of an Avro codec
+type AvroConfig struct {
+	// Schema is the schema values are written with and read into
+	Schema *AvroSchema
+	// Registry resolves the writer schemas of payloads written with other
+	// schemas, e.g. by older or newer producers. Optional.
+	Registry AvroSchemaRegistry
+	// ContentType defaults to application/avro
+	ContentType string
+}
+
+// Avro encodes values with an Avro schema in the single-object encoding, so
+// every payload carries the fingerprint of its writer schema. Payloads
+// written with another schema are resolved against the codec's schema
+// following the Avro schema resolution rules: fields are matched by name or
+// alias, missing fields take their defaults and numeric types are promoted.
+// Struct fields are named by their avro tag, then their json tag.
+type Avro struct {
+	schema      *AvroSchema
+	registry    AvroSchemaRegistry
+	contentType string
+
+	// writers caches writer schemas resolved through the registry
+	writers sync.Map
+}
+
+// NewAvro creates an Avro codec
+func NewAvro(config *AvroConfig) (*Avro, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if config.Schema == nil {
+		return nil, fmt.Errorf("schema is required")
+	}
+	contentType := config.ContentType
+	if contentType == "" {
+		contentType = "application/avro"
+	}
+	return &Avro{
+		schema:      config.Schema,
+		registry:    config.Registry,
+		contentType: contentType,
+	}, nil
+}
+
+// ContentType returns the configured content type
+func (a *Avro) ContentType() string {
+	return a.contentType
+}
+
+// Schema returns the schema of the codec
+func (a *Avro) Schema() *AvroSchema {
+	return a.schema
+}
+
+// Marshal encodes v with the codec's schema
+func (a *Avro) Marshal(v interface{}) ([]byte, error) {
+	tree, err := toTree(reflect.ValueOf(v), "avro")
+	if err != nil {
+		return nil, err
+	}
+	b := append([]byte(nil), avroMagic...)
+	b = binary.LittleEndian.AppendUint64(b, a.schema.Fingerprint())
+	return appendAvro(b, a.schema.root, tree)
+}
+
+// Unmarshal decodes a single-object encoded payload into v, which must be a
+// non-nil pointer
+func (a *Avro) Unmarshal(data []byte, v interface{}) error {
+	if len(data) < 10 || !bytes.HasPrefix(data, avroMagic) {
+		return fmt.Errorf("avro: missing single-object encoding header")
+	}
+	writer, err := a.writerSchema(binary.LittleEndian.Uint64(data[2:10]))
+	if err != nil {
+		return err
+	}
+
+	r := &avroReader{data: data[10:]}
+	tree, err := r.read(writer.root, a.schema.root, 0)
+	if err != nil {
+		return err
+	}
+	if r.pos != len(r.data) {
+		return fmt.Errorf("avro: %d trailing bytes", len(r.data)-r.pos)
+	}
+	return decodeInto(tree, v, "avro")
+}
+
+// writerSchema returns the schema with a fingerprint
+func (a *Avro) writerSchema(fingerprint uint64) (*AvroSchema, error) {
+	if fingerprint == a.schema.Fingerprint() {
+		return a.schema, nil
+	}
+	if s, ok := a.writers.Load(fingerprint); ok {
+		return s.(*AvroSchema), nil
+	}
+	if a.registry == nil {
+		return nil, fmt.Errorf("avro: payload written with unknown schema %016x and no registry configured", fingerprint)
+	}
+	s, err := a.registry.LookupSchema(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	a.writers.Store(fingerprint, s)
+	return s, nil
+}
+
+// appendAvro appends the binary encoding of a tree of type t to b
+func appendAvro(b []byte, t *avroType, tree interface{}) ([]byte, error) {
+	switch t.kind {
+	case avroNull:
+		if tree != nil {
+			return nil, fmt.Errorf("avro: cannot write %T as null", tree)
+		}
+		return b, nil
+	case avroBoolean:
+		v, ok := tree.(bool)
+		if !ok {
+			return nil, fmt.Errorf("avro: cannot write %T as boolean", tree)
+		}
+		if v {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+
+	case avroInt, avroLong:
+		n, err := treeInt(tree)
+		if err != nil {
+			return nil, fmt.Errorf("avro: %s: %w", t.kind, err)
+		}
+		if t.kind == avroInt && (n < math.MinInt32 || n > math.MaxInt32) {
+			return nil, fmt.Errorf("avro: %d overflows int", n)
+		}
+		return binary.AppendVarint(b, n), nil
+	case avroFloat, avroDouble:
+		f, ok := avroFloatValue(tree)
+		if !ok {
+			return nil, fmt.Errorf("avro: cannot write %T as %s", tree, t.kind)
+		}
+		if t.kind == avroFloat {
+			return binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(f))), nil
+		}
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(f)), nil
+
+	case avroBytes, avroString:
+		var data []byte
+		switch v := tree.(type) {
+		case []byte:
+			data = v
+		case string:
+			data = []byte(v)
+		default:
+			return nil, fmt.Errorf("avro: cannot write %T as %s", tree, t.kind)
+		}
+		return append(binary.AppendVarint(b, int64(len(data))), data...), nil
+	case avroFixed:
+		data, ok := tree.([]byte)
+		if !ok || len(data) != t.size {
+			return nil, fmt.Errorf("avro: fixed %s needs %d bytes", t.name, t.size)
+		}
+		return append(b, data...), nil
+	case avroEnum:
+		s, _ := tree.(string)
+		i := indexOf(t.symbols, s)
+		if i < 0 {
+			return nil, fmt.Errorf("avro: %v is not a symbol of enum %s", tree, t.name)
+		}
+		return binary.AppendVarint(b, int64(i)), nil
+
+	case avroArray:
+		var items []interface{}
+		switch v := tree.(type) {
+		case nil:
+		case []interface{}:
+			items = v
+		case []byte:
+			// []uint8 values arrive as bytes
+			for _, c := range v {
+				items = append(items, uint64(c))
+			}
+		default:
+			return nil, fmt.Errorf("avro: cannot write %T as array", tree)
+		}
+		if len(items) > 0 {
+			b = binary.AppendVarint(b, int64(len(items)))
+		}
+		var err error
+		for _, item := range items {
+			if b, err = appendAvro(b, t.items, item); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, 0), nil
+	case avroMap:
+		m, ok := tree.(mapValue)
+		if !ok && tree != nil {
+			return nil, fmt.Errorf("avro: cannot write %T as map", tree)
+		}
+		if len(m) > 0 {
+			b = binary.AppendVarint(b, int64(len(m)))
+		}
+		var err error
+		for _, entry := range m {
+			key, ok := entry.Key.(string)
+			if !ok {
+				return nil, fmt.Errorf("avro: map keys must be strings, got %T", entry.Key)
+			}
+			b = append(binary.AppendVarint(b, int64(len(key))), key...)
+			if b, err = appendAvro(b, t.values, entry.Value); err != nil {
+				return nil, err
+			}
+		}
+		return append(b, 0), nil
+
+	case avroRecord:
+		m, ok := tree.(mapValue)
+		if !ok {
+			return nil, fmt.Errorf("avro: cannot write %T as record %s", tree, t.name)
+		}
+		var err error
+		for _, f := range t.fields {
+			value, found := recordValue(m, f.name)
+			if !found {
+				if !f.hasDefault {
+					return nil, fmt.Errorf("avro: record %s: missing field %s", t.name, f.name)
+				}
+				value = f.def
+			}
+			if b, err = appendAvro(b, f.typ, value); err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.name, err)
+			}
+		}
+		return b, nil
+
+	case avroUnion:
+		for i, branch := range t.branches {
+			if avroAccepts(branch, tree) {
+				return appendAvro(binary.AppendVarint(b, int64(i)), branch, tree)
+			}
+		}
+		return nil, fmt.Errorf("avro: no union branch accepts %T", tree)
+
+	default:
+		return nil, fmt.Errorf("avro: unsupported type %s", t.kind)
+	}
+}
+
+// avroAccepts reports whether a tree can be written as t, to pick a union
+// branch
+func avroAccepts(t *avroType, tree interface{}) bool {
+	switch v := tree.(type) {
+	case nil:
+		return t.kind == avroNull
+	case bool:
+		return t.kind == avroBoolean
+	case int64, uint64:
+		n, err := treeInt(v)
+		switch t.kind {
+		case avroInt:
+			return err == nil && n >= math.MinInt32 && n <= math.MaxInt32
+		case avroLong:
+			return err == nil
+		}
+		return t.kind == avroFloat || t.kind == avroDouble
+	case float32, float64:
+		return t.kind == avroFloat || t.kind == avroDouble
+	case string:
+		return t.kind == avroString || (t.kind == avroEnum && indexOf(t.symbols, v) >= 0)
+	case []byte:
+		return t.kind == avroBytes || (t.kind == avroFixed && len(v) == t.size)
+	case []interface{}:
+		return t.kind == avroArray
+	case mapValue:
+		return t.kind == avroRecord || t.kind == avroMap
+	}
+	return false
+}
+
+// avroFloatValue converts a numeric tree to a float
+func avroFloatValue(tree interface{}) (float64, bool) {
+	switch v := tree.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// recordValue finds a field in a record tree, by exact then case-insensitive
+// name
+func recordValue(m mapValue, name string) (interface{}, bool) {
+	for _, entry := range m {
+		if entry.Key == name {
+			return entry.Value, true
+		}
+	}
+	for _, entry := range m {
+		if key, ok := entry.Key.(string); ok && strings.EqualFold(key, name) {
+			return entry.Value, true
+		}
+	}
+	return nil, false
+}
+
+// avroReader reads trees from Avro binary data, resolving the writer schema
+// against the reader schema
+type avroReader struct {
+	data []byte
+	pos  int
+}
+
+// next returns the next n bytes
+func (r *avroReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.data)-r.pos < n {
+		return nil, fmt.Errorf("avro: unexpected end of data")
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// long reads a zig-zag varint
+func (r *avroReader) long() (int64, error) {
+	n, size := binary.Varint(r.data[r.pos:])
+	if size <= 0 {
+		return 0, fmt.Errorf("avro: invalid varint")
+	}
+	r.pos += size
+	return n, nil
+}
+
+// blob reads length-prefixed bytes
+func (r *avroReader) blob() ([]byte, error) {
+	n, err := r.long()
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > int64(len(r.data)-r.pos) {
+		return nil, fmt.Errorf("avro: length %d exceeds the payload", n)
+	}
+	return r.next(int(n))
+}
+
+// blockCount reads the item count of an array or map block. A negative count
+// is followed by the block size in bytes.
+func (r *avroReader) blockCount() (int, error) {
+	n, err := r.long()
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		n = -n
+		if _, err := r.long(); err != nil {
+			return 0, err
+		}
+	}
+	if n > int64(len(r.data)-r.pos) {
+		return 0, fmt.Errorf("avro: block of %d items exceeds the payload", n)
+	}
+	return int(n), nil
+}
+
+// read reads a value written as w and converts it to reader type rd
+func (r *avroReader) read(w, rd *avroType, depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, fmt.Errorf("avro: nesting too deep")
+	}
+
+	if w.kind == avroUnion {
+		i, err := r.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(w.branches)) {
+			return nil, fmt.Errorf("avro: union branch %d out of range", i)
+		}
+		return r.read(w.branches[i], rd, depth+1)
+	}
+	if rd.kind == avroUnion {
+		for _, branch := range rd.branches {
+			if avroMatches(w, branch) {
+				return r.read(w, branch, depth+1)
+			}
+		}
+		return nil, fmt.Errorf("avro: no reader union branch matches writer %s", w.describe())
+	}
+	if !avroMatches(w, rd) {
+		return nil, fmt.Errorf("avro: writer %s does not match reader %s", w.describe(), rd.describe())
+	}
+
+	switch w.kind {
+	case avroNull:
+		return nil, nil
+	case avroBoolean:
+		b, err := r.next(1)
+		if err != nil {
+			return nil, err
+		}
+		return b[0] != 0, nil
+
+	case avroInt, avroLong:
+		n, err := r.long()
+		if err != nil {
+			return nil, err
+		}
+		if rd.kind == avroFloat || rd.kind == avroDouble {
+			return float64(n), nil
+		}
+		return n, nil
+	case avroFloat:
+		b, err := r.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), nil
+	case avroDouble:
+		b, err := r.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+
+	case avroBytes, avroString:
+		b, err := r.blob()
+		if err != nil {
+			return nil, err
+		}
+		if rd.kind == avroString {
+			return string(b), nil
+		}
+		return append([]byte(nil), b...), nil
+	case avroFixed:
+		b, err := r.next(w.size)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case avroEnum:
+		i, err := r.long()
+		if err != nil {
+			return nil, err
+		}
+		if i < 0 || i >= int64(len(w.symbols)) {
+			return nil, fmt.Errorf("avro: enum index %d out of range", i)
+		}
+		symbol := w.symbols[i]
+		if indexOf(rd.symbols, symbol) < 0 {
+			if rd.enumDefault == "" {
+				return nil, fmt.Errorf("avro: symbol %s unknown to enum %s", symbol, rd.name)
+			}
+			symbol = rd.enumDefault
+		}
+		return symbol, nil
+
+	case avroArray:
+		items := []interface{}{}
+		for {
+			n, err := r.blockCount()
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				return items, nil
+			}
+			for ; n > 0; n-- {
+				item, err := r.read(w.items, rd.items, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+		}
+	case avroMap:
+		m := mapValue{}
+		for {
+			n, err := r.blockCount()
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				return m, nil
+			}
+			for ; n > 0; n-- {
+				key, err := r.blob()
+				if err != nil {
+					return nil, err
+				}
+				value, err := r.read(w.values, rd.values, depth+1)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, mapEntry{Key: string(key), Value: value})
+			}
+		}
+
+	case avroRecord:
+		return r.readRecord(w, rd, depth)
+
+	default:
+		return nil, fmt.Errorf("avro: unsupported type %s", w.kind)
+	}
+}
+
+// readRecord reads the fields of writer record w, dropping those unknown to
+// reader rd and filling in the defaults of reader fields the writer lacks
+func (r *avroReader) readRecord(w, rd *avroType, depth int) (interface{}, error) {
+	values := make([]interface{}, len(rd.fields))
+	set := make([]bool, len(rd.fields))
+	for _, wf := range w.fields {
+		i := rd.fieldIndex(wf.name)
+		if i < 0 {
+			if _, err := r.read(wf.typ, wf.typ, depth+1); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		value, err := r.read(wf.typ, rd.fields[i].typ, depth+1)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", wf.name, err)
+		}
+		values[i], set[i] = value, true
+	}
+
+	m := make(mapValue, len(rd.fields))
+	for i, f := range rd.fields {
+		if !set[i] {
+			if !f.hasDefault {
+				return nil, fmt.Errorf("avro: record %s: writer lacks field %s, which has no default", rd.name, f.name)
+			}
+			values[i] = f.def
+		}
+		m[i] = mapEntry{Key: f.name, Value: values[i]}
+	}
+	return m, nil
+}
+
+// fieldIndex returns the reader field named or aliased name, or -1
+func (t *avroType) fieldIndex(name string) int {
+	for i, f := range t.fields {
+		if f.name == name {
+			return i
+		}
+	}
+	for i, f := range t.fields {
+		if indexOf(f.aliases, name) >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// avroMatches reports whether data written as w can be read as rd, allowing
+// the numeric and string/bytes promotions
+func avroMatches(w, rd *avroType) bool {
+	switch w.kind {
+	case avroInt:
+		return rd.kind == avroInt || rd.kind == avroLong || rd.kind == avroFloat || rd.kind == avroDouble
+	case avroLong:
+		return rd.kind == avroLong || rd.kind == avroFloat || rd.kind == avroDouble
+	case avroFloat:
+		return rd.kind == avroFloat || rd.kind == avroDouble
+	case avroString, avroBytes:
+		return rd.kind == avroString || rd.kind == avroBytes
+	case avroRecord, avroEnum, avroFixed:
+		if rd.kind != w.kind || (w.kind == avroFixed && w.size != rd.size) {
+			return false
+		}
+		return w.shortName() == rd.shortName() || indexOf(rd.aliases, w.name) >= 0
+	case avroUnion:
+		return true
+	default:
+		return w.kind == rd.kind
+	}
+}
+
+// describe names a type in errors
+func (t *avroType) describe() string {
+	if t.name != "" {
+		return fmt.Sprintf("%s %s", t.kind, t.name)
+	}
+	return string(t.kind)
+}
@@ -0,0 +1,512 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AvroSchema is a parsed Avro schema. Logical types are read as their
+// underlying type.
+type AvroSchema struct {
+	root        *avroType
+	canonical   string
+	fingerprint uint64
+}
+
+// ParseAvroSchema parses an Avro schema in its JSON form
+func ParseAvroSchema(text string) (*AvroSchema, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var raw interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("avro: invalid schema JSON: %w", err)
+	}
+
+	p := &avroParser{named: make(map[string]*avroType)}
+	root, err := p.parse(raw, "")
+	if err != nil {
+		return nil, err
+	}
+	canonical := root.canonical()
+	return &AvroSchema{root: root, canonical: canonical, fingerprint: avroFingerprint([]byte(canonical))}, nil
+}
+
+// MustParseAvroSchema is like ParseAvroSchema but panics on error, for
+// schemas compiled into the program
+func MustParseAvroSchema(text string) *AvroSchema {
+	s, err := ParseAvroSchema(text)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Fingerprint returns the CRC-64-AVRO fingerprint of the schema's Parsing
+// Canonical Form, as carried in the single-object encoding header
+func (s *AvroSchema) Fingerprint() uint64 {
+	return s.fingerprint
+}
+
+// String returns the Parsing Canonical Form of the schema
+func (s *AvroSchema) String() string {
+	return s.canonical
+}
+
+// avroKind is the kind of an Avro type
+type avroKind string
+
+const (
+	avroNull    avroKind = "null"
+	avroBoolean avroKind = "boolean"
+	avroInt     avroKind = "int"
+	avroLong    avroKind = "long"
+	avroFloat   avroKind = "float"
+	avroDouble  avroKind = "double"
+	avroBytes   avroKind = "bytes"
+	avroString  avroKind = "string"
+	avroRecord  avroKind = "record"
+	avroEnum    avroKind = "enum"
+	avroArray   avroKind = "array"
+	avroMap     avroKind = "map"
+	avroUnion   avroKind = "union"
+	avroFixed   avroKind = "fixed"
+)
+
+// avroType is a node of a parsed schema. Named types are shared by pointer,
+// so recursive schemas form cycles.
+type avroType struct {
+	kind avroKind
+
+	// name is the full name of a record, enum or fixed
+	name    string
+	aliases []string
+
+	fields []*avroField // record
+	// symbols and enumDefault are set for enums; enumDefault is "" if unset
+	symbols     []string
+	enumDefault string
+	items       *avroType   // array
+	values      *avroType   // map
+	branches    []*avroType // union
+	size        int         // fixed
+}
+
+// avroField is a record field
+type avroField struct {
+	name       string
+	aliases    []string
+	typ        *avroType
+	hasDefault bool
+	// def is the default value as a decoded tree
+	def interface{}
+}
+
+// shortName returns the name without its namespace
+func (t *avroType) shortName() string {
+	return t.name[strings.LastIndexByte(t.name, '.')+1:]
+}
+
+// avroParser holds the named types defined so far
+type avroParser struct {
+	named map[string]*avroType
+}
+
+// parse parses a schema node within namespace
+func (p *avroParser) parse(raw interface{}, namespace string) (*avroType, error) {
+	switch r := raw.(type) {
+	case string:
+		return p.reference(r, namespace)
+	case []interface{}:
+		return p.parseUnion(r, namespace)
+	case map[string]interface{}:
+		return p.parseObject(r, namespace)
+	default:
+		return nil, fmt.Errorf("avro: invalid schema %v", raw)
+	}
+}
+
+// reference resolves a primitive or previously defined named type
+func (p *avroParser) reference(name, namespace string) (*avroType, error) {
+	switch kind := avroKind(name); kind {
+	case avroNull, avroBoolean, avroInt, avroLong, avroFloat, avroDouble, avroBytes, avroString:
+		return &avroType{kind: kind}, nil
+	}
+	if !strings.Contains(name, ".") && namespace != "" {
+		if t, ok := p.named[namespace+"."+name]; ok {
+			return t, nil
+		}
+	}
+	if t, ok := p.named[name]; ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("avro: unknown type %q", name)
+}
+
+// parseUnion parses a union
+func (p *avroParser) parseUnion(raw []interface{}, namespace string) (*avroType, error) {
+	t := &avroType{kind: avroUnion}
+	for _, item := range raw {
+		branch, err := p.parse(item, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if branch.kind == avroUnion {
+			return nil, fmt.Errorf("avro: unions may not immediately contain unions")
+		}
+		t.branches = append(t.branches, branch)
+	}
+	if len(t.branches) == 0 {
+		return nil, fmt.Errorf("avro: empty union")
+	}
+	return t, nil
+}
+
+// parseObject parses a schema object
+func (p *avroParser) parseObject(raw map[string]interface{}, namespace string) (*avroType, error) {
+	typeName, ok := raw["type"].(string)
+	if !ok {
+		if nested, present := raw["type"]; present {
+			return p.parse(nested, namespace)
+		}
+		return nil, fmt.Errorf("avro: schema object without type")
+	}
+
+	switch kind := avroKind(typeName); kind {
+	case avroRecord, "error", avroEnum, avroFixed:
+		if kind == "error" {
+			kind = avroRecord
+		}
+		return p.parseNamed(kind, raw, namespace)
+	case avroArray:
+		items, err := p.parse(raw["items"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &avroType{kind: avroArray, items: items}, nil
+	case avroMap:
+		values, err := p.parse(raw["values"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		return &avroType{kind: avroMap, values: values}, nil
+	default:
+		return p.reference(typeName, namespace)
+	}
+}
+
+// parseNamed parses a record, enum or fixed and defines its name
+func (p *avroParser) parseNamed(kind avroKind, raw map[string]interface{}, namespace string) (*avroType, error) {
+	name, _ := raw["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("avro: %s without name", kind)
+	}
+	if ns, ok := raw["namespace"].(string); ok && !strings.Contains(name, ".") {
+		namespace = ns
+	}
+	fullName := qualify(name, namespace)
+	if i := strings.LastIndexByte(fullName, '.'); i >= 0 {
+		namespace = fullName[:i]
+	} else {
+		namespace = ""
+	}
+	if _, exists := p.named[fullName]; exists {
+		return nil, fmt.Errorf("avro: type %q defined twice", fullName)
+	}
+
+	t := &avroType{kind: kind, name: fullName, aliases: qualifyAll(stringList(raw["aliases"]), namespace)}
+	p.named[fullName] = t
+
+	switch kind {
+	case avroRecord:
+		rawFields, ok := raw["fields"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("avro: record %s without fields", fullName)
+		}
+		for _, rawField := range rawFields {
+			field, err := p.parseField(rawField, namespace)
+			if err != nil {
+				return nil, fmt.Errorf("avro: record %s: %w", fullName, err)
+			}
+			t.fields = append(t.fields, field)
+		}
+
+	case avroEnum:
+		t.symbols = stringList(raw["symbols"])
+		if len(t.symbols) == 0 {
+			return nil, fmt.Errorf("avro: enum %s without symbols", fullName)
+		}
+		if def, ok := raw["default"].(string); ok {
+			if indexOf(t.symbols, def) < 0 {
+				return nil, fmt.Errorf("avro: enum %s default %q is not a symbol", fullName, def)
+			}
+			t.enumDefault = def
+		}
+
+	case avroFixed:
+		size, ok := raw["size"].(json.Number)
+		n, err := size.Int64()
+		if !ok || err != nil || n < 0 {
+			return nil, fmt.Errorf("avro: fixed %s without valid size", fullName)
+		}
+		t.size = int(n)
+	}
+	return t, nil
+}
+
+// parseField parses a record field
+func (p *avroParser) parseField(raw interface{}, namespace string) (*avroField, error) {
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid field %v", raw)
+	}
+	name, _ := obj["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("field without name")
+	}
+	typ, err := p.parse(obj["type"], namespace)
+	if err != nil {
+		return nil, fmt.Errorf("field %s: %w", name, err)
+	}
+
+	field := &avroField{name: name, aliases: stringList(obj["aliases"]), typ: typ}
+	if def, ok := obj["default"]; ok {
+		if field.def, err = avroDefault(typ, def); err != nil {
+			return nil, fmt.Errorf("field %s: invalid default: %w", name, err)
+		}
+		field.hasDefault = true
+	}
+	return field, nil
+}
+
+// avroDefault converts a JSON default value to a tree of type t
+func avroDefault(t *avroType, raw interface{}) (interface{}, error) {
+	switch t.kind {
+	case avroNull:
+		if raw != nil {
+			return nil, fmt.Errorf("expected null")
+		}
+		return nil, nil
+	case avroBoolean:
+		if b, ok := raw.(bool); ok {
+			return b, nil
+		}
+	case avroInt, avroLong:
+		if n, ok := raw.(json.Number); ok {
+			return n.Int64()
+		}
+	case avroFloat, avroDouble:
+		if n, ok := raw.(json.Number); ok {
+			return n.Float64()
+		}
+	case avroString, avroEnum:
+		if s, ok := raw.(string); ok {
+			return s, nil
+		}
+	case avroBytes, avroFixed:
+		// Bytes defaults are strings of code points 0-255
+		if s, ok := raw.(string); ok {
+			data := make([]byte, 0, len(s))
+			for _, r := range s {
+				if r > 0xff {
+					return nil, fmt.Errorf("byte default contains %q", r)
+				}
+				data = append(data, byte(r))
+			}
+			return data, nil
+		}
+	case avroArray:
+		if items, ok := raw.([]interface{}); ok {
+			tree := make([]interface{}, len(items))
+			for i, item := range items {
+				v, err := avroDefault(t.items, item)
+				if err != nil {
+					return nil, err
+				}
+				tree[i] = v
+			}
+			return tree, nil
+		}
+	case avroMap:
+		if obj, ok := raw.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(obj))
+			for k := range obj {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			m := make(mapValue, len(keys))
+			for i, k := range keys {
+				v, err := avroDefault(t.values, obj[k])
+				if err != nil {
+					return nil, err
+				}
+				m[i] = mapEntry{Key: k, Value: v}
+			}
+			return m, nil
+		}
+	case avroRecord:
+		if obj, ok := raw.(map[string]interface{}); ok {
+			m := make(mapValue, 0, len(t.fields))
+			for _, f := range t.fields {
+				fieldRaw, present := obj[f.name]
+				if !present {
+					if !f.hasDefault {
+						return nil, fmt.Errorf("missing field %s", f.name)
+					}
+					m = append(m, mapEntry{Key: f.name, Value: f.def})
+					continue
+				}
+				v, err := avroDefault(f.typ, fieldRaw)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, mapEntry{Key: f.name, Value: v})
+			}
+			return m, nil
+		}
+	case avroUnion:
+		// Union defaults belong to the first branch
+		return avroDefault(t.branches[0], raw)
+	}
+	return nil, fmt.Errorf("default %v does not match type %s", raw, t.kind)
+}
+
+// canonical returns the Parsing Canonical Form of t
+func (t *avroType) canonical() string {
+	var b bytes.Buffer
+	t.writeCanonical(&b, make(map[*avroType]bool))
+	return b.String()
+}
+
+// writeCanonical writes t, referring to named types already written by name
+func (t *avroType) writeCanonical(b *bytes.Buffer, written map[*avroType]bool) {
+	quote := func(s string) {
+		data, _ := json.Marshal(s)
+		b.Write(data)
+	}
+
+	if t.name != "" {
+		if written[t] {
+			quote(t.name)
+			return
+		}
+		written[t] = true
+	}
+
+	switch t.kind {
+	case avroRecord:
+		b.WriteString(`{"name":`)
+		quote(t.name)
+		b.WriteString(`,"type":"record","fields":[`)
+		for i, f := range t.fields {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(`{"name":`)
+			quote(f.name)
+			b.WriteString(`,"type":`)
+			f.typ.writeCanonical(b, written)
+			b.WriteByte('}')
+		}
+		b.WriteString(`]}`)
+	case avroEnum:
+		b.WriteString(`{"name":`)
+		quote(t.name)
+		b.WriteString(`,"type":"enum","symbols":[`)
+		for i, s := range t.symbols {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			quote(s)
+		}
+		b.WriteString(`]}`)
+	case avroFixed:
+		b.WriteString(`{"name":`)
+		quote(t.name)
+		fmt.Fprintf(b, `,"type":"fixed","size":%d}`, t.size)
+	case avroArray:
+		b.WriteString(`{"type":"array","items":`)
+		t.items.writeCanonical(b, written)
+		b.WriteByte('}')
+	case avroMap:
+		b.WriteString(`{"type":"map","values":`)
+		t.values.writeCanonical(b, written)
+		b.WriteByte('}')
+	case avroUnion:
+		b.WriteByte('[')
+		for i, branch := range t.branches {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			branch.writeCanonical(b, written)
+		}
+		b.WriteByte(']')
+	default:
+		quote(string(t.kind))
+	}
+}
+
+// avroFingerprintEmpty is the CRC-64-AVRO of no data
+const avroFingerprintEmpty = 0xc15d213aa4d7a795
+
+// avroFingerprintTable is the lookup table of CRC-64-AVRO
+var avroFingerprintTable = func() [256]uint64 {
+	var table [256]uint64
+	for i := range table {
+		fp := uint64(i)
+		for j := 0; j < 8; j++ {
+			fp = (fp >> 1) ^ (avroFingerprintEmpty & -(fp & 1))
+		}
+		table[i] = fp
+	}
+	return table
+}()
+
+// avroFingerprint computes the CRC-64-AVRO (Rabin) fingerprint of data
+func avroFingerprint(data []byte) uint64 {
+	fp := uint64(avroFingerprintEmpty)
+	for _, c := range data {
+		fp = (fp >> 8) ^ avroFingerprintTable[byte(fp)^c]
+	}
+	return fp
+}
+
+// qualify returns the full name of name within namespace
+func qualify(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// qualifyAll qualifies every name
+func qualifyAll(names []string, namespace string) []string {
+	for i, name := range names {
+		names[i] = qualify(name, namespace)
+	}
+	return names
+}
+
+// stringList returns the strings of a JSON array
+func stringList(raw interface{}) []string {
+	items, _ := raw.([]interface{})
+	var list []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// indexOf returns the index of s in list, or -1
+func indexOf(list []string, s string) int {
+	for i, item := range list {
+		if item == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,182 @@
+package codec
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const orderSchemaV1 = `{
+	"type": "record", "name": "Order", "namespace": "shop",
+	"doc": "an order",
+	"fields": [
+		{"name": "id", "type": "string"},
+		{"name": "qty", "type": "int"},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "PAID", "SHIPPED"]}},
+		{"name": "note", "type": ["null", "string"], "default": null},
+		{"name": "legacy", "type": "boolean", "default": false}
+	]
+}`
+
+const orderSchemaV2 = `{
+	"type": "record", "name": "Order", "namespace": "shop",
+	"fields": [
+		{"name": "id", "type": "string"},
+		{"name": "quantity", "type": "long", "aliases": ["qty"]},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["NEW", "PAID", "UNKNOWN"], "default": "UNKNOWN"}},
+		{"name": "note", "type": ["null", "string"], "default": null},
+		{"name": "tags", "type": {"type": "array", "items": "string"}, "default": ["web"]},
+		{"name": "price", "type": "double", "default": 9.5}
+	]
+}`
+
+type orderV1 struct {
+	ID     string  `avro:"id"`
+	Qty    int     `avro:"qty"`
+	Status string  `avro:"status"`
+	Note   *string `avro:"note"`
+}
+
+type orderV2 struct {
+	ID       string   `json:"id"`
+	Quantity int64    `json:"quantity"`
+	Status   string   `json:"status"`
+	Note     *string  `json:"note"`
+	Tags     []string `json:"tags"`
+	Price    float64  `json:"price"`
+}
+
+func TestAvroSchema_CanonicalForm(t *testing.T) {
+	s, err := ParseAvroSchema(`"int"`)
+	if err != nil {
+		t.Fatalf("ParseAvroSchema failed: %v", err)
+	}
+	// CRC-64-AVRO of "int" from the Avro reference implementations
+	if got := s.Fingerprint(); got != 0x7275d51a3f395c8f {
+		t.Errorf("Fingerprint = %016x", got)
+	}
+
+	s = MustParseAvroSchema(orderSchemaV1)
+	want := `{"name":"shop.Order","type":"record","fields":[{"name":"id","type":"string"},{"name":"qty","type":"int"},` +
+		`{"name":"status","type":{"name":"shop.Status","type":"enum","symbols":["NEW","PAID","SHIPPED"]}},` +
+		`{"name":"note","type":["null","string"]},{"name":"legacy","type":"boolean"}]}`
+	if s.String() != want {
+		t.Errorf("canonical form = %s, want %s", s, want)
+	}
+
+	for _, bad := range []string{`"nope"`, `{"type": "record", "name": "R"}`, `[]`, `{"type": "record", "name": "R", "fields": [{"name": "a", "type": "int", "default": "x"}]}`} {
+		if _, err := ParseAvroSchema(bad); err == nil {
+			t.Errorf("ParseAvroSchema(%s) expected error", bad)
+		}
+	}
+}
+
+func TestAvro_Encoding(t *testing.T) {
+	// Example from the Avro specification
+	schema := MustParseAvroSchema(`{"type": "record", "name": "test", "fields": [{"name": "a", "type": "long"}, {"name": "b", "type": "string"}]}`)
+	c, err := NewAvro(&AvroConfig{Schema: schema})
+	if err != nil {
+		t.Fatalf("NewAvro failed: %v", err)
+	}
+
+	data, err := c.Marshal(map[string]interface{}{"a": 27, "b": "foo"})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if got := hex.EncodeToString(data[10:]); got != "3606666f6f" {
+		t.Errorf("body = %s, want 3606666f6f", got)
+	}
+	if data[0] != 0xc3 || data[1] != 0x01 {
+		t.Errorf("header = %x", data[:2])
+	}
+}
+
+func TestAvro_RoundTrip(t *testing.T) {
+	c, err := NewAvro(&AvroConfig{Schema: MustParseAvroSchema(orderSchemaV1)})
+	if err != nil {
+		t.Fatalf("NewAvro failed: %v", err)
+	}
+	note := "gift"
+	for _, in := range []orderV1{
+		{ID: "a-1", Qty: -3, Status: "PAID", Note: &note},
+		{ID: "a-2", Qty: 1 << 20, Status: "NEW"},
+	} {
+		data, err := c.Marshal(in)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		var out orderV1
+		if err := c.Unmarshal(data, &out); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if !reflect.DeepEqual(in, out) {
+			t.Errorf("round trip = %+v, want %+v", out, in)
+		}
+	}
+
+	if _, err := c.Marshal(orderV1{ID: "a-3", Status: "LOST"}); err == nil {
+		t.Error("expected error for an unknown enum symbol")
+	}
+}
+
+func TestAvro_SchemaEvolution(t *testing.T) {
+	v1 := MustParseAvroSchema(orderSchemaV1)
+	v2 := MustParseAvroSchema(orderSchemaV2)
+	producer, _ := NewAvro(&AvroConfig{Schema: v1})
+	consumer, _ := NewAvro(&AvroConfig{Schema: v2, Registry: NewAvroSchemas(v1)})
+
+	data, err := producer.Marshal(orderV1{ID: "a-1", Qty: 7, Status: "SHIPPED"})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var got orderV2
+	if err := consumer.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	want := orderV2{ID: "a-1", Quantity: 7, Status: "UNKNOWN", Tags: []string{"web"}, Price: 9.5}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resolved = %+v, want %+v", got, want)
+	}
+
+	// Without the writer schema the payload cannot be read
+	strict, _ := NewAvro(&AvroConfig{Schema: v2})
+	if err := strict.Unmarshal(data, &got); err == nil || !strings.Contains(err.Error(), "unknown schema") {
+		t.Errorf("expected unknown schema error, got %v", err)
+	}
+
+	// Reader fields without defaults cannot be filled in
+	v3 := MustParseAvroSchema(`{"type": "record", "name": "Order", "namespace": "shop", "fields": [{"name": "id", "type": "string"}, {"name": "region", "type": "string"}]}`)
+	incompatible, _ := NewAvro(&AvroConfig{Schema: v3, Registry: NewAvroSchemas(v1)})
+	if err := incompatible.Unmarshal(data, &got); err == nil {
+		t.Error("expected error for a reader field without default")
+	}
+}
+
+type failingSchemaRegistry struct{}
+
+func (failingSchemaRegistry) LookupSchema(uint64) (*AvroSchema, error) {
+	return nil, errors.New("registry unavailable")
+}
+
+func TestAvro_Errors(t *testing.T) {
+	if _, err := NewAvro(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+	if _, err := NewAvro(&AvroConfig{}); err == nil {
+		t.Error("expected error without schema")
+	}
+
+	c, _ := NewAvro(&AvroConfig{Schema: MustParseAvroSchema(orderSchemaV2), Registry: failingSchemaRegistry{}, ContentType: "application/vnd.orders+avro"})
+	if c.ContentType() != "application/vnd.orders+avro" {
+		t.Errorf("ContentType = %s", c.ContentType())
+	}
+	var v interface{}
+	for _, data := range []string{"", "3606666f6f", "c30100000000000000003606"} {
+		raw, _ := hex.DecodeString(data)
+		if err := c.Unmarshal(raw, &v); err == nil {
+			t.Errorf("Unmarshal(%s) expected error", data)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// CBOR encodes values as CBOR (RFC 8949). Struct fields are named by their
+// cbor tag, then their json tag. Tags on decoded items are ignored, and
+// undefined decodes as nil.
+type CBOR struct{}
+
+// ContentType returns application/cbor
+func (CBOR) ContentType() string {
+	return "application/cbor"
+}
+
+// Marshal encodes v as CBOR
+func (CBOR) Marshal(v interface{}) ([]byte, error) {
+	tree, err := toTree(reflect.ValueOf(v), "cbor")
+	if err != nil {
+		return nil, err
+	}
+	return appendCBOR(nil, tree)
+}
+
+// Unmarshal decodes CBOR into v, which must be a non-nil pointer
+func (CBOR) Unmarshal(data []byte, v interface{}) error {
+	r := &cborReader{data: data}
+	tree, err := r.read(0)
+	if err != nil {
+		return err
+	}
+	if r.pos != len(data) {
+		return fmt.Errorf("cbor: %d trailing bytes", len(data)-r.pos)
+	}
+	return decodeInto(tree, v, "cbor")
+}
+
+// CBOR major types
+const (
+	cborUint   = 0 << 5
+	cborNegInt = 1 << 5
+	cborBytes  = 2 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+	cborSimple = 7 << 5
+)
+
+// cborBreak ends an indefinite-length item
+const cborBreak = 0xff
+
+// appendCBOR appends the encoding of a tree to b
+func appendCBOR(b []byte, tree interface{}) ([]byte, error) {
+	switch t := tree.(type) {
+	case nil:
+		return append(b, cborSimple|22), nil
+	case bool:
+		if t {
+			return append(b, cborSimple|21), nil
+		}
+		return append(b, cborSimple|20), nil
+
+	case int64:
+		if t < 0 {
+			return appendCBORHead(b, cborNegInt, uint64(-1-t)), nil
+		}
+		return appendCBORHead(b, cborUint, uint64(t)), nil
+	case uint64:
+		return appendCBORHead(b, cborUint, t), nil
+	case float32:
+		return binary.BigEndian.AppendUint32(append(b, cborSimple|26), math.Float32bits(t)), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, cborSimple|27), math.Float64bits(t)), nil
+
+	case string:
+		return append(appendCBORHead(b, cborText, uint64(len(t))), t...), nil
+	case []byte:
+		return append(appendCBORHead(b, cborBytes, uint64(len(t))), t...), nil
+
+	case []interface{}:
+		b = appendCBORHead(b, cborArray, uint64(len(t)))
+		var err error
+		for _, item := range t {
+			if b, err = appendCBOR(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case mapValue:
+		b = appendCBORHead(b, cborMap, uint64(len(t)))
+		var err error
+		for _, entry := range t {
+			if b, err = appendCBOR(b, entry.Key); err != nil {
+				return nil, err
+			}
+			if b, err = appendCBOR(b, entry.Value); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+
+	default:
+		return nil, fmt.Errorf("cbor: unsupported value %T", tree)
+	}
+}
+
+// appendCBORHead appends the initial byte of a major type with its argument
+// in the smallest encoding
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), n)
+	}
+}
+
+// cborReader reads a tree from CBOR data
+type cborReader struct {
+	data []byte
+	pos  int
+}
+
+// next returns the next n bytes
+func (r *cborReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.data)-r.pos < n {
+		return nil, fmt.Errorf("cbor: unexpected end of data")
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// head reads an initial byte and its argument. Indefinite lengths are
+// reported with indefinite set.
+func (r *cborReader) head() (major byte, info byte, arg uint64, indefinite bool, err error) {
+	b, err := r.next(1)
+	if err != nil {
+		return 0, 0, 0, false, err
+	}
+	major, info = b[0]&0xe0, b[0]&0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), false, nil
+	case info <= 27:
+		b, err := r.next(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, false, err
+		}
+		return major, info, beUint(b), false, nil
+	case info == 31:
+		return major, info, 0, true, nil
+	default:
+		return 0, 0, 0, false, fmt.Errorf("cbor: reserved additional information %d", info)
+	}
+}
+
+// length converts an argument to a length no larger than the rest of the
+// payload
+func (r *cborReader) length(arg uint64, per int) (int, error) {
+	if arg > uint64(len(r.data)-r.pos)/uint64(per) {
+		return 0, fmt.Errorf("cbor: length %d exceeds the payload", arg)
+	}
+	return int(arg), nil
+}
+
+// read reads one item
+func (r *cborReader) read(depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, fmt.Errorf("cbor: nesting too deep")
+	}
+	major, info, arg, indefinite, err := r.head()
+	if err != nil {
+		return nil, err
+	}
+	if indefinite {
+		return r.readIndefinite(major, depth)
+	}
+
+	switch major {
+	case cborUint:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("cbor: negative integer -1-%d overflows int64", arg)
+		}
+		return -1 - int64(arg), nil
+
+	case cborBytes, cborText:
+		n, err := r.length(arg, 1)
+		if err != nil {
+			return nil, err
+		}
+		b, err := r.next(n)
+		if err != nil {
+			return nil, err
+		}
+		if major == cborText {
+			return string(b), nil
+		}
+		return append([]byte(nil), b...), nil
+
+	case cborArray:
+		n, err := r.length(arg, 1)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = r.read(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	case cborMap:
+		n, err := r.length(arg, 2)
+		if err != nil {
+			return nil, err
+		}
+		m := make(mapValue, n)
+		for i := range m {
+			if m[i], err = r.readEntry(depth); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+
+	case cborTag:
+		return r.read(depth + 1)
+
+	default:
+		return r.simple(info, arg)
+	}
+}
+
+// readIndefinite reads the items of an indefinite-length string, array or
+// map up to the break
+func (r *cborReader) readIndefinite(major byte, depth int) (interface{}, error) {
+	switch major {
+	case cborBytes, cborText:
+		var chunks []byte
+		for !r.atBreak() {
+			chunkMajor, _, arg, indefinite, err := r.head()
+			if err != nil {
+				return nil, err
+			}
+			if chunkMajor != major || indefinite {
+				return nil, fmt.Errorf("cbor: invalid chunk in indefinite-length string")
+			}
+			n, err := r.length(arg, 1)
+			if err != nil {
+				return nil, err
+			}
+			chunk, err := r.next(n)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, chunk...)
+		}
+		if major == cborText {
+			return string(chunks), nil
+		}
+		if chunks == nil {
+			chunks = []byte{}
+		}
+		return chunks, nil
+
+	case cborArray:
+		items := []interface{}{}
+		for !r.atBreak() {
+			item, err := r.read(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case cborMap:
+		m := mapValue{}
+		for !r.atBreak() {
+			entry, err := r.readEntry(depth)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, entry)
+		}
+		return m, nil
+
+	default:
+		return nil, fmt.Errorf("cbor: unexpected indefinite length for major type %d", major>>5)
+	}
+}
+
+// atBreak consumes a break if it is next. A missing break is reported by the
+// following read at the end of the data.
+func (r *cborReader) atBreak() bool {
+	if r.pos < len(r.data) && r.data[r.pos] == cborBreak {
+		r.pos++
+		return true
+	}
+	return false
+}
+
+// readEntry reads one key and value of a map
+func (r *cborReader) readEntry(depth int) (mapEntry, error) {
+	key, err := r.read(depth + 1)
+	if err != nil {
+		return mapEntry{}, err
+	}
+	value, err := r.read(depth + 1)
+	if err != nil {
+		return mapEntry{}, err
+	}
+	return mapEntry{Key: key, Value: value}, nil
+}
+
+// simple decodes a simple value or float
+func (r *cborReader) simple(info byte, arg uint64) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return halfToFloat(uint16(arg)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(arg))), nil
+	case 27:
+		return math.Float64frombits(arg), nil
+	default:
+		return nil, fmt.Errorf("cbor: unsupported simple value %d", arg)
+	}
+}
+
+// halfToFloat converts an IEEE 754 half-precision float
+func halfToFloat(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			return math.Inf(int(sign))
+		}
+		return math.NaN()
+	default:
+		return sign * math.Ldexp(mant+1024, exp-25)
+	}
+}
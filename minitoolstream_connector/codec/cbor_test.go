@@ -0,0 +1,108 @@
+package codec
+
+import (
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestCBOR_Vectors(t *testing.T) {
+	// Vectors from RFC 8949 appendix A
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "f6"},
+		{false, "f4"},
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{1000, "1903e8"},
+		{uint64(18446744073709551615), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-1000, "3903e7"},
+		{1.1, "fb3ff199999999999a"},
+		{"IETF", "6449455446"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{[]int{1, 2, 3}, "83010203"},
+		{map[string][]int{"a": {1}}, "a161618101"},
+	}
+	for _, tt := range tests {
+		data, err := CBOR{}.Marshal(tt.value)
+		if err != nil {
+			t.Errorf("Marshal(%#v) failed: %v", tt.value, err)
+			continue
+		}
+		if got := hex.EncodeToString(data); got != tt.want {
+			t.Errorf("Marshal(%#v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCBOR_Decode(t *testing.T) {
+	tests := map[string]interface{}{
+		"f7":         nil,
+		"f93c00":     1.0,
+		"f9c400":     -4.0,
+		"f90001":     5.960464477539063e-8,
+		"f97c00":     math.Inf(1),
+		"fa47c35000": 100000.0,
+		"c074323031332d30332d32315432303a30343a30305a": "2013-03-21T20:04:00Z",
+		"5f42010243030405ff":                           []byte{1, 2, 3, 4, 5},
+		"7f657374726561646d696e67ff":                   "streaming",
+		"9f018202039f0405ffff":                         []interface{}{int64(1), []interface{}{int64(2), int64(3)}, []interface{}{int64(4), int64(5)}},
+		"bf61610161629f0203ffff": map[string]interface{}{
+			"a": int64(1),
+			"b": []interface{}{int64(2), int64(3)},
+		},
+		"a201020304": map[interface{}]interface{}{int64(1): int64(2), int64(3): int64(4)},
+	}
+	for data, want := range tests {
+		raw, _ := hex.DecodeString(data)
+		var got interface{}
+		if err := (CBOR{}).Unmarshal(raw, &got); err != nil {
+			t.Errorf("Unmarshal(%s) failed: %v", data, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Unmarshal(%s) = %#v, want %#v", data, got, want)
+		}
+	}
+}
+
+func TestCBOR_RoundTrip(t *testing.T) {
+	type Reading struct {
+		Sensor string   `cbor:"s"`
+		Temp   float32  `json:"temp"`
+		Values []int16  `cbor:"v"`
+		Flags  []bool   `cbor:"f,omitempty"`
+		Parent *Reading `cbor:"p"`
+	}
+	in := Reading{Sensor: "t-1", Temp: -3.5, Values: []int16{-300, 7}, Parent: &Reading{Sensor: "hub"}}
+
+	data, err := CBOR{}.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var out Reading
+	if err := (CBOR{}).Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+}
+
+func TestCBOR_Errors(t *testing.T) {
+	var v interface{}
+	for _, data := range []string{"", "18", "62ff", "9f01", "3bffffffffffffffff", "1c", "5f01ff", "ff", "0000"} {
+		raw, _ := hex.DecodeString(data)
+		if err := (CBOR{}).Unmarshal(raw, &v); err == nil {
+			t.Errorf("Unmarshal(%s) expected error", data)
+		}
+	}
+	if _, err := Default.Lookup("application/cbor"); err != nil {
+		t.Errorf("CBOR not registered in Default: %v", err)
+	}
+}
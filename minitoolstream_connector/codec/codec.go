@@ -0,0 +1,133 @@
+// Package codec maps content types to codecs, so typed handlers decode
+// subjects carrying several payload formats without switching on the
+// content-type header themselves.
+package codec
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// DefaultContentType is assumed for messages without a content-type header
+const DefaultContentType = "application/json"
+
+// Registry maps content types to codecs. It is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	codecs map[string]domain.Codec
+}
+
+// NewRegistry creates a registry holding codecs under their content types
+func NewRegistry(codecs ...domain.Codec) *Registry {
+	r := &Registry{codecs: make(map[string]domain.Codec)}
+	for _, c := range codecs {
+		r.Register(c)
+	}
+	return r
+}
+
+// Default is the registry used by the package-level functions. It holds the
+// JSON, protobuf, MessagePack and CBOR codecs.
+var Default = newDefault()
+
+// newDefault creates the Default registry
+func newDefault() *Registry {
+	r := NewRegistry(JSON{}, Proto{}, CBOR{})
+	r.Register(MsgPack{}, "application/x-msgpack")
+	return r
+}
+
+// Register adds a codec under its content type and any aliases, replacing
+// codecs registered under the same types
+func (r *Registry) Register(c domain.Codec, aliases ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, contentType := range append([]string{c.ContentType()}, aliases...) {
+		r.codecs[mediaType(contentType)] = c
+	}
+}
+
+// Lookup returns the codec of a content type. Parameters such as charset are
+// ignored, an empty content type means DefaultContentType and structured
+// syntax suffixes fall back to their base format, e.g. application/vnd.x+json
+// to application/json.
+func (r *Registry) Lookup(contentType string) (domain.Codec, error) {
+	if contentType == "" {
+		contentType = DefaultContentType
+	}
+	mt := mediaType(contentType)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.codecs[mt]; ok {
+		return c, nil
+	}
+	if i := strings.LastIndexByte(mt, '+'); i >= 0 {
+		if c, ok := r.codecs["application/"+mt[i+1:]]; ok {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("no codec registered for content-type %q", contentType)
+}
+
+// Decode decodes the payload of msg into v with the codec of its content type.
+// Errors match domain.ErrDecode.
+func (r *Registry) Decode(msg *domain.ReceivedMessage, v interface{}) error {
+	c, err := r.Lookup(msg.Headers["content-type"])
+	if err != nil {
+		return fmt.Errorf("%w: sequence %d: %v", domain.ErrDecode, msg.Sequence, err)
+	}
+	if err := c.Unmarshal(msg.Data, v); err != nil {
+		return fmt.Errorf("%w: sequence %d into %T: %v", domain.ErrDecode, msg.Sequence, v, err)
+	}
+	return nil
+}
+
+// Encode encodes v with the codec of contentType
+func (r *Registry) Encode(contentType string, v interface{}) ([]byte, error) {
+	c, err := r.Lookup(contentType)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %T as %s: %w", v, c.ContentType(), err)
+	}
+	return data, nil
+}
+
+// Handler returns a handler decoding each payload into a T with the codecs of
+// r before calling fn. Undecodable messages fail with an error matching
+// domain.ErrDecode and do not reach fn.
+func Handler[T any](r *Registry, fn func(ctx context.Context, v T, msg *domain.ReceivedMessage) error) domain.MessageHandler {
+	return domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		var v T
+		if err := r.Decode(msg, &v); err != nil {
+			return err
+		}
+		return fn(ctx, v, msg)
+	})
+}
+
+// Register adds a codec to the Default registry
+func Register(c domain.Codec, aliases ...string) {
+	Default.Register(c, aliases...)
+}
+
+// Lookup returns the codec of a content type from the Default registry
+func Lookup(contentType string) (domain.Codec, error) {
+	return Default.Lookup(contentType)
+}
+
+// mediaType normalizes a content type to its lower-case media type
+func mediaType(contentType string) string {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mt
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
@@ -0,0 +1,139 @@
+package codec
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// upperCodec is a user codec storing strings in upper case
+type upperCodec struct{}
+
+func (upperCodec) ContentType() string { return "text/upper" }
+
+func (upperCodec) Marshal(v interface{}) ([]byte, error) {
+	return []byte(strings.ToUpper(*v.(*string))), nil
+}
+
+func (upperCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*string) = string(data)
+	return nil
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	r := NewRegistry(JSON{}, Proto{})
+	r.Register(upperCodec{}, "text/x-upper")
+
+	tests := map[string]string{
+		"":                                "application/json",
+		"application/json":                "application/json",
+		"Application/JSON; charset=utf-8": "application/json",
+		"application/vnd.orders.v1+json":  "application/json",
+		"application/x-protobuf":          "application/x-protobuf",
+		"text/upper":                      "text/upper",
+		"text/x-upper":                    "text/upper",
+	}
+	for contentType, want := range tests {
+		c, err := r.Lookup(contentType)
+		if err != nil {
+			t.Errorf("Lookup(%q) failed: %v", contentType, err)
+			continue
+		}
+		if c.ContentType() != want {
+			t.Errorf("Lookup(%q) = %s, want %s", contentType, c.ContentType(), want)
+		}
+	}
+	if _, err := r.Lookup("image/png"); err == nil {
+		t.Error("expected error for an unregistered content type")
+	}
+}
+
+func TestRegistry_MixedFormats(t *testing.T) {
+	r := NewRegistry(JSON{}, upperCodec{})
+
+	var got []string
+	handler := Handler(r, func(ctx context.Context, v string, msg *domain.ReceivedMessage) error {
+		got = append(got, v)
+		return nil
+	})
+	messages := []*domain.ReceivedMessage{
+		{Sequence: 1, Data: []byte("ADA"), Headers: map[string]string{"content-type": "text/upper"}},
+		{Sequence: 2, Data: []byte(`"grace"`), Headers: map[string]string{"content-type": "application/json"}},
+		{Sequence: 3, Data: []byte(`"linus"`)},
+	}
+	for _, msg := range messages {
+		if err := handler.Handle(context.Background(), msg); err != nil {
+			t.Errorf("sequence %d: Handle failed: %v", msg.Sequence, err)
+		}
+	}
+	if len(got) != 3 || got[0] != "ADA" || got[1] != "grace" || got[2] != "linus" {
+		t.Errorf("expected every format to decode, got %q", got)
+	}
+
+	for _, msg := range []*domain.ReceivedMessage{
+		{Data: []byte("x"), Headers: map[string]string{"content-type": "image/png"}},
+		{Data: []byte("{"), Headers: map[string]string{"content-type": "application/json"}},
+	} {
+		if err := handler.Handle(context.Background(), msg); !errors.Is(err, domain.ErrDecode) {
+			t.Errorf("expected ErrDecode, got %v", err)
+		}
+	}
+}
+
+func TestHandler_Proto(t *testing.T) {
+	data, err := Default.Encode("application/x-protobuf", wrapperspb.String("ada"))
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	var got string
+	handler := Handler(Default, func(ctx context.Context, v *wrapperspb.StringValue, msg *domain.ReceivedMessage) error {
+		got = v.GetValue()
+		return nil
+	})
+	msg := &domain.ReceivedMessage{Data: data, Headers: map[string]string{"content-type": "application/x-protobuf"}}
+	if err := handler.Handle(context.Background(), msg); err != nil || got != "ada" {
+		t.Errorf("expected the protobuf message to decode, got %q (%v)", got, err)
+	}
+}
+
+func TestProto(t *testing.T) {
+	if _, err := (Proto{}).Marshal("not a message"); err == nil {
+		t.Error("expected error for a non-proto value")
+	}
+
+	data, _ := proto.Marshal(wrapperspb.Int64(42))
+	var direct wrapperspb.Int64Value
+	if err := (Proto{}).Unmarshal(data, &direct); err != nil || direct.GetValue() != 42 {
+		t.Errorf("expected to decode into a message, got %v (%v)", direct.GetValue(), err)
+	}
+	var ptr *wrapperspb.Int64Value
+	if err := (Proto{}).Unmarshal(data, &ptr); err != nil || ptr.GetValue() != 42 {
+		t.Errorf("expected to decode into a pointer to a message, got %v (%v)", ptr.GetValue(), err)
+	}
+	var s string
+	if err := (Proto{}).Unmarshal(data, &s); err == nil {
+		t.Error("expected error decoding into a non-proto value")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(upperCodec{})
+	defer func() {
+		Default.mu.Lock()
+		delete(Default.codecs, "text/upper")
+		Default.mu.Unlock()
+	}()
+
+	v := "shout"
+	data, err := Default.Encode("text/upper", &v)
+	if err != nil || string(data) != "SHOUT" {
+		t.Errorf("expected the registered codec to encode, got %q (%v)", data, err)
+	}
+}
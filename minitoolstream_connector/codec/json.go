@@ -0,0 +1,21 @@
+package codec
+
+import "encoding/json"
+
+// JSON encodes values with encoding/json
+type JSON struct{}
+
+// ContentType returns application/json
+func (JSON) ContentType() string {
+	return "application/json"
+}
+
+// Marshal encodes v as JSON
+func (JSON) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes JSON into v
+func (JSON) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,330 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// MsgPack encodes values as MessagePack. Struct fields are named by their
+// msgpack tag, then their json tag. Extension types are not supported.
+type MsgPack struct{}
+
+// ContentType returns application/msgpack
+func (MsgPack) ContentType() string {
+	return "application/msgpack"
+}
+
+// Marshal encodes v as MessagePack
+func (MsgPack) Marshal(v interface{}) ([]byte, error) {
+	tree, err := toTree(reflect.ValueOf(v), "msgpack")
+	if err != nil {
+		return nil, err
+	}
+	return appendMsgPack(nil, tree)
+}
+
+// Unmarshal decodes MessagePack into v, which must be a non-nil pointer
+func (MsgPack) Unmarshal(data []byte, v interface{}) error {
+	r := &msgpackReader{data: data}
+	tree, err := r.read(0)
+	if err != nil {
+		return err
+	}
+	if r.pos != len(data) {
+		return fmt.Errorf("msgpack: %d trailing bytes", len(data)-r.pos)
+	}
+	return decodeInto(tree, v, "msgpack")
+}
+
+// appendMsgPack appends the encoding of a tree to b
+func appendMsgPack(b []byte, tree interface{}) ([]byte, error) {
+	switch t := tree.(type) {
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		if t {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+
+	case int64:
+		switch {
+		case t >= 0:
+			return appendMsgPackUint(b, uint64(t)), nil
+		case t >= -32:
+			return append(b, byte(t)), nil
+		case t >= math.MinInt8:
+			return append(b, 0xd0, byte(t)), nil
+		case t >= math.MinInt16:
+			return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(t)), nil
+		case t >= math.MinInt32:
+			return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(t)), nil
+		default:
+			return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(t)), nil
+		}
+	case uint64:
+		return appendMsgPackUint(b, t), nil
+	case float32:
+		return binary.BigEndian.AppendUint32(append(b, 0xca), math.Float32bits(t)), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(t)), nil
+
+	case string:
+		n := len(t)
+		switch {
+		case n < 32:
+			b = append(b, 0xa0|byte(n))
+		case n <= math.MaxUint8:
+			b = append(b, 0xd9, byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+		}
+		return append(b, t...), nil
+	case []byte:
+		n := len(t)
+		switch {
+		case n <= math.MaxUint8:
+			b = append(b, 0xc4, byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+		}
+		return append(b, t...), nil
+
+	case []interface{}:
+		b = appendMsgPackHeader(b, len(t), 0x90, 0xdc, 0xdd)
+		var err error
+		for _, item := range t {
+			if b, err = appendMsgPack(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case mapValue:
+		b = appendMsgPackHeader(b, len(t), 0x80, 0xde, 0xdf)
+		var err error
+		for _, entry := range t {
+			if b, err = appendMsgPack(b, entry.Key); err != nil {
+				return nil, err
+			}
+			if b, err = appendMsgPack(b, entry.Value); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported value %T", tree)
+	}
+}
+
+// appendMsgPackUint appends an unsigned integer in its smallest encoding
+func appendMsgPackUint(b []byte, n uint64) []byte {
+	switch {
+	case n < 128:
+		return append(b, byte(n))
+	case n <= math.MaxUint8:
+		return append(b, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), n)
+	}
+}
+
+// appendMsgPackHeader appends an array or map header of n items
+func appendMsgPackHeader(b []byte, n int, fix, code16, code32 byte) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, code16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, code32), uint32(n))
+	}
+}
+
+// msgpackReader reads a tree from MessagePack data
+type msgpackReader struct {
+	data []byte
+	pos  int
+}
+
+// next returns the next n bytes
+func (r *msgpackReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.data)-r.pos < n {
+		return nil, fmt.Errorf("msgpack: unexpected end of data")
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// length reads a big-endian length of size bytes
+func (r *msgpackReader) length(size int) (int, error) {
+	b, err := r.next(size)
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return int(b[0]), nil
+	case 2:
+		return int(binary.BigEndian.Uint16(b)), nil
+	default:
+		n := binary.BigEndian.Uint32(b)
+		if uint64(n) > uint64(len(r.data)) {
+			return 0, fmt.Errorf("msgpack: length %d exceeds the payload", n)
+		}
+		return int(n), nil
+	}
+}
+
+// read reads one value
+func (r *msgpackReader) read(depth int) (interface{}, error) {
+	if depth > maxDecodeDepth {
+		return nil, fmt.Errorf("msgpack: nesting too deep")
+	}
+	b, err := r.next(1)
+	if err != nil {
+		return nil, err
+	}
+	code := b[0]
+
+	switch {
+	case code <= 0x7f:
+		return int64(code), nil
+	case code >= 0xe0:
+		return int64(int8(code)), nil
+	case code&0xe0 == 0xa0:
+		return r.str(int(code & 0x1f))
+	case code&0xf0 == 0x90:
+		return r.array(int(code&0x0f), depth)
+	case code&0xf0 == 0x80:
+		return r.mapOf(int(code&0x0f), depth)
+	}
+
+	switch code {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		size := 1 << (code - 0xcc)
+		b, err := r.next(size)
+		if err != nil {
+			return nil, err
+		}
+		return beUint(b), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (code - 0xd0)
+		b, err := r.next(size)
+		if err != nil {
+			return nil, err
+		}
+		n := beUint(b)
+		shift := 64 - 8*uint(size)
+		return int64(n<<shift) >> shift, nil
+
+	case 0xca:
+		b, err := r.next(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 0xcb:
+		b, err := r.next(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+
+	case 0xd9, 0xda, 0xdb:
+		n, err := r.length(1 << (code - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return r.str(n)
+	case 0xc4, 0xc5, 0xc6:
+		n, err := r.length(1 << (code - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := r.next(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+
+	case 0xdc, 0xdd:
+		n, err := r.length(2 << (code - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return r.array(n, depth)
+	case 0xde, 0xdf:
+		n, err := r.length(2 << (code - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return r.mapOf(n, depth)
+
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported type code 0x%02x", code)
+	}
+}
+
+// str reads a string of n bytes
+func (r *msgpackReader) str(n int) (interface{}, error) {
+	b, err := r.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// array reads n values
+func (r *msgpackReader) array(n int, depth int) (interface{}, error) {
+	if n > len(r.data)-r.pos {
+		return nil, fmt.Errorf("msgpack: array of %d items exceeds the payload", n)
+	}
+	items := make([]interface{}, n)
+	for i := range items {
+		item, err := r.read(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	return items, nil
+}
+
+// mapOf reads n key-value pairs
+func (r *msgpackReader) mapOf(n int, depth int) (interface{}, error) {
+	if 2*n > len(r.data)-r.pos {
+		return nil, fmt.Errorf("msgpack: map of %d entries exceeds the payload", n)
+	}
+	m := make(mapValue, n)
+	for i := range m {
+		key, err := r.read(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := r.read(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		m[i] = mapEntry{Key: key, Value: value}
+	}
+	return m, nil
+}
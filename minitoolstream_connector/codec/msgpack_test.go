@@ -0,0 +1,110 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMsgPack_Vectors(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "c0"},
+		{true, "c3"},
+		{1, "01"},
+		{-1, "ff"},
+		{-33, "d0df"},
+		{200, "ccc8"},
+		{70000, "ce00011170"},
+		{-70000, "d2fffeee90"},
+		{1.5, "cb3ff8000000000000"},
+		{float32(1.5), "ca3fc00000"},
+		{"a", "a161"},
+		{strings.Repeat("x", 40), "d928" + strings.Repeat("78", 40)},
+		{[]byte{1, 2}, "c4020102"},
+		{[]int{1, 2}, "920102"},
+		{map[string]int{"a": 1}, "81a16101"},
+	}
+	for _, tt := range tests {
+		data, err := MsgPack{}.Marshal(tt.value)
+		if err != nil {
+			t.Errorf("Marshal(%#v) failed: %v", tt.value, err)
+			continue
+		}
+		if got := hex.EncodeToString(data); got != tt.want {
+			t.Errorf("Marshal(%#v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMsgPack_RoundTrip(t *testing.T) {
+	type Item struct {
+		SKU string `msgpack:"sku"`
+		Qty uint16 `json:"qty"`
+	}
+	type Order struct {
+		ID     int64             `msgpack:"id"`
+		Items  []Item            `msgpack:"items"`
+		Labels map[string]string `msgpack:"labels,omitempty"`
+		Raw    []byte            `msgpack:"raw"`
+		Note   *string           `msgpack:"note"`
+		Skip   string            `msgpack:"-"`
+	}
+	in := Order{ID: -42, Items: []Item{{SKU: "a", Qty: 3}}, Raw: []byte{0xff}, Skip: "x"}
+
+	data, err := MsgPack{}.Marshal(in)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var out Order
+	if err := (MsgPack{}).Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	in.Skip = ""
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+
+	var generic interface{}
+	if err := (MsgPack{}).Unmarshal(data, &generic); err != nil {
+		t.Fatalf("Unmarshal into interface{} failed: %v", err)
+	}
+	m := generic.(map[string]interface{})
+	if m["id"] != int64(-42) || m["note"] != nil {
+		t.Errorf("generic = %v", m)
+	}
+	if item := m["items"].([]interface{})[0].(map[string]interface{}); item["qty"] != int64(3) {
+		t.Errorf("generic item = %v", item)
+	}
+}
+
+func TestMsgPack_Errors(t *testing.T) {
+	var n int8
+	if err := (MsgPack{}).Unmarshal([]byte{0xcc, 0xc8}, &n); err == nil {
+		t.Error("expected overflow error decoding 200 into int8")
+	}
+	var v interface{}
+	for _, data := range []string{"", "a2", "dc0010", "0101", "d4", "dd7fffffff"} {
+		raw, _ := hex.DecodeString(data)
+		if err := (MsgPack{}).Unmarshal(raw, &v); err == nil {
+			t.Errorf("Unmarshal(%s) expected error", data)
+		}
+	}
+	if err := (MsgPack{}).Unmarshal([]byte{0x01}, v); err == nil {
+		t.Error("expected error for a non-pointer target")
+	}
+}
+
+func TestDefault_MsgPack(t *testing.T) {
+	data, err := Default.Encode("application/x-msgpack", map[string]string{"k": "v"})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if !bytes.Equal(data, []byte{0x81, 0xa1, 'k', 0xa1, 'v'}) {
+		t.Errorf("Encode = %x", data)
+	}
+}
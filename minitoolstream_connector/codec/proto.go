@@ -0,0 +1,51 @@
+package codec
+
+import (
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Proto encodes protobuf messages in the binary wire format
+type Proto struct{}
+
+// ContentType returns application/x-protobuf
+func (Proto) ContentType() string {
+	return "application/x-protobuf"
+}
+
+// Marshal encodes v, which must be a proto.Message
+func (Proto) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal decodes into v, which must be a proto.Message or a pointer to one
+func (Proto) Unmarshal(data []byte, v interface{}) error {
+	m, err := protoTarget(v)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// protoTarget returns the message to decode into. Typed handlers decode into
+// a pointer to their type, so a **Message is filled with a new message.
+func protoTarget(v interface{}) (proto.Message, error) {
+	if m, ok := v.(proto.Message); ok {
+		return m, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Pointer {
+		if _, ok := rv.Elem().Interface().(proto.Message); ok {
+			rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+			return rv.Elem().Interface().(proto.Message), nil
+		}
+	}
+	return nil, fmt.Errorf("%T is not a proto.Message", v)
+}
@@ -0,0 +1,110 @@
+package codec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// anyFullName is the full name of google.protobuf.Any
+var anyFullName = (&anypb.Any{}).ProtoReflect().Descriptor().FullName()
+
+// TypeRegistry dispatches protobuf messages to the handler registered for
+// their message type, so one subject can carry several types. The type is
+// read from the proto-type-url or proto-message header; payloads without
+// either, or of type google.protobuf.Any, are unpacked as an anypb.Any. It
+// implements domain.MessageHandler and is safe for concurrent use.
+type TypeRegistry struct {
+	mu       sync.RWMutex
+	handlers map[protoreflect.FullName]typeHandler
+	fallback domain.MessageHandler
+}
+
+// typeHandler decodes a payload and calls the registered function
+type typeHandler func(ctx context.Context, data []byte, msg *domain.ReceivedMessage) error
+
+// NewTypeRegistry creates an empty type registry
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{handlers: make(map[protoreflect.FullName]typeHandler)}
+}
+
+// HandleProto registers fn for messages of type T, replacing any handler
+// registered for it. T must be a generated message pointer such as
+// *orderspb.Order.
+func HandleProto[T proto.Message](r *TypeRegistry, fn func(ctx context.Context, m T, msg *domain.ReceivedMessage) error) {
+	var zero T
+	mt := zero.ProtoReflect().Type()
+	name := mt.Descriptor().FullName()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[name] = func(ctx context.Context, data []byte, msg *domain.ReceivedMessage) error {
+		m := mt.New().Interface()
+		if err := proto.Unmarshal(data, m); err != nil {
+			return fmt.Errorf("%w: sequence %d into %s: %v", domain.ErrDecode, msg.Sequence, name, err)
+		}
+		return fn(ctx, m.(T), msg)
+	}
+}
+
+// SetFallback sets the handler of messages whose type has no handler. Without
+// one they fail with an error matching domain.ErrDecode.
+func (r *TypeRegistry) SetFallback(handler domain.MessageHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = handler
+}
+
+// Handle dispatches msg to the handler of its type
+func (r *TypeRegistry) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	name, data, err := messageType(msg)
+	if err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	h, ok := r.handlers[name]
+	fallback := r.fallback
+	r.mu.RUnlock()
+
+	switch {
+	case ok:
+		return h(ctx, data, msg)
+	case fallback != nil:
+		return fallback.Handle(ctx, msg)
+	default:
+		return fmt.Errorf("%w: sequence %d: no handler for protobuf type %q", domain.ErrDecode, msg.Sequence, name)
+	}
+}
+
+// messageType returns the message type of msg and the encoded message
+func messageType(msg *domain.ReceivedMessage) (protoreflect.FullName, []byte, error) {
+	var name protoreflect.FullName
+	if typeURL := msg.Headers[domain.HeaderProtoTypeURL]; typeURL != "" {
+		name = typeName(typeURL)
+	} else {
+		name = protoreflect.FullName(msg.Headers[domain.HeaderProtoMessage])
+	}
+	if name != "" && name != anyFullName {
+		return name, msg.Data, nil
+	}
+
+	var packed anypb.Any
+	if err := proto.Unmarshal(msg.Data, &packed); err != nil || packed.TypeUrl == "" {
+		return "", nil, fmt.Errorf("%w: sequence %d: payload carries no protobuf type", domain.ErrDecode, msg.Sequence)
+	}
+	return typeName(packed.TypeUrl), packed.Value, nil
+}
+
+// typeName returns the message name of a type URL such as
+// type.googleapis.com/shop.Order
+func typeName(typeURL string) protoreflect.FullName {
+	return protoreflect.FullName(typeURL[strings.LastIndexByte(typeURL, '/')+1:])
+}
@@ -0,0 +1,80 @@
+package codec
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestTypeRegistry_Dispatch(t *testing.T) {
+	r := NewTypeRegistry()
+	var got []string
+	HandleProto(r, func(ctx context.Context, m *wrapperspb.StringValue, msg *domain.ReceivedMessage) error {
+		got = append(got, "string:"+m.GetValue())
+		return nil
+	})
+	HandleProto(r, func(ctx context.Context, m *wrapperspb.BoolValue, msg *domain.ReceivedMessage) error {
+		got = append(got, "bool")
+		return nil
+	})
+
+	str, _ := proto.Marshal(wrapperspb.String("a"))
+	boolean, _ := proto.Marshal(wrapperspb.Bool(true))
+	packed, _ := anypb.New(wrapperspb.String("b"))
+	packedData, _ := proto.Marshal(packed)
+
+	messages := []*domain.ReceivedMessage{
+		{Data: str, Headers: map[string]string{domain.HeaderProtoTypeURL: "type.googleapis.com/google.protobuf.StringValue"}},
+		{Data: boolean, Headers: map[string]string{domain.HeaderProtoMessage: "google.protobuf.BoolValue"}},
+		// A whole Any, with or without headers naming it
+		{Data: packedData},
+		{Data: packedData, Headers: map[string]string{domain.HeaderProtoMessage: "google.protobuf.Any"}},
+	}
+	for i, msg := range messages {
+		if err := r.Handle(context.Background(), msg); err != nil {
+			t.Errorf("message %d: Handle failed: %v", i, err)
+		}
+	}
+	want := []string{"string:a", "bool", "string:b", "string:b"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q, want %q", got, want)
+			break
+		}
+	}
+}
+
+func TestTypeRegistry_Unknown(t *testing.T) {
+	r := NewTypeRegistry()
+	unknown := &domain.ReceivedMessage{Data: []byte{}, Headers: map[string]string{domain.HeaderProtoMessage: "shop.Order"}}
+	if err := r.Handle(context.Background(), unknown); !errors.Is(err, domain.ErrDecode) {
+		t.Errorf("expected ErrDecode for an unknown type, got %v", err)
+	}
+	if err := r.Handle(context.Background(), &domain.ReceivedMessage{Data: []byte("{}")}); !errors.Is(err, domain.ErrDecode) {
+		t.Errorf("expected ErrDecode for an untyped payload, got %v", err)
+	}
+
+	HandleProto(r, func(ctx context.Context, m *wrapperspb.StringValue, msg *domain.ReceivedMessage) error { return nil })
+	malformed := &domain.ReceivedMessage{Data: []byte{0xff}, Headers: map[string]string{domain.HeaderProtoMessage: "google.protobuf.StringValue"}}
+	if err := r.Handle(context.Background(), malformed); !errors.Is(err, domain.ErrDecode) {
+		t.Errorf("expected ErrDecode for a malformed payload, got %v", err)
+	}
+
+	var fallback int
+	r.SetFallback(domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		fallback++
+		return nil
+	}))
+	if err := r.Handle(context.Background(), unknown); err != nil || fallback != 1 {
+		t.Errorf("expected the fallback to handle unknown types, got %v (%d calls)", err, fallback)
+	}
+}
@@ -0,0 +1,455 @@
+package codec
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// The binary codecs share a data model: values are converted to a tree of
+// nil, bool, int64, uint64, float32, float64, string, []byte, []interface{}
+// and mapValue before being written, and trees read from a payload are
+// assigned to the decode target.
+
+// mapValue is a map in the tree, keeping the order of its entries
+type mapValue []mapEntry
+
+// mapEntry is one key and value of a mapValue
+type mapEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// maxDecodeDepth bounds nesting so malicious payloads cannot exhaust the stack
+const maxDecodeDepth = 512
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// toTree converts a Go value to a tree. Struct fields are named by the tag
+// called tagName, then the json tag, then the field name.
+func toTree(v reflect.Value, tagName string) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return string(text), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return toTree(v.Elem(), tagName)
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32:
+		return float32(v.Float()), nil
+	case reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(data), v)
+			return data, nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := toTree(v.Index(i), tagName)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		m := make(mapValue, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := toTree(iter.Key(), tagName)
+			if err != nil {
+				return nil, err
+			}
+			value, err := toTree(iter.Value(), tagName)
+			if err != nil {
+				return nil, err
+			}
+			m = append(m, mapEntry{Key: key, Value: value})
+		}
+		return m, nil
+
+	case reflect.Struct:
+		var m mapValue
+		for _, f := range structFields(v.Type(), tagName) {
+			field := v.FieldByIndex(f.index)
+			if f.omitEmpty && field.IsZero() {
+				continue
+			}
+			value, err := toTree(field, tagName)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.name, err)
+			}
+			m = append(m, mapEntry{Key: f.name, Value: value})
+		}
+		return m, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported type %s", v.Type())
+	}
+}
+
+// fieldInfo describes an encoded struct field
+type fieldInfo struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// structFields lists the encoded fields of a struct type, flattening
+// untagged embedded structs
+func structFields(t reflect.Type, tagName string) []fieldInfo {
+	var fields []fieldInfo
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(tagName)
+		if !ok {
+			tag = sf.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			for _, f := range structFields(sf.Type, tagName) {
+				f.index = append([]int{i}, f.index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, fieldInfo{name: name, index: []int{i}, omitEmpty: strings.Contains(opts, "omitempty")})
+	}
+	return fields
+}
+
+// fromTree assigns a tree to v, which must be settable
+func fromTree(tree interface{}, v reflect.Value, tagName string) error {
+	if tree == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if v.Kind() != reflect.Pointer && v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		text, ok := tree.(string)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into %s", tree, v.Type())
+		}
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return fromTree(tree, v.Elem(), tagName)
+
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("cannot decode into %s", v.Type())
+		}
+		v.Set(reflect.ValueOf(plain(tree)))
+		return nil
+
+	case reflect.Bool:
+		b, ok := tree.(bool)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into %s", tree, v.Type())
+		}
+		v.SetBool(b)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := treeInt(tree)
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(n) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
+		}
+		v.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := treeUint(tree)
+		if err != nil {
+			return err
+		}
+		if v.OverflowUint(n) {
+			return fmt.Errorf("%d overflows %s", n, v.Type())
+		}
+		v.SetUint(n)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		switch n := tree.(type) {
+		case float64:
+			v.SetFloat(n)
+		case float32:
+			v.SetFloat(float64(n))
+		case int64:
+			v.SetFloat(float64(n))
+		case uint64:
+			v.SetFloat(float64(n))
+		default:
+			return fmt.Errorf("cannot decode %T into %s", tree, v.Type())
+		}
+		return nil
+
+	case reflect.String:
+		switch s := tree.(type) {
+		case string:
+			v.SetString(s)
+		case []byte:
+			v.SetString(string(s))
+		default:
+			return fmt.Errorf("cannot decode %T into %s", tree, v.Type())
+		}
+		return nil
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			switch data := tree.(type) {
+			case []byte:
+				v.SetBytes(append([]byte(nil), data...))
+				return nil
+			case string:
+				v.SetBytes([]byte(data))
+				return nil
+			}
+		}
+		items, ok := tree.([]interface{})
+		if !ok {
+			return fmt.Errorf("cannot decode %T into %s", tree, v.Type())
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := fromTree(item, slice.Index(i), tagName); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+
+	case reflect.Array:
+		if data, ok := tree.([]byte); ok && v.Type().Elem().Kind() == reflect.Uint8 {
+			reflect.Copy(v, reflect.ValueOf(data))
+			return nil
+		}
+		items, ok := tree.([]interface{})
+		if !ok {
+			return fmt.Errorf("cannot decode %T into %s", tree, v.Type())
+		}
+		for i := 0; i < v.Len() && i < len(items); i++ {
+			if err := fromTree(items[i], v.Index(i), tagName); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		m, ok := tree.(mapValue)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into %s", tree, v.Type())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(m)))
+		}
+		for _, entry := range m {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := fromTree(entry.Key, key, tagName); err != nil {
+				return fmt.Errorf("map key: %w", err)
+			}
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := fromTree(entry.Value, value, tagName); err != nil {
+				return fmt.Errorf("map value %v: %w", entry.Key, err)
+			}
+			v.SetMapIndex(key, value)
+		}
+		return nil
+
+	case reflect.Struct:
+		m, ok := tree.(mapValue)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into %s", tree, v.Type())
+		}
+		fields := structFields(v.Type(), tagName)
+		for _, entry := range m {
+			name, ok := entry.Key.(string)
+			if !ok {
+				continue
+			}
+			f, ok := findField(fields, name)
+			if !ok {
+				continue
+			}
+			if err := fromTree(entry.Value, v.FieldByIndex(f.index), tagName); err != nil {
+				return fmt.Errorf("field %s: %w", f.name, err)
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+}
+
+// findField finds a field by exact name, then case-insensitively as
+// encoding/json does
+func findField(fields []fieldInfo, name string) (fieldInfo, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return fieldInfo{}, false
+}
+
+// treeInt converts a numeric tree value to an int64
+func treeInt(tree interface{}) (int64, error) {
+	switch n := tree.(type) {
+	case int64:
+		return n, nil
+	case uint64:
+		if n > math.MaxInt64 {
+			return 0, fmt.Errorf("%d overflows int64", n)
+		}
+		return int64(n), nil
+	case float64:
+		if n != math.Trunc(n) || n < math.MinInt64 || n >= math.MaxInt64 {
+			return 0, fmt.Errorf("cannot decode %v into an integer", n)
+		}
+		return int64(n), nil
+	default:
+		return 0, fmt.Errorf("cannot decode %T into an integer", tree)
+	}
+}
+
+// treeUint converts a numeric tree value to a uint64
+func treeUint(tree interface{}) (uint64, error) {
+	switch n := tree.(type) {
+	case uint64:
+		return n, nil
+	case int64:
+		if n < 0 {
+			return 0, fmt.Errorf("%d overflows an unsigned integer", n)
+		}
+		return uint64(n), nil
+	case float64:
+		if n != math.Trunc(n) || n < 0 || n >= math.MaxUint64 {
+			return 0, fmt.Errorf("cannot decode %v into an unsigned integer", n)
+		}
+		return uint64(n), nil
+	default:
+		return 0, fmt.Errorf("cannot decode %T into an unsigned integer", tree)
+	}
+}
+
+// plain converts a tree to plain Go values for decoding into interface{}:
+// maps with string keys become map[string]interface{}, others
+// map[interface{}]interface{}
+func plain(tree interface{}) interface{} {
+	switch t := tree.(type) {
+	case []interface{}:
+		items := make([]interface{}, len(t))
+		for i, item := range t {
+			items[i] = plain(item)
+		}
+		return items
+	case mapValue:
+		stringKeys := true
+		for _, entry := range t {
+			if _, ok := entry.Key.(string); !ok {
+				stringKeys = false
+				break
+			}
+		}
+		if stringKeys {
+			m := make(map[string]interface{}, len(t))
+			for _, entry := range t {
+				m[entry.Key.(string)] = plain(entry.Value)
+			}
+			return m
+		}
+		m := make(map[interface{}]interface{}, len(t))
+		for _, entry := range t {
+			key := plain(entry.Key)
+			switch k := key.(type) {
+			case []byte:
+				key = string(k)
+			case []interface{}, map[string]interface{}, map[interface{}]interface{}:
+				key = fmt.Sprint(k)
+			}
+			m[key] = plain(entry.Value)
+		}
+		return m
+	default:
+		return tree
+	}
+}
+
+// decodeInto assigns a tree to the value v points to
+func decodeInto(tree interface{}, v interface{}, tagName string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("decode target must be a non-nil pointer, got %T", v)
+	}
+	return fromTree(tree, rv.Elem(), tagName)
+}
+
+// beUint decodes a big-endian unsigned integer of 1, 2, 4 or 8 bytes
+func beUint(b []byte) uint64 {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n
+}
@@ -0,0 +1,35 @@
+package minitoolstream_connector
+
+import (
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/compression"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+)
+
+// Compressor re-exports domain.Compressor
+type Compressor = domain.Compressor
+
+// Decompressor re-exports domain.Decompressor
+type Decompressor = domain.Decompressor
+
+// CompressionRule re-exports publisher.CompressionRule
+type CompressionRule = publisher.CompressionRule
+
+// CompressorRegistry re-exports compression.Registry
+type CompressorRegistry = compression.Registry
+
+// Compressors
+type (
+	GzipCompressor   = compression.Gzip
+	ZstdCompressor   = compression.Zstd
+	SnappyCompressor = compression.Snappy
+	LZ4Compressor    = compression.LZ4
+)
+
+// Compressor registries
+var (
+	// DefaultCompressors holds the gzip, zstd, snappy and lz4 compressors
+	DefaultCompressors    = compression.Default
+	NewCompressorRegistry = compression.NewRegistry
+	RegisterCompressor    = compression.Register
+)
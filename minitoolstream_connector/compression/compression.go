@@ -0,0 +1,146 @@
+// Package compression compresses message payloads with pluggable algorithms.
+// Publishers name the algorithm in the content-encoding header, and
+// subscribers pick the matching compressor to restore the payload.
+package compression
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// maxDecompressedSize caps decompressed payloads to guard against
+// decompression bombs
+const maxDecompressedSize = 256 << 20
+
+// Registry maps content encodings to compressors. It implements
+// domain.Decompressor and is safe for concurrent use.
+type Registry struct {
+	mu          sync.RWMutex
+	compressors map[string]domain.Compressor
+}
+
+// NewRegistry creates a registry holding compressors under their encodings
+func NewRegistry(compressors ...domain.Compressor) *Registry {
+	r := &Registry{compressors: make(map[string]domain.Compressor)}
+	for _, c := range compressors {
+		r.Register(c)
+	}
+	return r
+}
+
+// Default holds the gzip, zstd, snappy and lz4 compressors
+var Default = NewRegistry(Gzip{}, Zstd{}, Snappy{}, LZ4{})
+
+// Register adds a compressor under its encoding, replacing any compressor
+// registered under the same encoding
+func (r *Registry) Register(c domain.Compressor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.compressors[normalize(c.Encoding())] = c
+}
+
+// Lookup returns the compressor of an encoding
+func (r *Registry) Lookup(encoding string) (domain.Compressor, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if c, ok := r.compressors[normalize(encoding)]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("unsupported content-encoding %q", encoding)
+}
+
+// Encodings returns the registered encodings, e.g. to advertise them
+func (r *Registry) Encodings() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	encodings := make([]string, 0, len(r.compressors))
+	for encoding := range r.compressors {
+		encodings = append(encodings, encoding)
+	}
+	return encodings
+}
+
+// Decompress returns a copy of msg with its payload decompressed and the
+// content-encoding header removed. Several encodings, e.g. "gzip, lz4", are
+// undone in reverse order. Messages without an encoding are returned as is.
+// Errors match domain.ErrDecode.
+func (r *Registry) Decompress(msg *domain.ReceivedMessage) (*domain.ReceivedMessage, error) {
+	header := msg.Headers[domain.HeaderContentEncoding]
+	if header == "" {
+		return msg, nil
+	}
+
+	encodings := strings.Split(header, ",")
+	data := msg.Data
+	for i := len(encodings) - 1; i >= 0; i-- {
+		if normalize(encodings[i]) == "identity" {
+			continue
+		}
+		c, err := r.Lookup(encodings[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: sequence %d: %v", domain.ErrDecode, msg.Sequence, err)
+		}
+		if data, err = c.Decompress(data); err != nil {
+			return nil, fmt.Errorf("%w: sequence %d: %s: %v", domain.ErrDecode, msg.Sequence, c.Encoding(), err)
+		}
+	}
+
+	decompressed := *msg
+	decompressed.Data = data
+	decompressed.Headers = make(map[string]string, len(msg.Headers))
+	for name, value := range msg.Headers {
+		if name != domain.HeaderContentEncoding {
+			decompressed.Headers[name] = value
+		}
+	}
+	return &decompressed, nil
+}
+
+// Compress compresses the payload of msg with c and sets its content-encoding
+// header. It reports false and leaves msg unchanged when compression would
+// not make the payload smaller.
+func Compress(c domain.Compressor, msg *domain.PublishMessage) (bool, error) {
+	data, err := c.Compress(msg.Data)
+	if err != nil {
+		return false, fmt.Errorf("failed to compress with %s: %w", c.Encoding(), err)
+	}
+	if len(data) >= len(msg.Data) {
+		return false, nil
+	}
+
+	msg.Data = data
+	headers := make(map[string]string, len(msg.Headers)+1)
+	for name, value := range msg.Headers {
+		headers[name] = value
+	}
+	headers[domain.HeaderContentEncoding] = c.Encoding()
+	msg.Headers = headers
+	return true, nil
+}
+
+// Handler returns a handler decompressing messages with d before calling
+// next. Messages that cannot be decompressed fail with an error matching
+// domain.ErrDecode and do not reach next.
+func Handler(d domain.Decompressor, next domain.MessageHandler) domain.MessageHandler {
+	return domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		decompressed, err := d.Decompress(msg)
+		if err != nil {
+			return err
+		}
+		return next.Handle(ctx, decompressed)
+	})
+}
+
+// Register adds a compressor to the Default registry
+func Register(c domain.Compressor) {
+	Default.Register(c)
+}
+
+// normalize returns the lower-case encoding without surrounding spaces
+func normalize(encoding string) string {
+	return strings.ToLower(strings.TrimSpace(encoding))
+}
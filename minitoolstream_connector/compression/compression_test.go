@@ -0,0 +1,150 @@
+package compression
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestCompressors_RoundTrip(t *testing.T) {
+	random := make([]byte, 100<<10)
+	rand.New(rand.NewSource(1)).Read(random)
+	inputs := map[string][]byte{
+		"empty":      {},
+		"short":      []byte("abc"),
+		"repetitive": []byte(strings.Repeat("telemetry sample 42; ", 20000)),
+		"random":     random,
+	}
+
+	for _, c := range []domain.Compressor{Gzip{}, Gzip{Level: 9}, Zstd{}, Snappy{}, LZ4{}} {
+		for name, data := range inputs {
+			compressed, err := c.Compress(data)
+			if err != nil {
+				t.Errorf("%s %s: Compress failed: %v", c.Encoding(), name, err)
+				continue
+			}
+			if name == "repetitive" && len(compressed) > len(data)/10 {
+				t.Errorf("%s: compressed %d bytes to %d", c.Encoding(), len(data), len(compressed))
+			}
+			got, err := c.Decompress(compressed)
+			if err != nil {
+				t.Errorf("%s %s: Decompress failed: %v", c.Encoding(), name, err)
+				continue
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("%s %s: round trip changed the data", c.Encoding(), name)
+			}
+		}
+	}
+}
+
+func TestLZ4_Frames(t *testing.T) {
+	// Frames written by the lz4 command-line tool
+	frames := map[string]string{
+		"":                              "04224d186440a700000000055dcc02",
+		"hello hello hello hello hello": "04224d186440a70f0000006e68656c6c6f2006005068656c6c6f0000000079b1fff9",
+	}
+	for want, frame := range frames {
+		data, _ := hex.DecodeString(frame)
+		got, err := LZ4{}.Decompress(data)
+		if err != nil || string(got) != want {
+			t.Errorf("Decompress(%s) = %q, %v; want %q", frame, got, err, want)
+		}
+	}
+
+	// An empty input is the same frame as the tool's
+	if got, _ := (LZ4{}).Compress(nil); hex.EncodeToString(got) != frames[""] {
+		t.Errorf("Compress(empty) = %x", got)
+	}
+
+	for _, bad := range []string{"04224d18", "04224d186440a6", "04224d186440a70f000000", "04224d186440a700000000055dcc03", "00112233"} {
+		data, _ := hex.DecodeString(bad)
+		if _, err := (LZ4{}).Decompress(data); err == nil {
+			t.Errorf("Decompress(%s) expected error", bad)
+		}
+	}
+}
+
+func TestXXH32(t *testing.T) {
+	tests := map[string]uint32{
+		"":    0x02cc5d05,
+		"abc": 0x32d153ff,
+		"Nobody inspects the spammish repetition": 0xe2293b2f,
+	}
+	for input, want := range tests {
+		if got := xxh32([]byte(input), 0); got != want {
+			t.Errorf("xxh32(%q) = %08x, want %08x", input, got, want)
+		}
+	}
+}
+
+func TestRegistry_Decompress(t *testing.T) {
+	payload := []byte(strings.Repeat("payload ", 100))
+	gz, _ := Gzip{}.Compress(payload)
+	both, _ := LZ4{}.Compress(gz)
+
+	msg := &domain.ReceivedMessage{
+		Sequence: 7,
+		Data:     both,
+		Headers:  map[string]string{domain.HeaderContentEncoding: "gzip, LZ4", "content-type": "text/plain"},
+	}
+	got, err := Default.Decompress(msg)
+	if err != nil {
+		t.Fatalf("Decompress failed: %v", err)
+	}
+	if !bytes.Equal(got.Data, payload) || got.Sequence != 7 {
+		t.Errorf("unexpected message %+v", got)
+	}
+	if _, ok := got.Headers[domain.HeaderContentEncoding]; ok || got.Headers["content-type"] != "text/plain" {
+		t.Errorf("unexpected headers %v", got.Headers)
+	}
+	if msg.Headers[domain.HeaderContentEncoding] == "" || !bytes.Equal(msg.Data, both) {
+		t.Error("Decompress modified the received message")
+	}
+
+	plain := &domain.ReceivedMessage{Data: payload}
+	if got, err := Default.Decompress(plain); err != nil || got != plain {
+		t.Errorf("expected a message without encoding to pass through, got %v", err)
+	}
+
+	for _, encoding := range []string{"br", "gzip"} {
+		bad := &domain.ReceivedMessage{Data: payload, Headers: map[string]string{domain.HeaderContentEncoding: encoding}}
+		if _, err := Default.Decompress(bad); !errors.Is(err, domain.ErrDecode) {
+			t.Errorf("%s: expected ErrDecode, got %v", encoding, err)
+		}
+	}
+}
+
+func TestCompress_Handler(t *testing.T) {
+	payload := []byte(strings.Repeat("x", 1000))
+	msg := &domain.PublishMessage{Subject: "s", Data: payload}
+	if ok, err := Compress(Zstd{}, msg); !ok || err != nil {
+		t.Fatalf("Compress = %v, %v", ok, err)
+	}
+	if msg.Headers[domain.HeaderContentEncoding] != "zstd" || len(msg.Data) >= len(payload) {
+		t.Errorf("unexpected message %+v", msg)
+	}
+
+	tiny := &domain.PublishMessage{Subject: "s", Data: []byte("x")}
+	if ok, _ := Compress(Gzip{}, tiny); ok || tiny.Headers != nil {
+		t.Error("expected a payload compression does not shrink to be left alone")
+	}
+
+	var got []byte
+	h := Handler(Default, domain.MessageHandlerFunc(func(ctx context.Context, m *domain.ReceivedMessage) error {
+		got = m.Data
+		return nil
+	}))
+	if err := h.Handle(context.Background(), &domain.ReceivedMessage{Data: msg.Data, Headers: msg.Headers}); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Error("handler did not receive the decompressed payload")
+	}
+}
@@ -0,0 +1,118 @@
+package compression
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Gzip compresses payloads with gzip
+type Gzip struct {
+	// Level is the compression level (default gzip.DefaultCompression)
+	Level int
+}
+
+// Encoding returns gzip
+func (Gzip) Encoding() string {
+	return "gzip"
+}
+
+// Compress compresses data
+func (g Gzip) Compress(data []byte) ([]byte, error) {
+	level := g.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses data
+func (Gzip) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readLimited(r)
+}
+
+// Zstd compresses payloads with Zstandard
+type Zstd struct{}
+
+// zstdCoders are shared by all Zstd values; EncodeAll and DecodeAll are safe
+// for concurrent use
+var zstdCoders = sync.OnceValues(func() (*zstd.Encoder, *zstd.Decoder) {
+	enc, _ := zstd.NewWriter(nil)
+	dec, _ := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize), zstd.WithDecoderConcurrency(0))
+	return enc, dec
+})
+
+// Encoding returns zstd
+func (Zstd) Encoding() string {
+	return "zstd"
+}
+
+// Compress compresses data
+func (Zstd) Compress(data []byte) ([]byte, error) {
+	enc, _ := zstdCoders()
+	return enc.EncodeAll(data, nil), nil
+}
+
+// Decompress decompresses data
+func (Zstd) Decompress(data []byte) ([]byte, error) {
+	_, dec := zstdCoders()
+	return dec.DecodeAll(data, nil)
+}
+
+// Snappy compresses payloads with the Snappy block format
+type Snappy struct{}
+
+// Encoding returns snappy
+func (Snappy) Encoding() string {
+	return "snappy"
+}
+
+// Compress compresses data
+func (Snappy) Compress(data []byte) ([]byte, error) {
+	return s2.EncodeSnappy(nil, data), nil
+}
+
+// Decompress decompresses data
+func (Snappy) Decompress(data []byte) ([]byte, error) {
+	n, err := s2.DecodedLen(data)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed size %d exceeds the limit", n)
+	}
+	return s2.Decode(nil, data)
+}
+
+// readLimited reads r up to maxDecompressedSize
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDecompressedSize {
+		return nil, fmt.Errorf("decompressed size exceeds the limit of %d bytes", maxDecompressedSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,349 @@
+package compression
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// LZ4 compresses payloads in the LZ4 frame format, readable by the lz4 tool
+// and other LZ4 libraries. It writes independent 64 KiB blocks with a
+// content checksum and reads any frame without a dictionary.
+type LZ4 struct{}
+
+const (
+	lz4Magic          = 0x184d2204
+	lz4SkippableMagic = 0x184d2a50
+	lz4BlockSize      = 64 << 10
+	lz4MinMatch       = 4
+	lz4HashLog        = 14
+	// Matches start at least lz4MatchLimit bytes before the end of a block,
+	// and the last lz4LastLiterals bytes are literals
+	lz4MatchLimit   = 12
+	lz4LastLiterals = 5
+)
+
+// Encoding returns lz4
+func (LZ4) Encoding() string {
+	return "lz4"
+}
+
+// Compress compresses data into one frame
+func (LZ4) Compress(data []byte) ([]byte, error) {
+	// FLG: version 1, independent blocks, content checksum; BD: 64 KiB blocks
+	descriptor := []byte{0x64, 0x40}
+	out := binary.LittleEndian.AppendUint32(nil, lz4Magic)
+	out = append(out, descriptor...)
+	out = append(out, byte(xxh32(descriptor, 0)>>8))
+
+	var table [1 << lz4HashLog]int32
+	for start := 0; start < len(data); start += lz4BlockSize {
+		block := data[start:min(start+lz4BlockSize, len(data))]
+		compressed := lz4CompressBlock(block, &table)
+		if len(compressed) >= len(block) {
+			out = binary.LittleEndian.AppendUint32(out, uint32(len(block))|1<<31)
+			out = append(out, block...)
+			continue
+		}
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(compressed)))
+		out = append(out, compressed...)
+	}
+
+	out = binary.LittleEndian.AppendUint32(out, 0)
+	return binary.LittleEndian.AppendUint32(out, xxh32(data, 0)), nil
+}
+
+// Decompress decompresses one or more concatenated frames, skipping
+// skippable frames
+func (LZ4) Decompress(data []byte) ([]byte, error) {
+	var out []byte
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("lz4: truncated frame")
+		}
+		magic := binary.LittleEndian.Uint32(data)
+		if magic&0xfffffff0 == lz4SkippableMagic {
+			if len(data) < 8 {
+				return nil, fmt.Errorf("lz4: truncated skippable frame")
+			}
+			size := uint64(binary.LittleEndian.Uint32(data[4:]))
+			if size > uint64(len(data)-8) {
+				return nil, fmt.Errorf("lz4: truncated skippable frame")
+			}
+			data = data[8+size:]
+			continue
+		}
+		if magic != lz4Magic {
+			return nil, fmt.Errorf("lz4: invalid magic number %08x", magic)
+		}
+
+		var err error
+		if out, data, err = lz4DecompressFrame(out, data[4:]); err != nil {
+			return nil, err
+		}
+	}
+	if out == nil {
+		out = []byte{}
+	}
+	return out, nil
+}
+
+// lz4DecompressFrame appends the content of the frame at the start of data,
+// after its magic number, to out and returns the data after the frame
+func lz4DecompressFrame(out, data []byte) ([]byte, []byte, error) {
+	if len(data) < 3 {
+		return nil, nil, fmt.Errorf("lz4: truncated frame descriptor")
+	}
+	flg, bd := data[0], data[1]
+	if flg>>6 != 1 {
+		return nil, nil, fmt.Errorf("lz4: unsupported frame version %d", flg>>6)
+	}
+	if flg&0x01 != 0 {
+		return nil, nil, fmt.Errorf("lz4: dictionaries are not supported")
+	}
+	blockChecksum, contentChecksum, hasContentSize := flg&0x10 != 0, flg&0x04 != 0, flg&0x08 != 0
+	maxBlock := 1 << (8 + 2*int(bd>>4&0x07))
+	if bd>>4&0x07 < 4 {
+		return nil, nil, fmt.Errorf("lz4: invalid block size code %d", bd>>4&0x07)
+	}
+
+	descriptorLen := 2
+	if hasContentSize {
+		descriptorLen += 8
+	}
+	if len(data) < descriptorLen+1 {
+		return nil, nil, fmt.Errorf("lz4: truncated frame descriptor")
+	}
+	if byte(xxh32(data[:descriptorLen], 0)>>8) != data[descriptorLen] {
+		return nil, nil, fmt.Errorf("lz4: frame descriptor checksum mismatch")
+	}
+	data = data[descriptorLen+1:]
+
+	frameStart := len(out)
+	for {
+		if len(data) < 4 {
+			return nil, nil, fmt.Errorf("lz4: truncated block")
+		}
+		size := binary.LittleEndian.Uint32(data)
+		data = data[4:]
+		if size == 0 {
+			break
+		}
+		uncompressed := size&(1<<31) != 0
+		size &^= 1 << 31
+		if int64(size) > int64(len(data)) || int(size) > maxBlock {
+			return nil, nil, fmt.Errorf("lz4: invalid block size %d", size)
+		}
+		block := data[:size]
+		data = data[size:]
+
+		if blockChecksum {
+			if len(data) < 4 {
+				return nil, nil, fmt.Errorf("lz4: truncated block checksum")
+			}
+			if binary.LittleEndian.Uint32(data) != xxh32(block, 0) {
+				return nil, nil, fmt.Errorf("lz4: block checksum mismatch")
+			}
+			data = data[4:]
+		}
+
+		if uncompressed {
+			out = append(out, block...)
+		} else {
+			var err error
+			if out, err = lz4DecompressBlock(out, block, len(out)+maxBlock); err != nil {
+				return nil, nil, err
+			}
+		}
+		if len(out) > maxDecompressedSize {
+			return nil, nil, fmt.Errorf("lz4: decompressed size exceeds the limit of %d bytes", maxDecompressedSize)
+		}
+	}
+
+	if contentChecksum {
+		if len(data) < 4 {
+			return nil, nil, fmt.Errorf("lz4: truncated content checksum")
+		}
+		if binary.LittleEndian.Uint32(data) != xxh32(out[frameStart:], 0) {
+			return nil, nil, fmt.Errorf("lz4: content checksum mismatch")
+		}
+		data = data[4:]
+	}
+	return out, data, nil
+}
+
+// lz4CompressBlock compresses src into an LZ4 block with a greedy hash chain
+// of depth one. table is scratch space reset by the call.
+func lz4CompressBlock(src []byte, table *[1 << lz4HashLog]int32) []byte {
+	*table = [1 << lz4HashLog]int32{}
+	dst := make([]byte, 0, len(src)+len(src)/255+16)
+
+	anchor := 0
+	if len(src) > lz4MatchLimit {
+		limit := len(src) - lz4MatchLimit
+		for i := 0; i < limit; {
+			seq := binary.LittleEndian.Uint32(src[i:])
+			h := (seq * 2654435761) >> (32 - lz4HashLog)
+			ref := int(table[h]) - 1
+			table[h] = int32(i + 1)
+
+			if ref < 0 || i-ref > 0xffff || binary.LittleEndian.Uint32(src[ref:]) != seq {
+				i++
+				continue
+			}
+
+			end := i + lz4MinMatch
+			for end < len(src)-lz4LastLiterals && src[end] == src[ref+end-i] {
+				end++
+			}
+			dst = lz4AppendSequence(dst, src[anchor:i], i-ref, end-i)
+			i, anchor = end, end
+		}
+	}
+	return lz4AppendSequence(dst, src[anchor:], 0, 0)
+}
+
+// lz4AppendSequence appends literals followed by a match of matchLen bytes
+// at offset, or only the literals when matchLen is 0
+func lz4AppendSequence(dst, literals []byte, offset, matchLen int) []byte {
+	token := len(literals)
+	if token > 15 {
+		token = 15
+	}
+	matchCode := 0
+	if matchLen > 0 {
+		matchCode = min(matchLen-lz4MinMatch, 15)
+	}
+	dst = append(dst, byte(token<<4|matchCode))
+	if len(literals) >= 15 {
+		dst = lz4AppendLength(dst, len(literals)-15)
+	}
+	dst = append(dst, literals...)
+
+	if matchLen == 0 {
+		return dst
+	}
+	dst = binary.LittleEndian.AppendUint16(dst, uint16(offset))
+	if matchLen-lz4MinMatch >= 15 {
+		dst = lz4AppendLength(dst, matchLen-lz4MinMatch-15)
+	}
+	return dst
+}
+
+// lz4AppendLength appends the continuation bytes of a length
+func lz4AppendLength(dst []byte, n int) []byte {
+	for ; n >= 255; n -= 255 {
+		dst = append(dst, 255)
+	}
+	return append(dst, byte(n))
+}
+
+// lz4DecompressBlock appends the content of an LZ4 block to out, growing it
+// to at most limit bytes. Matches may reach back into earlier blocks.
+func lz4DecompressBlock(out, src []byte, limit int) ([]byte, error) {
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+
+		literals, err := lz4ReadLength(src, &i, int(token>>4))
+		if err != nil {
+			return nil, err
+		}
+		if literals > len(src)-i || len(out)+literals > limit {
+			return nil, fmt.Errorf("lz4: corrupt block")
+		}
+		out = append(out, src[i:i+literals]...)
+		i += literals
+		if i == len(src) {
+			return out, nil
+		}
+
+		if len(src)-i < 2 {
+			return nil, fmt.Errorf("lz4: corrupt block")
+		}
+		offset := int(binary.LittleEndian.Uint16(src[i:]))
+		i += 2
+		matchLen, err := lz4ReadLength(src, &i, int(token&0x0f))
+		if err != nil {
+			return nil, err
+		}
+		matchLen += lz4MinMatch
+		if offset == 0 || offset > len(out) || len(out)+matchLen > limit {
+			return nil, fmt.Errorf("lz4: corrupt block")
+		}
+
+		// Matches may overlap the bytes they produce
+		pos := len(out) - offset
+		for k := 0; k < matchLen; k++ {
+			out = append(out, out[pos+k])
+		}
+	}
+	return nil, fmt.Errorf("lz4: block ends without literals")
+}
+
+// lz4ReadLength completes a 4-bit length with its continuation bytes
+func lz4ReadLength(src []byte, i *int, n int) (int, error) {
+	if n != 15 {
+		return n, nil
+	}
+	for {
+		if *i >= len(src) {
+			return 0, fmt.Errorf("lz4: corrupt block")
+		}
+		b := src[*i]
+		*i++
+		n += int(b)
+		if b != 255 {
+			return n, nil
+		}
+	}
+}
+
+// xxHash32 primes
+const (
+	xxhPrime1 uint32 = 2654435761
+	xxhPrime2 uint32 = 2246822519
+	xxhPrime3 uint32 = 3266489917
+	xxhPrime4 uint32 = 668265263
+	xxhPrime5 uint32 = 374761393
+)
+
+// xxh32 computes the xxHash32 of data, used by LZ4 frame checksums
+func xxh32(data []byte, seed uint32) uint32 {
+	n := len(data)
+	var h uint32
+	if n >= 16 {
+		v1, v2, v3, v4 := seed+xxhPrime1+xxhPrime2, seed+xxhPrime2, seed, seed-xxhPrime1
+		for ; len(data) >= 16; data = data[16:] {
+			v1 = xxhRound(v1, binary.LittleEndian.Uint32(data))
+			v2 = xxhRound(v2, binary.LittleEndian.Uint32(data[4:]))
+			v3 = xxhRound(v3, binary.LittleEndian.Uint32(data[8:]))
+			v4 = xxhRound(v4, binary.LittleEndian.Uint32(data[12:]))
+		}
+		h = bits.RotateLeft32(v1, 1) + bits.RotateLeft32(v2, 7) + bits.RotateLeft32(v3, 12) + bits.RotateLeft32(v4, 18)
+	} else {
+		h = seed + xxhPrime5
+	}
+
+	h += uint32(n)
+	for ; len(data) >= 4; data = data[4:] {
+		h += binary.LittleEndian.Uint32(data) * xxhPrime3
+		h = bits.RotateLeft32(h, 17) * xxhPrime4
+	}
+	for _, c := range data {
+		h += uint32(c) * xxhPrime5
+		h = bits.RotateLeft32(h, 11) * xxhPrime1
+	}
+
+	h ^= h >> 15
+	h *= xxhPrime2
+	h ^= h >> 13
+	h *= xxhPrime3
+	h ^= h >> 16
+	return h
+}
+
+// xxhRound mixes one lane of input into an accumulator
+func xxhRound(acc, input uint32) uint32 {
+	return bits.RotateLeft32(acc+input*xxhPrime2, 13) * xxhPrime1
+}
@@ -0,0 +1,148 @@
+package connectortest
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeClock is a domain.Clock that only moves when told to. Channels returned
+// by After fire once Advance or Set moves the clock past their deadline, so
+// retry backoff, TTLs and timestamps can be tested without sleeping.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{}
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFakeClock creates a fake clock set to start, or to 2024-01-01T00:00:00Z
+// when start is zero
+func NewFakeClock(start time.Time) *FakeClock {
+	if start.IsZero() {
+		start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return &FakeClock{now: start, changed: make(chan struct{})}
+}
+
+// Now returns the current fake time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the fake time once the clock has
+// advanced by d. A non-positive d fires immediately.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, &fakeWaiter{deadline: c.now.Add(d), ch: ch})
+	c.notify()
+	return ch
+}
+
+// Advance moves the clock forward by d and fires every waiter that is due
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(c.now.Add(d))
+}
+
+// Set moves the clock to t and fires every waiter that is due. Setting an
+// earlier time does not fire anything.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(t)
+}
+
+// AdvanceToNext moves the clock to the earliest pending deadline and fires
+// the waiters due at it. It returns false when nothing is waiting.
+func (c *FakeClock) AdvanceToNext() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.waiters) == 0 {
+		return false
+	}
+	next := c.waiters[0].deadline
+	for _, w := range c.waiters[1:] {
+		if w.deadline.Before(next) {
+			next = w.deadline
+		}
+	}
+	c.setLocked(next)
+	return true
+}
+
+// Waiters returns the number of pending After channels
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// WaitForWaiters blocks until at least n After channels are pending, which
+// tells the test that the code under test has started waiting. It fails the
+// test after timeout of real time.
+func (c *FakeClock) WaitForWaiters(t testing.TB, n int, timeout time.Duration) {
+	t.Helper()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		c.mu.Lock()
+		count, changed := len(c.waiters), c.changed
+		c.mu.Unlock()
+		if count >= n {
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			t.Fatalf("timed out after %v waiting for %d clock waiters, got %d", timeout, n, count)
+			return
+		}
+	}
+}
+
+// setLocked moves the clock to t and fires due waiters in deadline order;
+// c.mu must be held
+func (c *FakeClock) setLocked(t time.Time) {
+	if t.After(c.now) {
+		c.now = t
+	}
+
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+	})
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+	c.notify()
+}
+
+// notify wakes goroutines blocked in WaitForWaiters; c.mu must be held
+func (c *FakeClock) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
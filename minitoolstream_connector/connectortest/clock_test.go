@@ -0,0 +1,124 @@
+package connectortest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/handler"
+	publisherUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+)
+
+var _ domain.Clock = (*FakeClock)(nil)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+
+	if !c.Now().Equal(start) {
+		t.Errorf("expected %v, got %v", start, c.Now())
+	}
+	if NewFakeClock(time.Time{}).Now().IsZero() {
+		t.Error("expected a fixed default start time")
+	}
+
+	select {
+	case <-c.After(0):
+	default:
+		t.Error("expected a non-positive duration to fire immediately")
+	}
+
+	short, long := c.After(time.Second), c.After(time.Minute)
+	if c.Waiters() != 2 {
+		t.Errorf("expected 2 waiters, got %d", c.Waiters())
+	}
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case <-short:
+		t.Fatal("fired before its deadline")
+	default:
+	}
+
+	c.Advance(500 * time.Millisecond)
+	if got := <-short; !got.Equal(start.Add(time.Second)) {
+		t.Errorf("expected the fake time on the channel, got %v", got)
+	}
+
+	if !c.AdvanceToNext() {
+		t.Fatal("expected a pending waiter")
+	}
+	<-long
+	if !c.Now().Equal(start.Add(time.Minute)) {
+		t.Errorf("expected the clock at the deadline, got %v", c.Now())
+	}
+	if c.AdvanceToNext() {
+		t.Error("expected no pending waiters")
+	}
+
+	c.Set(start)
+	if !c.Now().Equal(start.Add(time.Minute)) {
+		t.Error("expected Set to never move the clock backwards")
+	}
+}
+
+func TestFakeClock_PublisherBackoff(t *testing.T) {
+	clock := NewFakeClock(time.Time{})
+	ingress := NewRecordingIngressClient()
+	ingress.FailNext(domain.ErrUnavailable, domain.ErrUnavailable)
+
+	pub, err := publisherUsecase.New(&publisherUsecase.Config{
+		Client:       ingress,
+		Logger:       quietLogger{},
+		MaxRetries:   2,
+		RetryBackoff: time.Hour,
+		MessageTTL:   24 * time.Hour,
+		Clock:        clock,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- pub.Publish(context.Background(), handler.NewDataHandler(&handler.DataHandlerConfig{
+			Subject: "orders",
+			Data:    []byte("x"),
+			Logger:  quietLogger{},
+			Clock:   clock,
+		}))
+	}()
+
+	// Backoff doubles: one hour, then two hours of fake time
+	clock.WaitForWaiters(t, 1, time.Second)
+	clock.Advance(time.Hour)
+	clock.WaitForWaiters(t, 1, time.Second)
+	clock.Advance(2 * time.Hour)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("publish did not complete after the backoff elapsed")
+	}
+
+	msg := ingress.Last()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if msg.Headers["timestamp"] != start.Format(time.RFC3339) {
+		t.Errorf("expected the fake timestamp, got %q", msg.Headers["timestamp"])
+	}
+	if want := start.Add(24 * time.Hour).Format(time.RFC3339Nano); msg.Headers[domain.HeaderExpiresAt] != want {
+		t.Errorf("expected expiry %s relative to the fake time, got %q", want, msg.Headers[domain.HeaderExpiresAt])
+	}
+}
+
+func TestFakeClock_WaitForWaitersTimeout(t *testing.T) {
+	rec := &failureRecorder{TB: t}
+	NewFakeClock(time.Time{}).WaitForWaiters(rec, 1, 10*time.Millisecond)
+	if len(rec.failures) != 1 {
+		t.Errorf("expected the wait to fail the test, got %v", rec.failures)
+	}
+}
@@ -0,0 +1,296 @@
+package connectortest
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// ScriptedEgressClient is a domain.EgressClient that serves a script of
+// messages, notifications and errors. Deliver queues messages and a
+// notification for each; a subscriber then receives the notification from
+// its Subscribe stream and fetches the queued messages, as it would from a
+// server with a durable consumer. Every subject has one script, so concurrent
+// subscriptions to the same subject share it. It is safe for concurrent use.
+type ScriptedEgressClient struct {
+	mu            sync.Mutex
+	subjects      map[string]*subjectScript
+	subscribeErrs map[string][]error
+	fetchErrs     map[string][]error
+	subscriptions []domain.SubscriptionConfig
+	fetches       []domain.SubscriptionConfig
+	closed        bool
+	changed       chan struct{}
+}
+
+// subjectScript is the queued state of one subject
+type subjectScript struct {
+	events   []streamEvent
+	messages []*domain.ReceivedMessage
+	last     uint64
+}
+
+// streamEvent is the next result of a notification stream
+type streamEvent struct {
+	notification *domain.Notification
+	err          error
+}
+
+// NewScriptedEgressClient creates a new scripted egress client
+func NewScriptedEgressClient() *ScriptedEgressClient {
+	return &ScriptedEgressClient{
+		subjects:      make(map[string]*subjectScript),
+		subscribeErrs: make(map[string][]error),
+		fetchErrs:     make(map[string][]error),
+		changed:       make(chan struct{}),
+	}
+}
+
+// Deliver queues messages for fetching and a notification for each. Zero
+// sequences are assigned after the last sequence of the subject and zero
+// timestamps are set to now.
+func (c *ScriptedEgressClient) Deliver(msgs ...*domain.ReceivedMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, msg := range msgs {
+		script := c.script(msg.Subject)
+
+		copied := *msg
+		copied.Data = append([]byte(nil), msg.Data...)
+		if msg.Headers != nil {
+			copied.Headers = make(map[string]string, len(msg.Headers))
+			for k, v := range msg.Headers {
+				copied.Headers[k] = v
+			}
+		}
+		if copied.Sequence == 0 {
+			copied.Sequence = script.last + 1
+		}
+		if copied.Timestamp.IsZero() {
+			copied.Timestamp = time.Now()
+		}
+		if copied.Sequence > script.last {
+			script.last = copied.Sequence
+		}
+
+		script.messages = append(script.messages, &copied)
+		script.events = append(script.events, streamEvent{
+			notification: &domain.Notification{Subject: copied.Subject, Sequence: copied.Sequence},
+		})
+	}
+	c.notifyLocked()
+}
+
+// Notify queues a notification without a message, e.g. to test a fetch that
+// returns nothing
+func (c *ScriptedEgressClient) Notify(subject string, sequence uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	script := c.script(subject)
+	script.events = append(script.events, streamEvent{notification: &domain.Notification{Subject: subject, Sequence: sequence}})
+	c.notifyLocked()
+}
+
+// FailStream queues err on the notification stream of subject; the stream
+// returns it after the notifications queued before it
+func (c *ScriptedEgressClient) FailStream(subject string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	script := c.script(subject)
+	script.events = append(script.events, streamEvent{err: err})
+	c.notifyLocked()
+}
+
+// EndStream queues the end (io.EOF) of the notification stream of subject
+func (c *ScriptedEgressClient) EndStream(subject string) {
+	c.FailStream(subject, io.EOF)
+}
+
+// FailSubscribe makes the next Subscribe calls for subject return errs, one
+// per call, in order
+func (c *ScriptedEgressClient) FailSubscribe(subject string, errs ...error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscribeErrs[subject] = append(c.subscribeErrs[subject], errs...)
+}
+
+// FailFetch makes the next Fetch calls for subject return errs, one per call,
+// in order. The queued messages stay available for the following fetch.
+func (c *ScriptedEgressClient) FailFetch(subject string, errs ...error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetchErrs[subject] = append(c.fetchErrs[subject], errs...)
+}
+
+// SetLastSequence sets the sequence GetLastSequence returns for subject
+func (c *ScriptedEgressClient) SetLastSequence(subject string, sequence uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.script(subject).last = sequence
+}
+
+// Subscriptions returns the configs of all Subscribe calls
+func (c *ScriptedEgressClient) Subscriptions() []domain.SubscriptionConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]domain.SubscriptionConfig(nil), c.subscriptions...)
+}
+
+// Fetches returns the configs of all Fetch calls
+func (c *ScriptedEgressClient) Fetches() []domain.SubscriptionConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]domain.SubscriptionConfig(nil), c.fetches...)
+}
+
+// Pending returns the number of messages of subject not fetched yet
+func (c *ScriptedEgressClient) Pending(subject string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if script, ok := c.subjects[subject]; ok {
+		return len(script.messages)
+	}
+	return 0
+}
+
+// Subscribe returns a notification stream of the subject's script
+func (c *ScriptedEgressClient) Subscribe(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, ErrClosed
+	}
+	c.subscriptions = append(c.subscriptions, *config)
+	if err := popError(c.subscribeErrs, config.Subject); err != nil {
+		return nil, err
+	}
+
+	c.script(config.Subject)
+	return &notificationStream{client: c, subject: config.Subject, ctx: ctx}, nil
+}
+
+// Fetch returns up to BatchSize queued messages of the subject, all of them
+// when BatchSize is not positive
+func (c *ScriptedEgressClient) Fetch(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, ErrClosed
+	}
+	c.fetches = append(c.fetches, *config)
+	if err := popError(c.fetchErrs, config.Subject); err != nil {
+		return nil, err
+	}
+
+	script := c.script(config.Subject)
+	n := len(script.messages)
+	if config.BatchSize > 0 && int(config.BatchSize) < n {
+		n = int(config.BatchSize)
+	}
+	batch := script.messages[:n:n]
+	script.messages = script.messages[n:]
+	return &messageStream{messages: batch}, nil
+}
+
+// GetLastSequence returns the highest delivered or set sequence of subject
+func (c *ScriptedEgressClient) GetLastSequence(ctx context.Context, subject string) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, ErrClosed
+	}
+	return c.script(subject).last, nil
+}
+
+// Close ends all notification streams
+func (c *ScriptedEgressClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closed {
+		c.closed = true
+		c.notifyLocked()
+	}
+	return nil
+}
+
+// script returns the script of subject, creating it if needed. The caller
+// must hold c.mu.
+func (c *ScriptedEgressClient) script(subject string) *subjectScript {
+	script, ok := c.subjects[subject]
+	if !ok {
+		script = &subjectScript{}
+		c.subjects[subject] = script
+	}
+	return script
+}
+
+// notifyLocked wakes up streams waiting for events. The caller must hold c.mu.
+func (c *ScriptedEgressClient) notifyLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// popError removes and returns the first queued error of subject
+func popError(errs map[string][]error, subject string) error {
+	queued := errs[subject]
+	if len(queued) == 0 {
+		return nil
+	}
+	errs[subject] = queued[1:]
+	return queued[0]
+}
+
+// notificationStream serves the events of one subject
+type notificationStream struct {
+	client  *ScriptedEgressClient
+	subject string
+	ctx     context.Context
+}
+
+// Recv blocks until an event is queued, the context is done or the client
+// is closed
+func (s *notificationStream) Recv() (*domain.Notification, error) {
+	for {
+		s.client.mu.Lock()
+		if s.client.closed {
+			s.client.mu.Unlock()
+			return nil, io.EOF
+		}
+		script := s.client.subjects[s.subject]
+		if len(script.events) > 0 {
+			event := script.events[0]
+			script.events = script.events[1:]
+			s.client.mu.Unlock()
+			return event.notification, event.err
+		}
+		changed := s.client.changed
+		s.client.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-s.ctx.Done():
+			return nil, s.ctx.Err()
+		}
+	}
+}
+
+// messageStream serves a fetched batch
+type messageStream struct {
+	messages []*domain.ReceivedMessage
+}
+
+// Recv returns the next message of the batch, io.EOF at its end
+func (s *messageStream) Recv() (*domain.ReceivedMessage, error) {
+	if len(s.messages) == 0 {
+		return nil, io.EOF
+	}
+	msg := s.messages[0]
+	s.messages = s.messages[1:]
+	return msg, nil
+}
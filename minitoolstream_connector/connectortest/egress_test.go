@@ -0,0 +1,164 @@
+package connectortest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	subscriberUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/subscriber"
+)
+
+type quietLogger struct{}
+
+func (quietLogger) Printf(format string, v ...interface{}) {}
+
+func TestScriptedEgressClient_Stream(t *testing.T) {
+	c := NewScriptedEgressClient()
+	ctx := context.Background()
+
+	c.Deliver(
+		&domain.ReceivedMessage{Subject: "orders", Data: []byte("a")},
+		&domain.ReceivedMessage{Subject: "orders", Data: []byte("b")},
+		&domain.ReceivedMessage{Subject: "orders", Sequence: 10, Data: []byte("c")},
+	)
+	broken := errors.New("stream reset")
+	c.FailStream("orders", broken)
+
+	stream, err := c.Subscribe(ctx, &domain.SubscriptionConfig{Subject: "orders", DurableName: "test"})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	for _, want := range []uint64{1, 2, 10} {
+		n, err := stream.Recv()
+		if err != nil || n.Sequence != want {
+			t.Fatalf("expected notification %d, got %+v (%v)", want, n, err)
+		}
+	}
+	if _, err := stream.Recv(); !errors.Is(err, broken) {
+		t.Errorf("expected injected stream error, got %v", err)
+	}
+
+	batch, _ := c.Fetch(ctx, &domain.SubscriptionConfig{Subject: "orders", BatchSize: 2})
+	var data []string
+	for {
+		msg, err := batch.Recv()
+		if err == io.EOF {
+			break
+		}
+		data = append(data, string(msg.Data))
+		if msg.Timestamp.IsZero() {
+			t.Error("expected timestamp to be set")
+		}
+	}
+	if len(data) != 2 || data[0] != "a" || data[1] != "b" {
+		t.Errorf("unexpected batch %q", data)
+	}
+	if c.Pending("orders") != 1 {
+		t.Errorf("expected 1 pending message, got %d", c.Pending("orders"))
+	}
+	if last, _ := c.GetLastSequence(ctx, "orders"); last != 10 {
+		t.Errorf("expected last sequence 10, got %d", last)
+	}
+	if len(c.Subscriptions()) != 1 || c.Subscriptions()[0].DurableName != "test" || len(c.Fetches()) != 1 {
+		t.Errorf("unexpected calls %+v %+v", c.Subscriptions(), c.Fetches())
+	}
+}
+
+func TestScriptedEgressClient_Errors(t *testing.T) {
+	c := NewScriptedEgressClient()
+	ctx := context.Background()
+	denied := errors.New("denied")
+
+	c.FailSubscribe("orders", denied)
+	if _, err := c.Subscribe(ctx, &domain.SubscriptionConfig{Subject: "orders"}); !errors.Is(err, denied) {
+		t.Errorf("expected injected subscribe error, got %v", err)
+	}
+
+	c.Deliver(&domain.ReceivedMessage{Subject: "orders", Data: []byte("a")})
+	c.FailFetch("orders", denied)
+	if _, err := c.Fetch(ctx, &domain.SubscriptionConfig{Subject: "orders"}); !errors.Is(err, denied) {
+		t.Errorf("expected injected fetch error, got %v", err)
+	}
+	if c.Pending("orders") != 1 {
+		t.Error("failed fetch should keep messages queued")
+	}
+
+	stream, _ := c.Subscribe(ctx, &domain.SubscriptionConfig{Subject: "orders"})
+	stream.Recv()
+	c.EndStream("orders")
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	stream, _ = c.Subscribe(cctx, &domain.SubscriptionConfig{Subject: "orders"})
+	if _, err := stream.Recv(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context error, got %v", err)
+	}
+
+	stream, _ = c.Subscribe(ctx, &domain.SubscriptionConfig{Subject: "orders"})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		c.Close()
+	}()
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("expected io.EOF after Close, got %v", err)
+	}
+	if _, err := c.Subscribe(ctx, &domain.SubscriptionConfig{Subject: "orders"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestScriptedEgressClient_WithSubscriber(t *testing.T) {
+	c := NewScriptedEgressClient()
+	sub, err := subscriberUsecase.New(&subscriberUsecase.Config{Client: c, DurableName: "it", BatchSize: 10, Logger: quietLogger{}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	var received []uint64
+	done := make(chan struct{})
+	sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, msg.Sequence)
+		if len(received) == 3 {
+			close(done)
+		}
+		return nil
+	})
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer sub.Stop()
+
+	c.Deliver(
+		&domain.ReceivedMessage{Subject: "orders", Data: []byte("1")},
+		&domain.ReceivedMessage{Subject: "orders", Data: []byte("2")},
+		&domain.ReceivedMessage{Subject: "orders", Data: []byte("3")},
+	)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for messages")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, seq := range received {
+		if seq != uint64(i+1) {
+			t.Errorf("expected sequences 1..3 in order, got %v", received)
+			break
+		}
+	}
+	if subs := c.Subscriptions(); len(subs) == 0 || subs[0].DurableName != "it" {
+		t.Errorf("unexpected subscriptions %+v", subs)
+	}
+}
@@ -0,0 +1,296 @@
+package connectortest
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Errors returned by fault-injecting clients
+var (
+	// ErrInjected is returned for injected call failures
+	ErrInjected = errors.New("injected fault")
+	// ErrStreamDropped is returned by streams that were dropped
+	ErrStreamDropped = errors.New("injected stream drop")
+)
+
+// FaultConfig configures the faults injected by FaultyIngressClient and
+// FaultyEgressClient. Rates are probabilities between 0 and 1.
+type FaultConfig struct {
+	// Latency delays every call and stream receive; Jitter adds a random
+	// delay of up to Jitter on top
+	Latency time.Duration
+	Jitter  time.Duration
+	// ErrorRate fails calls with Error before they reach the wrapped client
+	ErrorRate float64
+	// Error is the injected error (default ErrInjected)
+	Error error
+	// LostAckRate lets publishes succeed but returns Error, as if the
+	// response was lost; retries then publish duplicates
+	LostAckRate float64
+	// DropRate ends notification and message streams with ErrStreamDropped
+	// on a receive
+	DropRate float64
+	// DuplicateRate delivers a fetched message a second time
+	DuplicateRate float64
+	// Seed makes the faults reproducible; zero seeds from the clock
+	Seed int64
+}
+
+// FaultStats counts the injected faults
+type FaultStats struct {
+	Delays     int64
+	Errors     int64
+	LostAcks   int64
+	Drops      int64
+	Duplicates int64
+}
+
+// faults decides which faults to inject
+type faults struct {
+	config FaultConfig
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+
+	delays     atomic.Int64
+	errors     atomic.Int64
+	lostAcks   atomic.Int64
+	drops      atomic.Int64
+	duplicates atomic.Int64
+}
+
+func newFaults(config *FaultConfig) *faults {
+	f := &faults{}
+	if config != nil {
+		f.config = *config
+	}
+	if f.config.Error == nil {
+		f.config.Error = ErrInjected
+	}
+	seed := f.config.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	f.rnd = rand.New(rand.NewSource(seed))
+	return f
+}
+
+// hit reports whether a fault with the given rate happens
+func (f *faults) hit(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rnd.Float64() < rate
+}
+
+// delay sleeps for the configured latency
+func (f *faults) delay(ctx context.Context) error {
+	d := f.config.Latency
+	if f.config.Jitter > 0 {
+		f.mu.Lock()
+		d += time.Duration(f.rnd.Int63n(int64(f.config.Jitter) + 1))
+		f.mu.Unlock()
+	}
+	if d <= 0 {
+		return nil
+	}
+	f.delays.Add(1)
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// call delays a call and decides whether it fails
+func (f *faults) call(ctx context.Context) error {
+	if err := f.delay(ctx); err != nil {
+		return err
+	}
+	if f.hit(f.config.ErrorRate) {
+		f.errors.Add(1)
+		return f.config.Error
+	}
+	return nil
+}
+
+func (f *faults) stats() FaultStats {
+	return FaultStats{
+		Delays:     f.delays.Load(),
+		Errors:     f.errors.Load(),
+		LostAcks:   f.lostAcks.Load(),
+		Drops:      f.drops.Load(),
+		Duplicates: f.duplicates.Load(),
+	}
+}
+
+// FaultyIngressClient wraps a domain.IngressClient and injects latency,
+// errors and lost acknowledgements into publishes
+type FaultyIngressClient struct {
+	client domain.IngressClient
+	faults *faults
+}
+
+// NewFaultyIngressClient wraps client with fault injection
+func NewFaultyIngressClient(client domain.IngressClient, config *FaultConfig) *FaultyIngressClient {
+	return &FaultyIngressClient{client: client, faults: newFaults(config)}
+}
+
+// Publish publishes through the wrapped client unless a fault is injected
+func (c *FaultyIngressClient) Publish(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+	if err := c.faults.call(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := c.client.Publish(ctx, msg)
+	if err == nil && c.faults.hit(c.faults.config.LostAckRate) {
+		c.faults.lostAcks.Add(1)
+		return nil, c.faults.config.Error
+	}
+	return result, err
+}
+
+// Close closes the wrapped client
+func (c *FaultyIngressClient) Close() error {
+	return c.client.Close()
+}
+
+// Stats returns the faults injected so far
+func (c *FaultyIngressClient) Stats() FaultStats {
+	return c.faults.stats()
+}
+
+// FaultyEgressClient wraps a domain.EgressClient and injects latency,
+// errors, stream drops and duplicate deliveries
+type FaultyEgressClient struct {
+	client domain.EgressClient
+	faults *faults
+}
+
+// NewFaultyEgressClient wraps client with fault injection
+func NewFaultyEgressClient(client domain.EgressClient, config *FaultConfig) *FaultyEgressClient {
+	return &FaultyEgressClient{client: client, faults: newFaults(config)}
+}
+
+// Subscribe subscribes through the wrapped client unless a fault is injected
+func (c *FaultyEgressClient) Subscribe(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+	if err := c.faults.call(ctx); err != nil {
+		return nil, err
+	}
+	stream, err := c.client.Subscribe(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return &faultyNotificationStream{stream: stream, faults: c.faults, ctx: ctx}, nil
+}
+
+// Fetch fetches through the wrapped client unless a fault is injected
+func (c *FaultyEgressClient) Fetch(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+	if err := c.faults.call(ctx); err != nil {
+		return nil, err
+	}
+	stream, err := c.client.Fetch(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return &faultyMessageStream{stream: stream, faults: c.faults, ctx: ctx}, nil
+}
+
+// GetLastSequence calls the wrapped client unless a fault is injected
+func (c *FaultyEgressClient) GetLastSequence(ctx context.Context, subject string) (uint64, error) {
+	if err := c.faults.call(ctx); err != nil {
+		return 0, err
+	}
+	return c.client.GetLastSequence(ctx, subject)
+}
+
+// Close closes the wrapped client
+func (c *FaultyEgressClient) Close() error {
+	return c.client.Close()
+}
+
+// Stats returns the faults injected so far
+func (c *FaultyEgressClient) Stats() FaultStats {
+	return c.faults.stats()
+}
+
+// faultyNotificationStream may drop a notification stream
+type faultyNotificationStream struct {
+	stream  domain.NotificationStream
+	faults  *faults
+	ctx     context.Context
+	dropped bool
+}
+
+// Recv receives from the wrapped stream unless the stream is dropped
+func (s *faultyNotificationStream) Recv() (*domain.Notification, error) {
+	if s.dropped {
+		return nil, ErrStreamDropped
+	}
+	n, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.faults.delay(s.ctx); err != nil {
+		return nil, err
+	}
+	if s.faults.hit(s.faults.config.DropRate) {
+		s.faults.drops.Add(1)
+		s.dropped = true
+		return nil, ErrStreamDropped
+	}
+	return n, nil
+}
+
+// faultyMessageStream may drop a batch or deliver messages twice
+type faultyMessageStream struct {
+	stream    domain.MessageStream
+	faults    *faults
+	ctx       context.Context
+	dropped   bool
+	duplicate *domain.ReceivedMessage
+}
+
+// Recv receives from the wrapped stream, possibly repeating the previous
+// message or dropping the stream
+func (s *faultyMessageStream) Recv() (*domain.ReceivedMessage, error) {
+	if s.dropped {
+		return nil, ErrStreamDropped
+	}
+	if msg := s.duplicate; msg != nil {
+		s.duplicate = nil
+		return msg, nil
+	}
+
+	if err := s.faults.delay(s.ctx); err != nil {
+		return nil, err
+	}
+	if s.faults.hit(s.faults.config.DropRate) {
+		s.faults.drops.Add(1)
+		s.dropped = true
+		return nil, ErrStreamDropped
+	}
+
+	msg, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if s.faults.hit(s.faults.config.DuplicateRate) {
+		s.faults.duplicates.Add(1)
+		copied := *msg
+		s.duplicate = &copied
+	}
+	return msg, nil
+}
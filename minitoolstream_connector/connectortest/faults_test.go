@@ -0,0 +1,188 @@
+package connectortest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	subscriberUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/subscriber"
+)
+
+func TestFaultyIngressClient(t *testing.T) {
+	ctx := context.Background()
+	msg := &domain.PublishMessage{Subject: "orders", Data: []byte("x")}
+
+	t.Run("errors", func(t *testing.T) {
+		inner := NewRecordingIngressClient()
+		c := NewFaultyIngressClient(inner, &FaultConfig{ErrorRate: 1})
+
+		if _, err := c.Publish(ctx, msg); !errors.Is(err, ErrInjected) {
+			t.Errorf("expected ErrInjected, got %v", err)
+		}
+		inner.AssertCount(t, 0)
+		if c.Stats().Errors != 1 {
+			t.Errorf("unexpected stats %+v", c.Stats())
+		}
+	})
+
+	t.Run("lost acknowledgements", func(t *testing.T) {
+		inner := NewRecordingIngressClient()
+		lost := errors.New("deadline exceeded")
+		c := NewFaultyIngressClient(inner, &FaultConfig{LostAckRate: 1, Error: lost})
+
+		if _, err := c.Publish(ctx, msg); !errors.Is(err, lost) {
+			t.Errorf("expected custom error, got %v", err)
+		}
+		inner.AssertCount(t, 1)
+		if c.Stats().LostAcks != 1 {
+			t.Errorf("unexpected stats %+v", c.Stats())
+		}
+	})
+
+	t.Run("latency", func(t *testing.T) {
+		c := NewFaultyIngressClient(NewRecordingIngressClient(), &FaultConfig{Latency: 20 * time.Millisecond, Jitter: 10 * time.Millisecond})
+
+		start := time.Now()
+		if _, err := c.Publish(ctx, msg); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+			t.Errorf("expected at least 20ms latency, got %v", elapsed)
+		}
+
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := c.Publish(cctx, msg); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context error during delay, got %v", err)
+		}
+	})
+
+	t.Run("seed reproduces faults", func(t *testing.T) {
+		pattern := func() string {
+			c := NewFaultyIngressClient(NewRecordingIngressClient(), &FaultConfig{ErrorRate: 0.5, Seed: 42})
+			var s string
+			for i := 0; i < 32; i++ {
+				_, err := c.Publish(ctx, msg)
+				s += fmt.Sprint(err == nil)
+			}
+			return s
+		}
+		if pattern() != pattern() {
+			t.Error("expected the same faults for the same seed")
+		}
+	})
+}
+
+func TestFaultyEgressClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("duplicates", func(t *testing.T) {
+		inner := NewScriptedEgressClient()
+		inner.Deliver(
+			&domain.ReceivedMessage{Subject: "orders", Data: []byte("a")},
+			&domain.ReceivedMessage{Subject: "orders", Data: []byte("b")},
+		)
+		c := NewFaultyEgressClient(inner, &FaultConfig{DuplicateRate: 1})
+
+		stream, _ := c.Fetch(ctx, &domain.SubscriptionConfig{Subject: "orders"})
+		var got []uint64
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			got = append(got, msg.Sequence)
+		}
+		if fmt.Sprint(got) != "[1 1 2 2]" {
+			t.Errorf("expected every message twice, got %v", got)
+		}
+		if c.Stats().Duplicates != 2 {
+			t.Errorf("unexpected stats %+v", c.Stats())
+		}
+	})
+
+	t.Run("stream drops", func(t *testing.T) {
+		inner := NewScriptedEgressClient()
+		inner.Deliver(&domain.ReceivedMessage{Subject: "orders"}, &domain.ReceivedMessage{Subject: "orders"})
+		c := NewFaultyEgressClient(inner, &FaultConfig{DropRate: 1})
+
+		stream, _ := c.Subscribe(ctx, &domain.SubscriptionConfig{Subject: "orders"})
+		for i := 0; i < 2; i++ {
+			if _, err := stream.Recv(); !errors.Is(err, ErrStreamDropped) {
+				t.Errorf("expected ErrStreamDropped, got %v", err)
+			}
+		}
+
+		batch, _ := c.Fetch(ctx, &domain.SubscriptionConfig{Subject: "orders"})
+		if _, err := batch.Recv(); !errors.Is(err, ErrStreamDropped) {
+			t.Errorf("expected ErrStreamDropped, got %v", err)
+		}
+		if c.Stats().Drops != 2 {
+			t.Errorf("unexpected stats %+v", c.Stats())
+		}
+	})
+
+	t.Run("call errors", func(t *testing.T) {
+		c := NewFaultyEgressClient(NewScriptedEgressClient(), &FaultConfig{ErrorRate: 1})
+		if _, err := c.Subscribe(ctx, &domain.SubscriptionConfig{Subject: "orders"}); !errors.Is(err, ErrInjected) {
+			t.Errorf("expected ErrInjected from Subscribe, got %v", err)
+		}
+		if _, err := c.Fetch(ctx, &domain.SubscriptionConfig{Subject: "orders"}); !errors.Is(err, ErrInjected) {
+			t.Errorf("expected ErrInjected from Fetch, got %v", err)
+		}
+		if _, err := c.GetLastSequence(ctx, "orders"); !errors.Is(err, ErrInjected) {
+			t.Errorf("expected ErrInjected from GetLastSequence, got %v", err)
+		}
+	})
+}
+
+func TestFaultyEgressClient_SubscriberDedup(t *testing.T) {
+	inner := NewScriptedEgressClient()
+	c := NewFaultyEgressClient(inner, &FaultConfig{DuplicateRate: 1})
+
+	sub, err := subscriberUsecase.New(&subscriberUsecase.Config{
+		Client:      c,
+		DurableName: "dedup",
+		DedupWindow: 16,
+		Logger:      quietLogger{},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	var handled []uint64
+	sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, msg.Sequence)
+		return nil
+	})
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer sub.Stop()
+
+	inner.Deliver(
+		&domain.ReceivedMessage{Subject: "orders"},
+		&domain.ReceivedMessage{Subject: "orders"},
+		&domain.ReceivedMessage{Subject: "orders"},
+	)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for c.Stats().Duplicates < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(handled) != "[1 2 3]" {
+		t.Errorf("expected duplicates to be filtered, handled %v", handled)
+	}
+}
@@ -0,0 +1,242 @@
+// Package connectortest provides fakes of domain.IngressClient and
+// domain.EgressClient for testing code built on the connector without a
+// MiniToolStream server.
+//
+// RecordingIngressClient captures every published message and offers
+// assertion helpers; ScriptedEgressClient delivers queued messages to
+// subscribers and can inject errors at each step. Server runs the gRPC
+// services in memory for end-to-end tests of the real clients, and
+// FaultyIngressClient and FaultyEgressClient wrap any client with injected
+// latency, errors, stream drops and duplicates. FakeClock controls time for
+// components that take a domain.Clock.
+package connectortest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// ErrClosed is returned by fakes after Close
+var ErrClosed = errors.New("client closed")
+
+// RecordingIngressClient is a domain.IngressClient that records published
+// messages. Messages are assigned increasing sequences per subject. It is
+// safe for concurrent use.
+type RecordingIngressClient struct {
+	// PublishFunc, when set, decides the result of each publish instead of the
+	// default success result; the message is recorded either way
+	PublishFunc func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error)
+
+	mu        sync.Mutex
+	messages  []*domain.PublishMessage
+	sequences map[string]uint64
+	failures  []error
+	closed    bool
+	changed   chan struct{}
+}
+
+// NewRecordingIngressClient creates a new recording ingress client
+func NewRecordingIngressClient() *RecordingIngressClient {
+	return &RecordingIngressClient{
+		sequences: make(map[string]uint64),
+		changed:   make(chan struct{}),
+	}
+}
+
+// Publish records the message and returns its result
+func (c *RecordingIngressClient) Publish(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+
+	recorded := copyPublishMessage(msg)
+	c.messages = append(c.messages, recorded)
+	close(c.changed)
+	c.changed = make(chan struct{})
+
+	if len(c.failures) > 0 {
+		err := c.failures[0]
+		c.failures = c.failures[1:]
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	publishFunc := c.PublishFunc
+	if publishFunc == nil {
+		c.sequences[msg.Subject]++
+		seq := c.sequences[msg.Subject]
+		c.mu.Unlock()
+		return &domain.PublishResult{Sequence: seq, ObjectName: fmt.Sprintf("%s-%d", msg.Subject, seq)}, nil
+	}
+	c.mu.Unlock()
+
+	return publishFunc(ctx, recorded)
+}
+
+// Close marks the client closed; later publishes fail with ErrClosed
+func (c *RecordingIngressClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+// Closed reports whether Close was called
+func (c *RecordingIngressClient) Closed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// FailNext makes the next publishes return errs, one per call, in order
+func (c *RecordingIngressClient) FailNext(errs ...error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = append(c.failures, errs...)
+}
+
+// Messages returns the recorded messages in publish order
+func (c *RecordingIngressClient) Messages() []*domain.PublishMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*domain.PublishMessage(nil), c.messages...)
+}
+
+// MessagesFor returns the recorded messages of a subject
+func (c *RecordingIngressClient) MessagesFor(subject string) []*domain.PublishMessage {
+	var messages []*domain.PublishMessage
+	for _, msg := range c.Messages() {
+		if msg.Subject == subject {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// Last returns the last recorded message, or nil
+func (c *RecordingIngressClient) Last() *domain.PublishMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.messages) == 0 {
+		return nil
+	}
+	return c.messages[len(c.messages)-1]
+}
+
+// Reset forgets the recorded messages, sequences and pending failures
+func (c *RecordingIngressClient) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = nil
+	c.sequences = make(map[string]uint64)
+	c.failures = nil
+}
+
+// WaitFor waits until at least n messages were recorded, failing the test
+// after timeout. Use it with publishers that send asynchronously.
+func (c *RecordingIngressClient) WaitFor(t testing.TB, n int, timeout time.Duration) []*domain.PublishMessage {
+	t.Helper()
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		c.mu.Lock()
+		count, changed := len(c.messages), c.changed
+		c.mu.Unlock()
+		if count >= n {
+			return c.Messages()
+		}
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			t.Fatalf("timed out after %v waiting for %d published messages, got %d", timeout, n, count)
+			return nil
+		}
+	}
+}
+
+// AssertCount fails the test unless exactly n messages were recorded
+func (c *RecordingIngressClient) AssertCount(t testing.TB, n int) {
+	t.Helper()
+	if got := len(c.Messages()); got != n {
+		t.Errorf("expected %d published messages, got %d", n, got)
+	}
+}
+
+// AssertSubjects fails the test unless messages were published to exactly
+// these subjects, in order
+func (c *RecordingIngressClient) AssertSubjects(t testing.TB, subjects ...string) {
+	t.Helper()
+	messages := c.Messages()
+	got := make([]string, len(messages))
+	for i, msg := range messages {
+		got[i] = msg.Subject
+	}
+	if fmt.Sprint(got) != fmt.Sprint(subjects) {
+		t.Errorf("expected subjects %q, got %q", subjects, got)
+	}
+}
+
+// AssertPublished fails the test unless a message with this payload was
+// published to subject
+func (c *RecordingIngressClient) AssertPublished(t testing.TB, subject string, data []byte) {
+	t.Helper()
+	for _, msg := range c.MessagesFor(subject) {
+		if string(msg.Data) == string(data) {
+			return
+		}
+	}
+	t.Errorf("expected a message %q on subject %s", truncate(data), subject)
+}
+
+// AssertHeader fails the test unless the last message on subject has the
+// header key set to value
+func (c *RecordingIngressClient) AssertHeader(t testing.TB, subject, key, value string) {
+	t.Helper()
+	messages := c.MessagesFor(subject)
+	if len(messages) == 0 {
+		t.Errorf("expected a message on subject %s, got none", subject)
+		return
+	}
+	got, ok := messages[len(messages)-1].Headers[key]
+	if !ok {
+		t.Errorf("expected header %s=%q on subject %s, header is missing", key, value, subject)
+	} else if got != value {
+		t.Errorf("expected header %s=%q on subject %s, got %q", key, value, subject, got)
+	}
+}
+
+// copyPublishMessage copies a message so later changes by the caller do not
+// affect the record
+func copyPublishMessage(msg *domain.PublishMessage) *domain.PublishMessage {
+	copied := *msg
+	copied.Data = append([]byte(nil), msg.Data...)
+	if msg.Headers != nil {
+		copied.Headers = make(map[string]string, len(msg.Headers))
+		for k, v := range msg.Headers {
+			copied.Headers[k] = v
+		}
+	}
+	return &copied
+}
+
+// truncate shortens payloads quoted in failure messages
+func truncate(data []byte) string {
+	if len(data) > 64 {
+		return string(data[:64]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,140 @@
+package connectortest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// failureRecorder is a testing.TB that records failures instead of failing
+type failureRecorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *failureRecorder) Helper() {}
+
+func (r *failureRecorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *failureRecorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestRecordingIngressClient_Publish(t *testing.T) {
+	c := NewRecordingIngressClient()
+	ctx := context.Background()
+
+	msg := &domain.PublishMessage{Subject: "orders", Data: []byte("a"), Headers: map[string]string{"k": "v"}}
+	result, err := c.Publish(ctx, msg)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if result.Sequence != 1 || result.ObjectName != "orders-1" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	// The record is a copy
+	msg.Data[0] = 'x'
+	msg.Headers["k"] = "changed"
+
+	c.Publish(ctx, &domain.PublishMessage{Subject: "orders", Data: []byte("b"), Headers: map[string]string{"k": "w"}})
+	result, _ = c.Publish(ctx, &domain.PublishMessage{Subject: "audit", Data: []byte("c")})
+	if result.Sequence != 1 {
+		t.Errorf("expected sequences per subject, got %d", result.Sequence)
+	}
+
+	c.AssertCount(t, 3)
+	c.AssertSubjects(t, "orders", "orders", "audit")
+	c.AssertPublished(t, "orders", []byte("a"))
+	c.AssertHeader(t, "orders", "k", "w")
+	if got := c.Messages()[0]; string(got.Data) != "a" || got.Headers["k"] != "v" {
+		t.Errorf("record changed with the published message: %q %v", got.Data, got.Headers)
+	}
+
+	if got := len(c.MessagesFor("orders")); got != 2 {
+		t.Errorf("expected 2 orders messages, got %d", got)
+	}
+	if string(c.Last().Data) != "c" {
+		t.Errorf("unexpected last message %q", c.Last().Data)
+	}
+
+	c.Reset()
+	c.AssertCount(t, 0)
+	if c.Last() != nil {
+		t.Error("expected no last message after reset")
+	}
+}
+
+func TestRecordingIngressClient_Assertions(t *testing.T) {
+	c := NewRecordingIngressClient()
+	c.Publish(context.Background(), &domain.PublishMessage{Subject: "orders", Data: []byte("a"), Headers: map[string]string{"k": "v"}})
+
+	r := &failureRecorder{TB: t}
+	c.AssertCount(r, 2)
+	c.AssertSubjects(r, "audit")
+	c.AssertPublished(r, "orders", []byte("b"))
+	c.AssertHeader(r, "orders", "k", "w")
+	c.AssertHeader(r, "orders", "other", "v")
+	c.AssertHeader(r, "audit", "k", "v")
+	c.WaitFor(r, 2, 10*time.Millisecond)
+
+	if len(r.failures) != 7 {
+		t.Errorf("expected 7 failures, got %d: %q", len(r.failures), r.failures)
+	}
+}
+
+func TestRecordingIngressClient_Failures(t *testing.T) {
+	c := NewRecordingIngressClient()
+	ctx := context.Background()
+	unavailable := errors.New("unavailable")
+
+	c.FailNext(unavailable)
+	if _, err := c.Publish(ctx, &domain.PublishMessage{Subject: "s"}); !errors.Is(err, unavailable) {
+		t.Errorf("expected injected error, got %v", err)
+	}
+	if _, err := c.Publish(ctx, &domain.PublishMessage{Subject: "s"}); err != nil {
+		t.Errorf("expected success after injected error, got %v", err)
+	}
+
+	c.PublishFunc = func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+		return &domain.PublishResult{StatusCode: 1, ErrorMessage: "denied"}, nil
+	}
+	if result, _ := c.Publish(ctx, &domain.PublishMessage{Subject: "s"}); result.ErrorMessage != "denied" {
+		t.Errorf("expected PublishFunc result, got %+v", result)
+	}
+	c.AssertCount(t, 3)
+
+	c.Close()
+	if !c.Closed() {
+		t.Error("expected client to be closed")
+	}
+	if _, err := c.Publish(ctx, &domain.PublishMessage{Subject: "s"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestRecordingIngressClient_WaitFor(t *testing.T) {
+	c := NewRecordingIngressClient()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(10 * time.Millisecond)
+			c.Publish(context.Background(), &domain.PublishMessage{Subject: "async"})
+		}()
+	}
+
+	if messages := c.WaitFor(t, 5, time.Second); len(messages) != 5 {
+		t.Errorf("expected 5 messages, got %d", len(messages))
+	}
+	wg.Wait()
+}
@@ -0,0 +1,227 @@
+package connectortest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// serverBufferSize is the in-memory connection buffer of a Server
+const serverBufferSize = 1 << 20
+
+// Server runs in-memory Ingress and Egress gRPC services over bufconn, so
+// end-to-end tests exercise the real gRPC clients without a deployment:
+//
+//	srv := connectortest.StartServer(t)
+//	pub, _ := minitoolstream.NewPublisher(srv.Address(), srv.DialOptions()...)
+//	sub, _ := minitoolstream.NewSubscriber(srv.Address(), "consumer", srv.DialOptions()...)
+//
+// Subjects are stored in memory with sequences starting at 1. Each durable
+// consumer has a position per subject that Fetch advances; Subscribe sends
+// one notification per message after that position, then one per publish.
+type Server struct {
+	pb.UnimplementedIngressServiceServer
+	pb.UnimplementedEgressServiceServer
+
+	listener *bufconn.Listener
+	grpc     *grpc.Server
+	done     chan struct{}
+
+	mu        sync.Mutex
+	subjects  map[string][]*pb.Message
+	positions map[consumerKey]uint64
+	changed   chan struct{}
+	closeOnce sync.Once
+}
+
+// consumerKey identifies the position of a durable consumer on a subject
+type consumerKey struct {
+	durable string
+	subject string
+}
+
+// NewServer starts a new in-memory server
+func NewServer() *Server {
+	s := &Server{
+		listener:  bufconn.Listen(serverBufferSize),
+		grpc:      grpc.NewServer(),
+		done:      make(chan struct{}),
+		subjects:  make(map[string][]*pb.Message),
+		positions: make(map[consumerKey]uint64),
+		changed:   make(chan struct{}),
+	}
+	pb.RegisterIngressServiceServer(s.grpc, s)
+	pb.RegisterEgressServiceServer(s.grpc, s)
+
+	go s.grpc.Serve(s.listener)
+	return s
+}
+
+// StartServer starts a new in-memory server that is closed when the test ends
+func StartServer(t testing.TB) *Server {
+	t.Helper()
+	s := NewServer()
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Address returns the target to pass to NewPublisher and NewSubscriber
+// together with DialOptions
+func (s *Server) Address() string {
+	return "passthrough:///bufconn"
+}
+
+// DialOptions returns the dial options connecting clients to the server
+func (s *Server) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return s.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+}
+
+// Close stops the server, ending all streams
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.grpc.Stop()
+		s.listener.Close()
+	})
+}
+
+// Messages returns the stored messages of subject
+func (s *Server) Messages(subject string) []*domain.ReceivedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	messages := make([]*domain.ReceivedMessage, len(s.subjects[subject]))
+	for i, msg := range s.subjects[subject] {
+		messages[i] = &domain.ReceivedMessage{
+			Subject:   msg.Subject,
+			Sequence:  msg.Sequence,
+			Data:      msg.Data,
+			Headers:   msg.Headers,
+			Timestamp: msg.Timestamp.AsTime(),
+		}
+	}
+	return messages
+}
+
+// Publish stores a message
+func (s *Server) Publish(ctx context.Context, req *pb.PublishRequest) (*pb.PublishResponse, error) {
+	if req.Subject == "" {
+		return nil, status.Error(codes.InvalidArgument, "subject cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seq := uint64(len(s.subjects[req.Subject]) + 1)
+	s.subjects[req.Subject] = append(s.subjects[req.Subject], &pb.Message{
+		Subject:   req.Subject,
+		Sequence:  seq,
+		Data:      req.Data,
+		Headers:   req.Headers,
+		Timestamp: timestamppb.New(time.Now()),
+	})
+	close(s.changed)
+	s.changed = make(chan struct{})
+
+	return &pb.PublishResponse{Sequence: seq, ObjectName: fmt.Sprintf("%s-%d", req.Subject, seq)}, nil
+}
+
+// Subscribe notifies about messages after the consumer position, then about
+// every new message, until the client cancels or the server closes
+func (s *Server) Subscribe(req *pb.SubscribeRequest, stream grpc.ServerStreamingServer[pb.Notification]) error {
+	if req.Subject == "" {
+		return status.Error(codes.InvalidArgument, "subject cannot be empty")
+	}
+
+	key := consumerKey{durable: req.DurableName, subject: req.Subject}
+	s.mu.Lock()
+	if req.StartSequence != nil {
+		s.positions[key] = max(*req.StartSequence, 1)
+	}
+	notified := s.position(key) - 1
+	s.mu.Unlock()
+
+	for {
+		s.mu.Lock()
+		last := uint64(len(s.subjects[req.Subject]))
+		changed := s.changed
+		s.mu.Unlock()
+
+		for ; notified < last; notified++ {
+			if err := stream.Send(&pb.Notification{Subject: req.Subject, Sequence: notified + 1}); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-stream.Context().Done():
+			return nil
+		case <-s.done:
+			return nil
+		}
+	}
+}
+
+// Fetch sends up to BatchSize messages from the consumer position and
+// advances it
+func (s *Server) Fetch(req *pb.FetchRequest, stream grpc.ServerStreamingServer[pb.Message]) error {
+	if req.Subject == "" {
+		return status.Error(codes.InvalidArgument, "subject cannot be empty")
+	}
+
+	key := consumerKey{durable: req.DurableName, subject: req.Subject}
+	s.mu.Lock()
+	messages := s.subjects[req.Subject]
+	start := s.position(key) - 1
+	end := uint64(len(messages))
+	if req.BatchSize > 0 && start+uint64(req.BatchSize) < end {
+		end = start + uint64(req.BatchSize)
+	}
+	var batch []*pb.Message
+	if start < end {
+		batch = messages[start:end]
+		s.positions[key] = end + 1
+	}
+	s.mu.Unlock()
+
+	for _, msg := range batch {
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetLastSequence returns the last stored sequence of a subject
+func (s *Server) GetLastSequence(ctx context.Context, req *pb.GetLastSequenceRequest) (*pb.GetLastSequenceResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &pb.GetLastSequenceResponse{LastSequence: uint64(len(s.subjects[req.Subject]))}, nil
+}
+
+// position returns the next sequence of a consumer. The caller must hold s.mu.
+func (s *Server) position(key consumerKey) uint64 {
+	if pos, ok := s.positions[key]; ok {
+		return pos
+	}
+	return 1
+}
@@ -0,0 +1,173 @@
+package connectortest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	minitoolstream "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domaintest"
+	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
+)
+
+func publishString(t *testing.T, pub minitoolstream.Publisher, subject, data string) {
+	t.Helper()
+	err := pub.Publish(context.Background(), domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{Subject: subject, Data: []byte(data), Headers: map[string]string{"n": data}}, nil
+	}))
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+}
+
+func TestServer_EndToEnd(t *testing.T) {
+	srv := StartServer(t)
+
+	pub, err := minitoolstream.NewPublisher(srv.Address(), srv.DialOptions()...)
+	if err != nil {
+		t.Fatalf("NewPublisher failed: %v", err)
+	}
+	defer pub.Close()
+
+	// Published before the subscriber starts
+	publishString(t, pub, "orders", "1")
+
+	sub, err := minitoolstream.NewSubscriber(srv.Address(), "e2e", srv.DialOptions()...)
+	if err != nil {
+		t.Fatalf("NewSubscriber failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	var received []string
+	done := make(chan struct{})
+	sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, fmt.Sprintf("%d:%s:%s", msg.Sequence, msg.Data, msg.Headers["n"]))
+		if len(received) == 3 {
+			close(done)
+		}
+		return nil
+	})
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer sub.Stop()
+
+	publishString(t, pub, "orders", "2")
+	publishString(t, pub, "orders", "3")
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for messages")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"1:1:1", "2:2:2", "3:3:3"}
+	if fmt.Sprint(received) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, received)
+	}
+	if got := len(srv.Messages("orders")); got != 3 {
+		t.Errorf("expected 3 stored messages, got %d", got)
+	}
+}
+
+func TestServer_EgressClient(t *testing.T) {
+	srv := StartServer(t)
+	ctx := context.Background()
+
+	ingress, err := grpcClient.NewIngressClient(srv.Address(), srv.DialOptions()...)
+	if err != nil {
+		t.Fatalf("NewIngressClient failed: %v", err)
+	}
+	defer ingress.Close()
+	egress, err := grpcClient.NewEgressClient(srv.Address(), srv.DialOptions()...)
+	if err != nil {
+		t.Fatalf("NewEgressClient failed: %v", err)
+	}
+	defer egress.Close()
+
+	for i := 1; i <= 5; i++ {
+		result, err := ingress.Publish(ctx, &domain.PublishMessage{Subject: "logs", Data: []byte{byte('0' + i)}})
+		if err != nil || result.Sequence != uint64(i) {
+			t.Fatalf("unexpected publish result %+v (%v)", result, err)
+		}
+	}
+
+	if last, err := egress.GetLastSequence(ctx, "logs"); err != nil || last != 5 {
+		t.Errorf("expected last sequence 5, got %d (%v)", last, err)
+	}
+
+	fetch := func(durable string, batch int32) string {
+		t.Helper()
+		stream, err := egress.Fetch(ctx, &domain.SubscriptionConfig{Subject: "logs", DurableName: durable, BatchSize: batch})
+		if err != nil {
+			t.Fatalf("Fetch failed: %v", err)
+		}
+		var data string
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				break
+			}
+			data += string(msg.Data)
+		}
+		return data
+	}
+
+	if got := fetch("a", 2); got != "12" {
+		t.Errorf("expected first batch 12, got %q", got)
+	}
+	if got := fetch("a", 10); got != "345" {
+		t.Errorf("expected remaining batch 345, got %q", got)
+	}
+	if got := fetch("b", 0); got != "12345" {
+		t.Errorf("expected independent consumer to read all, got %q", got)
+	}
+
+	// Subscribing from the latest position skips stored messages
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := egress.Subscribe(sctx, &domain.SubscriptionConfig{
+		Subject:       "logs",
+		DurableName:   "c",
+		StartPosition: domain.StartPosition{Kind: domain.StartLatest},
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	ingress.Publish(ctx, &domain.PublishMessage{Subject: "logs", Data: []byte("6")})
+	if n, err := stream.Recv(); err != nil || n.Sequence != 6 {
+		t.Errorf("expected notification for sequence 6, got %+v (%v)", n, err)
+	}
+}
+
+// newGRPCClients connects the gRPC clients to a fresh server
+func newGRPCClients(t *testing.T) domaintest.Clients {
+	srv := StartServer(t)
+	ingress, err := grpcClient.NewIngressClient(srv.Address(), srv.DialOptions()...)
+	if err != nil {
+		t.Fatalf("NewIngressClient failed: %v", err)
+	}
+	egress, err := grpcClient.NewEgressClient(srv.Address(), srv.DialOptions()...)
+	if err != nil {
+		t.Fatalf("NewEgressClient failed: %v", err)
+	}
+	t.Cleanup(func() {
+		ingress.Close()
+		egress.Close()
+	})
+	return domaintest.Clients{Ingress: ingress, Egress: egress}
+}
+
+func TestServer_Contract(t *testing.T) {
+	domaintest.RunIngressClientTests(t, func(t *testing.T) domain.IngressClient {
+		return newGRPCClients(t).Ingress
+	})
+	domaintest.RunEgressClientTests(t, newGRPCClients)
+}
@@ -0,0 +1,127 @@
+package minitoolstream_connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/credentials/oauth"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
+)
+
+// dialSettings holds the connection settings shared by both builders
+type dialSettings struct {
+	dialOpts       []grpc.DialOption
+	transportCreds credentials.TransportCredentials
+	perRPCCreds    credentials.PerRPCCredentials
+	contextDialer  func(ctx context.Context, addr string) (net.Conn, error)
+	unary          []grpc.UnaryClientInterceptor
+	stream         []grpc.StreamClientInterceptor
+	lbPolicy       string
+	serviceConfig  string
+	deadlines      *grpcClient.Deadlines
+	clock          domain.Clock
+}
+
+// isInsecure reports whether the connection would run without transport security.
+// Without explicit transport credentials and dial options the clients fall back
+// to insecure credentials.
+func (s *dialSettings) isInsecure() bool {
+	if s.transportCreds != nil {
+		return s.transportCreds.Info().SecurityProtocol == "insecure"
+	}
+	return len(s.dialOpts) == 0
+}
+
+// validate checks that the credentials are consistent with each other and
+// that the service config can be built
+func (s *dialSettings) validate() error {
+	var errs []error
+	if s.perRPCCreds != nil && s.perRPCCreds.RequireTransportSecurity() && s.isInsecure() {
+		errs = append(errs, fmt.Errorf("per-RPC credentials require transport security, but the connection is insecure"))
+	}
+	if s.lbPolicy != "" && balancer.Get(s.lbPolicy) == nil {
+		errs = append(errs, fmt.Errorf("unknown load balancing policy %q", s.lbPolicy))
+	}
+	if _, err := s.buildServiceConfig(); err != nil {
+		errs = append(errs, err)
+	}
+	if d := s.deadlines; d != nil && (d.Publish < 0 || d.Fetch < 0 || d.SubscribeEstablish < 0 || d.GetLastSequence < 0) {
+		errs = append(errs, fmt.Errorf("deadlines cannot be negative, got %+v", *d))
+	}
+	return errors.Join(errs...)
+}
+
+// buildServiceConfig returns the default service config JSON, with the load
+// balancing policy taking precedence over one in the configured service config
+func (s *dialSettings) buildServiceConfig() (string, error) {
+	if s.lbPolicy == "" {
+		if s.serviceConfig != "" && !json.Valid([]byte(s.serviceConfig)) {
+			return "", fmt.Errorf("invalid service config: not valid JSON")
+		}
+		return s.serviceConfig, nil
+	}
+
+	config := map[string]interface{}{}
+	if s.serviceConfig != "" {
+		if err := json.Unmarshal([]byte(s.serviceConfig), &config); err != nil {
+			return "", fmt.Errorf("invalid service config: %w", err)
+		}
+	}
+	delete(config, "loadBalancingPolicy")
+	config["loadBalancingConfig"] = []map[string]interface{}{{s.lbPolicy: map[string]interface{}{}}}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode service config: %w", err)
+	}
+	return string(data), nil
+}
+
+// options assembles the dial options passed to the gRPC client
+func (s *dialSettings) options() []grpc.DialOption {
+	opts := append([]grpc.DialOption(nil), s.dialOpts...)
+	if s.transportCreds != nil {
+		opts = append(opts, grpc.WithTransportCredentials(s.transportCreds))
+	} else if len(opts) == 0 && s.needsDefaultCredentials() {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	if s.perRPCCreds != nil {
+		opts = append(opts, grpc.WithPerRPCCredentials(s.perRPCCreds))
+	}
+	if s.contextDialer != nil {
+		opts = append(opts, grpc.WithContextDialer(s.contextDialer))
+	}
+	if len(s.unary) > 0 {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(s.unary...))
+	}
+	if len(s.stream) > 0 {
+		opts = append(opts, grpc.WithChainStreamInterceptor(s.stream...))
+	}
+	if config, err := s.buildServiceConfig(); err == nil && config != "" {
+		opts = append(opts, grpc.WithDefaultServiceConfig(config))
+	}
+	return opts
+}
+
+// needsDefaultCredentials reports whether settings other than credentials produce
+// dial options, so the client no longer applies its insecure default by itself
+func (s *dialSettings) needsDefaultCredentials() bool {
+	return s.perRPCCreds != nil || s.contextDialer != nil || len(s.unary) > 0 || len(s.stream) > 0 ||
+		s.lbPolicy != "" || s.serviceConfig != ""
+}
+
+// oauth2Credentials attaches bearer tokens from ts to every RPC, reusing each
+// token until it expires
+func oauth2Credentials(ts oauth2.TokenSource) credentials.PerRPCCredentials {
+	return oauth.TokenSource{TokenSource: oauth2.ReuseTokenSource(nil, ts)}
+}
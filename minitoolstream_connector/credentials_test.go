@@ -0,0 +1,363 @@
+package minitoolstream_connector
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+type testPerRPCCredentials struct {
+	secure bool
+}
+
+func (c *testPerRPCCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer token"}, nil
+}
+
+func (c *testPerRPCCredentials) RequireTransportSecurity() bool {
+	return c.secure
+}
+
+func TestDialSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings dialSettings
+		valid    bool
+	}{
+		{"defaults", dialSettings{}, true},
+		{"secure token over default insecure", dialSettings{perRPCCreds: &testPerRPCCredentials{secure: true}}, false},
+		{"secure token over explicit insecure", dialSettings{
+			transportCreds: insecure.NewCredentials(),
+			perRPCCreds:    &testPerRPCCredentials{secure: true},
+		}, false},
+		{"secure token over tls", dialSettings{
+			transportCreds: credentials.NewTLS(&tls.Config{}),
+			perRPCCreds:    &testPerRPCCredentials{secure: true},
+		}, true},
+		{"insecure token", dialSettings{perRPCCreds: &testPerRPCCredentials{}}, true},
+		{"custom dial options", dialSettings{
+			dialOpts:    []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{}))},
+			perRPCCreds: &testPerRPCCredentials{secure: true},
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.settings.validate()
+			if tt.valid && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestDialSettings_Options(t *testing.T) {
+	t.Run("no settings", func(t *testing.T) {
+		settings := dialSettings{}
+		if len(settings.options()) != 0 {
+			t.Error("expected no options so the client applies its defaults")
+		}
+	})
+
+	t.Run("credentials appended", func(t *testing.T) {
+		settings := dialSettings{
+			transportCreds: credentials.NewTLS(&tls.Config{}),
+			perRPCCreds:    &testPerRPCCredentials{secure: true},
+		}
+		if len(settings.options()) != 2 {
+			t.Errorf("expected 2 options, got %d", len(settings.options()))
+		}
+	})
+
+	t.Run("per-rpc credentials only adds insecure transport", func(t *testing.T) {
+		settings := dialSettings{perRPCCreds: &testPerRPCCredentials{}}
+		if len(settings.options()) != 2 {
+			t.Errorf("expected 2 options, got %d", len(settings.options()))
+		}
+	})
+
+	t.Run("context dialer adds insecure transport", func(t *testing.T) {
+		settings := dialSettings{contextDialer: dialTCP}
+		if len(settings.options()) != 2 {
+			t.Errorf("expected 2 options, got %d", len(settings.options()))
+		}
+	})
+
+	t.Run("context dialer keeps custom dial options", func(t *testing.T) {
+		settings := dialSettings{
+			dialOpts:      []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+			contextDialer: dialTCP,
+		}
+		if len(settings.options()) != 2 {
+			t.Errorf("expected 2 options, got %d", len(settings.options()))
+		}
+	})
+}
+
+func dialTCP(ctx context.Context, addr string) (net.Conn, error) {
+	return (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+}
+
+type testIngressServer struct {
+	pb.UnimplementedIngressServiceServer
+}
+
+func (s *testIngressServer) Publish(ctx context.Context, req *pb.PublishRequest) (*pb.PublishResponse, error) {
+	return &pb.PublishResponse{Sequence: 1}, nil
+}
+
+func TestPublisherBuilder_WithContextDialer(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterIngressServiceServer(server, &testIngressServer{})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	var (
+		mu     sync.Mutex
+		dialed []string
+	)
+	dialer := func(ctx context.Context, addr string) (net.Conn, error) {
+		mu.Lock()
+		dialed = append(dialed, addr)
+		mu.Unlock()
+		return dialTCP(ctx, lis.Addr().String())
+	}
+
+	pub, err := NewPublisherBuilder("passthrough:///stream.internal:9090").
+		WithContextDialer(dialer).
+		WithLogger(&testPubLogger{}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	err = pub.Publish(context.Background(), MessagePreparerFunc(func(ctx context.Context) (*PublishMessage, error) {
+		return &PublishMessage{Subject: "orders", Data: []byte("x")}, nil
+	}))
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dialed) == 0 || dialed[0] != "stream.internal:9090" {
+		t.Errorf("expected dialer to be called with stream.internal:9090, got %v", dialed)
+	}
+}
+
+func TestBuilders_WithOAuth2(t *testing.T) {
+	tokens := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "secret", TokenType: "Bearer"})
+
+	t.Run("attaches bearer token", func(t *testing.T) {
+		b := NewPublisherBuilder("localhost:9090").WithOAuth2(tokens)
+		if b.perRPCCreds == nil {
+			t.Fatal("expected per-RPC credentials to be set")
+		}
+
+		ctx := credentials.NewContextWithRequestInfo(context.Background(), credentials.RequestInfo{
+			AuthInfo: credentials.TLSInfo{CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity}},
+		})
+		md, err := b.perRPCCreds.GetRequestMetadata(ctx)
+		if err != nil {
+			t.Fatalf("GetRequestMetadata failed: %v", err)
+		}
+		if md["authorization"] != "Bearer secret" {
+			t.Errorf("expected bearer token, got %q", md["authorization"])
+		}
+	})
+
+	t.Run("requires transport security", func(t *testing.T) {
+		if err := NewPublisherBuilder("localhost:9090").WithOAuth2(tokens).Validate(); err == nil {
+			t.Error("expected error for tokens over an insecure connection")
+		}
+		if err := NewSubscriberBuilder("localhost:9090").WithOAuth2(tokens).Validate(); err == nil {
+			t.Error("expected error for tokens over an insecure connection")
+		}
+	})
+
+	t.Run("valid with tls", func(t *testing.T) {
+		err := NewSubscriberBuilder("localhost:9090").
+			WithTransportCredentials(credentials.NewTLS(&tls.Config{})).
+			WithOAuth2(tokens).
+			Validate()
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}
+
+func TestBuilders_WithInterceptors(t *testing.T) {
+	t.Run("interceptors add insecure transport", func(t *testing.T) {
+		b := NewSubscriberBuilder("localhost:9090").
+			WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				return invoker(ctx, method, req, reply, cc, opts...)
+			}).
+			WithStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+				return streamer(ctx, desc, cc, method, opts...)
+			})
+		if len(b.options()) != 3 {
+			t.Errorf("expected 3 options, got %d", len(b.options()))
+		}
+	})
+
+	t.Run("unary interceptors run in order", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to listen: %v", err)
+		}
+		server := grpc.NewServer()
+		pb.RegisterIngressServiceServer(server, &testIngressServer{})
+		go server.Serve(lis)
+		defer server.Stop()
+
+		var (
+			mu    sync.Mutex
+			calls []string
+		)
+		record := func(name string) grpc.UnaryClientInterceptor {
+			return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				mu.Lock()
+				calls = append(calls, name+" "+method)
+				mu.Unlock()
+				return invoker(ctx, method, req, reply, cc, opts...)
+			}
+		}
+
+		pub, err := NewPublisherBuilder(lis.Addr().String()).
+			WithUnaryInterceptor(record("auth")).
+			WithUnaryInterceptor(record("tracing")).
+			WithLogger(&testPubLogger{}).
+			Build()
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+
+		err = pub.Publish(context.Background(), MessagePreparerFunc(func(ctx context.Context) (*PublishMessage, error) {
+			return &PublishMessage{Subject: "orders", Data: []byte("x")}, nil
+		}))
+		if err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		want := []string{
+			"auth /minitoolstream.IngressService/Publish",
+			"tracing /minitoolstream.IngressService/Publish",
+		}
+		if len(calls) != len(want) || calls[0] != want[0] || calls[1] != want[1] {
+			t.Errorf("expected %v, got %v", want, calls)
+		}
+	})
+}
+
+func TestDialSettings_ServiceConfig(t *testing.T) {
+	t.Run("policy only", func(t *testing.T) {
+		settings := dialSettings{lbPolicy: "round_robin"}
+		config, err := settings.buildServiceConfig()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if config != `{"loadBalancingConfig":[{"round_robin":{}}]}` {
+			t.Errorf("unexpected service config %s", config)
+		}
+	})
+
+	t.Run("policy merged into service config", func(t *testing.T) {
+		settings := dialSettings{
+			lbPolicy:      "round_robin",
+			serviceConfig: `{"loadBalancingPolicy":"pick_first","methodConfig":[{"name":[{"service":"minitoolstream.EgressService"}],"timeout":"5s"}]}`,
+		}
+		config, err := settings.buildServiceConfig()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		var decoded map[string]interface{}
+		json.Unmarshal([]byte(config), &decoded)
+		if _, ok := decoded["loadBalancingPolicy"]; ok {
+			t.Error("expected legacy policy field to be replaced")
+		}
+		if _, ok := decoded["methodConfig"]; !ok {
+			t.Error("expected method config to be kept")
+		}
+		if _, ok := decoded["loadBalancingConfig"]; !ok {
+			t.Error("expected load balancing config")
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			settings dialSettings
+			valid    bool
+		}{
+			{"round robin", dialSettings{lbPolicy: "round_robin"}, true},
+			{"unknown policy", dialSettings{lbPolicy: "fastest"}, false},
+			{"invalid json", dialSettings{serviceConfig: "{"}, false},
+			{"invalid json with policy", dialSettings{lbPolicy: "round_robin", serviceConfig: "["}, false},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := tt.settings.validate()
+				if tt.valid && err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				if !tt.valid && err == nil {
+					t.Error("expected error")
+				}
+			})
+		}
+	})
+
+	t.Run("builders accept round robin", func(t *testing.T) {
+		pub, err := NewPublisherBuilder("dns:///localhost:9090").
+			WithLoadBalancingPolicy("round_robin").
+			WithLogger(&testPubLogger{}).
+			Build()
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		pub.Close()
+
+		b := NewSubscriberBuilder("localhost:9090").WithLoadBalancingPolicy("round_robin")
+		if len(b.options()) != 2 {
+			t.Errorf("expected 2 options, got %d", len(b.options()))
+		}
+	})
+}
+
+func TestBuilders_WithDeadlines(t *testing.T) {
+	deadlines := DefaultDeadlines()
+	deadlines.Publish = time.Second
+
+	b := NewPublisherBuilder("localhost:9090").WithDeadlines(deadlines)
+	if b.deadlines == nil || b.deadlines.Publish != time.Second {
+		t.Errorf("expected deadlines to be set, got %+v", b.deadlines)
+	}
+	if err := b.Validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	deadlines.Fetch = -time.Second
+	if err := NewSubscriberBuilder("localhost:9090").WithDeadlines(deadlines).Validate(); err == nil {
+		t.Error("expected error for negative deadline")
+	}
+}
@@ -0,0 +1,29 @@
+package minitoolstream_connector
+
+import (
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/delta"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+)
+
+// Differ re-exports domain.Differ
+type Differ = domain.Differ
+
+// DeltaRule re-exports publisher.DeltaRule
+type DeltaRule = publisher.DeltaRule
+
+// DeltaReconstructor re-exports delta.Reconstructor
+type DeltaReconstructor = delta.Reconstructor
+
+// Differs
+type (
+	JSONPatchDiffer = delta.JSONPatch
+	BinaryDiffer    = delta.Binary
+)
+
+// Delta reconstruction
+var (
+	NewDeltaReconstructor = delta.NewReconstructor
+	// ErrMissingDeltaBase reports a delta whose snapshot was not received
+	ErrMissingDeltaBase = delta.ErrMissingBase
+)
@@ -0,0 +1,233 @@
+package delta
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/compression"
+)
+
+// Binary diffs arbitrary payloads with the bsdiff algorithm, which copes
+// well with content that shifts around. A delta holds the target size
+// followed by bsdiff control records, each followed by its diff and extra
+// bytes. Unlike bsdiff files, whose blocks are compressed with bzip2, the
+// whole delta is compressed with zstd.
+type Binary struct{}
+
+// Format returns bsdiff
+func (Binary) Format() string {
+	return "bsdiff"
+}
+
+// Diff returns the delta turning base into target
+func (Binary) Diff(base, target []byte) ([]byte, error) {
+	old, cur := base, target
+	index := suffixArray(old)
+	out := binary.AppendUvarint(nil, uint64(len(cur)))
+
+	var scan, pos, length, lastScan, lastPos, lastOffset int
+	for scan < len(cur) {
+		// Find the next match that is not just a continuation of the
+		// previous alignment
+		oldScore := 0
+		scan += length
+		for scsc := scan; scan < len(cur); scan++ {
+			pos, length = bsdiffSearch(index, old, cur[scan:])
+			for ; scsc < scan+length; scsc++ {
+				if scsc+lastOffset < len(old) && old[scsc+lastOffset] == cur[scsc] {
+					oldScore++
+				}
+			}
+			if (length == oldScore && length != 0) || length > oldScore+8 {
+				break
+			}
+			if scan+lastOffset < len(old) && old[scan+lastOffset] == cur[scan] {
+				oldScore--
+			}
+		}
+		if length == oldScore && scan != len(cur) {
+			continue
+		}
+
+		// Extend the previous match forwards and this one backwards
+		lenF := 0
+		for s, sf, i := 0, 0, 0; lastScan+i < scan && lastPos+i < len(old); {
+			if old[lastPos+i] == cur[lastScan+i] {
+				s++
+			}
+			i++
+			if s*2-i > sf*2-lenF {
+				sf, lenF = s, i
+			}
+		}
+		lenB := 0
+		if scan < len(cur) {
+			for s, sb, i := 0, 0, 1; scan >= lastScan+i && pos >= i; i++ {
+				if old[pos-i] == cur[scan-i] {
+					s++
+				}
+				if s*2-i > sb*2-lenB {
+					sb, lenB = s, i
+				}
+			}
+		}
+		if lastScan+lenF > scan-lenB {
+			overlap := lastScan + lenF - (scan - lenB)
+			s, ss, lenS := 0, 0, 0
+			for i := 0; i < overlap; i++ {
+				if cur[lastScan+lenF-overlap+i] == old[lastPos+lenF-overlap+i] {
+					s++
+				}
+				if cur[scan-lenB+i] == old[pos-lenB+i] {
+					s--
+				}
+				if s > ss {
+					ss, lenS = s, i+1
+				}
+			}
+			lenF += lenS - overlap
+			lenB -= lenS
+		}
+
+		extra := scan - lenB - (lastScan + lenF)
+		out = binary.AppendUvarint(out, uint64(lenF))
+		out = binary.AppendUvarint(out, uint64(extra))
+		out = binary.AppendVarint(out, int64(pos-lenB-(lastPos+lenF)))
+		for i := 0; i < lenF; i++ {
+			out = append(out, cur[lastScan+i]-old[lastPos+i])
+		}
+		out = append(out, cur[lastScan+lenF:scan-lenB]...)
+
+		lastScan, lastPos, lastOffset = scan-lenB, pos-lenB, pos-scan
+	}
+	return compression.Zstd{}.Compress(out)
+}
+
+// Patch applies a delta produced by Diff to base
+func (Binary) Patch(base, delta []byte) ([]byte, error) {
+	delta, err := compression.Zstd{}.Decompress(delta)
+	if err != nil {
+		return nil, fmt.Errorf("bsdiff: %w", err)
+	}
+	size, n := binary.Uvarint(delta)
+	if n <= 0 {
+		return nil, fmt.Errorf("bsdiff: invalid header")
+	}
+	if size > maxPayloadSize {
+		return nil, fmt.Errorf("bsdiff: patched size %d exceeds the limit of %d bytes", size, maxPayloadSize)
+	}
+	delta = delta[n:]
+
+	out := make([]byte, 0, size)
+	oldPos := 0
+	for len(delta) > 0 {
+		diffLen, n1 := binary.Uvarint(delta)
+		extraLen, n2 := binary.Uvarint(delta[max(n1, 0):])
+		seek, n3 := binary.Varint(delta[max(n1, 0)+max(n2, 0):])
+		if n1 <= 0 || n2 <= 0 || n3 <= 0 {
+			return nil, fmt.Errorf("bsdiff: invalid control record")
+		}
+		delta = delta[n1+n2+n3:]
+
+		remaining := size - uint64(len(out))
+		if diffLen > remaining || extraLen > remaining-diffLen || diffLen+extraLen > uint64(len(delta)) {
+			return nil, fmt.Errorf("bsdiff: control record exceeds the delta")
+		}
+		if diffLen > uint64(len(base)-oldPos) {
+			return nil, fmt.Errorf("bsdiff: control record exceeds the base")
+		}
+
+		for i, b := range delta[:diffLen] {
+			out = append(out, b+base[oldPos+i])
+		}
+		out = append(out, delta[diffLen:diffLen+extraLen]...)
+		delta = delta[diffLen+extraLen:]
+
+		next := int64(oldPos) + int64(diffLen) + seek
+		if seek < -int64(len(base)) || seek > int64(len(base)) || next < 0 || next > int64(len(base)) {
+			return nil, fmt.Errorf("bsdiff: control record seeks outside the base")
+		}
+		oldPos = int(next)
+	}
+	if uint64(len(out)) != size {
+		return nil, fmt.Errorf("bsdiff: patched %d bytes, expected %d", len(out), size)
+	}
+	return out, nil
+}
+
+// bsdiffSearch returns the position and length of the longest prefix of cur
+// found in old, given the suffix array of old
+func bsdiffSearch(index []int, old, cur []byte) (int, int) {
+	st, en := 0, len(index)-1
+	for en-st >= 2 {
+		x := st + (en-st)/2
+		n := min(len(old)-index[x], len(cur))
+		if bytes.Compare(old[index[x]:index[x]+n], cur[:n]) < 0 {
+			st = x
+		} else {
+			en = x
+		}
+	}
+	x, y := matchLen(old[index[st]:], cur), matchLen(old[index[en]:], cur)
+	if x > y {
+		return index[st], x
+	}
+	return index[en], y
+}
+
+// matchLen returns the length of the common prefix of a and b
+func matchLen(a, b []byte) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// suffixArray returns the suffixes of s, including the empty one, in sorted
+// order, by prefix doubling
+func suffixArray(s []byte) []int {
+	n := len(s)
+	sa := make([]int, n+1)
+	rank := make([]int, n+1)
+	next := make([]int, n+1)
+	for i := range sa {
+		sa[i] = i
+		if i < n {
+			rank[i] = int(s[i]) + 1
+		}
+	}
+
+	for k := 1; ; k <<= 1 {
+		second := func(i int) int {
+			if i+k <= n {
+				return rank[i+k]
+			}
+			return -1
+		}
+		compare := func(a, b int) int {
+			if rank[a] != rank[b] {
+				return cmp.Compare(rank[a], rank[b])
+			}
+			return cmp.Compare(second(a), second(b))
+		}
+		slices.SortFunc(sa, compare)
+
+		next[sa[0]] = 0
+		for i := 1; i <= n; i++ {
+			next[sa[i]] = next[sa[i-1]]
+			if compare(sa[i-1], sa[i]) < 0 {
+				next[sa[i]]++
+			}
+		}
+		copy(rank, next)
+		if rank[sa[n]] == n {
+			return sa
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package delta sends large, frequently updated documents as deltas against
+// their last full snapshot. Publishers name the delta format and the sequence
+// of the snapshot in the delta-format and delta-base headers, and subscribers
+// keep the latest snapshot of each subject to patch deltas back into full
+// payloads.
+package delta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strconv"
+	"sync"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// maxPayloadSize caps patched payloads to guard against corrupt deltas
+const maxPayloadSize = 256 << 20
+
+// ErrMissingBase reports a delta whose snapshot the subscriber has not
+// received, e.g. because it started after the snapshot was published
+var ErrMissingBase = errors.New("delta base not received")
+
+// snapshot is the latest full payload of a subject
+type snapshot struct {
+	sequence uint64
+	data     []byte
+}
+
+// Reconstructor patches delta payloads back into full payloads. It
+// remembers the latest full payload of every subject it sees and is safe
+// for concurrent use.
+type Reconstructor struct {
+	differs   map[string]domain.Differ
+	mu        sync.Mutex
+	snapshots map[string]snapshot
+}
+
+// NewReconstructor creates a reconstructor for the formats of differs, or
+// for json-patch and bsdiff when none are given
+func NewReconstructor(differs ...domain.Differ) *Reconstructor {
+	if len(differs) == 0 {
+		differs = []domain.Differ{JSONPatch{}, Binary{}}
+	}
+	r := &Reconstructor{
+		differs:   make(map[string]domain.Differ, len(differs)),
+		snapshots: make(map[string]snapshot),
+	}
+	for _, d := range differs {
+		r.differs[d.Format()] = d
+	}
+	return r
+}
+
+// Reconstruct returns a copy of msg with its delta payload patched into the
+// full payload and the delta headers removed. Full payloads are returned as
+// is and kept as the snapshot of their subject. Errors match
+// domain.ErrDecode, and also ErrMissingBase when the snapshot is missing.
+func (r *Reconstructor) Reconstruct(msg *domain.ReceivedMessage) (*domain.ReceivedMessage, error) {
+	format := msg.Headers[domain.HeaderDeltaFormat]
+	if format == "" {
+		r.remember(msg)
+		return msg, nil
+	}
+
+	differ, ok := r.differs[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: sequence %d: unsupported delta format %q", domain.ErrDecode, msg.Sequence, format)
+	}
+	base, err := strconv.ParseUint(msg.Headers[domain.HeaderDeltaBase], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sequence %d: invalid delta base %q", domain.ErrDecode, msg.Sequence, msg.Headers[domain.HeaderDeltaBase])
+	}
+
+	r.mu.Lock()
+	snap, ok := r.snapshots[msg.Subject]
+	r.mu.Unlock()
+	if !ok || snap.sequence != base {
+		return nil, fmt.Errorf("%w: sequence %d: %w: %d", domain.ErrDecode, msg.Sequence, ErrMissingBase, base)
+	}
+
+	data, err := differ.Patch(snap.data, msg.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sequence %d: %s: %v", domain.ErrDecode, msg.Sequence, format, err)
+	}
+
+	full := *msg
+	full.Data = data
+	full.Headers = maps.Clone(msg.Headers)
+	delete(full.Headers, domain.HeaderDeltaFormat)
+	delete(full.Headers, domain.HeaderDeltaBase)
+	return &full, nil
+}
+
+// remember keeps a full payload as the snapshot of its subject unless a
+// later one is already kept
+func (r *Reconstructor) remember(msg *domain.ReceivedMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if snap, ok := r.snapshots[msg.Subject]; ok && snap.sequence > msg.Sequence {
+		return
+	}
+	r.snapshots[msg.Subject] = snapshot{sequence: msg.Sequence, data: msg.Data}
+}
+
+// Handler wraps next so it receives full payloads
+func (r *Reconstructor) Handler(next domain.MessageHandler) domain.MessageHandler {
+	return domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		full, err := r.Reconstruct(msg)
+		if err != nil {
+			return err
+		}
+		return next.Handle(ctx, full)
+	})
+}
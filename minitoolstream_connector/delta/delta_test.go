@@ -0,0 +1,199 @@
+package delta
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/compression"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestJSONPatch_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name, base, target string
+		ops                int
+	}{
+		{"equal", `{"a":1}`, `{"a":1.0}`, 0},
+		{"members", `{"a":1,"b":{"c":[1,2]},"gone":true}`, `{"a":2,"b":{"c":[1,2],"d":null},"new":"x"}`, 4},
+		{"insert", `[1,2,3,4]`, `[1,2,9,3,4]`, 1},
+		{"remove", `{"list":[{"id":1},{"id":2},{"id":3}]}`, `{"list":[{"id":1},{"id":3}]}`, 1},
+		{"escaped keys", `{"a/b":1,"c~d":2}`, `{"a/b":3,"c~d":4}`, 2},
+		{"root type", `[1]`, `{"a":"<b>"}`, 1},
+	}
+	for _, tt := range tests {
+		d, err := JSONPatch{}.Diff([]byte(tt.base), []byte(tt.target))
+		if err != nil {
+			t.Fatalf("%s: Diff failed: %v", tt.name, err)
+		}
+		var ops []patchOp
+		if err := json.Unmarshal(d, &ops); err != nil || len(ops) != tt.ops {
+			t.Errorf("%s: got %d operations %s, want %d", tt.name, len(ops), d, tt.ops)
+		}
+
+		got, err := JSONPatch{}.Patch([]byte(tt.base), d)
+		if err != nil {
+			t.Fatalf("%s: Patch failed: %v", tt.name, err)
+		}
+		want, _ := decodeJSON([]byte(tt.target))
+		if doc, _ := decodeJSON(got); !jsonEqual(doc, want) {
+			t.Errorf("%s: patched %s, want %s", tt.name, got, tt.target)
+		}
+	}
+}
+
+func TestJSONPatch_Operations(t *testing.T) {
+	// Examples from RFC 6902, appendix A
+	base := `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"},"arr":["a","b","c"]}`
+	patch := `[
+		{"op":"test","path":"/foo/bar","value":"baz"},
+		{"op":"move","from":"/foo/waldo","path":"/qux/thud"},
+		{"op":"copy","from":"/arr/0","path":"/arr/-"},
+		{"op":"add","path":"/arr/1","value":"x"},
+		{"op":"remove","path":"/arr/2"},
+		{"op":"replace","path":"/foo","value":{"~":1}},
+		{"op":"test","path":"/foo/~0","value":1.0}
+	]`
+	got, err := JSONPatch{}.Patch([]byte(base), []byte(patch))
+	if err != nil {
+		t.Fatalf("Patch failed: %v", err)
+	}
+	if want := `{"arr":["a","x","c","a"],"foo":{"~":1},"qux":{"corge":"grault","thud":"fred"}}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	for _, bad := range []string{
+		`[{"op":"test","path":"/foo/bar","value":"qux"}]`,
+		`[{"op":"remove","path":"/missing"}]`,
+		`[{"op":"add","path":"/arr/5","value":1}]`,
+		`[{"op":"replace","path":"/arr/01","value":1}]`,
+		`[{"op":"add","path":"/foo/bar/x","value":1}]`,
+		`[{"op":"move","from":"/foo","path":"/foo/x"}]`,
+		`[{"op":"add","path":"/x"}]`,
+		`[{"op":"patch","path":"/x"}]`,
+		`[{"op":"add","path":"x","value":1}]`,
+		`{}`,
+	} {
+		if _, err := (JSONPatch{}).Patch([]byte(base), []byte(bad)); err == nil {
+			t.Errorf("Patch(%s) expected error", bad)
+		}
+	}
+}
+
+func TestBinary_RoundTrip(t *testing.T) {
+	random := make([]byte, 50<<10)
+	rand.New(rand.NewSource(1)).Read(random)
+	edited := append([]byte("header "), random[:20000]...)
+	edited = append(edited, random[20100:]...)
+	edited[30000] ^= 0xff
+
+	doc := []byte(strings.Repeat(`{"id":1,"status":"pending"},`, 1000))
+	updated := bytes.Replace(doc, []byte(`"pending"`), []byte(`"shipped"`), 3)
+
+	tests := []struct {
+		name         string
+		base, target []byte
+	}{
+		{"empty", nil, nil},
+		{"from empty", nil, []byte("hello")},
+		{"to empty", []byte("hello"), nil},
+		{"edited", random, edited},
+		{"document", doc, updated},
+	}
+	for _, tt := range tests {
+		d, err := Binary{}.Diff(tt.base, tt.target)
+		if err != nil {
+			t.Fatalf("%s: Diff failed: %v", tt.name, err)
+		}
+		got, err := Binary{}.Patch(tt.base, d)
+		if err != nil {
+			t.Fatalf("%s: Patch failed: %v", tt.name, err)
+		}
+		if !bytes.Equal(got, tt.target) {
+			t.Errorf("%s: patch does not restore the target", tt.name)
+		}
+		if len(tt.target) > 1000 && len(d) > len(tt.target)/5 {
+			t.Errorf("%s: delta of %d bytes for a %d byte target", tt.name, len(d), len(tt.target))
+		}
+	}
+
+	d, _ := Binary{}.Diff(doc, updated)
+	raw, _ := compression.Zstd{}.Decompress(d)
+	for _, bad := range [][]byte{nil, d[:len(d)-1], zstd(raw[:len(raw)-1]), zstd(append(raw, 0))} {
+		if _, err := (Binary{}).Patch(doc, bad); err == nil {
+			t.Errorf("Patch(%x) expected error", bad)
+		}
+	}
+	if _, err := (Binary{}).Patch(doc[:100], d); err == nil {
+		t.Error("expected error for a delta against another base")
+	}
+}
+
+// zstd compresses a raw bsdiff delta
+func zstd(raw []byte) []byte {
+	d, _ := compression.Zstd{}.Compress(raw)
+	return d
+}
+
+func TestSuffixArray(t *testing.T) {
+	s := []byte("banana")
+	want := []int{6, 5, 3, 1, 0, 4, 2}
+	got := suffixArray(s)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("suffixArray(banana) = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestReconstructor(t *testing.T) {
+	r := NewReconstructor()
+	var got []string
+	handler := r.Handler(domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		got = append(got, string(msg.Data))
+		if msg.Headers[domain.HeaderDeltaFormat] != "" {
+			t.Error("delta headers were not removed")
+		}
+		return nil
+	}))
+	deltaMessage := func(sequence uint64, base, format string, data []byte) *domain.ReceivedMessage {
+		return &domain.ReceivedMessage{
+			Subject:  "docs",
+			Sequence: sequence,
+			Data:     data,
+			Headers:  map[string]string{domain.HeaderDeltaFormat: format, domain.HeaderDeltaBase: base},
+		}
+	}
+
+	v2, _ := JSONPatch{}.Diff([]byte(`{"v":1}`), []byte(`{"v":2}`))
+	if err := handler.Handle(context.Background(), deltaMessage(2, "1", "json-patch", v2)); !errors.Is(err, ErrMissingBase) || !errors.Is(err, domain.ErrDecode) {
+		t.Errorf("expected ErrMissingBase before the snapshot, got %v", err)
+	}
+
+	if err := handler.Handle(context.Background(), &domain.ReceivedMessage{Subject: "docs", Sequence: 1, Data: []byte(`{"v":1}`)}); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	v3, _ := Binary{}.Diff([]byte(`{"v":1}`), []byte(`{"v":3}`))
+	for _, msg := range []*domain.ReceivedMessage{deltaMessage(2, "1", "json-patch", v2), deltaMessage(3, "1", "bsdiff", v3)} {
+		if err := handler.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+	}
+	if strings.Join(got, " ") != `{"v":1} {"v":2} {"v":3}` {
+		t.Errorf("got %v", got)
+	}
+
+	for _, msg := range []*domain.ReceivedMessage{
+		deltaMessage(4, "1", "xdelta", v2),
+		deltaMessage(4, "one", "json-patch", v2),
+		deltaMessage(4, "1", "json-patch", []byte("not a patch")),
+	} {
+		if _, err := r.Reconstruct(msg); !errors.Is(err, domain.ErrDecode) || errors.Is(err, ErrMissingBase) {
+			t.Errorf("expected ErrDecode, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,447 @@
+package delta
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPatch diffs JSON documents as RFC 6902 JSON Patch documents. Patch
+// applies any JSON Patch, not only those produced by Diff. Patched documents
+// are equal to the target as JSON values, but object keys come out sorted
+// and insignificant whitespace is dropped.
+type JSONPatch struct{}
+
+// patchOp is one operation of a JSON Patch document
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Format returns json-patch
+func (JSONPatch) Format() string {
+	return "json-patch"
+}
+
+// Diff returns the operations turning base into target
+func (JSONPatch) Diff(base, target []byte) ([]byte, error) {
+	a, err := decodeJSON(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base document: %w", err)
+	}
+	b, err := decodeJSON(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target document: %w", err)
+	}
+	return encodeJSON(diffValues([]patchOp{}, "", a, b))
+}
+
+// Patch applies the operations of delta to base
+func (JSONPatch) Patch(base, delta []byte) ([]byte, error) {
+	doc, err := decodeJSON(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base document: %w", err)
+	}
+	var ops []patchOp
+	if err := json.Unmarshal(delta, &ops); err != nil {
+		return nil, fmt.Errorf("invalid JSON patch: %w", err)
+	}
+
+	for i, op := range ops {
+		if doc, err = applyOp(doc, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return encodeJSON(doc)
+}
+
+// diffValues appends the operations turning a into b at path
+func diffValues(ops []patchOp, path string, a, b interface{}) []patchOp {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			return diffObjects(ops, path, av, bv)
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			return diffArrays(ops, path, av, bv)
+		}
+	}
+	if jsonEqual(a, b) {
+		return ops
+	}
+	return append(ops, newOp("replace", path, b))
+}
+
+// diffObjects appends the operations turning object a into b, in key order
+func diffObjects(ops []patchOp, path string, a, b map[string]interface{}) []patchOp {
+	for _, key := range sortedKeys(a) {
+		if _, ok := b[key]; !ok {
+			ops = append(ops, newOp("remove", path+"/"+escapeToken(key), nil))
+		}
+	}
+	for _, key := range sortedKeys(b) {
+		child := path + "/" + escapeToken(key)
+		if av, ok := a[key]; ok {
+			ops = diffValues(ops, child, av, b[key])
+		} else {
+			ops = append(ops, newOp("add", child, b[key]))
+		}
+	}
+	return ops
+}
+
+// diffArrays appends the operations turning array a into b. Elements the
+// arrays share at either end are kept, so inserting or removing elements in
+// one place yields only adds or removes; the elements in between are diffed
+// by position.
+func diffArrays(ops []patchOp, path string, a, b []interface{}) []patchOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && jsonEqual(a[prefix], b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && jsonEqual(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	common := min(len(a), len(b))
+	for i := 0; i < common; i++ {
+		ops = diffValues(ops, path+"/"+strconv.Itoa(prefix+i), a[i], b[i])
+	}
+	for i := common; i < len(b); i++ {
+		ops = append(ops, newOp("add", path+"/"+strconv.Itoa(prefix+i), b[i]))
+	}
+	for i := len(a) - 1; i >= common; i-- {
+		ops = append(ops, newOp("remove", path+"/"+strconv.Itoa(prefix+i), nil))
+	}
+	return ops
+}
+
+// newOp creates an operation; remove operations have no value
+func newOp(op, path string, value interface{}) patchOp {
+	o := patchOp{Op: op, Path: path}
+	if op != "remove" {
+		// Values decoded from JSON always encode
+		o.Value, _ = encodeJSON(value)
+	}
+	return o
+}
+
+// applyOp applies one operation to doc and returns the new document
+func applyOp(doc interface{}, op patchOp) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+		value, err := decodeJSON(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+		switch op.Op {
+		case "add":
+			return addValue(doc, path, value)
+		case "replace":
+			if _, err := getValue(doc, path); err != nil {
+				return nil, err
+			}
+			return setValue(doc, path, value)
+		default:
+			current, err := getValue(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !jsonEqual(current, value) {
+				return nil, fmt.Errorf("test failed")
+			}
+			return doc, nil
+		}
+	case "remove":
+		return removeValue(doc, path)
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := getValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if op.Op == "copy" {
+			return addValue(doc, path, copyValue(value))
+		}
+		if op.From == op.Path {
+			return doc, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move a value into itself")
+		}
+		if doc, err = removeValue(doc, from); err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, value)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into its unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// escapeToken escapes an object key for use in a JSON pointer
+func escapeToken(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// getValue returns the value at path
+func getValue(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := doc.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			doc = value
+		case []interface{}:
+			i, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = container[i]
+		default:
+			return nil, fmt.Errorf("cannot index a scalar with %q", token)
+		}
+	}
+	return doc, nil
+}
+
+// modify replaces the container holding the last token of path with the
+// result of fn and returns the new document
+func modify(doc interface{}, path []string, fn func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+	child, err := getValue(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = modify(child, path[1:], fn); err != nil {
+		return nil, err
+	}
+	switch container := doc.(type) {
+	case map[string]interface{}:
+		container[path[0]] = child
+	case []interface{}:
+		// getValue validated the index
+		i, _ := strconv.Atoi(path[0])
+		container[i] = child
+	}
+	return doc, nil
+}
+
+// addValue adds value at path, inserting into arrays
+func addValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			if token == "-" {
+				return append(c, value), nil
+			}
+			i, err := arrayIndex(token, len(c))
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		default:
+			return nil, fmt.Errorf("cannot add %q to a scalar", token)
+		}
+	})
+}
+
+// setValue replaces the existing value at path
+func setValue(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+		case []interface{}:
+			// getValue validated the index
+			i, _ := strconv.Atoi(token)
+			c[i] = value
+		}
+		return container, nil
+	})
+}
+
+// removeValue removes the value at path
+func removeValue(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	return modify(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if _, ok := c[token]; !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			delete(c, token)
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:i], c[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("cannot remove %q from a scalar", token)
+		}
+	})
+}
+
+// arrayIndex parses an array index token, which must not exceed max
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || token[0] < '0' || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// copyValue returns a deep copy of a decoded JSON value
+func copyValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, child := range value {
+			m[k] = copyValue(child)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(value))
+		for i, child := range value {
+			a[i] = copyValue(child)
+		}
+		return a
+	default:
+		return v
+	}
+}
+
+// jsonEqual reports whether two decoded JSON values are equal, comparing
+// numbers by value
+func jsonEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, child := range av {
+			other, ok := bv[k]
+			if !ok || !jsonEqual(child, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		if av == bv {
+			return true
+		}
+		x, okX := new(big.Float).SetString(string(av))
+		y, okY := new(big.Float).SetString(string(bv))
+		return okX && okY && x.Cmp(y) == 0
+	default:
+		return a == b
+	}
+}
+
+// sortedKeys returns the keys of an object in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// decodeJSON decodes one JSON value, keeping numbers as written
+func decodeJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return v, nil
+}
+
+// encodeJSON encodes v compactly, without escaping HTML characters
+func encodeJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,102 @@
+// Package dialopts provides ready-made gRPC dial options for connecting to
+// MiniToolStream Ingress and Egress services.
+package dialopts
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// retryServiceConfig retries calls that failed with UNAVAILABLE on both services
+const retryServiceConfig = `{
+	"methodConfig": [{
+		"name": [
+			{"service": "minitoolstream.IngressService"},
+			{"service": "minitoolstream.EgressService"}
+		],
+		"retryPolicy": {
+			"maxAttempts": 4,
+			"initialBackoff": "0.2s",
+			"maxBackoff": "5s",
+			"backoffMultiplier": 2.0,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
+
+// Insecure returns a dial option for plaintext connections without TLS
+func Insecure() grpc.DialOption {
+	return grpc.WithTransportCredentials(insecure.NewCredentials())
+}
+
+// TLS returns a dial option that secures the connection with the given TLS config.
+// A nil config uses the system root CAs.
+func TLS(cfg *tls.Config) grpc.DialOption {
+	if cfg == nil {
+		cfg = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(cfg))
+}
+
+// MTLSFromFiles returns a dial option for mutual TLS using a client certificate
+// and key, verifying the server against the CA bundle in caFile.
+// An empty caFile uses the system root CAs.
+func MTLSFromFiles(certFile, keyFile, caFile string) (grpc.DialOption, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if caFile != "" {
+		pool, err := loadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+
+	return TLS(cfg), nil
+}
+
+// WithDefaultKeepalive returns a dial option with keepalive pings suitable for
+// long-lived subscription streams behind load balancers and NATs
+func WithDefaultKeepalive() grpc.DialOption {
+	return grpc.WithKeepaliveParams(keepalive.ClientParameters{
+		Time:                30 * time.Second,
+		Timeout:             10 * time.Second,
+		PermitWithoutStream: true,
+	})
+}
+
+// WithRetryDefaults returns a dial option enabling transparent retries of
+// calls that fail with UNAVAILABLE, with exponential backoff
+func WithRetryDefaults() grpc.DialOption {
+	return grpc.WithDefaultServiceConfig(retryServiceConfig)
+}
+
+// loadCertPool reads a PEM encoded CA bundle
+func loadCertPool(caFile string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file %s: %w", caFile, err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+	}
+	return pool, nil
+}
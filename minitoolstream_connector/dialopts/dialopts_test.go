@@ -0,0 +1,104 @@
+package dialopts
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// writeTestCertificate writes a self-signed certificate and key to dir
+func writeTestCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile
+}
+
+func TestOptionsCreateClient(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []grpc.DialOption
+	}{
+		{"insecure", []grpc.DialOption{Insecure()}},
+		{"tls with nil config", []grpc.DialOption{TLS(nil)}},
+		{"keepalive", []grpc.DialOption{Insecure(), WithDefaultKeepalive()}},
+		{"retry defaults", []grpc.DialOption{Insecure(), WithRetryDefaults()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := grpc.NewClient("localhost:9090", tt.opts...)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			conn.Close()
+		})
+	}
+}
+
+func TestMTLSFromFiles(t *testing.T) {
+	t.Run("valid files", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCertificate(t, dir)
+
+		opt, err := MTLSFromFiles(certFile, keyFile, certFile)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if opt == nil {
+			t.Error("expected dial option")
+		}
+	})
+
+	t.Run("missing certificate", func(t *testing.T) {
+		_, err := MTLSFromFiles("missing.pem", "missing.key", "")
+		if err == nil {
+			t.Fatal("expected error for missing files")
+		}
+	})
+
+	t.Run("invalid CA file", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCertificate(t, dir)
+		caFile := filepath.Join(dir, "ca.pem")
+		os.WriteFile(caFile, []byte("not a certificate"), 0600)
+
+		_, err := MTLSFromFiles(certFile, keyFile, caFile)
+		if err == nil {
+			t.Fatal("expected error for invalid CA file")
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package dialopts
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// ReloadConfig holds configuration for ReloadingCredentials
+type ReloadConfig struct {
+	CertFile   string
+	KeyFile    string
+	CAFile     string        // Optional; empty uses the system root CAs
+	ServerName string        // Optional; overrides the name verified against the server certificate
+	Interval   time.Duration // How often the files are checked for changes (default: 30s)
+	Logger     Logger
+}
+
+// ReloadingCredentials are mutual TLS transport credentials that pick up
+// rotated certificate, key and CA files without restarting the connector.
+//
+// Established connections keep the certificate they were opened with; only
+// new handshakes (reconnects, new subchannels) use the reloaded files, so a
+// rotation never forces connections to be torn down.
+type ReloadingCredentials struct {
+	*certWatcher
+	serverName string // Set by OverrideServerName
+}
+
+// certWatcher holds the current credentials shared by all clones
+type certWatcher struct {
+	config   ReloadConfig
+	interval time.Duration
+	logger   Logger
+
+	mu      sync.RWMutex
+	current credentials.TransportCredentials
+	stamps  map[string]fileStamp
+}
+
+// fileStamp identifies a version of a watched file
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewReloadingCredentials loads the certificate files and returns credentials
+// that reload them while Run is active
+func NewReloadingCredentials(config *ReloadConfig) (*ReloadingCredentials, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, fmt.Errorf("certificate and key files are required")
+	}
+
+	interval := config.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	w := &certWatcher{
+		config:   *config,
+		interval: interval,
+		logger:   logger,
+	}
+	if err := w.reload(); err != nil {
+		return nil, err
+	}
+
+	return &ReloadingCredentials{certWatcher: w}, nil
+}
+
+// MTLSReloading returns a dial option for mutual TLS with ReloadingCredentials.
+// Run must be started on the returned credentials for rotations to be picked up.
+func MTLSReloading(config *ReloadConfig) (grpc.DialOption, *ReloadingCredentials, error) {
+	creds, err := NewReloadingCredentials(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return grpc.WithTransportCredentials(creds), creds, nil
+}
+
+// Run checks the files for changes until ctx is cancelled.
+// A failed reload, e.g. while a rotation is half-written, keeps the previous
+// certificate and is retried on the next check.
+func (c *ReloadingCredentials) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		if !c.changed() {
+			continue
+		}
+		if err := c.reload(); err != nil {
+			c.logger.Printf("✗ Failed to reload TLS certificate: %v", err)
+			continue
+		}
+		c.logger.Printf("✓ Reloaded TLS certificate from %s", c.config.CertFile)
+	}
+}
+
+// Reload loads the files immediately, e.g. on SIGHUP
+func (c *ReloadingCredentials) Reload() error {
+	return c.reload()
+}
+
+// reload builds new credentials from the files and swaps them in
+func (w *certWatcher) reload() error {
+	stamps, err := w.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(w.config.CertFile, w.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ServerName:   w.config.ServerName,
+		MinVersion:   tls.VersionTLS12,
+	}
+	if w.config.CAFile != "" {
+		pool, err := loadCertPool(w.config.CAFile)
+		if err != nil {
+			return err
+		}
+		cfg.RootCAs = pool
+	}
+
+	w.mu.Lock()
+	w.current = credentials.NewTLS(cfg)
+	w.stamps = stamps
+	w.mu.Unlock()
+	return nil
+}
+
+// changed reports whether any watched file differs from the loaded version
+func (w *certWatcher) changed() bool {
+	stamps, err := w.stat()
+	if err != nil {
+		// Files may briefly disappear while being replaced
+		return false
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for name, stamp := range stamps {
+		if w.stamps[name] != stamp {
+			return true
+		}
+	}
+	return false
+}
+
+// stat records the current version of the watched files
+func (w *certWatcher) stat() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp, 3)
+	for _, name := range []string{w.config.CertFile, w.config.KeyFile, w.config.CAFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", name, err)
+		}
+		stamps[name] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
+
+// load returns the current credentials
+func (w *certWatcher) load() credentials.TransportCredentials {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// ClientHandshake performs the TLS handshake with the current certificate
+func (c *ReloadingCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	creds := c.load()
+	if c.serverName != "" {
+		creds = creds.Clone()
+		creds.OverrideServerName(c.serverName)
+	}
+	return creds.ClientHandshake(ctx, authority, conn)
+}
+
+// ServerHandshake is not supported; the credentials are client-side only
+func (c *ReloadingCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, fmt.Errorf("reloading credentials are client-side only")
+}
+
+// Info returns the protocol info of the current credentials
+func (c *ReloadingCredentials) Info() credentials.ProtocolInfo {
+	info := c.load().Info()
+	if c.serverName != "" {
+		info.ServerName = c.serverName
+	}
+	return info
+}
+
+// Clone returns credentials sharing the same watched files
+func (c *ReloadingCredentials) Clone() credentials.TransportCredentials {
+	return &ReloadingCredentials{certWatcher: c.certWatcher, serverName: c.serverName}
+}
+
+// OverrideServerName overrides the server name used to verify the server certificate
+func (c *ReloadingCredentials) OverrideServerName(serverName string) error {
+	c.serverName = serverName
+	return nil
+}
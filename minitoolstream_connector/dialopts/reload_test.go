@@ -0,0 +1,250 @@
+package dialopts
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type discardLogger struct{}
+
+func (l *discardLogger) Printf(format string, v ...interface{}) {}
+
+// writeNamedCertificate writes a self-signed certificate for localhost with the given common name
+func writeNamedCertificate(t *testing.T, certFile, keyFile, commonName string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+}
+
+// startTLSServer accepts TLS connections requiring a client certificate and
+// reports the common name of each client
+func startTLSServer(t *testing.T, certFile, keyFile string) (string, <-chan string) {
+	t.Helper()
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("failed to load server certificate: %v", err)
+	}
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAnyClientCert,
+		NextProtos:   []string{"h2"},
+	})
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { lis.Close() })
+
+	clients := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			tlsConn := conn.(*tls.Conn)
+			if err := tlsConn.Handshake(); err == nil {
+				clients <- tlsConn.ConnectionState().PeerCertificates[0].Subject.CommonName
+			}
+			conn.Close()
+		}
+	}()
+	return lis.Addr().String(), clients
+}
+
+// handshake connects to addr with creds and returns the client name seen by the server
+func handshake(t *testing.T, creds *ReloadingCredentials, addr string, clients <-chan string) string {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	if _, _, err := creds.ClientHandshake(context.Background(), "localhost", conn); err != nil {
+		t.Fatalf("handshake failed: %v", err)
+	}
+	select {
+	case name := <-clients:
+		return name
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not complete the handshake")
+		return ""
+	}
+}
+
+func TestNewReloadingCredentials(t *testing.T) {
+	t.Run("nil config", func(t *testing.T) {
+		if _, err := NewReloadingCredentials(nil); err == nil {
+			t.Error("expected error for nil config")
+		}
+	})
+
+	t.Run("missing files", func(t *testing.T) {
+		_, err := NewReloadingCredentials(&ReloadConfig{CertFile: "missing.pem", KeyFile: "missing.key"})
+		if err == nil {
+			t.Error("expected error for missing files")
+		}
+	})
+
+	t.Run("valid files", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := writeTestCertificate(t, dir)
+
+		opt, creds, err := MTLSReloading(&ReloadConfig{CertFile: certFile, KeyFile: keyFile, CAFile: certFile})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if opt == nil || creds == nil {
+			t.Fatal("expected dial option and credentials")
+		}
+		if creds.Info().SecurityProtocol != "tls" {
+			t.Errorf("expected tls security protocol, got %q", creds.Info().SecurityProtocol)
+		}
+	})
+}
+
+func TestReloadingCredentials_Reload(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, serverKey := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	writeNamedCertificate(t, serverCert, serverKey, "server")
+	addr, clients := startTLSServer(t, serverCert, serverKey)
+
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	writeNamedCertificate(t, certFile, keyFile, "client-v1")
+
+	creds, err := NewReloadingCredentials(&ReloadConfig{
+		CertFile: certFile,
+		KeyFile:  keyFile,
+		CAFile:   serverCert,
+		Logger:   &discardLogger{},
+	})
+	if err != nil {
+		t.Fatalf("failed to create credentials: %v", err)
+	}
+
+	if name := handshake(t, creds, addr, clients); name != "client-v1" {
+		t.Errorf("expected client-v1, got %q", name)
+	}
+
+	t.Run("manual reload", func(t *testing.T) {
+		writeNamedCertificate(t, certFile, keyFile, "client-v2")
+		if err := creds.Reload(); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+		if name := handshake(t, creds, addr, clients); name != "client-v2" {
+			t.Errorf("expected client-v2, got %q", name)
+		}
+	})
+
+	t.Run("broken files keep previous certificate", func(t *testing.T) {
+		os.WriteFile(keyFile, []byte("half-written"), 0600)
+		if err := creds.Reload(); err == nil {
+			t.Fatal("expected error for broken key")
+		}
+		if name := handshake(t, creds, addr, clients); name != "client-v2" {
+			t.Errorf("expected client-v2, got %q", name)
+		}
+	})
+
+	t.Run("clones share reloads", func(t *testing.T) {
+		clone := creds.Clone().(*ReloadingCredentials)
+		writeNamedCertificate(t, certFile, keyFile, "client-v3")
+		if err := creds.Reload(); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+		if name := handshake(t, clone, addr, clients); name != "client-v3" {
+			t.Errorf("expected client-v3, got %q", name)
+		}
+	})
+}
+
+func TestReloadingCredentials_Run(t *testing.T) {
+	dir := t.TempDir()
+	serverCert, serverKey := filepath.Join(dir, "server.pem"), filepath.Join(dir, "server.key")
+	writeNamedCertificate(t, serverCert, serverKey, "server")
+	addr, clients := startTLSServer(t, serverCert, serverKey)
+
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client.key")
+	writeNamedCertificate(t, certFile, keyFile, "client-v1")
+
+	creds, err := NewReloadingCredentials(&ReloadConfig{
+		CertFile: certFile,
+		KeyFile:  keyFile,
+		CAFile:   serverCert,
+		Interval: 10 * time.Millisecond,
+		Logger:   &discardLogger{},
+	})
+	if err != nil {
+		t.Fatalf("failed to create credentials: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		creds.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// Simulate a rotation; bump the modification time so coarse file system
+	// timestamps still register the change
+	writeNamedCertificate(t, certFile, keyFile, "client-v2")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+	os.Chtimes(keyFile, future, future)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		name := handshake(t, creds, addr, clients)
+		if name == "client-v2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("rotation was not picked up, still presenting %q", name)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
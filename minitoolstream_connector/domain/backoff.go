@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BackoffError is returned by handlers to pause fetching the subject, e.g.
+// when a downstream rate limit was hit. The message is delivered again once
+// the delay has passed; the pause does not count as a delivery attempt.
+type BackoffError struct {
+	Delay time.Duration
+	// Err optionally carries the cause
+	Err error
+}
+
+// Backoff returns an error telling the subscriber to pause the subject for d
+func Backoff(d time.Duration) error {
+	return &BackoffError{Delay: d}
+}
+
+// BackoffWithCause is like Backoff and records why the handler backed off
+func BackoffWithCause(d time.Duration, cause error) error {
+	return &BackoffError{Delay: d, Err: cause}
+}
+
+func (e *BackoffError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("backoff %v: %v", e.Delay, e.Err)
+	}
+	return fmt.Sprintf("backoff %v", e.Delay)
+}
+
+func (e *BackoffError) Unwrap() error {
+	return e.Err
+}
+
+// BackoffDelay returns the delay requested by a BackoffError in err's chain
+func BackoffDelay(err error) (time.Duration, bool) {
+	var backoff *BackoffError
+	if !errors.As(err, &backoff) {
+		return 0, false
+	}
+	return backoff.Delay, true
+}
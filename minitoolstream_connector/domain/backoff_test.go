@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	if _, ok := BackoffDelay(errors.New("failed")); ok {
+		t.Error("expected no backoff for a plain error")
+	}
+
+	err := fmt.Errorf("handler: %w", Backoff(time.Second))
+	if d, ok := BackoffDelay(err); !ok || d != time.Second {
+		t.Errorf("expected 1s backoff, got %v (%v)", d, ok)
+	}
+
+	cause := errors.New("rate limited")
+	err = errors.Join(errors.New("other handler failed"), BackoffWithCause(2*time.Second, cause))
+	if d, ok := BackoffDelay(err); !ok || d != 2*time.Second {
+		t.Errorf("expected 2s backoff in joined error, got %v (%v)", d, ok)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("expected the cause to be unwrapped")
+	}
+	if got := BackoffWithCause(time.Second, cause).Error(); got != "backoff 1s: rate limited" {
+		t.Errorf("unexpected message %q", got)
+	}
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Clock tells the time and waits for it to pass. Components that stamp,
+// expire or back off take a Clock so tests can control time; a nil Clock in
+// their configuration means SystemClock.
+type Clock interface {
+	Now() time.Time
+	// After returns a channel that receives the current time once d has elapsed
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock backed by the time package
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// ClockOrSystem returns c, or SystemClock when c is nil
+func ClockOrSystem(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+	return c
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+type stubClock struct{ now time.Time }
+
+func (c stubClock) Now() time.Time                         { return c.now }
+func (c stubClock) After(d time.Duration) <-chan time.Time { return nil }
+
+func TestClockOrSystem(t *testing.T) {
+	if ClockOrSystem(nil) != SystemClock {
+		t.Error("expected SystemClock for nil")
+	}
+
+	c := stubClock{now: time.Unix(42, 0)}
+	if ClockOrSystem(c) != Clock(c) {
+		t.Error("expected the given clock")
+	}
+}
+
+func TestSystemClock(t *testing.T) {
+	before := time.Now()
+	if now := SystemClock.Now(); now.Before(before) {
+		t.Errorf("expected a current time, got %v", now)
+	}
+
+	select {
+	case <-SystemClock.After(time.Millisecond):
+	case <-time.After(time.Second):
+		t.Error("After did not fire")
+	}
+}
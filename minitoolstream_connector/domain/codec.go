@@ -0,0 +1,9 @@
+package domain
+
+// Codec encodes values into payloads of one content type and decodes them back
+type Codec interface {
+	// ContentType is the media type written to the content-type header
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
@@ -0,0 +1,16 @@
+package domain
+
+// Compressor compresses payloads with one content encoding
+type Compressor interface {
+	// Encoding is the name written to the content-encoding header
+	Encoding() string
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// Decompressor restores messages published with a content-encoding header
+type Decompressor interface {
+	// Decompress returns msg with its payload decompressed and the
+	// content-encoding header removed, or msg itself when it has no encoding
+	Decompress(msg *ReceivedMessage) (*ReceivedMessage, error)
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "context"
+
+// deliveryAttemptKey is the context key for the delivery attempt number
+type deliveryAttemptKey struct{}
+
+// WithDeliveryAttempt returns a context carrying the delivery attempt number
+func WithDeliveryAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, deliveryAttemptKey{}, attempt)
+}
+
+// DeliveryAttempt returns the delivery attempt number (starting at 1) of the
+// message being handled, or 1 if the context carries none
+func DeliveryAttempt(ctx context.Context) int {
+	if attempt, ok := ctx.Value(deliveryAttemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
+
+// correlationIDKey is the context key for the correlation ID
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a context carrying the correlation ID. Messages
+// published with the context inherit it, so it follows work across services.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation ID carried by the context, or "" if
+// it carries none
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDeliveryAttempt(t *testing.T) {
+	t.Run("default attempt", func(t *testing.T) {
+		if attempt := DeliveryAttempt(context.Background()); attempt != 1 {
+			t.Errorf("expected attempt 1, got %d", attempt)
+		}
+	})
+
+	t.Run("attempt from context", func(t *testing.T) {
+		ctx := WithDeliveryAttempt(context.Background(), 3)
+		if attempt := DeliveryAttempt(ctx); attempt != 3 {
+			t.Errorf("expected attempt 3, got %d", attempt)
+		}
+	})
+}
+
+func TestCorrelationID(t *testing.T) {
+	if id := CorrelationID(context.Background()); id != "" {
+		t.Errorf("expected no correlation ID, got %q", id)
+	}
+
+	ctx := WithCorrelationID(context.Background(), "abc")
+	if id := CorrelationID(ctx); id != "abc" {
+		t.Errorf("expected correlation ID abc, got %q", id)
+	}
+}
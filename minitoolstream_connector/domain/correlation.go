@@ -0,0 +1,14 @@
+package domain
+
+// CorrelationID returns the correlation ID the message was published with, if any
+func (m *ReceivedMessage) CorrelationID() string {
+	return m.Headers[HeaderCorrelationID]
+}
+
+// SetCorrelationID sets the correlation-id header of the message
+func (m *PublishMessage) SetCorrelationID(id string) {
+	if m.Headers == nil {
+		m.Headers = make(map[string]string)
+	}
+	m.Headers[HeaderCorrelationID] = id
+}
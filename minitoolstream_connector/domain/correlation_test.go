@@ -0,0 +1,19 @@
+package domain
+
+import "testing"
+
+func TestCorrelationIDHeader(t *testing.T) {
+	pub := &PublishMessage{Subject: "s"}
+	pub.SetCorrelationID("abc")
+	if pub.Headers[HeaderCorrelationID] != "abc" {
+		t.Errorf("expected correlation-id header, got %v", pub.Headers)
+	}
+
+	msg := &ReceivedMessage{Headers: pub.Headers}
+	if id := msg.CorrelationID(); id != "abc" {
+		t.Errorf("expected correlation ID abc, got %q", id)
+	}
+	if id := (&ReceivedMessage{}).CorrelationID(); id != "" {
+		t.Errorf("expected no correlation ID, got %q", id)
+	}
+}
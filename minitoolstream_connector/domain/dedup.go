@@ -0,0 +1,21 @@
+package domain
+
+// WireHeaders returns the headers sent with the message: Headers plus the
+// idempotency-key header carrying DedupKey, when set
+func (m *PublishMessage) WireHeaders() map[string]string {
+	if m.DedupKey == "" {
+		return m.Headers
+	}
+
+	headers := make(map[string]string, len(m.Headers)+1)
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+	headers[HeaderIdempotencyKey] = m.DedupKey
+	return headers
+}
+
+// DedupKey returns the key identifying the logical message across retries, if any
+func (m *ReceivedMessage) DedupKey() string {
+	return m.Headers[HeaderIdempotencyKey]
+}
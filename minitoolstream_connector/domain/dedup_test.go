@@ -0,0 +1,30 @@
+package domain
+
+import "testing"
+
+func TestPublishMessage_WireHeaders(t *testing.T) {
+	t.Run("without dedup key", func(t *testing.T) {
+		msg := &PublishMessage{Headers: map[string]string{"k": "v"}}
+		if headers := msg.WireHeaders(); len(headers) != 1 || headers["k"] != "v" {
+			t.Errorf("unexpected headers: %v", headers)
+		}
+	})
+
+	t.Run("with dedup key", func(t *testing.T) {
+		msg := &PublishMessage{Headers: map[string]string{"k": "v"}, DedupKey: "order-42"}
+		headers := msg.WireHeaders()
+		if headers[HeaderIdempotencyKey] != "order-42" || headers["k"] != "v" {
+			t.Errorf("unexpected headers: %v", headers)
+		}
+		if _, ok := msg.Headers[HeaderIdempotencyKey]; ok {
+			t.Error("expected message headers to be left unchanged")
+		}
+	})
+}
+
+func TestReceivedMessage_DedupKey(t *testing.T) {
+	msg := &ReceivedMessage{Headers: map[string]string{HeaderIdempotencyKey: "order-42"}}
+	if msg.DedupKey() != "order-42" {
+		t.Errorf("expected order-42, got %q", msg.DedupKey())
+	}
+}
@@ -0,0 +1,11 @@
+package domain
+
+// Differ computes and applies deltas between versions of a payload
+type Differ interface {
+	// Format is the name written to the delta-format header
+	Format() string
+	// Diff returns the delta turning base into target
+	Diff(base, target []byte) ([]byte, error)
+	// Patch applies a delta produced by Diff to base
+	Patch(base, delta []byte) ([]byte, error)
+}
@@ -10,6 +10,9 @@ type PublishMessage struct {
 	Subject string
 	Data    []byte
 	Headers map[string]string
+	// DedupKey identifies the logical message across retries; it is sent as
+	// the idempotency-key header
+	DedupKey string
 }
 
 // ReceivedMessage represents a message received from Egress
@@ -29,6 +32,24 @@ type PublishResult struct {
 	ErrorMessage string
 }
 
+// FanOutResult is the outcome of publishing to one subject of a fan-out
+type FanOutResult struct {
+	Subject string
+	Result  *PublishResult
+	Err     error
+}
+
+// PublishOutcome is the outcome of one preparer of a PublishAllResults call
+type PublishOutcome struct {
+	// Index is the zero-based position of the preparer
+	Index int
+	// Subject is the subject of the prepared message; empty when preparing failed
+	Subject string
+	// Result is the server's result; nil when the message was not sent
+	Result *PublishResult
+	Err    error
+}
+
 // Notification represents a notification about new messages
 type Notification struct {
 	Subject  string
@@ -40,6 +61,7 @@ type SubscriptionConfig struct {
 	Subject       string
 	DurableName   string
 	StartSequence *uint64
+	StartPosition StartPosition
 	BatchSize     int32
 }
 
@@ -69,6 +91,27 @@ func (f ResultHandlerFunc) Handle(ctx context.Context, result *PublishResult) er
 	return f(ctx, result)
 }
 
+// MessageResultHandler is a ResultHandler that also receives the published
+// message, so results of concurrent publishes can be matched to their messages.
+// The publisher calls HandleMessage instead of Handle when it is implemented.
+type MessageResultHandler interface {
+	ResultHandler
+	HandleMessage(ctx context.Context, msg *PublishMessage, result *PublishResult) error
+}
+
+// MessageResultHandlerFunc is a function adapter for MessageResultHandler
+type MessageResultHandlerFunc func(ctx context.Context, msg *PublishMessage, result *PublishResult) error
+
+// Handle implements ResultHandler interface; the message is nil
+func (f MessageResultHandlerFunc) Handle(ctx context.Context, result *PublishResult) error {
+	return f(ctx, nil, result)
+}
+
+// HandleMessage implements MessageResultHandler interface
+func (f MessageResultHandlerFunc) HandleMessage(ctx context.Context, msg *PublishMessage, result *PublishResult) error {
+	return f(ctx, msg, result)
+}
+
 // MessageHandler processes received messages
 type MessageHandler interface {
 	Handle(ctx context.Context, msg *ReceivedMessage) error
@@ -81,3 +124,16 @@ type MessageHandlerFunc func(ctx context.Context, msg *ReceivedMessage) error
 func (f MessageHandlerFunc) Handle(ctx context.Context, msg *ReceivedMessage) error {
 	return f(ctx, msg)
 }
+
+// FailureSink receives messages whose handler kept failing after all delivery attempts
+type FailureSink interface {
+	Handle(ctx context.Context, msg *ReceivedMessage, cause error) error
+}
+
+// FailureSinkFunc is a function adapter for FailureSink
+type FailureSinkFunc func(ctx context.Context, msg *ReceivedMessage, cause error) error
+
+// Handle implements FailureSink interface
+func (f FailureSinkFunc) Handle(ctx context.Context, msg *ReceivedMessage, cause error) error {
+	return f(ctx, msg, cause)
+}
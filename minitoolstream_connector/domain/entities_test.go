@@ -102,6 +102,36 @@ func TestResultHandlerFunc(t *testing.T) {
 	})
 }
 
+func TestMessageResultHandlerFunc(t *testing.T) {
+	msg := &PublishMessage{Subject: "orders"}
+	result := &PublishResult{Sequence: 7}
+
+	var gotMsg *PublishMessage
+	handler := MessageResultHandlerFunc(func(ctx context.Context, m *PublishMessage, r *PublishResult) error {
+		gotMsg = m
+		if r != result {
+			t.Errorf("expected result %v, got %v", result, r)
+		}
+		return nil
+	})
+
+	var _ MessageResultHandler = handler
+
+	if err := handler.HandleMessage(context.Background(), msg, result); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if gotMsg != msg {
+		t.Error("expected the published message")
+	}
+
+	if err := handler.Handle(context.Background(), result); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if gotMsg != nil {
+		t.Error("expected nil message from Handle")
+	}
+}
+
 func TestMessageHandlerFunc(t *testing.T) {
 	t.Run("successful handle", func(t *testing.T) {
 		called := false
@@ -318,3 +348,21 @@ func TestSubscriptionConfig(t *testing.T) {
 		}
 	})
 }
+
+func TestFailureSinkFunc(t *testing.T) {
+	t.Run("function adapter", func(t *testing.T) {
+		var got error
+		sink := FailureSinkFunc(func(ctx context.Context, msg *ReceivedMessage, cause error) error {
+			got = cause
+			return nil
+		})
+
+		cause := errors.New("handler error")
+		if err := sink.Handle(context.Background(), &ReceivedMessage{}, cause); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got != cause {
+			t.Error("expected cause to be passed to the sink")
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "errors"
+
+// ErrPayloadTooLarge is returned when a message exceeds the configured payload size limit
+var ErrPayloadTooLarge = errors.New("payload too large")
+
+// Transport errors, matched with errors.Is on errors returned by the clients
+var (
+	// ErrUnavailable means the server could not be reached; the call may be retried
+	ErrUnavailable = errors.New("server unavailable")
+
+	// ErrUnauthenticated means the credentials were missing, invalid or not permitted for the call
+	ErrUnauthenticated = errors.New("unauthenticated")
+
+	// ErrResourceExhausted means a quota or rate limit was hit; retry after backing off
+	ErrResourceExhausted = errors.New("resource exhausted")
+
+	// ErrNotFound means the subject or other requested entity does not exist
+	ErrNotFound = errors.New("not found")
+)
+
+// ErrDecode is returned when a payload cannot be decoded into the requested
+// type, e.g. because it is malformed or has an unsupported content type
+var ErrDecode = errors.New("cannot decode payload")
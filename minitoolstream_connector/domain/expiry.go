@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// SetExpiresAt sets the expires-at header of the message
+func (m *PublishMessage) SetExpiresAt(t time.Time) {
+	if m.Headers == nil {
+		m.Headers = make(map[string]string)
+	}
+	m.Headers[HeaderExpiresAt] = t.UTC().Format(time.RFC3339Nano)
+}
+
+// ExpiresAt returns the expiry time from the expires-at header, if present and valid
+func (m *ReceivedMessage) ExpiresAt() (time.Time, bool) {
+	value, ok := m.Headers[HeaderExpiresAt]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Expired reports whether the message carries an expiry that lies before now
+func (m *ReceivedMessage) Expired(now time.Time) bool {
+	expiresAt, ok := m.ExpiresAt()
+	return ok && now.After(expiresAt)
+}
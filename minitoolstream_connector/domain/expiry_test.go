@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishMessage_SetExpiresAt(t *testing.T) {
+	t.Run("sets header on nil map", func(t *testing.T) {
+		msg := &PublishMessage{Subject: "test"}
+		expiresAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		msg.SetExpiresAt(expiresAt)
+
+		if msg.Headers[HeaderExpiresAt] != "2024-05-01T10:00:00Z" {
+			t.Errorf("unexpected header value: %s", msg.Headers[HeaderExpiresAt])
+		}
+	})
+}
+
+func TestReceivedMessage_Expired(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		expired bool
+	}{
+		{"no header", nil, false},
+		{"invalid header", map[string]string{HeaderExpiresAt: "tomorrow"}, false},
+		{"in the future", map[string]string{HeaderExpiresAt: now.Add(time.Minute).Format(time.RFC3339Nano)}, false},
+		{"in the past", map[string]string{HeaderExpiresAt: now.Add(-time.Minute).Format(time.RFC3339Nano)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &ReceivedMessage{Headers: tt.headers}
+			if got := msg.Expired(now); got != tt.expired {
+				t.Errorf("expected expired=%v, got %v", tt.expired, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package domain
+
+// GroupID returns the publish group the message belongs to, if any
+func (m *ReceivedMessage) GroupID() (string, bool) {
+	id, ok := m.Headers[HeaderGroupID]
+	return id, ok && id != ""
+}
+
+// GroupAborted reports whether the message announces that its group was
+// aborted; consumers should discard messages already received for that group
+func (m *ReceivedMessage) GroupAborted() bool {
+	return m.Headers[HeaderGroupAborted] == "true"
+}
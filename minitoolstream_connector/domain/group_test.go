@@ -0,0 +1,22 @@
+package domain
+
+import "testing"
+
+func TestReceivedMessage_Group(t *testing.T) {
+	msg := &ReceivedMessage{Headers: map[string]string{HeaderGroupID: "g1"}}
+	if id, ok := msg.GroupID(); !ok || id != "g1" {
+		t.Errorf("expected group g1, got %q (%v)", id, ok)
+	}
+	if msg.GroupAborted() {
+		t.Error("expected regular group member")
+	}
+
+	msg.Headers[HeaderGroupAborted] = "true"
+	if !msg.GroupAborted() {
+		t.Error("expected aborted group marker")
+	}
+
+	if _, ok := (&ReceivedMessage{}).GroupID(); ok {
+		t.Error("expected no group without header")
+	}
+}
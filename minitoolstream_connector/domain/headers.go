@@ -0,0 +1,45 @@
+package domain
+
+// Well-known message header names
+const (
+	// HeaderIdempotencyKey carries a client-generated key that identifies a
+	// logical publish across retries, allowing the ingest layer to drop duplicates
+	HeaderIdempotencyKey = "idempotency-key"
+	// HeaderExpiresAt carries the RFC 3339 time after which a message should no longer be processed
+	HeaderExpiresAt = "expires-at"
+	// HeaderCorrelationID identifies the chain of messages caused by one
+	// request, so a message can be followed across services
+	HeaderCorrelationID = "correlation-id"
+	// HeaderGroupID identifies the messages published together by PublishGroup
+	HeaderGroupID = "group-id"
+	// HeaderGroupSize carries the number of messages in the group
+	HeaderGroupSize = "group-size"
+	// HeaderGroupIndex carries the zero-based position of the message in its group
+	HeaderGroupIndex = "group-index"
+	// HeaderGroupAborted marks the compensating message sent when a group could
+	// not be published completely
+	HeaderGroupAborted = "group-aborted"
+	// HeaderProtoMessage carries the full name of the protobuf message type of
+	// a protobuf payload
+	HeaderProtoMessage = "proto-message"
+	// HeaderProtoTypeURL carries the type URL of a protobuf payload published
+	// from an anypb.Any
+	HeaderProtoTypeURL = "proto-type-url"
+	// HeaderContentEncoding names the compression applied to the payload,
+	// e.g. gzip or zstd
+	HeaderContentEncoding = "content-encoding"
+	// HeaderDeltaFormat names the delta format of a payload that holds only
+	// the changes against an earlier full payload, e.g. json-patch
+	HeaderDeltaFormat = "delta-format"
+	// HeaderDeltaBase carries the sequence of the full payload a delta
+	// applies to
+	HeaderDeltaBase = "delta-base"
+	// HeaderClaimCheck carries the object storage key of a payload published
+	// by reference instead of inline
+	HeaderClaimCheck = "claim-check"
+	// HeaderClaimCheckSize carries the size in bytes of the referenced payload
+	HeaderClaimCheckSize = "claim-check-size"
+	// HeaderClaimCheckDigest carries the hex SHA-256 digest of the referenced
+	// payload
+	HeaderClaimCheckDigest = "claim-check-digest"
+)
@@ -3,6 +3,8 @@ package domain
 import (
 	"context"
 	"io"
+	"regexp"
+	"time"
 )
 
 // IngressClient represents the interface for communicating with MiniToolStreamIngress
@@ -19,6 +21,13 @@ type EgressClient interface {
 	Close() error
 }
 
+// FetchAcker is implemented by egress clients that acknowledge messages when
+// they are fetched, before any handler runs. Delivery through such a client is
+// at most once: the server does not redeliver a message whose handler failed.
+type FetchAcker interface {
+	AcksOnFetch() bool
+}
+
 // NotificationStream represents a stream of notifications
 type NotificationStream interface {
 	Recv() (*Notification, error)
@@ -29,25 +38,61 @@ type MessageStream interface {
 	Recv() (*ReceivedMessage, error)
 }
 
+// SubjectLister is implemented by clients that can list the subjects known to the server
+type SubjectLister interface {
+	ListSubjects(ctx context.Context) ([]string, error)
+}
+
+// CheckpointStore persists the last processed sequence per durable consumer and subject
+type CheckpointStore interface {
+	Load(ctx context.Context, durableName, subject string) (uint64, bool, error)
+	Save(ctx context.Context, durableName, subject string, sequence uint64) error
+}
+
 // Publisher represents the interface for publishing messages
 type Publisher interface {
 	Publish(ctx context.Context, preparer MessagePreparer) error
 	PublishAll(ctx context.Context, preparers []MessagePreparer) error
+	PublishAllResults(ctx context.Context, preparers []MessagePreparer) ([]PublishOutcome, error)
+	PublishGroup(ctx context.Context, preparers []MessagePreparer) error
+	PublishFanOut(ctx context.Context, subjects []string, data []byte, headers map[string]string) ([]FanOutResult, error)
+	PublishEvery(ctx context.Context, preparer MessagePreparer, interval time.Duration) error
+	PublishSchedule(ctx context.Context, preparer MessagePreparer, schedule Schedule) error
 	RegisterHandler(preparer MessagePreparer)
 	RegisterHandlers(preparers []MessagePreparer)
+	RegisterPreparerFunc(fn func(ctx context.Context) (*PublishMessage, error))
 	SetResultHandler(handler ResultHandler)
 	Close() error
 }
 
+// Schedule decides when a scheduled publish runs next
+type Schedule interface {
+	// Next returns the first run time after the given time, or the zero time
+	// when the schedule has no further runs
+	Next(after time.Time) time.Time
+}
+
 // Subscriber represents the interface for subscribing to subjects
 type Subscriber interface {
 	RegisterHandler(subject string, handler MessageHandler)
 	RegisterHandlers(handlers map[string]MessageHandler)
-	Start() error
+	RegisterHandlerFunc(subject string, fn func(ctx context.Context, msg *ReceivedMessage) error)
+	RegisterPatternHandler(pattern *regexp.Regexp, handler MessageHandler)
+	Messages(ctx context.Context, subject string) (MessageIterator, error)
+	SetErrorHandler(handler func(subject string, msg *ReceivedMessage, err error))
+	Start(ctx context.Context) error
 	Stop()
+	Drain(ctx context.Context) error
 	Wait()
 }
 
+// MessageIterator pulls messages of one subject on demand
+type MessageIterator interface {
+	Next(ctx context.Context) (*ReceivedMessage, error)
+	// Close ends the subscription
+	Close() error
+}
+
 // IsEOF checks if error is EOF
 func IsEOF(err error) bool {
 	return err == io.EOF
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LogFormatter renders a log line of the publisher or subscriber before it
+// is handed to the Logger
+type LogFormatter interface {
+	Format(level LogLevel, message string) string
+}
+
+// EmojiLogFormatter keeps lines as written, status symbols included. This is
+// what the publisher and subscriber log without a formatter.
+type EmojiLogFormatter struct{}
+
+// Format returns the message unchanged
+func (EmojiLogFormatter) Format(level LogLevel, message string) string {
+	return message
+}
+
+// PlainLogFormatter strips status symbols such as ✓, ✗ and 📬 for log
+// pipelines that expect plain text
+type PlainLogFormatter struct{}
+
+// Format returns the message without symbols
+func (PlainLogFormatter) Format(level LogLevel, message string) string {
+	var b strings.Builder
+	b.Grow(len(message))
+	skipSpace := false
+	for _, r := range message {
+		if unicode.Is(unicode.So, r) || r == '\ufe0f' {
+			skipSpace = true
+			continue
+		}
+		if skipSpace && r == ' ' {
+			skipSpace = false
+			continue
+		}
+		skipSpace = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// JSONLogFormatter renders every line as a JSON object with the time, level
+// and symbol-free message. Pair it with a Logger without a prefix, e.g.
+// log.New(os.Stderr, "", 0), so each line stays valid JSON.
+type JSONLogFormatter struct {
+	// Clock stamps the lines (default SystemClock)
+	Clock Clock
+}
+
+// jsonLogLine is one line written by JSONLogFormatter
+type jsonLogLine struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+}
+
+// Format returns the message as a JSON object
+func (f JSONLogFormatter) Format(level LogLevel, message string) string {
+	data, err := json.Marshal(jsonLogLine{
+		Time:    ClockOrSystem(f.Clock).Now().UTC(),
+		Level:   level.String(),
+		Message: PlainLogFormatter{}.Format(level, message),
+	})
+	if err != nil {
+		return message
+	}
+	return string(data)
+}
+
+// ParseLogFormatter returns the formatter named "emoji", "plain" or "json",
+// e.g. from a config file
+func ParseLogFormatter(s string) (LogFormatter, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "emoji", "":
+		return EmojiLogFormatter{}, nil
+	case "plain", "text":
+		return PlainLogFormatter{}, nil
+	case "json":
+		return JSONLogFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", s)
+	}
+}
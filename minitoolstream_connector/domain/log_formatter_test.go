@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestPlainLogFormatter(t *testing.T) {
+	tests := map[string]string{
+		"✓ Published: sequence=7":                      "Published: sequence=7",
+		"[orders] 📬 Notification received: sequence=3": "[orders] Notification received: sequence=3",
+		"[orders] ✗ Subscriber is stopped":             "[orders] Subscriber is stopped",
+		"Publishing 3 messages...":                     "Publishing 3 messages...",
+	}
+	for in, want := range tests {
+		if got := (PlainLogFormatter{}).Format(LogLevelInfo, in); got != want {
+			t.Errorf("Format(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestJSONLogFormatter(t *testing.T) {
+	f := JSONLogFormatter{Clock: stubClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}}
+
+	var line struct {
+		Time    time.Time `json:"time"`
+		Level   string    `json:"level"`
+		Message string    `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(f.Format(LogLevelError, `✗ Publish failed: error="denied"`)), &line); err != nil {
+		t.Fatalf("expected valid JSON: %v", err)
+	}
+	if !line.Time.Equal(f.Clock.Now()) || line.Level != "error" || line.Message != `Publish failed: error="denied"` {
+		t.Errorf("unexpected line: %+v", line)
+	}
+}
+
+func TestParseLogFormatter(t *testing.T) {
+	tests := map[string]LogFormatter{
+		"":      EmojiLogFormatter{},
+		"emoji": EmojiLogFormatter{},
+		"Plain": PlainLogFormatter{},
+		"json":  JSONLogFormatter{},
+	}
+	for in, want := range tests {
+		got, err := ParseLogFormatter(in)
+		if err != nil || got != want {
+			t.Errorf("ParseLogFormatter(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseLogFormatter("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
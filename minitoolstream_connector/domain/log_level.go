@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LogLevel controls how much the publisher and subscriber log
+type LogLevel int
+
+// Log levels, from most to least verbose
+const (
+	// LogLevelDebug logs every message (the default)
+	LogLevelDebug LogLevel = iota
+	// LogLevelInfo logs lifecycle events and errors but not individual messages
+	LogLevelInfo
+	// LogLevelError logs errors only
+	LogLevelError
+	// LogLevelOff logs nothing
+	LogLevelOff
+)
+
+// ParseLogLevel parses "debug", "info", "error" or "off", e.g. from a config file
+func ParseLogLevel(s string) (LogLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug", "":
+		return LogLevelDebug, nil
+	case "info":
+		return LogLevelInfo, nil
+	case "error":
+		return LogLevelError, nil
+	case "off", "quiet":
+		return LogLevelOff, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q", s)
+	}
+}
+
+// String returns the name of the level
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelError:
+		return "error"
+	case LogLevelOff:
+		return "off"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// Enabled reports whether messages at level are logged when l is configured
+func (l LogLevel) Enabled(level LogLevel) bool {
+	return level >= l
+}
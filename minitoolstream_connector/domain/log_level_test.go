@@ -0,0 +1,34 @@
+package domain
+
+import "testing"
+
+func TestParseLogLevel(t *testing.T) {
+	for _, level := range []LogLevel{LogLevelDebug, LogLevelInfo, LogLevelError, LogLevelOff} {
+		parsed, err := ParseLogLevel(level.String())
+		if err != nil || parsed != level {
+			t.Errorf("expected %v, got %v (%v)", level, parsed, err)
+		}
+	}
+
+	if level, err := ParseLogLevel(" INFO "); err != nil || level != LogLevelInfo {
+		t.Errorf("expected info, got %v (%v)", level, err)
+	}
+	if level, err := ParseLogLevel("quiet"); err != nil || level != LogLevelOff {
+		t.Errorf("expected off, got %v (%v)", level, err)
+	}
+	if _, err := ParseLogLevel("verbose"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+}
+
+func TestLogLevel_Enabled(t *testing.T) {
+	if !LogLevelInfo.Enabled(LogLevelError) || !LogLevelInfo.Enabled(LogLevelInfo) {
+		t.Error("expected info and error messages at info level")
+	}
+	if LogLevelInfo.Enabled(LogLevelDebug) {
+		t.Error("expected debug messages to be dropped at info level")
+	}
+	if LogLevelOff.Enabled(LogLevelError) {
+		t.Error("expected nothing to be logged when off")
+	}
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LogSampler thins out per-message log lines at high throughput: it passes
+// one line in every N and periodically reports how many it suppressed
+type LogSampler struct {
+	every    uint64
+	interval time.Duration
+	clock    Clock
+
+	mu          sync.Mutex
+	seen        uint64
+	suppressed  uint64
+	lastSummary time.Time
+}
+
+// NewLogSampler creates a sampler passing one line in every (0 and 1 pass
+// all) and summarizing suppressed lines every interval (0 disables summaries)
+func NewLogSampler(every int, interval time.Duration, clock Clock) *LogSampler {
+	clock = ClockOrSystem(clock)
+	if every < 1 {
+		every = 1
+	}
+	return &LogSampler{
+		every:       uint64(every),
+		interval:    interval,
+		clock:       clock,
+		lastSummary: clock.Now(),
+	}
+}
+
+// Sample reports whether a line should be logged. When a summary is due, it
+// is returned to be logged first.
+func (s *LogSampler) Sample() (bool, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pass := s.seen%s.every == 0
+	s.seen++
+	if !pass {
+		s.suppressed++
+	}
+
+	var summary string
+	if s.interval > 0 && s.suppressed > 0 {
+		if now := s.clock.Now(); now.Sub(s.lastSummary) >= s.interval {
+			summary = fmt.Sprintf("Log sampling: suppressed %d per-message lines in the last %v", s.suppressed, now.Sub(s.lastSummary).Round(time.Second))
+			s.suppressed = 0
+			s.lastSummary = now
+		}
+	}
+	return pass, summary
+}
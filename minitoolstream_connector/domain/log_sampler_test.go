@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// steppingClock is a clock advanced by the test
+type steppingClock struct{ now time.Time }
+
+func (c *steppingClock) Now() time.Time                         { return c.now }
+func (c *steppingClock) After(d time.Duration) <-chan time.Time { return nil }
+
+func TestLogSampler_OneInN(t *testing.T) {
+	s := NewLogSampler(3, 0, nil)
+
+	var passed []int
+	for i := 0; i < 7; i++ {
+		if pass, summary := s.Sample(); pass {
+			passed = append(passed, i)
+		} else if summary != "" {
+			t.Errorf("expected no summary with summaries disabled, got %q", summary)
+		}
+	}
+	if len(passed) != 3 || passed[0] != 0 || passed[1] != 3 || passed[2] != 6 {
+		t.Errorf("expected lines 0, 3 and 6 to pass, got %v", passed)
+	}
+
+	all := NewLogSampler(0, 0, nil)
+	for i := 0; i < 3; i++ {
+		if pass, _ := all.Sample(); !pass {
+			t.Errorf("expected every line to pass with every=0")
+		}
+	}
+}
+
+func TestLogSampler_Summary(t *testing.T) {
+	clock := &steppingClock{now: time.Unix(1000, 0)}
+	s := NewLogSampler(10, 5*time.Second, clock)
+
+	for i := 0; i < 20; i++ {
+		if _, summary := s.Sample(); summary != "" {
+			t.Fatalf("expected no summary before the interval, got %q", summary)
+		}
+	}
+
+	clock.now = clock.now.Add(5 * time.Second)
+	_, summary := s.Sample()
+	if !strings.Contains(summary, "suppressed 18 ") || !strings.Contains(summary, "5s") {
+		t.Errorf("unexpected summary %q", summary)
+	}
+
+	clock.now = clock.now.Add(10 * time.Second)
+	if _, summary := s.Sample(); !strings.Contains(summary, "suppressed 1 ") {
+		t.Errorf("expected the count to restart after a summary, got %q", summary)
+	}
+}
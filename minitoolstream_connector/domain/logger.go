@@ -0,0 +1,17 @@
+package domain
+
+import "log"
+
+// Logger defines the logging interface
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// DefaultLogger writes through the standard log package. Packages fall back
+// to it when no Logger is configured.
+type DefaultLogger struct{}
+
+// Printf logs with log.Printf
+func (DefaultLogger) Printf(format string, v ...interface{}) {
+	log.Printf(format, v...)
+}
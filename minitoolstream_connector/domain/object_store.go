@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+// ObjectStore holds payloads published by reference, e.g. in a bucket
+type ObjectStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// PayloadFetcher restores messages whose payload was published by reference
+type PayloadFetcher interface {
+	// Fetch returns msg with its payload fetched, or msg itself when it
+	// carries its payload inline
+	Fetch(ctx context.Context, msg *ReceivedMessage) (*ReceivedMessage, error)
+}
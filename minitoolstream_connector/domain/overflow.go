@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OverflowPolicy decides what the subscriber does with a notification when a
+// subject's notification queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest drops the incoming notification and fetches to catch
+	// up once the queue empties (the default)
+	OverflowDropNewest OverflowPolicy = iota
+	// OverflowBlock stops reading the notification stream until there is room
+	OverflowBlock
+	// OverflowDropOldest drops the oldest queued notification to make room and
+	// fetches to catch up once the queue empties
+	OverflowDropOldest
+	// OverflowSpillToDisk writes the notification to a temp file and processes
+	// it after the queued ones
+	OverflowSpillToDisk
+)
+
+// ParseOverflowPolicy parses "drop-newest", "block", "drop-oldest" or
+// "spill-to-disk", e.g. from a config file
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "drop-newest", "":
+		return OverflowDropNewest, nil
+	case "block":
+		return OverflowBlock, nil
+	case "drop-oldest":
+		return OverflowDropOldest, nil
+	case "spill-to-disk", "spill":
+		return OverflowSpillToDisk, nil
+	default:
+		return 0, fmt.Errorf("unknown overflow policy %q", s)
+	}
+}
+
+// String returns the name of the policy
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowDropNewest:
+		return "drop-newest"
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowSpillToDisk:
+		return "spill-to-disk"
+	default:
+		return fmt.Sprintf("OverflowPolicy(%d)", int(p))
+	}
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "testing"
+
+func TestParseOverflowPolicy(t *testing.T) {
+	for _, policy := range []OverflowPolicy{OverflowDropNewest, OverflowBlock, OverflowDropOldest, OverflowSpillToDisk} {
+		parsed, err := ParseOverflowPolicy(policy.String())
+		if err != nil || parsed != policy {
+			t.Errorf("ParseOverflowPolicy(%q) = %v, %v", policy.String(), parsed, err)
+		}
+	}
+	if p, err := ParseOverflowPolicy(""); err != nil || p != OverflowDropNewest {
+		t.Errorf("expected the empty string to parse as drop-newest, got %v, %v", p, err)
+	}
+	if _, err := ParseOverflowPolicy("discard"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+	if s := OverflowPolicy(9).String(); s != "OverflowPolicy(9)" {
+		t.Errorf("unexpected String for an unknown policy: %s", s)
+	}
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PublishFailure is the failure of one preparer of a PublishAll call
+type PublishFailure struct {
+	// Index is the zero-based position of the preparer
+	Index int
+	// Subject is the subject of the prepared message; empty when preparing failed
+	Subject string
+	Err     error
+}
+
+func (f PublishFailure) Error() string {
+	if f.Subject != "" {
+		return fmt.Sprintf("message %d (%s): %v", f.Index+1, f.Subject, f.Err)
+	}
+	return fmt.Sprintf("message %d: %v", f.Index+1, f.Err)
+}
+
+func (f PublishFailure) Unwrap() error {
+	return f.Err
+}
+
+// PublishAllError is returned by PublishAll when some preparers failed. The
+// failures are ordered by preparer index; errors.Is and errors.As match
+// against the cause of every failure.
+type PublishAllError struct {
+	// Total is the number of preparers in the call
+	Total    int
+	Failures []PublishFailure
+}
+
+func (e *PublishAllError) Error() string {
+	causes := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		causes[i] = f.Error()
+	}
+	return fmt.Sprintf("failed to publish %d of %d messages: %s", len(e.Failures), e.Total, strings.Join(causes, "; "))
+}
+
+func (e *PublishAllError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f
+	}
+	return errs
+}
+
+// Indexes returns the preparer indexes that failed, e.g. to retry only those
+func (e *PublishAllError) Indexes() []int {
+	indexes := make([]int, len(e.Failures))
+	for i, f := range e.Failures {
+		indexes[i] = f.Index
+	}
+	return indexes
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// StartPositionKind identifies where a subscription starts reading a subject
+type StartPositionKind int
+
+const (
+	// StartDefault leaves the start position to the server (durable cursor)
+	StartDefault StartPositionKind = iota
+	// StartEarliest starts from the first available message
+	StartEarliest
+	// StartLatest starts after the last message available at subscribe time
+	StartLatest
+	// StartSequence starts from a specific sequence number
+	StartSequence
+	// StartTime starts from the first message published at or after a point in time
+	StartTime
+)
+
+// StartPosition describes where a subscription starts reading a subject.
+// The zero value is StartDefault.
+type StartPosition struct {
+	Kind     StartPositionKind
+	Sequence uint64
+	Time     time.Time
+}
+
+// Earliest returns a start position at the first available message
+func Earliest() StartPosition {
+	return StartPosition{Kind: StartEarliest}
+}
+
+// Latest returns a start position after the last available message
+func Latest() StartPosition {
+	return StartPosition{Kind: StartLatest}
+}
+
+// FromSequence returns a start position at the given sequence
+func FromSequence(seq uint64) StartPosition {
+	return StartPosition{Kind: StartSequence, Sequence: seq}
+}
+
+// FromTime returns a start position at the first message published at or after t
+func FromTime(t time.Time) StartPosition {
+	return StartPosition{Kind: StartTime, Time: t}
+}
+
+// IsDefault reports whether the position leaves the start to the server
+func (p StartPosition) IsDefault() bool {
+	return p.Kind == StartDefault
+}
+
+// Skip reports whether a message lies before a time-based start position
+func (p StartPosition) Skip(msg *ReceivedMessage) bool {
+	return p.Kind == StartTime && msg.Timestamp.Before(p.Time)
+}
+
+// String returns a human readable representation of the position
+func (p StartPosition) String() string {
+	switch p.Kind {
+	case StartEarliest:
+		return "earliest"
+	case StartLatest:
+		return "latest"
+	case StartSequence:
+		return fmt.Sprintf("sequence=%d", p.Sequence)
+	case StartTime:
+		return fmt.Sprintf("time=%s", p.Time.Format(time.RFC3339))
+	default:
+		return "default"
+	}
+}
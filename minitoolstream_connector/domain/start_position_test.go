@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartPosition(t *testing.T) {
+	t.Run("constructors", func(t *testing.T) {
+		now := time.Now()
+		tests := []struct {
+			name string
+			pos  StartPosition
+			kind StartPositionKind
+			str  string
+		}{
+			{"default", StartPosition{}, StartDefault, "default"},
+			{"earliest", Earliest(), StartEarliest, "earliest"},
+			{"latest", Latest(), StartLatest, "latest"},
+			{"sequence", FromSequence(42), StartSequence, "sequence=42"},
+			{"time", FromTime(now), StartTime, "time=" + now.Format(time.RFC3339)},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if tt.pos.Kind != tt.kind {
+					t.Errorf("expected kind %d, got %d", tt.kind, tt.pos.Kind)
+				}
+				if tt.pos.String() != tt.str {
+					t.Errorf("expected %q, got %q", tt.str, tt.pos.String())
+				}
+			})
+		}
+	})
+
+	t.Run("is default", func(t *testing.T) {
+		if !(StartPosition{}).IsDefault() {
+			t.Error("expected zero value to be default")
+		}
+		if Earliest().IsDefault() {
+			t.Error("expected earliest not to be default")
+		}
+	})
+
+	t.Run("skip messages before time", func(t *testing.T) {
+		start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		pos := FromTime(start)
+
+		if !pos.Skip(&ReceivedMessage{Timestamp: start.Add(-time.Second)}) {
+			t.Error("expected older message to be skipped")
+		}
+		if pos.Skip(&ReceivedMessage{Timestamp: start}) {
+			t.Error("expected message at start time to be kept")
+		}
+		if Earliest().Skip(&ReceivedMessage{}) {
+			t.Error("expected non-time positions to keep all messages")
+		}
+	})
+}
@@ -0,0 +1,143 @@
+// Package domaintest provides conformance tests for domain.IngressClient and
+// domain.EgressClient implementations. Every backend (the gRPC clients,
+// JetStream, Pub/Sub, the local broker) must pass them, so code built on the
+// connector behaves the same whichever backend it runs against:
+//
+//	func TestBroker_Contract(t *testing.T) {
+//		domaintest.RunIngressClientTests(t, func(t *testing.T) domain.IngressClient {
+//			return newBroker(t)
+//		})
+//		domaintest.RunEgressClientTests(t, func(t *testing.T) domaintest.Clients {
+//			b := newBroker(t)
+//			return domaintest.Clients{Ingress: b, Egress: b}
+//		})
+//	}
+//
+// The suites only rely on the documented contract: sequences increase per
+// subject but need not start at 1, notifications may be coalesced, and
+// published headers arrive unchanged although backends may add their own.
+package domaintest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// waitTimeout bounds how long a suite waits for asynchronous deliveries
+const waitTimeout = 5 * time.Second
+
+// Clients is a pair of clients connected to the same backend
+type Clients struct {
+	Ingress domain.IngressClient
+	Egress  domain.EgressClient
+}
+
+// Factory creates clients for a fresh, empty backend. It is called once per
+// test; release resources with t.Cleanup. The suites close the clients
+// themselves only in the tests that exercise Close.
+type Factory func(t *testing.T) Clients
+
+// publish publishes a message and fails the test on error
+func publish(t *testing.T, client domain.IngressClient, msg *domain.PublishMessage) *domain.PublishResult {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+
+	result, err := client.Publish(ctx, msg)
+	if err != nil {
+		t.Fatalf("Publish(%s) failed: %v", msg.Subject, err)
+	}
+	if result == nil {
+		t.Fatalf("Publish(%s) returned no result", msg.Subject)
+	}
+	return result
+}
+
+// publishN publishes n messages with data "<subject>-<i>" and returns their sequences
+func publishN(t *testing.T, client domain.IngressClient, subject string, n int) []uint64 {
+	t.Helper()
+	sequences := make([]uint64, n)
+	for i := range sequences {
+		sequences[i] = publish(t, client, &domain.PublishMessage{
+			Subject: subject,
+			Data:    []byte(fmt.Sprintf("%s-%d", subject, i)),
+		}).Sequence
+	}
+	return sequences
+}
+
+// fetch fetches one batch and returns all its messages
+func fetch(t *testing.T, client domain.EgressClient, config *domain.SubscriptionConfig) []*domain.ReceivedMessage {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), waitTimeout)
+	defer cancel()
+
+	stream, err := client.Fetch(ctx, config)
+	if err != nil {
+		t.Fatalf("Fetch(%s) failed: %v", config.Subject, err)
+	}
+
+	var messages []*domain.ReceivedMessage
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return messages
+		}
+		if err != nil {
+			t.Fatalf("Fetch(%s) stream failed: %v", config.Subject, err)
+		}
+		messages = append(messages, msg)
+	}
+}
+
+// sequencesOf returns the sequences of messages
+func sequencesOf(messages []*domain.ReceivedMessage) []uint64 {
+	sequences := make([]uint64, len(messages))
+	for i, msg := range messages {
+		sequences[i] = msg.Sequence
+	}
+	return sequences
+}
+
+// recvResult is the outcome of one Recv call
+type recvResult struct {
+	notification *domain.Notification
+	err          error
+}
+
+// recvAsync calls Recv in the background so callers can bound the wait
+func recvAsync(stream domain.NotificationStream) <-chan recvResult {
+	ch := make(chan recvResult, 1)
+	go func() {
+		n, err := stream.Recv()
+		ch <- recvResult{n, err}
+	}()
+	return ch
+}
+
+// awaitNotification receives notifications until one reaches sequence
+func awaitNotification(t *testing.T, stream domain.NotificationStream, subject string, sequence uint64) {
+	t.Helper()
+	deadline := time.After(waitTimeout)
+	for {
+		select {
+		case r := <-recvAsync(stream):
+			if r.err != nil {
+				t.Fatalf("notification stream for %s failed: %v", subject, r.err)
+			}
+			if r.notification.Subject != subject {
+				t.Fatalf("expected a notification for %s, got %s", subject, r.notification.Subject)
+			}
+			if r.notification.Sequence >= sequence {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("no notification for %s up to sequence %d within %v", subject, sequence, waitTimeout)
+		}
+	}
+}
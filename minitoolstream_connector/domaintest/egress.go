@@ -0,0 +1,232 @@
+package domaintest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// RunEgressClientTests runs the EgressClient conformance tests. The ingress
+// client returned by factory is used to publish the messages under test.
+func RunEgressClientTests(t *testing.T, factory Factory) {
+	t.Run("GetLastSequence", func(t *testing.T) {
+		c := factory(t)
+		ctx := context.Background()
+
+		last, err := c.Egress.GetLastSequence(ctx, "contract.unknown")
+		if err != nil {
+			t.Fatalf("GetLastSequence failed for an unknown subject: %v", err)
+		}
+		if last != 0 {
+			t.Errorf("expected 0 for an unknown subject, got %d", last)
+		}
+
+		sequences := publishN(t, c.Ingress, "contract.orders", 3)
+		last, err = c.Egress.GetLastSequence(ctx, "contract.orders")
+		if err != nil {
+			t.Fatalf("GetLastSequence failed: %v", err)
+		}
+		if want := sequences[len(sequences)-1]; last != want {
+			t.Errorf("expected last sequence %d, got %d", want, last)
+		}
+	})
+
+	t.Run("FetchReturnsPublishedMessages", func(t *testing.T) {
+		c := factory(t)
+		msg := &domain.PublishMessage{
+			Subject: "contract.orders",
+			Data:    []byte(`{"id":1}`),
+			Headers: map[string]string{"content-type": "application/json", "trace-id": "abc"},
+		}
+		before := time.Now().Add(-time.Minute)
+		result := publish(t, c.Ingress, msg)
+
+		messages := fetch(t, c.Egress, &domain.SubscriptionConfig{Subject: "contract.orders", DurableName: "contract", BatchSize: 10})
+		if len(messages) != 1 {
+			t.Fatalf("expected 1 message, got %d", len(messages))
+		}
+		got := messages[0]
+		if got.Subject != msg.Subject || got.Sequence != result.Sequence || !bytes.Equal(got.Data, msg.Data) {
+			t.Errorf("expected %s #%d %q, got %s #%d %q", msg.Subject, result.Sequence, msg.Data, got.Subject, got.Sequence, got.Data)
+		}
+		for k, v := range msg.Headers {
+			if got.Headers[k] != v {
+				t.Errorf("expected header %s=%q, got %q", k, v, got.Headers[k])
+			}
+		}
+		if got.Timestamp.Before(before) {
+			t.Errorf("expected a publish timestamp, got %v", got.Timestamp)
+		}
+	})
+
+	t.Run("FetchSendsDedupKey", func(t *testing.T) {
+		c := factory(t)
+		publish(t, c.Ingress, &domain.PublishMessage{Subject: "contract.orders", DedupKey: "order-1"})
+
+		messages := fetch(t, c.Egress, &domain.SubscriptionConfig{Subject: "contract.orders", DurableName: "contract", BatchSize: 10})
+		if len(messages) != 1 || messages[0].Headers[domain.HeaderIdempotencyKey] != "order-1" {
+			t.Errorf("expected the dedup key as %s header, got %v", domain.HeaderIdempotencyKey, messages)
+		}
+	})
+
+	t.Run("FetchHonorsBatchSizeAndAdvances", func(t *testing.T) {
+		c := factory(t)
+		sequences := publishN(t, c.Ingress, "contract.orders", 5)
+		config := &domain.SubscriptionConfig{Subject: "contract.orders", DurableName: "contract", BatchSize: 2}
+
+		var got []uint64
+		for i := 0; i < 3; i++ {
+			batch := fetch(t, c.Egress, config)
+			if len(batch) > 2 {
+				t.Fatalf("expected at most 2 messages per batch, got %d", len(batch))
+			}
+			got = append(got, sequencesOf(batch)...)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(sequences) {
+			t.Errorf("expected %v in order across batches, got %v", sequences, got)
+		}
+
+		if rest := fetch(t, c.Egress, config); len(rest) != 0 {
+			t.Errorf("expected nothing left, got %v", sequencesOf(rest))
+		}
+	})
+
+	t.Run("FetchKeepsDurablesApart", func(t *testing.T) {
+		c := factory(t)
+		sequences := publishN(t, c.Ingress, "contract.orders", 2)
+
+		first := fetch(t, c.Egress, &domain.SubscriptionConfig{Subject: "contract.orders", DurableName: "first", BatchSize: 10})
+		second := fetch(t, c.Egress, &domain.SubscriptionConfig{Subject: "contract.orders", DurableName: "second", BatchSize: 10})
+		if fmt.Sprint(sequencesOf(first)) != fmt.Sprint(sequences) || fmt.Sprint(sequencesOf(second)) != fmt.Sprint(sequences) {
+			t.Errorf("expected both durables to receive %v, got %v and %v", sequences, sequencesOf(first), sequencesOf(second))
+		}
+	})
+
+	t.Run("FetchKeepsSubjectsApart", func(t *testing.T) {
+		c := factory(t)
+		publishN(t, c.Ingress, "contract.a", 2)
+		publishN(t, c.Ingress, "contract.b", 1)
+
+		messages := fetch(t, c.Egress, &domain.SubscriptionConfig{Subject: "contract.b", DurableName: "contract", BatchSize: 10})
+		if len(messages) != 1 || messages[0].Subject != "contract.b" {
+			t.Errorf("expected only the contract.b message, got %v", messages)
+		}
+	})
+
+	t.Run("FetchEmptySubject", func(t *testing.T) {
+		c := factory(t)
+		if messages := fetch(t, c.Egress, &domain.SubscriptionConfig{Subject: "contract.none", DurableName: "contract", BatchSize: 10}); len(messages) != 0 {
+			t.Errorf("expected no messages, got %d", len(messages))
+		}
+	})
+
+	t.Run("SubscribeNotifiesAboutNewMessages", func(t *testing.T) {
+		c := factory(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stream, err := c.Egress.Subscribe(ctx, &domain.SubscriptionConfig{Subject: "contract.orders", DurableName: "contract"})
+		if err != nil {
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+
+		sequences := publishN(t, c.Ingress, "contract.orders", 2)
+		awaitNotification(t, stream, "contract.orders", sequences[1])
+	})
+
+	t.Run("SubscribeFromSequence", func(t *testing.T) {
+		c := factory(t)
+		sequences := publishN(t, c.Ingress, "contract.orders", 4)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		config := &domain.SubscriptionConfig{
+			Subject:       "contract.orders",
+			DurableName:   "contract",
+			StartPosition: domain.FromSequence(sequences[2]),
+			BatchSize:     10,
+		}
+		stream, err := c.Egress.Subscribe(ctx, config)
+		if err != nil {
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+		awaitNotification(t, stream, "contract.orders", sequences[3])
+
+		got := sequencesOf(fetch(t, c.Egress, config))
+		if fmt.Sprint(got) != fmt.Sprint(sequences[2:]) {
+			t.Errorf("expected %v from the start sequence, got %v", sequences[2:], got)
+		}
+	})
+
+	t.Run("SubscribeFromLatest", func(t *testing.T) {
+		c := factory(t)
+		publishN(t, c.Ingress, "contract.orders", 2)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		config := &domain.SubscriptionConfig{
+			Subject:       "contract.orders",
+			DurableName:   "contract",
+			StartPosition: domain.Latest(),
+			BatchSize:     10,
+		}
+		stream, err := c.Egress.Subscribe(ctx, config)
+		if err != nil {
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+
+		latest := publishN(t, c.Ingress, "contract.orders", 1)
+		awaitNotification(t, stream, "contract.orders", latest[0])
+
+		got := sequencesOf(fetch(t, c.Egress, config))
+		if fmt.Sprint(got) != fmt.Sprint(latest) {
+			t.Errorf("expected only %v published after subscribing, got %v", latest, got)
+		}
+	})
+
+	t.Run("SubscribeEndsWhenCancelled", func(t *testing.T) {
+		c := factory(t)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		stream, err := c.Egress.Subscribe(ctx, &domain.SubscriptionConfig{Subject: "contract.orders", DurableName: "contract"})
+		if err != nil {
+			cancel()
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+		recv := recvAsync(stream)
+		cancel()
+
+		select {
+		case r := <-recv:
+			if r.err == nil {
+				t.Errorf("expected an error after cancellation, got %+v", r.notification)
+			}
+		case <-time.After(waitTimeout):
+			t.Fatal("Recv did not return after the context was cancelled")
+		}
+	})
+
+	t.Run("RejectsEmptySubject", func(t *testing.T) {
+		c := factory(t)
+		ctx := context.Background()
+		if _, err := c.Egress.Subscribe(ctx, &domain.SubscriptionConfig{DurableName: "contract"}); err == nil {
+			t.Error("expected Subscribe to reject an empty subject")
+		}
+		if _, err := c.Egress.Fetch(ctx, &domain.SubscriptionConfig{DurableName: "contract"}); err == nil {
+			t.Error("expected Fetch to reject an empty subject")
+		}
+		if _, err := c.Egress.GetLastSequence(ctx, ""); err == nil {
+			t.Error("expected GetLastSequence to reject an empty subject")
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		if err := factory(t).Egress.Close(); err != nil {
+			t.Errorf("Close failed: %v", err)
+		}
+	})
+}
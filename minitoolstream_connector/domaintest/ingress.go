@@ -0,0 +1,57 @@
+package domaintest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// RunIngressClientTests runs the IngressClient conformance tests. factory
+// returns a client for a fresh, empty backend and is called once per test.
+func RunIngressClientTests(t *testing.T, factory func(t *testing.T) domain.IngressClient) {
+	t.Run("PublishAssignsIncreasingSequences", func(t *testing.T) {
+		client := factory(t)
+		sequences := publishN(t, client, "contract.orders", 3)
+		for i := 1; i < len(sequences); i++ {
+			if sequences[i] <= sequences[i-1] {
+				t.Fatalf("expected increasing sequences, got %v", sequences)
+			}
+		}
+		if sequences[0] == 0 {
+			t.Errorf("expected a non-zero sequence, got %v", sequences)
+		}
+	})
+
+	t.Run("PublishKeepsSubjectsApart", func(t *testing.T) {
+		client := factory(t)
+		a := publishN(t, client, "contract.a", 2)
+		b := publishN(t, client, "contract.b", 1)
+		more := publishN(t, client, "contract.a", 1)
+		if more[0] <= a[1] {
+			t.Errorf("expected contract.a to keep increasing after publishing to contract.b, got %v then %v (contract.b: %v)", a, more, b)
+		}
+	})
+
+	t.Run("PublishAcceptsEmptyData", func(t *testing.T) {
+		publish(t, factory(t), &domain.PublishMessage{Subject: "contract.empty"})
+	})
+
+	t.Run("PublishRejectsEmptySubject", func(t *testing.T) {
+		if _, err := factory(t).Publish(context.Background(), &domain.PublishMessage{Data: []byte("x")}); err == nil {
+			t.Error("expected an error for an empty subject")
+		}
+	})
+
+	t.Run("PublishRejectsNilMessage", func(t *testing.T) {
+		if _, err := factory(t).Publish(context.Background(), nil); err == nil {
+			t.Error("expected an error for a nil message")
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		if err := factory(t).Close(); err != nil {
+			t.Errorf("Close failed: %v", err)
+		}
+	})
+}
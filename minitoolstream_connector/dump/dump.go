@@ -0,0 +1,57 @@
+// Package dump exports the messages of a subject for backups and offline
+// analysis, and imports dumps into another environment to seed it.
+//
+// A dump is either NDJSON, one Record per line with the payload inline, or a
+// directory holding one payload file per message and an index.json with the
+// metadata of every message (see Index).
+package dump
+
+import (
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// IndexFile is the name of the metadata index of a directory dump
+const IndexFile = "index.json"
+
+// Formats of a dump
+const (
+	FormatNDJSON = "ndjson"
+	FormatDir    = "dir"
+)
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// Record is an exported message. NDJSON dumps carry the payload in Data;
+// directory dumps store it in File, relative to the dump directory.
+type Record struct {
+	Subject   string            `json:"subject"`
+	Sequence  uint64            `json:"sequence"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Data      []byte            `json:"data,omitempty"`
+	File      string            `json:"file,omitempty"`
+	Size      int               `json:"size"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// Summary describes the messages written by an export or republished by an import
+type Summary struct {
+	// Subject is the exported subject, or the subject of the first imported message
+	Subject string `json:"subject"`
+	// FirstSequence and LastSequence are the dumped sequences of the first and
+	// last message (0 when there were none)
+	FirstSequence uint64 `json:"first_sequence"`
+	LastSequence  uint64 `json:"last_sequence"`
+	Count         int    `json:"count"`
+	// Bytes is the total payload size
+	Bytes int64 `json:"bytes"`
+}
+
+// Index is the metadata index of a directory dump
+type Index struct {
+	Summary
+	ExportedAt time.Time `json:"exported_at"`
+	Messages   []Record  `json:"messages"`
+}
@@ -0,0 +1,150 @@
+package dump
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/localbroker"
+)
+
+type discardLogger struct{}
+
+func (discardLogger) Printf(string, ...interface{}) {}
+
+// newBroker returns a broker holding n messages on subject "orders"
+func newBroker(t *testing.T, n int) *localbroker.Broker {
+	t.Helper()
+	b, err := localbroker.New(&localbroker.Config{Dir: t.TempDir(), PollInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	for i := 1; i <= n; i++ {
+		msg := &domain.PublishMessage{
+			Subject: "orders",
+			Data:    []byte(fmt.Sprintf("order-%d", i)),
+			Headers: map[string]string{"index": fmt.Sprint(i)},
+		}
+		if _, err := b.Publish(context.Background(), msg); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	return b
+}
+
+func TestNewExporter(t *testing.T) {
+	b := newBroker(t, 0)
+	for _, config := range []*ExporterConfig{
+		nil,
+		{Subject: "orders"},
+		{Client: b},
+		{Client: b, Subject: "orders", FromSequence: 5, ToSequence: 4},
+	} {
+		if _, err := NewExporter(config); err == nil {
+			t.Errorf("expected error for %+v", config)
+		}
+	}
+}
+
+func TestExporter_WriteNDJSON(t *testing.T) {
+	b := newBroker(t, 7)
+	exporter, err := NewExporter(&ExporterConfig{Client: b, Subject: "orders", FromSequence: 2, ToSequence: 6, BatchSize: 2, Logger: discardLogger{}})
+	if err != nil {
+		t.Fatalf("NewExporter failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	summary, err := exporter.WriteNDJSON(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("WriteNDJSON failed: %v", err)
+	}
+	if summary.Count != 5 || summary.FirstSequence != 2 || summary.LastSequence != 6 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	var records []Record
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 5 {
+		t.Fatalf("expected 5 records, got %d", len(records))
+	}
+	for i, r := range records {
+		seq := uint64(i + 2)
+		if r.Sequence != seq || string(r.Data) != fmt.Sprintf("order-%d", seq) || r.Headers["index"] != fmt.Sprint(seq) {
+			t.Errorf("unexpected record %+v", r)
+		}
+	}
+}
+
+func TestExporter_DefaultRange(t *testing.T) {
+	b := newBroker(t, 3)
+	exporter, _ := NewExporter(&ExporterConfig{Client: b, Subject: "orders", Logger: discardLogger{}})
+
+	var buf bytes.Buffer
+	summary, err := exporter.WriteNDJSON(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("WriteNDJSON failed: %v", err)
+	}
+	if summary.Count != 3 || summary.LastSequence != 3 {
+		t.Errorf("expected the 3 existing messages, got %+v", summary)
+	}
+
+	empty, _ := NewExporter(&ExporterConfig{Client: newBroker(t, 0), Subject: "orders", Logger: discardLogger{}})
+	summary, err = empty.WriteNDJSON(context.Background(), &buf)
+	if err != nil || summary.Count != 0 {
+		t.Errorf("expected an empty export, got %+v (%v)", summary, err)
+	}
+}
+
+func TestExporter_WriteDir(t *testing.T) {
+	b := newBroker(t, 3)
+	exporter, _ := NewExporter(&ExporterConfig{Client: b, Subject: "orders", Logger: discardLogger{}})
+
+	dir := filepath.Join(t.TempDir(), "dump")
+	summary, err := exporter.WriteDir(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("WriteDir failed: %v", err)
+	}
+	if summary.Count != 3 {
+		t.Errorf("expected 3 messages, got %+v", summary)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		t.Fatalf("failed to read index: %v", err)
+	}
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		t.Fatalf("invalid index: %v", err)
+	}
+	if index.Count != 3 || len(index.Messages) != 3 || index.ExportedAt.IsZero() {
+		t.Fatalf("unexpected index %+v", index)
+	}
+	for _, r := range index.Messages {
+		if r.Data != nil {
+			t.Errorf("expected the payload of %d to be stored in a file", r.Sequence)
+		}
+		payload, err := os.ReadFile(filepath.Join(dir, r.File))
+		if err != nil || string(payload) != fmt.Sprintf("order-%d", r.Sequence) || r.Size != len(payload) {
+			t.Errorf("unexpected payload file %s: %q (%v)", r.File, payload, err)
+		}
+	}
+
+	again, _ := NewExporter(&ExporterConfig{Client: b, Subject: "orders", Logger: discardLogger{}})
+	if _, err := again.WriteDir(context.Background(), dir); err == nil {
+		t.Error("expected error when the directory already holds a dump")
+	}
+}
@@ -0,0 +1,168 @@
+package dump
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// ImporterConfig represents importer configuration
+type ImporterConfig struct {
+	// Publisher republishes the messages, applying its retry and rate settings
+	Publisher domain.Publisher
+	// Subject, when set, republishes every message to this subject
+	Subject string
+	// Subjects maps dumped subjects to the subjects they are republished to;
+	// subjects not listed keep their name
+	Subjects map[string]string
+	Logger   Logger
+}
+
+// Importer republishes the messages of a dump, preserving their headers
+type Importer struct {
+	publisher domain.Publisher
+	subject   string
+	subjects  map[string]string
+	logger    Logger
+}
+
+// NewImporter creates a new importer
+func NewImporter(config *ImporterConfig) (*Importer, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Publisher == nil {
+		return nil, fmt.Errorf("publisher cannot be nil")
+	}
+
+	for from, to := range config.Subjects {
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("invalid subject mapping %q -> %q", from, to)
+		}
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	return &Importer{
+		publisher: config.Publisher,
+		subject:   config.Subject,
+		subjects:  config.Subjects,
+		logger:    logger,
+	}, nil
+}
+
+// ReadNDJSON republishes the records of an NDJSON dump in order
+func (i *Importer) ReadNDJSON(ctx context.Context, r io.Reader) (*Summary, error) {
+	dec := json.NewDecoder(r)
+	return i.run(ctx, func(publish func(Record) error) error {
+		for line := 1; ; line++ {
+			var record Record
+			if err := dec.Decode(&record); err == io.EOF {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("invalid record %d: %w", line, err)
+			}
+			if err := publish(record); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// ReadDir republishes the messages of a directory dump in index order
+func (i *Importer) ReadDir(ctx context.Context, dir string) (*Summary, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid index: %w", err)
+	}
+
+	return i.run(ctx, func(publish func(Record) error) error {
+		for _, record := range index.Messages {
+			if !filepath.IsLocal(record.File) {
+				return fmt.Errorf("message %d: payload file %q is outside the dump", record.Sequence, record.File)
+			}
+			payload, err := os.ReadFile(filepath.Join(dir, record.File))
+			if err != nil {
+				return fmt.Errorf("message %d: failed to read payload: %w", record.Sequence, err)
+			}
+			if len(payload) != record.Size {
+				return fmt.Errorf("message %d: payload has %d bytes, index says %d", record.Sequence, len(payload), record.Size)
+			}
+			record.Data = payload
+			if err := publish(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// run publishes the records produced by read and summarizes them
+func (i *Importer) run(ctx context.Context, read func(publish func(Record) error) error) (*Summary, error) {
+	summary := &Summary{}
+	start := time.Now()
+
+	err := read(func(record Record) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		subject := i.targetSubject(record.Subject)
+		if subject == "" {
+			return fmt.Errorf("message %d has no subject", record.Sequence)
+		}
+		msg := &domain.PublishMessage{
+			Subject: subject,
+			Data:    record.Data,
+			Headers: maps.Clone(record.Headers),
+		}
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return msg, nil
+		})
+		if err := i.publisher.Publish(ctx, preparer); err != nil {
+			return fmt.Errorf("failed to publish message %d to %s: %w", record.Sequence, subject, err)
+		}
+
+		if summary.Count == 0 {
+			summary.Subject = subject
+			summary.FirstSequence = record.Sequence
+		}
+		summary.LastSequence = record.Sequence
+		summary.Count++
+		summary.Bytes += int64(len(record.Data))
+		return nil
+	})
+	if err != nil {
+		return summary, err
+	}
+
+	i.logger.Printf("✓ Imported %d messages (%d bytes) in %v", summary.Count, summary.Bytes, time.Since(start).Round(time.Millisecond))
+	return summary, nil
+}
+
+// targetSubject returns the subject a dumped subject is republished to
+func (i *Importer) targetSubject(subject string) string {
+	if i.subject != "" {
+		return i.subject
+	}
+	if to, ok := i.subjects[subject]; ok {
+		return to
+	}
+	return subject
+}
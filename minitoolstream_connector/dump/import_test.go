@@ -0,0 +1,184 @@
+package dump
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/localbroker"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+)
+
+// newPublisher returns a publisher on a new broker
+func newPublisher(t *testing.T) (*publisher.SimplePublisher, *localbroker.Broker) {
+	t.Helper()
+	b := newBroker(t, 0)
+	pub, err := publisher.New(&publisher.Config{Client: b, Logger: discardLogger{}})
+	if err != nil {
+		t.Fatalf("failed to create publisher: %v", err)
+	}
+	return pub, b
+}
+
+// exportAll returns all messages of a subject on the broker
+func exportAll(t *testing.T, b *localbroker.Broker, subject string) []Record {
+	t.Helper()
+	exporter, err := NewExporter(&ExporterConfig{Client: b, Subject: subject, Logger: discardLogger{}})
+	if err != nil {
+		t.Fatalf("NewExporter failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := exporter.WriteNDJSON(context.Background(), &buf); err != nil {
+		t.Fatalf("WriteNDJSON failed: %v", err)
+	}
+
+	var records []Record
+	for dec := json.NewDecoder(&buf); dec.More(); {
+		var r Record
+		if err := dec.Decode(&r); err != nil {
+			t.Fatalf("invalid record: %v", err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// recordingPublisher records published messages before passing them on
+type recordingPublisher struct {
+	domain.Publisher
+	records *[]Record
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, preparer domain.MessagePreparer) error {
+	msg, err := preparer.Prepare(ctx)
+	if err != nil {
+		return err
+	}
+	*p.records = append(*p.records, Record{Subject: msg.Subject, Headers: msg.Headers, Data: msg.Data})
+	return nil
+}
+
+func TestNewImporter(t *testing.T) {
+	pub, _ := newPublisher(t)
+	for _, config := range []*ImporterConfig{
+		nil,
+		{},
+		{Publisher: pub, Subjects: map[string]string{"orders": ""}},
+	} {
+		if _, err := NewImporter(config); err == nil {
+			t.Errorf("expected error for %+v", config)
+		}
+	}
+}
+
+func TestImporter_RoundTrip(t *testing.T) {
+	source := newBroker(t, 3)
+	exporter, _ := NewExporter(&ExporterConfig{Client: source, Subject: "orders", Logger: discardLogger{}})
+
+	var buf bytes.Buffer
+	if _, err := exporter.WriteNDJSON(context.Background(), &buf); err != nil {
+		t.Fatalf("WriteNDJSON failed: %v", err)
+	}
+	dir := t.TempDir()
+	exporter, _ = NewExporter(&ExporterConfig{Client: source, Subject: "orders", Logger: discardLogger{}})
+	if _, err := exporter.WriteDir(context.Background(), dir); err != nil {
+		t.Fatalf("WriteDir failed: %v", err)
+	}
+
+	t.Run("ndjson", func(t *testing.T) {
+		pub, target := newPublisher(t)
+		importer, _ := NewImporter(&ImporterConfig{Publisher: pub, Logger: discardLogger{}})
+
+		summary, err := importer.ReadNDJSON(context.Background(), bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("ReadNDJSON failed: %v", err)
+		}
+		if summary.Count != 3 || summary.FirstSequence != 1 || summary.LastSequence != 3 {
+			t.Errorf("unexpected summary %+v", summary)
+		}
+		assertOrders(t, exportAll(t, target, "orders"))
+	})
+
+	t.Run("directory with subject rewrite", func(t *testing.T) {
+		pub, target := newPublisher(t)
+		importer, _ := NewImporter(&ImporterConfig{
+			Publisher: pub,
+			Subjects:  map[string]string{"orders": "staging.orders"},
+			Logger:    discardLogger{},
+		})
+
+		if _, err := importer.ReadDir(context.Background(), dir); err != nil {
+			t.Fatalf("ReadDir failed: %v", err)
+		}
+		assertOrders(t, exportAll(t, target, "staging.orders"))
+	})
+}
+
+// assertOrders checks that records hold the messages published by newBroker
+func assertOrders(t *testing.T, records []Record) {
+	t.Helper()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(records))
+	}
+	for i, r := range records {
+		seq := strconv.Itoa(i + 1)
+		if string(r.Data) != "order-"+seq || r.Headers["index"] != seq {
+			t.Errorf("unexpected message %d: %q %v", i+1, r.Data, r.Headers)
+		}
+	}
+}
+
+func TestImporter_Subject(t *testing.T) {
+	var records []Record
+	importer, _ := NewImporter(&ImporterConfig{
+		Publisher: &recordingPublisher{records: &records},
+		Subject:   "replay",
+		Subjects:  map[string]string{"a": "ignored"},
+		Logger:    discardLogger{},
+	})
+
+	dump := `{"subject":"a","sequence":1,"data":"eA==","size":1}` + "\n" + `{"subject":"b","sequence":2,"size":0}`
+	if _, err := importer.ReadNDJSON(context.Background(), strings.NewReader(dump)); err != nil {
+		t.Fatalf("ReadNDJSON failed: %v", err)
+	}
+	if len(records) != 2 || records[0].Subject != "replay" || records[1].Subject != "replay" || string(records[0].Data) != "x" {
+		t.Errorf("expected every message on replay, got %+v", records)
+	}
+}
+
+func TestImporter_InvalidDumps(t *testing.T) {
+	var records []Record
+	importer, _ := NewImporter(&ImporterConfig{Publisher: &recordingPublisher{records: &records}, Logger: discardLogger{}})
+	ctx := context.Background()
+
+	if _, err := importer.ReadNDJSON(ctx, strings.NewReader(`{"subject":"a","sequence":1}`+"\nnot json")); err == nil {
+		t.Error("expected error for a malformed line")
+	}
+	if _, err := importer.ReadNDJSON(ctx, strings.NewReader(`{"sequence":1}`)); err == nil {
+		t.Error("expected error for a record without subject")
+	}
+
+	dir := t.TempDir()
+	if _, err := importer.ReadDir(ctx, dir); err == nil {
+		t.Error("expected error without an index")
+	}
+
+	index := `{"messages":[{"subject":"a","sequence":1,"file":"../secret","size":1}]}`
+	os.WriteFile(filepath.Join(dir, IndexFile), []byte(index), 0644)
+	if _, err := importer.ReadDir(ctx, dir); err == nil || !strings.Contains(err.Error(), "outside the dump") {
+		t.Errorf("expected error for a file outside the dump, got %v", err)
+	}
+
+	index = `{"messages":[{"subject":"a","sequence":1,"file":"1.bin","size":5}]}`
+	os.WriteFile(filepath.Join(dir, IndexFile), []byte(index), 0644)
+	os.WriteFile(filepath.Join(dir, "1.bin"), []byte("abc"), 0644)
+	if _, err := importer.ReadDir(ctx, dir); err == nil || !strings.Contains(err.Error(), "index says") {
+		t.Errorf("expected error for a truncated payload, got %v", err)
+	}
+}
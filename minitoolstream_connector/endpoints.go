@@ -0,0 +1,105 @@
+package minitoolstream_connector
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/endpoints"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+	subscriberUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/subscriber"
+)
+
+// defaultLivenessWindow is how long a subscriber's stream may be down before
+// its /healthz endpoint reports it as not live
+const defaultLivenessWindow = 60 * time.Second
+
+// endpointSettings configures the embedded health and metrics endpoints
+type endpointSettings struct {
+	httpAddr string
+}
+
+// validate checks the endpoint address
+func (e *endpointSettings) validate() error {
+	if e.httpAddr == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(e.httpAddr); err != nil {
+		return fmt.Errorf("invalid HTTP endpoint address %q: %w", e.httpAddr, err)
+	}
+	return nil
+}
+
+// newEndpoints creates the endpoint server if an address is set, or returns
+// nil. The callbacks are only called once the server is started; live may be
+// nil to always report the component as live.
+func (e *endpointSettings) newEndpoints(live, ready func() error, metrics func() []endpoints.Metric, logger endpoints.Logger) (*endpoints.Server, error) {
+	if e.httpAddr == "" {
+		return nil, nil
+	}
+	return endpoints.New(&endpoints.Config{Live: live, Ready: ready, Metrics: metrics, Logger: logger})
+}
+
+// publisherMetrics converts publisher stats to metrics
+func publisherMetrics(stats publisher.Stats) []endpoints.Metric {
+	return []endpoints.Metric{
+		{Name: "minitoolstream_publisher_published_total", Help: "Messages acknowledged by the server.", Type: endpoints.Counter, Value: float64(stats.Published)},
+		{Name: "minitoolstream_publisher_failed_total", Help: "Messages that could not be published.", Type: endpoints.Counter, Value: float64(stats.Failed)},
+		{Name: "minitoolstream_publisher_retries_total", Help: "Publish attempts retried after client errors.", Type: endpoints.Counter, Value: float64(stats.Retried)},
+	}
+}
+
+// subscriberMetrics converts subscriber stats to metrics
+func subscriberMetrics(stats subscriberUsecase.Stats) []endpoints.Metric {
+	return []endpoints.Metric{
+		{Name: "minitoolstream_subscriber_expired_total", Help: "Fetched messages skipped because they expired.", Type: endpoints.Counter, Value: float64(stats.Expired)},
+		{Name: "minitoolstream_subscriber_redelivered_total", Help: "Delivery attempts made after handler failures.", Type: endpoints.Counter, Value: float64(stats.Redelivered)},
+		{Name: "minitoolstream_subscriber_failed_total", Help: "Messages handed to the failure sink.", Type: endpoints.Counter, Value: float64(stats.Failed)},
+		{Name: "minitoolstream_subscriber_duplicates_total", Help: "Fetched messages skipped by the dedup window.", Type: endpoints.Counter, Value: float64(stats.Duplicates)},
+		{Name: "minitoolstream_subscriber_backoffs_total", Help: "Times a handler paused its subject.", Type: endpoints.Counter, Value: float64(stats.Backoffs)},
+		{Name: "minitoolstream_subscriber_dropped_notifications_total", Help: "Notifications dropped because the subject's queue was full.", Type: endpoints.Counter, Value: float64(stats.DroppedNotifications)},
+		{Name: "minitoolstream_subscriber_spilled_notifications_total", Help: "Notifications written to disk because the subject's queue was full.", Type: endpoints.Counter, Value: float64(stats.SpilledNotifications)},
+		{Name: "minitoolstream_subscriber_stream_gaps_total", Help: "Notification streams that ended while the subscriber was running.", Type: endpoints.Counter, Value: float64(stats.StreamGaps)},
+		{Name: "minitoolstream_subscriber_compensating_fetches_total", Help: "Fetches made to catch up after dropped or missed notifications.", Type: endpoints.Counter, Value: float64(stats.CompensatingFetches)},
+		{Name: "minitoolstream_subscriber_in_flight", Help: "Messages fetched but not yet handled.", Type: endpoints.Gauge, Value: float64(stats.InFlight)},
+	}
+}
+
+// closingIngressClient closes an extra resource along with the client
+type closingIngressClient struct {
+	domain.IngressClient
+	closer io.Closer
+}
+
+func (c *closingIngressClient) Close() error {
+	return errors.Join(c.IngressClient.Close(), c.closer.Close())
+}
+
+// closingEgressClient closes an extra resource along with the client
+type closingEgressClient struct {
+	domain.EgressClient
+	closer io.Closer
+}
+
+func (c *closingEgressClient) Close() error {
+	return errors.Join(c.EgressClient.Close(), c.closer.Close())
+}
+
+// listingClosingEgressClient is a closingEgressClient over a client that lists subjects
+type listingClosingEgressClient struct {
+	*closingEgressClient
+	domain.SubjectLister
+}
+
+// egressWithCloser returns client with closer closed along with it, keeping
+// domain.SubjectLister if client implements it
+func egressWithCloser(client domain.EgressClient, closer io.Closer) domain.EgressClient {
+	closing := &closingEgressClient{EgressClient: client, closer: closer}
+	if lister, ok := client.(domain.SubjectLister); ok {
+		return &listingClosingEgressClient{closingEgressClient: closing, SubjectLister: lister}
+	}
+	return closing
+}
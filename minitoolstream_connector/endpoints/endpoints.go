@@ -0,0 +1,205 @@
+// Package endpoints serves the HTTP endpoints deployments probe a connector on:
+//
+//	GET /healthz  liveness, 200 while the process is healthy
+//	GET /readyz   readiness, 200 while the publisher or subscriber can do work
+//	GET /metrics  counters and gauges in the Prometheus text format
+//
+// The endpoints are driven by callbacks, so any component can be exposed; the
+// builders wire them to a publisher's or subscriber's internal state.
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// MetricType is the Prometheus type of a metric
+type MetricType string
+
+// Metric types
+const (
+	Counter MetricType = "counter"
+	Gauge   MetricType = "gauge"
+)
+
+// metricNamePattern lists the valid Prometheus metric names
+var metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// Metric is the current value of one metric
+type Metric struct {
+	Name  string
+	Help  string
+	Type  MetricType
+	Value float64
+}
+
+// Config represents endpoint server configuration
+type Config struct {
+	// Live returns an error when the component is unhealthy (default always live)
+	Live func() error
+	// Ready returns an error when the component cannot do work (default always ready)
+	Ready func() error
+	// Metrics returns the current metric values; /metrics is empty when nil
+	Metrics func() []Metric
+	Logger  Logger
+}
+
+// Server serves the health and metrics endpoints
+type Server struct {
+	live    func() error
+	ready   func() error
+	metrics func() []Metric
+	logger  Logger
+	mux     *http.ServeMux
+
+	mu  sync.Mutex
+	srv *http.Server
+	ln  net.Listener
+}
+
+// New creates a new endpoint server
+func New(config *Config) (*Server, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	s := &Server{
+		live:    config.Live,
+		ready:   config.Ready,
+		metrics: config.Metrics,
+		logger:  logger,
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /healthz", s.handleProbe(s.live))
+	s.mux.HandleFunc("GET /readyz", s.handleProbe(s.ready))
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Start listens on addr and serves the endpoints in the background until
+// Close is called. Listening errors, e.g. a port in use, are returned.
+func (s *Server) Start(addr string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.srv != nil {
+		return fmt.Errorf("endpoint server already started")
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s.ln = ln
+	s.srv = &http.Server{Handler: s, ReadHeaderTimeout: 5 * time.Second}
+	go func(srv *http.Server) {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Printf("✗ Endpoint server failed: %v", err)
+		}
+	}(s.srv)
+
+	s.logger.Printf("✓ Serving health and metrics endpoints on %s", ln.Addr())
+	return nil
+}
+
+// Addr returns the address the server listens on, or nil before Start
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ln == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// Close shuts the server down gracefully. It is a no-op if the server was not started.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	srv := s.srv
+	s.srv, s.ln = nil, nil
+	s.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
+
+// handleProbe answers 200 when probe succeeds and 503 with the error otherwise
+func (s *Server) handleProbe(probe func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if probe != nil {
+			if err := probe(); err != nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprintln(w, err)
+				return
+			}
+		}
+		fmt.Fprintln(w, "ok")
+	}
+}
+
+// handleMetrics writes the metrics in the Prometheus text exposition format
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if s.metrics == nil {
+		return
+	}
+	if err := WriteMetrics(w, s.metrics()); err != nil {
+		s.logger.Printf("✗ Failed to write metrics: %v", err)
+	}
+}
+
+// WriteMetrics writes metrics in the Prometheus text exposition format,
+// skipping metrics with invalid names
+func WriteMetrics(w io.Writer, metrics []Metric) error {
+	for _, m := range metrics {
+		if !metricNamePattern.MatchString(m.Name) {
+			continue
+		}
+
+		metricType := m.Type
+		if metricType == "" {
+			metricType = Gauge
+		}
+		if m.Help != "" {
+			if _, err := fmt.Fprintf(w, "# HELP %s %s\n", m.Name, m.Help); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE %s %s\n%s %s\n", m.Name, metricType, m.Name, strconv.FormatFloat(m.Value, 'g', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package endpoints
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type discardLogger struct{}
+
+func (discardLogger) Printf(string, ...interface{}) {}
+
+func get(t *testing.T, h http.Handler, path string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+}
+
+func TestServer_Probes(t *testing.T) {
+	var readyErr error
+	s, _ := New(&Config{Ready: func() error { return readyErr }, Logger: discardLogger{}})
+
+	if code, body := get(t, s, "/healthz"); code != http.StatusOK || body != "ok\n" {
+		t.Errorf("expected healthz ok, got %d %q", code, body)
+	}
+	if code, _ := get(t, s, "/readyz"); code != http.StatusOK {
+		t.Errorf("expected readyz 200, got %d", code)
+	}
+
+	readyErr = errors.New("subscriber stopped")
+	code, body := get(t, s, "/readyz")
+	if code != http.StatusServiceUnavailable || !strings.Contains(body, "subscriber stopped") {
+		t.Errorf("expected readyz 503 with the error, got %d %q", code, body)
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	s, _ := New(&Config{
+		Metrics: func() []Metric {
+			return []Metric{
+				{Name: "messages_total", Help: "Messages handled", Type: Counter, Value: 3},
+				{Name: "in_flight", Value: 1.5},
+				{Name: "bad name", Value: 1},
+			}
+		},
+		Logger: discardLogger{},
+	})
+
+	code, body := get(t, s, "/metrics")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	want := "# HELP messages_total Messages handled\n# TYPE messages_total counter\nmessages_total 3\n" +
+		"# TYPE in_flight gauge\nin_flight 1.5\n"
+	if body != want {
+		t.Errorf("unexpected metrics:\n%s\nwant:\n%s", body, want)
+	}
+}
+
+func TestServer_StartClose(t *testing.T) {
+	s, _ := New(&Config{Logger: discardLogger{}})
+	if err := s.Close(); err != nil {
+		t.Errorf("expected Close before Start to be a no-op, got %v", err)
+	}
+
+	if err := s.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := s.Start("127.0.0.1:0"); err == nil {
+		t.Error("expected error when started twice")
+	}
+
+	resp, err := http.Get("http://" + s.Addr().String() + "/healthz")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok\n" {
+		t.Errorf("expected ok, got %d %q", resp.StatusCode, body)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if s.Addr() != nil {
+		t.Error("expected no address after Close")
+	}
+}
@@ -0,0 +1,118 @@
+package minitoolstream_connector
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// freeAddr returns a local address nothing listens on
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func httpGet(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestSubscriberBuilder_WithHTTPEndpoints(t *testing.T) {
+	if err := NewSubscriberBuilder("localhost:9091").WithHTTPEndpoints("8081").Validate(); err == nil {
+		t.Error("expected error for an address without a port")
+	}
+
+	addr := freeAddr(t)
+	sub, err := NewSubscriberBuilder("localhost:9091").
+		WithHTTPEndpoints(addr).
+		WithLogger(&testSubLogger{}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if code, body := httpGet(t, "http://"+addr+"/healthz"); code != http.StatusOK {
+		t.Errorf("expected healthz 200, got %d %q", code, body)
+	}
+	if code, body := httpGet(t, "http://"+addr+"/readyz"); code != http.StatusServiceUnavailable || !strings.Contains(body, "not started") {
+		t.Errorf("expected readyz 503 before Start, got %d %q", code, body)
+	}
+	if _, body := httpGet(t, "http://"+addr+"/metrics"); !strings.Contains(body, "minitoolstream_subscriber_in_flight 0") {
+		t.Errorf("expected subscriber metrics, got %q", body)
+	}
+
+	sub.Stop()
+	if _, err := http.Get("http://" + addr + "/healthz"); err == nil {
+		t.Error("expected the endpoints to stop with the subscriber")
+	}
+}
+
+func TestSubscriberBuilder_WithLivenessWindow(t *testing.T) {
+	if err := NewSubscriberBuilder("localhost:9091").WithLivenessWindow(-time.Second).Validate(); err == nil {
+		t.Error("expected error for a negative liveness window")
+	}
+	if err := NewSubscriberBuilder("localhost:9091").WithHTTPEndpoints(":8081").WithLivenessWindow(time.Second).Validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestPublisherBuilder_WithHTTPEndpoints(t *testing.T) {
+	addr := freeAddr(t)
+	pub, err := NewPublisherBuilder("localhost:9090").
+		WithHTTPEndpoints(addr).
+		WithLogger(&testPubLogger{}).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if code, _ := httpGet(t, "http://"+addr+"/readyz"); code != http.StatusOK {
+		t.Errorf("expected readyz 200, got %d", code)
+	}
+	if _, body := httpGet(t, "http://"+addr+"/metrics"); !strings.Contains(body, "minitoolstream_publisher_published_total 0") {
+		t.Errorf("expected publisher metrics, got %q", body)
+	}
+
+	// The port is taken, so a second publisher fails to build
+	if _, err := NewPublisherBuilder("localhost:9090").WithHTTPEndpoints(addr).WithLogger(&testPubLogger{}).Build(); err == nil {
+		t.Error("expected error when the endpoint address is in use")
+	}
+
+	pub.Close()
+	if _, err := http.Get("http://" + addr + "/healthz"); err == nil {
+		t.Error("expected the endpoints to stop with the publisher")
+	}
+}
+
+type listingEgressClient struct {
+	domain.EgressClient
+}
+
+func (listingEgressClient) ListSubjects(ctx context.Context) ([]string, error) {
+	return []string{"orders"}, nil
+}
+
+func TestEgressWithCloser(t *testing.T) {
+	client := egressWithCloser(listingEgressClient{}, io.NopCloser(nil))
+	if _, ok := client.(domain.SubjectLister); !ok {
+		t.Error("expected the wrapper to keep listing subjects")
+	}
+}
@@ -0,0 +1,324 @@
+// Package gateway exposes MiniToolStream subjects over HTTP and WebSocket so services
+// without a gRPC client can publish messages and follow subjects.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// headerPrefix marks HTTP headers that are mapped to message headers
+const headerPrefix = "X-Stream-Header-"
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// Config represents gateway configuration
+type Config struct {
+	// Ingress publishes messages; publishing is disabled when nil
+	Ingress domain.IngressClient
+	// Egress streams messages; streaming is disabled when nil
+	Egress domain.EgressClient
+	// BatchSize is the fetch batch size used for streams
+	BatchSize int32
+	// MaxBodySize limits the size of published bodies in bytes
+	MaxBodySize int64
+	Logger      Logger
+}
+
+// Server serves the HTTP gateway endpoints:
+//
+//	POST /subjects/{subject}         publish the request body
+//	GET  /subjects/{subject}/stream  server-sent events of received messages
+type Server struct {
+	ingress     domain.IngressClient
+	egress      domain.EgressClient
+	batchSize   int32
+	maxBodySize int64
+	logger      Logger
+	mux         *http.ServeMux
+}
+
+// StreamEvent is the JSON payload of a streamed message
+type StreamEvent struct {
+	Subject   string            `json:"subject"`
+	Sequence  uint64            `json:"sequence"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Data      []byte            `json:"data"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// PublishResponse is the JSON response of a publish request
+type PublishResponse struct {
+	Subject    string `json:"subject"`
+	Sequence   uint64 `json:"sequence"`
+	ObjectName string `json:"object_name,omitempty"`
+}
+
+// New creates a new gateway server
+func New(config *Config) (*Server, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Ingress == nil && config.Egress == nil {
+		return nil, fmt.Errorf("at least one of ingress or egress client is required")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 10
+	}
+
+	maxBodySize := config.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = 32 << 20
+	}
+
+	s := &Server{
+		ingress:     config.Ingress,
+		egress:      config.Egress,
+		batchSize:   batchSize,
+		maxBodySize: maxBodySize,
+		logger:      logger,
+		mux:         http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("POST /subjects/{subject}", s.handlePublish)
+	s.mux.HandleFunc("GET /subjects/{subject}/stream", s.handleStream)
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves the gateway on addr until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	return listenAndServe(ctx, addr, s)
+}
+
+// listenAndServe serves handler on addr until ctx is cancelled, then shuts down gracefully
+func listenAndServe(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:        addr,
+		Handler:     handler,
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
+
+// handlePublish publishes the request body to the subject in the path
+func (s *Server) handlePublish(w http.ResponseWriter, r *http.Request) {
+	if s.ingress == nil {
+		http.Error(w, "publishing is not enabled", http.StatusNotImplemented)
+		return
+	}
+
+	subject := r.PathValue("subject")
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBodySize))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	msg := &domain.PublishMessage{
+		Subject: subject,
+		Data:    data,
+		Headers: messageHeaders(r.Header),
+	}
+
+	result, err := s.ingress.Publish(r.Context(), msg)
+	if err != nil {
+		s.logger.Printf("[gateway] Publish to %s failed: %v", subject, err)
+		http.Error(w, "publish failed", http.StatusBadGateway)
+		return
+	}
+	if result.StatusCode != 0 {
+		http.Error(w, "publish rejected: "+result.ErrorMessage, http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &PublishResponse{
+		Subject:    subject,
+		Sequence:   result.Sequence,
+		ObjectName: result.ObjectName,
+	})
+}
+
+// handleStream streams messages of the subject in the path as server-sent events.
+// Query parameters: durable (durable name, required), start (earliest, latest
+// or a sequence). The server keeps a durable consumer for every name, so
+// clients reuse theirs to resume instead of leaving one behind per connection.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	if s.egress == nil {
+		http.Error(w, "streaming is not enabled", http.StatusNotImplemented)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	startPos, err := parseStart(r.URL.Query().Get("start"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	durableName := r.URL.Query().Get("durable")
+	if durableName == "" {
+		http.Error(w, "durable is required", http.StatusBadRequest)
+		return
+	}
+
+	config := &domain.SubscriptionConfig{
+		Subject:       r.PathValue("subject"),
+		DurableName:   durableName,
+		StartPosition: startPos,
+		BatchSize:     s.batchSize,
+	}
+
+	ctx := r.Context()
+	notifications, err := s.egress.Subscribe(ctx, config)
+	if err != nil {
+		s.logger.Printf("[gateway] Subscribe to %s failed: %v", config.Subject, err)
+		http.Error(w, "subscribe failed", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		if _, err := notifications.Recv(); err != nil {
+			if ctx.Err() == nil && err != io.EOF {
+				s.logger.Printf("[gateway] Stream for %s ended: %v", config.Subject, err)
+			}
+			return
+		}
+
+		if err := s.streamBatch(ctx, w, config); err != nil {
+			if ctx.Err() == nil {
+				s.logger.Printf("[gateway] Fetch for %s failed: %v", config.Subject, err)
+			}
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// streamBatch fetches available messages and writes them as events
+func (s *Server) streamBatch(ctx context.Context, w io.Writer, config *domain.SubscriptionConfig) error {
+	messages, err := s.egress.Fetch(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	for {
+		msg, err := messages.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if config.StartPosition.Skip(msg) {
+			continue
+		}
+
+		payload, err := json.Marshal(&StreamEvent{
+			Subject:   msg.Subject,
+			Sequence:  msg.Sequence,
+			Headers:   msg.Headers,
+			Data:      msg.Data,
+			Timestamp: msg.Timestamp,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: message\ndata: %s\n\n", msg.Sequence, payload); err != nil {
+			return err
+		}
+	}
+}
+
+// messageHeaders maps HTTP request headers to message headers
+func messageHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string)
+	if contentType := h.Get("Content-Type"); contentType != "" {
+		headers["content-type"] = contentType
+	}
+	for name, values := range h {
+		if len(values) == 0 || !strings.HasPrefix(name, headerPrefix) {
+			continue
+		}
+		headers[strings.ToLower(strings.TrimPrefix(name, headerPrefix))] = values[0]
+	}
+	return headers
+}
+
+// parseStart parses the start query parameter
+func parseStart(value string) (domain.StartPosition, error) {
+	switch value {
+	case "":
+		return domain.StartPosition{}, nil
+	case "earliest":
+		return domain.Earliest(), nil
+	case "latest":
+		return domain.Latest(), nil
+	}
+
+	seq, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return domain.StartPosition{}, fmt.Errorf("invalid start %q: use earliest, latest or a sequence", value)
+	}
+	return domain.FromSequence(seq), nil
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,278 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, format)
+}
+
+type mockIngressClient struct {
+	publishFunc func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error)
+}
+
+func (m *mockIngressClient) Publish(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+	return m.publishFunc(ctx, msg)
+}
+
+func (m *mockIngressClient) Close() error {
+	return nil
+}
+
+type notificationStream struct {
+	notifications []*domain.Notification
+}
+
+func (s *notificationStream) Recv() (*domain.Notification, error) {
+	if len(s.notifications) == 0 {
+		return nil, io.EOF
+	}
+	n := s.notifications[0]
+	s.notifications = s.notifications[1:]
+	return n, nil
+}
+
+type messageStream struct {
+	messages []*domain.ReceivedMessage
+}
+
+func (s *messageStream) Recv() (*domain.ReceivedMessage, error) {
+	if len(s.messages) == 0 {
+		return nil, io.EOF
+	}
+	msg := s.messages[0]
+	s.messages = s.messages[1:]
+	return msg, nil
+}
+
+type mockEgressClient struct {
+	config   *domain.SubscriptionConfig
+	messages []*domain.ReceivedMessage
+}
+
+func (m *mockEgressClient) Subscribe(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+	m.config = config
+	return &notificationStream{notifications: []*domain.Notification{{Subject: config.Subject}}}, nil
+}
+
+func (m *mockEgressClient) Fetch(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+	return &messageStream{messages: m.messages}, nil
+}
+
+func (m *mockEgressClient) GetLastSequence(ctx context.Context, subject string) (uint64, error) {
+	return 0, nil
+}
+
+func (m *mockEgressClient) Close() error {
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	t.Run("nil config", func(t *testing.T) {
+		if _, err := New(nil); err == nil {
+			t.Fatal("expected error for nil config")
+		}
+	})
+
+	t.Run("no clients", func(t *testing.T) {
+		if _, err := New(&Config{}); err == nil {
+			t.Fatal("expected error when no client is set")
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		s, err := New(&Config{Egress: &mockEgressClient{}})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if s.batchSize != 10 {
+			t.Errorf("expected default batch size 10, got %d", s.batchSize)
+		}
+	})
+}
+
+func TestServer_Publish(t *testing.T) {
+	t.Run("publishes body and headers", func(t *testing.T) {
+		var published *domain.PublishMessage
+		s, _ := New(&Config{
+			Ingress: &mockIngressClient{
+				publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+					published = msg
+					return &domain.PublishResult{Sequence: 42, ObjectName: "orders-42"}, nil
+				},
+			},
+			Logger: &testLogger{},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/subjects/orders", strings.NewReader(`{"id":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Stream-Header-Tenant", "acme")
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", rec.Code)
+		}
+		if published.Subject != "orders" {
+			t.Errorf("expected subject 'orders', got %s", published.Subject)
+		}
+		if string(published.Data) != `{"id":1}` {
+			t.Errorf("unexpected data: %s", published.Data)
+		}
+		if published.Headers["content-type"] != "application/json" {
+			t.Errorf("expected content-type header, got %v", published.Headers)
+		}
+		if published.Headers["tenant"] != "acme" {
+			t.Errorf("expected tenant header, got %v", published.Headers)
+		}
+
+		var resp PublishResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Sequence != 42 {
+			t.Errorf("expected sequence 42, got %d", resp.Sequence)
+		}
+	})
+
+	t.Run("rejected publish", func(t *testing.T) {
+		s, _ := New(&Config{
+			Ingress: &mockIngressClient{
+				publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+					return &domain.PublishResult{StatusCode: 1, ErrorMessage: "denied"}, nil
+				},
+			},
+			Logger: &testLogger{},
+		})
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subjects/orders", strings.NewReader("x")))
+
+		if rec.Code != http.StatusBadGateway {
+			t.Errorf("expected status 502, got %d", rec.Code)
+		}
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		s, _ := New(&Config{
+			Ingress: &mockIngressClient{
+				publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+					t.Fatal("publish should not be called")
+					return nil, nil
+				},
+			},
+			MaxBodySize: 4,
+			Logger:      &testLogger{},
+		})
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subjects/orders", strings.NewReader("too large")))
+
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected status 413, got %d", rec.Code)
+		}
+	})
+
+	t.Run("publishing disabled", func(t *testing.T) {
+		s, _ := New(&Config{Egress: &mockEgressClient{}, Logger: &testLogger{}})
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/subjects/orders", strings.NewReader("x")))
+
+		if rec.Code != http.StatusNotImplemented {
+			t.Errorf("expected status 501, got %d", rec.Code)
+		}
+	})
+}
+
+func TestServer_Stream(t *testing.T) {
+	t.Run("streams messages as events", func(t *testing.T) {
+		egress := &mockEgressClient{
+			messages: []*domain.ReceivedMessage{
+				{Subject: "orders", Sequence: 1, Data: []byte("first")},
+				{Subject: "orders", Sequence: 2, Data: []byte("second")},
+			},
+		}
+		s, _ := New(&Config{Egress: egress, Logger: &testLogger{}})
+
+		srv := httptest.NewServer(s)
+		defer srv.Close()
+
+		resp, err := http.Get(srv.URL + "/subjects/orders/stream?durable=web&start=earliest")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("expected event stream, got %s", ct)
+		}
+
+		var events []StreamEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var event StreamEvent
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+				t.Fatalf("failed to decode event: %v", err)
+			}
+			events = append(events, event)
+		}
+
+		if len(events) != 2 {
+			t.Fatalf("expected 2 events, got %d", len(events))
+		}
+		if string(events[1].Data) != "second" || events[1].Sequence != 2 {
+			t.Errorf("unexpected event: %+v", events[1])
+		}
+		if egress.config.DurableName != "web" {
+			t.Errorf("expected durable name 'web', got %s", egress.config.DurableName)
+		}
+		if egress.config.StartPosition != domain.Earliest() {
+			t.Errorf("expected earliest start, got %s", egress.config.StartPosition)
+		}
+	})
+
+	t.Run("missing durable name", func(t *testing.T) {
+		egress := &mockEgressClient{}
+		s, _ := New(&Config{Egress: egress, Logger: &testLogger{}})
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subjects/orders/stream", nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+		if egress.config != nil {
+			t.Error("expected no subscription without a durable name")
+		}
+	})
+
+	t.Run("invalid start", func(t *testing.T) {
+		s, _ := New(&Config{Egress: &mockEgressClient{}, Logger: &testLogger{}})
+
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/subjects/orders/stream?start=soon", nil))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400, got %d", rec.Code)
+		}
+	})
+}
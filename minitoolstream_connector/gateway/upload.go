@@ -0,0 +1,247 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// UploadConfig represents upload server configuration
+type UploadConfig struct {
+	// Publisher publishes the uploads, with its retries and result handling
+	Publisher domain.Publisher
+	// SubjectPrefix is prepended to the subject derived from the request path
+	SubjectPrefix string
+	// MaxBodySize limits the size of a request body in bytes (default 32 MiB)
+	MaxBodySize int64
+	// MaxMemory is the part of a multipart body kept in memory; the rest is
+	// buffered in temporary files (default 8 MiB)
+	MaxMemory int64
+	Logger    Logger
+}
+
+// UploadServer accepts file uploads and publishes them, so files can be pushed
+// with curl or from a browser form. The request path maps to the subject, with
+// slashes replaced by dots: POST /images/raw publishes to images.raw.
+//
+// A multipart/form-data body publishes every file part as its own message;
+// the other form fields become headers of each file. Any other body is
+// published as a single message. X-Stream-Header-* request headers become
+// message headers in both cases.
+type UploadServer struct {
+	publisher     domain.Publisher
+	subjectPrefix string
+	maxBodySize   int64
+	maxMemory     int64
+	logger        Logger
+	mux           *http.ServeMux
+}
+
+// UploadResponse is the JSON response of an upload request
+type UploadResponse struct {
+	Subject string   `json:"subject"`
+	Files   []string `json:"files,omitempty"`
+	// Published is the number of messages published
+	Published int `json:"published"`
+}
+
+// NewUploadServer creates a new upload server
+func NewUploadServer(config *UploadConfig) (*UploadServer, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Publisher == nil {
+		return nil, fmt.Errorf("publisher cannot be nil")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	maxBodySize := config.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = 32 << 20
+	}
+
+	maxMemory := config.MaxMemory
+	if maxMemory <= 0 {
+		maxMemory = 8 << 20
+	}
+
+	s := &UploadServer{
+		publisher:     config.Publisher,
+		subjectPrefix: config.SubjectPrefix,
+		maxBodySize:   maxBodySize,
+		maxMemory:     maxMemory,
+		logger:        logger,
+		mux:           http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("POST /{path...}", s.handleUpload)
+
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler
+func (s *UploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves uploads on addr until ctx is cancelled
+func (s *UploadServer) ListenAndServe(ctx context.Context, addr string) error {
+	return listenAndServe(ctx, addr, s)
+}
+
+// subject maps a request path to a subject
+func (s *UploadServer) subject(path string) string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return ""
+	}
+	return s.subjectPrefix + strings.ReplaceAll(path, "/", ".")
+}
+
+// handleUpload publishes the request body to the subject derived from the path
+func (s *UploadServer) handleUpload(w http.ResponseWriter, r *http.Request) {
+	subject := s.subject(r.PathValue("path"))
+	if subject == "" {
+		http.Error(w, "subject is required in the path", http.StatusBadRequest)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxBodySize)
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		s.publishForm(w, r, subject)
+		return
+	}
+	s.publishBody(w, r, subject)
+}
+
+// publishBody publishes a raw request body as one message
+func (s *UploadServer) publishBody(w http.ResponseWriter, r *http.Request, subject string) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		readError(w, err)
+		return
+	}
+
+	headers := messageHeaders(r.Header)
+	var files []string
+	if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		headers["filename"] = params["filename"]
+		files = append(files, params["filename"])
+	}
+
+	if err := s.publish(r.Context(), subject, data, headers); err != nil {
+		s.publishError(w, subject, err)
+		return
+	}
+
+	s.logger.Printf("[upload] Published %d bytes to %s", len(data), subject)
+	writeJSON(w, http.StatusOK, &UploadResponse{Subject: subject, Files: files, Published: 1})
+}
+
+// publishForm publishes each file of a multipart form as one message, ordered
+// by form field name
+func (s *UploadServer) publishForm(w http.ResponseWriter, r *http.Request, subject string) {
+	if err := r.ParseMultipartForm(s.maxMemory); err != nil {
+		readError(w, err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	base := messageHeaders(r.Header)
+	delete(base, "content-type")
+	for name, values := range r.MultipartForm.Value {
+		if len(values) > 0 {
+			base[strings.ToLower(name)] = values[0]
+		}
+	}
+
+	fields := make([]string, 0, len(r.MultipartForm.File))
+	for field := range r.MultipartForm.File {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	resp := &UploadResponse{Subject: subject}
+	for _, field := range fields {
+		for _, fh := range r.MultipartForm.File[field] {
+			data, err := readFormFile(fh)
+			if err != nil {
+				http.Error(w, "failed to read file "+fh.Filename, http.StatusBadRequest)
+				return
+			}
+
+			headers := make(map[string]string, len(base)+3)
+			for k, v := range base {
+				headers[k] = v
+			}
+			headers["filename"] = fh.Filename
+			headers["form-field"] = field
+			if contentType := fh.Header.Get("Content-Type"); contentType != "" {
+				headers["content-type"] = contentType
+			}
+
+			if err := s.publish(r.Context(), subject, data, headers); err != nil {
+				s.publishError(w, subject, err)
+				return
+			}
+			resp.Files = append(resp.Files, fh.Filename)
+			resp.Published++
+		}
+	}
+
+	if resp.Published == 0 {
+		http.Error(w, "no files in form", http.StatusBadRequest)
+		return
+	}
+
+	s.logger.Printf("[upload] Published %d files to %s", resp.Published, subject)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// publish publishes one message through the publisher
+func (s *UploadServer) publish(ctx context.Context, subject string, data []byte, headers map[string]string) error {
+	return s.publisher.Publish(ctx, domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{Subject: subject, Data: data, Headers: headers}, nil
+	}))
+}
+
+// readError writes the response for a body that could not be read
+func readError(w http.ResponseWriter, err error) {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, "failed to read request body", http.StatusBadRequest)
+}
+
+// publishError writes the response for a failed publish. Files of a form
+// published before the failure are not rolled back.
+func (s *UploadServer) publishError(w http.ResponseWriter, subject string, err error) {
+	s.logger.Printf("[upload] Publish to %s failed: %v", subject, err)
+	http.Error(w, "publish failed", http.StatusBadGateway)
+}
+
+// readFormFile reads an uploaded file
+func readFormFile(fh *multipart.FileHeader) ([]byte, error) {
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
@@ -0,0 +1,198 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type mockPublisher struct {
+	published []*domain.PublishMessage
+	err       error
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, preparer domain.MessagePreparer) error {
+	if m.err != nil {
+		return m.err
+	}
+	msg, err := preparer.Prepare(ctx)
+	if err != nil {
+		return err
+	}
+	m.published = append(m.published, msg)
+	return nil
+}
+
+func (m *mockPublisher) PublishAll(ctx context.Context, preparers []domain.MessagePreparer) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishAllResults(ctx context.Context, preparers []domain.MessagePreparer) ([]domain.PublishOutcome, error) {
+	return nil, nil
+}
+
+func (m *mockPublisher) PublishGroup(ctx context.Context, preparers []domain.MessagePreparer) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishFanOut(ctx context.Context, subjects []string, data []byte, headers map[string]string) ([]domain.FanOutResult, error) {
+	return nil, nil
+}
+
+func (m *mockPublisher) PublishEvery(ctx context.Context, preparer domain.MessagePreparer, interval time.Duration) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishSchedule(ctx context.Context, preparer domain.MessagePreparer, schedule domain.Schedule) error {
+	return nil
+}
+func (m *mockPublisher) RegisterHandler(preparer domain.MessagePreparer)     {}
+func (m *mockPublisher) RegisterHandlers(preparers []domain.MessagePreparer) {}
+func (m *mockPublisher) RegisterPreparerFunc(fn func(ctx context.Context) (*domain.PublishMessage, error)) {
+}
+func (m *mockPublisher) SetResultHandler(handler domain.ResultHandler) {}
+func (m *mockPublisher) Close() error                                  { return nil }
+
+func TestNewUploadServer(t *testing.T) {
+	if _, err := NewUploadServer(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+	if _, err := NewUploadServer(&UploadConfig{}); err == nil {
+		t.Error("expected error for nil publisher")
+	}
+}
+
+func TestUploadServer_RawBody(t *testing.T) {
+	pub := &mockPublisher{}
+	s, _ := NewUploadServer(&UploadConfig{Publisher: pub, SubjectPrefix: "uploads.", Logger: &testLogger{}})
+
+	req := httptest.NewRequest(http.MethodPost, "/images/raw", strings.NewReader("png-bytes"))
+	req.Header.Set("Content-Type", "image/png")
+	req.Header.Set("Content-Disposition", `attachment; filename="cat.png"`)
+	req.Header.Set("X-Stream-Header-Source", "camera-1")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp UploadResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Subject != "uploads.images.raw" || resp.Published != 1 || len(resp.Files) != 1 || resp.Files[0] != "cat.png" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	if len(pub.published) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(pub.published))
+	}
+	msg := pub.published[0]
+	if msg.Subject != "uploads.images.raw" || string(msg.Data) != "png-bytes" {
+		t.Errorf("unexpected message %s %q", msg.Subject, msg.Data)
+	}
+	want := map[string]string{"content-type": "image/png", "filename": "cat.png", "source": "camera-1"}
+	for k, v := range want {
+		if msg.Headers[k] != v {
+			t.Errorf("expected header %s=%q, got %q", k, v, msg.Headers[k])
+		}
+	}
+}
+
+func TestUploadServer_Multipart(t *testing.T) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("camera", "front-door")
+	fw, _ := form.CreateFormFile("b", "second.txt")
+	fw.Write([]byte("second"))
+	fw, _ = form.CreateFormFile("a", "first.txt")
+	fw.Write([]byte("first"))
+	form.Close()
+
+	pub := &mockPublisher{}
+	s, _ := NewUploadServer(&UploadConfig{Publisher: pub, Logger: &testLogger{}})
+
+	req := httptest.NewRequest(http.MethodPost, "/docs", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(pub.published) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(pub.published))
+	}
+
+	first, second := pub.published[0], pub.published[1]
+	if string(first.Data) != "first" || first.Headers["filename"] != "first.txt" || first.Headers["form-field"] != "a" {
+		t.Errorf("unexpected first message %q %v", first.Data, first.Headers)
+	}
+	if string(second.Data) != "second" || second.Headers["filename"] != "second.txt" {
+		t.Errorf("unexpected second message %q %v", second.Data, second.Headers)
+	}
+	if first.Headers["camera"] != "front-door" || first.Headers["content-type"] != "application/octet-stream" {
+		t.Errorf("expected form fields and part content type as headers, got %v", first.Headers)
+	}
+}
+
+func TestUploadServer_Errors(t *testing.T) {
+	t.Run("empty path", func(t *testing.T) {
+		s, _ := NewUploadServer(&UploadConfig{Publisher: &mockPublisher{}, Logger: &testLogger{}})
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("x")))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		s, _ := NewUploadServer(&UploadConfig{Publisher: &mockPublisher{}, MaxBodySize: 4, Logger: &testLogger{}})
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/docs", strings.NewReader("too large")))
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected 413, got %d", rec.Code)
+		}
+	})
+
+	t.Run("form without files", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		form.WriteField("camera", "front-door")
+		form.Close()
+
+		s, _ := NewUploadServer(&UploadConfig{Publisher: &mockPublisher{}, Logger: &testLogger{}})
+		req := httptest.NewRequest(http.MethodPost, "/docs", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", rec.Code)
+		}
+	})
+
+	t.Run("publish failure", func(t *testing.T) {
+		s, _ := NewUploadServer(&UploadConfig{Publisher: &mockPublisher{err: errors.New("unavailable")}, Logger: &testLogger{}})
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/docs", strings.NewReader("x")))
+		if rec.Code != http.StatusBadGateway {
+			t.Errorf("expected 502, got %d", rec.Code)
+		}
+	})
+
+	t.Run("wrong method", func(t *testing.T) {
+		s, _ := NewUploadServer(&UploadConfig{Publisher: &mockPublisher{}, Logger: &testLogger{}})
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs", nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected 405, got %d", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// BridgeConfig represents WebSocket bridge configuration
+type BridgeConfig struct {
+	// Subscriber delivers the messages relayed to clients; the caller starts it
+	Subscriber domain.Subscriber
+	// Subjects are the subjects relayed by the bridge
+	Subjects []string
+	// SendBuffer is the number of frames buffered per client before frames are dropped
+	SendBuffer int
+	// AllowedOrigins lists the hosts of other origins whose pages may open a
+	// connection, e.g. "app.example.com" or "*.example.com". Pages of the
+	// bridge's own host and clients that send no Origin header, such as
+	// services, are always allowed.
+	AllowedOrigins []string
+	Logger         Logger
+}
+
+// Bridge relays subject messages to connected WebSocket clients as JSON frames.
+// Clients connect with an optional ?subject= query parameter to filter frames.
+type Bridge struct {
+	subjects   map[string]bool
+	sendBuffer int
+	origins    []string
+	logger     Logger
+
+	mu      sync.RWMutex
+	clients map[*bridgeClient]struct{}
+}
+
+// bridgeClient is a connected WebSocket client
+type bridgeClient struct {
+	subject string
+	send    chan *StreamEvent
+}
+
+// NewBridge creates a WebSocket bridge and registers its handlers on the subscriber
+func NewBridge(config *BridgeConfig) (*Bridge, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Subscriber == nil {
+		return nil, fmt.Errorf("subscriber cannot be nil")
+	}
+
+	if len(config.Subjects) == 0 {
+		return nil, fmt.Errorf("at least one subject is required")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	sendBuffer := config.SendBuffer
+	if sendBuffer <= 0 {
+		sendBuffer = 64
+	}
+
+	b := &Bridge{
+		subjects:   make(map[string]bool, len(config.Subjects)),
+		sendBuffer: sendBuffer,
+		origins:    config.AllowedOrigins,
+		logger:     logger,
+		clients:    make(map[*bridgeClient]struct{}),
+	}
+
+	for _, subject := range config.Subjects {
+		b.subjects[subject] = true
+		config.Subscriber.RegisterHandler(subject, b)
+	}
+
+	return b, nil
+}
+
+// Handle broadcasts a received message to connected clients
+func (b *Bridge) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	event := &StreamEvent{
+		Subject:   msg.Subject,
+		Sequence:  msg.Sequence,
+		Headers:   msg.Headers,
+		Data:      msg.Data,
+		Timestamp: msg.Timestamp,
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for client := range b.clients {
+		if client.subject != "" && client.subject != msg.Subject {
+			continue
+		}
+		select {
+		case client.send <- event:
+		default:
+			b.logger.Printf("[bridge] Client buffer full, dropping %s sequence %d", msg.Subject, msg.Sequence)
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP implements http.Handler by upgrading the request to a WebSocket.
+// Handshakes from origins that are not allowed are rejected with 403.
+func (b *Bridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	subject := r.URL.Query().Get("subject")
+	if subject != "" && !b.subjects[subject] {
+		http.Error(w, "unknown subject", http.StatusNotFound)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{OriginPatterns: b.origins})
+	if err != nil {
+		b.logger.Printf("[bridge] Rejected connection from %s: %v", r.Header.Get("Origin"), err)
+		return
+	}
+	b.serveConn(r.Context(), conn, subject)
+}
+
+// Clients returns the number of connected clients
+func (b *Bridge) Clients() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.clients)
+}
+
+// serveConn writes frames to a connected client until it disconnects
+func (b *Bridge) serveConn(ctx context.Context, conn *websocket.Conn, subject string) {
+	client := &bridgeClient{
+		subject: subject,
+		send:    make(chan *StreamEvent, b.sendBuffer),
+	}
+
+	b.mu.Lock()
+	b.clients[client] = struct{}{}
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.clients, client)
+		b.mu.Unlock()
+		conn.CloseNow()
+	}()
+
+	// Client frames are ignored; ctx ends when the client disconnects
+	ctx = conn.CloseRead(ctx)
+
+	for {
+		select {
+		case event := <-client.send:
+			if err := wsjson.Write(ctx, conn, event); err != nil {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type mockSubscriber struct {
+	handlers map[string]domain.MessageHandler
+}
+
+func (m *mockSubscriber) RegisterHandler(subject string, handler domain.MessageHandler) {
+	if m.handlers == nil {
+		m.handlers = make(map[string]domain.MessageHandler)
+	}
+	m.handlers[subject] = handler
+}
+
+func (m *mockSubscriber) RegisterHandlers(handlers map[string]domain.MessageHandler) {
+	for subject, handler := range handlers {
+		m.RegisterHandler(subject, handler)
+	}
+}
+
+func (m *mockSubscriber) RegisterHandlerFunc(subject string, fn func(ctx context.Context, msg *domain.ReceivedMessage) error) {
+	m.RegisterHandler(subject, domain.MessageHandlerFunc(fn))
+}
+
+func (m *mockSubscriber) Drain(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockSubscriber) RegisterPatternHandler(pattern *regexp.Regexp, handler domain.MessageHandler) {
+}
+
+func (m *mockSubscriber) Messages(ctx context.Context, subject string) (domain.MessageIterator, error) {
+	return nil, nil
+}
+
+func (m *mockSubscriber) SetErrorHandler(handler func(subject string, msg *domain.ReceivedMessage, err error)) {
+}
+
+func (m *mockSubscriber) Start(ctx context.Context) error { return nil }
+func (m *mockSubscriber) Stop()                           {}
+func (m *mockSubscriber) Wait()                           {}
+
+func TestNewBridge(t *testing.T) {
+	t.Run("nil config", func(t *testing.T) {
+		if _, err := NewBridge(nil); err == nil {
+			t.Fatal("expected error for nil config")
+		}
+	})
+
+	t.Run("nil subscriber", func(t *testing.T) {
+		if _, err := NewBridge(&BridgeConfig{Subjects: []string{"orders"}}); err == nil {
+			t.Fatal("expected error for nil subscriber")
+		}
+	})
+
+	t.Run("no subjects", func(t *testing.T) {
+		if _, err := NewBridge(&BridgeConfig{Subscriber: &mockSubscriber{}}); err == nil {
+			t.Fatal("expected error for empty subjects")
+		}
+	})
+
+	t.Run("registers handlers", func(t *testing.T) {
+		sub := &mockSubscriber{}
+		if _, err := NewBridge(&BridgeConfig{Subscriber: sub, Subjects: []string{"orders", "events"}}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(sub.handlers) != 2 {
+			t.Errorf("expected 2 handlers, got %d", len(sub.handlers))
+		}
+	})
+}
+
+func dialBridge(t *testing.T, srv *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+	conn, resp, err := dialOrigin(srv, query, srv.URL)
+	if err != nil {
+		t.Fatalf("dial failed: %v (%v)", err, resp)
+	}
+	return conn
+}
+
+// dialOrigin connects to the bridge as a page of origin would
+func dialOrigin(srv *httptest.Server, query, origin string) (*websocket.Conn, *http.Response, error) {
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/" + query
+	return websocket.Dial(context.Background(), url, &websocket.DialOptions{
+		HTTPHeader: http.Header{"Origin": {origin}},
+	})
+}
+
+func waitForClients(t *testing.T, b *Bridge, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for b.Clients() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d clients, got %d", n, b.Clients())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBridge_Relay(t *testing.T) {
+	sub := &mockSubscriber{}
+	bridge, _ := NewBridge(&BridgeConfig{
+		Subscriber: sub,
+		Subjects:   []string{"orders", "events"},
+		Logger:     &testLogger{},
+	})
+
+	srv := httptest.NewServer(bridge)
+	defer srv.Close()
+
+	all := dialBridge(t, srv, "")
+	defer all.CloseNow()
+	orders := dialBridge(t, srv, "?subject=orders")
+	defer orders.CloseNow()
+	waitForClients(t, bridge, 2)
+
+	ctx := context.Background()
+	sub.handlers["events"].Handle(ctx, &domain.ReceivedMessage{Subject: "events", Sequence: 1, Data: []byte("e1")})
+	sub.handlers["orders"].Handle(ctx, &domain.ReceivedMessage{Subject: "orders", Sequence: 7, Data: []byte("o7")})
+
+	t.Run("unfiltered client receives all subjects", func(t *testing.T) {
+		for _, want := range []string{"e1", "o7"} {
+			var event StreamEvent
+			if err := wsjson.Read(ctx, all, &event); err != nil {
+				t.Fatalf("receive failed: %v", err)
+			}
+			if string(event.Data) != want {
+				t.Errorf("expected %s, got %s", want, event.Data)
+			}
+		}
+	})
+
+	t.Run("filtered client receives its subject", func(t *testing.T) {
+		var event StreamEvent
+		if err := wsjson.Read(ctx, orders, &event); err != nil {
+			t.Fatalf("receive failed: %v", err)
+		}
+		if event.Subject != "orders" || event.Sequence != 7 {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	})
+
+	t.Run("client disconnect", func(t *testing.T) {
+		orders.Close(websocket.StatusNormalClosure, "")
+		waitForClients(t, bridge, 1)
+	})
+}
+
+func TestBridge_UnknownSubject(t *testing.T) {
+	bridge, _ := NewBridge(&BridgeConfig{
+		Subscriber: &mockSubscriber{},
+		Subjects:   []string{"orders"},
+		Logger:     &testLogger{},
+	})
+
+	rec := httptest.NewRecorder()
+	bridge.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?subject=secret", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestBridge_Origins(t *testing.T) {
+	bridge, _ := NewBridge(&BridgeConfig{
+		Subscriber:     &mockSubscriber{},
+		Subjects:       []string{"orders"},
+		AllowedOrigins: []string{"*.example.com"},
+		Logger:         &testLogger{},
+	})
+	srv := httptest.NewServer(bridge)
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{"same origin", srv.URL, true},
+		{"allowed origin", "https://app.example.com", true},
+		{"other origin", "https://evil.test", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, resp, err := dialOrigin(srv, "", tt.origin)
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("expected the handshake to succeed, got %v", err)
+				}
+				conn.CloseNow()
+				return
+			}
+			if err == nil {
+				conn.CloseNow()
+				t.Fatal("expected the handshake to be rejected")
+			}
+			if resp == nil || resp.StatusCode != http.StatusForbidden {
+				t.Errorf("expected status 403, got %v", resp)
+			}
+		})
+	}
+}
@@ -8,24 +8,134 @@ import (
 
 // Publisher handlers
 var (
-	NewDataHandler  = handler.NewDataHandler
-	NewFileHandler  = handler.NewFileHandler
-	NewImageHandler = handler.NewImageHandler
+	NewDataHandler        = handler.NewDataHandler
+	NewFileHandler        = handler.NewFileHandler
+	NewImageHandler       = handler.NewImageHandler
+	NewZipHandler         = handler.NewZipHandler
+	NewMultipartHandler   = handler.NewMultipartHandler
+	NewJSONPreparer       = handler.NewJSONPreparer
+	NewSQLPreparer        = handler.NewSQLPreparer
+	NewTailHandler        = handler.NewTailHandler
+	NewLoadGenHandler     = handler.NewLoadGenHandler
+	PreparersFromGlob     = handler.PreparersFromGlob
+	WithGlobContentType   = handler.WithGlobContentType
+	WithGlobProgress      = handler.WithGlobProgress
+	WithGlobLogger        = handler.WithGlobLogger
+	PreparersFromFixtures = handler.PreparersFromFixtures
+	LoadFixtures          = handler.LoadFixtures
 )
 
 // Subscriber handlers
 var (
-	NewFileSaver      = handler.NewFileSaver
-	NewImageProcessor = handler.NewImageProcessor
-	NewLoggerHandler  = handler.NewLoggerHandler
+	NewFileSaver        = handler.NewFileSaver
+	NewImageProcessor   = handler.NewImageProcessor
+	NewLoggerHandler    = handler.NewLoggerHandler
+	NewHTTPForwarder    = handler.NewHTTPForwarder
+	NewReassembler      = handler.NewReassembler
+	NewAzureBlobSaver   = handler.NewAzureBlobSaver
+	NewMetricsEmitter   = handler.NewMetricsEmitter
+	NewNotificationSink = handler.NewNotificationSink
+	NewTranscoder       = handler.NewTranscoder
+	NewFixtureRecorder  = handler.NewFixtureRecorder
 )
 
+// Handler composition
+var (
+	NewHandlerBuilder = handler.NewHandlerBuilder
+	ChainHandlers     = handler.Chain
+	WithHandlerRetry  = handler.WithRetry
+)
+
+// Payload encryption
+var (
+	NewEncryptingPreparer = handler.NewEncryptingPreparer
+	WithHandlerDecryption = handler.WithDecryption
+	NewFileKeyProvider    = handler.NewFileKeyProvider
+	NewVaultKeyProvider   = handler.NewVaultKeyProvider
+	ErrKeyNotFound        = handler.ErrKeyNotFound
+)
+
+// Audit trail
+var (
+	NewAuditHandler       = handler.NewAuditHandler
+	NewAuditResultHandler = handler.NewAuditResultHandler
+	OpenAuditFile         = handler.OpenAuditFile
+	NewAuditSubject       = handler.NewAuditSubject
+)
+
+// Audit types
+type (
+	AuditConfig = handler.AuditConfig
+	AuditRecord = handler.AuditRecord
+	AuditSink   = handler.AuditSink
+)
+
+// Key providers
+type (
+	KeyProvider            = handler.KeyProvider
+	StaticKeys             = handler.StaticKeys
+	VaultKeyProviderConfig = handler.VaultKeyProviderConfig
+)
+
+// EncryptionAESGCM re-exports handler.EncryptionAESGCM
+const EncryptionAESGCM = handler.EncryptionAESGCM
+
 // Handler configs
 type (
-	DataHandlerConfig     = handler.DataHandlerConfig
-	FileHandlerConfig     = handler.FileHandlerConfig
-	ImageHandlerConfig    = handler.ImageHandlerConfig
-	FileSaverConfig       = handler.FileSaverConfig
-	ImageProcessorConfig  = handler.ImageProcessorConfig
-	LoggerHandlerConfig   = handler.LoggerHandlerConfig
+	DataHandlerConfig      = handler.DataHandlerConfig
+	FileHandlerConfig      = handler.FileHandlerConfig
+	ImageHandlerConfig     = handler.ImageHandlerConfig
+	ZipHandlerConfig       = handler.ZipHandlerConfig
+	MultipartHandlerConfig = handler.MultipartHandlerConfig
+	ReassemblerConfig      = handler.ReassemblerConfig
+	SQLPreparerConfig      = handler.SQLPreparerConfig
+	TailHandlerConfig      = handler.TailHandlerConfig
+	LoadGenConfig          = handler.LoadGenConfig
+	LoadGenStats           = handler.LoadGenStats
+	FileSaverConfig        = handler.FileSaverConfig
+	AzureBlobSaverConfig   = handler.AzureBlobSaverConfig
+	MetricsEmitterConfig   = handler.MetricsEmitterConfig
+	MetricSpec             = handler.MetricSpec
+	NotificationSinkConfig = handler.NotificationSinkConfig
+	SMTPConfig             = handler.SMTPConfig
+	NotificationData       = handler.NotificationData
+	TranscoderConfig       = handler.TranscoderConfig
+	FixtureRecorderConfig  = handler.FixtureRecorderConfig
+	ImageProcessorConfig   = handler.ImageProcessorConfig
+	LoggerHandlerConfig    = handler.LoggerHandlerConfig
+	HTTPForwarderConfig    = handler.HTTPForwarderConfig
+)
+
+// Transcoder command placeholders
+const (
+	TranscodeInput  = handler.TranscodeInput
+	TranscodeOutput = handler.TranscodeOutput
 )
+
+// SQL result set formats
+const (
+	SQLFormatNDJSON = handler.SQLFormatNDJSON
+	SQLFormatCSV    = handler.SQLFormatCSV
+)
+
+// Load generator patterns
+const (
+	LoadPatternRandom     = handler.LoadPatternRandom
+	LoadPatternSequential = handler.LoadPatternSequential
+	LoadPatternReplay     = handler.LoadPatternReplay
+)
+
+// Metric types
+const (
+	MetricGauge   = handler.MetricGauge
+	MetricCounter = handler.MetricCounter
+)
+
+// GlobProgress re-exports handler.GlobProgress
+type GlobProgress = handler.GlobProgress
+
+// MultipartManifest re-exports handler.Manifest
+type MultipartManifest = handler.Manifest
+
+// HandlerBuilder re-exports handler.HandlerBuilder
+type HandlerBuilder = handler.HandlerBuilder
@@ -0,0 +1,131 @@
+// Package amqp bridges MiniToolStream subjects and AMQP (RabbitMQ) exchanges and queues
+package amqp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Header names carrying stream metadata on AMQP messages
+const (
+	HeaderSubject  = "x-stream-subject"
+	HeaderSequence = "x-stream-sequence"
+)
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// PublishChannel is the part of *amqp091.Channel used by Forwarder
+type PublishChannel interface {
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp091.Publishing) error
+}
+
+// Forwarder forwards received messages to an AMQP exchange
+type Forwarder struct {
+	channel    PublishChannel
+	exchange   string
+	routingKey string
+	transient  bool
+	logger     Logger
+	clock      domain.Clock
+}
+
+// ForwarderConfig represents configuration for Forwarder
+type ForwarderConfig struct {
+	Channel  PublishChannel
+	Exchange string
+	// RoutingKey defaults to the message subject
+	RoutingKey string
+	// Transient disables persistent delivery mode
+	Transient bool
+	Logger    Logger
+	// Clock stamps messages without a timestamp (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// NewForwarder creates a new AMQP forwarder handler
+func NewForwarder(config *ForwarderConfig) (*Forwarder, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Channel == nil {
+		return nil, fmt.Errorf("channel cannot be nil")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	return &Forwarder{
+		channel:    config.Channel,
+		exchange:   config.Exchange,
+		routingKey: config.RoutingKey,
+		transient:  config.Transient,
+		logger:     logger,
+		clock:      domain.ClockOrSystem(config.Clock),
+	}, nil
+}
+
+// Handle publishes the message to the configured exchange.
+// Message headers are copied to AMQP headers.
+func (f *Forwarder) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	routingKey := f.routingKey
+	if routingKey == "" {
+		routingKey = msg.Subject
+	}
+
+	headers := amqp091.Table{
+		HeaderSubject:  msg.Subject,
+		HeaderSequence: int64(msg.Sequence),
+	}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	deliveryMode := amqp091.Persistent
+	if f.transient {
+		deliveryMode = amqp091.Transient
+	}
+
+	timestamp := msg.Timestamp
+	if timestamp.IsZero() {
+		timestamp = f.clock.Now()
+	}
+
+	publishing := amqp091.Publishing{
+		Headers:      headers,
+		ContentType:  msg.Headers["content-type"],
+		DeliveryMode: deliveryMode,
+		Timestamp:    timestamp,
+		Body:         msg.Data,
+	}
+
+	if err := f.channel.PublishWithContext(ctx, f.exchange, routingKey, false, false, publishing); err != nil {
+		return fmt.Errorf("failed to publish to exchange %q: %w", f.exchange, err)
+	}
+
+	f.logger.Printf("[%s] Forwarded sequence %d to AMQP exchange %q (%s)", msg.Subject, msg.Sequence, f.exchange, routingKey)
+	return nil
+}
+
+// messageHeaders converts AMQP headers to message headers, dropping stream metadata
+func messageHeaders(delivery *amqp091.Delivery) map[string]string {
+	headers := make(map[string]string)
+	if delivery.ContentType != "" {
+		headers["content-type"] = delivery.ContentType
+	}
+	for k, v := range delivery.Headers {
+		if strings.HasPrefix(k, "x-stream-") {
+			continue
+		}
+		headers[k] = fmt.Sprint(v)
+	}
+	return headers
+}
@@ -0,0 +1,123 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, format)
+}
+
+type mockPublishChannel struct {
+	exchange   string
+	key        string
+	publishing amqp091.Publishing
+	err        error
+}
+
+func (m *mockPublishChannel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp091.Publishing) error {
+	m.exchange = exchange
+	m.key = key
+	m.publishing = msg
+	return m.err
+}
+
+func TestNewForwarder(t *testing.T) {
+	t.Run("nil config", func(t *testing.T) {
+		if _, err := NewForwarder(nil); err == nil {
+			t.Fatal("expected error for nil config")
+		}
+	})
+
+	t.Run("nil channel", func(t *testing.T) {
+		if _, err := NewForwarder(&ForwarderConfig{Exchange: "events"}); err == nil {
+			t.Fatal("expected error for nil channel")
+		}
+	})
+}
+
+func TestForwarder_Handle(t *testing.T) {
+	msg := &domain.ReceivedMessage{
+		Subject:  "orders",
+		Sequence: 12,
+		Data:     []byte(`{"id":1}`),
+		Headers:  map[string]string{"content-type": "application/json", "tenant": "acme"},
+	}
+
+	t.Run("routes by subject", func(t *testing.T) {
+		channel := &mockPublishChannel{}
+		f, _ := NewForwarder(&ForwarderConfig{Channel: channel, Exchange: "events", Logger: &testLogger{}})
+
+		if err := f.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if channel.exchange != "events" || channel.key != "orders" {
+			t.Errorf("unexpected exchange/key: %s/%s", channel.exchange, channel.key)
+		}
+		if channel.publishing.ContentType != "application/json" {
+			t.Errorf("expected content type, got %s", channel.publishing.ContentType)
+		}
+		if channel.publishing.DeliveryMode != amqp091.Persistent {
+			t.Error("expected persistent delivery")
+		}
+		if channel.publishing.Headers["tenant"] != "acme" {
+			t.Errorf("expected tenant header, got %v", channel.publishing.Headers)
+		}
+		if channel.publishing.Headers[HeaderSequence] != int64(12) {
+			t.Errorf("expected sequence header, got %v", channel.publishing.Headers[HeaderSequence])
+		}
+	})
+
+	t.Run("fixed routing key", func(t *testing.T) {
+		channel := &mockPublishChannel{}
+		f, _ := NewForwarder(&ForwarderConfig{Channel: channel, RoutingKey: "all", Transient: true, Logger: &testLogger{}})
+
+		f.Handle(context.Background(), msg)
+
+		if channel.key != "all" {
+			t.Errorf("expected routing key 'all', got %s", channel.key)
+		}
+		if channel.publishing.DeliveryMode != amqp091.Transient {
+			t.Error("expected transient delivery")
+		}
+	})
+
+	t.Run("timestamp from the clock", func(t *testing.T) {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		channel := &mockPublishChannel{}
+		f, _ := NewForwarder(&ForwarderConfig{Channel: channel, Logger: &testLogger{}, Clock: connectortest.NewFakeClock(now)})
+
+		f.Handle(context.Background(), msg)
+		if !channel.publishing.Timestamp.Equal(now) {
+			t.Errorf("expected timestamp %v, got %v", now, channel.publishing.Timestamp)
+		}
+
+		stamped := *msg
+		stamped.Timestamp = now.Add(-time.Hour)
+		f.Handle(context.Background(), &stamped)
+		if !channel.publishing.Timestamp.Equal(stamped.Timestamp) {
+			t.Errorf("expected message timestamp %v, got %v", stamped.Timestamp, channel.publishing.Timestamp)
+		}
+	})
+
+	t.Run("publish error", func(t *testing.T) {
+		channel := &mockPublishChannel{err: errors.New("channel closed")}
+		f, _ := NewForwarder(&ForwarderConfig{Channel: channel, Logger: &testLogger{}})
+
+		if err := f.Handle(context.Background(), msg); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package amqp
+
+import (
+	"context"
+	"fmt"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// ConsumeChannel is the part of *amqp091.Channel used by Source
+type ConsumeChannel interface {
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error)
+}
+
+// Source consumes an AMQP queue and publishes each delivery to a subject.
+// Deliveries are acknowledged after a successful publish and requeued otherwise.
+type Source struct {
+	channel   ConsumeChannel
+	queue     string
+	consumer  string
+	subject   string
+	publisher domain.Publisher
+	logger    Logger
+}
+
+// SourceConfig represents configuration for Source
+type SourceConfig struct {
+	Channel ConsumeChannel
+	Queue   string
+	// Consumer is the AMQP consumer tag; generated by the server when empty
+	Consumer  string
+	Subject   string
+	Publisher domain.Publisher
+	Logger    Logger
+}
+
+// NewSource creates a new AMQP source
+func NewSource(config *SourceConfig) (*Source, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Channel == nil {
+		return nil, fmt.Errorf("channel cannot be nil")
+	}
+
+	if config.Publisher == nil {
+		return nil, fmt.Errorf("publisher cannot be nil")
+	}
+
+	if config.Queue == "" {
+		return nil, fmt.Errorf("queue is required")
+	}
+
+	if config.Subject == "" {
+		return nil, fmt.Errorf("subject is required")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	return &Source{
+		channel:   config.Channel,
+		queue:     config.Queue,
+		consumer:  config.Consumer,
+		subject:   config.Subject,
+		publisher: config.Publisher,
+		logger:    logger,
+	}, nil
+}
+
+// Run consumes the queue until ctx is cancelled or the delivery channel is closed
+func (s *Source) Run(ctx context.Context) error {
+	deliveries, err := s.channel.Consume(s.queue, s.consumer, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume queue %q: %w", s.queue, err)
+	}
+
+	s.logger.Printf("✓ Consuming AMQP queue %q into subject %s", s.queue, s.subject)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case delivery, ok := <-deliveries:
+			if !ok {
+				s.logger.Printf("AMQP queue %q delivery channel closed", s.queue)
+				return nil
+			}
+			s.forward(ctx, &delivery)
+		}
+	}
+}
+
+// Preparer returns a message preparer for a single delivery
+func (s *Source) Preparer(delivery *amqp091.Delivery) domain.MessagePreparer {
+	return domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{
+			Subject: s.subject,
+			Data:    delivery.Body,
+			Headers: messageHeaders(delivery),
+		}, nil
+	})
+}
+
+// forward publishes a delivery and settles it with the broker
+func (s *Source) forward(ctx context.Context, delivery *amqp091.Delivery) {
+	if err := s.publisher.Publish(ctx, s.Preparer(delivery)); err != nil {
+		s.logger.Printf("✗ Failed to publish AMQP delivery %d: %v", delivery.DeliveryTag, err)
+		if err := delivery.Nack(false, true); err != nil {
+			s.logger.Printf("✗ Failed to requeue AMQP delivery %d: %v", delivery.DeliveryTag, err)
+		}
+		return
+	}
+
+	if err := delivery.Ack(false); err != nil {
+		s.logger.Printf("✗ Failed to ack AMQP delivery %d: %v", delivery.DeliveryTag, err)
+	}
+}
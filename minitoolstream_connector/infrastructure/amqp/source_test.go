@@ -0,0 +1,205 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type mockConsumeChannel struct {
+	deliveries chan amqp091.Delivery
+	err        error
+}
+
+func (m *mockConsumeChannel) Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp091.Table) (<-chan amqp091.Delivery, error) {
+	return m.deliveries, m.err
+}
+
+type mockAcknowledger struct {
+	acked  []uint64
+	nacked []uint64
+}
+
+func (m *mockAcknowledger) Ack(tag uint64, multiple bool) error {
+	m.acked = append(m.acked, tag)
+	return nil
+}
+
+func (m *mockAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	m.nacked = append(m.nacked, tag)
+	return nil
+}
+
+func (m *mockAcknowledger) Reject(tag uint64, requeue bool) error {
+	return nil
+}
+
+type mockPublisher struct {
+	published []*domain.PublishMessage
+	fail      bool
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, preparer domain.MessagePreparer) error {
+	if m.fail {
+		return errors.New("unavailable")
+	}
+	msg, err := preparer.Prepare(ctx)
+	if err != nil {
+		return err
+	}
+	m.published = append(m.published, msg)
+	return nil
+}
+
+func (m *mockPublisher) PublishAll(ctx context.Context, preparers []domain.MessagePreparer) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishAllResults(ctx context.Context, preparers []domain.MessagePreparer) ([]domain.PublishOutcome, error) {
+	return nil, nil
+}
+
+func (m *mockPublisher) PublishGroup(ctx context.Context, preparers []domain.MessagePreparer) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishFanOut(ctx context.Context, subjects []string, data []byte, headers map[string]string) ([]domain.FanOutResult, error) {
+	return nil, nil
+}
+
+func (m *mockPublisher) PublishEvery(ctx context.Context, preparer domain.MessagePreparer, interval time.Duration) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishSchedule(ctx context.Context, preparer domain.MessagePreparer, schedule domain.Schedule) error {
+	return nil
+}
+func (m *mockPublisher) RegisterHandler(preparer domain.MessagePreparer)     {}
+func (m *mockPublisher) RegisterHandlers(preparers []domain.MessagePreparer) {}
+func (m *mockPublisher) RegisterPreparerFunc(fn func(ctx context.Context) (*domain.PublishMessage, error)) {
+}
+func (m *mockPublisher) SetResultHandler(handler domain.ResultHandler) {}
+func (m *mockPublisher) Close() error                                  { return nil }
+
+func TestNewSource(t *testing.T) {
+	channel := &mockConsumeChannel{}
+	publisher := &mockPublisher{}
+
+	tests := []struct {
+		name   string
+		config *SourceConfig
+	}{
+		{"nil config", nil},
+		{"nil channel", &SourceConfig{Publisher: publisher, Queue: "q", Subject: "s"}},
+		{"nil publisher", &SourceConfig{Channel: channel, Queue: "q", Subject: "s"}},
+		{"empty queue", &SourceConfig{Channel: channel, Publisher: publisher, Subject: "s"}},
+		{"empty subject", &SourceConfig{Channel: channel, Publisher: publisher, Queue: "q"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSource(tt.config); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestSource_Run(t *testing.T) {
+	t.Run("publishes and acks deliveries", func(t *testing.T) {
+		ack := &mockAcknowledger{}
+		deliveries := make(chan amqp091.Delivery, 2)
+		deliveries <- amqp091.Delivery{Acknowledger: ack, DeliveryTag: 1, ContentType: "text/plain", Body: []byte("one"),
+			Headers: amqp091.Table{"tenant": "acme", HeaderSequence: int64(3)}}
+		deliveries <- amqp091.Delivery{Acknowledger: ack, DeliveryTag: 2, Body: []byte("two")}
+		close(deliveries)
+
+		publisher := &mockPublisher{}
+		source, _ := NewSource(&SourceConfig{
+			Channel:   &mockConsumeChannel{deliveries: deliveries},
+			Queue:     "legacy",
+			Subject:   "orders",
+			Publisher: publisher,
+			Logger:    &testLogger{},
+		})
+
+		if err := source.Run(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(publisher.published) != 2 {
+			t.Fatalf("expected 2 published messages, got %d", len(publisher.published))
+		}
+		first := publisher.published[0]
+		if first.Subject != "orders" || string(first.Data) != "one" {
+			t.Errorf("unexpected message: %+v", first)
+		}
+		if first.Headers["content-type"] != "text/plain" || first.Headers["tenant"] != "acme" {
+			t.Errorf("unexpected headers: %v", first.Headers)
+		}
+		if _, ok := first.Headers[HeaderSequence]; ok {
+			t.Error("expected stream metadata header to be dropped")
+		}
+		if len(ack.acked) != 2 {
+			t.Errorf("expected 2 acks, got %d", len(ack.acked))
+		}
+	})
+
+	t.Run("requeues on publish failure", func(t *testing.T) {
+		ack := &mockAcknowledger{}
+		deliveries := make(chan amqp091.Delivery, 1)
+		deliveries <- amqp091.Delivery{Acknowledger: ack, DeliveryTag: 7, Body: []byte("x")}
+		close(deliveries)
+
+		source, _ := NewSource(&SourceConfig{
+			Channel:   &mockConsumeChannel{deliveries: deliveries},
+			Queue:     "legacy",
+			Subject:   "orders",
+			Publisher: &mockPublisher{fail: true},
+			Logger:    &testLogger{},
+		})
+
+		source.Run(context.Background())
+
+		if len(ack.nacked) != 1 || ack.nacked[0] != 7 {
+			t.Errorf("expected delivery 7 to be requeued, got %v", ack.nacked)
+		}
+		if len(ack.acked) != 0 {
+			t.Error("expected no acks")
+		}
+	})
+
+	t.Run("consume error", func(t *testing.T) {
+		source, _ := NewSource(&SourceConfig{
+			Channel:   &mockConsumeChannel{err: errors.New("no queue")},
+			Queue:     "legacy",
+			Subject:   "orders",
+			Publisher: &mockPublisher{},
+			Logger:    &testLogger{},
+		})
+
+		if err := source.Run(context.Background()); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		source, _ := NewSource(&SourceConfig{
+			Channel:   &mockConsumeChannel{deliveries: make(chan amqp091.Delivery)},
+			Queue:     "legacy",
+			Subject:   "orders",
+			Publisher: &mockPublisher{},
+			Logger:    &testLogger{},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := source.Run(ctx); err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package checkpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore persists checkpoints as a JSON document on the local filesystem.
+// Writes go to a temporary file which is then renamed, so a crash never leaves
+// a partially written checkpoint file behind.
+type FileStore struct {
+	path        string
+	mu          sync.Mutex
+	checkpoints map[string]uint64
+}
+
+// NewFileStore creates a checkpoint store backed by the file at path,
+// loading existing checkpoints if the file is present
+func NewFileStore(path string) (*FileStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("checkpoint file path is required")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	store := &FileStore{
+		path:        path,
+		checkpoints: make(map[string]uint64),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read checkpoint file %s: %w", path, err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &store.checkpoints); err != nil {
+			return nil, fmt.Errorf("failed to parse checkpoint file %s: %w", path, err)
+		}
+	}
+
+	return store, nil
+}
+
+// Load returns the stored checkpoint for a durable consumer and subject
+func (s *FileStore) Load(ctx context.Context, durableName, subject string) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seq, ok := s.checkpoints[key(durableName, subject)]
+	return seq, ok, nil
+}
+
+// Save stores the checkpoint and flushes all checkpoints to disk
+func (s *FileStore) Save(ctx context.Context, durableName, subject string, sequence uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkpoints[key(durableName, subject)] = sequence
+
+	data, err := json.Marshal(s.checkpoints)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoints: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint file: %w", err)
+	}
+
+	return nil
+}
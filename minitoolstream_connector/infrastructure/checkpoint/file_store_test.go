@@ -0,0 +1,57 @@
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewFileStore(t *testing.T) {
+	t.Run("empty path", func(t *testing.T) {
+		_, err := NewFileStore("")
+		if err == nil {
+			t.Fatal("expected error for empty path")
+		}
+	})
+
+	t.Run("invalid file content", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "checkpoints.json")
+		os.WriteFile(path, []byte("not json"), 0644)
+
+		_, err := NewFileStore(path)
+		if err == nil {
+			t.Fatal("expected error for invalid content")
+		}
+	})
+}
+
+func TestFileStore_SaveLoad(t *testing.T) {
+	t.Run("checkpoints survive reopen", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "state", "checkpoints.json")
+
+		store, err := NewFileStore(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := store.Save(context.Background(), "consumer", "orders", 7); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		reopened, err := NewFileStore(path)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		seq, ok, err := reopened.Load(context.Background(), "consumer", "orders")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !ok || seq != 7 {
+			t.Errorf("expected checkpoint 7, got %d (found=%v)", seq, ok)
+		}
+
+		if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+			t.Error("expected temporary file to be removed")
+		}
+	})
+}
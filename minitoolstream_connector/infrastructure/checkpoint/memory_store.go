@@ -0,0 +1,40 @@
+package checkpoint
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore keeps checkpoints in memory; useful for tests and short-lived processes
+type MemoryStore struct {
+	mu          sync.RWMutex
+	checkpoints map[string]uint64
+}
+
+// NewMemoryStore creates a new in-memory checkpoint store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		checkpoints: make(map[string]uint64),
+	}
+}
+
+// Load returns the stored checkpoint for a durable consumer and subject
+func (s *MemoryStore) Load(ctx context.Context, durableName, subject string) (uint64, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seq, ok := s.checkpoints[key(durableName, subject)]
+	return seq, ok, nil
+}
+
+// Save stores the checkpoint for a durable consumer and subject
+func (s *MemoryStore) Save(ctx context.Context, durableName, subject string, sequence uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[key(durableName, subject)] = sequence
+	return nil
+}
+
+// key builds the map key for a durable consumer and subject
+func key(durableName, subject string) string {
+	return durableName + "/" + subject
+}
@@ -0,0 +1,35 @@
+package checkpoint
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	t.Run("load missing checkpoint", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, ok, err := store.Load(context.Background(), "consumer", "subject")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if ok {
+			t.Error("expected no checkpoint")
+		}
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		store := NewMemoryStore()
+		if err := store.Save(context.Background(), "consumer", "subject", 42); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		seq, ok, _ := store.Load(context.Background(), "consumer", "subject")
+		if !ok || seq != 42 {
+			t.Errorf("expected checkpoint 42, got %d (found=%v)", seq, ok)
+		}
+
+		if _, ok, _ := store.Load(context.Background(), "other", "subject"); ok {
+			t.Error("expected checkpoints to be scoped by durable name")
+		}
+	})
+}
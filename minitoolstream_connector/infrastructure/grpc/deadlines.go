@@ -0,0 +1,66 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Deadlines holds the timeouts applied to calls whose context has no deadline.
+// A zero duration leaves the call without a deadline.
+type Deadlines struct {
+	Publish            time.Duration // Publish call
+	Fetch              time.Duration // Whole Fetch batch
+	SubscribeEstablish time.Duration // Opening a Subscribe stream; the stream itself is long-lived
+	GetLastSequence    time.Duration // GetLastSequence call
+}
+
+// DefaultDeadlines returns the deadlines used by new clients
+func DefaultDeadlines() Deadlines {
+	return Deadlines{
+		Publish:            10 * time.Second,
+		Fetch:              30 * time.Second,
+		SubscribeEstablish: 10 * time.Second,
+		GetLastSequence:    5 * time.Second,
+	}
+}
+
+// withDeadline applies timeout to ctx unless it already has a deadline
+func withDeadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// establish runs open with a stream context that stays valid after open returns,
+// but is cancelled if open does not return within timeout. The returned cancel func
+// releases the stream context once the stream is done.
+func establish(ctx context.Context, timeout time.Duration, open func(ctx context.Context) error) (context.Context, context.CancelFunc, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	var timer *time.Timer
+	if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+		timer = time.AfterFunc(timeout, cancel)
+	}
+
+	err := open(streamCtx)
+	if timer != nil && !timer.Stop() {
+		// The timer fired and has cancelled the stream
+		err = fmt.Errorf("stream not established within %v: %w", timeout, context.DeadlineExceeded)
+	}
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return streamCtx, cancel, nil
+}
+
+// SetDeadlines sets the deadlines applied to calls whose context has no deadline
+func (c *IngressClient) SetDeadlines(deadlines Deadlines) {
+	c.deadlines = deadlines
+}
+
+// SetDeadlines sets the deadlines applied to calls whose context has no deadline
+func (c *EgressClient) SetDeadlines(deadlines Deadlines) {
+	c.deadlines = deadlines
+}
@@ -0,0 +1,149 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/grpc"
+)
+
+func TestWithDeadline(t *testing.T) {
+	t.Run("applies timeout", func(t *testing.T) {
+		ctx, cancel := withDeadline(context.Background(), time.Minute)
+		defer cancel()
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected deadline")
+		}
+	})
+
+	t.Run("keeps caller deadline", func(t *testing.T) {
+		parent, cancelParent := context.WithTimeout(context.Background(), time.Hour)
+		defer cancelParent()
+		want, _ := parent.Deadline()
+
+		ctx, cancel := withDeadline(parent, time.Minute)
+		defer cancel()
+		if got, _ := ctx.Deadline(); !got.Equal(want) {
+			t.Errorf("expected caller deadline %v, got %v", want, got)
+		}
+	})
+
+	t.Run("zero timeout", func(t *testing.T) {
+		ctx, cancel := withDeadline(context.Background(), 0)
+		defer cancel()
+		if _, ok := ctx.Deadline(); ok {
+			t.Error("expected no deadline")
+		}
+	})
+}
+
+func TestIngressClient_PublishDeadline(t *testing.T) {
+	var remaining time.Duration
+	client := &IngressClient{
+		client: &mockIngressServiceClient{
+			publishFunc: func(ctx context.Context, in *pb.PublishRequest, opts ...grpc.CallOption) (*pb.PublishResponse, error) {
+				deadline, ok := ctx.Deadline()
+				if ok {
+					remaining = time.Until(deadline)
+				}
+				return &pb.PublishResponse{}, nil
+			},
+		},
+	}
+	client.SetDeadlines(Deadlines{Publish: time.Minute})
+
+	if _, err := client.Publish(context.Background(), &domain.PublishMessage{Subject: "orders"}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if remaining <= 0 || remaining > time.Minute {
+		t.Errorf("expected publish deadline within a minute, got %v", remaining)
+	}
+}
+
+func TestEgressClient_Deadlines(t *testing.T) {
+	t.Run("get last sequence", func(t *testing.T) {
+		hasDeadline := false
+		client := &EgressClient{
+			client: &mockEgressServiceClient{
+				getLastSequenceFunc: func(ctx context.Context, in *pb.GetLastSequenceRequest, opts ...grpc.CallOption) (*pb.GetLastSequenceResponse, error) {
+					_, hasDeadline = ctx.Deadline()
+					return &pb.GetLastSequenceResponse{}, nil
+				},
+			},
+		}
+		client.SetDeadlines(DefaultDeadlines())
+
+		client.GetLastSequence(context.Background(), "orders")
+		if !hasDeadline {
+			t.Error("expected deadline on GetLastSequence")
+		}
+	})
+
+	t.Run("fetch", func(t *testing.T) {
+		var streamCtx context.Context
+		client := &EgressClient{
+			client: &mockEgressServiceClient{
+				fetchFunc: func(ctx context.Context, in *pb.FetchRequest, opts ...grpc.CallOption) (pb.EgressService_FetchClient, error) {
+					streamCtx = ctx
+					return &mockFetchClient{}, nil
+				},
+			},
+		}
+		client.SetDeadlines(DefaultDeadlines())
+
+		stream, err := client.Fetch(context.Background(), &domain.SubscriptionConfig{Subject: "orders"})
+		if err != nil {
+			t.Fatalf("Fetch failed: %v", err)
+		}
+		if _, ok := streamCtx.Deadline(); !ok {
+			t.Error("expected deadline on Fetch")
+		}
+		stream.Recv()
+		if streamCtx.Err() == nil {
+			t.Error("expected fetch context to be released after the stream ended")
+		}
+	})
+
+	t.Run("subscribe stream outlives establish deadline", func(t *testing.T) {
+		var streamCtx context.Context
+		client := &EgressClient{
+			client: &mockEgressServiceClient{
+				subscribeFunc: func(ctx context.Context, in *pb.SubscribeRequest, opts ...grpc.CallOption) (pb.EgressService_SubscribeClient, error) {
+					streamCtx = ctx
+					return &mockSubscribeClient{}, nil
+				},
+			},
+		}
+		client.SetDeadlines(Deadlines{SubscribeEstablish: 10 * time.Millisecond})
+
+		if _, err := client.Subscribe(context.Background(), &domain.SubscriptionConfig{Subject: "orders"}); err != nil {
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+		time.Sleep(30 * time.Millisecond)
+		if streamCtx.Err() != nil {
+			t.Errorf("expected stream context to stay open, got %v", streamCtx.Err())
+		}
+	})
+
+	t.Run("subscribe establish timeout", func(t *testing.T) {
+		client := &EgressClient{
+			client: &mockEgressServiceClient{
+				subscribeFunc: func(ctx context.Context, in *pb.SubscribeRequest, opts ...grpc.CallOption) (pb.EgressService_SubscribeClient, error) {
+					// Block like a stream waiting for an unreachable server
+					<-ctx.Done()
+					return nil, ctx.Err()
+				},
+			},
+		}
+		client.SetDeadlines(Deadlines{SubscribeEstablish: 10 * time.Millisecond})
+
+		_, err := client.Subscribe(context.Background(), &domain.SubscriptionConfig{Subject: "orders"})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+	})
+}
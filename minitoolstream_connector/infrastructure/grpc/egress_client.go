@@ -13,11 +13,15 @@ import (
 
 // EgressClient implements domain.EgressClient using gRPC
 type EgressClient struct {
-	conn   *grpc.ClientConn
-	client pb.EgressServiceClient
+	conn      *grpc.ClientConn
+	client    pb.EgressServiceClient
+	recovery  streamRecovery
+	deadlines Deadlines
 }
 
-// NewEgressClient creates a new gRPC client for MiniToolStreamEgress
+// NewEgressClient creates a new gRPC client for MiniToolStreamEgress.
+// Subscribe and Fetch streams ended by GOAWAY or transport resets are
+// re-established transparently; see SetStreamRecovery.
 func NewEgressClient(serverAddr string, opts ...grpc.DialOption) (*EgressClient, error) {
 	if serverAddr == "" {
 		return nil, fmt.Errorf("server address is required")
@@ -31,7 +35,7 @@ func NewEgressClient(serverAddr string, opts ...grpc.DialOption) (*EgressClient,
 	}
 
 	// Connect to the server
-	conn, err := grpc.NewClient(serverAddr, opts...)
+	conn, err := grpc.NewClient(dialTarget(serverAddr), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to %s: %w", serverAddr, err)
 	}
@@ -41,6 +45,11 @@ func NewEgressClient(serverAddr string, opts ...grpc.DialOption) (*EgressClient,
 	return &EgressClient{
 		conn:   conn,
 		client: client,
+		recovery: streamRecovery{
+			maxAttempts: defaultMaxRecoveries,
+			backoff:     defaultRecoveryBackoff,
+		},
+		deadlines: DefaultDeadlines(),
 	}, nil
 }
 
@@ -59,17 +68,59 @@ func (c *EgressClient) Subscribe(ctx context.Context, config *domain.Subscriptio
 		DurableName: config.DurableName,
 	}
 
-	if config.StartSequence != nil {
-		seq := *config.StartSequence
-		req.StartSequence = &seq
+	startSequence, err := c.resolveStartSequence(ctx, config)
+	if err != nil {
+		return nil, err
 	}
+	req.StartSequence = startSequence
 
-	stream, err := c.client.Subscribe(ctx, req)
+	var stream pb.EgressService_SubscribeClient
+	streamCtx, cancel, err := establish(ctx, c.deadlines.SubscribeEstablish, func(ctx context.Context) error {
+		var err error
+		stream, err = c.client.Subscribe(ctx, req)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("subscribe failed: %w", err)
+		return nil, fmt.Errorf("subscribe failed: %w", mapError(err))
 	}
 
-	return &notificationStreamAdapter{stream: stream}, nil
+	return &notificationStreamAdapter{
+		stream:   stream,
+		ctx:      streamCtx,
+		cancel:   cancel,
+		client:   c.client,
+		req:      req,
+		recovery: c.recovery,
+	}, nil
+}
+
+// resolveStartSequence translates the subscription start options into a start sequence.
+// An explicit StartSequence wins over StartPosition. The server has no time index,
+// so time-based positions start from the earliest message and are filtered by the caller.
+func (c *EgressClient) resolveStartSequence(ctx context.Context, config *domain.SubscriptionConfig) (*uint64, error) {
+	if config.StartSequence != nil {
+		seq := *config.StartSequence
+		return &seq, nil
+	}
+
+	var seq uint64
+	switch config.StartPosition.Kind {
+	case domain.StartDefault:
+		return nil, nil
+	case domain.StartEarliest, domain.StartTime:
+		seq = 0
+	case domain.StartSequence:
+		seq = config.StartPosition.Sequence
+	case domain.StartLatest:
+		last, err := c.GetLastSequence(ctx, config.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve latest position: %w", err)
+		}
+		seq = last + 1
+	default:
+		return nil, fmt.Errorf("unknown start position kind: %d", config.StartPosition.Kind)
+	}
+	return &seq, nil
 }
 
 // Fetch fetches messages from a subject
@@ -88,12 +139,21 @@ func (c *EgressClient) Fetch(ctx context.Context, config *domain.SubscriptionCon
 		BatchSize:   config.BatchSize,
 	}
 
+	ctx, cancel := withDeadline(ctx, c.deadlines.Fetch)
 	stream, err := c.client.Fetch(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("fetch failed: %w", err)
+		cancel()
+		return nil, fmt.Errorf("fetch failed: %w", mapError(err))
 	}
 
-	return &messageStreamAdapter{stream: stream}, nil
+	return &messageStreamAdapter{
+		stream:   stream,
+		ctx:      ctx,
+		cancel:   cancel,
+		client:   c.client,
+		req:      req,
+		recovery: c.recovery,
+	}, nil
 }
 
 // GetLastSequence gets the last sequence number for a subject
@@ -106,9 +166,12 @@ func (c *EgressClient) GetLastSequence(ctx context.Context, subject string) (uin
 		Subject: subject,
 	}
 
+	ctx, cancel := withDeadline(ctx, c.deadlines.GetLastSequence)
+	defer cancel()
+
 	resp, err := c.client.GetLastSequence(ctx, req)
 	if err != nil {
-		return 0, fmt.Errorf("get last sequence failed: %w", err)
+		return 0, fmt.Errorf("get last sequence failed: %w", mapError(err))
 	}
 
 	return resp.LastSequence, nil
@@ -121,40 +184,3 @@ func (c *EgressClient) Close() error {
 	}
 	return nil
 }
-
-// notificationStreamAdapter adapts gRPC stream to domain.NotificationStream
-type notificationStreamAdapter struct {
-	stream pb.EgressService_SubscribeClient
-}
-
-func (a *notificationStreamAdapter) Recv() (*domain.Notification, error) {
-	notification, err := a.stream.Recv()
-	if err != nil {
-		return nil, err
-	}
-
-	return &domain.Notification{
-		Subject:  notification.Subject,
-		Sequence: notification.Sequence,
-	}, nil
-}
-
-// messageStreamAdapter adapts gRPC stream to domain.MessageStream
-type messageStreamAdapter struct {
-	stream pb.EgressService_FetchClient
-}
-
-func (a *messageStreamAdapter) Recv() (*domain.ReceivedMessage, error) {
-	msg, err := a.stream.Recv()
-	if err != nil {
-		return nil, err
-	}
-
-	return &domain.ReceivedMessage{
-		Subject:   msg.Subject,
-		Sequence:  msg.Sequence,
-		Data:      msg.Data,
-		Headers:   msg.Headers,
-		Timestamp: msg.Timestamp.AsTime(),
-	}, nil
-}
@@ -439,3 +439,67 @@ func TestEgressClient_Close(t *testing.T) {
 		}
 	})
 }
+
+func TestEgressClient_SubscribeStartPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *domain.SubscriptionConfig
+		expected *uint64
+	}{
+		{"default", &domain.SubscriptionConfig{Subject: "s"}, nil},
+		{"earliest", &domain.SubscriptionConfig{Subject: "s", StartPosition: domain.Earliest()}, uint64Ptr(0)},
+		{"latest", &domain.SubscriptionConfig{Subject: "s", StartPosition: domain.Latest()}, uint64Ptr(11)},
+		{"sequence", &domain.SubscriptionConfig{Subject: "s", StartPosition: domain.FromSequence(5)}, uint64Ptr(5)},
+		{"time", &domain.SubscriptionConfig{Subject: "s", StartPosition: domain.FromTime(time.Now())}, uint64Ptr(0)},
+		{"explicit sequence wins", &domain.SubscriptionConfig{Subject: "s", StartSequence: uint64Ptr(3), StartPosition: domain.Latest()}, uint64Ptr(3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *uint64
+			client := &EgressClient{
+				client: &mockEgressServiceClient{
+					subscribeFunc: func(ctx context.Context, in *pb.SubscribeRequest, opts ...grpc.CallOption) (pb.EgressService_SubscribeClient, error) {
+						got = in.StartSequence
+						return &mockSubscribeClient{}, nil
+					},
+					getLastSequenceFunc: func(ctx context.Context, in *pb.GetLastSequenceRequest, opts ...grpc.CallOption) (*pb.GetLastSequenceResponse, error) {
+						return &pb.GetLastSequenceResponse{LastSequence: 10}, nil
+					},
+				},
+			}
+
+			if _, err := client.Subscribe(context.Background(), tt.config); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			switch {
+			case tt.expected == nil && got != nil:
+				t.Errorf("expected no start sequence, got %d", *got)
+			case tt.expected != nil && got == nil:
+				t.Errorf("expected start sequence %d, got none", *tt.expected)
+			case tt.expected != nil && *got != *tt.expected:
+				t.Errorf("expected start sequence %d, got %d", *tt.expected, *got)
+			}
+		})
+	}
+
+	t.Run("latest lookup error", func(t *testing.T) {
+		client := &EgressClient{
+			client: &mockEgressServiceClient{
+				getLastSequenceFunc: func(ctx context.Context, in *pb.GetLastSequenceRequest, opts ...grpc.CallOption) (*pb.GetLastSequenceResponse, error) {
+					return nil, errors.New("unavailable")
+				},
+			},
+		}
+
+		_, err := client.Subscribe(context.Background(), &domain.SubscriptionConfig{Subject: "s", StartPosition: domain.Latest()})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func uint64Ptr(v uint64) *uint64 {
+	return &v
+}
@@ -0,0 +1,46 @@
+package grpc
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// statusError is a gRPC status error that also matches a domain error
+type statusError struct {
+	err    error
+	domain error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func (e *statusError) Unwrap() []error {
+	return []error{e.err, e.domain}
+}
+
+// mapError makes gRPC status errors match the corresponding domain error with
+// errors.Is. The status is kept, so status.FromError still works on the result.
+func mapError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	var target error
+	switch st.Code() {
+	case codes.Unavailable:
+		target = domain.ErrUnavailable
+	case codes.Unauthenticated, codes.PermissionDenied:
+		target = domain.ErrUnauthenticated
+	case codes.ResourceExhausted:
+		target = domain.ErrResourceExhausted
+	case codes.NotFound:
+		target = domain.ErrNotFound
+	default:
+		return err
+	}
+	return &statusError{err: err, domain: target}
+}
@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"unavailable", status.Error(codes.Unavailable, "connection refused"), domain.ErrUnavailable},
+		{"unauthenticated", status.Error(codes.Unauthenticated, "bad token"), domain.ErrUnauthenticated},
+		{"permission denied", status.Error(codes.PermissionDenied, "forbidden"), domain.ErrUnauthenticated},
+		{"resource exhausted", status.Error(codes.ResourceExhausted, "rate limited"), domain.ErrResourceExhausted},
+		{"not found", status.Error(codes.NotFound, "no such subject"), domain.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("publish failed: %w", mapError(tt.err))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v to match %v", err, tt.want)
+			}
+			if status.Code(err) != status.Code(tt.err) {
+				t.Errorf("expected status code %v to be kept, got %v", status.Code(tt.err), status.Code(err))
+			}
+			if err.Error() != "publish failed: "+tt.err.Error() {
+				t.Errorf("expected message to be kept, got %q", err.Error())
+			}
+		})
+	}
+
+	t.Run("unmapped errors are unchanged", func(t *testing.T) {
+		for _, err := range []error{io.EOF, errors.New("boom"), status.Error(codes.Internal, "boom")} {
+			if got := mapError(err); got != err {
+				t.Errorf("expected %v to be unchanged, got %v", err, got)
+			}
+		}
+	})
+}
+
+func TestClients_MapErrors(t *testing.T) {
+	ingress := &IngressClient{
+		client: &mockIngressServiceClient{
+			publishFunc: func(ctx context.Context, in *pb.PublishRequest, opts ...grpc.CallOption) (*pb.PublishResponse, error) {
+				return nil, status.Error(codes.Unauthenticated, "bad token")
+			},
+		},
+	}
+	_, err := ingress.Publish(context.Background(), &domain.PublishMessage{Subject: "orders"})
+	if !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("expected ErrUnauthenticated from Publish, got %v", err)
+	}
+
+	egress := &EgressClient{
+		client: &mockEgressServiceClient{
+			getLastSequenceFunc: func(ctx context.Context, in *pb.GetLastSequenceRequest, opts ...grpc.CallOption) (*pb.GetLastSequenceResponse, error) {
+				return nil, status.Error(codes.NotFound, "no such subject")
+			},
+			subscribeFunc: func(ctx context.Context, in *pb.SubscribeRequest, opts ...grpc.CallOption) (pb.EgressService_SubscribeClient, error) {
+				return resettingSubscribeClient(status.Error(codes.ResourceExhausted, "too many streams")), nil
+			},
+		},
+	}
+	if _, err := egress.GetLastSequence(context.Background(), "orders"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound from GetLastSequence, got %v", err)
+	}
+
+	stream, err := egress.Subscribe(context.Background(), &domain.SubscriptionConfig{Subject: "orders"})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, domain.ErrResourceExhausted) {
+		t.Errorf("expected ErrResourceExhausted from Recv, got %v", err)
+	}
+}
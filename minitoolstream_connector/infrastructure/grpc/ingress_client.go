@@ -13,8 +13,9 @@ import (
 
 // IngressClient implements domain.IngressClient using gRPC
 type IngressClient struct {
-	conn   *grpc.ClientConn
-	client pb.IngressServiceClient
+	conn      *grpc.ClientConn
+	client    pb.IngressServiceClient
+	deadlines Deadlines
 }
 
 // NewIngressClient creates a new gRPC client for MiniToolStreamIngress
@@ -31,7 +32,7 @@ func NewIngressClient(serverAddr string, opts ...grpc.DialOption) (*IngressClien
 	}
 
 	// Connect to the server
-	conn, err := grpc.NewClient(serverAddr, opts...)
+	conn, err := grpc.NewClient(dialTarget(serverAddr), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to %s: %w", serverAddr, err)
 	}
@@ -39,8 +40,9 @@ func NewIngressClient(serverAddr string, opts ...grpc.DialOption) (*IngressClien
 	client := pb.NewIngressServiceClient(conn)
 
 	return &IngressClient{
-		conn:   conn,
-		client: client,
+		conn:      conn,
+		client:    client,
+		deadlines: DefaultDeadlines(),
 	}, nil
 }
 
@@ -57,12 +59,15 @@ func (c *IngressClient) Publish(ctx context.Context, msg *domain.PublishMessage)
 	req := &pb.PublishRequest{
 		Subject: msg.Subject,
 		Data:    msg.Data,
-		Headers: msg.Headers,
+		Headers: msg.WireHeaders(),
 	}
 
+	ctx, cancel := withDeadline(ctx, c.deadlines.Publish)
+	defer cancel()
+
 	resp, err := c.client.Publish(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("publish failed: %w", err)
+		return nil, fmt.Errorf("publish failed: %w", mapError(err))
 	}
 
 	return &domain.PublishResult{
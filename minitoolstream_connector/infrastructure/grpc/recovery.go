@@ -0,0 +1,221 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+)
+
+// Stream recovery defaults
+const (
+	defaultMaxRecoveries   = 5
+	defaultRecoveryBackoff = 100 * time.Millisecond
+	maxRecoveryBackoff     = 5 * time.Second
+)
+
+// isTransportReset reports whether err ended a stream because the connection
+// went away (GOAWAY, connection loss) or the stream was reset by the transport,
+// as opposed to an error returned by the service itself
+func isTransportReset(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	switch st.Code() {
+	case codes.Unavailable:
+		return true
+	case codes.Internal:
+		return strings.Contains(st.Message(), "RST_STREAM")
+	default:
+		return false
+	}
+}
+
+// streamRecovery re-establishes streams ended by transport resets
+type streamRecovery struct {
+	maxAttempts int
+	backoff     time.Duration
+	clock       domain.Clock
+}
+
+// reopen calls open until it succeeds, giving up after the configured number of
+// attempts, on errors other than transport resets, or when ctx is done.
+// cause is the error that ended the previous stream.
+func (r streamRecovery) reopen(ctx context.Context, cause error, open func() error) error {
+	err := cause
+	backoff := r.backoff
+	clock := domain.ClockOrSystem(r.clock)
+	for attempt := 0; attempt < r.maxAttempts; attempt++ {
+		if !isTransportReset(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return cause
+		case <-clock.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxRecoveryBackoff {
+			backoff = maxRecoveryBackoff
+		}
+
+		if err = open(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// SetStreamRecovery configures how often Subscribe and Fetch streams ended by
+// GOAWAY or transport resets are re-established before the error is returned,
+// and the initial backoff between attempts. Zero attempts disables recovery.
+func (c *EgressClient) SetStreamRecovery(maxAttempts int, backoff time.Duration) {
+	c.recovery.maxAttempts = maxAttempts
+	c.recovery.backoff = backoff
+}
+
+// SetClock sets the clock that times the backoff between stream recovery
+// attempts (nil means domain.SystemClock)
+func (c *EgressClient) SetClock(clock domain.Clock) {
+	c.recovery.clock = clock
+}
+
+// notificationStreamAdapter adapts gRPC stream to domain.NotificationStream.
+// Streams ended by transport resets are re-established from the sequence after
+// the last delivered notification.
+type notificationStreamAdapter struct {
+	stream pb.EgressService_SubscribeClient
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	client    pb.EgressServiceClient
+	req       *pb.SubscribeRequest
+	recovery  streamRecovery
+	delivered bool
+	last      uint64
+}
+
+func (a *notificationStreamAdapter) Recv() (*domain.Notification, error) {
+	for {
+		notification, err := a.stream.Recv()
+		if err == nil {
+			a.delivered = true
+			a.last = notification.Sequence
+			return &domain.Notification{
+				Subject:  notification.Subject,
+				Sequence: notification.Sequence,
+			}, nil
+		}
+
+		if a.ctx.Err() != nil {
+			return nil, a.done(err)
+		}
+		if err := a.recovery.reopen(a.ctx, err, a.resubscribe); err != nil {
+			return nil, a.done(err)
+		}
+	}
+}
+
+// done releases the stream context once the stream has ended with err and
+// maps err to a domain error
+func (a *notificationStreamAdapter) done(err error) error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	return mapError(err)
+}
+
+// resubscribe opens a new stream starting after the last delivered sequence
+func (a *notificationStreamAdapter) resubscribe() error {
+	if a.delivered {
+		next := a.last + 1
+		a.req.StartSequence = &next
+	}
+	stream, err := a.client.Subscribe(a.ctx, a.req)
+	if err != nil {
+		return err
+	}
+	a.stream = stream
+	return nil
+}
+
+// messageStreamAdapter adapts gRPC stream to domain.MessageStream.
+// Streams ended by transport resets are fetched again for the rest of the batch,
+// skipping messages that were already delivered.
+type messageStreamAdapter struct {
+	stream pb.EgressService_FetchClient
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	client    pb.EgressServiceClient
+	req       *pb.FetchRequest
+	recovery  streamRecovery
+	delivered int32
+	last      uint64
+}
+
+func (a *messageStreamAdapter) Recv() (*domain.ReceivedMessage, error) {
+	for {
+		msg, err := a.stream.Recv()
+		if err == nil {
+			if a.delivered > 0 && msg.Sequence <= a.last {
+				continue
+			}
+			a.delivered++
+			a.last = msg.Sequence
+			return &domain.ReceivedMessage{
+				Subject:   msg.Subject,
+				Sequence:  msg.Sequence,
+				Data:      msg.Data,
+				Headers:   msg.Headers,
+				Timestamp: msg.Timestamp.AsTime(),
+			}, nil
+		}
+
+		if a.ctx.Err() != nil {
+			return nil, a.done(err)
+		}
+		if isTransportReset(err) && a.req.BatchSize > 0 && a.delivered >= a.req.BatchSize {
+			// The batch was complete; nothing is left to fetch
+			return nil, a.done(io.EOF)
+		}
+		if err := a.recovery.reopen(a.ctx, err, a.refetch); err != nil {
+			return nil, a.done(err)
+		}
+	}
+}
+
+// done releases the stream context once the stream has ended with err and
+// maps err to a domain error
+func (a *messageStreamAdapter) done(err error) error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	return mapError(err)
+}
+
+// refetch opens a new stream for the remainder of the batch
+func (a *messageStreamAdapter) refetch() error {
+	req := &pb.FetchRequest{
+		Subject:     a.req.Subject,
+		DurableName: a.req.DurableName,
+		BatchSize:   a.req.BatchSize,
+	}
+	if req.BatchSize > 0 {
+		req.BatchSize -= a.delivered
+	}
+	stream, err := a.client.Fetch(a.ctx, req)
+	if err != nil {
+		return err
+	}
+	a.stream = stream
+	return nil
+}
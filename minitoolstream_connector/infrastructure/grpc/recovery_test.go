@@ -0,0 +1,284 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	errGoAway          = status.Error(codes.Unavailable, "transport is closing")
+	domainSubscription = domain.SubscriptionConfig{Subject: "orders"}
+)
+
+// resettingSubscribeClient returns notifications, then ends with err
+func resettingSubscribeClient(err error, seqs ...uint64) *mockSubscribeClient {
+	var i int
+	return &mockSubscribeClient{recvFunc: func() (*pb.Notification, error) {
+		if i >= len(seqs) {
+			return nil, err
+		}
+		i++
+		return &pb.Notification{Subject: "orders", Sequence: seqs[i-1]}, nil
+	}}
+}
+
+func TestIsTransportReset(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"goaway", errGoAway, true},
+		{"rst stream", status.Error(codes.Internal, "stream terminated by RST_STREAM with error code: INTERNAL_ERROR"), true},
+		{"internal", status.Error(codes.Internal, "boom"), false},
+		{"not found", status.Error(codes.NotFound, "no such subject"), false},
+		{"eof", io.EOF, false},
+		{"plain error", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransportReset(tt.err); got != tt.want {
+				t.Errorf("isTransportReset(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEgressClient_SubscribeRecovery(t *testing.T) {
+	t.Run("resumes after last delivered sequence", func(t *testing.T) {
+		var (
+			mu       sync.Mutex
+			requests []*pb.SubscribeRequest
+		)
+		streams := []*mockSubscribeClient{
+			resettingSubscribeClient(errGoAway, 1, 2),
+			resettingSubscribeClient(io.EOF, 3, 4),
+		}
+		client := &EgressClient{
+			client: &mockEgressServiceClient{
+				subscribeFunc: func(ctx context.Context, in *pb.SubscribeRequest, opts ...grpc.CallOption) (pb.EgressService_SubscribeClient, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					var start *uint64
+					if in.StartSequence != nil {
+						seq := *in.StartSequence
+						start = &seq
+					}
+					requests = append(requests, &pb.SubscribeRequest{Subject: in.Subject, StartSequence: start})
+					stream := streams[0]
+					streams = streams[1:]
+					return stream, nil
+				},
+			},
+		}
+		client.SetStreamRecovery(3, time.Millisecond)
+
+		stream, err := client.Subscribe(context.Background(), &domainSubscription)
+		if err != nil {
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+
+		var got []uint64
+		for {
+			n, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got = append(got, n.Sequence)
+		}
+
+		if len(got) != 4 || got[0] != 1 || got[3] != 4 {
+			t.Errorf("expected sequences 1-4, got %v", got)
+		}
+		if len(requests) != 2 {
+			t.Fatalf("expected 2 subscribe calls, got %d", len(requests))
+		}
+		if requests[0].StartSequence != nil {
+			t.Error("expected first subscription to use the server default")
+		}
+		if requests[1].StartSequence == nil || *requests[1].StartSequence != 3 {
+			t.Errorf("expected resubscription from sequence 3, got %v", requests[1].StartSequence)
+		}
+	})
+
+	t.Run("service errors are returned", func(t *testing.T) {
+		calls := 0
+		client := &EgressClient{
+			client: &mockEgressServiceClient{
+				subscribeFunc: func(ctx context.Context, in *pb.SubscribeRequest, opts ...grpc.CallOption) (pb.EgressService_SubscribeClient, error) {
+					calls++
+					return resettingSubscribeClient(status.Error(codes.PermissionDenied, "denied")), nil
+				},
+			},
+		}
+		client.SetStreamRecovery(3, time.Millisecond)
+
+		stream, _ := client.Subscribe(context.Background(), &domainSubscription)
+		if _, err := stream.Recv(); status.Code(err) != codes.PermissionDenied {
+			t.Errorf("expected PermissionDenied, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("expected no resubscription, got %d calls", calls)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		calls := 0
+		client := &EgressClient{
+			client: &mockEgressServiceClient{
+				subscribeFunc: func(ctx context.Context, in *pb.SubscribeRequest, opts ...grpc.CallOption) (pb.EgressService_SubscribeClient, error) {
+					calls++
+					if calls > 1 {
+						return nil, errGoAway
+					}
+					return resettingSubscribeClient(errGoAway), nil
+				},
+			},
+		}
+		client.SetStreamRecovery(2, time.Millisecond)
+
+		stream, _ := client.Subscribe(context.Background(), &domainSubscription)
+		if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+			t.Errorf("expected Unavailable, got %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("expected 1 subscription and 2 attempts, got %d calls", calls)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		calls := 0
+		client := &EgressClient{
+			client: &mockEgressServiceClient{
+				subscribeFunc: func(ctx context.Context, in *pb.SubscribeRequest, opts ...grpc.CallOption) (pb.EgressService_SubscribeClient, error) {
+					calls++
+					return resettingSubscribeClient(errGoAway, 1), nil
+				},
+			},
+		}
+
+		stream, _ := client.Subscribe(context.Background(), &domainSubscription)
+		stream.Recv()
+		if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+			t.Errorf("expected Unavailable, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("expected no resubscription, got %d calls", calls)
+		}
+	})
+
+	t.Run("backs off on the clock", func(t *testing.T) {
+		var calls atomic.Int32
+		client := &EgressClient{
+			client: &mockEgressServiceClient{
+				subscribeFunc: func(ctx context.Context, in *pb.SubscribeRequest, opts ...grpc.CallOption) (pb.EgressService_SubscribeClient, error) {
+					if calls.Add(1) == 1 {
+						return resettingSubscribeClient(errGoAway), nil
+					}
+					return resettingSubscribeClient(io.EOF, 1), nil
+				},
+			},
+		}
+		clock := connectortest.NewFakeClock(time.Time{})
+		client.SetStreamRecovery(3, time.Minute)
+		client.SetClock(clock)
+
+		stream, _ := client.Subscribe(context.Background(), &domainSubscription)
+		done := make(chan error, 1)
+		go func() {
+			_, err := stream.Recv()
+			done <- err
+		}()
+
+		clock.WaitForWaiters(t, 1, 2*time.Second)
+		if n := calls.Load(); n != 1 {
+			t.Errorf("expected no resubscription before the backoff elapsed, got %d calls", n)
+		}
+		clock.Advance(time.Minute)
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("stream was not recovered after the clock advanced")
+		}
+	})
+}
+
+func TestEgressClient_FetchRecovery(t *testing.T) {
+	message := func(seq uint64) *pb.Message {
+		return &pb.Message{Subject: "orders", Sequence: seq, Timestamp: timestamppb.Now()}
+	}
+
+	var batchSizes []int32
+	streams := []*mockFetchClient{
+		{recvFunc: func() func() (*pb.Message, error) {
+			seqs := []uint64{1, 2}
+			return func() (*pb.Message, error) {
+				if len(seqs) == 0 {
+					return nil, errGoAway
+				}
+				seq := seqs[0]
+				seqs = seqs[1:]
+				return message(seq), nil
+			}
+		}()},
+		// The server redelivers message 2 after the reset
+		{messages: []*pb.Message{message(2), message(3), message(4)}},
+	}
+	client := &EgressClient{
+		client: &mockEgressServiceClient{
+			fetchFunc: func(ctx context.Context, in *pb.FetchRequest, opts ...grpc.CallOption) (pb.EgressService_FetchClient, error) {
+				batchSizes = append(batchSizes, in.BatchSize)
+				stream := streams[0]
+				streams = streams[1:]
+				return stream, nil
+			},
+		},
+	}
+	client.SetStreamRecovery(3, time.Millisecond)
+
+	config := domainSubscription
+	config.BatchSize = 4
+	stream, err := client.Fetch(context.Background(), &config)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	var got []uint64
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, msg.Sequence)
+	}
+
+	if len(got) != 4 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("expected sequences 1-4 without duplicates, got %v", got)
+	}
+	if len(batchSizes) != 2 || batchSizes[0] != 4 || batchSizes[1] != 2 {
+		t.Errorf("expected batch sizes [4 2], got %v", batchSizes)
+	}
+}
@@ -0,0 +1,14 @@
+package grpc
+
+import "strings"
+
+// dialTarget returns the gRPC target for a server address. Unix socket
+// addresses (unix:///path/to.sock, unix:relative.sock, unix-abstract:name) are
+// resolved by gRPC's unix resolver; a bare absolute path is treated as a unix
+// socket path, for sidecars that share a socket with the stream services.
+func dialTarget(serverAddr string) string {
+	if strings.HasPrefix(serverAddr, "/") {
+		return "unix://" + serverAddr
+	}
+	return serverAddr
+}
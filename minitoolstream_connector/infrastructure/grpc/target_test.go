@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	pb "github.com/moroshma/MiniToolStreamConnector/model"
+	"google.golang.org/grpc"
+)
+
+type unixIngressServer struct {
+	pb.UnimplementedIngressServiceServer
+}
+
+func (s *unixIngressServer) Publish(ctx context.Context, req *pb.PublishRequest) (*pb.PublishResponse, error) {
+	return &pb.PublishResponse{Sequence: 7}, nil
+}
+
+type unixEgressServer struct {
+	pb.UnimplementedEgressServiceServer
+}
+
+func (s *unixEgressServer) GetLastSequence(ctx context.Context, req *pb.GetLastSequenceRequest) (*pb.GetLastSequenceResponse, error) {
+	return &pb.GetLastSequenceResponse{LastSequence: 42}, nil
+}
+
+func TestDialTarget(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"localhost:9090", "localhost:9090"},
+		{"unix:///var/run/stream.sock", "unix:///var/run/stream.sock"},
+		{"/var/run/stream.sock", "unix:///var/run/stream.sock"},
+	}
+	for _, tt := range tests {
+		if got := dialTarget(tt.addr); got != tt.want {
+			t.Errorf("dialTarget(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestClients_UnixSocket(t *testing.T) {
+	// Keep the path short; socket paths are limited to ~100 bytes
+	dir, err := os.MkdirTemp("", "mts")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "s.sock")
+
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterIngressServiceServer(server, &unixIngressServer{})
+	pb.RegisterEgressServiceServer(server, &unixEgressServer{})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	for _, addr := range []string{"unix://" + socket, socket} {
+		t.Run(addr, func(t *testing.T) {
+			ingress, err := NewIngressClient(addr)
+			if err != nil {
+				t.Fatalf("NewIngressClient failed: %v", err)
+			}
+			defer ingress.Close()
+
+			result, err := ingress.Publish(context.Background(), &domain.PublishMessage{Subject: "orders", Data: []byte("x")})
+			if err != nil {
+				t.Fatalf("Publish failed: %v", err)
+			}
+			if result.Sequence != 7 {
+				t.Errorf("expected sequence 7, got %d", result.Sequence)
+			}
+
+			egress, err := NewEgressClient(addr)
+			if err != nil {
+				t.Fatalf("NewEgressClient failed: %v", err)
+			}
+			defer egress.Close()
+
+			last, err := egress.GetLastSequence(context.Background(), "orders")
+			if err != nil {
+				t.Fatalf("GetLastSequence failed: %v", err)
+			}
+			if last != 42 {
+				t.Errorf("expected last sequence 42, got %d", last)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Audit actions
+const (
+	AuditPublish = "publish"
+	AuditConsume = "consume"
+)
+
+// AuditRecord is one entry of an audit trail: who published or consumed
+// which message, when and from which host. The payload itself is not
+// recorded, only its size and SHA-256 digest.
+type AuditRecord struct {
+	Time           time.Time `json:"time"`
+	Action         string    `json:"action"`
+	Actor          string    `json:"actor"`
+	Host           string    `json:"host"`
+	Subject        string    `json:"subject,omitempty"`
+	Sequence       uint64    `json:"sequence,omitempty"`
+	Size           int       `json:"size"`
+	Digest         string    `json:"digest,omitempty"`
+	CorrelationID  string    `json:"correlation_id,omitempty"`
+	IdempotencyKey string    `json:"idempotency_key,omitempty"`
+	// Error is the server error of a failed publish
+	Error string `json:"error,omitempty"`
+}
+
+// AuditSink stores audit records. Implementations only ever append.
+type AuditSink interface {
+	Append(ctx context.Context, record *AuditRecord) error
+}
+
+// AuditFile appends audit records as JSON lines to a file opened in append
+// mode, syncing every record to disk before Append returns
+type AuditFile struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenAuditFile opens the audit log at path, creating it if needed
+func OpenAuditFile(path string) (*AuditFile, error) {
+	if path == "" {
+		return nil, fmt.Errorf("audit file path is required")
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file %s: %w", path, err)
+	}
+	return &AuditFile{file: file}, nil
+}
+
+// Append writes the record as one line
+func (a *AuditFile) Append(ctx context.Context, record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err := a.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit record: %w", err)
+	}
+	if err := a.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit file: %w", err)
+	}
+	return nil
+}
+
+// Close closes the audit file
+func (a *AuditFile) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
+
+// AuditSubject publishes audit records as JSON messages to a subject. It uses
+// the client directly, so auditing a publisher never audits its own records.
+type AuditSubject struct {
+	client  domain.IngressClient
+	subject string
+}
+
+// NewAuditSubject creates a sink publishing to subject with client
+func NewAuditSubject(client domain.IngressClient, subject string) (*AuditSubject, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+
+	if subject == "" {
+		return nil, fmt.Errorf("subject is required")
+	}
+
+	return &AuditSubject{client: client, subject: subject}, nil
+}
+
+// Append publishes the record
+func (a *AuditSubject) Append(ctx context.Context, record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	result, err := a.client.Publish(ctx, &domain.PublishMessage{
+		Subject: a.subject,
+		Data:    data,
+		Headers: map[string]string{"content-type": "application/json"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish audit record: %w", err)
+	}
+	if result.StatusCode != 0 {
+		return fmt.Errorf("failed to publish audit record: %s", result.ErrorMessage)
+	}
+	return nil
+}
+
+// AuditConfig represents configuration for the audit handlers
+type AuditConfig struct {
+	Sink AuditSink
+	// Actor identifies who publishes or consumes (default the OS user)
+	Actor string
+	// Host identifies where from (default the hostname)
+	Host  string
+	Clock domain.Clock
+}
+
+// auditor builds audit records for both audit handlers
+type auditor struct {
+	sink  AuditSink
+	actor string
+	host  string
+	clock domain.Clock
+}
+
+// newAuditor validates the config and fills in the defaults
+func newAuditor(config *AuditConfig) (*auditor, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Sink == nil {
+		return nil, fmt.Errorf("audit sink is required")
+	}
+
+	a := &auditor{
+		sink:  config.Sink,
+		actor: config.Actor,
+		host:  config.Host,
+		clock: domain.ClockOrSystem(config.Clock),
+	}
+
+	if a.actor == "" {
+		a.actor = "unknown"
+		if u, err := user.Current(); err == nil {
+			a.actor = u.Username
+		}
+	}
+	if a.host == "" {
+		a.host = "unknown"
+		if h, err := os.Hostname(); err == nil {
+			a.host = h
+		}
+	}
+
+	return a, nil
+}
+
+// record creates a record of the action stamped with the actor, host and time
+func (a *auditor) record(action, subject string, data []byte, headers map[string]string) *AuditRecord {
+	record := &AuditRecord{
+		Time:           a.clock.Now().UTC(),
+		Action:         action,
+		Actor:          a.actor,
+		Host:           a.host,
+		Subject:        subject,
+		Size:           len(data),
+		CorrelationID:  headers[domain.HeaderCorrelationID],
+		IdempotencyKey: headers[domain.HeaderIdempotencyKey],
+	}
+	if data != nil {
+		sum := sha256.Sum256(data)
+		record.Digest = hex.EncodeToString(sum[:])
+	}
+	return record
+}
+
+// AuditResultHandler appends a record of every publish result to an audit sink.
+// It implements domain.MessageResultHandler, so records carry the subject and
+// headers of the published message.
+type AuditResultHandler struct {
+	auditor *auditor
+}
+
+// NewAuditResultHandler creates a new audit result handler
+func NewAuditResultHandler(config *AuditConfig) (*AuditResultHandler, error) {
+	a, err := newAuditor(config)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditResultHandler{auditor: a}, nil
+}
+
+// Handle records a result whose message is unknown
+func (h *AuditResultHandler) Handle(ctx context.Context, result *domain.PublishResult) error {
+	return h.HandleMessage(ctx, nil, result)
+}
+
+// HandleMessage records the published message and its result
+func (h *AuditResultHandler) HandleMessage(ctx context.Context, msg *domain.PublishMessage, result *domain.PublishResult) error {
+	if result == nil {
+		return fmt.Errorf("result cannot be nil")
+	}
+
+	var record *AuditRecord
+	if msg != nil {
+		record = h.auditor.record(AuditPublish, msg.Subject, msg.Data, msg.WireHeaders())
+	} else {
+		record = h.auditor.record(AuditPublish, "", nil, nil)
+	}
+	record.Sequence = result.Sequence
+	if result.StatusCode != 0 {
+		record.Error = result.ErrorMessage
+	}
+
+	return h.auditor.sink.Append(ctx, record)
+}
+
+// AuditHandler appends a record of every consumed message to an audit sink.
+// Chain it before the handlers processing the message; an append failure
+// fails the message so it is not consumed without a record.
+type AuditHandler struct {
+	auditor *auditor
+}
+
+// NewAuditHandler creates a new audit message handler
+func NewAuditHandler(config *AuditConfig) (*AuditHandler, error) {
+	a, err := newAuditor(config)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditHandler{auditor: a}, nil
+}
+
+// Handle records the consumed message
+func (h *AuditHandler) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	record := h.auditor.record(AuditConsume, msg.Subject, msg.Data, msg.Headers)
+	record.Sequence = msg.Sequence
+	return h.auditor.sink.Append(ctx, record)
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/localbroker"
+)
+
+// fixedClock always reports the same time
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time                         { return c.now }
+func (c fixedClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// memorySink collects audit records
+type memorySink struct {
+	records []*AuditRecord
+}
+
+func (s *memorySink) Append(ctx context.Context, record *AuditRecord) error {
+	s.records = append(s.records, record)
+	return nil
+}
+
+func TestNewAuditHandlers_Validation(t *testing.T) {
+	if _, err := NewAuditHandler(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+	if _, err := NewAuditResultHandler(&AuditConfig{}); err == nil {
+		t.Error("expected error for missing sink")
+	}
+
+	h, err := NewAuditHandler(&AuditConfig{Sink: &memorySink{}})
+	if err != nil {
+		t.Fatalf("NewAuditHandler failed: %v", err)
+	}
+	if h.auditor.actor == "" || h.auditor.host == "" {
+		t.Errorf("expected actor and host defaults, got %q and %q", h.auditor.actor, h.auditor.host)
+	}
+}
+
+func TestAuditResultHandler(t *testing.T) {
+	sink := &memorySink{}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	h, _ := NewAuditResultHandler(&AuditConfig{Sink: sink, Actor: "billing-svc", Host: "node-1", Clock: fixedClock{now}})
+
+	msg := &domain.PublishMessage{
+		Subject:  "payments",
+		Data:     []byte("abc"),
+		Headers:  map[string]string{domain.HeaderCorrelationID: "req-1"},
+		DedupKey: "key-1",
+	}
+	if err := h.HandleMessage(context.Background(), msg, &domain.PublishResult{Sequence: 42}); err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+	if err := h.HandleMessage(context.Background(), msg, &domain.PublishResult{StatusCode: 1, ErrorMessage: "quota exceeded"}); err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+
+	var _ domain.MessageResultHandler = h
+	if len(sink.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(sink.records))
+	}
+	want := AuditRecord{
+		Time:           now,
+		Action:         AuditPublish,
+		Actor:          "billing-svc",
+		Host:           "node-1",
+		Subject:        "payments",
+		Sequence:       42,
+		Size:           3,
+		Digest:         "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		CorrelationID:  "req-1",
+		IdempotencyKey: "key-1",
+	}
+	if *sink.records[0] != want {
+		t.Errorf("unexpected record:\n got %+v\nwant %+v", *sink.records[0], want)
+	}
+	if sink.records[1].Error != "quota exceeded" {
+		t.Errorf("expected the server error to be recorded, got %+v", sink.records[1])
+	}
+}
+
+func TestAuditHandler_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	os.WriteFile(path, []byte(`{"action":"earlier"}`+"\n"), 0600)
+
+	file, err := OpenAuditFile(path)
+	if err != nil {
+		t.Fatalf("OpenAuditFile failed: %v", err)
+	}
+	h, _ := NewAuditHandler(&AuditConfig{Sink: file, Actor: "reporting"})
+
+	for seq := uint64(1); seq <= 2; seq++ {
+		msg := &domain.ReceivedMessage{Subject: "orders", Sequence: seq, Data: []byte("x")}
+		if err := h.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	f, _ := os.Open(path)
+	defer f.Close()
+	var records []AuditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid audit line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+
+	if len(records) != 3 || records[0].Action != "earlier" {
+		t.Fatalf("expected records to be appended to the existing log, got %+v", records)
+	}
+	if r := records[2]; r.Action != AuditConsume || r.Actor != "reporting" || r.Subject != "orders" || r.Sequence != 2 {
+		t.Errorf("unexpected record: %+v", r)
+	}
+}
+
+func TestAuditSubject(t *testing.T) {
+	if _, err := NewAuditSubject(nil, "audit"); err == nil {
+		t.Error("expected error for nil client")
+	}
+
+	broker, err := localbroker.New(&localbroker.Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	sink, err := NewAuditSubject(broker, "audit")
+	if err != nil {
+		t.Fatalf("NewAuditSubject failed: %v", err)
+	}
+	h, _ := NewAuditHandler(&AuditConfig{Sink: sink, Actor: "a", Host: "h"})
+	if err := h.Handle(context.Background(), &domain.ReceivedMessage{Subject: "orders", Sequence: 7}); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	stream, err := broker.Fetch(context.Background(), &domain.SubscriptionConfig{Subject: "audit", DurableName: "test"})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	msg, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("expected an audit message, got %v", err)
+	}
+	var record AuditRecord
+	if err := json.Unmarshal(msg.Data, &record); err != nil {
+		t.Fatalf("invalid audit message: %v", err)
+	}
+	if record.Subject != "orders" || record.Sequence != 7 || record.Action != AuditConsume {
+		t.Errorf("unexpected record: %+v", record)
+	}
+}
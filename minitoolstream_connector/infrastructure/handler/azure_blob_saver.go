@@ -0,0 +1,352 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// azureAPIVersion is the Blob service REST API version used for requests
+const azureAPIVersion = "2021-08-06"
+
+// AzureBlobSaver saves message data as block blobs in an Azure Blob Storage
+// container. Message headers are stored as blob metadata.
+type AzureBlobSaver struct {
+	accountURL      *url.URL
+	container       string
+	accountName     string
+	accountKey      []byte
+	sasToken        url.Values
+	createContainer bool
+	blobName        func(msg *domain.ReceivedMessage) string
+	blockSize       int
+	client          *http.Client
+	logger          Logger
+
+	mu             sync.Mutex
+	containerReady bool
+}
+
+// AzureBlobSaverConfig represents configuration for AzureBlobSaver
+type AzureBlobSaverConfig struct {
+	// AccountURL is the blob endpoint, e.g. https://myaccount.blob.core.windows.net
+	// or http://127.0.0.1:10000/devstoreaccount1 for Azurite
+	AccountURL string
+	Container  string
+	// AccountName and AccountKey authenticate with Shared Key
+	AccountName string
+	AccountKey  string
+	// SASToken authenticates with a shared access signature instead
+	SASToken string
+	// CreateContainer creates the container on first use if it does not exist
+	CreateContainer bool
+	// BlobName returns the blob name of a message (default subject/sequence plus
+	// an extension based on the content type)
+	BlobName func(msg *domain.ReceivedMessage) string
+	// BlockSize is the size of staged blocks; smaller blobs are uploaded in a
+	// single request (default 4 MiB)
+	BlockSize int
+	Client    *http.Client
+	Logger    Logger
+}
+
+// NewAzureBlobSaver creates a new Azure Blob Storage saver handler
+func NewAzureBlobSaver(config *AzureBlobSaverConfig) (*AzureBlobSaver, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.AccountURL == "" {
+		return nil, fmt.Errorf("account URL is required")
+	}
+	accountURL, err := url.Parse(strings.TrimSuffix(config.AccountURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid account URL: %w", err)
+	}
+
+	if config.Container == "" {
+		return nil, fmt.Errorf("container is required")
+	}
+
+	var accountKey []byte
+	var sasToken url.Values
+	switch {
+	case config.AccountKey != "":
+		if config.AccountName == "" {
+			return nil, fmt.Errorf("account name is required with an account key")
+		}
+		if accountKey, err = base64.StdEncoding.DecodeString(config.AccountKey); err != nil {
+			return nil, fmt.Errorf("invalid account key: %w", err)
+		}
+	case config.SASToken != "":
+		if sasToken, err = url.ParseQuery(strings.TrimPrefix(config.SASToken, "?")); err != nil {
+			return nil, fmt.Errorf("invalid SAS token: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("account key or SAS token is required")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = &defaultLogger{}
+	}
+
+	blobName := config.BlobName
+	if blobName == nil {
+		blobName = defaultBlobName
+	}
+
+	blockSize := config.BlockSize
+	if blockSize <= 0 {
+		blockSize = 4 << 20
+	}
+
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+
+	return &AzureBlobSaver{
+		accountURL:      accountURL,
+		container:       config.Container,
+		accountName:     config.AccountName,
+		accountKey:      accountKey,
+		sasToken:        sasToken,
+		createContainer: config.CreateContainer,
+		blobName:        blobName,
+		blockSize:       blockSize,
+		client:          client,
+		logger:          logger,
+	}, nil
+}
+
+// defaultBlobName names blobs subject/sequence with an extension based on the content type
+func defaultBlobName(msg *domain.ReceivedMessage) string {
+	return fmt.Sprintf("%s/%d%s", msg.Subject, msg.Sequence, getFileExtension(msg.Headers["content-type"]))
+}
+
+// Handle uploads the message data as a block blob
+func (h *AzureBlobSaver) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	if err := h.ensureContainer(ctx); err != nil {
+		return err
+	}
+
+	name := h.blobName(msg)
+	headers := h.blobHeaders(msg)
+
+	if len(msg.Data) <= h.blockSize {
+		headers.Set("x-ms-blob-type", "BlockBlob")
+		if err := h.do(ctx, http.MethodPut, name, nil, headers, msg.Data, http.StatusCreated); err != nil {
+			return fmt.Errorf("failed to upload blob %s: %w", name, err)
+		}
+	} else if err := h.uploadBlocks(ctx, name, headers, msg.Data); err != nil {
+		return fmt.Errorf("failed to upload blob %s: %w", name, err)
+	}
+
+	h.logger.Printf("   ✓ Saved to Azure blob: %s/%s (%d bytes)", h.container, name, len(msg.Data))
+	return nil
+}
+
+// uploadBlocks stages data as blocks and commits them
+func (h *AzureBlobSaver) uploadBlocks(ctx context.Context, name string, headers http.Header, data []byte) error {
+	var list bytes.Buffer
+	list.WriteString(`<?xml version="1.0" encoding="utf-8"?><BlockList>`)
+
+	for i := 0; i*h.blockSize < len(data); i++ {
+		end := (i + 1) * h.blockSize
+		if end > len(data) {
+			end = len(data)
+		}
+		// Block IDs of one blob must all have the same length
+		id := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("block-%06d", i)))
+		query := url.Values{"comp": {"block"}, "blockid": {id}}
+		if err := h.do(ctx, http.MethodPut, name, query, nil, data[i*h.blockSize:end], http.StatusCreated); err != nil {
+			return fmt.Errorf("block %d: %w", i, err)
+		}
+		list.WriteString("<Latest>" + id + "</Latest>")
+	}
+
+	list.WriteString("</BlockList>")
+	headers.Set("Content-Type", "application/xml")
+	return h.do(ctx, http.MethodPut, name, url.Values{"comp": {"blocklist"}}, headers, list.Bytes(), http.StatusCreated)
+}
+
+// ensureContainer creates the container once if CreateContainer is set
+func (h *AzureBlobSaver) ensureContainer(ctx context.Context) error {
+	if !h.createContainer {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.containerReady {
+		return nil
+	}
+
+	err := h.do(ctx, http.MethodPut, "", url.Values{"restype": {"container"}}, nil, nil, http.StatusCreated, http.StatusConflict)
+	if err != nil {
+		return fmt.Errorf("failed to create container %s: %w", h.container, err)
+	}
+	h.containerReady = true
+	return nil
+}
+
+// blobHeaders returns the content type and metadata headers of a blob
+func (h *AzureBlobSaver) blobHeaders(msg *domain.ReceivedMessage) http.Header {
+	// Metadata keys are set directly so their case is kept
+	headers := make(http.Header)
+	if contentType := msg.Headers["content-type"]; contentType != "" {
+		headers.Set("x-ms-blob-content-type", contentType)
+	}
+	for k, v := range msg.Headers {
+		if name := metadataName(k); name != "" && k != "content-type" {
+			headers["x-ms-meta-"+name] = []string{metadataValue(v)}
+		}
+	}
+	headers["x-ms-meta-subject"] = []string{msg.Subject}
+	headers["x-ms-meta-sequence"] = []string{strconv.FormatUint(msg.Sequence, 10)}
+	return headers
+}
+
+// metadataName maps a header name to a valid metadata name (a C# identifier)
+func metadataName(name string) string {
+	var b strings.Builder
+	for i, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// metadataValue drops characters that cannot be sent in a header value
+func metadataValue(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+// do sends a request for the container, or a blob of it when name is set, and
+// checks the response status
+func (h *AzureBlobSaver) do(ctx context.Context, method, name string, query url.Values, headers http.Header, body []byte, expected ...int) error {
+	u := *h.accountURL
+	u.Path += "/" + h.container
+	u.RawPath = ""
+	if name != "" {
+		u.Path += "/" + name
+	}
+
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	for k, v := range h.sasToken {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	if h.accountKey != nil {
+		req.Header.Set("Authorization", "SharedKey "+h.accountName+":"+h.sign(req, len(body)))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("blob service returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+}
+
+// sign returns the Shared Key signature of a request
+func (h *AzureBlobSaver) sign(req *http.Request, contentLength int) string {
+	mac := hmac.New(sha256.New, h.accountKey)
+	mac.Write([]byte(stringToSign(req, h.accountName, contentLength)))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// stringToSign builds the Shared Key string to sign of a request
+func stringToSign(req *http.Request, accountName string, contentLength int) string {
+	length := ""
+	if contentLength > 0 {
+		length = strconv.Itoa(contentLength)
+	}
+
+	var b strings.Builder
+	b.WriteString(req.Method + "\n")
+	for _, header := range []string{"Content-Encoding", "Content-Language"} {
+		b.WriteString(req.Header.Get(header) + "\n")
+	}
+	b.WriteString(length + "\n")
+	for _, header := range []string{"Content-MD5", "Content-Type", "Date", "If-Modified-Since", "If-Match", "If-None-Match", "If-Unmodified-Since", "Range"} {
+		b.WriteString(req.Header.Get(header) + "\n")
+	}
+
+	// Canonicalized headers
+	msHeaders := make(map[string]string)
+	var names []string
+	for name, values := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") && len(values) > 0 {
+			msHeaders[lower] = strings.TrimSpace(values[0])
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(name + ":" + msHeaders[name] + "\n")
+	}
+
+	// Canonicalized resource
+	b.WriteString("/" + accountName + req.URL.EscapedPath())
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		b.WriteString("\n" + strings.ToLower(name) + ":" + strings.Join(values, ","))
+	}
+	return b.String()
+}
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+const (
+	testAccountName = "devstoreaccount1"
+	testAccountKey  = "a2V5LWZvci10ZXN0cw=="
+)
+
+// fakeBlobService is a minimal Blob service that checks Shared Key signatures
+type fakeBlobService struct {
+	mu         sync.Mutex
+	containers map[string]bool
+	blobs      map[string][]byte
+	metadata   map[string]http.Header
+	blocks     map[string][]byte
+	requests   []string
+}
+
+func newFakeBlobService() *fakeBlobService {
+	return &fakeBlobService{
+		containers: make(map[string]bool),
+		blobs:      make(map[string][]byte),
+		metadata:   make(map[string]http.Header),
+		blocks:     make(map[string][]byte),
+	}
+}
+
+func (f *fakeBlobService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	query := r.URL.Query()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path+" "+query.Get("comp")+query.Get("restype"))
+
+	if query.Get("sig") == "" {
+		key, _ := base64.StdEncoding.DecodeString(testAccountKey)
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(stringToSign(r, testAccountName, len(body))))
+		want := "SharedKey " + testAccountName + ":" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		if r.Header.Get("Authorization") != want {
+			http.Error(w, "AuthenticationFailed", http.StatusForbidden)
+			return
+		}
+	}
+
+	// Path-style URL: /account/container[/blob]
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"+testAccountName+"/"), "/", 2)
+	container := parts[0]
+
+	if query.Get("restype") == "container" {
+		if f.containers[container] {
+			http.Error(w, "ContainerAlreadyExists", http.StatusConflict)
+			return
+		}
+		f.containers[container] = true
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	if !f.containers[container] {
+		http.Error(w, "ContainerNotFound", http.StatusNotFound)
+		return
+	}
+	blob := r.URL.Path
+
+	switch query.Get("comp") {
+	case "block":
+		f.blocks[blob+"#"+query.Get("blockid")] = body
+	case "blocklist":
+		var list struct {
+			Latest []string `xml:"Latest"`
+		}
+		if err := xml.Unmarshal(body, &list); err != nil {
+			http.Error(w, "InvalidXmlDocument", http.StatusBadRequest)
+			return
+		}
+		var data []byte
+		for _, id := range list.Latest {
+			data = append(data, f.blocks[blob+"#"+id]...)
+		}
+		f.blobs[blob] = data
+		f.metadata[blob] = r.Header.Clone()
+	default:
+		if r.Header.Get("x-ms-blob-type") != "BlockBlob" {
+			http.Error(w, "MissingRequiredHeader", http.StatusBadRequest)
+			return
+		}
+		f.blobs[blob] = body
+		f.metadata[blob] = r.Header.Clone()
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func TestNewAzureBlobSaver(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *AzureBlobSaverConfig
+	}{
+		{"nil config", nil},
+		{"missing URL", &AzureBlobSaverConfig{Container: "c", SASToken: "sig=x"}},
+		{"missing container", &AzureBlobSaverConfig{AccountURL: "http://localhost", SASToken: "sig=x"}},
+		{"missing credentials", &AzureBlobSaverConfig{AccountURL: "http://localhost", Container: "c"}},
+		{"key without account", &AzureBlobSaverConfig{AccountURL: "http://localhost", Container: "c", AccountKey: testAccountKey}},
+		{"invalid key", &AzureBlobSaverConfig{AccountURL: "http://localhost", Container: "c", AccountName: "a", AccountKey: "not base64!"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAzureBlobSaver(tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestAzureBlobSaver_Handle(t *testing.T) {
+	newSaver := func(t *testing.T, service *fakeBlobService, config AzureBlobSaverConfig) *AzureBlobSaver {
+		t.Helper()
+		srv := httptest.NewServer(service)
+		t.Cleanup(srv.Close)
+
+		config.AccountURL = srv.URL + "/" + testAccountName
+		config.Container = "messages"
+		if config.SASToken == "" {
+			config.AccountName = testAccountName
+			config.AccountKey = testAccountKey
+		}
+		config.Logger = &testLogger{}
+		saver, err := NewAzureBlobSaver(&config)
+		if err != nil {
+			t.Fatalf("NewAzureBlobSaver failed: %v", err)
+		}
+		return saver
+	}
+
+	msg := &domain.ReceivedMessage{
+		Subject:  "images.raw",
+		Sequence: 7,
+		Data:     []byte("png-bytes"),
+		Headers:  map[string]string{"content-type": "image/png", "camera-id": "front door", "2fa": "yes"},
+	}
+
+	t.Run("single upload with metadata", func(t *testing.T) {
+		service := newFakeBlobService()
+		saver := newSaver(t, service, AzureBlobSaverConfig{CreateContainer: true})
+
+		if err := saver.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+
+		blob := "/" + testAccountName + "/messages/images.raw/7.png"
+		if string(service.blobs[blob]) != "png-bytes" {
+			t.Fatalf("expected blob %s, got %v", blob, service.blobs)
+		}
+		meta := service.metadata[blob]
+		want := map[string]string{
+			"X-Ms-Blob-Content-Type": "image/png",
+			"X-Ms-Meta-Camera_id":    "front door",
+			"X-Ms-Meta-_2fa":         "yes",
+			"X-Ms-Meta-Subject":      "images.raw",
+			"X-Ms-Meta-Sequence":     "7",
+		}
+		for k, v := range want {
+			if meta.Get(k) != v {
+				t.Errorf("expected %s=%q, got %q", k, v, meta.Get(k))
+			}
+		}
+	})
+
+	t.Run("container is created once", func(t *testing.T) {
+		service := newFakeBlobService()
+		service.containers["messages"] = true
+		saver := newSaver(t, service, AzureBlobSaverConfig{CreateContainer: true})
+
+		for i := 0; i < 2; i++ {
+			if err := saver.Handle(context.Background(), msg); err != nil {
+				t.Fatalf("Handle failed: %v", err)
+			}
+		}
+		creates := 0
+		for _, req := range service.requests {
+			if strings.HasSuffix(req, "container") {
+				creates++
+			}
+		}
+		if creates != 1 {
+			t.Errorf("expected one container creation, got %d in %v", creates, service.requests)
+		}
+	})
+
+	t.Run("missing container without auto-creation", func(t *testing.T) {
+		saver := newSaver(t, newFakeBlobService(), AzureBlobSaverConfig{})
+		if err := saver.Handle(context.Background(), msg); err == nil {
+			t.Error("expected error for missing container")
+		}
+	})
+
+	t.Run("large blobs are uploaded in blocks", func(t *testing.T) {
+		service := newFakeBlobService()
+		saver := newSaver(t, service, AzureBlobSaverConfig{CreateContainer: true, BlockSize: 4})
+
+		large := &domain.ReceivedMessage{Subject: "docs", Sequence: 1, Data: []byte("0123456789")}
+		if err := saver.Handle(context.Background(), large); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+
+		blob := "/" + testAccountName + "/messages/docs/1"
+		if !bytes.Equal(service.blobs[blob], large.Data) {
+			t.Errorf("expected reassembled blob, got %q", service.blobs[blob])
+		}
+		if len(service.blocks) != 3 {
+			t.Errorf("expected 3 blocks, got %d", len(service.blocks))
+		}
+	})
+
+	t.Run("SAS token", func(t *testing.T) {
+		service := newFakeBlobService()
+		service.containers["messages"] = true
+		saver := newSaver(t, service, AzureBlobSaverConfig{SASToken: "?sv=2021-08-06&sig=abc"})
+
+		if err := saver.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+	})
+
+	t.Run("custom blob name", func(t *testing.T) {
+		service := newFakeBlobService()
+		saver := newSaver(t, service, AzureBlobSaverConfig{
+			CreateContainer: true,
+			BlobName:        func(msg *domain.ReceivedMessage) string { return "archive/" + msg.Headers["camera-id"] },
+		})
+
+		if err := saver.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+		if _, ok := service.blobs["/"+testAccountName+"/messages/archive/front door"]; !ok {
+			t.Errorf("expected custom blob name, got %v", service.requests)
+		}
+	})
+}
+
+func TestStringToSign(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPut, "https://acct.blob.core.windows.net/c/a%20b.txt?comp=block&blockid=QQ%3D%3D", nil)
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("x-ms-version", azureAPIVersion)
+	req.Header.Set("x-ms-date", "Mon, 01 Jan 2024 00:00:00 GMT")
+	req.Header["x-ms-meta-Owner"] = []string{" team "}
+
+	want := "PUT\n\n\n5\n\ntext/plain\n\n\n\n\n\n\n" +
+		"x-ms-date:Mon, 01 Jan 2024 00:00:00 GMT\n" +
+		"x-ms-meta-owner:team\n" +
+		"x-ms-version:" + azureAPIVersion + "\n" +
+		"/acct/c/a%20b.txt\nblockid:QQ==\ncomp:block"
+	if got := stringToSign(req, "acct", 5); got != want {
+		t.Errorf("unexpected string to sign:\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Chain runs handlers in order and stops at the first error
+func Chain(handlers ...domain.MessageHandler) domain.MessageHandler {
+	return domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		for _, h := range handlers {
+			if err := h.Handle(ctx, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// WithRetry retries a failing handler up to attempts times in total,
+// waiting backoff between attempts
+func WithRetry(handler domain.MessageHandler, attempts int, backoff time.Duration) domain.MessageHandler {
+	return WithRetryClock(handler, attempts, backoff, nil)
+}
+
+// WithRetryClock is WithRetry waiting on clock (a nil clock means domain.SystemClock)
+func WithRetryClock(handler domain.MessageHandler, attempts int, backoff time.Duration, clock domain.Clock) domain.MessageHandler {
+	if attempts <= 1 {
+		return handler
+	}
+	clock = domain.ClockOrSystem(clock)
+
+	return domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		var err error
+		for attempt := 1; attempt <= attempts; attempt++ {
+			if err = handler.Handle(ctx, msg); err == nil {
+				return nil
+			}
+			if attempt == attempts {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-clock.After(backoff):
+			}
+		}
+		return err
+	})
+}
@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestChain(t *testing.T) {
+	t.Run("runs handlers in order", func(t *testing.T) {
+		var order []int
+		h := Chain(
+			domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+				order = append(order, 1)
+				return nil
+			}),
+			domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+				order = append(order, 2)
+				return nil
+			}),
+		)
+
+		if err := h.Handle(context.Background(), &domain.ReceivedMessage{}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+			t.Errorf("unexpected order: %v", order)
+		}
+	})
+
+	t.Run("stops at first error", func(t *testing.T) {
+		called := false
+		h := Chain(
+			domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+				return errors.New("step failed")
+			}),
+			domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+				called = true
+				return nil
+			}),
+		)
+
+		if err := h.Handle(context.Background(), &domain.ReceivedMessage{}); err == nil {
+			t.Fatal("expected error")
+		}
+		if called {
+			t.Error("expected chain to stop after failure")
+		}
+	})
+}
+
+func TestWithRetry(t *testing.T) {
+	t.Run("retries until success", func(t *testing.T) {
+		calls := 0
+		h := WithRetry(domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			calls++
+			if calls < 3 {
+				return errors.New("temporary failure")
+			}
+			return nil
+		}), 3, time.Millisecond)
+
+		if err := h.Handle(context.Background(), &domain.ReceivedMessage{}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("expected 3 calls, got %d", calls)
+		}
+	})
+
+	t.Run("returns last error", func(t *testing.T) {
+		calls := 0
+		h := WithRetry(domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			calls++
+			return errors.New("permanent failure")
+		}), 2, time.Millisecond)
+
+		if err := h.Handle(context.Background(), &domain.ReceivedMessage{}); err == nil {
+			t.Fatal("expected error")
+		}
+		if calls != 2 {
+			t.Errorf("expected 2 calls, got %d", calls)
+		}
+	})
+
+	t.Run("waits on the clock", func(t *testing.T) {
+		clock := connectortest.NewFakeClock(time.Time{})
+		var calls atomic.Int32
+		h := WithRetryClock(domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			if calls.Add(1) == 1 {
+				return errors.New("temporary failure")
+			}
+			return nil
+		}), 2, time.Minute, clock)
+
+		done := make(chan error, 1)
+		go func() { done <- h.Handle(context.Background(), &domain.ReceivedMessage{}) }()
+
+		clock.WaitForWaiters(t, 1, 2*time.Second)
+		if n := calls.Load(); n != 1 {
+			t.Errorf("expected 1 call before the backoff elapsed, got %d", n)
+		}
+		clock.Advance(time.Minute)
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("handler did not retry after the clock advanced")
+		}
+		if n := calls.Load(); n != 2 {
+			t.Errorf("expected 2 calls, got %d", n)
+		}
+	})
+}
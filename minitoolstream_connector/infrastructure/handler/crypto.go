@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Headers describing encrypted payloads
+const (
+	headerKeyID               = "key-id"
+	headerEncryption          = "encryption"
+	headerOriginalContentType = "original-content-type"
+)
+
+// EncryptionAESGCM is the encryption header value of AES-GCM payloads
+const EncryptionAESGCM = "aes-gcm"
+
+// encryptingPreparer encrypts the messages of another preparer
+type encryptingPreparer struct {
+	preparer domain.MessagePreparer
+	keys     KeyProvider
+	keyID    string
+}
+
+// NewEncryptingPreparer wraps a preparer so its payloads are encrypted with
+// AES-GCM under the key keyID. The payload becomes nonce || ciphertext,
+// authenticated together with the subject; the key-id header tells
+// WithDecryption which key to use.
+func NewEncryptingPreparer(preparer domain.MessagePreparer, keys KeyProvider, keyID string) domain.MessagePreparer {
+	return &encryptingPreparer{preparer: preparer, keys: keys, keyID: keyID}
+}
+
+// Prepare prepares the inner message and encrypts its payload
+func (p *encryptingPreparer) Prepare(ctx context.Context) (*domain.PublishMessage, error) {
+	msg, err := p.preparer.Prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(ctx, p.keys, p.keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	headers := make(map[string]string, len(msg.Headers)+3)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	if contentType, ok := headers["content-type"]; ok {
+		headers[headerOriginalContentType] = contentType
+	}
+	headers["content-type"] = "application/octet-stream"
+	headers[headerKeyID] = p.keyID
+	headers[headerEncryption] = EncryptionAESGCM
+
+	encrypted := *msg
+	encrypted.Data = aead.Seal(nonce, nonce, msg.Data, []byte(msg.Subject))
+	encrypted.Headers = headers
+	return &encrypted, nil
+}
+
+// WithDecryption decrypts messages produced by NewEncryptingPreparer before
+// passing them to handler, selecting the key by the key-id header. Messages
+// that are not encrypted are rejected, so plaintext cannot be slipped in.
+func WithDecryption(handler domain.MessageHandler, keys KeyProvider) domain.MessageHandler {
+	return domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		if algorithm := msg.Headers[headerEncryption]; algorithm != EncryptionAESGCM {
+			return fmt.Errorf("sequence %d: unsupported encryption %q", msg.Sequence, algorithm)
+		}
+		keyID := msg.Headers[headerKeyID]
+		if keyID == "" {
+			return fmt.Errorf("sequence %d: missing %s header", msg.Sequence, headerKeyID)
+		}
+
+		aead, err := newAEAD(ctx, keys, keyID)
+		if err != nil {
+			return fmt.Errorf("sequence %d: %w", msg.Sequence, err)
+		}
+		if len(msg.Data) < aead.NonceSize() {
+			return fmt.Errorf("sequence %d: encrypted payload too short", msg.Sequence)
+		}
+		nonce, ciphertext := msg.Data[:aead.NonceSize()], msg.Data[aead.NonceSize():]
+		data, err := aead.Open(nil, nonce, ciphertext, []byte(msg.Subject))
+		if err != nil {
+			return fmt.Errorf("sequence %d: failed to decrypt with key %s: %w", msg.Sequence, keyID, err)
+		}
+
+		headers := make(map[string]string, len(msg.Headers))
+		for k, v := range msg.Headers {
+			headers[k] = v
+		}
+		delete(headers, headerEncryption)
+		delete(headers, "content-type")
+		if contentType, ok := headers[headerOriginalContentType]; ok {
+			headers["content-type"] = contentType
+			delete(headers, headerOriginalContentType)
+		}
+
+		decrypted := *msg
+		decrypted.Data = data
+		decrypted.Headers = headers
+		return handler.Handle(ctx, &decrypted)
+	})
+}
+
+// newAEAD creates an AES-GCM cipher with the key keyID
+func newAEAD(ctx context.Context, keys KeyProvider, keyID string) (cipher.AEAD, error) {
+	key, err := keys.Key(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key %s: %w", keyID, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key %s: %w", keyID, err)
+	}
+	return cipher.NewGCM(block)
+}
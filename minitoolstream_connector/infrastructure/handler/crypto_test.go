@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func encryptForTest(t *testing.T, keys KeyProvider, keyID string, msg *domain.PublishMessage) *domain.ReceivedMessage {
+	t.Helper()
+	preparer := NewEncryptingPreparer(domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return msg, nil
+	}), keys, keyID)
+
+	encrypted, err := preparer.Prepare(context.Background())
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	return &domain.ReceivedMessage{Subject: encrypted.Subject, Sequence: 1, Data: encrypted.Data, Headers: encrypted.Headers}
+}
+
+func TestEncryptionRoundTrip(t *testing.T) {
+	keys := StaticKeys{
+		"k1": bytes.Repeat([]byte{1}, 32),
+		"k2": bytes.Repeat([]byte{2}, 16),
+	}
+	plain := &domain.PublishMessage{
+		Subject: "payments",
+		Data:    []byte(`{"amount":100}`),
+		Headers: map[string]string{"content-type": "application/json", "tenant": "acme"},
+	}
+
+	for _, keyID := range []string{"k1", "k2"} {
+		t.Run(keyID, func(t *testing.T) {
+			received := encryptForTest(t, keys, keyID, plain)
+
+			if bytes.Contains(received.Data, plain.Data) {
+				t.Error("payload is not encrypted")
+			}
+			if received.Headers["key-id"] != keyID || received.Headers["content-type"] != "application/octet-stream" {
+				t.Errorf("unexpected encrypted headers %v", received.Headers)
+			}
+			if plain.Headers["key-id"] != "" {
+				t.Error("inner message headers were modified")
+			}
+
+			var got *domain.ReceivedMessage
+			handler := WithDecryption(domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+				got = msg
+				return nil
+			}), keys)
+
+			if err := handler.Handle(context.Background(), received); err != nil {
+				t.Fatalf("Handle failed: %v", err)
+			}
+			if string(got.Data) != string(plain.Data) {
+				t.Errorf("expected %q, got %q", plain.Data, got.Data)
+			}
+			if got.Headers["content-type"] != "application/json" || got.Headers["tenant"] != "acme" {
+				t.Errorf("headers not restored: %v", got.Headers)
+			}
+			if _, ok := got.Headers["encryption"]; ok {
+				t.Error("encryption header should be removed")
+			}
+		})
+	}
+}
+
+func TestWithDecryption_Rejects(t *testing.T) {
+	keys := StaticKeys{"k1": bytes.Repeat([]byte{1}, 32), "k2": bytes.Repeat([]byte{2}, 32)}
+	called := false
+	handler := WithDecryption(domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		called = true
+		return nil
+	}), keys)
+
+	valid := func() *domain.ReceivedMessage {
+		return encryptForTest(t, keys, "k1", &domain.PublishMessage{Subject: "payments", Data: []byte("secret")})
+	}
+
+	tests := []struct {
+		name   string
+		modify func(msg *domain.ReceivedMessage)
+	}{
+		{"plaintext", func(msg *domain.ReceivedMessage) { msg.Headers = map[string]string{} }},
+		{"missing key id", func(msg *domain.ReceivedMessage) { delete(msg.Headers, "key-id") }},
+		{"unknown key", func(msg *domain.ReceivedMessage) { msg.Headers["key-id"] = "k9" }},
+		{"wrong key", func(msg *domain.ReceivedMessage) { msg.Headers["key-id"] = "k2" }},
+		{"tampered payload", func(msg *domain.ReceivedMessage) { msg.Data[len(msg.Data)-1] ^= 1 }},
+		{"different subject", func(msg *domain.ReceivedMessage) { msg.Subject = "refunds" }},
+		{"truncated payload", func(msg *domain.ReceivedMessage) { msg.Data = msg.Data[:4] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := valid()
+			tt.modify(msg)
+			if err := handler.Handle(context.Background(), msg); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+	if called {
+		t.Error("handler should not be called for rejected messages")
+	}
+}
+
+func TestEncryptingPreparer_Errors(t *testing.T) {
+	inner := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{Subject: "s", Data: []byte("x")}, nil
+	})
+
+	if _, err := NewEncryptingPreparer(inner, StaticKeys{}, "missing").Prepare(context.Background()); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+	if _, err := NewEncryptingPreparer(inner, StaticKeys{"short": []byte("abc")}, "short").Prepare(context.Background()); err == nil {
+		t.Error("expected error for invalid key size")
+	}
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
@@ -13,7 +14,9 @@ type DataHandler struct {
 	data        []byte
 	contentType string
 	headers     map[string]string
+	dedupKey    string
 	logger      Logger
+	clock       domain.Clock
 }
 
 // DataHandlerConfig represents configuration for DataHandler
@@ -22,7 +25,11 @@ type DataHandlerConfig struct {
 	Data        []byte
 	ContentType string
 	Headers     map[string]string
-	Logger      Logger
+	// DedupKey identifies the message across retries (see domain.PublishMessage)
+	DedupKey string
+	Logger   Logger
+	// Clock stamps the timestamp header (default domain.SystemClock)
+	Clock domain.Clock
 }
 
 // NewDataHandler creates a new data handler
@@ -39,7 +46,7 @@ func NewDataHandler(config *DataHandlerConfig) *DataHandler {
 
 	contentType := config.ContentType
 	if contentType == "" {
-		contentType = "application/octet-stream"
+		contentType = sniffContentType(config.Data)
 	}
 
 	return &DataHandler{
@@ -47,10 +54,21 @@ func NewDataHandler(config *DataHandlerConfig) *DataHandler {
 		data:        config.Data,
 		contentType: contentType,
 		headers:     headers,
+		dedupKey:    config.DedupKey,
 		logger:      logger,
+		clock:       domain.ClockOrSystem(config.Clock),
 	}
 }
 
+// sniffContentType sniffs the content type of data, falling back to
+// application/octet-stream for empty data
+func sniffContentType(data []byte) string {
+	if len(data) == 0 {
+		return "application/octet-stream"
+	}
+	return http.DetectContentType(data)
+}
+
 // WithHeaders adds custom headers to the handler
 func (h *DataHandler) WithHeaders(headers map[string]string) *DataHandler {
 	for k, v := range headers {
@@ -59,6 +77,12 @@ func (h *DataHandler) WithHeaders(headers map[string]string) *DataHandler {
 	return h
 }
 
+// WithDedupKey sets the dedup key of the published message
+func (h *DataHandler) WithDedupKey(key string) *DataHandler {
+	h.dedupKey = key
+	return h
+}
+
 // Prepare prepares raw data for publishing
 func (h *DataHandler) Prepare(ctx context.Context) (*domain.PublishMessage, error) {
 	h.logger.Printf("[%s] Preparing data (%d bytes)", h.subject, len(h.data))
@@ -66,7 +90,7 @@ func (h *DataHandler) Prepare(ctx context.Context) (*domain.PublishMessage, erro
 	// Build headers
 	headers := make(map[string]string)
 	headers["content-type"] = h.contentType
-	headers["timestamp"] = time.Now().Format(time.RFC3339)
+	headers["timestamp"] = h.clock.Now().Format(time.RFC3339)
 
 	// Add custom headers
 	for k, v := range h.headers {
@@ -74,8 +98,9 @@ func (h *DataHandler) Prepare(ctx context.Context) (*domain.PublishMessage, erro
 	}
 
 	return &domain.PublishMessage{
-		Subject: h.subject,
-		Data:    h.data,
-		Headers: headers,
+		Subject:  h.subject,
+		Data:     h.data,
+		Headers:  headers,
+		DedupKey: h.dedupKey,
 	}, nil
 }
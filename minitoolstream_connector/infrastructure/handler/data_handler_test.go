@@ -77,8 +77,25 @@ func TestNewDataHandler(t *testing.T) {
 		}
 
 		handler := NewDataHandler(config)
-		if handler.contentType != "application/octet-stream" {
-			t.Errorf("expected default content type 'application/octet-stream', got %s", handler.contentType)
+		if handler.contentType != "text/plain; charset=utf-8" {
+			t.Errorf("expected sniffed content type 'text/plain; charset=utf-8', got %s", handler.contentType)
+		}
+	})
+
+	t.Run("sniffs binary content types", func(t *testing.T) {
+		tests := []struct {
+			data []byte
+			want string
+		}{
+			{[]byte("\x89PNG\r\n\x1a\n"), "image/png"},
+			{[]byte{0x00, 0x01, 0x02, 0x03}, "application/octet-stream"},
+			{nil, "application/octet-stream"},
+		}
+		for _, tt := range tests {
+			handler := NewDataHandler(&DataHandlerConfig{Subject: "test.subject", Data: tt.data})
+			if handler.contentType != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, handler.contentType)
+			}
 		}
 	})
 }
@@ -243,3 +260,19 @@ func TestDataHandler_Prepare(t *testing.T) {
 		}
 	})
 }
+
+func TestDataHandler_DedupKey(t *testing.T) {
+	handler := NewDataHandler(&DataHandlerConfig{Subject: "orders", DedupKey: "order-42"})
+	msg, err := handler.Prepare(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if msg.DedupKey != "order-42" {
+		t.Errorf("expected dedup key 'order-42', got %q", msg.DedupKey)
+	}
+
+	msg, _ = handler.WithDedupKey("order-43").Prepare(context.Background())
+	if msg.DedupKey != "order-43" {
+		t.Errorf("expected dedup key 'order-43', got %q", msg.DedupKey)
+	}
+}
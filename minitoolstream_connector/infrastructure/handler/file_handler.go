@@ -16,6 +16,7 @@ type FileHandler struct {
 	filePath    string
 	contentType string
 	logger      Logger
+	clock       domain.Clock
 }
 
 // FileHandlerConfig represents configuration for FileHandler
@@ -24,6 +25,8 @@ type FileHandlerConfig struct {
 	FilePath    string
 	ContentType string
 	Logger      Logger
+	// Clock stamps the timestamp header (default domain.SystemClock)
+	Clock domain.Clock
 }
 
 // NewFileHandler creates a new file handler
@@ -38,6 +41,7 @@ func NewFileHandler(config *FileHandlerConfig) *FileHandler {
 		filePath:    config.FilePath,
 		contentType: config.ContentType,
 		logger:      logger,
+		clock:       domain.ClockOrSystem(config.Clock),
 	}
 }
 
@@ -68,7 +72,7 @@ func (h *FileHandler) Prepare(ctx context.Context) (*domain.PublishMessage, erro
 		Headers: map[string]string{
 			"content-type": contentType,
 			"filename":     filepath.Base(h.filePath),
-			"timestamp":    time.Now().Format(time.RFC3339),
+			"timestamp":    h.clock.Now().Format(time.RFC3339),
 		},
 	}, nil
 }
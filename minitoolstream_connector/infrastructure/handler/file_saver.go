@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"fmt"
+	"mime"
 	"os"
 	"path/filepath"
 
@@ -74,6 +75,10 @@ func (h *FileSaver) Handle(ctx context.Context, msg *domain.ReceivedMessage) err
 
 // getFileExtension returns file extension for content type
 func getFileExtension(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+
 	switch contentType {
 	case "image/jpeg":
 		return ".jpg"
@@ -148,6 +148,7 @@ func TestFileSaver_Handle(t *testing.T) {
 			{"application/json", ".json"},
 			{"application/pdf", ".pdf"},
 			{"text/plain", ".txt"},
+			{"text/plain; charset=utf-8", ".txt"},
 			{"application/octet-stream", ".bin"},
 		}
 
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// fixtureExt is the extension of fixture files
+const fixtureExt = ".json"
+
+// fixture is the on-disk form of a recorded message; Data is base64 encoded
+// so payloads are preserved byte for byte
+type fixture struct {
+	Subject   string            `json:"subject"`
+	Sequence  uint64            `json:"sequence"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Data      []byte            `json:"data"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// FixtureRecorder persists received messages to a fixture directory, one
+// numbered JSON file per message in the order received. Recording into a
+// directory that already holds fixtures appends to them.
+type FixtureRecorder struct {
+	dir    string
+	logger Logger
+
+	mu   sync.Mutex
+	next int
+}
+
+// FixtureRecorderConfig represents configuration for FixtureRecorder
+type FixtureRecorderConfig struct {
+	Dir    string
+	Logger Logger
+}
+
+// NewFixtureRecorder creates a new fixture recorder handler
+func NewFixtureRecorder(config *FixtureRecorderConfig) (*FixtureRecorder, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Dir == "" {
+		return nil, fmt.Errorf("fixture directory is required")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = &defaultLogger{}
+	}
+
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory %s: %w", config.Dir, err)
+	}
+
+	files, err := fixtureFiles(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	next := 1
+	if len(files) > 0 {
+		last, _ := strconv.Atoi(strings.TrimSuffix(filepath.Base(files[len(files)-1]), fixtureExt))
+		next = last + 1
+	}
+
+	return &FixtureRecorder{
+		dir:    config.Dir,
+		logger: logger,
+		next:   next,
+	}, nil
+}
+
+// Handle writes the message to the next fixture file
+func (r *FixtureRecorder) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	data, err := json.MarshalIndent(&fixture{
+		Subject:   msg.Subject,
+		Sequence:  msg.Sequence,
+		Headers:   msg.Headers,
+		Data:      msg.Data,
+		Timestamp: msg.Timestamp,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := filepath.Join(r.dir, fmt.Sprintf("%06d%s", r.next, fixtureExt))
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	r.next++
+
+	r.logger.Printf("   ✓ Recorded sequence %d to: %s", msg.Sequence, path)
+	return nil
+}
+
+// LoadFixtures reads the messages recorded in a fixture directory, in
+// recording order. Feed them to a handler for golden-data tests of a
+// consumer pipeline.
+func LoadFixtures(dir string) ([]*domain.ReceivedMessage, error) {
+	files, err := fixtureFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no fixtures in %s", dir)
+	}
+
+	messages := make([]*domain.ReceivedMessage, 0, len(files))
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+		var f fixture
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to decode fixture %s: %w", path, err)
+		}
+		messages = append(messages, &domain.ReceivedMessage{
+			Subject:   f.Subject,
+			Sequence:  f.Sequence,
+			Data:      f.Data,
+			Headers:   f.Headers,
+			Timestamp: f.Timestamp,
+		})
+	}
+	return messages, nil
+}
+
+// PreparersFromFixtures creates one preparer per recorded message that
+// republishes it verbatim: same subject, headers and payload. Pass them to
+// PublishAll to replay a recording.
+func PreparersFromFixtures(dir string) ([]domain.MessagePreparer, error) {
+	messages, err := LoadFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	preparers := make([]domain.MessagePreparer, len(messages))
+	for i, msg := range messages {
+		msg := msg
+		preparers[i] = domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			var headers map[string]string
+			if msg.Headers != nil {
+				headers = make(map[string]string, len(msg.Headers))
+				for k, v := range msg.Headers {
+					headers[k] = v
+				}
+			}
+			return &domain.PublishMessage{Subject: msg.Subject, Data: msg.Data, Headers: headers}, nil
+		})
+	}
+	return preparers, nil
+}
+
+// fixtureFiles returns the fixture files of a directory in recording order
+func fixtureFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture directory: %w", err)
+	}
+
+	var files []string
+	index := make(map[string]int)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != fixtureExt {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(name, fixtureExt))
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		files = append(files, path)
+		index[path] = n
+	}
+	sort.Slice(files, func(i, j int) bool { return index[files[i]] < index[files[j]] })
+	return files, nil
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestNewFixtureRecorder(t *testing.T) {
+	if _, err := NewFixtureRecorder(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+	if _, err := NewFixtureRecorder(&FixtureRecorderConfig{}); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestFixtureRecordAndReplay(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "golden")
+	recorded := []*domain.ReceivedMessage{
+		{Subject: "orders", Sequence: 7, Data: []byte(`{"id":1}`), Headers: map[string]string{"content-type": "application/json"}, Timestamp: time.Unix(1700000000, 0).UTC()},
+		{Subject: "images", Sequence: 3, Data: []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}},
+	}
+
+	recorder, err := NewFixtureRecorder(&FixtureRecorderConfig{Dir: dir, Logger: &testLogger{}})
+	if err != nil {
+		t.Fatalf("NewFixtureRecorder failed: %v", err)
+	}
+	for _, msg := range recorded {
+		if err := recorder.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+	}
+
+	// A second recorder appends after the existing fixtures
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("golden data"), 0644)
+	recorder, _ = NewFixtureRecorder(&FixtureRecorderConfig{Dir: dir, Logger: &testLogger{}})
+	extra := &domain.ReceivedMessage{Subject: "orders", Sequence: 8, Data: []byte(`{"id":2}`)}
+	recorder.Handle(context.Background(), extra)
+	recorded = append(recorded, extra)
+
+	if _, err := os.Stat(filepath.Join(dir, "000003.json")); err != nil {
+		t.Errorf("expected third fixture file: %v", err)
+	}
+
+	loaded, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatalf("LoadFixtures failed: %v", err)
+	}
+	if len(loaded) != len(recorded) {
+		t.Fatalf("expected %d fixtures, got %d", len(recorded), len(loaded))
+	}
+	for i, msg := range loaded {
+		want := recorded[i]
+		if msg.Subject != want.Subject || msg.Sequence != want.Sequence || !bytes.Equal(msg.Data, want.Data) || !msg.Timestamp.Equal(want.Timestamp) {
+			t.Errorf("fixture %d: expected %+v, got %+v", i, want, msg)
+		}
+	}
+	if loaded[0].Headers["content-type"] != "application/json" {
+		t.Errorf("expected headers to be restored, got %v", loaded[0].Headers)
+	}
+
+	preparers, err := PreparersFromFixtures(dir)
+	if err != nil {
+		t.Fatalf("PreparersFromFixtures failed: %v", err)
+	}
+	for i, preparer := range preparers {
+		msg, err := preparer.Prepare(context.Background())
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		if msg.Subject != recorded[i].Subject || !bytes.Equal(msg.Data, recorded[i].Data) || len(msg.Headers) != len(recorded[i].Headers) {
+			t.Errorf("preparer %d: expected verbatim message, got %+v", i, msg)
+		}
+	}
+}
+
+func TestLoadFixtures_Errors(t *testing.T) {
+	if _, err := LoadFixtures(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for missing directory")
+	}
+	if _, err := LoadFixtures(t.TempDir()); err == nil {
+		t.Error("expected error for empty directory")
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "000001.json"), []byte("{"), 0644)
+	if _, err := PreparersFromFixtures(dir); err == nil {
+		t.Error("expected error for invalid fixture")
+	}
+}
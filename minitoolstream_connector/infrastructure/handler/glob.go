@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// GlobProgress reports a file of a glob that has been read for publishing
+type GlobProgress struct {
+	Index int // Position of the file in the glob, starting at 0
+	Total int
+	Path  string
+	Size  int
+	Err   error // Set when the file could not be read
+}
+
+// GlobOption configures PreparersFromGlob
+type GlobOption func(*globOptions)
+
+type globOptions struct {
+	contentType string
+	progress    func(GlobProgress)
+	logger      Logger
+}
+
+// WithGlobContentType sets the content type of every file instead of detecting it
+func WithGlobContentType(contentType string) GlobOption {
+	return func(o *globOptions) { o.contentType = contentType }
+}
+
+// WithGlobProgress sets a callback invoked as each file is read for publishing.
+// PublishAll publishes concurrently, so the callback may be invoked concurrently
+// and out of order.
+func WithGlobProgress(fn func(GlobProgress)) GlobOption {
+	return func(o *globOptions) { o.progress = fn }
+}
+
+// WithGlobLogger sets the logger of the file preparers
+func WithGlobLogger(logger Logger) GlobOption {
+	return func(o *globOptions) { o.logger = logger }
+}
+
+// PreparersFromGlob returns one file preparer per regular file matching pattern,
+// sorted by path. Besides the usual path.Match syntax, a "**" path segment
+// matches any number of directories, e.g. "data/**/*.csv".
+//
+// The returned order is stable, but PublishAll publishes concurrently; every
+// message carries file-index and file-count headers so consumers can restore
+// the order, or the preparers can be published one by one with Publish.
+// It is an error for the pattern to match no files.
+func PreparersFromGlob(subject, pattern string, opts ...GlobOption) ([]domain.MessagePreparer, error) {
+	options := &globOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	paths, err := globFiles(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no files match %q", pattern)
+	}
+
+	preparers := make([]domain.MessagePreparer, len(paths))
+	for i, path := range paths {
+		preparers[i] = &globFilePreparer{
+			file: NewFileHandler(&FileHandlerConfig{
+				Subject:     subject,
+				FilePath:    path,
+				ContentType: options.contentType,
+				Logger:      options.logger,
+			}),
+			index:    i,
+			total:    len(paths),
+			path:     path,
+			progress: options.progress,
+		}
+	}
+	return preparers, nil
+}
+
+// globFilePreparer prepares one file of a glob
+type globFilePreparer struct {
+	file     *FileHandler
+	index    int
+	total    int
+	path     string
+	progress func(GlobProgress)
+}
+
+// Prepare reads the file and adds its position in the glob to the headers
+func (p *globFilePreparer) Prepare(ctx context.Context) (*domain.PublishMessage, error) {
+	msg, err := p.file.Prepare(ctx)
+
+	if p.progress != nil {
+		progress := GlobProgress{Index: p.index, Total: p.total, Path: p.path, Err: err}
+		if msg != nil {
+			progress.Size = len(msg.Data)
+		}
+		p.progress(progress)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	msg.Headers["file-path"] = filepath.ToSlash(p.path)
+	msg.Headers["file-index"] = strconv.Itoa(p.index)
+	msg.Headers["file-count"] = strconv.Itoa(p.total)
+	return msg, nil
+}
+
+// globFiles returns the sorted regular files matching pattern
+func globFiles(pattern string) ([]string, error) {
+	segments := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+	for _, seg := range segments {
+		if _, err := filepath.Match(seg, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	// Walk from the longest prefix without wildcards
+	fixed := 0
+	for fixed < len(segments)-1 && !hasMeta(segments[fixed]) {
+		fixed++
+	}
+	root := filepath.FromSlash(strings.Join(segments[:fixed], "/"))
+	if fixed == 0 {
+		root = "."
+	} else if root == "" {
+		root = string(filepath.Separator)
+	}
+	rest := segments[fixed:]
+
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return fs.SkipAll
+			}
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		relSegments := strings.Split(filepath.ToSlash(rel), "/")
+
+		if d.IsDir() {
+			if path != root && !matchPrefix(rest, relSegments) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && matchSegments(rest, relSegments) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", root, err)
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// matchSegments matches path segments against pattern segments, where "**"
+// matches zero or more segments
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := filepath.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+// matchPrefix reports whether paths below the directory segments can match pattern
+func matchPrefix(pattern, segments []string) bool {
+	if len(segments) == 0 {
+		return true
+	}
+	if len(pattern) == 0 {
+		return false
+	}
+	if pattern[0] == "**" {
+		return true
+	}
+	if ok, _ := filepath.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchPrefix(pattern[1:], segments[1:])
+}
+
+// hasMeta reports whether a pattern segment contains wildcards
+func hasMeta(segment string) bool {
+	return strings.ContainsAny(segment, `*?[\`)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// writeTree creates files with their path as content below dir
+func writeTree(t *testing.T, dir string, files ...string) {
+	t.Helper()
+	for _, name := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestPreparersFromGlob(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir,
+		"data/b.csv",
+		"data/a.csv",
+		"data/2024/01/jan.csv",
+		"data/2024/notes.txt",
+		"other/c.csv",
+	)
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    []string
+	}{
+		{"single level", "data/*.csv", []string{"data/a.csv", "data/b.csv"}},
+		{"recursive", "data/**/*.csv", []string{"data/2024/01/jan.csv", "data/a.csv", "data/b.csv"}},
+		{"recursive any file", "data/2024/**", []string{"data/2024/01/jan.csv", "data/2024/notes.txt"}},
+		{"wildcard directory", "*/c.csv", []string{"other/c.csv"}},
+		{"literal path", "data/a.csv", []string{"data/a.csv"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preparers, err := PreparersFromGlob("files", filepath.Join(dir, tt.pattern), WithGlobLogger(&testLogger{}))
+			if err != nil {
+				t.Fatalf("PreparersFromGlob failed: %v", err)
+			}
+			if len(preparers) != len(tt.want) {
+				t.Fatalf("expected %d preparers, got %d", len(tt.want), len(preparers))
+			}
+			for i, p := range preparers {
+				msg, err := p.Prepare(context.Background())
+				if err != nil {
+					t.Fatalf("Prepare failed: %v", err)
+				}
+				if string(msg.Data) != tt.want[i] {
+					t.Errorf("preparer %d: expected %s, got %s", i, tt.want[i], msg.Data)
+				}
+			}
+		})
+	}
+
+	t.Run("headers", func(t *testing.T) {
+		preparers, _ := PreparersFromGlob("files", filepath.Join(dir, "data/*.csv"), WithGlobLogger(&testLogger{}))
+		msg, err := preparers[1].Prepare(context.Background())
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		if msg.Subject != "files" || msg.Headers["file-index"] != "1" || msg.Headers["file-count"] != "2" {
+			t.Errorf("unexpected message %s %v", msg.Subject, msg.Headers)
+		}
+		if msg.Headers["filename"] != "b.csv" || msg.Headers["file-path"] != filepath.ToSlash(filepath.Join(dir, "data/b.csv")) {
+			t.Errorf("expected file headers, got %v", msg.Headers)
+		}
+	})
+
+	t.Run("content type override", func(t *testing.T) {
+		preparers, _ := PreparersFromGlob("files", filepath.Join(dir, "data/a.csv"),
+			WithGlobContentType("application/vnd.ms-excel"), WithGlobLogger(&testLogger{}))
+		msg, _ := preparers[0].Prepare(context.Background())
+		if msg.Headers["content-type"] != "application/vnd.ms-excel" {
+			t.Errorf("expected overridden content type, got %s", msg.Headers["content-type"])
+		}
+	})
+
+	t.Run("progress", func(t *testing.T) {
+		var mu sync.Mutex
+		var reports []GlobProgress
+		preparers, _ := PreparersFromGlob("files", filepath.Join(dir, "data/*.csv"),
+			WithGlobLogger(&testLogger{}),
+			WithGlobProgress(func(p GlobProgress) {
+				mu.Lock()
+				reports = append(reports, p)
+				mu.Unlock()
+			}))
+
+		os.Remove(filepath.Join(dir, "data/b.csv"))
+		defer writeTree(t, dir, "data/b.csv")
+
+		preparers[0].Prepare(context.Background())
+		if _, err := preparers[1].Prepare(context.Background()); err == nil {
+			t.Error("expected error for removed file")
+		}
+
+		if len(reports) != 2 {
+			t.Fatalf("expected 2 progress reports, got %d", len(reports))
+		}
+		if reports[0].Index != 0 || reports[0].Total != 2 || reports[0].Size != len("data/a.csv") || reports[0].Err != nil {
+			t.Errorf("unexpected first report %+v", reports[0])
+		}
+		if reports[1].Index != 1 || reports[1].Err == nil {
+			t.Errorf("expected error in second report, got %+v", reports[1])
+		}
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		if _, err := PreparersFromGlob("files", filepath.Join(dir, "data/*.json")); err == nil {
+			t.Error("expected error when nothing matches")
+		}
+		if _, err := PreparersFromGlob("files", filepath.Join(dir, "missing/*.csv")); err == nil {
+			t.Error("expected error for missing directory")
+		}
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		if _, err := PreparersFromGlob("files", filepath.Join(dir, "data/[.csv")); err == nil {
+			t.Error("expected error for invalid pattern")
+		}
+	})
+}
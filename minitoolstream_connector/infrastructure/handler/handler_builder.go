@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// HandlerBuilder assembles a message handler pipeline from the built-in handlers.
+// Steps run in the order they were added; each step is retried on its own so a
+// failing forward doesn't repeat an earlier save.
+type HandlerBuilder struct {
+	steps        []domain.MessageHandler
+	attempts     int
+	retryBackoff time.Duration
+	logger       Logger
+	clock        domain.Clock
+	errs         []error
+}
+
+// NewHandlerBuilder creates a new handler builder
+func NewHandlerBuilder() *HandlerBuilder {
+	return &HandlerBuilder{
+		attempts:     1,
+		retryBackoff: 500 * time.Millisecond,
+	}
+}
+
+// WithLogger sets the logger used by the built-in steps added afterwards
+func (b *HandlerBuilder) WithLogger(logger Logger) *HandlerBuilder {
+	b.logger = logger
+	return b
+}
+
+// SaveTo adds a step that saves message data to files in dir
+func (b *HandlerBuilder) SaveTo(dir string) *HandlerBuilder {
+	saver, err := NewFileSaver(&FileSaverConfig{OutputDir: dir, Logger: b.logger})
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	return b.Then(saver)
+}
+
+// SaveImagesTo adds a step that saves image messages to dir
+func (b *HandlerBuilder) SaveImagesTo(dir string) *HandlerBuilder {
+	processor, err := NewImageProcessor(&ImageProcessorConfig{OutputDir: dir, Logger: b.logger})
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	return b.Then(processor)
+}
+
+// ThenLog adds a step that logs messages with the given prefix
+func (b *HandlerBuilder) ThenLog(prefix string) *HandlerBuilder {
+	return b.Then(NewLoggerHandler(&LoggerHandlerConfig{Prefix: prefix, Logger: b.logger}))
+}
+
+// ThenForward adds a step that POSTs message data to url
+func (b *HandlerBuilder) ThenForward(url string) *HandlerBuilder {
+	forwarder, err := NewHTTPForwarder(&HTTPForwarderConfig{URL: url, Logger: b.logger})
+	if err != nil {
+		b.errs = append(b.errs, err)
+		return b
+	}
+	return b.Then(forwarder)
+}
+
+// Then adds a custom handler step
+func (b *HandlerBuilder) Then(handler domain.MessageHandler) *HandlerBuilder {
+	if handler == nil {
+		b.errs = append(b.errs, fmt.Errorf("handler step %d is nil", len(b.steps)+1))
+		return b
+	}
+	b.steps = append(b.steps, handler)
+	return b
+}
+
+// WithRetry sets how many times each step is attempted before the pipeline fails
+func (b *HandlerBuilder) WithRetry(attempts int) *HandlerBuilder {
+	b.attempts = attempts
+	return b
+}
+
+// WithRetryBackoff sets the delay between step attempts
+func (b *HandlerBuilder) WithRetryBackoff(backoff time.Duration) *HandlerBuilder {
+	b.retryBackoff = backoff
+	return b
+}
+
+// WithClock sets the clock that times the delay between step attempts
+func (b *HandlerBuilder) WithClock(clock domain.Clock) *HandlerBuilder {
+	b.clock = clock
+	return b
+}
+
+// Build creates the composed handler
+func (b *HandlerBuilder) Build() (domain.MessageHandler, error) {
+	if len(b.errs) > 0 {
+		return nil, errors.Join(b.errs...)
+	}
+
+	if len(b.steps) == 0 {
+		return nil, fmt.Errorf("handler pipeline has no steps")
+	}
+
+	steps := make([]domain.MessageHandler, len(b.steps))
+	for i, step := range b.steps {
+		steps[i] = WithRetryClock(step, b.attempts, b.retryBackoff, b.clock)
+	}
+
+	if len(steps) == 1 {
+		return steps[0], nil
+	}
+	return Chain(steps...), nil
+}
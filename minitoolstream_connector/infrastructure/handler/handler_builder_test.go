@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestHandlerBuilder_Build(t *testing.T) {
+	t.Run("no steps", func(t *testing.T) {
+		_, err := NewHandlerBuilder().Build()
+		if err == nil {
+			t.Fatal("expected error for empty pipeline")
+		}
+	})
+
+	t.Run("invalid step", func(t *testing.T) {
+		_, err := NewHandlerBuilder().ThenForward("").Then(nil).Build()
+		if err == nil {
+			t.Fatal("expected error for invalid steps")
+		}
+	})
+
+	t.Run("save then forward with retry", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		dir := t.TempDir()
+		h, err := NewHandlerBuilder().
+			WithLogger(&testLogger{}).
+			SaveTo(dir).
+			ThenForward(server.URL).
+			WithRetry(3).
+			WithRetryBackoff(0).
+			Build()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		msg := &domain.ReceivedMessage{
+			Subject:  "docs",
+			Sequence: 1,
+			Data:     []byte("hello"),
+			Headers:  map[string]string{"content-type": "text/plain"},
+		}
+		if err := h.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, "docs_seq_1.txt")); err != nil {
+			t.Errorf("expected file to be saved: %v", err)
+		}
+		if requests != 2 {
+			t.Errorf("expected forward to be retried once, got %d requests", requests)
+		}
+	})
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// HTTPForwarder forwards message data to an HTTP endpoint
+type HTTPForwarder struct {
+	url    string
+	method string
+	client *http.Client
+	logger Logger
+}
+
+// HTTPForwarderConfig represents configuration for HTTPForwarder
+type HTTPForwarderConfig struct {
+	URL    string
+	Method string
+	Client *http.Client
+	Logger Logger
+}
+
+// NewHTTPForwarder creates a new HTTP forwarder handler
+func NewHTTPForwarder(config *HTTPForwarderConfig) (*HTTPForwarder, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("forward URL is required")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = &defaultLogger{}
+	}
+
+	method := config.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &HTTPForwarder{
+		url:    config.URL,
+		method: method,
+		client: client,
+		logger: logger,
+	}, nil
+}
+
+// Handle sends the message data to the configured URL.
+// Message headers are passed as X-Stream-Header-* request headers.
+func (h *HTTPForwarder) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	req, err := http.NewRequestWithContext(ctx, h.method, h.url, bytes.NewReader(msg.Data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if contentType, ok := msg.Headers["content-type"]; ok {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("X-Stream-Subject", msg.Subject)
+	req.Header.Set("X-Stream-Sequence", strconv.FormatUint(msg.Sequence, 10))
+	for k, v := range msg.Headers {
+		req.Header.Set("X-Stream-Header-"+k, v)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to forward message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("forward endpoint returned status %d", resp.StatusCode)
+	}
+
+	h.logger.Printf("   ✓ Forwarded sequence %d to %s", msg.Sequence, h.url)
+	return nil
+}
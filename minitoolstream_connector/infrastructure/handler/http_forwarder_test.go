@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestNewHTTPForwarder(t *testing.T) {
+	t.Run("missing url", func(t *testing.T) {
+		_, err := NewHTTPForwarder(&HTTPForwarderConfig{})
+		if err == nil {
+			t.Fatal("expected error for missing url")
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		h, err := NewHTTPForwarder(&HTTPForwarderConfig{URL: "http://localhost"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if h.method != http.MethodPost {
+			t.Errorf("expected POST, got %s", h.method)
+		}
+		if h.client == nil || h.logger == nil {
+			t.Error("expected default client and logger")
+		}
+	})
+}
+
+func TestHTTPForwarder_Handle(t *testing.T) {
+	t.Run("forwards data and headers", func(t *testing.T) {
+		var body []byte
+		var header http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ = io.ReadAll(r.Body)
+			header = r.Header
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		h, _ := NewHTTPForwarder(&HTTPForwarderConfig{URL: server.URL, Logger: &testLogger{}})
+		msg := &domain.ReceivedMessage{
+			Subject:  "orders",
+			Sequence: 12,
+			Data:     []byte(`{"id":1}`),
+			Headers:  map[string]string{"content-type": "application/json", "tenant": "acme"},
+		}
+
+		if err := h.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if string(body) != `{"id":1}` {
+			t.Errorf("unexpected body: %s", body)
+		}
+		if header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected content type: %s", header.Get("Content-Type"))
+		}
+		if header.Get("X-Stream-Sequence") != "12" || header.Get("X-Stream-Subject") != "orders" {
+			t.Error("expected stream metadata headers")
+		}
+		if header.Get("X-Stream-Header-Tenant") != "acme" {
+			t.Error("expected message headers to be forwarded")
+		}
+	})
+
+	t.Run("non-2xx status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		h, _ := NewHTTPForwarder(&HTTPForwarderConfig{URL: server.URL, Logger: &testLogger{}})
+		if err := h.Handle(context.Background(), &domain.ReceivedMessage{}); err == nil {
+			t.Fatal("expected error for failing endpoint")
+		}
+	})
+}
@@ -15,6 +15,7 @@ type ImageHandler struct {
 	subject   string
 	imagePath string
 	logger    Logger
+	clock     domain.Clock
 }
 
 // ImageHandlerConfig represents configuration for ImageHandler
@@ -22,6 +23,8 @@ type ImageHandlerConfig struct {
 	Subject   string
 	ImagePath string
 	Logger    Logger
+	// Clock stamps the timestamp header (default domain.SystemClock)
+	Clock domain.Clock
 }
 
 // NewImageHandler creates a new image handler
@@ -35,6 +38,7 @@ func NewImageHandler(config *ImageHandlerConfig) *ImageHandler {
 		subject:   config.Subject,
 		imagePath: config.ImagePath,
 		logger:    logger,
+		clock:     domain.ClockOrSystem(config.Clock),
 	}
 }
 
@@ -62,7 +66,7 @@ func (h *ImageHandler) Prepare(ctx context.Context) (*domain.PublishMessage, err
 		Headers: map[string]string{
 			"content-type": contentType,
 			"filename":     filepath.Base(h.imagePath),
-			"timestamp":    time.Now().Format(time.RFC3339),
+			"timestamp":    h.clock.Now().Format(time.RFC3339),
 		},
 	}, nil
 }
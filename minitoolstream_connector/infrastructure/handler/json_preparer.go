@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// JSONPreparer publishes a Go value encoded as JSON
+type JSONPreparer struct {
+	subject   string
+	value     interface{}
+	data      []byte
+	err       error
+	remarshal bool
+	headers   map[string]string
+	dedupKey  string
+	clock     domain.Clock
+}
+
+// NewJSONPreparer creates a preparer that publishes v as JSON. The value is
+// encoded once, when the preparer is created; use WithRemarshal for values
+// that change between publishes.
+func NewJSONPreparer(subject string, v interface{}) *JSONPreparer {
+	data, err := json.Marshal(v)
+	return &JSONPreparer{
+		subject: subject,
+		value:   v,
+		data:    data,
+		err:     err,
+		headers: make(map[string]string),
+		clock:   domain.SystemClock,
+	}
+}
+
+// WithRemarshal encodes the value on every Prepare, so each publish reflects
+// its current state. The value must not be modified concurrently with Prepare.
+func (p *JSONPreparer) WithRemarshal() *JSONPreparer {
+	p.remarshal = true
+	return p
+}
+
+// WithHeaders adds custom headers to the preparer
+func (p *JSONPreparer) WithHeaders(headers map[string]string) *JSONPreparer {
+	for k, v := range headers {
+		p.headers[k] = v
+	}
+	return p
+}
+
+// WithDedupKey sets the dedup key of the published message
+func (p *JSONPreparer) WithDedupKey(key string) *JSONPreparer {
+	p.dedupKey = key
+	return p
+}
+
+// WithClock sets the clock that stamps the timestamp header
+func (p *JSONPreparer) WithClock(clock domain.Clock) *JSONPreparer {
+	p.clock = domain.ClockOrSystem(clock)
+	return p
+}
+
+// Prepare encodes the value for publishing
+func (p *JSONPreparer) Prepare(ctx context.Context) (*domain.PublishMessage, error) {
+	data, err := p.data, p.err
+	if p.remarshal {
+		data, err = json.Marshal(p.value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %T as JSON: %w", p.value, err)
+	}
+
+	headers := make(map[string]string, len(p.headers)+2)
+	headers["content-type"] = "application/json"
+	headers["timestamp"] = p.clock.Now().Format(time.RFC3339)
+	for k, v := range p.headers {
+		headers[k] = v
+	}
+
+	return &domain.PublishMessage{
+		Subject:  p.subject,
+		Data:     data,
+		Headers:  headers,
+		DedupKey: p.dedupKey,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"testing"
+)
+
+type order struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
+
+func TestJSONPreparer_Prepare(t *testing.T) {
+	t.Run("encodes value", func(t *testing.T) {
+		msg, err := NewJSONPreparer("orders", &order{ID: 1, Status: "new"}).
+			WithHeaders(map[string]string{"source": "api"}).
+			WithDedupKey("order-1").
+			Prepare(context.Background())
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		if string(msg.Data) != `{"id":1,"status":"new"}` {
+			t.Errorf("unexpected data %s", msg.Data)
+		}
+		if msg.Subject != "orders" || msg.DedupKey != "order-1" {
+			t.Errorf("unexpected message %s with dedup key %q", msg.Subject, msg.DedupKey)
+		}
+		if msg.Headers["content-type"] != "application/json" || msg.Headers["source"] != "api" {
+			t.Errorf("unexpected headers %v", msg.Headers)
+		}
+	})
+
+	t.Run("snapshot by default", func(t *testing.T) {
+		o := &order{ID: 1, Status: "new"}
+		p := NewJSONPreparer("orders", o)
+		o.Status = "paid"
+
+		msg, _ := p.Prepare(context.Background())
+		if string(msg.Data) != `{"id":1,"status":"new"}` {
+			t.Errorf("expected value at creation, got %s", msg.Data)
+		}
+	})
+
+	t.Run("remarshal", func(t *testing.T) {
+		o := &order{ID: 1, Status: "new"}
+		p := NewJSONPreparer("orders", o).WithRemarshal()
+		o.Status = "paid"
+
+		msg, _ := p.Prepare(context.Background())
+		if string(msg.Data) != `{"id":1,"status":"paid"}` {
+			t.Errorf("expected current value, got %s", msg.Data)
+		}
+	})
+
+	t.Run("unsupported value", func(t *testing.T) {
+		if _, err := NewJSONPreparer("orders", make(chan int)).Prepare(context.Background()); err == nil {
+			t.Error("expected encoding error")
+		}
+	})
+}
@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// ErrKeyNotFound is returned by key providers for unknown key IDs
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeyProvider looks up encryption keys by ID. AES keys are 16, 24 or 32 bytes.
+type KeyProvider interface {
+	Key(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// StaticKeys is a KeyProvider backed by an in-memory map
+type StaticKeys map[string][]byte
+
+// Key returns the key with the given ID
+func (k StaticKeys) Key(ctx context.Context, keyID string) ([]byte, error) {
+	key, ok := k[keyID]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// FileKeyProvider reads keys from a JSON file mapping key IDs to base64
+// encoded keys. The file is re-read when it changes, so keys can be rotated
+// without a restart.
+type FileKeyProvider struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	keys    StaticKeys
+}
+
+// NewFileKeyProvider creates a key provider reading path
+func NewFileKeyProvider(path string) (*FileKeyProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+
+	p := &FileKeyProvider{path: path}
+	if _, err := p.load(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Key returns the key with the given ID
+func (p *FileKeyProvider) Key(ctx context.Context, keyID string) ([]byte, error) {
+	keys, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	return keys.Key(ctx, keyID)
+}
+
+// load returns the keys, re-reading the file if it was modified
+func (p *FileKeyProvider) load() (StaticKeys, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat key file: %w", err)
+	}
+	if p.keys != nil && info.ModTime().Equal(p.modTime) {
+		return p.keys, nil
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	var encoded map[string]string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("failed to parse key file %s: %w", p.path, err)
+	}
+
+	keys := make(StaticKeys, len(encoded))
+	for id, value := range encoded {
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("key %s in %s is not valid base64: %w", id, p.path, err)
+		}
+		keys[id] = key
+	}
+
+	p.keys = keys
+	p.modTime = info.ModTime()
+	return keys, nil
+}
+
+// VaultKeyProvider reads keys from a HashiCorp Vault KV version 2 secrets
+// engine. Each key is a secret at Path/<key-id> holding the base64 encoded key
+// in Field. Keys are cached for CacheTTL.
+type VaultKeyProvider struct {
+	address  string
+	token    string
+	mount    string
+	path     string
+	field    string
+	cacheTTL time.Duration
+	client   *http.Client
+	clock    domain.Clock
+
+	mu    sync.Mutex
+	cache map[string]cachedKey
+}
+
+// VaultKeyProviderConfig represents configuration for VaultKeyProvider
+type VaultKeyProviderConfig struct {
+	// Address is the Vault server URL, e.g. https://vault.example.com:8200
+	Address string
+	Token   string
+	// Mount is the KV engine mount (default "secret")
+	Mount string
+	// Path is the secret path prefix below the mount, e.g. "minitoolstream/keys"
+	Path string
+	// Field is the secret field holding the key (default "key")
+	Field string
+	// CacheTTL is how long keys are cached (default 5m)
+	CacheTTL time.Duration
+	Client   *http.Client
+	// Clock expires cached keys (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// cachedKey is a key with its fetch time
+type cachedKey struct {
+	key     []byte
+	fetched time.Time
+}
+
+// NewVaultKeyProvider creates a key provider reading from Vault
+func NewVaultKeyProvider(config *VaultKeyProviderConfig) (*VaultKeyProvider, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+
+	if config.Token == "" {
+		return nil, fmt.Errorf("token is required")
+	}
+
+	p := &VaultKeyProvider{
+		address:  strings.TrimSuffix(config.Address, "/"),
+		token:    config.Token,
+		mount:    strings.Trim(config.Mount, "/"),
+		path:     strings.Trim(config.Path, "/"),
+		field:    config.Field,
+		cacheTTL: config.CacheTTL,
+		client:   config.Client,
+		clock:    domain.ClockOrSystem(config.Clock),
+		cache:    make(map[string]cachedKey),
+	}
+	if p.mount == "" {
+		p.mount = "secret"
+	}
+	if p.field == "" {
+		p.field = "key"
+	}
+	if p.cacheTTL <= 0 {
+		p.cacheTTL = 5 * time.Minute
+	}
+	if p.client == nil {
+		p.client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return p, nil
+}
+
+// Key returns the key with the given ID
+func (p *VaultKeyProvider) Key(ctx context.Context, keyID string) ([]byte, error) {
+	p.mu.Lock()
+	cached, ok := p.cache[keyID]
+	p.mu.Unlock()
+	if ok && p.clock.Now().Sub(cached.fetched) < p.cacheTTL {
+		return cached.key, nil
+	}
+
+	key, err := p.fetch(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.cache[keyID] = cachedKey{key: key, fetched: p.clock.Now()}
+	p.mu.Unlock()
+	return key, nil
+}
+
+// fetch reads a key secret from Vault
+func (p *VaultKeyProvider) fetch(ctx context.Context, keyID string) ([]byte, error) {
+	secretPath := url.PathEscape(keyID)
+	if p.path != "" {
+		secretPath = p.path + "/" + secretPath
+	}
+	endpoint := p.address + "/v1/" + p.mount + "/data/" + secretPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key from Vault: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrKeyNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("Vault returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("failed to decode Vault response: %w", err)
+	}
+
+	value, ok := secret.Data.Data[p.field].(string)
+	if !ok {
+		return nil, fmt.Errorf("secret %s has no field %s", secretPath, p.field)
+	}
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("field %s of secret %s is not valid base64: %w", p.field, secretPath, err)
+	}
+	return key, nil
+}
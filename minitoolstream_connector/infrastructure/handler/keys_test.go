@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+)
+
+func TestFileKeyProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	writeKeys := func(keys string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(keys), 0600); err != nil {
+			t.Fatal(err)
+		}
+		os.Chtimes(path, modTime, modTime)
+	}
+
+	now := time.Now()
+	writeKeys(`{"k1":"`+base64.StdEncoding.EncodeToString([]byte("0123456789abcdef"))+`"}`, now)
+
+	p, err := NewFileKeyProvider(path)
+	if err != nil {
+		t.Fatalf("NewFileKeyProvider failed: %v", err)
+	}
+
+	key, err := p.Key(context.Background(), "k1")
+	if err != nil || string(key) != "0123456789abcdef" {
+		t.Errorf("unexpected key %q (%v)", key, err)
+	}
+	if _, err := p.Key(context.Background(), "k2"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+
+	// Rotation: a rewritten file is picked up
+	writeKeys(`{"k2":"`+base64.StdEncoding.EncodeToString([]byte("fedcba9876543210"))+`"}`, now.Add(time.Second))
+	key, err = p.Key(context.Background(), "k2")
+	if err != nil || string(key) != "fedcba9876543210" {
+		t.Errorf("expected rotated key, got %q (%v)", key, err)
+	}
+
+	if _, err := NewFileKeyProvider(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+	writeKeys(`{"k1":"not base64!"}`, now.Add(2*time.Second))
+	if _, err := NewFileKeyProvider(path); err == nil {
+		t.Error("expected error for invalid key")
+	}
+}
+
+func TestNewVaultKeyProvider(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *VaultKeyProviderConfig
+	}{
+		{"nil config", nil},
+		{"missing address", &VaultKeyProviderConfig{Token: "t"}},
+		{"missing token", &VaultKeyProviderConfig{Address: "http://localhost:8200"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewVaultKeyProvider(tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestVaultKeyProvider_Key(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("X-Vault-Token") != "root" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/stream/keys/k1":
+			fmt.Fprintf(w, `{"data":{"data":{"value":%q},"metadata":{"version":3}}}`, base64.StdEncoding.EncodeToString([]byte("vault-key-0123456789abcdef012345")))
+		case "/v1/kv/data/stream/keys/bad":
+			fmt.Fprint(w, `{"data":{"data":{"other":"x"}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	clock := connectortest.NewFakeClock(time.Time{})
+	p, err := NewVaultKeyProvider(&VaultKeyProviderConfig{
+		Address:  srv.URL + "/",
+		Token:    "root",
+		Mount:    "kv",
+		Path:     "/stream/keys/",
+		Field:    "value",
+		CacheTTL: time.Minute,
+		Clock:    clock,
+	})
+	if err != nil {
+		t.Fatalf("NewVaultKeyProvider failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		key, err := p.Key(context.Background(), "k1")
+		if err != nil || string(key) != "vault-key-0123456789abcdef012345" {
+			t.Fatalf("unexpected key %q (%v)", key, err)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected cached key, got %d requests", n)
+	}
+
+	clock.Advance(time.Minute)
+	if _, err := p.Key(context.Background(), "k1"); err != nil {
+		t.Fatalf("Key failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected expired key to be fetched again, got %d requests", n)
+	}
+
+	if _, err := p.Key(context.Background(), "missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected ErrKeyNotFound, got %v", err)
+	}
+	if _, err := p.Key(context.Background(), "bad"); err == nil {
+		t.Error("expected error for secret without key field")
+	}
+
+	denied, _ := NewVaultKeyProvider(&VaultKeyProviderConfig{Address: srv.URL, Token: "wrong", Mount: "kv", Path: "stream/keys"})
+	if _, err := denied.Key(context.Background(), "k1"); err == nil {
+		t.Error("expected error for denied request")
+	}
+}
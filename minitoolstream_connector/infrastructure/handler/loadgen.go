@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// LoadPattern selects the payloads generated by LoadGenHandler
+type LoadPattern string
+
+// Load patterns
+const (
+	// LoadPatternRandom sends random bytes of a size between MinSize and MaxSize
+	LoadPatternRandom LoadPattern = "random"
+	// LoadPatternSequential sends the sequence number padded to a size between
+	// MinSize and MaxSize, so consumers can check for gaps and order
+	LoadPatternSequential LoadPattern = "sequential"
+	// LoadPatternReplay sends the lines of SampleFile in a loop
+	LoadPatternReplay LoadPattern = "replay"
+)
+
+// LoadGenHandler generates synthetic messages for load-testing a deployment.
+// It is a preparer producing a new payload on every Prepare, and Run drives a
+// publisher with it at a configured rate.
+type LoadGenHandler struct {
+	subject     string
+	pattern     LoadPattern
+	minSize     int
+	maxSize     int
+	samples     [][]byte
+	publisher   domain.Publisher
+	rate        float64
+	count       int64
+	duration    time.Duration
+	concurrency int
+	logger      Logger
+	clock       domain.Clock
+
+	seq  atomic.Int64
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// LoadGenConfig represents configuration for LoadGenHandler
+type LoadGenConfig struct {
+	Subject string
+	// Pattern selects the payloads (default LoadPatternRandom)
+	Pattern LoadPattern
+	// MinSize and MaxSize bound the payload size in bytes (default 1 KiB);
+	// MaxSize defaults to MinSize
+	MinSize int
+	MaxSize int
+	// SampleFile is the file replayed line by line by LoadPatternReplay
+	SampleFile string
+	// Seed makes random payloads reproducible; 0 uses the current time
+	Seed int64
+
+	// Publisher is used by Run; it is not needed to use the handler as a preparer
+	Publisher domain.Publisher
+	// Rate is the target number of messages per second for Run; 0 publishes
+	// as fast as the publisher allows
+	Rate float64
+	// Count stops Run after this many messages; 0 means no limit
+	Count int64
+	// Duration stops Run after this long; 0 means no limit
+	Duration time.Duration
+	// Concurrency is the number of concurrent publishes in Run (default 1)
+	Concurrency int
+	Logger      Logger
+	// Clock paces Run, bounds its Duration and stamps the loadgen-sent-at
+	// header (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// LoadGenStats summarizes a load generator run
+type LoadGenStats struct {
+	Sent    int64
+	Failed  int64
+	Bytes   int64
+	Elapsed time.Duration
+}
+
+// Rate returns the achieved number of successful messages per second
+func (s LoadGenStats) Rate() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Sent) / s.Elapsed.Seconds()
+}
+
+// NewLoadGenHandler creates a new load generator
+func NewLoadGenHandler(config *LoadGenConfig) (*LoadGenHandler, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Subject == "" {
+		return nil, fmt.Errorf("subject is required")
+	}
+
+	pattern := config.Pattern
+	if pattern == "" {
+		pattern = LoadPatternRandom
+	}
+
+	minSize := config.MinSize
+	if minSize <= 0 {
+		minSize = 1024
+	}
+	maxSize := config.MaxSize
+	if maxSize <= 0 {
+		maxSize = minSize
+	}
+	if maxSize < minSize {
+		return nil, fmt.Errorf("max size %d is smaller than min size %d", maxSize, minSize)
+	}
+
+	if config.Rate < 0 {
+		return nil, fmt.Errorf("rate cannot be negative")
+	}
+
+	var samples [][]byte
+	switch pattern {
+	case LoadPatternRandom, LoadPatternSequential:
+	case LoadPatternReplay:
+		data, err := os.ReadFile(config.SampleFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sample file: %w", err)
+		}
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			if line = bytes.TrimSuffix(line, []byte("\r")); len(line) > 0 {
+				samples = append(samples, line)
+			}
+		}
+		if len(samples) == 0 {
+			return nil, fmt.Errorf("sample file %s is empty", config.SampleFile)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported load pattern %q", pattern)
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = &defaultLogger{}
+	}
+
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	clock := domain.ClockOrSystem(config.Clock)
+
+	seed := config.Seed
+	if seed == 0 {
+		seed = clock.Now().UnixNano()
+	}
+
+	return &LoadGenHandler{
+		subject:     config.Subject,
+		pattern:     pattern,
+		minSize:     minSize,
+		maxSize:     maxSize,
+		samples:     samples,
+		publisher:   config.Publisher,
+		rate:        config.Rate,
+		count:       config.Count,
+		duration:    config.Duration,
+		concurrency: concurrency,
+		logger:      logger,
+		clock:       clock,
+		rand:        rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// Prepare generates the next message. It is safe for concurrent use.
+func (h *LoadGenHandler) Prepare(ctx context.Context) (*domain.PublishMessage, error) {
+	seq := h.seq.Add(1) - 1
+
+	var data []byte
+	contentType := "application/octet-stream"
+	switch h.pattern {
+	case LoadPatternRandom:
+		h.mu.Lock()
+		data = make([]byte, h.size())
+		h.rand.Read(data)
+		h.mu.Unlock()
+	case LoadPatternSequential:
+		h.mu.Lock()
+		size := h.size()
+		h.mu.Unlock()
+		data = sequentialPayload(seq, size)
+		contentType = "text/plain"
+	case LoadPatternReplay:
+		data = h.samples[seq%int64(len(h.samples))]
+		contentType = "text/plain"
+	}
+
+	return &domain.PublishMessage{
+		Subject: h.subject,
+		Data:    data,
+		Headers: map[string]string{
+			"content-type":    contentType,
+			"loadgen-pattern": string(h.pattern),
+			"loadgen-seq":     strconv.FormatInt(seq, 10),
+			"loadgen-sent-at": strconv.FormatInt(h.clock.Now().UnixNano(), 10),
+		},
+	}, nil
+}
+
+// size returns a random payload size; the caller holds h.mu
+func (h *LoadGenHandler) size() int {
+	if h.maxSize == h.minSize {
+		return h.minSize
+	}
+	return h.minSize + h.rand.Intn(h.maxSize-h.minSize+1)
+}
+
+// sequentialPayload returns seq followed by padding up to size bytes
+func sequentialPayload(seq int64, size int) []byte {
+	data := strconv.AppendInt(nil, seq, 10)
+	for len(data) < size {
+		data = append(data, '.')
+	}
+	return data
+}
+
+// Run publishes generated messages until Count or Duration is reached or ctx is
+// cancelled, and returns the statistics of the run. Failed publishes are
+// counted, not retried beyond what the publisher does itself.
+func (h *LoadGenHandler) Run(ctx context.Context) (LoadGenStats, error) {
+	if h.publisher == nil {
+		return LoadGenStats{}, fmt.Errorf("publisher is required to run the load generator")
+	}
+
+	if h.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			select {
+			case <-ctx.Done():
+			case <-h.clock.After(h.duration):
+				cancel()
+			}
+		}()
+	}
+
+	h.logger.Printf("✓ Load generator started: subject=%s pattern=%s rate=%v concurrency=%d",
+		h.subject, h.pattern, h.rate, h.concurrency)
+
+	var stats LoadGenStats
+	tokens := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < h.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range tokens {
+				h.publishOne(ctx, &stats)
+			}
+		}()
+	}
+
+	started := h.clock.Now()
+	h.schedule(ctx, tokens)
+	close(tokens)
+	wg.Wait()
+	stats.Elapsed = h.clock.Now().Sub(started)
+
+	h.logger.Printf("✓ Load generator finished: sent=%d failed=%d bytes=%d rate=%.1f/s",
+		stats.Sent, stats.Failed, stats.Bytes, stats.Rate())
+	return stats, nil
+}
+
+// schedule hands out one token per message at the configured rate
+func (h *LoadGenHandler) schedule(ctx context.Context, tokens chan<- struct{}) {
+	var interval time.Duration
+	if h.rate > 0 {
+		interval = time.Duration(float64(time.Second) / h.rate)
+	}
+
+	next := h.clock.Now()
+	for n := int64(0); h.count == 0 || n < h.count; n++ {
+		if interval > 0 {
+			if wait := next.Sub(h.clock.Now()); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-h.clock.After(wait):
+				}
+			}
+			next = next.Add(interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case tokens <- struct{}{}:
+		}
+	}
+}
+
+// publishOne publishes a generated message and records the outcome
+func (h *LoadGenHandler) publishOne(ctx context.Context, stats *LoadGenStats) {
+	var size int
+	err := h.publisher.Publish(ctx, domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		msg, err := h.Prepare(ctx)
+		if msg != nil {
+			size = len(msg.Data)
+		}
+		return msg, err
+	}))
+	if err != nil {
+		atomic.AddInt64(&stats.Failed, 1)
+		return
+	}
+	atomic.AddInt64(&stats.Sent, 1)
+	atomic.AddInt64(&stats.Bytes, int64(size))
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+)
+
+func TestNewLoadGenHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *LoadGenConfig
+	}{
+		{"nil config", nil},
+		{"empty subject", &LoadGenConfig{}},
+		{"max below min", &LoadGenConfig{Subject: "load", MinSize: 100, MaxSize: 10}},
+		{"negative rate", &LoadGenConfig{Subject: "load", Rate: -1}},
+		{"unknown pattern", &LoadGenConfig{Subject: "load", Pattern: "burst"}},
+		{"missing sample file", &LoadGenConfig{Subject: "load", Pattern: LoadPatternReplay, SampleFile: "missing.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLoadGenHandler(tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestLoadGenHandler_Prepare(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("random", func(t *testing.T) {
+		newHandler := func() *LoadGenHandler {
+			h, err := NewLoadGenHandler(&LoadGenConfig{Subject: "load", MinSize: 10, MaxSize: 20, Seed: 42, Logger: &testLogger{}})
+			if err != nil {
+				t.Fatalf("NewLoadGenHandler failed: %v", err)
+			}
+			return h
+		}
+		a, b := newHandler(), newHandler()
+
+		for i := 0; i < 50; i++ {
+			msgA, _ := a.Prepare(ctx)
+			msgB, _ := b.Prepare(ctx)
+			if len(msgA.Data) < 10 || len(msgA.Data) > 20 {
+				t.Fatalf("size %d out of range", len(msgA.Data))
+			}
+			if !bytes.Equal(msgA.Data, msgB.Data) {
+				t.Fatal("expected the same payloads for the same seed")
+			}
+		}
+	})
+
+	t.Run("sequential", func(t *testing.T) {
+		h, _ := NewLoadGenHandler(&LoadGenConfig{Subject: "load", Pattern: LoadPatternSequential, MinSize: 8, Logger: &testLogger{}})
+		for i, want := range []string{"0.......", "1......."} {
+			msg, _ := h.Prepare(ctx)
+			if string(msg.Data) != want {
+				t.Errorf("message %d: expected %q, got %q", i, want, msg.Data)
+			}
+			if msg.Subject != "load" || msg.Headers["loadgen-seq"] != string(rune('0'+i)) || msg.Headers["loadgen-pattern"] != "sequential" {
+				t.Errorf("unexpected message %s %v", msg.Subject, msg.Headers)
+			}
+			if msg.Headers["loadgen-sent-at"] == "" {
+				t.Error("expected loadgen-sent-at header")
+			}
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		sample := filepath.Join(t.TempDir(), "sample.ndjson")
+		os.WriteFile(sample, []byte("{\"a\":1}\r\n\n{\"b\":2}\n"), 0644)
+
+		h, err := NewLoadGenHandler(&LoadGenConfig{Subject: "load", Pattern: LoadPatternReplay, SampleFile: sample, Logger: &testLogger{}})
+		if err != nil {
+			t.Fatalf("NewLoadGenHandler failed: %v", err)
+		}
+		for i, want := range []string{`{"a":1}`, `{"b":2}`, `{"a":1}`} {
+			msg, _ := h.Prepare(ctx)
+			if string(msg.Data) != want {
+				t.Errorf("message %d: expected %s, got %s", i, want, msg.Data)
+			}
+		}
+	})
+}
+
+func TestLoadGenHandler_Run(t *testing.T) {
+	t.Run("count", func(t *testing.T) {
+		pub := &mockPublisher{}
+		h, _ := NewLoadGenHandler(&LoadGenConfig{
+			Subject:     "load",
+			MinSize:     100,
+			Publisher:   pub,
+			Count:       25,
+			Concurrency: 4,
+			Logger:      &testLogger{},
+		})
+
+		stats, err := h.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if stats.Sent != 25 || stats.Failed != 0 || stats.Bytes != 2500 || len(pub.data()) != 25 {
+			t.Errorf("unexpected stats %+v (published %d)", stats, len(pub.data()))
+		}
+	})
+
+	t.Run("rate and duration", func(t *testing.T) {
+		pub := &mockPublisher{}
+		h, _ := NewLoadGenHandler(&LoadGenConfig{
+			Subject:   "load",
+			Publisher: pub,
+			Rate:      100,
+			Duration:  200 * time.Millisecond,
+			Logger:    &testLogger{},
+		})
+
+		stats, err := h.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		// About 20 messages are due in 200ms; leave room for slow machines
+		if stats.Sent < 5 || stats.Sent > 25 {
+			t.Errorf("expected about 20 messages at 100/s for 200ms, got %d", stats.Sent)
+		}
+	})
+
+	t.Run("paced by the clock", func(t *testing.T) {
+		start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		clock := connectortest.NewFakeClock(start)
+		pub := &mockPublisher{}
+		h, _ := NewLoadGenHandler(&LoadGenConfig{
+			Subject:   "load",
+			Pattern:   LoadPatternSequential,
+			Publisher: pub,
+			Rate:      1,
+			Count:     3,
+			Logger:    &testLogger{},
+			Clock:     clock,
+		})
+
+		done := make(chan LoadGenStats, 1)
+		go func() {
+			stats, _ := h.Run(context.Background())
+			done <- stats
+		}()
+		for i := 0; i < 2; i++ {
+			clock.WaitForWaiters(t, 1, 2*time.Second)
+			clock.Advance(time.Second)
+		}
+
+		var stats LoadGenStats
+		select {
+		case stats = <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Run did not finish after the clock advanced")
+		}
+		if stats.Sent != 3 || stats.Elapsed != 2*time.Second {
+			t.Errorf("expected 3 messages in 2s, got %+v", stats)
+		}
+
+		msg, _ := h.Prepare(context.Background())
+		if want := strconv.FormatInt(start.Add(2*time.Second).UnixNano(), 10); msg.Headers["loadgen-sent-at"] != want {
+			t.Errorf("expected loadgen-sent-at %s, got %s", want, msg.Headers["loadgen-sent-at"])
+		}
+	})
+
+	t.Run("failures are counted", func(t *testing.T) {
+		pub := &mockPublisher{failures: 3}
+		h, _ := NewLoadGenHandler(&LoadGenConfig{Subject: "load", Publisher: pub, Count: 5, Logger: &testLogger{}})
+
+		stats, _ := h.Run(context.Background())
+		if stats.Sent != 2 || stats.Failed != 3 {
+			t.Errorf("expected 2 sent and 3 failed, got %+v", stats)
+		}
+	})
+
+	t.Run("publisher required", func(t *testing.T) {
+		h, _ := NewLoadGenHandler(&LoadGenConfig{Subject: "load", Logger: &testLogger{}})
+		if _, err := h.Run(context.Background()); err == nil {
+			t.Error("expected error without publisher")
+		}
+	})
+}
@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// MetricType is the Prometheus type of an emitted metric
+type MetricType string
+
+// Metric types
+const (
+	// MetricGauge sets the metric to the latest value
+	MetricGauge MetricType = "gauge"
+	// MetricCounter adds each (non-negative) value to the metric
+	MetricCounter MetricType = "counter"
+)
+
+var (
+	metricNamePattern = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNamePattern  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// MetricSpec describes a metric extracted from message payloads
+type MetricSpec struct {
+	Name string
+	Help string
+	// Type defaults to MetricGauge
+	Type MetricType
+	// Subject restricts the metric to one subject; empty matches all subjects
+	Subject string
+	// Field is the dot path of the value in a JSON payload, e.g. "cpu.load.0";
+	// empty parses the whole payload as a number
+	Field string
+	// Labels maps label names to dot paths of their values in the payload.
+	// Every series also carries a subject label.
+	Labels map[string]string
+}
+
+// MetricsEmitter extracts numeric values from messages and exposes them as
+// Prometheus metrics. It is a message handler and an http.Handler serving the
+// text exposition format, e.g. on /metrics.
+type MetricsEmitter struct {
+	namespace string
+	specs     []MetricSpec
+	logger    Logger
+
+	mu       sync.Mutex
+	families map[string]*metricFamily
+}
+
+// MetricsEmitterConfig represents configuration for MetricsEmitter
+type MetricsEmitterConfig struct {
+	// Namespace prefixes metric names, separated by an underscore
+	Namespace string
+	Metrics   []MetricSpec
+	Logger    Logger
+}
+
+// metricFamily holds the series of one metric
+type metricFamily struct {
+	spec   MetricSpec
+	series map[string]*metricSeries
+}
+
+// metricSeries is a metric value with its labels
+type metricSeries struct {
+	labels string
+	value  float64
+}
+
+// NewMetricsEmitter creates a new metrics emitter handler
+func NewMetricsEmitter(config *MetricsEmitterConfig) (*MetricsEmitter, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if len(config.Metrics) == 0 {
+		return nil, fmt.Errorf("at least one metric is required")
+	}
+
+	e := &MetricsEmitter{
+		namespace: config.Namespace,
+		logger:    config.Logger,
+		families:  make(map[string]*metricFamily),
+	}
+	if e.logger == nil {
+		e.logger = &defaultLogger{}
+	}
+
+	for _, spec := range config.Metrics {
+		if e.namespace != "" {
+			spec.Name = e.namespace + "_" + spec.Name
+		}
+		if !metricNamePattern.MatchString(spec.Name) {
+			return nil, fmt.Errorf("invalid metric name %q", spec.Name)
+		}
+		if spec.Type == "" {
+			spec.Type = MetricGauge
+		}
+		if spec.Type != MetricGauge && spec.Type != MetricCounter {
+			return nil, fmt.Errorf("metric %s: unsupported type %q", spec.Name, spec.Type)
+		}
+		for label := range spec.Labels {
+			if !labelNamePattern.MatchString(label) || label == "subject" {
+				return nil, fmt.Errorf("metric %s: invalid label name %q", spec.Name, label)
+			}
+		}
+		if _, ok := e.families[spec.Name]; ok {
+			return nil, fmt.Errorf("duplicate metric %s", spec.Name)
+		}
+
+		e.specs = append(e.specs, spec)
+		e.families[spec.Name] = &metricFamily{spec: spec, series: make(map[string]*metricSeries)}
+	}
+
+	return e, nil
+}
+
+// Handle updates the metrics matching the message subject. Metrics whose
+// field is missing from the payload are left unchanged.
+func (e *MetricsEmitter) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	var payload interface{}
+	decoded := false
+
+	for _, spec := range e.specs {
+		if spec.Subject != "" && spec.Subject != msg.Subject {
+			continue
+		}
+
+		if !decoded && (spec.Field != "" || len(spec.Labels) > 0) {
+			dec := json.NewDecoder(bytes.NewReader(msg.Data))
+			dec.UseNumber()
+			if err := dec.Decode(&payload); err != nil {
+				return fmt.Errorf("failed to decode payload of sequence %d: %w", msg.Sequence, err)
+			}
+			decoded = true
+		}
+
+		var raw interface{} = strings.TrimSpace(string(msg.Data))
+		if spec.Field != "" {
+			var ok bool
+			if raw, ok = lookupPath(payload, spec.Field); !ok {
+				e.logger.Printf("[%s] Metric %s: no field %s in sequence %d", msg.Subject, spec.Name, spec.Field, msg.Sequence)
+				continue
+			}
+		}
+		value, err := toFloat(raw)
+		if err != nil {
+			return fmt.Errorf("metric %s: %w", spec.Name, err)
+		}
+		if spec.Type == MetricCounter && value < 0 {
+			return fmt.Errorf("metric %s: counter cannot decrease by %v", spec.Name, value)
+		}
+
+		e.record(spec, e.labels(spec, msg.Subject, payload), value)
+	}
+	return nil
+}
+
+// labels renders the label set of a series
+func (e *MetricsEmitter) labels(spec MetricSpec, subject string, payload interface{}) string {
+	names := make([]string, 0, len(spec.Labels))
+	for name := range spec.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := []string{`subject="` + escapeLabelValue(subject) + `"`}
+	for _, name := range names {
+		value := ""
+		if v, ok := lookupPath(payload, spec.Labels[name]); ok {
+			value = fmt.Sprint(v)
+		}
+		pairs = append(pairs, name+`="`+escapeLabelValue(value)+`"`)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// record applies a value to a series
+func (e *MetricsEmitter) record(spec MetricSpec, labels string, value float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	family := e.families[spec.Name]
+	series, ok := family.series[labels]
+	if !ok {
+		series = &metricSeries{labels: labels}
+		family.series[labels] = series
+	}
+
+	if spec.Type == MetricCounter {
+		series.value += value
+	} else {
+		series.value = value
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (e *MetricsEmitter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var b bytes.Buffer
+
+	e.mu.Lock()
+	for _, spec := range e.specs {
+		family := e.families[spec.Name]
+		if len(family.series) == 0 {
+			continue
+		}
+		if spec.Help != "" {
+			fmt.Fprintf(&b, "# HELP %s %s\n", spec.Name, strings.ReplaceAll(strings.ReplaceAll(spec.Help, `\`, `\\`), "\n", `\n`))
+		}
+		fmt.Fprintf(&b, "# TYPE %s %s\n", spec.Name, spec.Type)
+
+		keys := make([]string, 0, len(family.series))
+		for key := range family.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			series := family.series[key]
+			fmt.Fprintf(&b, "%s{%s} %s\n", spec.Name, series.labels, strconv.FormatFloat(series.value, 'g', -1, 64))
+		}
+	}
+	e.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(b.Bytes())
+}
+
+// lookupPath returns the value at a dot path in decoded JSON; numeric segments
+// index arrays
+func lookupPath(v interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return v, true
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			next, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// toFloat converts a JSON number, numeric string or boolean to a float
+func toFloat(v interface{}) (float64, error) {
+	switch value := v.(type) {
+	case json.Number:
+		return value.Float64()
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0, fmt.Errorf("value %q is not numeric", value)
+		}
+		if math.IsNaN(f) {
+			return 0, fmt.Errorf("value %q is not numeric", value)
+		}
+		return f, nil
+	case bool:
+		if value {
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("value %v is not numeric", v)
+	}
+}
+
+// escapeLabelValue escapes a label value for the text exposition format
+func escapeLabelValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestNewMetricsEmitter(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *MetricsEmitterConfig
+	}{
+		{"nil config", nil},
+		{"no metrics", &MetricsEmitterConfig{}},
+		{"invalid name", &MetricsEmitterConfig{Metrics: []MetricSpec{{Name: "cpu-load"}}}},
+		{"invalid type", &MetricsEmitterConfig{Metrics: []MetricSpec{{Name: "cpu", Type: "histogram"}}}},
+		{"invalid label", &MetricsEmitterConfig{Metrics: []MetricSpec{{Name: "cpu", Labels: map[string]string{"host-name": "host"}}}}},
+		{"reserved label", &MetricsEmitterConfig{Metrics: []MetricSpec{{Name: "cpu", Labels: map[string]string{"subject": "s"}}}}},
+		{"duplicate", &MetricsEmitterConfig{Metrics: []MetricSpec{{Name: "cpu"}, {Name: "cpu"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMetricsEmitter(tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// scrape returns the exposition output of the emitter
+func scrape(t *testing.T, e *MetricsEmitter) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %s", rec.Header().Get("Content-Type"))
+	}
+	return rec.Body.String()
+}
+
+func TestMetricsEmitter_Handle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("JSON fields", func(t *testing.T) {
+		e, err := NewMetricsEmitter(&MetricsEmitterConfig{
+			Namespace: "telemetry",
+			Metrics: []MetricSpec{
+				{Name: "cpu_load", Help: "CPU load\naverage", Field: "cpu.load.0", Labels: map[string]string{"host": "host"}},
+				{Name: "requests_total", Type: MetricCounter, Field: "requests", Subject: "telemetry.web"},
+				{Name: "healthy", Field: "healthy"},
+			},
+			Logger: &testLogger{},
+		})
+		if err != nil {
+			t.Fatalf("NewMetricsEmitter failed: %v", err)
+		}
+
+		messages := []string{
+			`{"host":"web-1","cpu":{"load":[0.5,0.4]},"requests":10,"healthy":true}`,
+			`{"host":"web-1","cpu":{"load":[0.75,0.4]},"requests":"5","healthy":false}`,
+			`{"host":"web-\"2\"","cpu":{"load":[1.25]}}`,
+		}
+		for i, data := range messages {
+			msg := &domain.ReceivedMessage{Subject: "telemetry.web", Sequence: uint64(i + 1), Data: []byte(data)}
+			if err := e.Handle(ctx, msg); err != nil {
+				t.Fatalf("Handle failed: %v", err)
+			}
+		}
+		// Other subjects do not feed subject-specific metrics
+		e.Handle(ctx, &domain.ReceivedMessage{Subject: "telemetry.db", Data: []byte(`{"requests":100}`)})
+
+		want := `# HELP telemetry_cpu_load CPU load\naverage
+# TYPE telemetry_cpu_load gauge
+telemetry_cpu_load{subject="telemetry.web",host="web-1"} 0.75
+telemetry_cpu_load{subject="telemetry.web",host="web-\"2\""} 1.25
+# TYPE telemetry_requests_total counter
+telemetry_requests_total{subject="telemetry.web"} 15
+# TYPE telemetry_healthy gauge
+telemetry_healthy{subject="telemetry.web"} 0
+`
+		if got := scrape(t, e); got != want {
+			t.Errorf("unexpected exposition:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("plain numeric payload", func(t *testing.T) {
+		e, _ := NewMetricsEmitter(&MetricsEmitterConfig{Metrics: []MetricSpec{{Name: "temperature"}}, Logger: &testLogger{}})
+
+		if err := e.Handle(ctx, &domain.ReceivedMessage{Subject: "sensors.t1", Data: []byte("21.5\n")}); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+		if got := scrape(t, e); !strings.Contains(got, `temperature{subject="sensors.t1"} 21.5`) {
+			t.Errorf("unexpected exposition:\n%s", got)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		e, _ := NewMetricsEmitter(&MetricsEmitterConfig{
+			Metrics: []MetricSpec{{Name: "errors_total", Type: MetricCounter, Field: "errors"}},
+			Logger:  &testLogger{},
+		})
+
+		for _, data := range []string{`not json`, `{"errors":"many"}`, `{"errors":-1}`, `{"errors":{"a":1}}`} {
+			if err := e.Handle(ctx, &domain.ReceivedMessage{Subject: "app", Data: []byte(data)}); err == nil {
+				t.Errorf("expected error for %s", data)
+			}
+		}
+		if got := scrape(t, e); got != "" {
+			t.Errorf("expected no series, got:\n%s", got)
+		}
+	})
+}
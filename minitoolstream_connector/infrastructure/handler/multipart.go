@@ -0,0 +1,426 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Multipart message headers
+const (
+	headerUploadID     = "upload-id"
+	headerPartIndex    = "part-index"
+	headerPartChecksum = "part-checksum"
+)
+
+// defaultPartSize is the part size used when none is configured
+const defaultPartSize = 4 << 20
+
+// uploadIDPattern restricts upload IDs to names that are safe as directory names
+var uploadIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Manifest describes a file split into parts by MultipartHandler
+type Manifest struct {
+	UploadID string         `json:"upload_id"`
+	Filename string         `json:"filename"`
+	Size     int64          `json:"size"`
+	SHA256   string         `json:"sha256"`
+	Parts    []ManifestPart `json:"parts"`
+}
+
+// ManifestPart describes one part of a multipart upload
+type ManifestPart struct {
+	Index  int    `json:"index"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// MultipartHandler publishes a large file as parts on a data subject followed
+// by a manifest on a control subject
+type MultipartHandler struct {
+	dataSubject    string
+	controlSubject string
+	filePath       string
+	partSize       int64
+	uploadID       string
+	logger         Logger
+	clock          domain.Clock
+}
+
+// MultipartHandlerConfig represents configuration for MultipartHandler
+type MultipartHandlerConfig struct {
+	DataSubject    string
+	ControlSubject string
+	FilePath       string
+	PartSize       int    // Bytes per part (default: 4 MiB)
+	UploadID       string // Identifies the upload (default: random)
+	Logger         Logger
+	// Clock stamps the timestamp header (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// NewMultipartHandler creates a new multipart upload handler
+func NewMultipartHandler(config *MultipartHandlerConfig) *MultipartHandler {
+	logger := config.Logger
+	if logger == nil {
+		logger = &defaultLogger{}
+	}
+
+	partSize := int64(config.PartSize)
+	if partSize <= 0 {
+		partSize = defaultPartSize
+	}
+
+	return &MultipartHandler{
+		dataSubject:    config.DataSubject,
+		controlSubject: config.ControlSubject,
+		filePath:       config.FilePath,
+		partSize:       partSize,
+		uploadID:       config.UploadID,
+		logger:         logger,
+		clock:          domain.ClockOrSystem(config.Clock),
+	}
+}
+
+// Preparers checksums the file and returns one preparer per part followed by
+// the manifest preparer. Parts are read when they are published, so the file
+// is never held in memory as a whole; a part that changed in the meantime
+// fails to prepare. Publish the manifest after the parts, or let the
+// Reassembler wait for late parts.
+func (h *MultipartHandler) Preparers() ([]domain.MessagePreparer, error) {
+	uploadID := h.uploadID
+	if uploadID == "" {
+		id, err := newUploadID()
+		if err != nil {
+			return nil, err
+		}
+		uploadID = id
+	}
+	if !uploadIDPattern.MatchString(uploadID) {
+		return nil, fmt.Errorf("invalid upload id %q", uploadID)
+	}
+
+	manifest, err := h.buildManifest(uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	preparers := make([]domain.MessagePreparer, 0, len(manifest.Parts)+1)
+	for _, part := range manifest.Parts {
+		preparers = append(preparers, &partPreparer{handler: h, uploadID: uploadID, part: part})
+	}
+	preparers = append(preparers, &manifestPreparer{handler: h, manifest: manifest})
+
+	h.logger.Printf("[%s] Split file: %s (%d bytes, %d parts)", h.dataSubject, h.filePath, manifest.Size, len(manifest.Parts))
+	return preparers, nil
+}
+
+// buildManifest reads the file once and checksums every part
+func (h *MultipartHandler) buildManifest(uploadID string) (*Manifest, error) {
+	f, err := os.Open(h.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", h.filePath, err)
+	}
+	defer f.Close()
+
+	manifest := &Manifest{
+		UploadID: uploadID,
+		Filename: filepath.Base(h.filePath),
+	}
+	whole := sha256.New()
+	for index := 0; ; index++ {
+		part := sha256.New()
+		n, err := io.Copy(io.MultiWriter(part, whole), io.LimitReader(f, h.partSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s: %w", h.filePath, err)
+		}
+		if n == 0 && index > 0 {
+			break
+		}
+		manifest.Parts = append(manifest.Parts, ManifestPart{
+			Index:  index,
+			Size:   n,
+			SHA256: hex.EncodeToString(part.Sum(nil)),
+		})
+		manifest.Size += n
+		if n < h.partSize {
+			break
+		}
+	}
+	manifest.SHA256 = hex.EncodeToString(whole.Sum(nil))
+	return manifest, nil
+}
+
+// newUploadID returns a random upload ID
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate upload id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// partPreparer prepares one part of a multipart upload
+type partPreparer struct {
+	handler  *MultipartHandler
+	uploadID string
+	part     ManifestPart
+}
+
+// Prepare reads the part and prepares it for publishing
+func (p *partPreparer) Prepare(ctx context.Context) (*domain.PublishMessage, error) {
+	h := p.handler
+
+	f, err := os.Open(h.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", h.filePath, err)
+	}
+	defer f.Close()
+
+	data := make([]byte, p.part.Size)
+	if _, err := f.ReadAt(data, int64(p.part.Index)*h.partSize); err != nil {
+		return nil, fmt.Errorf("failed to read part %d of %s: %w", p.part.Index, h.filePath, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != p.part.SHA256 {
+		return nil, fmt.Errorf("part %d of %s changed since it was checksummed", p.part.Index, h.filePath)
+	}
+
+	return &domain.PublishMessage{
+		Subject: h.dataSubject,
+		Data:    data,
+		Headers: map[string]string{
+			"content-type":     "application/octet-stream",
+			headerUploadID:     p.uploadID,
+			headerPartIndex:    strconv.Itoa(p.part.Index),
+			headerPartChecksum: p.part.SHA256,
+			"timestamp":        p.handler.clock.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// manifestPreparer prepares the manifest of a multipart upload
+type manifestPreparer struct {
+	handler  *MultipartHandler
+	manifest *Manifest
+}
+
+// Prepare encodes the manifest for publishing
+func (p *manifestPreparer) Prepare(ctx context.Context) (*domain.PublishMessage, error) {
+	data, err := json.Marshal(p.manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	return &domain.PublishMessage{
+		Subject: p.handler.controlSubject,
+		Data:    data,
+		Headers: map[string]string{
+			"content-type": "application/json",
+			headerUploadID: p.manifest.UploadID,
+			"filename":     p.manifest.Filename,
+			"timestamp":    p.handler.clock.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// Reassembler receives the parts and manifests published by MultipartHandler
+// and writes each complete, verified file to the output directory.
+// Register it for both the data and the control subject; parts and manifest
+// may arrive in any order.
+type Reassembler struct {
+	outputDir  string
+	stagingDir string
+	logger     Logger
+	mu         sync.Mutex
+}
+
+// ReassemblerConfig represents configuration for Reassembler
+type ReassemblerConfig struct {
+	OutputDir  string
+	StagingDir string // Where parts wait for their upload to complete (default: OutputDir/.parts)
+	Logger     Logger
+}
+
+// NewReassembler creates a new multipart upload reassembler
+func NewReassembler(config *ReassemblerConfig) (*Reassembler, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if config.OutputDir == "" {
+		return nil, fmt.Errorf("output directory is required")
+	}
+
+	stagingDir := config.StagingDir
+	if stagingDir == "" {
+		stagingDir = filepath.Join(config.OutputDir, ".parts")
+	}
+	if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = &defaultLogger{}
+	}
+
+	return &Reassembler{
+		outputDir:  config.OutputDir,
+		stagingDir: stagingDir,
+		logger:     logger,
+	}, nil
+}
+
+// Handle stores a part or manifest and assembles the file once the upload is complete
+func (r *Reassembler) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	uploadID := msg.Headers[headerUploadID]
+	if !uploadIDPattern.MatchString(uploadID) {
+		return fmt.Errorf("message %d has no valid upload id", msg.Sequence)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dir := filepath.Join(r.stagingDir, uploadID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	if index, ok := msg.Headers[headerPartIndex]; ok {
+		if err := r.storePart(dir, index, msg); err != nil {
+			return err
+		}
+	} else {
+		var manifest Manifest
+		if err := json.Unmarshal(msg.Data, &manifest); err != nil {
+			return fmt.Errorf("invalid manifest for upload %s: %w", uploadID, err)
+		}
+		if manifest.UploadID != uploadID {
+			return fmt.Errorf("manifest upload id %q does not match header %q", manifest.UploadID, uploadID)
+		}
+		if err := writeFileAtomic(filepath.Join(dir, "manifest.json"), msg.Data); err != nil {
+			return err
+		}
+	}
+
+	return r.assemble(dir)
+}
+
+// storePart verifies a part against its checksum header and stages it
+func (r *Reassembler) storePart(dir, index string, msg *domain.ReceivedMessage) error {
+	n, err := strconv.Atoi(index)
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid part index %q", index)
+	}
+
+	sum := sha256.Sum256(msg.Data)
+	if hex.EncodeToString(sum[:]) != msg.Headers[headerPartChecksum] {
+		return fmt.Errorf("checksum mismatch for part %d of upload %s", n, filepath.Base(dir))
+	}
+
+	return writeFileAtomic(filepath.Join(dir, strconv.Itoa(n)+".part"), msg.Data)
+}
+
+// assemble writes the file once the manifest and all its parts are staged
+func (r *Reassembler) assemble(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, "manifest.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	for _, part := range manifest.Parts {
+		if _, err := os.Stat(partPath(dir, part)); err != nil {
+			// Waiting for more parts
+			return nil
+		}
+	}
+
+	filename := filepath.Base(manifest.Filename)
+	if filename == "." || filename == string(filepath.Separator) {
+		return fmt.Errorf("invalid filename %q in manifest", manifest.Filename)
+	}
+
+	tmp, err := os.CreateTemp(r.outputDir, "."+filename+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	whole := sha256.New()
+	var size int64
+	for _, part := range manifest.Parts {
+		data, err := os.ReadFile(partPath(dir, part))
+		if err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to read part %d: %w", part.Index, err)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != part.SHA256 || int64(len(data)) != part.Size {
+			tmp.Close()
+			return fmt.Errorf("part %d of upload %s does not match the manifest", part.Index, manifest.UploadID)
+		}
+		whole.Write(data)
+		size += int64(len(data))
+		if _, err := tmp.Write(data); err != nil {
+			tmp.Close()
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if size != manifest.Size || hex.EncodeToString(whole.Sum(nil)) != manifest.SHA256 {
+		return fmt.Errorf("upload %s does not match the manifest checksum", manifest.UploadID)
+	}
+
+	target := filepath.Join(r.outputDir, filename)
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		r.logger.Printf("✗ Failed to remove staged parts of upload %s: %v", manifest.UploadID, err)
+	}
+
+	r.logger.Printf("✓ Reassembled %s (%d bytes, %d parts)", target, size, len(manifest.Parts))
+	return nil
+}
+
+// partPath returns where a part is staged
+func partPath(dir string, part ManifestPart) string {
+	return filepath.Join(dir, strconv.Itoa(part.Index)+".part")
+}
+
+// writeFileAtomic writes data to path through a temporary file
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
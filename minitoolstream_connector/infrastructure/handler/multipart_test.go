@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// prepareAll runs the preparers and converts the messages to received messages
+func prepareAll(t *testing.T, preparers []domain.MessagePreparer) []*domain.ReceivedMessage {
+	t.Helper()
+
+	var msgs []*domain.ReceivedMessage
+	for i, p := range preparers {
+		msg, err := p.Prepare(context.Background())
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		msgs = append(msgs, &domain.ReceivedMessage{
+			Subject:  msg.Subject,
+			Sequence: uint64(i + 1),
+			Data:     msg.Data,
+			Headers:  msg.Headers,
+		})
+	}
+	return msgs
+}
+
+func TestMultipartHandler_Preparers(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "video.bin")
+	content := bytes.Repeat([]byte("0123456789"), 25)
+	os.WriteFile(filePath, content, 0644)
+
+	handler := NewMultipartHandler(&MultipartHandlerConfig{
+		DataSubject:    "uploads.data",
+		ControlSubject: "uploads.control",
+		FilePath:       filePath,
+		PartSize:       100,
+		UploadID:       "upload-1",
+		Logger:         &testLogger{},
+	})
+
+	preparers, err := handler.Preparers()
+	if err != nil {
+		t.Fatalf("Preparers failed: %v", err)
+	}
+	if len(preparers) != 4 {
+		t.Fatalf("expected 3 parts and a manifest, got %d preparers", len(preparers))
+	}
+
+	msgs := prepareAll(t, preparers)
+	for i, msg := range msgs[:3] {
+		if msg.Subject != "uploads.data" || msg.Headers["upload-id"] != "upload-1" {
+			t.Errorf("unexpected part %d: subject %s, headers %v", i, msg.Subject, msg.Headers)
+		}
+	}
+	if len(msgs[2].Data) != 50 {
+		t.Errorf("expected last part of 50 bytes, got %d", len(msgs[2].Data))
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(msgs[3].Data, &manifest); err != nil {
+		t.Fatalf("invalid manifest: %v", err)
+	}
+	if msgs[3].Subject != "uploads.control" || manifest.Size != 250 || len(manifest.Parts) != 3 || manifest.Filename != "video.bin" {
+		t.Errorf("unexpected manifest on %s: %+v", msgs[3].Subject, manifest)
+	}
+
+	t.Run("file changed after split", func(t *testing.T) {
+		os.WriteFile(filePath, bytes.Repeat([]byte("x"), 250), 0644)
+		if _, err := preparers[0].Prepare(context.Background()); err == nil {
+			t.Error("expected error for changed part")
+		}
+	})
+
+	t.Run("invalid upload id", func(t *testing.T) {
+		h := NewMultipartHandler(&MultipartHandlerConfig{FilePath: filePath, UploadID: "../etc"})
+		if _, err := h.Preparers(); err == nil {
+			t.Error("expected error for invalid upload id")
+		}
+	})
+}
+
+func TestReassembler(t *testing.T) {
+	split := func(t *testing.T, content []byte) []*domain.ReceivedMessage {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, "report.csv")
+		os.WriteFile(filePath, content, 0644)
+
+		preparers, err := NewMultipartHandler(&MultipartHandlerConfig{
+			DataSubject:    "uploads.data",
+			ControlSubject: "uploads.control",
+			FilePath:       filePath,
+			PartSize:       16,
+			Logger:         &testLogger{},
+		}).Preparers()
+		if err != nil {
+			t.Fatalf("Preparers failed: %v", err)
+		}
+		return prepareAll(t, preparers)
+	}
+	content := bytes.Repeat([]byte("a,b,c\n"), 10)
+
+	newReassembler := func(t *testing.T) (*Reassembler, string) {
+		out := t.TempDir()
+		r, err := NewReassembler(&ReassemblerConfig{OutputDir: out, Logger: &testLogger{}})
+		if err != nil {
+			t.Fatalf("NewReassembler failed: %v", err)
+		}
+		return r, out
+	}
+
+	t.Run("in order", func(t *testing.T) {
+		r, out := newReassembler(t)
+		for _, msg := range split(t, content) {
+			if err := r.Handle(context.Background(), msg); err != nil {
+				t.Fatalf("Handle failed: %v", err)
+			}
+		}
+
+		data, err := os.ReadFile(filepath.Join(out, "report.csv"))
+		if err != nil {
+			t.Fatalf("expected reassembled file: %v", err)
+		}
+		if !bytes.Equal(data, content) {
+			t.Error("reassembled file does not match the original")
+		}
+		if entries, _ := os.ReadDir(filepath.Join(out, ".parts")); len(entries) != 0 {
+			t.Errorf("expected staged parts to be removed, got %d entries", len(entries))
+		}
+	})
+
+	t.Run("manifest first", func(t *testing.T) {
+		r, out := newReassembler(t)
+		msgs := split(t, content)
+		manifest := msgs[len(msgs)-1]
+		parts := msgs[:len(msgs)-1]
+
+		if err := r.Handle(context.Background(), manifest); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+		for i := len(parts) - 1; i >= 0; i-- {
+			if _, err := os.Stat(filepath.Join(out, "report.csv")); err == nil {
+				t.Fatal("file written before all parts arrived")
+			}
+			if err := r.Handle(context.Background(), parts[i]); err != nil {
+				t.Fatalf("Handle failed: %v", err)
+			}
+		}
+
+		data, _ := os.ReadFile(filepath.Join(out, "report.csv"))
+		if !bytes.Equal(data, content) {
+			t.Error("reassembled file does not match the original")
+		}
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		r, out := newReassembler(t)
+		for _, msg := range split(t, nil) {
+			if err := r.Handle(context.Background(), msg); err != nil {
+				t.Fatalf("Handle failed: %v", err)
+			}
+		}
+		if info, err := os.Stat(filepath.Join(out, "report.csv")); err != nil || info.Size() != 0 {
+			t.Errorf("expected empty file, got %v", err)
+		}
+	})
+
+	t.Run("corrupted part", func(t *testing.T) {
+		r, _ := newReassembler(t)
+		msgs := split(t, content)
+		msgs[0].Data = []byte("tampered")
+		if err := r.Handle(context.Background(), msgs[0]); err == nil {
+			t.Error("expected checksum error")
+		}
+	})
+
+	t.Run("manifest mismatch", func(t *testing.T) {
+		r, out := newReassembler(t)
+		msgs := split(t, content)
+
+		var manifest Manifest
+		json.Unmarshal(msgs[len(msgs)-1].Data, &manifest)
+		manifest.SHA256 = "0000"
+		manifest.Filename = "../escape.csv"
+		msgs[len(msgs)-1].Data, _ = json.Marshal(manifest)
+
+		var lastErr error
+		for _, msg := range msgs {
+			lastErr = r.Handle(context.Background(), msg)
+		}
+		if lastErr == nil {
+			t.Error("expected checksum error for the assembled file")
+		}
+		if _, err := os.Stat(filepath.Join(out, "escape.csv")); err == nil {
+			t.Error("file with mismatched checksum was written")
+		}
+	})
+
+	t.Run("missing upload id", func(t *testing.T) {
+		r, _ := newReassembler(t)
+		if err := r.Handle(context.Background(), &domain.ReceivedMessage{Headers: map[string]string{}}); err == nil {
+			t.Error("expected error for message without upload id")
+		}
+	})
+
+	t.Run("nil config", func(t *testing.T) {
+		if _, err := NewReassembler(nil); err == nil {
+			t.Error("expected error for nil config")
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// NotificationData is the data passed to notification templates
+type NotificationData struct {
+	Subject   string
+	Sequence  uint64
+	Headers   map[string]string
+	Data      string      // Payload as text
+	JSON      interface{} // Decoded payload when it is JSON, nil otherwise
+	Timestamp time.Time
+}
+
+// SMTPConfig configures email notifications
+type SMTPConfig struct {
+	// Addr is the server address, host:port
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+	// SubjectTemplate renders the email subject (default "[{{.Subject}}] notification")
+	SubjectTemplate string
+}
+
+// NotificationSink renders a template from each message and sends it to Slack,
+// a generic webhook and/or email. It is meant for low-volume alert subjects:
+// every message results in one notification per configured target.
+type NotificationSink struct {
+	body               *template.Template
+	emailSubject       *template.Template
+	slackWebhookURL    string
+	webhookURL         string
+	webhookContentType string
+	smtp               *SMTPConfig
+	client             *http.Client
+	sendMail           func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+	logger             Logger
+}
+
+// NotificationSinkConfig represents configuration for NotificationSink
+type NotificationSinkConfig struct {
+	// Template is a text/template rendered with NotificationData, e.g.
+	// "{{.Subject}} #{{.Sequence}}: {{.JSON.message}}"
+	Template string
+	// SlackWebhookURL is a Slack incoming webhook; the text is sent as {"text": ...}
+	SlackWebhookURL string
+	// WebhookURL receives the rendered text as the request body
+	WebhookURL string
+	// WebhookContentType is the content type of webhook requests (default text/plain)
+	WebhookContentType string
+	SMTP               *SMTPConfig
+	Client             *http.Client
+	Logger             Logger
+}
+
+// NewNotificationSink creates a new notification sink handler
+func NewNotificationSink(config *NotificationSinkConfig) (*NotificationSink, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Template == "" {
+		return nil, fmt.Errorf("template is required")
+	}
+
+	if config.SlackWebhookURL == "" && config.WebhookURL == "" && config.SMTP == nil {
+		return nil, fmt.Errorf("at least one of Slack webhook, webhook or SMTP is required")
+	}
+
+	body, err := template.New("notification").Option("missingkey=zero").Parse(config.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	s := &NotificationSink{
+		body:               body,
+		slackWebhookURL:    config.SlackWebhookURL,
+		webhookURL:         config.WebhookURL,
+		webhookContentType: config.WebhookContentType,
+		smtp:               config.SMTP,
+		client:             config.Client,
+		sendMail:           smtp.SendMail,
+		logger:             config.Logger,
+	}
+
+	if s.smtp != nil {
+		if s.smtp.Addr == "" || s.smtp.From == "" || len(s.smtp.To) == 0 {
+			return nil, fmt.Errorf("SMTP address, sender and recipients are required")
+		}
+		subject := s.smtp.SubjectTemplate
+		if subject == "" {
+			subject = "[{{.Subject}}] notification"
+		}
+		if s.emailSubject, err = template.New("subject").Option("missingkey=zero").Parse(subject); err != nil {
+			return nil, fmt.Errorf("invalid email subject template: %w", err)
+		}
+	}
+
+	if s.webhookContentType == "" {
+		s.webhookContentType = "text/plain; charset=utf-8"
+	}
+	if s.client == nil {
+		s.client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if s.logger == nil {
+		s.logger = &defaultLogger{}
+	}
+
+	return s, nil
+}
+
+// Handle renders the notification and sends it to every configured target.
+// A failing target does not prevent delivery to the others.
+func (s *NotificationSink) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	data := notificationData(msg)
+
+	text, err := render(s.body, data)
+	if err != nil {
+		return fmt.Errorf("failed to render notification: %w", err)
+	}
+
+	var errs []error
+	if s.slackWebhookURL != "" {
+		payload, _ := json.Marshal(map[string]string{"text": text})
+		if err := s.post(ctx, s.slackWebhookURL, "application/json", payload); err != nil {
+			errs = append(errs, fmt.Errorf("slack: %w", err))
+		}
+	}
+	if s.webhookURL != "" {
+		if err := s.post(ctx, s.webhookURL, s.webhookContentType, []byte(text)); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	if s.smtp != nil {
+		if err := s.email(data, text); err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to send notification for sequence %d: %w", msg.Sequence, err)
+	}
+
+	s.logger.Printf("   ✓ Sent notification for sequence %d", msg.Sequence)
+	return nil
+}
+
+// notificationData builds the template data of a message
+func notificationData(msg *domain.ReceivedMessage) *NotificationData {
+	data := &NotificationData{
+		Subject:   msg.Subject,
+		Sequence:  msg.Sequence,
+		Headers:   msg.Headers,
+		Data:      string(msg.Data),
+		Timestamp: msg.Timestamp,
+	}
+	var decoded interface{}
+	if json.Unmarshal(msg.Data, &decoded) == nil {
+		data.JSON = decoded
+	}
+	return data
+}
+
+// render executes a template to a string
+func render(tmpl *template.Template, data *NotificationData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// post sends body to url
+func (s *NotificationSink) post(ctx context.Context, url, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// email sends the notification text as a plain text email
+func (s *NotificationSink) email(data *NotificationData, text string) error {
+	subject, err := render(s.emailSubject, data)
+	if err != nil {
+		return fmt.Errorf("failed to render subject: %w", err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", s.smtp.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.smtp.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", headerSafe(subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if s.smtp.Username != "" {
+		host := s.smtp.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", s.smtp.Username, s.smtp.Password, host)
+	}
+
+	return s.sendMail(s.smtp.Addr, auth, s.smtp.From, s.smtp.To, msg.Bytes())
+}
+
+// headerSafe removes line breaks so a value cannot inject mail headers
+func headerSafe(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
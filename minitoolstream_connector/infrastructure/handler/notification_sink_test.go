@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestNewNotificationSink(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *NotificationSinkConfig
+	}{
+		{"nil config", nil},
+		{"no template", &NotificationSinkConfig{WebhookURL: "http://localhost"}},
+		{"no target", &NotificationSinkConfig{Template: "x"}},
+		{"invalid template", &NotificationSinkConfig{Template: "{{.Subject", WebhookURL: "http://localhost"}},
+		{"incomplete SMTP", &NotificationSinkConfig{Template: "x", SMTP: &SMTPConfig{Addr: "localhost:25"}}},
+		{"invalid subject template", &NotificationSinkConfig{Template: "x", SMTP: &SMTPConfig{
+			Addr: "localhost:25", From: "a@example.com", To: []string{"b@example.com"}, SubjectTemplate: "{{",
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewNotificationSink(tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestNotificationSink_Handle(t *testing.T) {
+	msg := &domain.ReceivedMessage{
+		Subject:  "alerts.disk",
+		Sequence: 42,
+		Data:     []byte(`{"host":"db-1","usage":97}`),
+		Headers:  map[string]string{"severity": "critical"},
+	}
+	const tmpl = `{{.Headers.severity}}: {{.JSON.host}} disk at {{.JSON.usage}}% ({{.Subject}} #{{.Sequence}})`
+	const want = "critical: db-1 disk at 97% (alerts.disk #42)"
+
+	t.Run("slack and webhook", func(t *testing.T) {
+		received := make(map[string]string)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received[r.URL.Path] = r.Header.Get("Content-Type") + " " + string(body)
+		}))
+		defer srv.Close()
+
+		sink, err := NewNotificationSink(&NotificationSinkConfig{
+			Template:        tmpl,
+			SlackWebhookURL: srv.URL + "/slack",
+			WebhookURL:      srv.URL + "/hook",
+			Logger:          &testLogger{},
+		})
+		if err != nil {
+			t.Fatalf("NewNotificationSink failed: %v", err)
+		}
+
+		if err := sink.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+
+		slack, _ := json.Marshal(map[string]string{"text": want})
+		if received["/slack"] != "application/json "+string(slack) {
+			t.Errorf("unexpected Slack request %q", received["/slack"])
+		}
+		if received["/hook"] != "text/plain; charset=utf-8 "+want {
+			t.Errorf("unexpected webhook request %q", received["/hook"])
+		}
+	})
+
+	t.Run("email", func(t *testing.T) {
+		sink, _ := NewNotificationSink(&NotificationSinkConfig{
+			Template: tmpl,
+			SMTP: &SMTPConfig{
+				Addr:            "smtp.example.com:587",
+				Username:        "alerts",
+				Password:        "secret",
+				From:            "alerts@example.com",
+				To:              []string{"ops@example.com", "dba@example.com"},
+				SubjectTemplate: "{{.Headers.severity}} on {{.JSON.host}}\r\nBcc: evil@example.com",
+			},
+			Logger: &testLogger{},
+		})
+
+		var sent struct {
+			addr string
+			auth smtp.Auth
+			from string
+			to   []string
+			msg  string
+		}
+		sink.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			sent.addr, sent.auth, sent.from, sent.to, sent.msg = addr, a, from, to, string(msg)
+			return nil
+		}
+
+		if err := sink.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+
+		if sent.addr != "smtp.example.com:587" || sent.auth == nil || sent.from != "alerts@example.com" || len(sent.to) != 2 {
+			t.Errorf("unexpected envelope %+v", sent)
+		}
+		for _, line := range []string{
+			"To: ops@example.com, dba@example.com\r\n",
+			"Subject: critical on db-1  Bcc: evil@example.com\r\n",
+			"\r\n\r\n" + want,
+		} {
+			if !strings.Contains(sent.msg, line) {
+				t.Errorf("expected %q in email:\n%s", line, sent.msg)
+			}
+		}
+	})
+
+	t.Run("failing target does not stop the others", func(t *testing.T) {
+		var hooks int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slack" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			hooks++
+		}))
+		defer srv.Close()
+
+		sink, _ := NewNotificationSink(&NotificationSinkConfig{
+			Template:        tmpl,
+			SlackWebhookURL: srv.URL + "/slack",
+			WebhookURL:      srv.URL + "/hook",
+			SMTP:            &SMTPConfig{Addr: "localhost:25", From: "a@example.com", To: []string{"b@example.com"}},
+			Logger:          &testLogger{},
+		})
+		sink.sendMail = func(string, smtp.Auth, string, []string, []byte) error {
+			return errors.New("connection refused")
+		}
+
+		err := sink.Handle(context.Background(), msg)
+		if err == nil || !strings.Contains(err.Error(), "slack") || !strings.Contains(err.Error(), "email") {
+			t.Errorf("expected Slack and email errors, got %v", err)
+		}
+		if hooks != 1 {
+			t.Errorf("expected the webhook to be called, got %d calls", hooks)
+		}
+	})
+
+	t.Run("plain text payload", func(t *testing.T) {
+		var body string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			body = string(b)
+		}))
+		defer srv.Close()
+
+		sink, _ := NewNotificationSink(&NotificationSinkConfig{
+			Template:   "{{.Data}}{{if .JSON}} (json){{end}}",
+			WebhookURL: srv.URL,
+			Logger:     &testLogger{},
+		})
+		sink.Handle(context.Background(), &domain.ReceivedMessage{Subject: "alerts", Data: []byte("backup failed")})
+		if body != "backup failed" {
+			t.Errorf("unexpected body %q", body)
+		}
+	})
+}
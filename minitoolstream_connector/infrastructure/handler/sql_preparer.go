@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// SQLFormat selects how SQLPreparer encodes a result set
+type SQLFormat string
+
+// Result set formats
+const (
+	SQLFormatNDJSON SQLFormat = "ndjson" // One JSON object per row, keyed by column name
+	SQLFormatCSV    SQLFormat = "csv"    // A header row with the column names, then one record per row
+)
+
+// SQLPreparer publishes the result set of a SQL query
+type SQLPreparer struct {
+	subject string
+	db      *sql.DB
+	query   string
+	args    []interface{}
+	format  SQLFormat
+	logger  Logger
+	clock   domain.Clock
+}
+
+// SQLPreparerConfig represents configuration for SQLPreparer
+type SQLPreparerConfig struct {
+	Subject string
+	DB      *sql.DB
+	Query   string
+	Args    []interface{}
+	Format  SQLFormat // Default: SQLFormatNDJSON
+	Logger  Logger
+	// Clock stamps the timestamp header (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// NewSQLPreparer creates a preparer that runs the query each time it is
+// prepared, e.g. for periodic snapshots with PublishEvery
+func NewSQLPreparer(config *SQLPreparerConfig) (*SQLPreparer, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if config.DB == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+	if config.Query == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+
+	format := config.Format
+	if format == "" {
+		format = SQLFormatNDJSON
+	}
+	if format != SQLFormatNDJSON && format != SQLFormatCSV {
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = &defaultLogger{}
+	}
+
+	return &SQLPreparer{
+		subject: config.Subject,
+		db:      config.DB,
+		query:   config.Query,
+		args:    config.Args,
+		format:  format,
+		logger:  logger,
+		clock:   domain.ClockOrSystem(config.Clock),
+	}, nil
+}
+
+// Prepare runs the query and encodes the result set for publishing
+func (p *SQLPreparer) Prepare(ctx context.Context) (*domain.PublishMessage, error) {
+	rows, err := p.db.QueryContext(ctx, p.query, p.args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	var (
+		buf         bytes.Buffer
+		contentType string
+		count       int
+	)
+	switch p.format {
+	case SQLFormatCSV:
+		contentType = "text/csv"
+		count, err = writeCSV(&buf, rows, columns)
+	default:
+		contentType = "application/x-ndjson"
+		count, err = writeNDJSON(&buf, rows, columns)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.logger.Printf("[%s] Queried %d rows (%d bytes)", p.subject, count, buf.Len())
+
+	return &domain.PublishMessage{
+		Subject: p.subject,
+		Data:    buf.Bytes(),
+		Headers: map[string]string{
+			"content-type": contentType,
+			"row-count":    strconv.Itoa(count),
+			"timestamp":    p.clock.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// scanRow scans the current row into driver values
+func scanRow(rows *sql.Rows, n int) ([]interface{}, error) {
+	values := make([]interface{}, n)
+	ptrs := make([]interface{}, n)
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, fmt.Errorf("failed to scan row: %w", err)
+	}
+	for i, v := range values {
+		// Text columns are commonly returned as bytes
+		if b, ok := v.([]byte); ok {
+			values[i] = string(b)
+		}
+	}
+	return values, nil
+}
+
+// writeNDJSON writes one JSON object per row
+func writeNDJSON(buf *bytes.Buffer, rows *sql.Rows, columns []string) (int, error) {
+	enc := json.NewEncoder(buf)
+	count := 0
+	for rows.Next() {
+		values, err := scanRow(rows, len(columns))
+		if err != nil {
+			return 0, err
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			row[column] = values[i]
+		}
+		if err := enc.Encode(row); err != nil {
+			return 0, fmt.Errorf("failed to encode row: %w", err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read rows: %w", err)
+	}
+	return count, nil
+}
+
+// writeCSV writes a header row followed by one record per row
+func writeCSV(buf *bytes.Buffer, rows *sql.Rows, columns []string) (int, error) {
+	w := csv.NewWriter(buf)
+	if err := w.Write(columns); err != nil {
+		return 0, fmt.Errorf("failed to encode header: %w", err)
+	}
+
+	count := 0
+	record := make([]string, len(columns))
+	for rows.Next() {
+		values, err := scanRow(rows, len(columns))
+		if err != nil {
+			return 0, err
+		}
+		for i, v := range values {
+			record[i] = formatCSVValue(v)
+		}
+		if err := w.Write(record); err != nil {
+			return 0, fmt.Errorf("failed to encode row: %w", err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return 0, fmt.Errorf("failed to encode rows: %w", err)
+	}
+	return count, nil
+}
+
+// formatCSVValue formats a driver value as a CSV field; NULL becomes empty
+func formatCSVValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// presetDriver is a database/sql driver that answers every query with preset
+// columns and rows and records the query arguments
+type presetDriver struct {
+	mu      sync.Mutex
+	columns []string
+	rows    [][]driver.Value
+	args    []driver.Value
+	err     error
+}
+
+var sqlTestDriver = &presetDriver{}
+
+func init() {
+	sql.Register("handler-test", sqlTestDriver)
+}
+
+func (d *presetDriver) Open(name string) (driver.Conn, error) { return &presetConn{d: d}, nil }
+
+func (d *presetDriver) reset(err error, columns []string, rows ...[]driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.columns, d.rows, d.args, d.err = columns, rows, nil, err
+}
+
+type presetConn struct{ d *presetDriver }
+
+func (c *presetConn) Prepare(query string) (driver.Stmt, error) { return &presetStmt{d: c.d}, nil }
+func (c *presetConn) Close() error                              { return nil }
+func (c *presetConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type presetStmt struct{ d *presetDriver }
+
+func (s *presetStmt) Close() error  { return nil }
+func (s *presetStmt) NumInput() int { return -1 }
+
+func (s *presetStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *presetStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	if s.d.err != nil {
+		return nil, s.d.err
+	}
+	s.d.args = args
+	return &presetDriverRows{columns: s.d.columns, rows: s.d.rows}, nil
+}
+
+type presetDriverRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *presetDriverRows) Columns() []string { return r.columns }
+func (r *presetDriverRows) Close() error      { return nil }
+
+func (r *presetDriverRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("handler-test", "")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestNewSQLPreparer(t *testing.T) {
+	db := openTestDB(t)
+
+	tests := []struct {
+		name   string
+		config *SQLPreparerConfig
+	}{
+		{"nil config", nil},
+		{"nil db", &SQLPreparerConfig{Query: "SELECT 1"}},
+		{"empty query", &SQLPreparerConfig{DB: db}},
+		{"unknown format", &SQLPreparerConfig{DB: db, Query: "SELECT 1", Format: "xml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSQLPreparer(tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestSQLPreparer_Prepare(t *testing.T) {
+	db := openTestDB(t)
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	columns := []string{"id", "name", "created_at", "note"}
+	rows := [][]driver.Value{
+		{int64(1), []byte("alice"), created, nil},
+		{int64(2), "bob, jr", created, "vip"},
+	}
+
+	t.Run("ndjson", func(t *testing.T) {
+		sqlTestDriver.reset(nil, columns, rows...)
+		p, err := NewSQLPreparer(&SQLPreparerConfig{
+			Subject: "snapshots.users",
+			DB:      db,
+			Query:   "SELECT id, name, created_at, note FROM users WHERE active = ?",
+			Args:    []interface{}{true},
+			Logger:  &testLogger{},
+		})
+		if err != nil {
+			t.Fatalf("NewSQLPreparer failed: %v", err)
+		}
+
+		msg, err := p.Prepare(context.Background())
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		want := `{"created_at":"2024-05-01T10:00:00Z","id":1,"name":"alice","note":null}` + "\n" +
+			`{"created_at":"2024-05-01T10:00:00Z","id":2,"name":"bob, jr","note":"vip"}` + "\n"
+		if string(msg.Data) != want {
+			t.Errorf("unexpected data:\n%s", msg.Data)
+		}
+		if msg.Headers["content-type"] != "application/x-ndjson" || msg.Headers["row-count"] != "2" {
+			t.Errorf("unexpected headers %v", msg.Headers)
+		}
+		if len(sqlTestDriver.args) != 1 || sqlTestDriver.args[0] != true {
+			t.Errorf("expected query args to be passed, got %v", sqlTestDriver.args)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		sqlTestDriver.reset(nil, columns, rows...)
+		p, _ := NewSQLPreparer(&SQLPreparerConfig{
+			Subject: "snapshots.users",
+			DB:      db,
+			Query:   "SELECT * FROM users",
+			Format:  SQLFormatCSV,
+			Logger:  &testLogger{},
+		})
+
+		msg, err := p.Prepare(context.Background())
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		want := strings.Join([]string{
+			"id,name,created_at,note",
+			"1,alice,2024-05-01T10:00:00Z,",
+			`2,"bob, jr",2024-05-01T10:00:00Z,vip`,
+			"",
+		}, "\n")
+		if string(msg.Data) != want {
+			t.Errorf("unexpected data:\n%s", msg.Data)
+		}
+		if msg.Headers["content-type"] != "text/csv" {
+			t.Errorf("unexpected content type %s", msg.Headers["content-type"])
+		}
+	})
+
+	t.Run("empty result", func(t *testing.T) {
+		sqlTestDriver.reset(nil, columns)
+		p, _ := NewSQLPreparer(&SQLPreparerConfig{DB: db, Query: "SELECT * FROM users", Logger: &testLogger{}})
+
+		msg, err := p.Prepare(context.Background())
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		if len(msg.Data) != 0 || msg.Headers["row-count"] != "0" {
+			t.Errorf("expected empty snapshot, got %q with headers %v", msg.Data, msg.Headers)
+		}
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		sqlTestDriver.reset(errors.New("no such table"), nil)
+		p, _ := NewSQLPreparer(&SQLPreparerConfig{DB: db, Query: "SELECT * FROM missing", Logger: &testLogger{}})
+
+		if _, err := p.Prepare(context.Background()); err == nil {
+			t.Error("expected query error")
+		}
+	})
+}
@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// TailHandler follows a log file and publishes new lines as they are written.
+//
+// The file is polled rather than watched, so it works on any file system.
+// Rotation is detected when the path starts pointing to a different file
+// (rename and recreate) or the file shrinks (copytruncate); the rest of the old
+// file is published before switching over. A batch that fails to publish is
+// retried on the next poll and reading pauses until it goes through.
+type TailHandler struct {
+	subject       string
+	filePath      string
+	publisher     domain.Publisher
+	batchSize     int
+	flushInterval time.Duration
+	pollInterval  time.Duration
+	fromStart     bool
+	logger        Logger
+	clock         domain.Clock
+}
+
+// TailHandlerConfig represents configuration for TailHandler
+type TailHandlerConfig struct {
+	Subject   string
+	FilePath  string
+	Publisher domain.Publisher
+	// BatchSize is the maximum number of lines per message (default 1)
+	BatchSize int
+	// FlushInterval is how long a partial batch waits for more lines (default 1s)
+	FlushInterval time.Duration
+	// PollInterval is the pause between reads once the end of the file is reached (default 250ms)
+	PollInterval time.Duration
+	// FromStart publishes the existing content of the file instead of only new lines
+	FromStart bool
+	Logger    Logger
+	// Clock stamps batches and paces polling (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// NewTailHandler creates a new log file tail handler
+func NewTailHandler(config *TailHandlerConfig) (*TailHandler, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Publisher == nil {
+		return nil, fmt.Errorf("publisher cannot be nil")
+	}
+
+	if config.FilePath == "" {
+		return nil, fmt.Errorf("file path is required")
+	}
+
+	if config.Subject == "" {
+		return nil, fmt.Errorf("subject is required")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = &defaultLogger{}
+	}
+
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	flushInterval := config.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+
+	pollInterval := config.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = 250 * time.Millisecond
+	}
+
+	return &TailHandler{
+		subject:       config.Subject,
+		filePath:      config.FilePath,
+		publisher:     config.Publisher,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		pollInterval:  pollInterval,
+		fromStart:     config.FromStart,
+		logger:        logger,
+		clock:         domain.ClockOrSystem(config.Clock),
+	}, nil
+}
+
+// tailState is the file currently followed by Run
+type tailState struct {
+	file    *os.File
+	reader  *bufio.Reader
+	offset  int64
+	partial []byte
+
+	lines   []string
+	batched time.Time
+}
+
+// Run follows the file until ctx is cancelled. The file does not need to exist
+// yet; Run waits for it to appear. Lines of a partial batch that has not been
+// flushed when ctx is cancelled are not published.
+func (h *TailHandler) Run(ctx context.Context) error {
+	st := &tailState{}
+	defer func() {
+		if st.file != nil {
+			st.file.Close()
+		}
+	}()
+
+	h.logger.Printf("✓ Tailing %s into subject %s", h.filePath, h.subject)
+
+	fromStart := h.fromStart
+	for {
+		if st.file == nil {
+			if err := h.open(st, fromStart); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			// A file that appears later is new, so it is read from the start
+			fromStart = true
+		}
+
+		eof := true
+		if st.file != nil {
+			var err error
+			if eof, err = h.readLines(st); err != nil {
+				return err
+			}
+		}
+
+		if len(st.lines) >= h.batchSize || (len(st.lines) > 0 && h.clock.Now().Sub(st.batched) >= h.flushInterval) {
+			if !h.flush(ctx, st) {
+				// Retry after a pause instead of reading further
+				if !h.sleep(ctx) {
+					return ctx.Err()
+				}
+				continue
+			}
+		}
+		if !eof {
+			continue
+		}
+
+		if st.file != nil {
+			rotated, err := h.rotated(st)
+			if err != nil {
+				return err
+			}
+			if rotated {
+				continue
+			}
+		}
+
+		if !h.sleep(ctx) {
+			return ctx.Err()
+		}
+	}
+}
+
+// open opens the file, positioned at its start or end
+func (h *TailHandler) open(st *tailState, fromStart bool) error {
+	f, err := os.Open(h.filePath)
+	if err != nil {
+		return err
+	}
+
+	var offset int64
+	if !fromStart {
+		if offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to seek %s: %w", h.filePath, err)
+		}
+	}
+
+	st.file = f
+	st.reader = bufio.NewReader(f)
+	st.offset = offset
+	st.partial = nil
+	h.logger.Printf("[%s] Opened %s at offset %d", h.subject, h.filePath, offset)
+	return nil
+}
+
+// readLines reads complete lines into the batch until the batch is full or the
+// end of the file is reached, and reports whether the end was reached
+func (h *TailHandler) readLines(st *tailState) (bool, error) {
+	for len(st.lines) < h.batchSize {
+		chunk, err := st.reader.ReadSlice('\n')
+		st.offset += int64(len(chunk))
+		if len(chunk) > 0 {
+			st.partial = append(st.partial, chunk...)
+		}
+
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to read %s: %w", h.filePath, err)
+		}
+
+		h.addLine(st, st.partial)
+		st.partial = nil
+	}
+	return false, nil
+}
+
+// addLine appends a line, without its line ending, to the batch
+func (h *TailHandler) addLine(st *tailState, line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(st.lines) == 0 {
+		st.batched = h.clock.Now()
+	}
+	st.lines = append(st.lines, string(line))
+}
+
+// rotated checks whether the file was rotated or truncated and, if so, switches
+// to the new file. It must only be called at the end of the current file.
+func (h *TailHandler) rotated(st *tailState) (bool, error) {
+	current, err := st.file.Stat()
+	if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", h.filePath, err)
+	}
+
+	if current.Size() < st.offset {
+		h.logger.Printf("[%s] %s was truncated, reading from the start", h.subject, h.filePath)
+		if _, err := st.file.Seek(0, io.SeekStart); err != nil {
+			return false, fmt.Errorf("failed to seek %s: %w", h.filePath, err)
+		}
+		st.reader.Reset(st.file)
+		st.offset = 0
+		st.partial = nil
+		return true, nil
+	}
+
+	info, err := os.Stat(h.filePath)
+	if err != nil {
+		// Rotated away and not recreated yet
+		return false, nil
+	}
+	if os.SameFile(current, info) {
+		return false, nil
+	}
+
+	// Lines written just before the rename still belong to the old file
+	eof, err := h.readLines(st)
+	if err != nil || !eof {
+		return !eof, err
+	}
+
+	h.logger.Printf("[%s] %s was rotated, following the new file", h.subject, h.filePath)
+	if len(st.partial) > 0 {
+		h.addLine(st, st.partial)
+	}
+	st.file.Close()
+	st.file = nil
+	if err := h.open(st, true); err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+// flush publishes up to a batch of lines as one message and reports whether it
+// succeeded; the lines are kept on failure
+func (h *TailHandler) flush(ctx context.Context, st *tailState) bool {
+	lines := st.lines
+	if len(lines) > h.batchSize {
+		lines = lines[:h.batchSize]
+	}
+
+	data := []byte(strings.Join(lines, "\n"))
+	preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{
+			Subject: h.subject,
+			Data:    data,
+			Headers: map[string]string{
+				"content-type": "text/plain",
+				"filename":     filepath.Base(h.filePath),
+				"line-count":   strconv.Itoa(len(lines)),
+				"timestamp":    h.clock.Now().Format(time.RFC3339),
+			},
+		}, nil
+	})
+
+	if err := h.publisher.Publish(ctx, preparer); err != nil {
+		h.logger.Printf("✗ [%s] Failed to publish %d lines from %s: %v", h.subject, len(lines), h.filePath, err)
+		return false
+	}
+
+	st.lines = st.lines[len(lines):]
+	if len(st.lines) > 0 {
+		st.batched = h.clock.Now()
+	}
+	return true
+}
+
+// sleep waits for the poll interval and reports whether ctx is still active
+func (h *TailHandler) sleep(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-h.clock.After(h.pollInterval):
+		return true
+	}
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type mockPublisher struct {
+	mu        sync.Mutex
+	published []*domain.PublishMessage
+	failures  int
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, preparer domain.MessagePreparer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("unavailable")
+	}
+	msg, err := preparer.Prepare(ctx)
+	if err != nil {
+		return err
+	}
+	m.published = append(m.published, msg)
+	return nil
+}
+
+func (m *mockPublisher) data() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var data []string
+	for _, msg := range m.published {
+		data = append(data, string(msg.Data))
+	}
+	return data
+}
+
+func (m *mockPublisher) PublishAll(ctx context.Context, preparers []domain.MessagePreparer) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishAllResults(ctx context.Context, preparers []domain.MessagePreparer) ([]domain.PublishOutcome, error) {
+	return nil, nil
+}
+
+func (m *mockPublisher) PublishGroup(ctx context.Context, preparers []domain.MessagePreparer) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishFanOut(ctx context.Context, subjects []string, data []byte, headers map[string]string) ([]domain.FanOutResult, error) {
+	return nil, nil
+}
+
+func (m *mockPublisher) PublishEvery(ctx context.Context, preparer domain.MessagePreparer, interval time.Duration) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishSchedule(ctx context.Context, preparer domain.MessagePreparer, schedule domain.Schedule) error {
+	return nil
+}
+func (m *mockPublisher) RegisterHandler(preparer domain.MessagePreparer)     {}
+func (m *mockPublisher) RegisterHandlers(preparers []domain.MessagePreparer) {}
+func (m *mockPublisher) RegisterPreparerFunc(fn func(ctx context.Context) (*domain.PublishMessage, error)) {
+}
+func (m *mockPublisher) SetResultHandler(handler domain.ResultHandler) {}
+func (m *mockPublisher) Close() error                                  { return nil }
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+// runTail starts the handler and stops it when the test ends
+func runTail(t *testing.T, config *TailHandlerConfig) {
+	t.Helper()
+	config.Subject = "logs.app"
+	config.PollInterval = 5 * time.Millisecond
+	config.Logger = &testLogger{}
+
+	h, err := NewTailHandler(config)
+	if err != nil {
+		t.Fatalf("NewTailHandler failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- h.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+// waitForData waits until the publisher has published exactly want
+func waitForData(t *testing.T, pub *mockPublisher, want ...string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := pub.data()
+		if len(got) == len(want) {
+			for i := range want {
+				if got[i] != want[i] {
+					t.Fatalf("expected %q, got %q", want, got)
+				}
+			}
+			return
+		}
+		if len(got) > len(want) || time.Now().After(deadline) {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestNewTailHandler(t *testing.T) {
+	pub := &mockPublisher{}
+
+	tests := []struct {
+		name   string
+		config *TailHandlerConfig
+	}{
+		{"nil config", nil},
+		{"nil publisher", &TailHandlerConfig{Subject: "s", FilePath: "app.log"}},
+		{"empty file path", &TailHandlerConfig{Subject: "s", Publisher: pub}},
+		{"empty subject", &TailHandlerConfig{FilePath: "app.log", Publisher: pub}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTailHandler(tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestTailHandler_Run(t *testing.T) {
+	t.Run("publishes new lines only", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "old line\n")
+		pub := &mockPublisher{}
+		runTail(t, &TailHandlerConfig{FilePath: path, Publisher: pub})
+
+		// Give Run time to open the file at its end
+		time.Sleep(50 * time.Millisecond)
+		appendFile(t, path, "first\r\nsec")
+		waitForData(t, pub, "first")
+		appendFile(t, path, "ond\n")
+		waitForData(t, pub, "first", "second")
+
+		pub.mu.Lock()
+		headers := pub.published[0].Headers
+		pub.mu.Unlock()
+		if headers["filename"] != "app.log" || headers["line-count"] != "1" || headers["content-type"] != "text/plain" {
+			t.Errorf("unexpected headers %v", headers)
+		}
+	})
+
+	t.Run("batches lines and flushes partial batches", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "a\nb\nc\n")
+		pub := &mockPublisher{}
+		runTail(t, &TailHandlerConfig{
+			FilePath:      path,
+			Publisher:     pub,
+			FromStart:     true,
+			BatchSize:     2,
+			FlushInterval: 50 * time.Millisecond,
+		})
+
+		waitForData(t, pub, "a\nb", "c")
+	})
+
+	t.Run("waits for the file to appear", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		pub := &mockPublisher{}
+		runTail(t, &TailHandlerConfig{FilePath: path, Publisher: pub})
+
+		time.Sleep(20 * time.Millisecond)
+		appendFile(t, path, "created\n")
+		waitForData(t, pub, "created")
+	})
+
+	t.Run("follows rotation", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "")
+		pub := &mockPublisher{}
+		runTail(t, &TailHandlerConfig{FilePath: path, Publisher: pub})
+
+		time.Sleep(50 * time.Millisecond)
+		appendFile(t, path, "before\n")
+		waitForData(t, pub, "before")
+
+		appendFile(t, path, "last of old\n")
+		if err := os.Rename(path, path+".1"); err != nil {
+			t.Fatalf("failed to rotate: %v", err)
+		}
+		appendFile(t, path, "first of new\n")
+		waitForData(t, pub, "before", "last of old", "first of new")
+	})
+
+	t.Run("follows truncation", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "")
+		pub := &mockPublisher{}
+		runTail(t, &TailHandlerConfig{FilePath: path, Publisher: pub})
+
+		time.Sleep(50 * time.Millisecond)
+		appendFile(t, path, "a fairly long line before truncation\n")
+		waitForData(t, pub, "a fairly long line before truncation")
+
+		if err := os.Truncate(path, 0); err != nil {
+			t.Fatalf("failed to truncate: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+		appendFile(t, path, "after\n")
+		waitForData(t, pub, "a fairly long line before truncation", "after")
+	})
+
+	t.Run("retries failed publishes", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		appendFile(t, path, "kept\n")
+		pub := &mockPublisher{failures: 2}
+		runTail(t, &TailHandlerConfig{FilePath: path, Publisher: pub, FromStart: true})
+
+		waitForData(t, pub, "kept")
+	})
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Placeholders substituted in transcoder command arguments
+const (
+	TranscodeInput  = "{input}"
+	TranscodeOutput = "{output}"
+)
+
+// maxTranscodeStderr bounds the ffmpeg output kept for error messages
+const maxTranscodeStderr = 4096
+
+// mediaTypes maps media file extensions to content types
+var mediaTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".webm": "video/webm",
+	".mkv":  "video/x-matroska",
+	".mov":  "video/quicktime",
+	".avi":  "video/x-msvideo",
+	".mp3":  "audio/mpeg",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".opus": "audio/opus",
+	".flac": "audio/flac",
+	".wav":  "audio/wav",
+	".gif":  "image/gif",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".webp": "image/webp",
+}
+
+// Transcoder runs ffmpeg (or any command following the same input/output
+// convention) on received media and republishes and/or saves the result.
+// Each message is transcoded in its own temporary directory, which is removed
+// afterwards.
+type Transcoder struct {
+	command           []string
+	outputExt         string
+	outputContentType string
+	timeout           time.Duration
+	tempDir           string
+	publisher         domain.Publisher
+	outputSubject     func(subject string) string
+	outputDir         string
+	logger            Logger
+}
+
+// TranscoderConfig represents configuration for Transcoder
+type TranscoderConfig struct {
+	// Command is the program and arguments to run; {input} and {output} are
+	// replaced with the temporary file paths. Default:
+	// ffmpeg -hide_banner -loglevel error -y -i {input} {output}
+	Command []string
+	// OutputFormat is the extension of the output file, e.g. "mp4" or ".webm";
+	// ffmpeg picks the container from it
+	OutputFormat string
+	// OutputContentType overrides the content type derived from OutputFormat
+	OutputContentType string
+	// Timeout bounds a single transcode (default 5m)
+	Timeout time.Duration
+	// TempDir is where temporary files are created (default os.TempDir())
+	TempDir string
+	// Publisher republishes the result when set
+	Publisher domain.Publisher
+	// OutputSubject maps the source subject to the result subject
+	// (default: subject + ".transcoded")
+	OutputSubject func(subject string) string
+	// OutputDir saves the result to a directory when set
+	OutputDir string
+	Logger    Logger
+}
+
+// NewTranscoder creates a new transcoding handler
+func NewTranscoder(config *TranscoderConfig) (*Transcoder, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.OutputFormat == "" {
+		return nil, fmt.Errorf("output format is required")
+	}
+
+	if config.Publisher == nil && config.OutputDir == "" {
+		return nil, fmt.Errorf("publisher or output directory is required")
+	}
+
+	command := config.Command
+	if len(command) == 0 {
+		command = []string{"ffmpeg", "-hide_banner", "-loglevel", "error", "-y", "-i", TranscodeInput, TranscodeOutput}
+	}
+	if !containsArg(command, TranscodeInput) || !containsArg(command, TranscodeOutput) {
+		return nil, fmt.Errorf("command must reference %s and %s", TranscodeInput, TranscodeOutput)
+	}
+
+	ext := "." + strings.TrimPrefix(config.OutputFormat, ".")
+
+	t := &Transcoder{
+		command:           command,
+		outputExt:         ext,
+		outputContentType: config.OutputContentType,
+		timeout:           config.Timeout,
+		tempDir:           config.TempDir,
+		publisher:         config.Publisher,
+		outputSubject:     config.OutputSubject,
+		outputDir:         config.OutputDir,
+		logger:            config.Logger,
+	}
+
+	if t.outputContentType == "" {
+		t.outputContentType = mediaTypes[ext]
+	}
+	if t.outputContentType == "" {
+		t.outputContentType = "application/octet-stream"
+	}
+	if t.timeout <= 0 {
+		t.timeout = 5 * time.Minute
+	}
+	if t.outputSubject == nil {
+		t.outputSubject = func(subject string) string { return subject + ".transcoded" }
+	}
+	if t.logger == nil {
+		t.logger = &defaultLogger{}
+	}
+
+	if t.outputDir != "" {
+		if err := os.MkdirAll(t.outputDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory %s: %w", t.outputDir, err)
+		}
+	}
+
+	return t, nil
+}
+
+// Handle transcodes the message payload
+func (t *Transcoder) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	if len(msg.Data) == 0 {
+		t.logger.Printf("   No media data for sequence %d", msg.Sequence)
+		return nil
+	}
+
+	start := time.Now()
+	output, err := t.transcode(ctx, msg)
+	if err != nil {
+		return fmt.Errorf("failed to transcode sequence %d: %w", msg.Sequence, err)
+	}
+	t.logger.Printf("   ✓ Transcoded sequence %d: %d -> %d bytes in %v", msg.Sequence, len(msg.Data), len(output), time.Since(start).Round(time.Millisecond))
+
+	filename := fmt.Sprintf("%s_seq_%d%s", msg.Subject, msg.Sequence, t.outputExt)
+	if name := msg.Headers["filename"]; name != "" {
+		filename = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name)) + t.outputExt
+	}
+
+	if t.outputDir != "" {
+		path := filepath.Join(t.outputDir, filename)
+		if err := os.WriteFile(path, output, 0644); err != nil {
+			return fmt.Errorf("failed to save transcoded media: %w", err)
+		}
+		t.logger.Printf("   ✓ Transcoded media saved to: %s", path)
+	}
+
+	if t.publisher != nil {
+		headers := make(map[string]string, len(msg.Headers)+4)
+		for k, v := range msg.Headers {
+			headers[k] = v
+		}
+		headers["content-type"] = t.outputContentType
+		headers["filename"] = filename
+		headers["source-subject"] = msg.Subject
+		headers["source-sequence"] = strconv.FormatUint(msg.Sequence, 10)
+
+		subject := t.outputSubject(msg.Subject)
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: subject, Data: output, Headers: headers}, nil
+		})
+		if err := t.publisher.Publish(ctx, preparer); err != nil {
+			return fmt.Errorf("failed to publish transcoded media: %w", err)
+		}
+		t.logger.Printf("   ✓ Transcoded media published to: %s", subject)
+	}
+
+	return nil
+}
+
+// transcode writes the payload to a temporary file, runs the command and
+// returns the output file contents
+func (t *Transcoder) transcode(ctx context.Context, msg *domain.ReceivedMessage) ([]byte, error) {
+	dir, err := os.MkdirTemp(t.tempDir, "transcode-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input"+inputExtension(msg.Headers))
+	output := filepath.Join(dir, "output"+t.outputExt)
+	if err := os.WriteFile(input, msg.Data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write input file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	args := make([]string, len(t.command))
+	for i, arg := range t.command {
+		arg = strings.ReplaceAll(arg, TranscodeInput, input)
+		args[i] = strings.ReplaceAll(arg, TranscodeOutput, output)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%s timed out after %v", args[0], t.timeout)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%s failed: %w: %s", args[0], err, tailOutput(stderr.Bytes()))
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+	return data, nil
+}
+
+// inputExtension picks an input file extension from the filename or content
+// type headers, so tools that rely on it can detect the format
+func inputExtension(headers map[string]string) string {
+	if ext := filepath.Ext(headers["filename"]); ext != "" {
+		return ext
+	}
+	if mediaType, _, err := mime.ParseMediaType(headers["content-type"]); err == nil {
+		for ext, contentType := range mediaTypes {
+			if contentType == mediaType {
+				return ext
+			}
+		}
+	}
+	return ""
+}
+
+// tailOutput returns the trimmed end of command output
+func tailOutput(output []byte) string {
+	if len(output) > maxTranscodeStderr {
+		output = output[len(output)-maxTranscodeStderr:]
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// containsArg reports whether any argument contains the placeholder
+func containsArg(args []string, placeholder string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, placeholder) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// upperCommand stands in for ffmpeg: it upper-cases the input into the output
+var upperCommand = []string{"sh", "-c", `tr a-z A-Z < "$0" > "$1"`, TranscodeInput, TranscodeOutput}
+
+func TestNewTranscoder(t *testing.T) {
+	pub := &mockPublisher{}
+	tests := []struct {
+		name   string
+		config *TranscoderConfig
+	}{
+		{"nil config", nil},
+		{"missing format", &TranscoderConfig{Publisher: pub}},
+		{"missing destination", &TranscoderConfig{OutputFormat: "mp4"}},
+		{"missing placeholders", &TranscoderConfig{OutputFormat: "mp4", Publisher: pub, Command: []string{"ffmpeg", "-i", TranscodeInput}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTranscoder(tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestTranscoder_Handle(t *testing.T) {
+	msg := &domain.ReceivedMessage{
+		Subject:  "media.raw",
+		Sequence: 3,
+		Data:     []byte("frames"),
+		Headers:  map[string]string{"content-type": "video/quicktime", "filename": "clip.mov", "camera": "lobby"},
+	}
+
+	t.Run("publish and save", func(t *testing.T) {
+		pub := &mockPublisher{}
+		outputDir := t.TempDir()
+		tempDir := t.TempDir()
+		tr, err := NewTranscoder(&TranscoderConfig{
+			Command:      upperCommand,
+			OutputFormat: "webm",
+			TempDir:      tempDir,
+			Publisher:    pub,
+			OutputDir:    outputDir,
+			Logger:       &testLogger{},
+		})
+		if err != nil {
+			t.Fatalf("NewTranscoder failed: %v", err)
+		}
+
+		if err := tr.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+
+		if len(pub.published) != 1 {
+			t.Fatalf("expected 1 published message, got %d", len(pub.published))
+		}
+		out := pub.published[0]
+		if out.Subject != "media.raw.transcoded" || string(out.Data) != "FRAMES" {
+			t.Errorf("unexpected message %s %q", out.Subject, out.Data)
+		}
+		want := map[string]string{
+			"content-type":    "video/webm",
+			"filename":        "clip.webm",
+			"camera":          "lobby",
+			"source-subject":  "media.raw",
+			"source-sequence": "3",
+		}
+		for k, v := range want {
+			if out.Headers[k] != v {
+				t.Errorf("expected header %s=%q, got %q", k, v, out.Headers[k])
+			}
+		}
+
+		saved, err := os.ReadFile(filepath.Join(outputDir, "clip.webm"))
+		if err != nil || string(saved) != "FRAMES" {
+			t.Errorf("expected saved output, got %q (%v)", saved, err)
+		}
+
+		if entries, _ := os.ReadDir(tempDir); len(entries) != 0 {
+			t.Errorf("expected temp files to be removed, found %d entries", len(entries))
+		}
+	})
+
+	t.Run("input extension from content type", func(t *testing.T) {
+		pub := &mockPublisher{}
+		tr, _ := NewTranscoder(&TranscoderConfig{
+			Command:      []string{"sh", "-c", `basename "$0" > "$1"`, TranscodeInput, TranscodeOutput},
+			OutputFormat: ".mp3",
+			Publisher:    pub,
+			OutputSubject: func(subject string) string {
+				return strings.Replace(subject, "raw", "audio", 1)
+			},
+			Logger: &testLogger{},
+		})
+
+		in := &domain.ReceivedMessage{Subject: "media.raw", Sequence: 4, Data: []byte("x"), Headers: map[string]string{"content-type": "video/mp4"}}
+		if err := tr.Handle(context.Background(), in); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+		out := pub.published[0]
+		if strings.TrimSpace(string(out.Data)) != "input.mp4" {
+			t.Errorf("expected input.mp4, got %q", out.Data)
+		}
+		if out.Subject != "media.audio" || out.Headers["content-type"] != "audio/mpeg" || out.Headers["filename"] != "media.raw_seq_4.mp3" {
+			t.Errorf("unexpected output %s %v", out.Subject, out.Headers)
+		}
+	})
+
+	t.Run("command failure includes stderr", func(t *testing.T) {
+		tr, _ := NewTranscoder(&TranscoderConfig{
+			Command:      []string{"sh", "-c", `echo "invalid data found" >&2; exit 1`, TranscodeInput, TranscodeOutput},
+			OutputFormat: "mp4",
+			Publisher:    &mockPublisher{},
+			Logger:       &testLogger{},
+		})
+
+		err := tr.Handle(context.Background(), msg)
+		if err == nil || !strings.Contains(err.Error(), "invalid data found") {
+			t.Errorf("expected stderr in error, got %v", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		tr, _ := NewTranscoder(&TranscoderConfig{
+			Command:      []string{"sh", "-c", `sleep 5`, TranscodeInput, TranscodeOutput},
+			OutputFormat: "mp4",
+			Timeout:      100 * time.Millisecond,
+			Publisher:    &mockPublisher{},
+			Logger:       &testLogger{},
+		})
+
+		start := time.Now()
+		err := tr.Handle(context.Background(), msg)
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Errorf("expected timeout error, got %v", err)
+		}
+		if time.Since(start) > 3*time.Second {
+			t.Errorf("timeout took %v", time.Since(start))
+		}
+	})
+
+	t.Run("empty payload is skipped", func(t *testing.T) {
+		pub := &mockPublisher{}
+		tr, _ := NewTranscoder(&TranscoderConfig{Command: upperCommand, OutputFormat: "mp4", Publisher: pub, Logger: &testLogger{}})
+
+		if err := tr.Handle(context.Background(), &domain.ReceivedMessage{Subject: "media.raw"}); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+		if len(pub.published) != 0 {
+			t.Errorf("expected nothing published, got %d", len(pub.published))
+		}
+	})
+}
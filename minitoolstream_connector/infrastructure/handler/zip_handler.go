@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// ZipHandler publishes the entries of a zip archive, one message per entry
+type ZipHandler struct {
+	subject     string
+	archivePath string
+	logger      Logger
+	clock       domain.Clock
+}
+
+// ZipHandlerConfig represents configuration for ZipHandler
+type ZipHandlerConfig struct {
+	Subject     string
+	ArchivePath string
+	Logger      Logger
+	// Clock stamps the timestamp header (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// NewZipHandler creates a new zip archive handler
+func NewZipHandler(config *ZipHandlerConfig) *ZipHandler {
+	logger := config.Logger
+	if logger == nil {
+		logger = &defaultLogger{}
+	}
+
+	return &ZipHandler{
+		subject:     config.Subject,
+		archivePath: config.ArchivePath,
+		logger:      logger,
+		clock:       domain.ClockOrSystem(config.Clock),
+	}
+}
+
+// Preparers lists the archive and returns one preparer per file entry, in
+// archive order; directories are skipped. Each preparer reads its entry when
+// it is published, so the archive is never unpacked as a whole.
+func (h *ZipHandler) Preparers() ([]domain.MessagePreparer, error) {
+	archive, err := zip.OpenReader(h.archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", h.archivePath, err)
+	}
+	defer archive.Close()
+
+	var preparers []domain.MessagePreparer
+	for i, f := range archive.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		preparers = append(preparers, &zipEntryPreparer{handler: h, index: i, name: f.Name})
+	}
+
+	h.logger.Printf("[%s] Listed archive: %s (%d entries)", h.subject, h.archivePath, len(preparers))
+	return preparers, nil
+}
+
+// zipEntryPreparer prepares a single archive entry
+type zipEntryPreparer struct {
+	handler *ZipHandler
+	index   int
+	name    string
+}
+
+// Prepare reads the entry and prepares it for publishing
+func (p *zipEntryPreparer) Prepare(ctx context.Context) (*domain.PublishMessage, error) {
+	h := p.handler
+
+	archive, err := zip.OpenReader(h.archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", h.archivePath, err)
+	}
+	defer archive.Close()
+
+	if p.index >= len(archive.File) || archive.File[p.index].Name != p.name {
+		return nil, fmt.Errorf("entry %s no longer in archive %s", p.name, h.archivePath)
+	}
+	f := archive.File[p.index]
+
+	r, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open entry %s: %w", p.name, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read entry %s: %w", p.name, err)
+	}
+
+	h.logger.Printf("[%s] Read archive entry: %s (%d bytes)", h.subject, p.name, len(data))
+
+	return &domain.PublishMessage{
+		Subject: h.subject,
+		Data:    data,
+		Headers: map[string]string{
+			"content-type": detectContentType(p.name),
+			"filename":     path.Base(p.name),
+			"entry-name":   p.name,
+			"entry-size":   strconv.Itoa(len(data)),
+			"archive-name": filepath.Base(h.archivePath),
+			"timestamp":    h.clock.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestArchive writes a zip archive with the given entries; names ending in / are directories
+func writeTestArchive(t *testing.T, path string, entries []string, contents map[string]string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create archive: %v", err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for _, name := range entries {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create entry: %v", err)
+		}
+		entry.Write([]byte(contents[name]))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+}
+
+func TestZipHandler_Preparers(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "bundle.zip")
+	writeTestArchive(t, archivePath,
+		[]string{"docs/", "docs/readme.txt", "data.json"},
+		map[string]string{"docs/readme.txt": "hello", "data.json": `{"a":1}`},
+	)
+
+	handler := NewZipHandler(&ZipHandlerConfig{
+		Subject:     "files",
+		ArchivePath: archivePath,
+		Logger:      &testLogger{},
+	})
+
+	preparers, err := handler.Preparers()
+	if err != nil {
+		t.Fatalf("Preparers failed: %v", err)
+	}
+	if len(preparers) != 2 {
+		t.Fatalf("expected 2 preparers (directories skipped), got %d", len(preparers))
+	}
+
+	t.Run("first entry", func(t *testing.T) {
+		msg, err := preparers[0].Prepare(context.Background())
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		if msg.Subject != "files" || string(msg.Data) != "hello" {
+			t.Errorf("unexpected message %s: %q", msg.Subject, msg.Data)
+		}
+		want := map[string]string{
+			"content-type": "text/plain",
+			"filename":     "readme.txt",
+			"entry-name":   "docs/readme.txt",
+			"entry-size":   "5",
+			"archive-name": "bundle.zip",
+		}
+		for k, v := range want {
+			if msg.Headers[k] != v {
+				t.Errorf("expected header %s=%q, got %q", k, v, msg.Headers[k])
+			}
+		}
+	})
+
+	t.Run("second entry", func(t *testing.T) {
+		msg, err := preparers[1].Prepare(context.Background())
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		if string(msg.Data) != `{"a":1}` || msg.Headers["content-type"] != "application/json" {
+			t.Errorf("unexpected message %q with headers %v", msg.Data, msg.Headers)
+		}
+	})
+
+	t.Run("archive changed", func(t *testing.T) {
+		writeTestArchive(t, archivePath, []string{"other.txt"}, nil)
+		if _, err := preparers[1].Prepare(context.Background()); err == nil {
+			t.Error("expected error for entry removed from archive")
+		}
+	})
+}
+
+func TestZipHandler_InvalidArchive(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("missing", func(t *testing.T) {
+		handler := NewZipHandler(&ZipHandlerConfig{Subject: "files", ArchivePath: filepath.Join(dir, "missing.zip")})
+		if _, err := handler.Preparers(); err == nil {
+			t.Error("expected error for missing archive")
+		}
+	})
+
+	t.Run("not a zip", func(t *testing.T) {
+		path := filepath.Join(dir, "plain.zip")
+		os.WriteFile(path, []byte("not an archive"), 0644)
+		handler := NewZipHandler(&ZipHandlerConfig{Subject: "files", ArchivePath: path})
+		if _, err := handler.Preparers(); err == nil {
+			t.Error("expected error for invalid archive")
+		}
+	})
+}
@@ -0,0 +1,338 @@
+package jetstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	js "github.com/nats-io/nats.go/jetstream"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// pollInterval is how often the consumer is checked for pending messages
+const pollInterval = 250 * time.Millisecond
+
+// EgressClient implements domain.EgressClient using JetStream pull consumers
+type EgressClient struct {
+	conn *nats.Conn
+	js   js.JetStream
+
+	mu        sync.Mutex
+	consumers map[string]js.Consumer
+}
+
+// NewEgressClient creates a new JetStream client for consuming
+func NewEgressClient(serverURL string, opts ...nats.Option) (*EgressClient, error) {
+	conn, jetStream, err := connect(serverURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EgressClient{
+		conn:      conn,
+		js:        jetStream,
+		consumers: make(map[string]js.Consumer),
+	}, nil
+}
+
+// Subscribe binds the durable consumer and notifies while it has pending messages.
+// An existing durable resumes unless a start option asks for a different start
+// position, in which case it is recreated at that position.
+func (c *EgressClient) Subscribe(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+	if config == nil {
+		return nil, fmt.Errorf("subscription config cannot be nil")
+	}
+
+	if config.Subject == "" {
+		return nil, fmt.Errorf("subject cannot be empty")
+	}
+
+	stream, err := c.js.StreamNameBySubject(ctx, config.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("no stream for subject %s: %w", config.Subject, err)
+	}
+
+	consumerConfig, explicit := consumerConfig(config)
+
+	consumer, err := c.js.Consumer(ctx, stream, consumerConfig.Durable)
+	if errors.Is(err, js.ErrConsumerNotFound) {
+		consumer, err = c.js.CreateConsumer(ctx, stream, consumerConfig)
+	} else if err == nil && explicit && !sameStart(consumer.CachedInfo().Config, consumerConfig) {
+		if err := c.js.DeleteConsumer(ctx, stream, consumerConfig.Durable); err != nil && !errors.Is(err, js.ErrConsumerNotFound) {
+			return nil, fmt.Errorf("failed to reset consumer: %w", err)
+		}
+		consumer, err = c.js.CreateConsumer(ctx, stream, consumerConfig)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("subscribe failed: %w", err)
+	}
+
+	c.mu.Lock()
+	c.consumers[consumerKey(config)] = consumer
+	c.mu.Unlock()
+
+	return &notificationStream{ctx: ctx, subject: config.Subject, consumer: consumer}, nil
+}
+
+// Fetch fetches up to BatchSize pending messages and acknowledges them
+func (c *EgressClient) Fetch(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+	if config == nil {
+		return nil, fmt.Errorf("subscription config cannot be nil")
+	}
+
+	if config.Subject == "" {
+		return nil, fmt.Errorf("subject cannot be empty")
+	}
+
+	consumer, err := c.consumer(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := int(config.BatchSize)
+	if batchSize <= 0 {
+		batchSize = 10
+	}
+
+	batch, err := consumer.FetchNoWait(batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed: %w", err)
+	}
+
+	var messages []*domain.ReceivedMessage
+	for msg := range batch.Messages() {
+		metadata, err := msg.Metadata()
+		if err != nil {
+			return nil, fmt.Errorf("fetch failed: %w", err)
+		}
+
+		messages = append(messages, &domain.ReceivedMessage{
+			Subject:   msg.Subject(),
+			Sequence:  metadata.Sequence.Stream,
+			Data:      msg.Data(),
+			Headers:   messageHeaders(msg.Headers()),
+			Timestamp: metadata.Timestamp,
+		})
+
+		if err := msg.Ack(); err != nil {
+			return nil, fmt.Errorf("failed to ack sequence %d: %w", metadata.Sequence.Stream, err)
+		}
+	}
+	if err := batch.Error(); err != nil {
+		return nil, fmt.Errorf("fetch failed: %w", err)
+	}
+
+	return &messageStream{messages: messages}, nil
+}
+
+// GetLastSequence gets the stream sequence of the last message on the subject
+func (c *EgressClient) GetLastSequence(ctx context.Context, subject string) (uint64, error) {
+	if subject == "" {
+		return 0, fmt.Errorf("subject cannot be empty")
+	}
+
+	name, err := c.js.StreamNameBySubject(ctx, subject)
+	if err != nil {
+		return 0, fmt.Errorf("get last sequence failed: %w", err)
+	}
+
+	stream, err := c.js.Stream(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("get last sequence failed: %w", err)
+	}
+
+	msg, err := stream.GetLastMsgForSubject(ctx, subject)
+	if errors.Is(err, js.ErrMsgNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get last sequence failed: %w", err)
+	}
+
+	return msg.Sequence, nil
+}
+
+// ListSubjects implements domain.SubjectLister with the subjects holding
+// messages in any stream, so pattern handlers can discover them
+func (c *EgressClient) ListSubjects(ctx context.Context) ([]string, error) {
+	lister := c.js.StreamNames(ctx)
+	var streams []string
+	for name := range lister.Name() {
+		streams = append(streams, name)
+	}
+	if err := lister.Err(); err != nil {
+		return nil, fmt.Errorf("list subjects failed: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var subjects []string
+	for _, name := range streams {
+		stream, err := c.js.Stream(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("list subjects failed: %w", err)
+		}
+		info, err := stream.Info(ctx, js.WithSubjectFilter(">"))
+		if err != nil {
+			return nil, fmt.Errorf("list subjects failed: %w", err)
+		}
+		for subject := range info.State.Subjects {
+			if !seen[subject] {
+				seen[subject] = true
+				subjects = append(subjects, subject)
+			}
+		}
+	}
+	sort.Strings(subjects)
+	return subjects, nil
+}
+
+// Close closes the NATS connection
+func (c *EgressClient) Close() error {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	return nil
+}
+
+// consumer returns the bound consumer, looking it up when Subscribe was not called
+func (c *EgressClient) consumer(ctx context.Context, config *domain.SubscriptionConfig) (js.Consumer, error) {
+	key := consumerKey(config)
+
+	c.mu.Lock()
+	consumer, ok := c.consumers[key]
+	c.mu.Unlock()
+	if ok {
+		return consumer, nil
+	}
+
+	stream, err := c.js.StreamNameBySubject(ctx, config.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("no stream for subject %s: %w", config.Subject, err)
+	}
+
+	consumer, err = c.js.Consumer(ctx, stream, durableName(config))
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed: %w", err)
+	}
+
+	c.mu.Lock()
+	c.consumers[key] = consumer
+	c.mu.Unlock()
+	return consumer, nil
+}
+
+// consumerConfig maps a subscription to a durable pull consumer configuration.
+// It reports whether the subscription requests an explicit start position.
+func consumerConfig(config *domain.SubscriptionConfig) (js.ConsumerConfig, bool) {
+	cfg := js.ConsumerConfig{
+		Durable:       durableName(config),
+		FilterSubject: config.Subject,
+		AckPolicy:     js.AckExplicitPolicy,
+		DeliverPolicy: js.DeliverAllPolicy,
+	}
+
+	if config.StartSequence != nil {
+		cfg.DeliverPolicy = js.DeliverByStartSequencePolicy
+		cfg.OptStartSeq = max(*config.StartSequence, 1)
+		return cfg, true
+	}
+
+	switch config.StartPosition.Kind {
+	case domain.StartEarliest:
+		return cfg, true
+	case domain.StartLatest:
+		cfg.DeliverPolicy = js.DeliverNewPolicy
+		return cfg, true
+	case domain.StartSequence:
+		cfg.DeliverPolicy = js.DeliverByStartSequencePolicy
+		cfg.OptStartSeq = max(config.StartPosition.Sequence, 1)
+		return cfg, true
+	case domain.StartTime:
+		start := config.StartPosition.Time
+		cfg.DeliverPolicy = js.DeliverByStartTimePolicy
+		cfg.OptStartTime = &start
+		return cfg, true
+	}
+	return cfg, false
+}
+
+// sameStart reports whether an existing consumer already starts where want asks
+func sameStart(have, want js.ConsumerConfig) bool {
+	if have.FilterSubject != want.FilterSubject || have.DeliverPolicy != want.DeliverPolicy || have.OptStartSeq != want.OptStartSeq {
+		return false
+	}
+	if (have.OptStartTime == nil) != (want.OptStartTime == nil) {
+		return false
+	}
+	return have.OptStartTime == nil || have.OptStartTime.Equal(*want.OptStartTime)
+}
+
+// durableName returns the consumer name, defaulting like the subscriber does
+func durableName(config *domain.SubscriptionConfig) string {
+	if config.DurableName == "" {
+		return "default-subscriber"
+	}
+	return config.DurableName
+}
+
+func consumerKey(config *domain.SubscriptionConfig) string {
+	return config.Subject + "/" + durableName(config)
+}
+
+// notificationStream notifies while the consumer has pending messages. It polls
+// the consumer info, and notifies again only after the consumer position or
+// backlog changed.
+type notificationStream struct {
+	ctx      context.Context
+	subject  string
+	consumer js.Consumer
+
+	delivered uint64
+	pending   uint64
+}
+
+func (s *notificationStream) Recv() (*domain.Notification, error) {
+	for {
+		info, err := s.consumer.Info(s.ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		delivered := info.Delivered.Stream
+		if info.NumPending > 0 && (delivered != s.delivered || info.NumPending != s.pending) {
+			s.delivered, s.pending = delivered, info.NumPending
+			return &domain.Notification{
+				Subject:  s.subject,
+				Sequence: delivered + info.NumPending,
+			}, nil
+		}
+
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return nil, s.ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// messageStream iterates over a fetched batch
+type messageStream struct {
+	messages []*domain.ReceivedMessage
+}
+
+func (s *messageStream) Recv() (*domain.ReceivedMessage, error) {
+	if len(s.messages) == 0 {
+		return nil, io.EOF
+	}
+	msg := s.messages[0]
+	s.messages = s.messages[1:]
+	return msg, nil
+}
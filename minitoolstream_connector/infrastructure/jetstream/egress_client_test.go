@@ -0,0 +1,161 @@
+package jetstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	js "github.com/nats-io/nats.go/jetstream"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestNewEgressClient(t *testing.T) {
+	t.Run("empty server URL", func(t *testing.T) {
+		if _, err := NewEgressClient(""); err == nil {
+			t.Fatal("expected error for empty server URL")
+		}
+	})
+}
+
+func TestEgressClient_Validation(t *testing.T) {
+	client := &EgressClient{}
+	ctx := context.Background()
+
+	if _, err := client.Subscribe(ctx, nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+	if _, err := client.Fetch(ctx, &domain.SubscriptionConfig{}); err == nil {
+		t.Error("expected error for empty subject")
+	}
+	if _, err := client.GetLastSequence(ctx, ""); err == nil {
+		t.Error("expected error for empty subject")
+	}
+}
+
+func TestConsumerConfig(t *testing.T) {
+	seq := uint64(0)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		config   *domain.SubscriptionConfig
+		policy   js.DeliverPolicy
+		startSeq uint64
+		explicit bool
+	}{
+		{"default", &domain.SubscriptionConfig{}, js.DeliverAllPolicy, 0, false},
+		{"start sequence zero", &domain.SubscriptionConfig{StartSequence: &seq}, js.DeliverByStartSequencePolicy, 1, true},
+		{"earliest", &domain.SubscriptionConfig{StartPosition: domain.Earliest()}, js.DeliverAllPolicy, 0, true},
+		{"latest", &domain.SubscriptionConfig{StartPosition: domain.Latest()}, js.DeliverNewPolicy, 0, true},
+		{"from sequence", &domain.SubscriptionConfig{StartPosition: domain.FromSequence(42)}, js.DeliverByStartSequencePolicy, 42, true},
+		{"from time", &domain.SubscriptionConfig{StartPosition: domain.FromTime(start)}, js.DeliverByStartTimePolicy, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Subject = "orders"
+			cfg, explicit := consumerConfig(tt.config)
+
+			if cfg.DeliverPolicy != tt.policy {
+				t.Errorf("expected policy %v, got %v", tt.policy, cfg.DeliverPolicy)
+			}
+			if cfg.OptStartSeq != tt.startSeq {
+				t.Errorf("expected start sequence %d, got %d", tt.startSeq, cfg.OptStartSeq)
+			}
+			if explicit != tt.explicit {
+				t.Errorf("expected explicit=%v, got %v", tt.explicit, explicit)
+			}
+			if cfg.FilterSubject != "orders" || cfg.Durable != "default-subscriber" {
+				t.Errorf("unexpected consumer: %+v", cfg)
+			}
+			if cfg.AckPolicy != js.AckExplicitPolicy {
+				t.Error("expected explicit acks")
+			}
+		})
+	}
+}
+
+func TestSameStart(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	want, _ := consumerConfig(&domain.SubscriptionConfig{Subject: "orders", StartPosition: domain.FromSequence(42)})
+	atTime, _ := consumerConfig(&domain.SubscriptionConfig{Subject: "orders", StartPosition: domain.FromTime(start)})
+	sameTime := start.In(time.FixedZone("UTC+1", 3600))
+
+	tests := []struct {
+		name string
+		have js.ConsumerConfig
+		want js.ConsumerConfig
+		same bool
+	}{
+		{"same sequence", want, want, true},
+		{"other sequence", js.ConsumerConfig{FilterSubject: "orders", DeliverPolicy: js.DeliverByStartSequencePolicy, OptStartSeq: 7}, want, false},
+		{"other policy", js.ConsumerConfig{FilterSubject: "orders", DeliverPolicy: js.DeliverAllPolicy}, want, false},
+		{"same time", js.ConsumerConfig{FilterSubject: "orders", DeliverPolicy: js.DeliverByStartTimePolicy, OptStartTime: &sameTime}, atTime, true},
+		{"missing time", js.ConsumerConfig{FilterSubject: "orders", DeliverPolicy: js.DeliverByStartTimePolicy}, atTime, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameStart(tt.have, tt.want); got != tt.same {
+				t.Errorf("expected sameStart=%v, got %v", tt.same, got)
+			}
+		})
+	}
+}
+
+// fakeJetStream serves stream names and per-stream subject state
+type fakeJetStream struct {
+	js.JetStream
+	subjects map[string][]string
+}
+
+func (f *fakeJetStream) StreamNames(ctx context.Context, opts ...js.StreamListOpt) js.StreamNameLister {
+	names := make(chan string, len(f.subjects))
+	for name := range f.subjects {
+		names <- name
+	}
+	close(names)
+	return &fakeNameLister{names: names}
+}
+
+func (f *fakeJetStream) Stream(ctx context.Context, name string) (js.Stream, error) {
+	return &fakeStream{subjects: f.subjects[name]}, nil
+}
+
+type fakeNameLister struct {
+	names chan string
+}
+
+func (l *fakeNameLister) Name() <-chan string { return l.names }
+func (l *fakeNameLister) Err() error          { return nil }
+
+type fakeStream struct {
+	js.Stream
+	subjects []string
+}
+
+func (s *fakeStream) Info(ctx context.Context, opts ...js.StreamInfoOpt) (*js.StreamInfo, error) {
+	state := js.StreamState{Subjects: make(map[string]uint64)}
+	for _, subject := range s.subjects {
+		state.Subjects[subject] = 1
+	}
+	return &js.StreamInfo{State: state}, nil
+}
+
+func TestEgressClient_ListSubjects(t *testing.T) {
+	var _ domain.SubjectLister = (*EgressClient)(nil)
+
+	client := &EgressClient{js: &fakeJetStream{subjects: map[string][]string{
+		"ORDERS":   {"orders.eu", "orders.us"},
+		"PAYMENTS": {"payments"},
+	}}}
+
+	subjects, err := client.ListSubjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListSubjects failed: %v", err)
+	}
+	if len(subjects) != 3 || subjects[0] != "orders.eu" || subjects[1] != "orders.us" || subjects[2] != "payments" {
+		t.Errorf("unexpected subjects: %v", subjects)
+	}
+}
@@ -0,0 +1,112 @@
+// Package jetstream implements domain.IngressClient and domain.EgressClient directly on
+// NATS JetStream, bypassing the MiniToolStream gRPC services.
+//
+// Subjects map to JetStream subjects that must be bound to an existing stream.
+// Durable names map to durable pull consumers filtered on the subject, and sequences
+// are JetStream stream sequences.
+package jetstream
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	js "github.com/nats-io/nats.go/jetstream"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// IngressClient implements domain.IngressClient using JetStream
+type IngressClient struct {
+	conn *nats.Conn
+	js   js.JetStream
+}
+
+// NewIngressClient creates a new JetStream client for publishing
+func NewIngressClient(serverURL string, opts ...nats.Option) (*IngressClient, error) {
+	conn, jetStream, err := connect(serverURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &IngressClient{
+		conn: conn,
+		js:   jetStream,
+	}, nil
+}
+
+// Publish publishes a message and waits for the stream acknowledgement
+func (c *IngressClient) Publish(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+
+	if msg.Subject == "" {
+		return nil, fmt.Errorf("subject cannot be empty")
+	}
+
+	ack, err := c.js.PublishMsg(ctx, &nats.Msg{
+		Subject: msg.Subject,
+		Data:    msg.Data,
+		Header:  natsHeader(msg.WireHeaders()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	return &domain.PublishResult{
+		Sequence:   ack.Sequence,
+		ObjectName: fmt.Sprintf("%s-%d", ack.Stream, ack.Sequence),
+	}, nil
+}
+
+// Close closes the NATS connection
+func (c *IngressClient) Close() error {
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	return nil
+}
+
+// connect opens a NATS connection and a JetStream context
+func connect(serverURL string, opts ...nats.Option) (*nats.Conn, js.JetStream, error) {
+	if serverURL == "" {
+		return nil, nil, fmt.Errorf("server URL is required")
+	}
+
+	conn, err := nats.Connect(serverURL, opts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to %s: %w", serverURL, err)
+	}
+
+	jetStream, err := js.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	return conn, jetStream, nil
+}
+
+// natsHeader converts message headers to NATS headers
+func natsHeader(headers map[string]string) nats.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	h := make(nats.Header, len(headers))
+	for k, v := range headers {
+		h.Set(k, v)
+	}
+	return h
+}
+
+// messageHeaders converts NATS headers to message headers, keeping the first value
+func messageHeaders(h nats.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for k, values := range h {
+		if len(values) > 0 {
+			headers[k] = values[0]
+		}
+	}
+	return headers
+}
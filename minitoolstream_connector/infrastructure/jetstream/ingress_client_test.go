@@ -0,0 +1,60 @@
+package jetstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestNewIngressClient(t *testing.T) {
+	t.Run("empty server URL", func(t *testing.T) {
+		client, err := NewIngressClient("")
+		if err == nil {
+			t.Fatal("expected error for empty server URL")
+		}
+		if client != nil {
+			t.Error("expected nil client")
+		}
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		if _, err := NewIngressClient("nats://127.0.0.1:1", nats.Timeout(100*time.Millisecond)); err == nil {
+			t.Fatal("expected connection error")
+		}
+	})
+}
+
+func TestIngressClient_PublishValidation(t *testing.T) {
+	client := &IngressClient{}
+
+	t.Run("nil message", func(t *testing.T) {
+		if _, err := client.Publish(context.Background(), nil); err == nil {
+			t.Fatal("expected error for nil message")
+		}
+	})
+
+	t.Run("empty subject", func(t *testing.T) {
+		if _, err := client.Publish(context.Background(), &domain.PublishMessage{}); err == nil {
+			t.Fatal("expected error for empty subject")
+		}
+	})
+}
+
+func TestHeaders(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		headers := messageHeaders(natsHeader(map[string]string{"content-type": "text/plain"}))
+		if headers["content-type"] != "text/plain" {
+			t.Errorf("unexpected headers: %v", headers)
+		}
+	})
+
+	t.Run("empty headers", func(t *testing.T) {
+		if natsHeader(nil) != nil {
+			t.Error("expected nil NATS header")
+		}
+	})
+}
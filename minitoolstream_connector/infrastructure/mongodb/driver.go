@@ -0,0 +1,43 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DriverClient implements Client with the official MongoDB driver, writing to
+// the collections of one database
+type DriverClient struct {
+	db *mongo.Database
+}
+
+// NewDriverClient creates a client writing to db
+func NewDriverClient(db *mongo.Database) (*DriverClient, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	return &DriverClient{db: db}, nil
+}
+
+// Upsert replaces the document matching filter, inserting it when none matches
+func (c *DriverClient) Upsert(ctx context.Context, collection string, filter, doc Document) error {
+	_, err := c.db.Collection(collection).ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(true))
+	return err
+}
+
+// EnsureIndex creates the unique (subject, sequence) index Writer upserts by,
+// if the collection does not have it yet
+func (c *DriverClient) EnsureIndex(ctx context.Context, collection string) error {
+	_, err := c.db.Collection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: FieldSubject, Value: 1}, {Key: FieldSequence, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create index on collection %s: %w", collection, err)
+	}
+	return nil
+}
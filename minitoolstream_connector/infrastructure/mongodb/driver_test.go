@@ -0,0 +1,117 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestNewDriverClient(t *testing.T) {
+	if _, err := NewDriverClient(nil); err == nil {
+		t.Error("expected error for nil database")
+	}
+}
+
+func TestDriverClient_Upsert(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("writes an upsert replacement", func(mt *mtest.T) {
+		client, err := NewDriverClient(mt.DB)
+		if err != nil {
+			mt.Fatal(err)
+		}
+		w, _ := NewWriter(&WriterConfig{Client: client, DecodeJSON: true, Logger: &testLogger{}})
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}))
+		ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+		err = w.Handle(context.Background(), &domain.ReceivedMessage{
+			Subject:   "orders",
+			Sequence:  42,
+			Data:      []byte(`{"id":7}`),
+			Headers:   map[string]string{"content-type": "application/json"},
+			Timestamp: ts,
+		})
+		if err != nil {
+			mt.Fatalf("Handle failed: %v", err)
+		}
+
+		started := mt.GetStartedEvent()
+		if started == nil || started.CommandName != "update" {
+			mt.Fatalf("expected an update command, got %+v", started)
+		}
+		if coll := started.Command.Lookup("update").StringValue(); coll != "orders" {
+			mt.Errorf("expected collection orders, got %s", coll)
+		}
+
+		update := started.Command.Lookup("updates").Array().Index(0).Value().Document()
+		if !update.Lookup("upsert").Boolean() {
+			mt.Error("expected upsert")
+		}
+		if seq := update.Lookup("q", FieldSequence).Int64(); seq != 42 {
+			mt.Errorf("expected filter sequence 42, got %d", seq)
+		}
+		replacement := update.Lookup("u")
+		if got := replacement.Document().Lookup(FieldTimestamp).Time().UTC(); !got.Equal(ts) {
+			mt.Errorf("expected a BSON date %v, got %v", ts, got)
+		}
+		if id := replacement.Document().Lookup(FieldBody, "id").Double(); id != 7 {
+			mt.Errorf("expected an embedded JSON body, got %v", replacement)
+		}
+	})
+
+	mt.Run("stores raw data as binary", func(mt *mtest.T) {
+		client, _ := NewDriverClient(mt.DB)
+		w, _ := NewWriter(&WriterConfig{Client: client, Logger: &testLogger{}})
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		if err := w.Handle(context.Background(), &domain.ReceivedMessage{Subject: "images", Sequence: 1, Data: []byte{0x89, 'P'}}); err != nil {
+			mt.Fatalf("Handle failed: %v", err)
+		}
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		subtype, data := update.Lookup("u", FieldData).Binary()
+		if subtype != 0 || string(data) != "\x89P" {
+			mt.Errorf("expected generic binary data, got subtype %d %q", subtype, data)
+		}
+	})
+
+	mt.Run("reports write errors", func(mt *mtest.T) {
+		client, _ := NewDriverClient(mt.DB)
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}))
+
+		err := client.Upsert(context.Background(), "orders", Document{FieldSequence: int64(1)}, Document{FieldSequence: int64(1)})
+		if err == nil {
+			mt.Error("expected the write error to be returned")
+		}
+	})
+}
+
+func TestDriverClient_EnsureIndex(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("creates a unique subject and sequence index", func(mt *mtest.T) {
+		client, _ := NewDriverClient(mt.DB)
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		if err := client.EnsureIndex(context.Background(), "orders"); err != nil {
+			mt.Fatalf("EnsureIndex failed: %v", err)
+		}
+
+		index := mt.GetStartedEvent().Command.Lookup("indexes").Array().Index(0).Value().Document()
+		var keys bson.D
+		if err := bson.Unmarshal(index.Lookup("key").Document(), &keys); err != nil {
+			mt.Fatal(err)
+		}
+		want := bson.D{{Key: FieldSubject, Value: int32(1)}, {Key: FieldSequence, Value: int32(1)}}
+		if len(keys) != 2 || keys[0] != want[0] || keys[1] != want[1] {
+			mt.Errorf("unexpected index keys %v", keys)
+		}
+		if !index.Lookup("unique").Boolean() {
+			mt.Error("expected a unique index")
+		}
+	})
+}
@@ -0,0 +1,163 @@
+// Package mongodb writes MiniToolStream messages into MongoDB collections.
+//
+// Writer depends on the narrow Client interface; DriverClient implements it
+// with the official MongoDB driver, and tests can substitute a fake.
+package mongodb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Document field names used for written messages
+const (
+	FieldSubject   = "subject"
+	FieldSequence  = "sequence"
+	FieldData      = "data"
+	FieldBody      = "body"
+	FieldHeaders   = "headers"
+	FieldTimestamp = "timestamp"
+)
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// Document is a MongoDB document; values are plain Go types the driver
+// marshals to BSON ([]byte as binary, time.Time as date)
+type Document = bson.M
+
+// Client is the MongoDB operation used by this package
+type Client interface {
+	// Upsert replaces the document matching filter in the collection, inserting
+	// it when none matches
+	Upsert(ctx context.Context, collection string, filter, doc Document) error
+}
+
+// Writer inserts received messages as documents. Each message is upserted by
+// subject and sequence, so redelivered messages overwrite their earlier
+// document instead of duplicating it; a unique index on (subject, sequence)
+// keeps this cheap.
+type Writer struct {
+	client     Client
+	collection func(subject string) string
+	decodeJSON bool
+	logger     Logger
+}
+
+// WriterConfig represents configuration for Writer
+type WriterConfig struct {
+	Client Client
+	// Collection maps a subject to its collection (default: the subject itself)
+	Collection func(subject string) string
+	// DecodeJSON stores JSON payloads as an embedded document in the body field
+	// instead of binary data, so they can be queried
+	DecodeJSON bool
+	Logger     Logger
+}
+
+// NewWriter creates a new MongoDB writer handler
+func NewWriter(config *WriterConfig) (*Writer, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	collection := config.Collection
+	if collection == nil {
+		collection = func(subject string) string { return subject }
+	}
+
+	return &Writer{
+		client:     config.Client,
+		collection: collection,
+		decodeJSON: config.DecodeJSON,
+		logger:     logger,
+	}, nil
+}
+
+// CollectionMap returns a collection mapping for WriterConfig that looks up
+// subjects in collections and falls back to fallback, or to the subject itself
+// when fallback is empty
+func CollectionMap(collections map[string]string, fallback string) func(subject string) string {
+	return func(subject string) string {
+		if collection, ok := collections[subject]; ok {
+			return collection
+		}
+		if fallback != "" {
+			return fallback
+		}
+		return subject
+	}
+}
+
+// Handle upserts the message as a document
+func (w *Writer) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	collection := w.collection(msg.Subject)
+	if collection == "" {
+		return fmt.Errorf("no collection for subject %s", msg.Subject)
+	}
+
+	filter := Document{
+		FieldSubject:  msg.Subject,
+		FieldSequence: int64(msg.Sequence),
+	}
+
+	if err := w.client.Upsert(ctx, collection, filter, w.document(msg)); err != nil {
+		return fmt.Errorf("failed to write sequence %d to collection %s: %w", msg.Sequence, collection, err)
+	}
+
+	w.logger.Printf("[%s] Wrote sequence %d to MongoDB collection %s", msg.Subject, msg.Sequence, collection)
+	return nil
+}
+
+// document converts a message to a document. Sequences are stored as int64,
+// since BSON has no unsigned integers.
+func (w *Writer) document(msg *domain.ReceivedMessage) Document {
+	headers := make(Document, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	doc := Document{
+		FieldSubject:   msg.Subject,
+		FieldSequence:  int64(msg.Sequence),
+		FieldHeaders:   headers,
+		FieldTimestamp: msg.Timestamp,
+	}
+
+	if w.decodeJSON && isJSON(msg.Headers["content-type"]) {
+		var body interface{}
+		if err := json.Unmarshal(msg.Data, &body); err == nil {
+			doc[FieldBody] = body
+			return doc
+		}
+		w.logger.Printf("[%s] Sequence %d is not valid JSON, storing raw data", msg.Subject, msg.Sequence)
+	}
+
+	doc[FieldData] = msg.Data
+	return doc
+}
+
+// isJSON reports whether a content type denotes JSON
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,162 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, format)
+}
+
+// upsert is a recorded Upsert call
+type upsert struct {
+	collection string
+	filter     Document
+	doc        Document
+}
+
+type mockClient struct {
+	upserts []upsert
+	err     error
+}
+
+func (m *mockClient) Upsert(ctx context.Context, collection string, filter, doc Document) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.upserts = append(m.upserts, upsert{collection, filter, doc})
+	return nil
+}
+
+func TestNewWriter(t *testing.T) {
+	if _, err := NewWriter(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+	if _, err := NewWriter(&WriterConfig{}); err == nil {
+		t.Error("expected error for nil client")
+	}
+}
+
+func TestWriter_Handle(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("raw data", func(t *testing.T) {
+		client := &mockClient{}
+		w, _ := NewWriter(&WriterConfig{Client: client, Logger: &testLogger{}})
+
+		msg := &domain.ReceivedMessage{
+			Subject:   "images.raw",
+			Sequence:  7,
+			Data:      []byte{0x89, 'P', 'N', 'G'},
+			Headers:   map[string]string{"content-type": "image/png"},
+			Timestamp: ts,
+		}
+		if err := w.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+
+		if len(client.upserts) != 1 {
+			t.Fatalf("expected 1 upsert, got %d", len(client.upserts))
+		}
+		got := client.upserts[0]
+		if got.collection != "images.raw" {
+			t.Errorf("expected collection images.raw, got %s", got.collection)
+		}
+		if !reflect.DeepEqual(got.filter, Document{"subject": "images.raw", "sequence": int64(7)}) {
+			t.Errorf("unexpected filter %v", got.filter)
+		}
+		want := Document{
+			"subject":   "images.raw",
+			"sequence":  int64(7),
+			"data":      []byte{0x89, 'P', 'N', 'G'},
+			"headers":   Document{"content-type": "image/png"},
+			"timestamp": ts,
+		}
+		if !reflect.DeepEqual(got.doc, want) {
+			t.Errorf("unexpected document %v", got.doc)
+		}
+	})
+
+	t.Run("decoded JSON", func(t *testing.T) {
+		client := &mockClient{}
+		w, _ := NewWriter(&WriterConfig{Client: client, DecodeJSON: true, Logger: &testLogger{}})
+
+		msg := &domain.ReceivedMessage{
+			Subject:  "orders",
+			Sequence: 1,
+			Data:     []byte(`{"id":42,"items":["a"]}`),
+			Headers:  map[string]string{"content-type": "application/json; charset=utf-8"},
+		}
+		w.Handle(context.Background(), msg)
+
+		doc := client.upserts[0].doc
+		want := map[string]interface{}{"id": float64(42), "items": []interface{}{"a"}}
+		if !reflect.DeepEqual(doc["body"], want) {
+			t.Errorf("expected decoded body, got %v", doc["body"])
+		}
+		if _, ok := doc["data"]; ok {
+			t.Error("expected no raw data next to the decoded body")
+		}
+	})
+
+	t.Run("invalid JSON is stored raw", func(t *testing.T) {
+		client := &mockClient{}
+		w, _ := NewWriter(&WriterConfig{Client: client, DecodeJSON: true, Logger: &testLogger{}})
+
+		w.Handle(context.Background(), &domain.ReceivedMessage{
+			Subject: "orders",
+			Data:    []byte(`{broken`),
+			Headers: map[string]string{"content-type": "application/vnd.api+json"},
+		})
+
+		doc := client.upserts[0].doc
+		if string(doc["data"].([]byte)) != "{broken" || doc["body"] != nil {
+			t.Errorf("expected raw data, got %v", doc)
+		}
+	})
+
+	t.Run("collection mapping", func(t *testing.T) {
+		client := &mockClient{}
+		w, _ := NewWriter(&WriterConfig{
+			Client:     client,
+			Collection: CollectionMap(map[string]string{"orders.created": "orders"}, "events"),
+			Logger:     &testLogger{},
+		})
+
+		w.Handle(context.Background(), &domain.ReceivedMessage{Subject: "orders.created", Sequence: 1})
+		w.Handle(context.Background(), &domain.ReceivedMessage{Subject: "users.signup", Sequence: 1})
+
+		if client.upserts[0].collection != "orders" || client.upserts[1].collection != "events" {
+			t.Errorf("unexpected collections %s, %s", client.upserts[0].collection, client.upserts[1].collection)
+		}
+		if CollectionMap(nil, "")("logs") != "logs" {
+			t.Error("expected subject as collection without fallback")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		w, _ := NewWriter(&WriterConfig{Client: &mockClient{err: errors.New("connection refused")}, Logger: &testLogger{}})
+		if err := w.Handle(context.Background(), &domain.ReceivedMessage{Subject: "orders"}); err == nil {
+			t.Error("expected client error")
+		}
+
+		w, _ = NewWriter(&WriterConfig{
+			Client:     &mockClient{},
+			Collection: func(string) string { return "" },
+			Logger:     &testLogger{},
+		})
+		if err := w.Handle(context.Background(), &domain.ReceivedMessage{Subject: "orders"}); err == nil {
+			t.Error("expected error for unmapped subject")
+		}
+	})
+}
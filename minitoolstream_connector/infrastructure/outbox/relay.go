@@ -0,0 +1,210 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// Relay publishes pending outbox records and updates their status.
+//
+// Records of one aggregate are published in ID order: after a failure, later
+// records of the same aggregate wait for the next poll. Each message carries
+// an idempotency key derived from the record ID, so a record published again
+// after a crash between publishing and marking it can be dropped as a
+// duplicate by the publisher or the ingest layer. Run a single relay per table.
+type Relay struct {
+	store        Store
+	publisher    domain.Publisher
+	batchSize    int
+	pollInterval time.Duration
+	maxAttempts  int
+	logger       Logger
+	clock        domain.Clock
+}
+
+// RelayConfig represents configuration for Relay
+type RelayConfig struct {
+	Store     Store
+	Publisher domain.Publisher
+	// BatchSize is the number of records read per poll (default 100)
+	BatchSize int
+	// PollInterval is the pause between polls when the outbox is drained (default 1s)
+	PollInterval time.Duration
+	// MaxAttempts is how many times a record is tried before it is marked
+	// failed (0 retries forever)
+	MaxAttempts int
+	Logger      Logger
+	// Clock paces polling (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// NewRelay creates a new outbox relay
+func NewRelay(config *RelayConfig) (*Relay, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Store == nil {
+		return nil, fmt.Errorf("store cannot be nil")
+	}
+
+	if config.Publisher == nil {
+		return nil, fmt.Errorf("publisher cannot be nil")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	pollInterval := config.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	return &Relay{
+		store:        config.Store,
+		publisher:    config.Publisher,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		maxAttempts:  config.MaxAttempts,
+		logger:       logger,
+		clock:        domain.ClockOrSystem(config.Clock),
+	}, nil
+}
+
+// Run relays records until ctx is cancelled or reading the store fails
+func (r *Relay) Run(ctx context.Context) error {
+	r.logger.Printf("✓ Outbox relay started")
+
+	for {
+		n, err := r.RelayOnce(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		// Keep going while full batches are published
+		if n == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.clock.After(r.pollInterval):
+		}
+	}
+}
+
+// RelayOnce publishes one batch of pending records and returns how many were published
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	records, err := r.store.Pending(ctx, r.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read outbox: %w", err)
+	}
+
+	blocked := make(map[string]bool)
+	unblocked := make(map[string]bool)
+	published, err := r.relayBatch(ctx, records, blocked, unblocked, nil)
+	if err != nil || len(unblocked) == 0 {
+		return published, err
+	}
+
+	// The store holds back records behind a head that failed before; read
+	// again so they follow a head that has now left the pending state
+	records, err = r.store.Pending(ctx, r.batchSize)
+	if err != nil {
+		return published, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	n, err := r.relayBatch(ctx, records, blocked, make(map[string]bool), unblocked)
+	return published + n, err
+}
+
+// relayBatch relays records in order, skipping the rest of an aggregate after
+// a failure. Aggregates whose previously failed head left the pending state
+// are added to unblocked; a non-nil only set limits the batch to its aggregates.
+func (r *Relay) relayBatch(ctx context.Context, records []Record, blocked, unblocked, only map[string]bool) (int, error) {
+	published := 0
+	for _, rec := range records {
+		if ctx.Err() != nil {
+			return published, ctx.Err()
+		}
+		if only != nil && !only[rec.AggregateID] {
+			continue
+		}
+		if rec.AggregateID != "" && blocked[rec.AggregateID] {
+			continue
+		}
+		if r.relay(ctx, rec) {
+			published++
+			if rec.AggregateID != "" && rec.Attempts > 0 {
+				unblocked[rec.AggregateID] = true
+			}
+		} else if rec.AggregateID != "" {
+			blocked[rec.AggregateID] = true
+		}
+	}
+	return published, nil
+}
+
+// relay publishes a record and records the outcome. It reports whether the
+// record left the pending state, letting later records of its aggregate through.
+func (r *Relay) relay(ctx context.Context, rec Record) bool {
+	err := r.publisher.Publish(ctx, r.Preparer(rec))
+	if err == nil {
+		if err := r.store.MarkPublished(ctx, rec.ID); err != nil {
+			r.logger.Printf("✗ Failed to mark outbox record %d published: %v", rec.ID, err)
+			return false
+		}
+		return true
+	}
+
+	attempts := rec.Attempts + 1
+	if r.maxAttempts > 0 && attempts >= r.maxAttempts {
+		r.logger.Printf("✗ Outbox record %d failed after %d attempts: %v", rec.ID, attempts, err)
+		if err := r.store.MarkFailed(ctx, rec.ID, attempts, err); err != nil {
+			r.logger.Printf("✗ Failed to mark outbox record %d failed: %v", rec.ID, err)
+			return false
+		}
+		return true
+	}
+
+	r.logger.Printf("✗ Failed to publish outbox record %d (attempt %d): %v", rec.ID, attempts, err)
+	if err := r.store.MarkRetry(ctx, rec.ID, attempts, err); err != nil {
+		r.logger.Printf("✗ Failed to record attempt for outbox record %d: %v", rec.ID, err)
+	}
+	return false
+}
+
+// Preparer returns a message preparer for a record
+func (r *Relay) Preparer(rec Record) domain.MessagePreparer {
+	return domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		headers := make(map[string]string, len(rec.Headers)+1)
+		for k, v := range rec.Headers {
+			headers[k] = v
+		}
+		if headers[domain.HeaderIdempotencyKey] == "" {
+			headers[domain.HeaderIdempotencyKey] = "outbox-" + strconv.FormatInt(rec.ID, 10)
+		}
+		return &domain.PublishMessage{
+			Subject: rec.Subject,
+			Data:    rec.Data,
+			Headers: headers,
+		}, nil
+	})
+}
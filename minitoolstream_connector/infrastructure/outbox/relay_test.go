@@ -0,0 +1,203 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type testLogger struct{}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {}
+
+// memoryStore is an in-memory Store
+type memoryStore struct {
+	records   []Record
+	status    map[int64]string
+	attempts  map[int64]int
+	published []int64
+}
+
+func newMemoryStore(records ...Record) *memoryStore {
+	return &memoryStore{records: records, status: make(map[int64]string), attempts: make(map[int64]int)}
+}
+
+func (s *memoryStore) Pending(ctx context.Context, limit int) ([]Record, error) {
+	var pending []Record
+	blocked := make(map[string]bool)
+	for _, rec := range s.records {
+		if s.status[rec.ID] != "" || len(pending) == limit {
+			continue
+		}
+		if rec.AggregateID != "" && blocked[rec.AggregateID] {
+			continue
+		}
+		rec.Attempts = s.attempts[rec.ID]
+		if rec.AggregateID != "" && rec.Attempts > 0 {
+			blocked[rec.AggregateID] = true
+		}
+		pending = append(pending, rec)
+	}
+	return pending, nil
+}
+
+func (s *memoryStore) MarkPublished(ctx context.Context, id int64) error {
+	s.status[id] = StatusPublished
+	s.published = append(s.published, id)
+	return nil
+}
+
+func (s *memoryStore) MarkRetry(ctx context.Context, id int64, attempts int, cause error) error {
+	s.attempts[id] = attempts
+	return nil
+}
+
+func (s *memoryStore) MarkFailed(ctx context.Context, id int64, attempts int, cause error) error {
+	s.status[id] = StatusFailed
+	s.attempts[id] = attempts
+	return nil
+}
+
+type mockPublisher struct {
+	domain.Publisher
+	failSubjects map[string]bool
+	published    []*domain.PublishMessage
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, preparer domain.MessagePreparer) error {
+	msg, err := preparer.Prepare(ctx)
+	if err != nil {
+		return err
+	}
+	if m.failSubjects[msg.Subject] {
+		return errors.New("unavailable")
+	}
+	m.published = append(m.published, msg)
+	return nil
+}
+
+func TestNewRelay(t *testing.T) {
+	if _, err := NewRelay(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+	if _, err := NewRelay(&RelayConfig{Publisher: &mockPublisher{}}); err == nil {
+		t.Error("expected error for nil store")
+	}
+	if _, err := NewRelay(&RelayConfig{Store: newMemoryStore()}); err == nil {
+		t.Error("expected error for nil publisher")
+	}
+}
+
+func TestRelay_RelayOnce(t *testing.T) {
+	t.Run("publishes and marks records", func(t *testing.T) {
+		store := newMemoryStore(
+			Record{ID: 1, AggregateID: "order-1", Subject: "orders", Data: []byte("created")},
+			Record{ID: 2, AggregateID: "order-1", Subject: "orders", Data: []byte("paid"), Headers: map[string]string{"k": "v"}},
+		)
+		pub := &mockPublisher{}
+		relay, _ := NewRelay(&RelayConfig{Store: store, Publisher: pub, Logger: &testLogger{}})
+
+		n, err := relay.RelayOnce(context.Background())
+		if err != nil {
+			t.Fatalf("RelayOnce failed: %v", err)
+		}
+		if n != 2 || len(store.published) != 2 {
+			t.Fatalf("expected 2 published records, got %d", n)
+		}
+		if pub.published[0].Headers[domain.HeaderIdempotencyKey] != "outbox-1" {
+			t.Errorf("unexpected idempotency key: %v", pub.published[0].Headers)
+		}
+		if pub.published[1].Headers["k"] != "v" {
+			t.Errorf("expected record headers, got %v", pub.published[1].Headers)
+		}
+	})
+
+	t.Run("failure blocks later records of the aggregate", func(t *testing.T) {
+		store := newMemoryStore(
+			Record{ID: 1, AggregateID: "a", Subject: "down"},
+			Record{ID: 2, AggregateID: "a", Subject: "orders"},
+			Record{ID: 3, AggregateID: "b", Subject: "orders"},
+		)
+		pub := &mockPublisher{failSubjects: map[string]bool{"down": true}}
+		relay, _ := NewRelay(&RelayConfig{Store: store, Publisher: pub, Logger: &testLogger{}})
+
+		relay.RelayOnce(context.Background())
+
+		if len(store.published) != 1 || store.published[0] != 3 {
+			t.Errorf("expected only record 3 published, got %v", store.published)
+		}
+		if store.attempts[1] != 1 {
+			t.Errorf("expected one recorded attempt, got %d", store.attempts[1])
+		}
+	})
+
+	t.Run("blocked aggregate does not starve others", func(t *testing.T) {
+		store := newMemoryStore(
+			Record{ID: 1, AggregateID: "a", Subject: "down"},
+			Record{ID: 2, AggregateID: "a", Subject: "orders"},
+			Record{ID: 3, AggregateID: "a", Subject: "orders"},
+			Record{ID: 4, AggregateID: "b", Subject: "orders"},
+		)
+		pub := &mockPublisher{failSubjects: map[string]bool{"down": true}}
+		relay, _ := NewRelay(&RelayConfig{Store: store, Publisher: pub, BatchSize: 2, Logger: &testLogger{}})
+
+		relay.RelayOnce(context.Background())
+		relay.RelayOnce(context.Background())
+
+		if len(store.published) != 1 || store.published[0] != 4 {
+			t.Errorf("expected record 4 published past the blocked aggregate, got %v", store.published)
+		}
+		if store.attempts[1] != 2 {
+			t.Errorf("expected the blocked head to be retried, got %d attempts", store.attempts[1])
+		}
+	})
+
+	t.Run("marks failed after max attempts", func(t *testing.T) {
+		store := newMemoryStore(
+			Record{ID: 1, AggregateID: "a", Subject: "down"},
+			Record{ID: 2, AggregateID: "a", Subject: "orders"},
+		)
+		pub := &mockPublisher{failSubjects: map[string]bool{"down": true}}
+		relay, _ := NewRelay(&RelayConfig{Store: store, Publisher: pub, MaxAttempts: 2, Logger: &testLogger{}})
+
+		relay.RelayOnce(context.Background())
+		relay.RelayOnce(context.Background())
+
+		if store.status[1] != StatusFailed {
+			t.Errorf("expected record 1 failed, got %q", store.status[1])
+		}
+		if len(store.published) != 1 || store.published[0] != 2 {
+			t.Errorf("expected record 2 published after record 1 failed, got %v", store.published)
+		}
+	})
+}
+
+func TestRelay_Run(t *testing.T) {
+	store := newMemoryStore(Record{ID: 1, Subject: "orders"})
+	relay, _ := NewRelay(&RelayConfig{Store: store, Publisher: &mockPublisher{}, Logger: &testLogger{}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := relay.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRelay_RunPollsOnClock(t *testing.T) {
+	clock := connectortest.NewFakeClock(time.Time{})
+	relay, _ := NewRelay(&RelayConfig{Store: newMemoryStore(), Publisher: &mockPublisher{}, Logger: &testLogger{}, Clock: clock})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- relay.Run(ctx) }()
+
+	clock.WaitForWaiters(t, 1, 2*time.Second)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
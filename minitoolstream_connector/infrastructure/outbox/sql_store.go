@@ -0,0 +1,188 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Record statuses stored in the status column
+const (
+	StatusPending   = "pending"
+	StatusPublished = "published"
+	StatusFailed    = "failed"
+)
+
+// Execer is implemented by *sql.DB, *sql.Tx and *sql.Conn
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// SQLStore keeps the outbox in a database table with the columns
+//
+//	id           auto-incrementing integer primary key
+//	aggregate_id text
+//	subject      text
+//	headers      text (JSON object)
+//	payload      binary
+//	status       text
+//	attempts     integer
+//	last_error   text
+//	created_at   timestamp
+//	published_at timestamp, nullable
+//
+// An index on (aggregate_id, status, id) keeps the pending query cheap.
+type SQLStore struct {
+	db          *sql.DB
+	table       string
+	placeholder func(n int) string
+	clock       domain.Clock
+}
+
+// SQLStoreConfig represents configuration for SQLStore
+type SQLStoreConfig struct {
+	DB *sql.DB
+	// Table is the outbox table name (default "outbox")
+	Table string
+	// NumberedPlaceholders uses $1, $2, ... (PostgreSQL) instead of ? (MySQL, SQLite)
+	NumberedPlaceholders bool
+	// Clock stamps created_at and published_at (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// NewSQLStore creates a new SQL outbox store
+func NewSQLStore(config *SQLStoreConfig) (*SQLStore, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.DB == nil {
+		return nil, fmt.Errorf("db cannot be nil")
+	}
+
+	table := config.Table
+	if table == "" {
+		table = "outbox"
+	}
+
+	placeholder := func(n int) string { return "?" }
+	if config.NumberedPlaceholders {
+		placeholder = func(n int) string { return fmt.Sprintf("$%d", n) }
+	}
+
+	return &SQLStore{
+		db:          config.DB,
+		table:       table,
+		placeholder: placeholder,
+		clock:       domain.ClockOrSystem(config.Clock),
+	}, nil
+}
+
+// Add inserts a message into the outbox. Pass the transaction that changes
+// the aggregate so the message is stored only if that transaction commits.
+func (s *SQLStore) Add(ctx context.Context, exec Execer, aggregateID string, msg *domain.PublishMessage) error {
+	if msg == nil {
+		return fmt.Errorf("message cannot be nil")
+	}
+
+	headers, err := json.Marshal(msg.Headers)
+	if err != nil {
+		return fmt.Errorf("failed to encode headers: %w", err)
+	}
+
+	if _, err := exec.ExecContext(ctx, s.insertQuery(),
+		aggregateID, msg.Subject, string(headers), msg.Data, StatusPending, 0, s.clock.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to insert outbox record: %w", err)
+	}
+	return nil
+}
+
+// Pending implements Store
+func (s *SQLStore) Pending(ctx context.Context, limit int) ([]Record, error) {
+	rows, err := s.db.QueryContext(ctx, s.pendingQuery(), StatusPending, StatusPending, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var records []Record
+	for rows.Next() {
+		var rec Record
+		var aggregateID sql.NullString
+		var headers sql.NullString
+		if err := rows.Scan(&rec.ID, &aggregateID, &rec.Subject, &headers, &rec.Data, &rec.Attempts, &rec.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox record: %w", err)
+		}
+		rec.AggregateID = aggregateID.String
+		if headers.Valid && headers.String != "" {
+			if err := json.Unmarshal([]byte(headers.String), &rec.Headers); err != nil {
+				return nil, fmt.Errorf("failed to decode headers of outbox record %d: %w", rec.ID, err)
+			}
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read outbox: %w", err)
+	}
+	return records, nil
+}
+
+// MarkPublished implements Store
+func (s *SQLStore) MarkPublished(ctx context.Context, id int64) error {
+	query := fmt.Sprintf("UPDATE %s SET status = %s, published_at = %s WHERE id = %s",
+		s.table, s.placeholder(1), s.placeholder(2), s.placeholder(3))
+	return s.exec(ctx, query, StatusPublished, s.clock.Now().UTC(), id)
+}
+
+// MarkRetry implements Store
+func (s *SQLStore) MarkRetry(ctx context.Context, id int64, attempts int, cause error) error {
+	return s.markAttempt(ctx, id, StatusPending, attempts, cause)
+}
+
+// MarkFailed implements Store
+func (s *SQLStore) MarkFailed(ctx context.Context, id int64, attempts int, cause error) error {
+	return s.markAttempt(ctx, id, StatusFailed, attempts, cause)
+}
+
+// markAttempt stores the status, attempt count and error of a record
+func (s *SQLStore) markAttempt(ctx context.Context, id int64, status string, attempts int, cause error) error {
+	query := fmt.Sprintf("UPDATE %s SET status = %s, attempts = %s, last_error = %s WHERE id = %s",
+		s.table, s.placeholder(1), s.placeholder(2), s.placeholder(3), s.placeholder(4))
+
+	var lastError string
+	if cause != nil {
+		lastError = cause.Error()
+	}
+	return s.exec(ctx, query, status, attempts, lastError, id)
+}
+
+// exec runs an update statement
+func (s *SQLStore) exec(ctx context.Context, query string, args ...interface{}) error {
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update outbox: %w", err)
+	}
+	return nil
+}
+
+// insertQuery builds the statement used by Add
+func (s *SQLStore) insertQuery() string {
+	placeholders := make([]string, 7)
+	for i := range placeholders {
+		placeholders[i] = s.placeholder(i + 1)
+	}
+	return fmt.Sprintf("INSERT INTO %s (aggregate_id, subject, headers, payload, status, attempts, created_at) VALUES (%s)",
+		s.table, strings.Join(placeholders, ", "))
+}
+
+// pendingQuery builds the statement used by Pending. Only the head of an
+// aggregate whose head has failed before is selected.
+func (s *SQLStore) pendingQuery() string {
+	return fmt.Sprintf("SELECT id, aggregate_id, subject, headers, payload, attempts, created_at FROM %s o WHERE status = %s"+
+		" AND NOT EXISTS (SELECT 1 FROM %s h WHERE h.aggregate_id = o.aggregate_id AND h.aggregate_id <> '' AND h.status = %s AND h.attempts > 0 AND h.id < o.id)"+
+		" ORDER BY id LIMIT %s",
+		s.table, s.placeholder(1), s.table, s.placeholder(2), s.placeholder(3))
+}
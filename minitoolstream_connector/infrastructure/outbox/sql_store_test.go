@@ -0,0 +1,208 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// recordingDriver is a database/sql driver that records statements and
+// answers queries with preset rows
+type recordingDriver struct {
+	mu      sync.Mutex
+	execs   []recordedStmt
+	queries []recordedStmt
+	rows    [][]driver.Value
+}
+
+type recordedStmt struct {
+	query string
+	args  []driver.Value
+}
+
+var testDriver = &recordingDriver{}
+
+// testNow is the time of the fake clock given to test stores
+var testNow = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func init() {
+	sql.Register("outbox-test", testDriver)
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) { return &recordingConn{d: d}, nil }
+
+func (d *recordingDriver) reset(rows ...[]driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.execs = nil
+	d.queries = nil
+	d.rows = rows
+}
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{d: c.d, query: query}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type recordingStmt struct {
+	d     *recordingDriver
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.execs = append(s.d.execs, recordedStmt{query: s.query, args: args})
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.queries = append(s.d.queries, recordedStmt{query: s.query, args: args})
+	return &presetRows{rows: s.d.rows}, nil
+}
+
+type presetRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *presetRows) Columns() []string {
+	return []string{"id", "aggregate_id", "subject", "headers", "payload", "attempts", "created_at"}
+}
+func (r *presetRows) Close() error { return nil }
+
+func (r *presetRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func newTestSQLStore(t *testing.T, numbered bool) *SQLStore {
+	t.Helper()
+	db, err := sql.Open("outbox-test", "")
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	store, err := NewSQLStore(&SQLStoreConfig{
+		DB:                   db,
+		Table:                "events_outbox",
+		NumberedPlaceholders: numbered,
+		Clock:                connectortest.NewFakeClock(testNow),
+	})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	return store
+}
+
+func TestNewSQLStore(t *testing.T) {
+	if _, err := NewSQLStore(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+	if _, err := NewSQLStore(&SQLStoreConfig{}); err == nil {
+		t.Error("expected error for nil db")
+	}
+}
+
+func TestSQLStore_Add(t *testing.T) {
+	testDriver.reset()
+	store := newTestSQLStore(t, true)
+
+	msg := &domain.PublishMessage{Subject: "orders", Data: []byte("created"), Headers: map[string]string{"k": "v"}}
+	if err := store.Add(context.Background(), store.db, "order-1", msg); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if len(testDriver.execs) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(testDriver.execs))
+	}
+	stmt := testDriver.execs[0]
+	if !strings.HasPrefix(stmt.query, "INSERT INTO events_outbox") || !strings.Contains(stmt.query, "$7") {
+		t.Errorf("unexpected query: %s", stmt.query)
+	}
+	if stmt.args[0] != "order-1" || stmt.args[1] != "orders" || stmt.args[2] != `{"k":"v"}` || stmt.args[4] != StatusPending {
+		t.Errorf("unexpected args: %v", stmt.args)
+	}
+	if created, _ := stmt.args[6].(time.Time); !created.Equal(testNow) {
+		t.Errorf("expected created_at from the clock, got %v", stmt.args[6])
+	}
+}
+
+func TestSQLStore_Pending(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	testDriver.reset(
+		[]driver.Value{int64(1), "order-1", "orders", `{"k":"v"}`, []byte("created"), int64(0), created},
+		[]driver.Value{int64(2), nil, "orders", nil, []byte("paid"), int64(2), created},
+	)
+	store := newTestSQLStore(t, false)
+
+	records, err := store.Pending(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].AggregateID != "order-1" || records[0].Headers["k"] != "v" || string(records[0].Data) != "created" {
+		t.Errorf("unexpected record: %+v", records[0])
+	}
+	if records[1].AggregateID != "" || records[1].Headers != nil || records[1].Attempts != 2 {
+		t.Errorf("unexpected record: %+v", records[1])
+	}
+
+	query := testDriver.queries[0]
+	if !strings.Contains(query.query, "NOT EXISTS (SELECT 1 FROM events_outbox h WHERE h.aggregate_id = o.aggregate_id") {
+		t.Errorf("expected blocked aggregates to be excluded, got %s", query.query)
+	}
+	if len(query.args) != 3 || query.args[0] != StatusPending || query.args[1] != StatusPending || query.args[2] != int64(10) {
+		t.Errorf("unexpected args: %v", query.args)
+	}
+}
+
+func TestSQLStore_Mark(t *testing.T) {
+	testDriver.reset()
+	store := newTestSQLStore(t, false)
+	ctx := context.Background()
+
+	store.MarkPublished(ctx, 1)
+	store.MarkRetry(ctx, 2, 1, errors.New("unavailable"))
+	store.MarkFailed(ctx, 3, 5, errors.New("rejected"))
+
+	if len(testDriver.execs) != 3 {
+		t.Fatalf("expected 3 statements, got %d", len(testDriver.execs))
+	}
+	if args := testDriver.execs[0].args; args[0] != StatusPublished || args[1] != testNow || args[2] != int64(1) {
+		t.Errorf("unexpected publish args: %v", args)
+	}
+	if args := testDriver.execs[1].args; args[0] != StatusPending || args[1] != int64(1) || args[2] != "unavailable" {
+		t.Errorf("unexpected retry args: %v", args)
+	}
+	if args := testDriver.execs[2].args; args[0] != StatusFailed || args[1] != int64(5) {
+		t.Errorf("unexpected failed args: %v", args)
+	}
+	if strings.Contains(testDriver.execs[2].query, "$") {
+		t.Errorf("expected ? placeholders, got %s", testDriver.execs[2].query)
+	}
+}
@@ -0,0 +1,33 @@
+package outbox
+
+import (
+	"context"
+	"time"
+)
+
+// Record is a message waiting in the outbox table
+type Record struct {
+	ID int64
+	// AggregateID groups records whose order must be preserved, e.g. the ID of
+	// the entity the event belongs to
+	AggregateID string
+	Subject     string
+	Headers     map[string]string
+	Data        []byte
+	Attempts    int
+	CreatedAt   time.Time
+}
+
+// Store reads pending records and records their outcome
+type Store interface {
+	// Pending returns up to limit pending records ordered by ID. Records behind
+	// an earlier pending record of the same aggregate that has already failed an
+	// attempt are left out, so a blocked aggregate cannot fill the batch.
+	Pending(ctx context.Context, limit int) ([]Record, error)
+	// MarkPublished marks a record as published
+	MarkPublished(ctx context.Context, id int64) error
+	// MarkRetry records a failed attempt; the record stays pending
+	MarkRetry(ctx context.Context, id int64, attempts int, cause error) error
+	// MarkFailed marks a record as failed for good
+	MarkFailed(ctx context.Context, id int64, attempts int, cause error) error
+}
@@ -0,0 +1,62 @@
+// Package pubsub implements domain.IngressClient and domain.EgressClient on top of
+// Google Cloud Pub/Sub, so applications written against this module's interfaces
+// can run in environments without a MiniToolStream server.
+//
+// The clients depend on the narrow Client interface; GCPClient implements it
+// with cloud.google.com/go/pubsub, and tests can substitute a fake.
+//
+// Pub/Sub has no stream sequences: sequences are assigned by IngressClient and carried
+// in the HeaderSequence attribute, and start positions are governed by the
+// subscription's acknowledgement state rather than SubscriptionConfig. For the
+// same reason EgressClient.GetLastSequence returns errors.ErrUnsupported.
+package pubsub
+
+import (
+	"context"
+	"time"
+)
+
+// HeaderSequence is the attribute carrying the sequence assigned on publish
+const HeaderSequence = "x-stream-sequence"
+
+// Message is a Pub/Sub message delivered to a subscription
+type Message struct {
+	ID          string
+	Data        []byte
+	Attributes  map[string]string
+	PublishTime time.Time
+	// Ack acknowledges the message; it may be nil
+	Ack func()
+}
+
+// Client is the set of Pub/Sub operations used by this package
+type Client interface {
+	// Publish publishes data to the topic and returns the server message ID
+	Publish(ctx context.Context, topic string, data []byte, attributes map[string]string) (string, error)
+	// Receive calls fn for each message of the subscription until ctx is done
+	Receive(ctx context.Context, subscription string, fn func(ctx context.Context, msg *Message)) error
+	Close() error
+}
+
+// Config represents configuration shared by the Pub/Sub clients
+type Config struct {
+	Client Client
+	// TopicPrefix is prepended to subjects to form topic IDs
+	TopicPrefix string
+	// SubscriptionName maps a subject and durable name to a subscription ID
+	// (default "<prefix><subject>-<durable>")
+	SubscriptionName func(subject, durableName string) string
+}
+
+// topic returns the topic ID for a subject
+func (c *Config) topic(subject string) string {
+	return c.TopicPrefix + subject
+}
+
+// subscription returns the subscription ID for a subject and durable name
+func (c *Config) subscription(subject, durableName string) string {
+	if c.SubscriptionName != nil {
+		return c.SubscriptionName(subject, durableName)
+	}
+	return c.TopicPrefix + subject + "-" + durableName
+}
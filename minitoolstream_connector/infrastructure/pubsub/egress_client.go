@@ -0,0 +1,210 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// EgressClient implements domain.EgressClient using Pub/Sub subscriptions.
+// Subscribe starts receiving into a local buffer; Fetch drains and acknowledges it.
+// Messages are acknowledged before handlers run, so delivery is at most once and
+// the subscriber's strict mode is refused (see domain.FetchAcker).
+type EgressClient struct {
+	config *Config
+
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+}
+
+// subscription buffers messages received for one subscription
+type subscription struct {
+	mu       sync.Mutex
+	buffered []*Message
+	next     uint64
+	notify   chan struct{}
+	done     chan struct{}
+	err      error
+}
+
+// NewEgressClient creates a new Pub/Sub egress client
+func NewEgressClient(config *Config) (*EgressClient, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+
+	return &EgressClient{
+		config:        config,
+		subscriptions: make(map[string]*subscription),
+	}, nil
+}
+
+// Subscribe starts receiving from the subscription of the subject and durable name
+func (c *EgressClient) Subscribe(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+	if config == nil {
+		return nil, fmt.Errorf("subscription config cannot be nil")
+	}
+
+	if config.Subject == "" {
+		return nil, fmt.Errorf("subject cannot be empty")
+	}
+
+	name := c.config.subscription(config.Subject, config.DurableName)
+	sub := &subscription{
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+
+	c.mu.Lock()
+	c.subscriptions[name] = sub
+	c.mu.Unlock()
+
+	go func() {
+		err := c.config.Client.Receive(ctx, name, func(ctx context.Context, msg *Message) {
+			sub.mu.Lock()
+			sub.buffered = append(sub.buffered, msg)
+			sub.mu.Unlock()
+
+			select {
+			case sub.notify <- struct{}{}:
+			default:
+			}
+		})
+
+		sub.mu.Lock()
+		sub.err = err
+		sub.mu.Unlock()
+		close(sub.done)
+	}()
+
+	return &notificationStream{ctx: ctx, subject: config.Subject, sub: sub}, nil
+}
+
+// Fetch returns up to BatchSize buffered messages, acknowledging them
+func (c *EgressClient) Fetch(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+	if config == nil {
+		return nil, fmt.Errorf("subscription config cannot be nil")
+	}
+
+	name := c.config.subscription(config.Subject, config.DurableName)
+
+	c.mu.Lock()
+	sub, ok := c.subscriptions[name]
+	c.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("subscription %q is not active", name)
+	}
+
+	batchSize := int(config.BatchSize)
+	if batchSize <= 0 {
+		batchSize = 10
+	}
+
+	sub.mu.Lock()
+	n := min(batchSize, len(sub.buffered))
+	batch := sub.buffered[:n]
+	sub.buffered = sub.buffered[n:]
+
+	messages := make([]*domain.ReceivedMessage, 0, n)
+	for _, msg := range batch {
+		messages = append(messages, sub.convert(config.Subject, msg))
+	}
+	sub.mu.Unlock()
+
+	for _, msg := range batch {
+		if msg.Ack != nil {
+			msg.Ack()
+		}
+	}
+
+	return &messageStream{messages: messages}, nil
+}
+
+// GetLastSequence is not supported: Pub/Sub does not expose the last message
+// of a topic, and the sequences seen by this client say nothing about it
+func (c *EgressClient) GetLastSequence(ctx context.Context, subject string) (uint64, error) {
+	return 0, fmt.Errorf("pubsub: last sequence of %s: %w", subject, errors.ErrUnsupported)
+}
+
+// AcksOnFetch implements domain.FetchAcker
+func (c *EgressClient) AcksOnFetch() bool {
+	return true
+}
+
+// Close closes the underlying client
+func (c *EgressClient) Close() error {
+	return c.config.Client.Close()
+}
+
+// convert builds a received message; the caller holds s.mu.
+// Messages without a sequence attribute are numbered locally.
+func (s *subscription) convert(subject string, msg *Message) *domain.ReceivedMessage {
+	headers := make(map[string]string, len(msg.Attributes))
+	for k, v := range msg.Attributes {
+		if k != HeaderSequence {
+			headers[k] = v
+		}
+	}
+
+	sequence, err := strconv.ParseUint(msg.Attributes[HeaderSequence], 10, 64)
+	if err != nil {
+		sequence = s.next + 1
+	}
+	if sequence > s.next {
+		s.next = sequence
+	}
+
+	return &domain.ReceivedMessage{
+		Subject:   subject,
+		Sequence:  sequence,
+		Data:      msg.Data,
+		Headers:   headers,
+		Timestamp: msg.PublishTime,
+	}
+}
+
+// notificationStream signals when messages are buffered
+type notificationStream struct {
+	ctx     context.Context
+	subject string
+	sub     *subscription
+}
+
+func (s *notificationStream) Recv() (*domain.Notification, error) {
+	select {
+	case <-s.sub.notify:
+		return &domain.Notification{Subject: s.subject}, nil
+	case <-s.sub.done:
+		s.sub.mu.Lock()
+		defer s.sub.mu.Unlock()
+		if s.sub.err != nil {
+			return nil, s.sub.err
+		}
+		return nil, io.EOF
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+// messageStream iterates over a fetched batch
+type messageStream struct {
+	messages []*domain.ReceivedMessage
+}
+
+func (s *messageStream) Recv() (*domain.ReceivedMessage, error) {
+	if len(s.messages) == 0 {
+		return nil, io.EOF
+	}
+	msg := s.messages[0]
+	s.messages = s.messages[1:]
+	return msg, nil
+}
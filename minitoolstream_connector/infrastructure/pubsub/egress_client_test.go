@@ -0,0 +1,146 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestNewEgressClient(t *testing.T) {
+	t.Run("nil config", func(t *testing.T) {
+		if _, err := NewEgressClient(nil); err == nil {
+			t.Fatal("expected error for nil config")
+		}
+	})
+
+	t.Run("nil client", func(t *testing.T) {
+		if _, err := NewEgressClient(&Config{}); err == nil {
+			t.Fatal("expected error for nil client")
+		}
+	})
+}
+
+func TestConfig_Subscription(t *testing.T) {
+	t.Run("default name", func(t *testing.T) {
+		config := &Config{TopicPrefix: "mts-"}
+		if name := config.subscription("orders", "billing"); name != "mts-orders-billing" {
+			t.Errorf("expected mts-orders-billing, got %s", name)
+		}
+	})
+
+	t.Run("custom name", func(t *testing.T) {
+		config := &Config{SubscriptionName: func(subject, durableName string) string {
+			return durableName + "." + subject
+		}}
+		if name := config.subscription("orders", "billing"); name != "billing.orders" {
+			t.Errorf("expected billing.orders, got %s", name)
+		}
+	})
+}
+
+func TestEgressClient_SubscribeFetch(t *testing.T) {
+	client := newFakeClient()
+	client.topicOf = func(subscription string) string {
+		return strings.TrimSuffix(subscription, "-billing")
+	}
+	config := &Config{Client: client}
+
+	ingress, _ := NewIngressClient(config)
+	egress, _ := NewEgressClient(config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, data := range []string{"one", "two", "three"} {
+		ingress.Publish(ctx, &domain.PublishMessage{
+			Subject: "orders",
+			Data:    []byte(data),
+			Headers: map[string]string{"tenant": "acme"},
+		})
+	}
+	client.Publish(ctx, "orders", []byte("external"), nil)
+
+	subConfig := &domain.SubscriptionConfig{Subject: "orders", DurableName: "billing", BatchSize: 2}
+
+	t.Run("fetch before subscribe", func(t *testing.T) {
+		if _, err := egress.Fetch(ctx, subConfig); err == nil {
+			t.Fatal("expected error for inactive subscription")
+		}
+	})
+
+	stream, err := egress.Subscribe(ctx, subConfig)
+	if err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+	<-client.receiving
+
+	t.Run("notification", func(t *testing.T) {
+		notification, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("expected notification, got %v", err)
+		}
+		if notification.Subject != "orders" {
+			t.Errorf("expected subject orders, got %s", notification.Subject)
+		}
+	})
+
+	var received []*domain.ReceivedMessage
+	for len(received) < 4 {
+		messages, err := egress.Fetch(ctx, subConfig)
+		if err != nil {
+			t.Fatalf("fetch failed: %v", err)
+		}
+		batch := 0
+		for {
+			msg, err := messages.Recv()
+			if err == io.EOF {
+				break
+			}
+			received = append(received, msg)
+			batch++
+		}
+		if batch > 2 {
+			t.Fatalf("expected batches of at most 2, got %d", batch)
+		}
+	}
+
+	t.Run("messages", func(t *testing.T) {
+		if string(received[0].Data) != "one" || received[0].Sequence != 1 {
+			t.Errorf("unexpected first message: %+v", received[0])
+		}
+		if received[2].Headers["tenant"] != "acme" {
+			t.Errorf("expected tenant header, got %v", received[2].Headers)
+		}
+		if _, ok := received[2].Headers[HeaderSequence]; ok {
+			t.Error("expected sequence attribute to be removed from headers")
+		}
+		if received[3].Sequence != 4 {
+			t.Errorf("expected external message to be numbered 4, got %d", received[3].Sequence)
+		}
+		if client.acked != 4 {
+			t.Errorf("expected 4 acks, got %d", client.acked)
+		}
+	})
+
+	t.Run("last sequence is unsupported", func(t *testing.T) {
+		if _, err := egress.GetLastSequence(ctx, "orders"); !errors.Is(err, errors.ErrUnsupported) {
+			t.Errorf("expected errors.ErrUnsupported, got %v", err)
+		}
+		if !egress.AcksOnFetch() {
+			t.Error("expected the client to report acks on fetch")
+		}
+	})
+
+	t.Run("stream ends with context", func(t *testing.T) {
+		cancel()
+		for {
+			if _, err := stream.Recv(); err != nil {
+				break
+			}
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	gcppubsub "cloud.google.com/go/pubsub"
+	"google.golang.org/api/option"
+)
+
+// GCPClient implements Client with the Cloud Pub/Sub SDK. Topics and
+// subscriptions must exist; the client does not create them.
+type GCPClient struct {
+	client *gcppubsub.Client
+
+	mu     sync.Mutex
+	topics map[string]*gcppubsub.Topic
+}
+
+// NewGCPClient creates a Pub/Sub client for a project. Credentials and the
+// endpoint come from opts or the environment, e.g. GOOGLE_APPLICATION_CREDENTIALS
+// or PUBSUB_EMULATOR_HOST.
+func NewGCPClient(ctx context.Context, projectID string, opts ...option.ClientOption) (*GCPClient, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("project ID is required")
+	}
+
+	client, err := gcppubsub.NewClient(ctx, projectID, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Pub/Sub client: %w", err)
+	}
+	return WrapGCPClient(client), nil
+}
+
+// WrapGCPClient adapts an existing SDK client; Close closes it
+func WrapGCPClient(client *gcppubsub.Client) *GCPClient {
+	return &GCPClient{
+		client: client,
+		topics: make(map[string]*gcppubsub.Topic),
+	}
+}
+
+// Publish publishes data to the topic and waits for the server message ID
+func (c *GCPClient) Publish(ctx context.Context, topic string, data []byte, attributes map[string]string) (string, error) {
+	result := c.topic(topic).Publish(ctx, &gcppubsub.Message{
+		Data:       data,
+		Attributes: attributes,
+	})
+	return result.Get(ctx)
+}
+
+// Receive streams the messages of the subscription to fn until ctx is done.
+// Messages are acknowledged through Message.Ack, after they were fetched.
+func (c *GCPClient) Receive(ctx context.Context, subscription string, fn func(ctx context.Context, msg *Message)) error {
+	return c.client.Subscription(subscription).Receive(ctx, func(ctx context.Context, m *gcppubsub.Message) {
+		fn(ctx, &Message{
+			ID:          m.ID,
+			Data:        m.Data,
+			Attributes:  m.Attributes,
+			PublishTime: m.PublishTime,
+			Ack:         m.Ack,
+		})
+	})
+}
+
+// Close flushes pending publishes and closes the SDK client
+func (c *GCPClient) Close() error {
+	c.mu.Lock()
+	for _, topic := range c.topics {
+		topic.Stop()
+	}
+	c.topics = make(map[string]*gcppubsub.Topic)
+	c.mu.Unlock()
+
+	return c.client.Close()
+}
+
+// topic returns the publisher of a topic, reusing it so messages are batched
+func (c *GCPClient) topic(id string) *gcppubsub.Topic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	topic, ok := c.topics[id]
+	if !ok {
+		topic = c.client.Topic(id)
+		c.topics[id] = topic
+	}
+	return topic
+}
@@ -0,0 +1,103 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gcppubsub "cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// newFakeServer starts an in-process Pub/Sub server with the topic and
+// subscription of the "orders" subject
+func newFakeServer(t *testing.T) *GCPClient {
+	t.Helper()
+	ctx := context.Background()
+
+	srv := pstest.NewServer()
+	t.Cleanup(func() { srv.Close() })
+	conn, err := grpc.NewClient(srv.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial fake server: %v", err)
+	}
+
+	client, err := NewGCPClient(ctx, "test-project", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("NewGCPClient failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	topic, err := client.client.CreateTopic(ctx, "stream-orders")
+	if err != nil {
+		t.Fatalf("failed to create topic: %v", err)
+	}
+	if _, err := client.client.CreateSubscription(ctx, "stream-orders-workers", gcppubsub.SubscriptionConfig{Topic: topic}); err != nil {
+		t.Fatalf("failed to create subscription: %v", err)
+	}
+	return client
+}
+
+func TestNewGCPClient_Validation(t *testing.T) {
+	if _, err := NewGCPClient(context.Background(), ""); err == nil {
+		t.Error("expected error for empty project ID")
+	}
+}
+
+func TestGCPClient_RoundTrip(t *testing.T) {
+	client := newFakeServer(t)
+	config := &Config{Client: client, TopicPrefix: "stream-"}
+
+	ingress, err := NewIngressClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	egress, err := NewEgressClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := ingress.Publish(ctx, &domain.PublishMessage{
+		Subject: "orders",
+		Data:    []byte(`{"id":1}`),
+		Headers: map[string]string{"content-type": "application/json"},
+	})
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if result.Sequence != 1 || result.ObjectName == "" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	subConfig := &domain.SubscriptionConfig{Subject: "orders", DurableName: "workers", BatchSize: 10}
+	stream, err := egress.Subscribe(ctx, subConfig)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("expected a notification, got %v", err)
+	}
+
+	messages, err := egress.Fetch(ctx, subConfig)
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	msg, err := messages.Recv()
+	if err != nil {
+		t.Fatalf("expected a message, got %v", err)
+	}
+	if string(msg.Data) != `{"id":1}` || msg.Sequence != 1 || msg.Headers["content-type"] != "application/json" {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if _, ok := msg.Headers[HeaderSequence]; ok {
+		t.Error("expected the sequence attribute to be stripped from headers")
+	}
+}
@@ -0,0 +1,73 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// IngressClient implements domain.IngressClient using Pub/Sub topics
+type IngressClient struct {
+	config *Config
+
+	mu        sync.Mutex
+	sequences map[string]uint64
+}
+
+// NewIngressClient creates a new Pub/Sub ingress client
+func NewIngressClient(config *Config) (*IngressClient, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+
+	return &IngressClient{
+		config:    config,
+		sequences: make(map[string]uint64),
+	}, nil
+}
+
+// Publish publishes a message to the subject's topic.
+// Headers become attributes; the returned object name is the Pub/Sub message ID.
+func (c *IngressClient) Publish(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+
+	if msg.Subject == "" {
+		return nil, fmt.Errorf("subject cannot be empty")
+	}
+
+	c.mu.Lock()
+	c.sequences[msg.Subject]++
+	sequence := c.sequences[msg.Subject]
+	c.mu.Unlock()
+
+	headers := msg.WireHeaders()
+	attributes := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		attributes[k] = v
+	}
+	attributes[HeaderSequence] = strconv.FormatUint(sequence, 10)
+
+	id, err := c.config.Client.Publish(ctx, c.config.topic(msg.Subject), msg.Data, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	return &domain.PublishResult{
+		Sequence:   sequence,
+		ObjectName: id,
+	}, nil
+}
+
+// Close closes the underlying client
+func (c *IngressClient) Close() error {
+	return c.config.Client.Close()
+}
@@ -0,0 +1,137 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// fakeClient is an in-memory Pub/Sub; Receive replays the messages of the
+// topic that topicOf maps the subscription to
+type fakeClient struct {
+	mu         sync.Mutex
+	published  map[string][]*Message
+	topicOf    func(subscription string) string
+	receiving  chan string
+	publishErr error
+	closed     bool
+	acked      int
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		published: make(map[string][]*Message),
+		receiving: make(chan string, 10),
+	}
+}
+
+func (c *fakeClient) Publish(ctx context.Context, topic string, data []byte, attributes map[string]string) (string, error) {
+	if c.publishErr != nil {
+		return "", c.publishErr
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id := fmt.Sprintf("%s-%d", topic, len(c.published[topic])+1)
+	c.published[topic] = append(c.published[topic], &Message{
+		ID:         id,
+		Data:       data,
+		Attributes: attributes,
+		Ack: func() {
+			c.mu.Lock()
+			c.acked++
+			c.mu.Unlock()
+		},
+	})
+	return id, nil
+}
+
+func (c *fakeClient) Receive(ctx context.Context, subscription string, fn func(ctx context.Context, msg *Message)) error {
+	c.mu.Lock()
+	messages := c.published[c.topicOf(subscription)]
+	c.mu.Unlock()
+
+	for _, msg := range messages {
+		fn(ctx, msg)
+	}
+	c.receiving <- subscription
+	<-ctx.Done()
+	return nil
+}
+
+func (c *fakeClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestNewIngressClient(t *testing.T) {
+	t.Run("nil config", func(t *testing.T) {
+		if _, err := NewIngressClient(nil); err == nil {
+			t.Fatal("expected error for nil config")
+		}
+	})
+
+	t.Run("nil client", func(t *testing.T) {
+		if _, err := NewIngressClient(&Config{}); err == nil {
+			t.Fatal("expected error for nil client")
+		}
+	})
+}
+
+func TestIngressClient_Publish(t *testing.T) {
+	t.Run("assigns sequences per subject", func(t *testing.T) {
+		client := newFakeClient()
+		ingress, _ := NewIngressClient(&Config{Client: client, TopicPrefix: "mts-"})
+		ctx := context.Background()
+
+		ingress.Publish(ctx, &domain.PublishMessage{Subject: "orders", Data: []byte("a")})
+		result, err := ingress.Publish(ctx, &domain.PublishMessage{
+			Subject: "orders",
+			Data:    []byte("b"),
+			Headers: map[string]string{"tenant": "acme"},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.Sequence != 2 {
+			t.Errorf("expected sequence 2, got %d", result.Sequence)
+		}
+		if result.ObjectName != "mts-orders-2" {
+			t.Errorf("expected message ID as object name, got %s", result.ObjectName)
+		}
+
+		msg := client.published["mts-orders"][1]
+		if msg.Attributes["tenant"] != "acme" || msg.Attributes[HeaderSequence] != "2" {
+			t.Errorf("unexpected attributes: %v", msg.Attributes)
+		}
+	})
+
+	t.Run("empty subject", func(t *testing.T) {
+		ingress, _ := NewIngressClient(&Config{Client: newFakeClient()})
+		if _, err := ingress.Publish(context.Background(), &domain.PublishMessage{}); err == nil {
+			t.Fatal("expected error for empty subject")
+		}
+	})
+
+	t.Run("publish error", func(t *testing.T) {
+		client := newFakeClient()
+		client.publishErr = errors.New("permission denied")
+		ingress, _ := NewIngressClient(&Config{Client: client})
+
+		if _, err := ingress.Publish(context.Background(), &domain.PublishMessage{Subject: "orders"}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		client := newFakeClient()
+		ingress, _ := NewIngressClient(&Config{Client: client})
+		ingress.Close()
+		if !client.closed {
+			t.Error("expected client to be closed")
+		}
+	})
+}
@@ -0,0 +1,142 @@
+// Package redisstream mirrors MiniToolStream subjects into Redis Streams and
+// ingests Redis Stream entries into MiniToolStream.
+//
+// Forwarder and Source depend on the narrow Client interface; GoRedisClient
+// implements it with go-redis, and tests can substitute a fake.
+package redisstream
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Entry field names used for mirrored messages
+const (
+	FieldData     = "data"
+	FieldSubject  = "subject"
+	FieldSequence = "sequence"
+	// FieldHeaderPrefix prefixes fields carrying message headers
+	FieldHeaderPrefix = "h:"
+)
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// Entry is a Redis Stream entry
+type Entry struct {
+	ID     string
+	Values map[string]interface{}
+}
+
+// Client is the set of Redis Stream commands used by this package
+type Client interface {
+	// XAdd appends an entry, trimming the stream to about maxLen entries when maxLen > 0
+	XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error)
+	// XReadGroup reads up to count new entries for the consumer, blocking up to block
+	XReadGroup(ctx context.Context, group, consumer, stream string, count int64, block time.Duration) ([]Entry, error)
+	// XAck acknowledges processed entries
+	XAck(ctx context.Context, stream, group string, ids ...string) error
+}
+
+// Forwarder mirrors received messages into a Redis Stream
+type Forwarder struct {
+	client Client
+	stream string
+	maxLen int64
+	logger Logger
+}
+
+// ForwarderConfig represents configuration for Forwarder
+type ForwarderConfig struct {
+	Client Client
+	// Stream is the target stream; defaults to the message subject
+	Stream string
+	// MaxLen caps the stream length; zero keeps all entries
+	MaxLen int64
+	Logger Logger
+}
+
+// NewForwarder creates a new Redis Stream forwarder handler
+func NewForwarder(config *ForwarderConfig) (*Forwarder, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+
+	if config.MaxLen < 0 {
+		return nil, fmt.Errorf("max length cannot be negative")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	return &Forwarder{
+		client: config.Client,
+		stream: config.Stream,
+		maxLen: config.MaxLen,
+		logger: logger,
+	}, nil
+}
+
+// Handle appends the message to the stream with XADD
+func (f *Forwarder) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	stream := f.stream
+	if stream == "" {
+		stream = msg.Subject
+	}
+
+	values := map[string]interface{}{
+		FieldData:     msg.Data,
+		FieldSubject:  msg.Subject,
+		FieldSequence: strconv.FormatUint(msg.Sequence, 10),
+	}
+	for k, v := range msg.Headers {
+		values[FieldHeaderPrefix+k] = v
+	}
+
+	id, err := f.client.XAdd(ctx, stream, f.maxLen, values)
+	if err != nil {
+		return fmt.Errorf("failed to add to stream %q: %w", stream, err)
+	}
+
+	f.logger.Printf("[%s] Mirrored sequence %d to Redis stream %s (%s)", msg.Subject, msg.Sequence, stream, id)
+	return nil
+}
+
+// entryMessage converts entry fields to message data and headers
+func entryMessage(entry Entry) ([]byte, map[string]string) {
+	var data []byte
+	headers := make(map[string]string)
+
+	for k, v := range entry.Values {
+		switch {
+		case k == FieldData:
+			data = toBytes(v)
+		case strings.HasPrefix(k, FieldHeaderPrefix):
+			headers[strings.TrimPrefix(k, FieldHeaderPrefix)] = string(toBytes(v))
+		}
+	}
+	return data, headers
+}
+
+// toBytes converts a Redis field value to bytes
+func toBytes(v interface{}) []byte {
+	switch val := v.(type) {
+	case []byte:
+		return val
+	case string:
+		return []byte(val)
+	default:
+		return []byte(fmt.Sprint(val))
+	}
+}
@@ -0,0 +1,133 @@
+package redisstream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, format)
+}
+
+type mockClient struct {
+	mu      sync.Mutex
+	added   map[string][]map[string]interface{}
+	maxLen  int64
+	pending []Entry
+	acked   []string
+	addErr  error
+	readErr error
+}
+
+func (m *mockClient) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	if m.addErr != nil {
+		return "", m.addErr
+	}
+	if m.added == nil {
+		m.added = make(map[string][]map[string]interface{})
+	}
+	m.added[stream] = append(m.added[stream], values)
+	m.maxLen = maxLen
+	return "1-0", nil
+}
+
+func (m *mockClient) XReadGroup(ctx context.Context, group, consumer, stream string, count int64, block time.Duration) ([]Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.readErr != nil {
+		return nil, m.readErr
+	}
+	entries := m.pending
+	m.pending = nil
+	return entries, nil
+}
+
+func (m *mockClient) XAck(ctx context.Context, stream, group string, ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.acked = append(m.acked, ids...)
+	return nil
+}
+
+func TestNewForwarder(t *testing.T) {
+	t.Run("nil config", func(t *testing.T) {
+		if _, err := NewForwarder(nil); err == nil {
+			t.Fatal("expected error for nil config")
+		}
+	})
+
+	t.Run("nil client", func(t *testing.T) {
+		if _, err := NewForwarder(&ForwarderConfig{}); err == nil {
+			t.Fatal("expected error for nil client")
+		}
+	})
+
+	t.Run("negative max length", func(t *testing.T) {
+		if _, err := NewForwarder(&ForwarderConfig{Client: &mockClient{}, MaxLen: -1}); err == nil {
+			t.Fatal("expected error for negative max length")
+		}
+	})
+}
+
+func TestForwarder_Handle(t *testing.T) {
+	msg := &domain.ReceivedMessage{
+		Subject:  "orders",
+		Sequence: 5,
+		Data:     []byte("payload"),
+		Headers:  map[string]string{"tenant": "acme"},
+	}
+
+	t.Run("stream defaults to subject", func(t *testing.T) {
+		client := &mockClient{}
+		f, _ := NewForwarder(&ForwarderConfig{Client: client, MaxLen: 1000, Logger: &testLogger{}})
+
+		if err := f.Handle(context.Background(), msg); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		entries := client.added["orders"]
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 entry, got %d", len(entries))
+		}
+		if string(entries[0][FieldData].([]byte)) != "payload" {
+			t.Errorf("unexpected data: %v", entries[0][FieldData])
+		}
+		if entries[0][FieldSequence] != "5" {
+			t.Errorf("expected sequence '5', got %v", entries[0][FieldSequence])
+		}
+		if entries[0]["h:tenant"] != "acme" {
+			t.Errorf("expected tenant header field, got %v", entries[0])
+		}
+		if client.maxLen != 1000 {
+			t.Errorf("expected max length 1000, got %d", client.maxLen)
+		}
+	})
+
+	t.Run("fixed stream", func(t *testing.T) {
+		client := &mockClient{}
+		f, _ := NewForwarder(&ForwarderConfig{Client: client, Stream: "mirror", Logger: &testLogger{}})
+
+		f.Handle(context.Background(), msg)
+
+		if len(client.added["mirror"]) != 1 {
+			t.Error("expected entry in fixed stream")
+		}
+	})
+
+	t.Run("add error", func(t *testing.T) {
+		f, _ := NewForwarder(&ForwarderConfig{Client: &mockClient{addErr: errors.New("OOM")}, Logger: &testLogger{}})
+
+		if err := f.Handle(context.Background(), msg); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
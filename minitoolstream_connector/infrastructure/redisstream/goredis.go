@@ -0,0 +1,93 @@
+package redisstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GoRedisClient implements Client with go-redis. XReadGroup creates a
+// missing stream and consumer group, reading the group from the start of the
+// stream.
+type GoRedisClient struct {
+	client redis.UniversalClient
+}
+
+// NewGoRedisClient adapts a go-redis client, e.g. *redis.Client or
+// *redis.ClusterClient
+func NewGoRedisClient(client redis.UniversalClient) *GoRedisClient {
+	return &GoRedisClient{client: client}
+}
+
+// XAdd appends an entry, trimming the stream to about maxLen entries when maxLen > 0
+func (c *GoRedisClient) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	return c.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: maxLen,
+		Approx: maxLen > 0,
+		Values: values,
+	}).Result()
+}
+
+// XReadGroup reads up to count new entries for the consumer, blocking up to
+// block. It returns no entries when the block times out.
+func (c *GoRedisClient) XReadGroup(ctx context.Context, group, consumer, stream string, count int64, block time.Duration) ([]Entry, error) {
+	args := &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{stream, ">"},
+		Count:    count,
+		Block:    block,
+	}
+
+	streams, err := c.client.XReadGroup(ctx, args).Result()
+	if isNoGroup(err) {
+		if err := c.createGroup(ctx, stream, group); err != nil {
+			return nil, err
+		}
+		streams, err = c.client.XReadGroup(ctx, args).Result()
+	}
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, s := range streams {
+		for _, msg := range s.Messages {
+			entries = append(entries, Entry{ID: msg.ID, Values: msg.Values})
+		}
+	}
+	return entries, nil
+}
+
+// XAck acknowledges processed entries
+func (c *GoRedisClient) XAck(ctx context.Context, stream, group string, ids ...string) error {
+	return c.client.XAck(ctx, stream, group, ids...).Err()
+}
+
+// Close closes the go-redis client
+func (c *GoRedisClient) Close() error {
+	return c.client.Close()
+}
+
+// createGroup creates the consumer group and the stream if needed; a group
+// created concurrently by another consumer is not an error
+func (c *GoRedisClient) createGroup(ctx context.Context, stream, group string) error {
+	err := c.client.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %q: %w", group, err)
+	}
+	return nil
+}
+
+// isNoGroup reports whether err is Redis' missing stream or group error
+func isNoGroup(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "NOGROUP")
+}
@@ -0,0 +1,108 @@
+package redisstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func newGoRedisClient(t *testing.T) (*GoRedisClient, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := NewGoRedisClient(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+func TestGoRedisClient_ForwardAndRead(t *testing.T) {
+	client, server := newGoRedisClient(t)
+	ctx := context.Background()
+
+	forwarder, err := NewForwarder(&ForwarderConfig{Client: client, Stream: "mirror", MaxLen: 100, Logger: &testLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seq := uint64(1); seq <= 2; seq++ {
+		msg := &domain.ReceivedMessage{
+			Subject:  "orders",
+			Sequence: seq,
+			Data:     []byte("payload"),
+			Headers:  map[string]string{"content-type": "text/plain"},
+		}
+		if err := forwarder.Handle(ctx, msg); err != nil {
+			t.Fatalf("Handle failed: %v", err)
+		}
+	}
+
+	entries, err := server.Stream("mirror")
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 entries on the server, got %d (%v)", len(entries), err)
+	}
+
+	// The group does not exist yet and is created from the start of the stream
+	read, err := client.XReadGroup(ctx, "workers", "w1", "mirror", 10, 0)
+	if err != nil {
+		t.Fatalf("XReadGroup failed: %v", err)
+	}
+	if len(read) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(read))
+	}
+	data, headers := entryMessage(read[0])
+	if string(data) != "payload" || headers["content-type"] != "text/plain" {
+		t.Errorf("unexpected entry %q %v", data, headers)
+	}
+
+	if err := client.XAck(ctx, "mirror", "workers", read[0].ID, read[1].ID); err != nil {
+		t.Fatalf("XAck failed: %v", err)
+	}
+	pending, err := client.client.XPending(ctx, "mirror", "workers").Result()
+	if err != nil || pending.Count != 0 {
+		t.Errorf("expected no pending entries, got %+v (%v)", pending, err)
+	}
+
+	// Nothing new: the block times out without an error
+	read, err = client.XReadGroup(ctx, "workers", "w1", "mirror", 10, 10*time.Millisecond)
+	if err != nil || len(read) != 0 {
+		t.Errorf("expected no entries after the timeout, got %d (%v)", len(read), err)
+	}
+}
+
+func TestGoRedisClient_Source(t *testing.T) {
+	client, _ := newGoRedisClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.XAdd(ctx, "events", 0, map[string]interface{}{FieldData: "hello", FieldHeaderPrefix + "kind": "greeting"}); err != nil {
+		t.Fatalf("XAdd failed: %v", err)
+	}
+
+	pub := &mockPublisher{}
+	source, err := NewSource(&SourceConfig{
+		Client:    client,
+		Stream:    "events",
+		Group:     "ingest",
+		Consumer:  "c1",
+		Subject:   "events",
+		Block:     10 * time.Millisecond,
+		Publisher: pub,
+		Logger:    &testLogger{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run reads until the deadline, creating the group on the first read
+	if err := source.Run(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the deadline to stop Run, got %v", err)
+	}
+
+	msgs := pub.published
+	if len(msgs) != 1 || string(msgs[0].Data) != "hello" || msgs[0].Headers["kind"] != "greeting" {
+		t.Fatalf("unexpected published messages %+v", msgs)
+	}
+}
@@ -0,0 +1,136 @@
+package redisstream
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Source reads a Redis Stream through a consumer group and publishes each entry
+// to a subject. Entries are acknowledged after a successful publish; failed
+// entries stay pending in the group.
+type Source struct {
+	client    Client
+	stream    string
+	group     string
+	consumer  string
+	subject   string
+	batchSize int64
+	block     time.Duration
+	publisher domain.Publisher
+	logger    Logger
+}
+
+// SourceConfig represents configuration for Source
+type SourceConfig struct {
+	Client   Client
+	Stream   string
+	Group    string
+	Consumer string
+	Subject  string
+	// BatchSize is the number of entries read per call (default 10)
+	BatchSize int64
+	// Block is how long a read waits for new entries (default 5s)
+	Block     time.Duration
+	Publisher domain.Publisher
+	Logger    Logger
+}
+
+// NewSource creates a new Redis Stream source
+func NewSource(config *SourceConfig) (*Source, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+
+	if config.Publisher == nil {
+		return nil, fmt.Errorf("publisher cannot be nil")
+	}
+
+	if config.Stream == "" || config.Group == "" || config.Consumer == "" {
+		return nil, fmt.Errorf("stream, group and consumer are required")
+	}
+
+	if config.Subject == "" {
+		return nil, fmt.Errorf("subject is required")
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 10
+	}
+
+	block := config.Block
+	if block <= 0 {
+		block = 5 * time.Second
+	}
+
+	return &Source{
+		client:    config.Client,
+		stream:    config.Stream,
+		group:     config.Group,
+		consumer:  config.Consumer,
+		subject:   config.Subject,
+		batchSize: batchSize,
+		block:     block,
+		publisher: config.Publisher,
+		logger:    logger,
+	}, nil
+}
+
+// Run reads the stream until ctx is cancelled or a read fails
+func (s *Source) Run(ctx context.Context) error {
+	s.logger.Printf("✓ Reading Redis stream %s (group %s) into subject %s", s.stream, s.group, s.subject)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entries, err := s.client.XReadGroup(ctx, s.group, s.consumer, s.stream, s.batchSize, s.block)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to read stream %q: %w", s.stream, err)
+		}
+
+		for _, entry := range entries {
+			s.forward(ctx, entry)
+		}
+	}
+}
+
+// Preparer returns a message preparer for a single entry
+func (s *Source) Preparer(entry Entry) domain.MessagePreparer {
+	return domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		data, headers := entryMessage(entry)
+		return &domain.PublishMessage{
+			Subject: s.subject,
+			Data:    data,
+			Headers: headers,
+		}, nil
+	})
+}
+
+// forward publishes an entry and acknowledges it on success
+func (s *Source) forward(ctx context.Context, entry Entry) {
+	if err := s.publisher.Publish(ctx, s.Preparer(entry)); err != nil {
+		s.logger.Printf("✗ Failed to publish Redis entry %s: %v", entry.ID, err)
+		return
+	}
+
+	if err := s.client.XAck(ctx, s.stream, s.group, entry.ID); err != nil {
+		s.logger.Printf("✗ Failed to ack Redis entry %s: %v", entry.ID, err)
+	}
+}
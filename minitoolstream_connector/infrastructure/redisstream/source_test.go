@@ -0,0 +1,162 @@
+package redisstream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type mockPublisher struct {
+	published []*domain.PublishMessage
+	failData  string
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, preparer domain.MessagePreparer) error {
+	msg, err := preparer.Prepare(ctx)
+	if err != nil {
+		return err
+	}
+	if string(msg.Data) == m.failData {
+		return errors.New("unavailable")
+	}
+	m.published = append(m.published, msg)
+	return nil
+}
+
+func (m *mockPublisher) PublishAll(ctx context.Context, preparers []domain.MessagePreparer) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishAllResults(ctx context.Context, preparers []domain.MessagePreparer) ([]domain.PublishOutcome, error) {
+	return nil, nil
+}
+
+func (m *mockPublisher) PublishGroup(ctx context.Context, preparers []domain.MessagePreparer) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishFanOut(ctx context.Context, subjects []string, data []byte, headers map[string]string) ([]domain.FanOutResult, error) {
+	return nil, nil
+}
+
+func (m *mockPublisher) PublishEvery(ctx context.Context, preparer domain.MessagePreparer, interval time.Duration) error {
+	return nil
+}
+
+func (m *mockPublisher) PublishSchedule(ctx context.Context, preparer domain.MessagePreparer, schedule domain.Schedule) error {
+	return nil
+}
+func (m *mockPublisher) RegisterHandler(preparer domain.MessagePreparer)     {}
+func (m *mockPublisher) RegisterHandlers(preparers []domain.MessagePreparer) {}
+func (m *mockPublisher) RegisterPreparerFunc(fn func(ctx context.Context) (*domain.PublishMessage, error)) {
+}
+func (m *mockPublisher) SetResultHandler(handler domain.ResultHandler) {}
+func (m *mockPublisher) Close() error                                  { return nil }
+
+func TestNewSource(t *testing.T) {
+	t.Run("nil config", func(t *testing.T) {
+		if _, err := NewSource(nil); err == nil {
+			t.Fatal("expected error for nil config")
+		}
+	})
+
+	t.Run("missing group", func(t *testing.T) {
+		_, err := NewSource(&SourceConfig{
+			Client:    &mockClient{},
+			Publisher: &mockPublisher{},
+			Stream:    "buffer",
+			Consumer:  "c1",
+			Subject:   "orders",
+		})
+		if err == nil {
+			t.Fatal("expected error for missing group")
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		s, err := NewSource(&SourceConfig{
+			Client:    &mockClient{},
+			Publisher: &mockPublisher{},
+			Stream:    "buffer",
+			Group:     "g",
+			Consumer:  "c1",
+			Subject:   "orders",
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if s.batchSize != 10 || s.block != 5*time.Second {
+			t.Errorf("unexpected defaults: batch=%d block=%v", s.batchSize, s.block)
+		}
+	})
+}
+
+func TestSource_Run(t *testing.T) {
+	t.Run("publishes and acks entries", func(t *testing.T) {
+		client := &mockClient{
+			pending: []Entry{
+				{ID: "1-0", Values: map[string]interface{}{FieldData: "first", "h:tenant": "acme", FieldSequence: "9"}},
+				{ID: "2-0", Values: map[string]interface{}{FieldData: "bad"}},
+			},
+		}
+		publisher := &mockPublisher{failData: "bad"}
+		source, _ := NewSource(&SourceConfig{
+			Client:    client,
+			Publisher: publisher,
+			Stream:    "buffer",
+			Group:     "g",
+			Consumer:  "c1",
+			Subject:   "orders",
+			Logger:    &testLogger{},
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- source.Run(ctx) }()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			client.mu.Lock()
+			n := len(client.acked)
+			client.mu.Unlock()
+			if n > 0 || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		if err := <-done; err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+
+		if len(publisher.published) != 1 {
+			t.Fatalf("expected 1 published message, got %d", len(publisher.published))
+		}
+		msg := publisher.published[0]
+		if msg.Subject != "orders" || string(msg.Data) != "first" || msg.Headers["tenant"] != "acme" {
+			t.Errorf("unexpected message: %+v", msg)
+		}
+		if len(client.acked) != 1 || client.acked[0] != "1-0" {
+			t.Errorf("expected only 1-0 to be acked, got %v", client.acked)
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		source, _ := NewSource(&SourceConfig{
+			Client:    &mockClient{readErr: errors.New("NOGROUP")},
+			Publisher: &mockPublisher{},
+			Stream:    "buffer",
+			Group:     "g",
+			Consumer:  "c1",
+			Subject:   "orders",
+			Logger:    &testLogger{},
+		})
+
+		if err := source.Run(context.Background()); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -0,0 +1,367 @@
+// Package loadtest drives publish and subscribe workloads against a
+// MiniToolStream server through the library's own publisher and subscriber,
+// and reports throughput, latency percentiles and error rates.
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	publisherUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+	subscriberUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/subscriber"
+)
+
+// Headers attached to load test messages
+const (
+	HeaderRunID  = "loadtest-run"
+	HeaderSentAt = "loadtest-sent-at"
+)
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// discardLogger silences the publisher and subscriber, which log every message
+type discardLogger struct{}
+
+func (discardLogger) Printf(format string, v ...interface{}) {}
+
+// Config represents load test configuration
+type Config struct {
+	// Ingress is the client messages are published with
+	Ingress domain.IngressClient
+	// Egress, when set, subscribes to the subject and measures end-to-end latency
+	Egress domain.EgressClient
+
+	Subject string
+	// Messages is the number of messages to publish (default 1000 unless
+	// Duration is set)
+	Messages int
+	// Duration bounds the publish phase
+	Duration time.Duration
+	// Rate limits publishes per second across all workers (0 is unlimited)
+	Rate float64
+	// Concurrency is the number of publishing workers (default 1)
+	Concurrency int
+	// PayloadSize is the size of each message in bytes (default 1024)
+	PayloadSize int
+	// BatchSize is the subscriber fetch batch size (default 100)
+	BatchSize int32
+	// DrainTimeout is how long to wait for the subscriber to receive all
+	// published messages (default 10s)
+	DrainTimeout time.Duration
+	// ProgressInterval logs progress periodically (0 disables)
+	ProgressInterval time.Duration
+	Logger           Logger
+}
+
+// Runner runs a load test
+type Runner struct {
+	ingress          domain.IngressClient
+	egress           domain.EgressClient
+	subject          string
+	messages         int
+	duration         time.Duration
+	rate             float64
+	concurrency      int
+	payloadSize      int
+	batchSize        int32
+	drainTimeout     time.Duration
+	progressInterval time.Duration
+	logger           Logger
+}
+
+// New creates a new load test runner
+func New(config *Config) (*Runner, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Ingress == nil {
+		return nil, fmt.Errorf("ingress client cannot be nil")
+	}
+
+	if config.Subject == "" {
+		return nil, fmt.Errorf("subject is required")
+	}
+
+	if config.Messages < 0 || config.Duration < 0 || config.Rate < 0 {
+		return nil, fmt.Errorf("messages, duration and rate cannot be negative")
+	}
+
+	r := &Runner{
+		ingress:          config.Ingress,
+		egress:           config.Egress,
+		subject:          config.Subject,
+		messages:         config.Messages,
+		duration:         config.Duration,
+		rate:             config.Rate,
+		concurrency:      config.Concurrency,
+		payloadSize:      config.PayloadSize,
+		batchSize:        config.BatchSize,
+		drainTimeout:     config.DrainTimeout,
+		progressInterval: config.ProgressInterval,
+		logger:           config.Logger,
+	}
+
+	if r.messages == 0 && r.duration == 0 {
+		r.messages = 1000
+	}
+	if r.concurrency <= 0 {
+		r.concurrency = 1
+	}
+	if r.payloadSize <= 0 {
+		r.payloadSize = 1024
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = 100
+	}
+	if r.drainTimeout <= 0 {
+		r.drainTimeout = 10 * time.Second
+	}
+	if r.logger == nil {
+		r.logger = domain.DefaultLogger{}
+	}
+
+	return r, nil
+}
+
+// run holds the state of one load test run
+type run struct {
+	id        string
+	published atomic.Int64
+	failed    atomic.Int64
+	received  atomic.Int64
+	bytes     atomic.Int64
+
+	publishLatency *recorder
+	endToEnd       *recorder
+}
+
+// Run publishes the workload and, with an egress client, receives it. The
+// clients are not closed. Cancelling ctx ends the run early with a report of
+// what was done so far.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	state := &run{
+		id:             strconv.FormatInt(time.Now().UnixNano(), 36),
+		publishLatency: newRecorder(),
+		endToEnd:       newRecorder(),
+	}
+
+	pub, err := publisherUsecase.New(&publisherUsecase.Config{
+		Client: nopCloseIngress{r.ingress},
+		Logger: discardLogger{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create publisher: %w", err)
+	}
+
+	var sub *subscriberUsecase.MultiSubject
+	if r.egress != nil {
+		if sub, err = r.subscribe(ctx, state); err != nil {
+			return nil, err
+		}
+		defer sub.Stop()
+	}
+
+	r.logger.Printf("Load test %s: subject=%s messages=%d duration=%v rate=%v concurrency=%d size=%d",
+		state.id, r.subject, r.messages, r.duration, r.rate, r.concurrency, r.payloadSize)
+
+	start := time.Now()
+	stopProgress := r.reportProgress(state, start)
+	r.publish(ctx, pub, state)
+	publishElapsed := time.Since(start)
+
+	if sub != nil {
+		r.drain(ctx, state)
+	}
+	stopProgress()
+
+	report := &Report{
+		Published:       state.published.Load(),
+		PublishErrors:   state.failed.Load(),
+		Received:        state.received.Load(),
+		Bytes:           state.bytes.Load(),
+		Duration:        publishElapsed,
+		PublishLatency:  state.publishLatency.summary(),
+		EndToEndLatency: state.endToEnd.summary(),
+		Subscribed:      sub != nil,
+	}
+	r.logger.Printf("Load test %s finished: %d published, %d failed, %d received in %v",
+		state.id, report.Published, report.PublishErrors, report.Received, publishElapsed.Round(time.Millisecond))
+	return report, nil
+}
+
+// subscribe starts a subscriber that counts the messages of this run
+func (r *Runner) subscribe(ctx context.Context, state *run) (*subscriberUsecase.MultiSubject, error) {
+	// Resolve the start before publishing; StartLatest would be evaluated
+	// when the subscription is established and could skip the first messages
+	last, err := r.egress.GetLastSequence(ctx, r.subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last sequence: %w", err)
+	}
+
+	sub, err := subscriberUsecase.New(&subscriberUsecase.Config{
+		Client:        nopCloseEgress{r.egress},
+		DurableName:   "loadtest-" + state.id,
+		BatchSize:     r.batchSize,
+		StartPosition: domain.FromSequence(last + 1),
+		Logger:        discardLogger{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscriber: %w", err)
+	}
+
+	sub.RegisterHandlerFunc(r.subject, func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		if msg.Headers[HeaderRunID] != state.id {
+			return nil
+		}
+		if sentAt, err := strconv.ParseInt(msg.Headers[HeaderSentAt], 10, 64); err == nil {
+			state.endToEnd.record(time.Since(time.Unix(0, sentAt)))
+		}
+		state.received.Add(1)
+		return nil
+	})
+
+	if err := sub.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to start subscriber: %w", err)
+	}
+	return sub, nil
+}
+
+// publish runs the publishing workers until the message count or duration
+// is reached
+func (r *Runner) publish(ctx context.Context, pub domain.Publisher, state *run) {
+	if r.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.duration)
+		defer cancel()
+	}
+
+	tokens := make(chan struct{})
+	go func() {
+		defer close(tokens)
+		var ticker *time.Ticker
+		if r.rate > 0 {
+			ticker = time.NewTicker(time.Duration(float64(time.Second) / r.rate))
+			defer ticker.Stop()
+		}
+		for i := 0; r.messages == 0 || i < r.messages; i++ {
+			if ticker != nil {
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case tokens <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < r.concurrency; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed))
+			payload := make([]byte, r.payloadSize)
+
+			for range tokens {
+				rnd.Read(payload)
+				data := append([]byte(nil), payload...)
+				sent := time.Now()
+
+				err := pub.Publish(ctx, domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+					return &domain.PublishMessage{
+						Subject: r.subject,
+						Data:    data,
+						Headers: map[string]string{
+							"content-type": "application/octet-stream",
+							HeaderRunID:    state.id,
+							HeaderSentAt:   strconv.FormatInt(sent.UnixNano(), 10),
+						},
+					}, nil
+				}))
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					state.failed.Add(1)
+					continue
+				}
+				state.publishLatency.record(time.Since(sent))
+				state.published.Add(1)
+				state.bytes.Add(int64(len(data)))
+			}
+		}(time.Now().UnixNano() + int64(w))
+	}
+	wg.Wait()
+}
+
+// drain waits until every published message was received
+func (r *Runner) drain(ctx context.Context, state *run) {
+	timeout := time.NewTimer(r.drainTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for state.received.Load() < state.published.Load() {
+		select {
+		case <-ticker.C:
+		case <-timeout.C:
+			r.logger.Printf("Load test %s: received %d of %d messages after %v",
+				state.id, state.received.Load(), state.published.Load(), r.drainTimeout)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reportProgress logs progress every ProgressInterval until stopped
+func (r *Runner) reportProgress(state *run, start time.Time) func() {
+	if r.progressInterval <= 0 {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(r.progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				elapsed := time.Since(start)
+				published := state.published.Load()
+				r.logger.Printf("   %v: %d published (%.0f msg/s), %d failed, %d received",
+					elapsed.Round(time.Second), published, float64(published)/elapsed.Seconds(), state.failed.Load(), state.received.Load())
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// nopCloseIngress keeps the publisher from closing the caller's client
+type nopCloseIngress struct {
+	domain.IngressClient
+}
+
+func (nopCloseIngress) Close() error { return nil }
+
+// nopCloseEgress keeps the subscriber from closing the caller's client
+type nopCloseEgress struct {
+	domain.EgressClient
+}
+
+func (nopCloseEgress) Close() error { return nil }
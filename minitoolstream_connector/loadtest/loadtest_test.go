@@ -0,0 +1,136 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
+)
+
+type testLogger struct{}
+
+func (testLogger) Printf(format string, v ...interface{}) {}
+
+func TestNew(t *testing.T) {
+	ingress := connectortest.NewRecordingIngressClient()
+	tests := []struct {
+		name   string
+		config *Config
+	}{
+		{"nil config", nil},
+		{"missing ingress", &Config{Subject: "s"}},
+		{"missing subject", &Config{Ingress: ingress}},
+		{"negative rate", &Config{Ingress: ingress, Subject: "s", Rate: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.config); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	r, err := New(&Config{Ingress: ingress, Subject: "s"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if r.messages != 1000 || r.concurrency != 1 || r.payloadSize != 1024 {
+		t.Errorf("unexpected defaults %+v", r)
+	}
+}
+
+func TestRunner_PublishOnly(t *testing.T) {
+	ingress := connectortest.NewRecordingIngressClient()
+	ingress.FailNext(errors.New("unavailable"), errors.New("unavailable"))
+
+	r, _ := New(&Config{
+		Ingress:     ingress,
+		Subject:     "bench",
+		Messages:    50,
+		Concurrency: 4,
+		PayloadSize: 64,
+		Logger:      testLogger{},
+	})
+
+	report, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Published != 48 || report.PublishErrors != 2 || report.Bytes != 48*64 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if report.PublishLatency.Count != 48 || report.Subscribed {
+		t.Errorf("unexpected latency summary %+v", report.PublishLatency)
+	}
+	if ingress.Closed() {
+		t.Error("runner must not close the caller's client")
+	}
+
+	msg := ingress.Last()
+	if msg.Headers[HeaderRunID] == "" || msg.Headers[HeaderSentAt] == "" || len(msg.Data) != 64 {
+		t.Errorf("unexpected message %+v", msg)
+	}
+}
+
+func TestRunner_RateAndDuration(t *testing.T) {
+	ingress := connectortest.NewRecordingIngressClient()
+	r, _ := New(&Config{
+		Ingress:  ingress,
+		Subject:  "bench",
+		Duration: 200 * time.Millisecond,
+		Rate:     50,
+		Logger:   testLogger{},
+	})
+
+	report, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	// 50 msg/s for 200ms is about 10 messages
+	if report.Published < 5 || report.Published > 12 {
+		t.Errorf("expected about 10 messages, got %d", report.Published)
+	}
+}
+
+func TestRunner_EndToEnd(t *testing.T) {
+	srv := connectortest.StartServer(t)
+
+	ingress, err := grpcClient.NewIngressClient(srv.Address(), srv.DialOptions()...)
+	if err != nil {
+		t.Fatalf("NewIngressClient failed: %v", err)
+	}
+	defer ingress.Close()
+	egress, err := grpcClient.NewEgressClient(srv.Address(), srv.DialOptions()...)
+	if err != nil {
+		t.Fatalf("NewEgressClient failed: %v", err)
+	}
+	defer egress.Close()
+
+	// Messages from before the run are not counted
+	ingress.Publish(context.Background(), &domain.PublishMessage{Subject: "bench", Data: []byte("old")})
+
+	r, _ := New(&Config{
+		Ingress:     ingress,
+		Egress:      egress,
+		Subject:     "bench",
+		Messages:    200,
+		Concurrency: 8,
+		PayloadSize: 256,
+		Logger:      testLogger{},
+	})
+
+	report, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Published != 200 || report.Received != 200 || report.LossRate() != 0 {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+	if report.EndToEndLatency.Count != 200 || report.EndToEndLatency.P99 <= 0 {
+		t.Errorf("unexpected end-to-end latency %+v", report.EndToEndLatency)
+	}
+}
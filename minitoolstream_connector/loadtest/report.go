@@ -0,0 +1,142 @@
+package loadtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report is the result of a load test run
+type Report struct {
+	Published     int64
+	PublishErrors int64
+	Received      int64
+	Bytes         int64
+	// Duration is the length of the publish phase
+	Duration        time.Duration
+	PublishLatency  LatencySummary
+	EndToEndLatency LatencySummary
+	// Subscribed reports whether messages were received, i.e. whether
+	// Received and EndToEndLatency are meaningful
+	Subscribed bool
+}
+
+// Throughput returns the successful publishes per second
+func (r *Report) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Published) / r.Duration.Seconds()
+}
+
+// ErrorRate returns the fraction of publish attempts that failed
+func (r *Report) ErrorRate() float64 {
+	attempts := r.Published + r.PublishErrors
+	if attempts == 0 {
+		return 0
+	}
+	return float64(r.PublishErrors) / float64(attempts)
+}
+
+// LossRate returns the fraction of published messages that were not received
+func (r *Report) LossRate() float64 {
+	if !r.Subscribed || r.Published == 0 || r.Received >= r.Published {
+		return 0
+	}
+	return float64(r.Published-r.Received) / float64(r.Published)
+}
+
+// String formats the report for terminals
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Published:   %d messages, %d bytes in %v\n", r.Published, r.Bytes, r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "Throughput:  %.1f msg/s, %.2f MiB/s\n", r.Throughput(), float64(r.Bytes)/(1<<20)/max(r.Duration.Seconds(), 1e-9))
+	fmt.Fprintf(&b, "Errors:      %d (%.2f%%)\n", r.PublishErrors, r.ErrorRate()*100)
+	fmt.Fprintf(&b, "Publish:     %s\n", r.PublishLatency)
+	if r.Subscribed {
+		fmt.Fprintf(&b, "Received:    %d messages (%.2f%% lost)\n", r.Received, r.LossRate()*100)
+		fmt.Fprintf(&b, "End-to-end:  %s\n", r.EndToEndLatency)
+	}
+	return b.String()
+}
+
+// LatencySummary summarizes recorded latencies
+type LatencySummary struct {
+	Count int
+	Min   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// String formats the summary on one line
+func (s LatencySummary) String() string {
+	if s.Count == 0 {
+		return "no samples"
+	}
+	return fmt.Sprintf("min=%v mean=%v p50=%v p90=%v p99=%v max=%v",
+		round(s.Min), round(s.Mean), round(s.P50), round(s.P90), round(s.P99), round(s.Max))
+}
+
+// round keeps latencies readable
+func round(d time.Duration) time.Duration {
+	if d >= time.Millisecond {
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
+
+// recorder collects latency samples
+type recorder struct {
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+func newRecorder() *recorder {
+	return &recorder{}
+}
+
+func (r *recorder) record(d time.Duration) {
+	r.mu.Lock()
+	r.samples = append(r.samples, d)
+	r.mu.Unlock()
+}
+
+// summary computes the summary of the recorded samples
+func (r *recorder) summary() LatencySummary {
+	r.mu.Lock()
+	samples := append([]time.Duration(nil), r.samples...)
+	r.mu.Unlock()
+
+	if len(samples) == 0 {
+		return LatencySummary{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	var total time.Duration
+	for _, d := range samples {
+		total += d
+	}
+	return LatencySummary{
+		Count: len(samples),
+		Min:   samples[0],
+		Mean:  total / time.Duration(len(samples)),
+		P50:   percentile(samples, 50),
+		P90:   percentile(samples, 90),
+		P99:   percentile(samples, 99),
+		Max:   samples[len(samples)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted samples
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
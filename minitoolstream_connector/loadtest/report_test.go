@@ -0,0 +1,76 @@
+package loadtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecorder_Summary(t *testing.T) {
+	r := newRecorder()
+	if s := r.summary(); s.Count != 0 || s.String() != "no samples" {
+		t.Errorf("unexpected empty summary %+v", s)
+	}
+
+	// 100..1 ms in reverse order
+	for i := 100; i >= 1; i-- {
+		r.record(time.Duration(i) * time.Millisecond)
+	}
+	s := r.summary()
+
+	want := LatencySummary{
+		Count: 100,
+		Min:   time.Millisecond,
+		Mean:  50500 * time.Microsecond,
+		P50:   50 * time.Millisecond,
+		P90:   90 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+		Max:   100 * time.Millisecond,
+	}
+	if s != want {
+		t.Errorf("expected %+v, got %+v", want, s)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	samples := []time.Duration{1, 2, 3}
+	tests := map[int]time.Duration{0: 1, 33: 1, 34: 2, 50: 2, 99: 3, 100: 3}
+	for p, want := range tests {
+		if got := percentile(samples, p); got != want {
+			t.Errorf("p%d: expected %v, got %v", p, want, got)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	r := &Report{
+		Published:     90,
+		PublishErrors: 10,
+		Received:      81,
+		Bytes:         90 << 10,
+		Duration:      2 * time.Second,
+		Subscribed:    true,
+	}
+
+	if r.Throughput() != 45 {
+		t.Errorf("expected 45 msg/s, got %v", r.Throughput())
+	}
+	if r.ErrorRate() != 0.1 {
+		t.Errorf("expected 10%% errors, got %v", r.ErrorRate())
+	}
+	if r.LossRate() != 0.1 {
+		t.Errorf("expected 10%% loss, got %v", r.LossRate())
+	}
+
+	out := r.String()
+	for _, line := range []string{"Throughput:  45.0 msg/s", "Errors:      10 (10.00%)", "Received:    81 messages (10.00% lost)"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("expected %q in report:\n%s", line, out)
+		}
+	}
+
+	r.Subscribed = false
+	if strings.Contains(r.String(), "Received") || r.LossRate() != 0 {
+		t.Error("expected no receive statistics without a subscriber")
+	}
+}
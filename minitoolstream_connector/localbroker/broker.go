@@ -0,0 +1,439 @@
+// Package localbroker implements domain.IngressClient and domain.EgressClient on top
+// of a local directory, for offline development and CI runs without a MiniToolStream
+// server.
+//
+// Each subject is an append-only file of JSON records with sequences starting at 1.
+// Durable consumers store their next sequence next to the subject file. A Broker
+// assumes it is the only writer of its directory; other processes may read it.
+package localbroker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+const (
+	messagesFile = "messages.log"
+	consumersDir = "consumers"
+)
+
+// Config represents local broker configuration
+type Config struct {
+	// Dir is the directory holding subject files; it is created if missing
+	Dir string
+	// PollInterval is how often subscriptions check for new messages (default 200ms)
+	PollInterval time.Duration
+	// Clock stamps messages and paces polling (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// Broker is a file-based implementation of IngressClient and EgressClient
+type Broker struct {
+	dir          string
+	pollInterval time.Duration
+	clock        domain.Clock
+
+	mu   sync.Mutex
+	last map[string]uint64
+}
+
+// record is the on-disk form of a message
+type record struct {
+	Sequence  uint64            `json:"sequence"`
+	Data      []byte            `json:"data"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// New creates a new local broker
+func New(config *Config) (*Broker, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Dir == "" {
+		return nil, fmt.Errorf("directory is required")
+	}
+
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create broker directory: %w", err)
+	}
+
+	pollInterval := config.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = 200 * time.Millisecond
+	}
+
+	return &Broker{
+		dir:          config.Dir,
+		pollInterval: pollInterval,
+		clock:        domain.ClockOrSystem(config.Clock),
+		last:         make(map[string]uint64),
+	}, nil
+}
+
+// Publish appends a message to the subject file
+func (b *Broker) Publish(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+
+	if msg.Subject == "" {
+		return nil, fmt.Errorf("subject cannot be empty")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	last, err := b.lastSequence(msg.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &record{
+		Sequence:  last + 1,
+		Data:      msg.Data,
+		Headers:   msg.WireHeaders(),
+		Timestamp: b.clock.Now().UTC(),
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	if err := os.MkdirAll(b.subjectDir(msg.Subject), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create subject directory: %w", err)
+	}
+
+	f, err := os.OpenFile(b.messagesPath(msg.Subject), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open subject file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to append message: %w", err)
+	}
+
+	b.last[msg.Subject] = rec.Sequence
+
+	return &domain.PublishResult{
+		Sequence:   rec.Sequence,
+		ObjectName: fmt.Sprintf("%s-%d", msg.Subject, rec.Sequence),
+	}, nil
+}
+
+// Subscribe positions the durable consumer and notifies when messages are pending.
+// Without a start option a new durable starts from the first message and an
+// existing durable resumes where it stopped.
+func (b *Broker) Subscribe(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+	if config == nil {
+		return nil, fmt.Errorf("subscription config cannot be nil")
+	}
+
+	if config.Subject == "" {
+		return nil, fmt.Errorf("subject cannot be empty")
+	}
+
+	next, err := b.startSequence(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if next != nil {
+		if err := b.saveNext(config, *next); err != nil {
+			return nil, err
+		}
+	}
+
+	return &notificationStream{ctx: ctx, broker: b, config: config}, nil
+}
+
+// startSequence resolves the subscription start options, returning nil to resume
+func (b *Broker) startSequence(ctx context.Context, config *domain.SubscriptionConfig) (*uint64, error) {
+	var seq uint64
+	switch {
+	case config.StartSequence != nil:
+		seq = *config.StartSequence
+	case config.StartPosition.Kind == domain.StartDefault:
+		return nil, nil
+	case config.StartPosition.Kind == domain.StartSequence:
+		seq = config.StartPosition.Sequence
+	case config.StartPosition.Kind == domain.StartLatest:
+		last, err := b.GetLastSequence(ctx, config.Subject)
+		if err != nil {
+			return nil, err
+		}
+		seq = last + 1
+	default:
+		seq = 1
+	}
+
+	if seq == 0 {
+		seq = 1
+	}
+	return &seq, nil
+}
+
+// Fetch returns up to BatchSize messages from the durable position and advances it
+func (b *Broker) Fetch(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+	if config == nil {
+		return nil, fmt.Errorf("subscription config cannot be nil")
+	}
+
+	if config.Subject == "" {
+		return nil, fmt.Errorf("subject cannot be empty")
+	}
+
+	batchSize := int(config.BatchSize)
+	if batchSize <= 0 {
+		batchSize = 10
+	}
+
+	next, err := b.loadNext(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []*domain.ReceivedMessage
+	err = b.scan(config.Subject, func(rec *record) bool {
+		if rec.Sequence < next {
+			return true
+		}
+		messages = append(messages, &domain.ReceivedMessage{
+			Subject:   config.Subject,
+			Sequence:  rec.Sequence,
+			Data:      rec.Data,
+			Headers:   rec.Headers,
+			Timestamp: rec.Timestamp,
+		})
+		return len(messages) < batchSize
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(messages) > 0 {
+		if err := b.saveNext(config, messages[len(messages)-1].Sequence+1); err != nil {
+			return nil, err
+		}
+	}
+
+	return &messageStream{messages: messages}, nil
+}
+
+// GetLastSequence returns the last sequence of the subject, 0 when empty
+func (b *Broker) GetLastSequence(ctx context.Context, subject string) (uint64, error) {
+	if subject == "" {
+		return 0, fmt.Errorf("subject cannot be empty")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastSequence(subject)
+}
+
+// ListSubjects returns the subjects that have at least one stored message
+func (b *Broker) ListSubjects(ctx context.Context) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subjects: %w", err)
+	}
+
+	var subjects []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		subject, err := url.PathUnescape(entry.Name())
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(b.messagesPath(subject)); err != nil {
+			continue
+		}
+		subjects = append(subjects, subject)
+	}
+	return subjects, nil
+}
+
+// Close implements IngressClient and EgressClient; the broker holds no open files
+func (b *Broker) Close() error {
+	return nil
+}
+
+// lastSequence returns the cached last sequence, scanning the file on first use.
+// The caller holds b.mu.
+func (b *Broker) lastSequence(subject string) (uint64, error) {
+	if last, ok := b.last[subject]; ok {
+		return last, nil
+	}
+
+	var last uint64
+	err := b.scan(subject, func(rec *record) bool {
+		last = rec.Sequence
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	b.last[subject] = last
+	return last, nil
+}
+
+// scan calls fn for each record of the subject until fn returns false
+func (b *Broker) scan(subject string, fn func(rec *record) bool) error {
+	f, err := os.Open(b.messagesPath(subject))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open subject file: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// A partial trailing line is a write in progress
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read subject file: %w", err)
+		}
+
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("corrupt record in %s: %w", subject, err)
+		}
+		if !fn(&rec) {
+			return nil
+		}
+	}
+}
+
+// loadNext returns the next sequence of the durable consumer, 1 when unknown
+func (b *Broker) loadNext(config *domain.SubscriptionConfig) (uint64, error) {
+	data, err := os.ReadFile(b.consumerPath(config))
+	if os.IsNotExist(err) {
+		return 1, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read consumer position: %w", err)
+	}
+
+	next, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("corrupt consumer position: %w", err)
+	}
+	return next, nil
+}
+
+// saveNext stores the next sequence of the durable consumer
+func (b *Broker) saveNext(config *domain.SubscriptionConfig, next uint64) error {
+	path := b.consumerPath(config)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create consumer directory: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatUint(next, 10)), 0644); err != nil {
+		return fmt.Errorf("failed to write consumer position: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write consumer position: %w", err)
+	}
+	return nil
+}
+
+func (b *Broker) subjectDir(subject string) string {
+	return filepath.Join(b.dir, url.PathEscape(subject))
+}
+
+func (b *Broker) messagesPath(subject string) string {
+	return filepath.Join(b.subjectDir(subject), messagesFile)
+}
+
+func (b *Broker) consumerPath(config *domain.SubscriptionConfig) string {
+	durable := config.DurableName
+	if durable == "" {
+		durable = "default"
+	}
+	return filepath.Join(b.subjectDir(config.Subject), consumersDir, url.PathEscape(durable))
+}
+
+// notificationStream polls the subject file for messages past the durable position.
+// It notifies again once the position or the last sequence changed, so a backlog
+// larger than one batch is drained batch by batch.
+type notificationStream struct {
+	ctx          context.Context
+	broker       *Broker
+	config       *domain.SubscriptionConfig
+	notifiedNext uint64
+	notifiedLast uint64
+}
+
+func (s *notificationStream) Recv() (*domain.Notification, error) {
+	for {
+		next, last, err := s.position()
+		if err != nil {
+			return nil, err
+		}
+		if last >= next && (next != s.notifiedNext || last != s.notifiedLast) {
+			s.notifiedNext, s.notifiedLast = next, last
+			return &domain.Notification{Subject: s.config.Subject, Sequence: last}, nil
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return nil, s.ctx.Err()
+		case <-s.broker.clock.After(s.broker.pollInterval):
+		}
+	}
+}
+
+// position returns the durable's next sequence and the subject's last sequence
+func (s *notificationStream) position() (uint64, uint64, error) {
+	next, err := s.broker.loadNext(s.config)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var last uint64
+	err = s.broker.scan(s.config.Subject, func(rec *record) bool {
+		last = rec.Sequence
+		return true
+	})
+	return next, last, err
+}
+
+// messageStream iterates over a fetched batch
+type messageStream struct {
+	messages []*domain.ReceivedMessage
+}
+
+func (s *messageStream) Recv() (*domain.ReceivedMessage, error) {
+	if len(s.messages) == 0 {
+		return nil, io.EOF
+	}
+	msg := s.messages[0]
+	s.messages = s.messages[1:]
+	return msg, nil
+}
@@ -0,0 +1,334 @@
+package localbroker
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domaintest"
+	usecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/subscriber"
+)
+
+type testLogger struct{}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {}
+
+func newTestBroker(t *testing.T, dir string) *Broker {
+	t.Helper()
+	b, err := New(&Config{Dir: dir, PollInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	return b
+}
+
+func publish(t *testing.T, b *Broker, subject string, data ...string) {
+	t.Helper()
+	for _, d := range data {
+		if _, err := b.Publish(context.Background(), &domain.PublishMessage{Subject: subject, Data: []byte(d)}); err != nil {
+			t.Fatalf("publish failed: %v", err)
+		}
+	}
+}
+
+func fetchAll(t *testing.T, b *Broker, config *domain.SubscriptionConfig) []string {
+	t.Helper()
+	stream, err := b.Fetch(context.Background(), config)
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	var data []string
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return data
+		}
+		data = append(data, string(msg.Data))
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("nil config", func(t *testing.T) {
+		if _, err := New(nil); err == nil {
+			t.Fatal("expected error for nil config")
+		}
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		if _, err := New(&Config{}); err == nil {
+			t.Fatal("expected error for empty directory")
+		}
+	})
+}
+
+func TestBroker_Publish(t *testing.T) {
+	dir := t.TempDir()
+	b := newTestBroker(t, dir)
+
+	result, err := b.Publish(context.Background(), &domain.PublishMessage{
+		Subject: "orders/eu",
+		Data:    []byte("a"),
+		Headers: map[string]string{"tenant": "acme"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Sequence != 1 {
+		t.Errorf("expected sequence 1, got %d", result.Sequence)
+	}
+
+	t.Run("sequence survives reopen", func(t *testing.T) {
+		reopened := newTestBroker(t, dir)
+		result, _ := reopened.Publish(context.Background(), &domain.PublishMessage{Subject: "orders/eu"})
+		if result.Sequence != 2 {
+			t.Errorf("expected sequence 2, got %d", result.Sequence)
+		}
+	})
+
+	t.Run("empty subject", func(t *testing.T) {
+		if _, err := b.Publish(context.Background(), &domain.PublishMessage{}); err == nil {
+			t.Fatal("expected error for empty subject")
+		}
+	})
+
+	t.Run("timestamp from the clock", func(t *testing.T) {
+		now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		clocked, err := New(&Config{Dir: t.TempDir(), Clock: connectortest.NewFakeClock(now)})
+		if err != nil {
+			t.Fatalf("failed to create broker: %v", err)
+		}
+		publish(t, clocked, "orders", "a")
+
+		stream, err := clocked.Fetch(context.Background(), &domain.SubscriptionConfig{Subject: "orders", DurableName: "c"})
+		if err != nil {
+			t.Fatalf("fetch failed: %v", err)
+		}
+		msg, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("recv failed: %v", err)
+		}
+		if !msg.Timestamp.Equal(now) {
+			t.Errorf("expected timestamp %v, got %v", now, msg.Timestamp)
+		}
+	})
+}
+
+func TestBroker_Fetch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("batches and durable position", func(t *testing.T) {
+		dir := t.TempDir()
+		b := newTestBroker(t, dir)
+		publish(t, b, "orders", "1", "2", "3")
+
+		config := &domain.SubscriptionConfig{Subject: "orders", DurableName: "billing", BatchSize: 2}
+		if got := fetchAll(t, b, config); len(got) != 2 || got[0] != "1" {
+			t.Errorf("unexpected first batch: %v", got)
+		}
+
+		reopened := newTestBroker(t, dir)
+		if got := fetchAll(t, reopened, config); len(got) != 1 || got[0] != "3" {
+			t.Errorf("expected durable to resume at 3, got %v", got)
+		}
+		if got := fetchAll(t, reopened, config); len(got) != 0 {
+			t.Errorf("expected no messages, got %v", got)
+		}
+
+		other := &domain.SubscriptionConfig{Subject: "orders", DurableName: "audit", BatchSize: 10}
+		if got := fetchAll(t, b, other); len(got) != 3 {
+			t.Errorf("expected independent durable to read 3 messages, got %v", got)
+		}
+	})
+
+	t.Run("start positions", func(t *testing.T) {
+		b := newTestBroker(t, t.TempDir())
+		publish(t, b, "orders", "1", "2", "3")
+
+		seq := uint64(2)
+		tests := []struct {
+			name   string
+			config *domain.SubscriptionConfig
+			want   int
+		}{
+			{"start sequence", &domain.SubscriptionConfig{StartSequence: &seq}, 2},
+			{"earliest", &domain.SubscriptionConfig{StartPosition: domain.Earliest()}, 3},
+			{"latest", &domain.SubscriptionConfig{StartPosition: domain.Latest()}, 0},
+			{"from sequence", &domain.SubscriptionConfig{StartPosition: domain.FromSequence(3)}, 1},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tt.config.Subject = "orders"
+				tt.config.DurableName = tt.name
+				if _, err := b.Subscribe(ctx, tt.config); err != nil {
+					t.Fatalf("subscribe failed: %v", err)
+				}
+				if got := fetchAll(t, b, tt.config); len(got) != tt.want {
+					t.Errorf("expected %d messages, got %v", tt.want, got)
+				}
+			})
+		}
+	})
+}
+
+func TestBroker_Subscribe(t *testing.T) {
+	t.Run("notifies on new messages", func(t *testing.T) {
+		b := newTestBroker(t, t.TempDir())
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		stream, err := b.Subscribe(ctx, &domain.SubscriptionConfig{Subject: "orders", DurableName: "d"})
+		if err != nil {
+			t.Fatalf("subscribe failed: %v", err)
+		}
+
+		go b.Publish(context.Background(), &domain.PublishMessage{Subject: "orders", Data: []byte("1")})
+
+		notification, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("expected notification, got %v", err)
+		}
+		if notification.Sequence != 1 {
+			t.Errorf("expected sequence 1, got %d", notification.Sequence)
+		}
+	})
+
+	t.Run("stops with context", func(t *testing.T) {
+		b := newTestBroker(t, t.TempDir())
+		ctx, cancel := context.WithCancel(context.Background())
+
+		stream, _ := b.Subscribe(ctx, &domain.SubscriptionConfig{Subject: "orders"})
+		cancel()
+
+		if _, err := stream.Recv(); err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestBroker_WithSubscriber(t *testing.T) {
+	b := newTestBroker(t, t.TempDir())
+	publish(t, b, "orders", "1", "2", "3", "4", "5")
+
+	sub, err := usecase.New(&usecase.Config{
+		Client:      b,
+		DurableName: "worker",
+		BatchSize:   2,
+		Logger:      &testLogger{},
+	})
+	if err != nil {
+		t.Fatalf("failed to create subscriber: %v", err)
+	}
+
+	var mu sync.Mutex
+	var received []string
+	done := make(chan struct{})
+	sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, string(msg.Data))
+		if len(received) == 6 {
+			close(done)
+		}
+		return nil
+	})
+
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start subscriber: %v", err)
+	}
+	defer sub.Stop()
+
+	publish(t, b, "orders", "6")
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		mu.Lock()
+		defer mu.Unlock()
+		t.Fatalf("expected 6 messages, got %v", received)
+	}
+}
+
+func TestBroker_ListSubjects(t *testing.T) {
+	b := newTestBroker(t, t.TempDir())
+	publish(t, b, "tenants/acme", "1")
+	publish(t, b, "orders", "1")
+
+	subjects, err := b.ListSubjects(context.Background())
+	if err != nil {
+		t.Fatalf("ListSubjects failed: %v", err)
+	}
+	sort.Strings(subjects)
+	if len(subjects) != 2 || subjects[0] != "orders" || subjects[1] != "tenants/acme" {
+		t.Errorf("unexpected subjects: %v", subjects)
+	}
+}
+
+func TestBroker_WithPatternHandler(t *testing.T) {
+	b := newTestBroker(t, t.TempDir())
+	publish(t, b, "tenant.a", "a1")
+	publish(t, b, "other", "x")
+
+	sub, err := usecase.New(&usecase.Config{
+		Client:            b,
+		DurableName:       "worker",
+		Logger:            &testLogger{},
+		DiscoveryInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("failed to create subscriber: %v", err)
+	}
+
+	var mu sync.Mutex
+	received := make(map[string]string)
+	done := make(chan struct{})
+	sub.RegisterPatternHandler(regexp.MustCompile(`^tenant\.`), domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received[msg.Subject] = string(msg.Data)
+		if len(received) == 2 {
+			close(done)
+		}
+		return nil
+	}))
+
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start subscriber: %v", err)
+	}
+	defer sub.Stop()
+
+	publish(t, b, "tenant.b", "b1")
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		mu.Lock()
+		defer mu.Unlock()
+		t.Fatalf("expected messages from both tenants, got %v", received)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if received["tenant.a"] != "a1" || received["tenant.b"] != "b1" {
+		t.Errorf("unexpected messages: %v", received)
+	}
+	if _, ok := received["other"]; ok {
+		t.Error("non-matching subject should not be routed")
+	}
+}
+
+func TestBroker_Contract(t *testing.T) {
+	domaintest.RunIngressClientTests(t, func(t *testing.T) domain.IngressClient {
+		return newTestBroker(t, t.TempDir())
+	})
+	domaintest.RunEgressClientTests(t, func(t *testing.T) domaintest.Clients {
+		b := newTestBroker(t, t.TempDir())
+		return domaintest.Clients{Ingress: b, Egress: b}
+	})
+}
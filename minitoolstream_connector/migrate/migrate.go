@@ -0,0 +1,270 @@
+// Package migrate copies the full history of subjects from one MiniToolStream
+// cluster to another, for infrastructure moves.
+//
+// Each subject is copied from the sequence after its saved progress up to its
+// last sequence on the source when the copy starts, in ranged fetches. Progress
+// is saved to a domain.CheckpointStore as messages are published, so an
+// interrupted migration resumes where it stopped. Messages are published with
+// an idempotency key derived from their source position, so the few messages
+// published again after a crash can be dropped by the destination.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/dump"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/checkpoint"
+	publisherUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+)
+
+// HeaderSourceSequence carries the sequence of a copied message on the source
+const HeaderSourceSequence = "migrate-source-sequence"
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// Config represents migration configuration
+type Config struct {
+	// Source is the client history is read from
+	Source domain.EgressClient
+	// Destination is the client history is copied to
+	Destination domain.IngressClient
+	// Subjects lists the subjects to copy; when empty, every subject listed by
+	// a Source implementing domain.SubjectLister is copied
+	Subjects []string
+
+	// Progress stores the last copied sequence of every subject (default in
+	// memory, which makes the migration restart from scratch)
+	Progress domain.CheckpointStore
+	// Name keys the progress, so several migrations can share a store
+	// (default "migrate")
+	Name string
+	// BatchSize is the number of messages fetched at a time (default 100)
+	BatchSize int32
+	// SaveEvery is the number of copied messages between progress saves
+	// (default BatchSize)
+	SaveEvery int
+	// MaxRetries is the number of additional publish attempts per message
+	MaxRetries int
+	// RetryBackoff is the initial delay between publish retries
+	RetryBackoff time.Duration
+
+	Logger Logger
+}
+
+// Result is the outcome of copying one subject
+type Result struct {
+	Subject string
+	// FromSequence is the first sequence copied by this run; above 1 when the
+	// migration resumed
+	FromSequence uint64
+	// ToSequence is the last sequence of the subject when the copy started
+	ToSequence uint64
+	// Copied is the number of messages copied by this run
+	Copied int
+	// Bytes is the total payload size copied by this run
+	Bytes int64
+}
+
+// Migrator copies subject history between clusters
+type Migrator struct {
+	source    domain.EgressClient
+	subjects  []string
+	progress  domain.CheckpointStore
+	name      string
+	batchSize int32
+	saveEvery int
+	logger    Logger
+
+	pub *publisherUsecase.SimplePublisher
+}
+
+// New creates a new migrator
+func New(config *Config) (*Migrator, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Source == nil || config.Destination == nil {
+		return nil, fmt.Errorf("source and destination clients cannot be nil")
+	}
+
+	if len(config.Subjects) == 0 {
+		if _, ok := config.Source.(domain.SubjectLister); !ok {
+			return nil, fmt.Errorf("subjects are required when the source cannot list subjects")
+		}
+	}
+
+	if config.SaveEvery < 0 || config.MaxRetries < 0 {
+		return nil, fmt.Errorf("save interval and max retries cannot be negative")
+	}
+
+	m := &Migrator{
+		source:    config.Source,
+		subjects:  append([]string(nil), config.Subjects...),
+		progress:  config.Progress,
+		name:      config.Name,
+		batchSize: config.BatchSize,
+		saveEvery: config.SaveEvery,
+		logger:    config.Logger,
+	}
+
+	if m.progress == nil {
+		m.progress = checkpoint.NewMemoryStore()
+	}
+	if m.name == "" {
+		m.name = "migrate"
+	}
+	if m.batchSize <= 0 {
+		m.batchSize = 100
+	}
+	if m.saveEvery == 0 {
+		m.saveEvery = int(m.batchSize)
+	}
+	if m.logger == nil {
+		m.logger = domain.DefaultLogger{}
+	}
+
+	pub, err := publisherUsecase.New(&publisherUsecase.Config{
+		Client:       nopCloseIngress{config.Destination},
+		MaxRetries:   config.MaxRetries,
+		RetryBackoff: config.RetryBackoff,
+		Logger:       discardLogger{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create publisher: %w", err)
+	}
+	m.pub = pub
+
+	return m, nil
+}
+
+// Run copies every subject in turn and returns the results of the subjects
+// copied so far. The clients are not closed.
+func (m *Migrator) Run(ctx context.Context) ([]Result, error) {
+	subjects := m.subjects
+	if len(subjects) == 0 {
+		listed, err := m.source.(domain.SubjectLister).ListSubjects(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list subjects: %w", err)
+		}
+		subjects = listed
+	}
+
+	m.logger.Printf("Migrating %d subjects", len(subjects))
+	start := time.Now()
+
+	results := make([]Result, 0, len(subjects))
+	var copied int
+	for _, subject := range subjects {
+		result, err := m.migrateSubject(ctx, subject)
+		results = append(results, result)
+		if err != nil {
+			return results, fmt.Errorf("failed to migrate %s: %w", subject, err)
+		}
+		copied += result.Copied
+	}
+
+	m.logger.Printf("✓ Migrated %d subjects, %d messages in %v", len(subjects), copied, time.Since(start).Round(time.Millisecond))
+	return results, nil
+}
+
+// migrateSubject copies the sequences of one subject after its saved progress
+func (m *Migrator) migrateSubject(ctx context.Context, subject string) (Result, error) {
+	result := Result{Subject: subject}
+
+	last, err := m.source.GetLastSequence(ctx, subject)
+	if err != nil {
+		return result, fmt.Errorf("failed to get last sequence: %w", err)
+	}
+	done, _, err := m.progress.Load(ctx, m.name, subject)
+	if err != nil {
+		return result, fmt.Errorf("failed to load progress: %w", err)
+	}
+
+	result.FromSequence, result.ToSequence = done+1, last
+	if done >= last {
+		m.logger.Printf("[%s] Up to date at sequence %d", subject, done)
+		return result, nil
+	}
+	if done > 0 {
+		m.logger.Printf("[%s] Resuming after sequence %d", subject, done)
+	}
+
+	exporter, err := dump.NewExporter(&dump.ExporterConfig{
+		Client:       m.source,
+		Subject:      subject,
+		FromSequence: done + 1,
+		ToSequence:   last,
+		BatchSize:    m.batchSize,
+		DurableName:  m.name + "-" + subject,
+		Logger:       m.logger,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	unsaved := 0
+	summary, err := exporter.Export(ctx, func(msg *domain.ReceivedMessage) error {
+		if err := m.copyMessage(ctx, msg); err != nil {
+			return err
+		}
+		if unsaved++; unsaved >= m.saveEvery {
+			unsaved = 0
+			return m.saveProgress(ctx, subject, msg.Sequence)
+		}
+		return nil
+	})
+	result.Copied, result.Bytes = summary.Count, summary.Bytes
+
+	// Save what was copied even when the copy failed, so a rerun resumes there
+	if unsaved > 0 {
+		if saveErr := m.saveProgress(ctx, subject, summary.LastSequence); err == nil {
+			err = saveErr
+		}
+	}
+	return result, err
+}
+
+// copyMessage publishes one source message on the destination
+func (m *Migrator) copyMessage(ctx context.Context, msg *domain.ReceivedMessage) error {
+	headers := make(map[string]string, len(msg.Headers)+1)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[HeaderSourceSequence] = strconv.FormatUint(msg.Sequence, 10)
+
+	dedupKey := msg.DedupKey()
+	if dedupKey == "" {
+		dedupKey = m.name + ":" + msg.Subject + ":" + strconv.FormatUint(msg.Sequence, 10)
+	}
+
+	return m.pub.Publish(ctx, domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{Subject: msg.Subject, Data: msg.Data, Headers: headers, DedupKey: dedupKey}, nil
+	}))
+}
+
+// saveProgress stores the last copied sequence of the subject
+func (m *Migrator) saveProgress(ctx context.Context, subject string, sequence uint64) error {
+	// Saving must not be skipped because the run was cancelled
+	if err := m.progress.Save(context.WithoutCancel(ctx), m.name, subject, sequence); err != nil {
+		return fmt.Errorf("failed to save progress: %w", err)
+	}
+	return nil
+}
+
+// discardLogger silences the publisher, which logs every message
+type discardLogger struct{}
+
+func (discardLogger) Printf(format string, v ...interface{}) {}
+
+// nopCloseIngress keeps the publisher from closing the caller's client
+type nopCloseIngress struct {
+	domain.IngressClient
+}
+
+func (nopCloseIngress) Close() error { return nil }
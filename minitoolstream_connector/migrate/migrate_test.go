@@ -0,0 +1,185 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/checkpoint"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/localbroker"
+)
+
+type testLogger struct{}
+
+func (testLogger) Printf(format string, v ...interface{}) {}
+
+func newBroker(t *testing.T) *localbroker.Broker {
+	t.Helper()
+	b, err := localbroker.New(&localbroker.Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	return b
+}
+
+func seed(t *testing.T, b *localbroker.Broker, subject string, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		msg := &domain.PublishMessage{Subject: subject, Data: []byte(fmt.Sprintf("%s-%d", subject, i)), Headers: map[string]string{"n": fmt.Sprint(i)}}
+		if _, err := b.Publish(context.Background(), msg); err != nil {
+			t.Fatalf("publish failed: %v", err)
+		}
+	}
+}
+
+// readers numbers the durable consumers readAll reads with
+var readers atomic.Int64
+
+// readAll returns every message of the subject
+func readAll(t *testing.T, b *localbroker.Broker, subject string) []*domain.ReceivedMessage {
+	t.Helper()
+	stream, err := b.Fetch(context.Background(), &domain.SubscriptionConfig{Subject: subject, DurableName: fmt.Sprintf("read-%d", readers.Add(1)), BatchSize: 1000})
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	var messages []*domain.ReceivedMessage
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return messages
+		}
+		messages = append(messages, msg)
+	}
+}
+
+// failingIngress fails every publish after the first n
+type failingIngress struct {
+	domain.IngressClient
+	remaining atomic.Int64
+}
+
+func (c *failingIngress) Publish(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+	if c.remaining.Add(-1) < 0 {
+		return nil, errors.New("destination unavailable")
+	}
+	return c.IngressClient.Publish(ctx, msg)
+}
+
+func TestNew_Validation(t *testing.T) {
+	src := newBroker(t)
+	tests := []struct {
+		name   string
+		config *Config
+		want   string
+	}{
+		{"nil config", nil, "config cannot be nil"},
+		{"missing destination", &Config{Source: src, Subjects: []string{"orders"}}, "cannot be nil"},
+		{"unlistable source", &Config{Source: unlistable{src}, Destination: src}, "subjects are required"},
+		{"negative retries", &Config{Source: src, Destination: src, MaxRetries: -1}, "cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// unlistable hides the broker's ListSubjects
+type unlistable struct {
+	domain.EgressClient
+}
+
+func TestMigrator_CopiesAllSubjects(t *testing.T) {
+	src, dst := newBroker(t), newBroker(t)
+	seed(t, src, "orders", 5)
+	seed(t, src, "payments", 3)
+
+	m, err := New(&Config{Source: src, Destination: dst, BatchSize: 2, Logger: testLogger{}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	results, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(results) != 2 || results[0].Copied != 5 || results[1].Copied != 3 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if results[0].FromSequence != 1 || results[0].ToSequence != 5 {
+		t.Errorf("unexpected range: %+v", results[0])
+	}
+
+	messages := readAll(t, dst, "orders")
+	if len(messages) != 5 {
+		t.Fatalf("expected 5 copied messages, got %d", len(messages))
+	}
+	for i, msg := range messages {
+		seq := fmt.Sprint(i + 1)
+		if string(msg.Data) != "orders-"+seq || msg.Headers["n"] != seq {
+			t.Errorf("message %d out of order or changed: %q %v", i+1, msg.Data, msg.Headers)
+		}
+		if msg.Headers[HeaderSourceSequence] != seq || msg.Headers[domain.HeaderIdempotencyKey] != "migrate:orders:"+seq {
+			t.Errorf("unexpected migration headers: %v", msg.Headers)
+		}
+	}
+}
+
+func TestMigrator_Resumes(t *testing.T) {
+	src, dst := newBroker(t), newBroker(t)
+	seed(t, src, "orders", 7)
+	path := filepath.Join(t.TempDir(), "progress.json")
+
+	// The first run copies three messages before the destination goes away
+	progress, _ := checkpoint.NewFileStore(path)
+	failing := &failingIngress{IngressClient: dst}
+	failing.remaining.Store(3)
+	m, _ := New(&Config{Source: src, Destination: failing, Subjects: []string{"orders"}, Progress: progress, BatchSize: 2, SaveEvery: 10, Logger: testLogger{}})
+	results, err := m.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "destination unavailable") {
+		t.Fatalf("expected the copy to fail, got %v", err)
+	}
+	if results[0].Copied != 3 {
+		t.Errorf("expected 3 messages copied before the failure, got %d", results[0].Copied)
+	}
+
+	// A new process picks up the saved progress
+	progress, _ = checkpoint.NewFileStore(path)
+	m, _ = New(&Config{Source: src, Destination: dst, Subjects: []string{"orders"}, Progress: progress, Logger: testLogger{}})
+	results, err = m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if results[0].FromSequence != 4 || results[0].Copied != 4 {
+		t.Errorf("expected the copy to resume at sequence 4, got %+v", results[0])
+	}
+
+	messages := readAll(t, dst, "orders")
+	if len(messages) != 7 {
+		t.Fatalf("expected 7 messages without duplicates, got %d", len(messages))
+	}
+	for i, msg := range messages {
+		if want := fmt.Sprintf("orders-%d", i+1); string(msg.Data) != want {
+			t.Errorf("expected %s at position %d, got %s", want, i+1, msg.Data)
+		}
+	}
+
+	// Running again only copies what was published since
+	seed(t, src, "orders", 1)
+	results, err = m.Run(context.Background())
+	if err != nil || results[0].Copied != 1 || results[0].FromSequence != 8 {
+		t.Errorf("expected one new message copied, got %+v, %v", results, err)
+	}
+	results, _ = m.Run(context.Background())
+	if results[0].Copied != 0 {
+		t.Errorf("expected nothing copied when up to date, got %d", results[0].Copied)
+	}
+}
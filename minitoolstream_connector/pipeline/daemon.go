@@ -0,0 +1,252 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/handler"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+)
+
+// defaultDrainTimeout bounds how long sinks may take to handle buffered
+// messages on shutdown
+const defaultDrainTimeout = 30 * time.Second
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// Config configures a Daemon
+type Config struct {
+	Spec *Spec
+	// Publisher publishes what sources produce; required when a pipeline
+	// has a source
+	Publisher domain.Publisher
+	// Subscriber feeds the sinks; required when a pipeline has sinks
+	Subscriber domain.Subscriber
+	// DrainTimeout bounds how long sinks may take to handle buffered
+	// messages on shutdown (default 30s)
+	DrainTimeout time.Duration
+	Logger       Logger
+	// Clock times scheduled sources and stamps what they publish
+	// (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// Daemon runs the pipelines of a pipeline file
+type Daemon struct {
+	spec         *Spec
+	publisher    domain.Publisher
+	subscriber   domain.Subscriber
+	drainTimeout time.Duration
+	logger       Logger
+	clock        domain.Clock
+}
+
+// New creates a daemon for the pipelines of config.Spec
+func New(config *Config) (*Daemon, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if config.Spec == nil {
+		return nil, fmt.Errorf("spec is required")
+	}
+	if err := config.Spec.Validate(); err != nil {
+		return nil, err
+	}
+	if config.Spec.HasSources() && config.Publisher == nil {
+		return nil, fmt.Errorf("publisher is required for pipelines with a source")
+	}
+	if config.Spec.HasSinks() && config.Subscriber == nil {
+		return nil, fmt.Errorf("subscriber is required for pipelines with sinks")
+	}
+
+	d := &Daemon{
+		spec:         config.Spec,
+		publisher:    config.Publisher,
+		subscriber:   config.Subscriber,
+		drainTimeout: config.DrainTimeout,
+		logger:       config.Logger,
+		clock:        domain.ClockOrSystem(config.Clock),
+	}
+	if d.drainTimeout <= 0 {
+		d.drainTimeout = defaultDrainTimeout
+	}
+	if d.logger == nil {
+		d.logger = domain.DefaultLogger{}
+	}
+	return d, nil
+}
+
+// Run runs the pipelines until ctx is cancelled or a source fails, then
+// drains the sinks. Without sinks or repeating sources, Run returns once
+// every source has published. Errors of scheduled runs are logged and the
+// next run goes ahead.
+func (d *Daemon) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if d.spec.HasSinks() {
+		for _, p := range d.spec.Pipelines {
+			if len(p.Sinks) == 0 {
+				continue
+			}
+			h, err := d.sinkHandler(p)
+			if err != nil {
+				return fmt.Errorf("pipeline %s: %w", p.Name, err)
+			}
+			d.subscriber.RegisterHandler(p.Subject, h)
+		}
+		// Shutdown goes through Drain below, so ctx must not stop the
+		// subscriptions on its own
+		if err := d.subscriber.Start(context.WithoutCancel(ctx)); err != nil {
+			d.subscriber.Stop()
+			return fmt.Errorf("failed to start subscriber: %w", err)
+		}
+	}
+
+	var (
+		wg    sync.WaitGroup
+		errMu sync.Mutex
+		first error
+	)
+	for _, p := range d.spec.Pipelines {
+		if p.Source == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(p PipelineSpec) {
+			defer wg.Done()
+			if err := d.runSource(ctx, p); err != nil && ctx.Err() == nil {
+				errMu.Lock()
+				if first == nil {
+					first = fmt.Errorf("pipeline %s: %w", p.Name, err)
+				}
+				errMu.Unlock()
+				cancel()
+			}
+		}(p)
+	}
+	d.logger.Printf("✓ Running %d pipelines", len(d.spec.Pipelines))
+
+	if d.spec.HasSinks() {
+		<-ctx.Done()
+	}
+	wg.Wait()
+	if !d.spec.HasSinks() {
+		return first
+	}
+
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), d.drainTimeout)
+	defer cancelDrain()
+	return errors.Join(first, d.subscriber.Drain(drainCtx))
+}
+
+// runSource publishes what the source of p produces until it is done or ctx
+// is cancelled
+func (d *Daemon) runSource(ctx context.Context, p PipelineSpec) error {
+	switch source := p.Source; {
+	case source.File != nil:
+		preparer := handler.NewFileHandler(&handler.FileHandlerConfig{
+			Subject:     p.Subject,
+			FilePath:    source.File.Path,
+			ContentType: source.File.ContentType,
+			Logger:      d.logger,
+			Clock:       d.clock,
+		})
+		return d.repeat(ctx, p.Name, source.File.Schedule, func() error {
+			return d.publisher.Publish(ctx, preparer)
+		})
+	case source.Glob != nil:
+		return d.repeat(ctx, p.Name, source.Glob.Schedule, func() error {
+			preparers, err := handler.PreparersFromGlob(p.Subject, source.Glob.Pattern)
+			if err != nil {
+				return err
+			}
+			if len(preparers) == 0 {
+				d.logger.Printf("[%s] No files match %s", p.Name, source.Glob.Pattern)
+				return nil
+			}
+			return d.publisher.PublishAll(ctx, preparers)
+		})
+	default:
+		tail, err := handler.NewTailHandler(&handler.TailHandlerConfig{
+			Subject:   p.Subject,
+			FilePath:  source.Tail.Path,
+			Publisher: d.publisher,
+			BatchSize: source.Tail.BatchSize,
+			FromStart: source.Tail.FromStart,
+			Logger:    d.logger,
+			Clock:     d.clock,
+		})
+		if err != nil {
+			return err
+		}
+		return tail.Run(ctx)
+	}
+}
+
+// repeat runs fn once, or on the schedule until ctx is cancelled. Interval
+// schedules run right away, cron schedules at their first time.
+func (d *Daemon) repeat(ctx context.Context, name string, s Schedule, fn func() error) error {
+	var schedule domain.Schedule
+	switch {
+	case s.Every > 0:
+		schedule = publisher.Every(s.Every)
+		if err := fn(); err != nil {
+			d.logger.Printf("[%s] ✗ Run failed: %v", name, err)
+		}
+	case s.Cron != "":
+		// Validate parsed the expression
+		schedule, _ = publisher.ParseCron(s.Cron)
+	default:
+		return fn()
+	}
+
+	for {
+		now := d.clock.Now()
+		next := schedule.Next(now)
+		if next.IsZero() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-d.clock.After(next.Sub(now)):
+		}
+		if err := fn(); err != nil {
+			d.logger.Printf("[%s] ✗ Run failed: %v", name, err)
+		}
+	}
+}
+
+// sinkHandler chains the sinks of p in order
+func (d *Daemon) sinkHandler(p PipelineSpec) (domain.MessageHandler, error) {
+	handlers := make([]domain.MessageHandler, 0, len(p.Sinks))
+	for _, sink := range p.Sinks {
+		switch {
+		case sink.File != nil:
+			saver, err := handler.NewFileSaver(&handler.FileSaverConfig{OutputDir: sink.File.Dir, Logger: d.logger})
+			if err != nil {
+				return nil, err
+			}
+			handlers = append(handlers, saver)
+		case sink.HTTP != nil:
+			forwarder, err := handler.NewHTTPForwarder(&handler.HTTPForwarderConfig{URL: sink.HTTP.URL, Method: sink.HTTP.Method, Logger: d.logger})
+			if err != nil {
+				return nil, err
+			}
+			handlers = append(handlers, forwarder)
+		default:
+			prefix := sink.Log.Prefix
+			if prefix == "" {
+				prefix = p.Name
+			}
+			handlers = append(handlers, handler.NewLoggerHandler(&handler.LoggerHandlerConfig{Prefix: prefix, Logger: d.logger}))
+		}
+	}
+	return handler.Chain(handlers...), nil
+}
@@ -0,0 +1,217 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/localbroker"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+	subscriberUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/subscriber"
+)
+
+type discardLogger struct{}
+
+func (discardLogger) Printf(format string, v ...interface{}) {}
+
+func newBroker(t *testing.T) *localbroker.Broker {
+	t.Helper()
+	broker, err := localbroker.New(&localbroker.Config{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	t.Cleanup(func() { broker.Close() })
+	return broker
+}
+
+func TestNew_Validation(t *testing.T) {
+	spec := &Spec{Pipelines: []PipelineSpec{{
+		Name:    "a",
+		Subject: "s",
+		Source:  &SourceSpec{File: &FileSource{Path: "f"}},
+		Sinks:   []SinkSpec{{Log: &LogSink{}}},
+	}}}
+	tests := []struct {
+		name   string
+		config *Config
+		want   string
+	}{
+		{"nil config", nil, "config cannot be nil"},
+		{"nil spec", &Config{}, "spec is required"},
+		{"invalid spec", &Config{Spec: &Spec{}}, "at least one pipeline"},
+		{"missing publisher", &Config{Spec: spec}, "publisher is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestDaemon_SourcesOnly(t *testing.T) {
+	broker := newBroker(t)
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pub, err := publisher.New(&publisher.Config{Client: broker, Logger: discardLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := New(&Config{
+		Spec: &Spec{Pipelines: []PipelineSpec{
+			{Name: "glob", Subject: "files", Source: &SourceSpec{Glob: &GlobSource{Pattern: filepath.Join(dir, "*.txt")}}},
+			{Name: "file", Subject: "single", Source: &SourceSpec{File: &FileSource{Path: filepath.Join(dir, "a.txt")}}},
+		}},
+		Publisher: pub,
+		Logger:    discardLogger{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for subject, want := range map[string]uint64{"files": 2, "single": 1} {
+		if seq, _ := broker.GetLastSequence(ctx, subject); seq != want {
+			t.Errorf("expected %d messages on %s, got %d", want, subject, seq)
+		}
+	}
+}
+
+func TestDaemon_ScheduledSource(t *testing.T) {
+	broker := newBroker(t)
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pub, err := publisher.New(&publisher.Config{Client: broker, Logger: discardLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := connectortest.NewFakeClock(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	d, err := New(&Config{
+		Spec: &Spec{Pipelines: []PipelineSpec{
+			{Name: "file", Subject: "single", Source: &SourceSpec{File: &FileSource{Path: path, Schedule: Schedule{Every: time.Minute}}}},
+		}},
+		Publisher: pub,
+		Logger:    discardLogger{},
+		Clock:     clock,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- d.Run(ctx) }()
+
+	for want := uint64(1); want <= 3; want++ {
+		clock.WaitForWaiters(t, 1, 2*time.Second)
+		if seq, _ := broker.GetLastSequence(ctx, "single"); seq != want {
+			t.Fatalf("expected %d runs, got %d", want, seq)
+		}
+		clock.Advance(time.Minute)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run failed: %v", err)
+	}
+}
+
+func TestDaemon_SourceFailure(t *testing.T) {
+	broker := newBroker(t)
+	pub, err := publisher.New(&publisher.Config{Client: broker, Logger: discardLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := New(&Config{
+		Spec: &Spec{Pipelines: []PipelineSpec{
+			{Name: "broken", Subject: "s", Source: &SourceSpec{File: &FileSource{Path: filepath.Join(t.TempDir(), "missing")}}},
+		}},
+		Publisher: pub,
+		Logger:    discardLogger{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "pipeline broken") {
+		t.Errorf("expected pipeline error, got %v", err)
+	}
+}
+
+func TestDaemon_SourceToSink(t *testing.T) {
+	broker := newBroker(t)
+	in := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(in, []byte(`{"ok":true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+
+	pub, err := publisher.New(&publisher.Config{Client: broker, Logger: discardLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := subscriberUsecase.New(&subscriberUsecase.Config{Client: broker, DurableName: "daemon-test", Logger: discardLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := New(&Config{
+		Spec: &Spec{Pipelines: []PipelineSpec{{
+			Name:    "reports",
+			Subject: "reports",
+			Source:  &SourceSpec{File: &FileSource{Path: in, ContentType: "application/json"}},
+			Sinks:   []SinkSpec{{File: &FileSink{Dir: out}}, {Log: &LogSink{}}},
+		}}},
+		Publisher:  pub,
+		Subscriber: sub,
+		Logger:     discardLogger{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- d.Run(ctx) }()
+
+	saved := filepath.Join(out, "reports_seq_1.json")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(saved); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			cancel()
+			t.Fatalf("timed out waiting for %s", saved)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+	data, err := os.ReadFile(saved)
+	if err != nil || string(data) != `{"ok":true}` {
+		t.Errorf("unexpected saved payload %q (%v)", data, err)
+	}
+}
@@ -0,0 +1,290 @@
+// Package pipeline runs declarative ingest and export pipelines described in
+// a YAML file. Each pipeline publishes what its source produces to a subject
+// and/or hands the messages of the subject to its sinks, using the built-in
+// handlers.
+//
+// Example file:
+//
+//	ingress: localhost:50051
+//	egress: localhost:50052
+//	durable: pipelines
+//	pipelines:
+//	  - name: nginx-logs
+//	    subject: logs.nginx
+//	    source:
+//	      tail: {path: /var/log/nginx/access.log, batch_size: 100}
+//	  - name: reports
+//	    subject: reports.daily
+//	    source:
+//	      glob: {pattern: "exports/**/*.csv", cron: "0 6 * * *"}
+//	    sinks:
+//	      - file: {dir: /srv/reports}
+//	      - http: {url: "https://hooks.example.com/reports"}
+package pipeline
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
+)
+
+// Spec is the content of a pipeline file
+type Spec struct {
+	// Ingress is the address of the ingress server sources publish to
+	Ingress string `yaml:"ingress"`
+	// Egress is the address of the egress server sinks consume from
+	Egress string `yaml:"egress"`
+	// Local is a local broker directory used instead of the servers
+	Local string `yaml:"local"`
+	// Durable is the durable name of the subscription feeding the sinks
+	Durable   string         `yaml:"durable"`
+	Pipelines []PipelineSpec `yaml:"pipelines"`
+}
+
+// PipelineSpec connects an optional source and optional sinks through a
+// subject
+type PipelineSpec struct {
+	Name    string      `yaml:"name"`
+	Subject string      `yaml:"subject"`
+	Source  *SourceSpec `yaml:"source"`
+	Sinks   []SinkSpec  `yaml:"sinks"`
+}
+
+// SourceSpec selects exactly one source
+type SourceSpec struct {
+	File *FileSource `yaml:"file"`
+	Glob *GlobSource `yaml:"glob"`
+	Tail *TailSource `yaml:"tail"`
+}
+
+// Schedule repeats a source every interval or at the times of a cron
+// expression; without either the source publishes once
+type Schedule struct {
+	Every time.Duration `yaml:"every"`
+	Cron  string        `yaml:"cron"`
+}
+
+// FileSource publishes a file
+type FileSource struct {
+	Path        string `yaml:"path"`
+	ContentType string `yaml:"content_type"`
+	Schedule    `yaml:",inline"`
+}
+
+// GlobSource publishes every file matching a pattern; ** matches any number
+// of directories
+type GlobSource struct {
+	Pattern  string `yaml:"pattern"`
+	Schedule `yaml:",inline"`
+}
+
+// TailSource publishes the lines appended to a file
+type TailSource struct {
+	Path string `yaml:"path"`
+	// BatchSize is the maximum number of lines per message (default 1)
+	BatchSize int `yaml:"batch_size"`
+	// FromStart publishes the existing content of the file first
+	FromStart bool `yaml:"from_start"`
+}
+
+// SinkSpec selects exactly one sink
+type SinkSpec struct {
+	File *FileSink `yaml:"file"`
+	HTTP *HTTPSink `yaml:"http"`
+	Log  *LogSink  `yaml:"log"`
+}
+
+// FileSink saves each payload to a file in a directory
+type FileSink struct {
+	Dir string `yaml:"dir"`
+}
+
+// HTTPSink forwards each payload to a URL
+type HTTPSink struct {
+	URL string `yaml:"url"`
+	// Method is the HTTP method (default POST)
+	Method string `yaml:"method"`
+}
+
+// LogSink logs the sequence, size and headers of each message
+type LogSink struct {
+	Prefix string `yaml:"prefix"`
+}
+
+// Load reads and validates a pipeline file
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline file: %w", err)
+	}
+	spec, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// Parse decodes and validates a pipeline file. Unknown fields are rejected
+// so typos do not go unnoticed.
+func Parse(data []byte) (*Spec, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var spec Spec
+	if err := dec.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid pipeline file: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate checks the pipelines, reporting every problem found
+func (s *Spec) Validate() error {
+	var errs []error
+	if len(s.Pipelines) == 0 {
+		errs = append(errs, fmt.Errorf("at least one pipeline is required"))
+	}
+	if s.Local != "" && (s.Ingress != "" || s.Egress != "") {
+		errs = append(errs, fmt.Errorf("local cannot be combined with ingress or egress"))
+	}
+
+	names := make(map[string]bool, len(s.Pipelines))
+	consumed := make(map[string]string)
+	for i, p := range s.Pipelines {
+		name := p.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			errs = append(errs, fmt.Errorf("pipeline %s: name is required", name))
+		} else if names[name] {
+			errs = append(errs, fmt.Errorf("pipeline %s: duplicate name", name))
+		}
+		names[name] = true
+		if len(p.Sinks) > 0 && p.Subject != "" {
+			// The subscription delivers each subject to one handler
+			if other, ok := consumed[p.Subject]; ok {
+				errs = append(errs, fmt.Errorf("pipeline %s: subject %s already has sinks in pipeline %s", name, p.Subject, other))
+			}
+			consumed[p.Subject] = name
+		}
+
+		for _, err := range p.validate() {
+			errs = append(errs, fmt.Errorf("pipeline %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HasSources reports whether any pipeline has a source
+func (s *Spec) HasSources() bool {
+	for _, p := range s.Pipelines {
+		if p.Source != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSinks reports whether any pipeline has sinks
+func (s *Spec) HasSinks() bool {
+	for _, p := range s.Pipelines {
+		if len(p.Sinks) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks one pipeline
+func (p *PipelineSpec) validate() []error {
+	var errs []error
+	if p.Subject == "" {
+		errs = append(errs, fmt.Errorf("subject is required"))
+	}
+	if p.Source == nil && len(p.Sinks) == 0 {
+		errs = append(errs, fmt.Errorf("a source or at least one sink is required"))
+	}
+	if p.Source != nil {
+		if err := p.Source.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("source: %w", err))
+		}
+	}
+	for i, sink := range p.Sinks {
+		if err := sink.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("sink %d: %w", i+1, err))
+		}
+	}
+	return errs
+}
+
+// validate checks that exactly one source is set and complete
+func (s *SourceSpec) validate() error {
+	switch {
+	case count(s.File != nil, s.Glob != nil, s.Tail != nil) != 1:
+		return fmt.Errorf("exactly one of file, glob or tail is required")
+	case s.File != nil:
+		if s.File.Path == "" {
+			return fmt.Errorf("file: path is required")
+		}
+		return s.File.Schedule.validate()
+	case s.Glob != nil:
+		if s.Glob.Pattern == "" {
+			return fmt.Errorf("glob: pattern is required")
+		}
+		return s.Glob.Schedule.validate()
+	default:
+		if s.Tail.Path == "" {
+			return fmt.Errorf("tail: path is required")
+		}
+		if s.Tail.BatchSize < 0 {
+			return fmt.Errorf("tail: batch size cannot be negative, got %d", s.Tail.BatchSize)
+		}
+		return nil
+	}
+}
+
+// validate checks that exactly one sink is set and complete
+func (s *SinkSpec) validate() error {
+	switch {
+	case count(s.File != nil, s.HTTP != nil, s.Log != nil) != 1:
+		return fmt.Errorf("exactly one of file, http or log is required")
+	case s.File != nil && s.File.Dir == "":
+		return fmt.Errorf("file: dir is required")
+	case s.HTTP != nil && s.HTTP.URL == "":
+		return fmt.Errorf("http: url is required")
+	}
+	return nil
+}
+
+// validate checks the interval and cron expression of a schedule
+func (s Schedule) validate() error {
+	if s.Every < 0 {
+		return fmt.Errorf("every cannot be negative, got %v", s.Every)
+	}
+	if s.Every > 0 && s.Cron != "" {
+		return fmt.Errorf("every and cron cannot be combined")
+	}
+	if s.Cron != "" {
+		if _, err := publisher.ParseCron(s.Cron); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// count returns how many conditions hold
+func count(conditions ...bool) int {
+	n := 0
+	for _, c := range conditions {
+		if c {
+			n++
+		}
+	}
+	return n
+}
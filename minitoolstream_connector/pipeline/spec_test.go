@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(`
+local: /tmp/broker
+durable: exports
+pipelines:
+  - name: reports
+    subject: reports.daily
+    source:
+      glob: {pattern: "exports/*.csv", every: 1h}
+    sinks:
+      - file: {dir: /srv/reports}
+      - log: {}
+  - name: logs
+    subject: logs.nginx
+    source:
+      tail: {path: /var/log/nginx/access.log, batch_size: 10}
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if spec.Local != "/tmp/broker" || spec.Durable != "exports" || len(spec.Pipelines) != 2 {
+		t.Fatalf("unexpected spec: %+v", spec)
+	}
+	reports := spec.Pipelines[0]
+	if reports.Source.Glob == nil || reports.Source.Glob.Every != time.Hour {
+		t.Errorf("expected hourly glob source, got %+v", reports.Source)
+	}
+	if len(reports.Sinks) != 2 || reports.Sinks[0].File == nil || reports.Sinks[1].Log == nil {
+		t.Errorf("expected file and log sinks, got %+v", reports.Sinks)
+	}
+	if tail := spec.Pipelines[1].Source.Tail; tail == nil || tail.BatchSize != 10 {
+		t.Errorf("expected tail source with batch size 10, got %+v", spec.Pipelines[1].Source)
+	}
+	if !spec.HasSources() || !spec.HasSinks() {
+		t.Error("expected sources and sinks")
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"unknown field", "pipelines:\n  - name: a\n    subjct: s\n", "field subjct not found"},
+		{"no pipelines", "ingress: localhost:50051\n", "at least one pipeline is required"},
+		{"local with ingress", "local: d\ningress: a:1\npipelines:\n  - {name: a, subject: s, sinks: [log: {}]}\n", "cannot be combined"},
+		{"missing name", "pipelines:\n  - {subject: s, sinks: [log: {}]}\n", "pipeline #1: name is required"},
+		{"duplicate name", "pipelines:\n  - {name: a, subject: s, sinks: [log: {}]}\n  - {name: a, subject: t, sinks: [log: {}]}\n", "duplicate name"},
+		{"missing subject", "pipelines:\n  - {name: a, sinks: [log: {}]}\n", "subject is required"},
+		{"nothing to do", "pipelines:\n  - {name: a, subject: s}\n", "a source or at least one sink is required"},
+		{"two sources", "pipelines:\n  - name: a\n    subject: s\n    source: {file: {path: f}, tail: {path: f}}\n", "exactly one of file, glob or tail"},
+		{"empty sink", "pipelines:\n  - {name: a, subject: s, sinks: [{}]}\n", "sink 1: exactly one of file, http or log"},
+		{"missing dir", "pipelines:\n  - {name: a, subject: s, sinks: [file: {}]}\n", "file: dir is required"},
+		{"shared sink subject", "pipelines:\n  - {name: a, subject: s, sinks: [log: {}]}\n  - {name: b, subject: s, sinks: [log: {}]}\n", "already has sinks in pipeline a"},
+		{"bad cron", "pipelines:\n  - name: a\n    subject: s\n    source: {glob: {pattern: '*', cron: 'nope'}}\n", "pipeline a: source"},
+		{"every and cron", "pipelines:\n  - name: a\n    subject: s\n    source: {file: {path: f, every: 1m, cron: '* * * * *'}}\n", "cannot be combined"},
+		{"negative batch", "pipelines:\n  - name: a\n    subject: s\n    source: {tail: {path: f, batch_size: -1}}\n", "cannot be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	path := filepath.Join(t.TempDir(), "pipelines.yaml")
+	if err := os.WriteFile(path, []byte("pipelines: []\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Load(path)
+	if err == nil || !strings.HasPrefix(err.Error(), path+":") {
+		t.Errorf("expected error prefixed with the path, got %v", err)
+	}
+}
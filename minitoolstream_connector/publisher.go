@@ -1,11 +1,19 @@
 package minitoolstream_connector
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"time"
 
+	"github.com/nats-io/nats.go"
+	"golang.org/x/oauth2"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/endpoints"
 	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
 	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/publisher"
 )
@@ -31,6 +39,95 @@ type ResultHandler = domain.ResultHandler
 // ResultHandlerFunc re-exports domain.ResultHandlerFunc
 type ResultHandlerFunc = domain.ResultHandlerFunc
 
+// FanOutResult re-exports domain.FanOutResult
+type FanOutResult = domain.FanOutResult
+
+// PublishAllError re-exports domain.PublishAllError
+type PublishAllError = domain.PublishAllError
+
+// PublishFailure re-exports domain.PublishFailure
+type PublishFailure = domain.PublishFailure
+
+// PublishOutcome re-exports domain.PublishOutcome
+type PublishOutcome = domain.PublishOutcome
+
+// MessageResultHandler re-exports domain.MessageResultHandler
+type MessageResultHandler = domain.MessageResultHandler
+
+// MessageResultHandlerFunc re-exports domain.MessageResultHandlerFunc
+type MessageResultHandlerFunc = domain.MessageResultHandlerFunc
+
+// IngressClient re-exports domain.IngressClient
+type IngressClient = domain.IngressClient
+
+// Schedule re-exports domain.Schedule
+type Schedule = domain.Schedule
+
+// Clock re-exports domain.Clock
+type Clock = domain.Clock
+
+// SystemClock is the Clock backed by the time package
+var SystemClock = domain.SystemClock
+
+// LogLevel re-exports domain.LogLevel
+type LogLevel = domain.LogLevel
+
+// Log levels
+const (
+	LogLevelDebug = domain.LogLevelDebug
+	LogLevelInfo  = domain.LogLevelInfo
+	LogLevelError = domain.LogLevelError
+	LogLevelOff   = domain.LogLevelOff
+)
+
+// ParseLogLevel parses "debug", "info", "error" or "off"
+var ParseLogLevel = domain.ParseLogLevel
+
+// Log formatters
+type (
+	LogFormatter      = domain.LogFormatter
+	EmojiLogFormatter = domain.EmojiLogFormatter
+	PlainLogFormatter = domain.PlainLogFormatter
+	JSONLogFormatter  = domain.JSONLogFormatter
+)
+
+// ParseLogFormatter returns the formatter named "emoji", "plain" or "json"
+var ParseLogFormatter = domain.ParseLogFormatter
+
+// Publish schedules
+var (
+	Every     = publisher.Every
+	ParseCron = publisher.ParseCron
+)
+
+// Deadlines re-exports the gRPC client deadline policy
+type Deadlines = grpcClient.Deadlines
+
+// DefaultDeadlines returns the deadlines applied when none are configured
+var DefaultDeadlines = grpcClient.DefaultDeadlines
+
+// Errors returned by publishers and subscribers, matched with errors.Is
+var (
+	ErrPayloadTooLarge   = domain.ErrPayloadTooLarge
+	ErrUnavailable       = domain.ErrUnavailable
+	ErrUnauthenticated   = domain.ErrUnauthenticated
+	ErrResourceExhausted = domain.ErrResourceExhausted
+	ErrNotFound          = domain.ErrNotFound
+)
+
+// PublisherSettings re-exports the publisher settings changed by Reconfigure
+type PublisherSettings = publisher.Settings
+
+// PublisherReconfigurer is implemented by publishers whose settings can be
+// changed while they run
+type PublisherReconfigurer interface {
+	Settings() PublisherSettings
+	Reconfigure(settings PublisherSettings) error
+}
+
+// ContentDedupKey derives a dedup key from the subject and data of a message
+var ContentDedupKey = publisher.ContentDedupKey
+
 // NewPublisher creates a new publisher with default configuration
 func NewPublisher(serverAddr string, opts ...grpc.DialOption) (Publisher, error) {
 	if serverAddr == "" {
@@ -55,12 +152,48 @@ func NewPublisher(serverAddr string, opts ...grpc.DialOption) (Publisher, error)
 	return pub, nil
 }
 
+// NewPublisherWithClient creates a new publisher on top of a caller-supplied
+// client, e.g. an instrumented, pooled or fake implementation
+func NewPublisherWithClient(client IngressClient) (Publisher, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client is required")
+	}
+
+	pub, err := publisher.New(&publisher.Config{
+		Client: client,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create publisher: %w", err)
+	}
+
+	return pub, nil
+}
+
 // PublisherBuilder provides a fluent interface for building publishers
 type PublisherBuilder struct {
-	serverAddr    string
-	dialOpts      []grpc.DialOption
-	resultHandler domain.ResultHandler
-	err           error
+	dialSettings
+	transportSettings
+	endpointSettings
+	serverAddr     string
+	resultHandler  domain.ResultHandler
+	logger         publisher.Logger
+	idempotency    bool
+	correlationIDs bool
+	maxRetries     int
+	retryBackoff   time.Duration
+	messageTTL     time.Duration
+	dedupKeyFunc   func(msg *domain.PublishMessage) string
+	maxPayload     int
+	onOversize     func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishMessage, error)
+	compression    []publisher.CompressionRule
+	delta          []publisher.DeltaRule
+	rateLimit      float64
+	logLevel       domain.LogLevel
+	logSample      int
+	logSummary     time.Duration
+	logFormatter   domain.LogFormatter
+	clock          domain.Clock
+	err            error
 }
 
 // NewPublisherBuilder creates a new publisher builder
@@ -76,37 +209,321 @@ func (b *PublisherBuilder) WithDialOptions(opts ...grpc.DialOption) *PublisherBu
 	return b
 }
 
+// WithTransportCredentials sets the transport credentials (TLS) for the connection
+func (b *PublisherBuilder) WithTransportCredentials(creds credentials.TransportCredentials) *PublisherBuilder {
+	b.transportCreds = creds
+	return b
+}
+
+// WithPerRPCCredentials sets credentials attached to every RPC (tokens, API keys)
+func (b *PublisherBuilder) WithPerRPCCredentials(creds credentials.PerRPCCredentials) *PublisherBuilder {
+	b.perRPCCreds = creds
+	return b
+}
+
+// WithOAuth2 attaches bearer tokens from the token source to every RPC, e.g. for
+// services behind an identity-aware proxy. Tokens are refreshed when they expire
+// and require transport security.
+func (b *PublisherBuilder) WithOAuth2(tokenSource oauth2.TokenSource) *PublisherBuilder {
+	b.perRPCCreds = oauth2Credentials(tokenSource)
+	return b
+}
+
+// WithContextDialer sets the function used to open connections to the server,
+// e.g. through a SOCKS/HTTP proxy, an SSH tunnel or a service mesh. The dialer
+// receives the resolved address; prefix the server address with passthrough:///
+// to hand it the address unresolved.
+func (b *PublisherBuilder) WithContextDialer(dialer func(ctx context.Context, addr string) (net.Conn, error)) *PublisherBuilder {
+	b.contextDialer = dialer
+	return b
+}
+
+// WithUnaryInterceptor adds interceptors to unary calls, e.g. for auth or tracing.
+// Interceptors run in the order they were added, after any set via dial options.
+func (b *PublisherBuilder) WithUnaryInterceptor(interceptors ...grpc.UnaryClientInterceptor) *PublisherBuilder {
+	b.unary = append(b.unary, interceptors...)
+	return b
+}
+
+// WithStreamInterceptor adds interceptors to streaming calls, e.g. for auth or tracing.
+// Interceptors run in the order they were added, after any set via dial options.
+func (b *PublisherBuilder) WithStreamInterceptor(interceptors ...grpc.StreamClientInterceptor) *PublisherBuilder {
+	b.stream = append(b.stream, interceptors...)
+	return b
+}
+
+// WithLoadBalancingPolicy sets the client-side load balancing policy, e.g.
+// "round_robin" to spread calls over all addresses the server name resolves to
+// (use a dns:/// address to re-resolve as backends scale). It replaces a default
+// service config set via dial options; combine both with WithServiceConfig.
+func (b *PublisherBuilder) WithLoadBalancingPolicy(policy string) *PublisherBuilder {
+	b.lbPolicy = policy
+	return b
+}
+
+// WithServiceConfig sets the default gRPC service config JSON, e.g. retry policies
+func (b *PublisherBuilder) WithServiceConfig(config string) *PublisherBuilder {
+	b.serviceConfig = config
+	return b
+}
+
+// WithDeadlines sets the timeouts applied to gRPC calls whose context has no
+// deadline, replacing DefaultDeadlines; zero fields disable a timeout
+func (b *PublisherBuilder) WithDeadlines(deadlines Deadlines) *PublisherBuilder {
+	b.deadlines = &deadlines
+	return b
+}
+
+// WithTenant scopes the publisher to a tenant: subjects are prefixed with the
+// tenant on the server ("orders" becomes "acme.orders") and handlers never see
+// another tenant's subjects
+func (b *PublisherBuilder) WithTenant(tenant string) *PublisherBuilder {
+	b.tenant = tenant
+	return b
+}
+
+// WithJetStream connects directly to NATS JetStream instead of the gRPC services.
+// The server address is then a NATS URL and gRPC dial options are ignored.
+func (b *PublisherBuilder) WithJetStream(opts ...nats.Option) *PublisherBuilder {
+	b.jetStream = true
+	b.natsOpts = append(b.natsOpts, opts...)
+	return b
+}
+
+// WithHTTPEndpoints serves /healthz, /readyz and /metrics for the publisher on
+// addr, e.g. ":8081", until it is closed
+func (b *PublisherBuilder) WithHTTPEndpoints(addr string) *PublisherBuilder {
+	b.httpAddr = addr
+	return b
+}
+
 // WithResultHandler sets a custom result handler
 func (b *PublisherBuilder) WithResultHandler(handler domain.ResultHandler) *PublisherBuilder {
 	b.resultHandler = handler
 	return b
 }
 
+// WithLogger sets a custom logger
+func (b *PublisherBuilder) WithLogger(logger publisher.Logger) *PublisherBuilder {
+	b.logger = logger
+	return b
+}
+
+// WithIdempotencyKeys enables idempotency-key headers so retried publishes can be deduplicated
+func (b *PublisherBuilder) WithIdempotencyKeys() *PublisherBuilder {
+	b.idempotency = true
+	return b
+}
+
+// WithCorrelationIDs attaches a generated correlation-id header to messages
+// that don't carry one, so they can be followed across services
+func (b *PublisherBuilder) WithCorrelationIDs() *PublisherBuilder {
+	b.correlationIDs = true
+	return b
+}
+
+// WithRetry sets the number of publish retries and the initial backoff between them
+func (b *PublisherBuilder) WithRetry(maxRetries int, backoff time.Duration) *PublisherBuilder {
+	b.maxRetries = maxRetries
+	b.retryBackoff = backoff
+	return b
+}
+
+// WithMessageTTL sets an expires-at header on every message that doesn't already carry one
+func (b *PublisherBuilder) WithMessageTTL(ttl time.Duration) *PublisherBuilder {
+	b.messageTTL = ttl
+	return b
+}
+
+// WithDedupKeyFunc derives the dedup key of messages that don't carry one,
+// e.g. ContentDedupKey
+func (b *PublisherBuilder) WithDedupKeyFunc(fn func(msg *PublishMessage) string) *PublisherBuilder {
+	b.dedupKeyFunc = fn
+	return b
+}
+
+// WithMaxPayloadSize rejects messages with more than size bytes of data with ErrPayloadTooLarge
+func (b *PublisherBuilder) WithMaxPayloadSize(size int) *PublisherBuilder {
+	b.maxPayload = size
+	return b
+}
+
+// WithOversizeHandler replaces messages over the payload size limit with the
+// message returned by fn instead of rejecting them
+func (b *PublisherBuilder) WithOversizeHandler(fn func(ctx context.Context, msg *PublishMessage) (*PublishMessage, error)) *PublisherBuilder {
+	b.onOversize = fn
+	return b
+}
+
+// WithClaimCheck publishes payloads over the claim check's threshold by
+// reference: they are written to its object store and a pointer message is
+// published instead. It sets the payload size limit to the threshold and
+// replaces any oversize handler.
+func (b *PublisherBuilder) WithClaimCheck(claimCheck *ClaimCheck) *PublisherBuilder {
+	b.maxPayload = claimCheck.Threshold()
+	b.onOversize = claimCheck.Offload
+	return b
+}
+
+// WithCompression compresses payloads by subject. Rules are tried in order
+// and the first one matching a subject decides, e.g.
+//
+//	WithCompression(
+//	    CompressionRule{Subject: "telemetry.*", Compressor: ZstdCompressor{}, MinSize: 1024},
+//	    CompressionRule{Subject: "images.*"}, // already compressed
+//	    CompressionRule{Compressor: GzipCompressor{}, MinSize: 4096},
+//	)
+func (b *PublisherBuilder) WithCompression(rules ...CompressionRule) *PublisherBuilder {
+	b.compression = append(b.compression, rules...)
+	return b
+}
+
+// WithDelta sends the payloads of matching subjects as deltas against their
+// last full snapshot; subscribers restore them with a DeltaReconstructor, e.g.
+//
+//	WithDelta(DeltaRule{Subject: "documents.*", Differ: JSONPatchDiffer{}, SnapshotEvery: 50})
+func (b *PublisherBuilder) WithDelta(rules ...DeltaRule) *PublisherBuilder {
+	b.delta = append(b.delta, rules...)
+	return b
+}
+
+// WithRateLimit caps publishes per second
+func (b *PublisherBuilder) WithRateLimit(perSecond float64) *PublisherBuilder {
+	b.rateLimit = perSecond
+	return b
+}
+
+// WithLogLevel drops log lines below the level
+func (b *PublisherBuilder) WithLogLevel(level LogLevel) *PublisherBuilder {
+	b.logLevel = level
+	return b
+}
+
+// WithLogSampling logs only one in every N per-message lines and reports
+// how many were suppressed at most once per summaryInterval (0 disables the
+// summary), for high-throughput deployments
+func (b *PublisherBuilder) WithLogSampling(every int, summaryInterval time.Duration) *PublisherBuilder {
+	b.logSample = every
+	b.logSummary = summaryInterval
+	return b
+}
+
+// WithLogFormatter renders log lines with formatter, e.g.
+// PlainLogFormatter{} or JSONLogFormatter{} for log pipelines
+func (b *PublisherBuilder) WithLogFormatter(formatter LogFormatter) *PublisherBuilder {
+	b.logFormatter = formatter
+	return b
+}
+
+// WithClock sets the clock used for TTLs, retry backoff and schedules
+func (b *PublisherBuilder) WithClock(clock Clock) *PublisherBuilder {
+	b.clock = clock
+	return b
+}
+
+// Validate checks the builder configuration without connecting to the server.
+// All problems found are returned together.
+func (b *PublisherBuilder) Validate() error {
+	var errs []error
+	if err := b.validateTarget(b.serverAddr, &b.dialSettings); err != nil {
+		errs = append(errs, err)
+	}
+	if b.maxRetries < 0 {
+		errs = append(errs, fmt.Errorf("max retries cannot be negative, got %d", b.maxRetries))
+	}
+	if b.maxPayload < 0 {
+		errs = append(errs, fmt.Errorf("max payload size cannot be negative, got %d", b.maxPayload))
+	}
+	if b.onOversize != nil && b.maxPayload == 0 {
+		errs = append(errs, fmt.Errorf("oversize handler requires a max payload size"))
+	}
+	if b.messageTTL < 0 {
+		errs = append(errs, fmt.Errorf("message TTL cannot be negative, got %v", b.messageTTL))
+	}
+	if b.rateLimit < 0 {
+		errs = append(errs, fmt.Errorf("rate limit cannot be negative, got %v", b.rateLimit))
+	}
+	if err := publisher.ValidateCompression(b.compression); err != nil {
+		errs = append(errs, err)
+	}
+	if err := publisher.ValidateDelta(b.delta); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateLogLevel(b.logLevel); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateLogSampling(b.logSample, b.logSummary); err != nil {
+		errs = append(errs, err)
+	}
+	if err := b.endpointSettings.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
 // Build creates the publisher instance
 func (b *PublisherBuilder) Build() (Publisher, error) {
 	if b.err != nil {
 		return nil, b.err
 	}
 
-	if b.serverAddr == "" {
-		return nil, fmt.Errorf("server address is required")
+	if err := b.Validate(); err != nil {
+		return nil, err
 	}
 
-	// Create gRPC client
-	client, err := grpcClient.NewIngressClient(b.serverAddr, b.dialOpts...)
+	client, err := b.ingressClient(b.serverAddr, &b.dialSettings)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+		return nil, err
+	}
+
+	var pub *publisher.SimplePublisher
+	server, err := b.newEndpoints(
+		nil,
+		func() error { return pub.Ready() },
+		func() []endpoints.Metric { return publisherMetrics(pub.Stats()) },
+		b.logger,
+	)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	if server != nil {
+		client = &closingIngressClient{IngressClient: client, closer: server}
 	}
 
 	// Create publisher
-	pub, err := publisher.New(&publisher.Config{
-		Client:        client,
-		ResultHandler: b.resultHandler,
+	pub, err = publisher.New(&publisher.Config{
+		Client:             client,
+		ResultHandler:      b.resultHandler,
+		Logger:             b.logger,
+		IdempotencyKeys:    b.idempotency,
+		CorrelationIDs:     b.correlationIDs,
+		MaxRetries:         b.maxRetries,
+		RetryBackoff:       b.retryBackoff,
+		MessageTTL:         b.messageTTL,
+		DedupKeyFunc:       b.dedupKeyFunc,
+		MaxPayloadSize:     b.maxPayload,
+		OnOversize:         b.onOversize,
+		Compression:        b.compression,
+		Delta:              b.delta,
+		RateLimit:          b.rateLimit,
+		LogLevel:           b.logLevel,
+		LogSampleEvery:     b.logSample,
+		LogSummaryInterval: b.logSummary,
+		LogFormatter:       b.logFormatter,
+		Clock:              b.clock,
 	})
 	if err != nil {
 		client.Close()
 		return nil, fmt.Errorf("failed to create publisher: %w", err)
 	}
 
+	if server != nil {
+		if err := server.Start(b.httpAddr); err != nil {
+			pub.Close()
+			return nil, err
+		}
+	}
+
 	return pub, nil
 }
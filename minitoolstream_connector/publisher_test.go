@@ -3,6 +3,7 @@ package minitoolstream_connector
 import (
 	"context"
 	"testing"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -137,3 +138,146 @@ func TestPublisherBuilder_FullChain(t *testing.T) {
 		}
 	})
 }
+
+func TestPublisherBuilder_WithIdempotencyKeys(t *testing.T) {
+	t.Run("enable idempotency and retry", func(t *testing.T) {
+		builder := NewPublisherBuilder("localhost:9090").
+			WithIdempotencyKeys().
+			WithRetry(3, 50*time.Millisecond)
+
+		if !builder.idempotency {
+			t.Error("expected idempotency keys to be enabled")
+		}
+		if builder.maxRetries != 3 {
+			t.Errorf("expected 3 retries, got %d", builder.maxRetries)
+		}
+		if builder.retryBackoff != 50*time.Millisecond {
+			t.Errorf("expected 50ms backoff, got %v", builder.retryBackoff)
+		}
+	})
+}
+
+func TestPublisherBuilder_WithMessageTTL(t *testing.T) {
+	t.Run("set message ttl", func(t *testing.T) {
+		builder := NewPublisherBuilder("localhost:9090").WithMessageTTL(time.Minute)
+		if builder.messageTTL != time.Minute {
+			t.Errorf("expected 1m ttl, got %v", builder.messageTTL)
+		}
+	})
+}
+
+func TestPublisherBuilder_WithDedupKeyFunc(t *testing.T) {
+	builder := NewPublisherBuilder("localhost:9090").WithDedupKeyFunc(ContentDedupKey)
+	if builder.dedupKeyFunc == nil {
+		t.Error("expected dedup key func to be set")
+	}
+}
+
+type testPubLogger struct {
+	messages []string
+}
+
+func (l *testPubLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, format)
+}
+
+func TestPublisherBuilder_WithLogger(t *testing.T) {
+	t.Run("set logger", func(t *testing.T) {
+		logger := &testPubLogger{}
+		builder := NewPublisherBuilder("localhost:9090").WithLogger(logger)
+
+		if builder.logger != logger {
+			t.Error("expected custom logger to be set")
+		}
+	})
+
+	t.Run("logger is used by built publisher", func(t *testing.T) {
+		logger := &testPubLogger{}
+		pub, err := NewPublisherBuilder("localhost:9090").WithLogger(logger).Build()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		pub.Close()
+
+		if len(logger.messages) == 0 {
+			t.Error("expected publisher to log through the custom logger")
+		}
+	})
+}
+
+type fakeIngressClient struct {
+	published []*PublishMessage
+	closed    bool
+}
+
+func (c *fakeIngressClient) Publish(ctx context.Context, msg *PublishMessage) (*PublishResult, error) {
+	c.published = append(c.published, msg)
+	return &PublishResult{Sequence: uint64(len(c.published))}, nil
+}
+
+func (c *fakeIngressClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestNewPublisherWithClient(t *testing.T) {
+	t.Run("nil client", func(t *testing.T) {
+		pub, err := NewPublisherWithClient(nil)
+		if err == nil {
+			t.Fatal("expected error for nil client")
+		}
+		if pub != nil {
+			t.Error("expected nil publisher")
+		}
+	})
+
+	t.Run("publishes through custom client", func(t *testing.T) {
+		client := &fakeIngressClient{}
+		pub, err := NewPublisherWithClient(client)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		err = pub.Publish(context.Background(), MessagePreparerFunc(func(ctx context.Context) (*PublishMessage, error) {
+			return &PublishMessage{Subject: "test", Data: []byte("data")}, nil
+		}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(client.published) != 1 {
+			t.Errorf("expected 1 published message, got %d", len(client.published))
+		}
+
+		pub.Close()
+		if !client.closed {
+			t.Error("expected client to be closed")
+		}
+	})
+}
+
+func TestPublisherBuilder_WithMaxPayloadSize(t *testing.T) {
+	builder := NewPublisherBuilder("localhost:9090").WithMaxPayloadSize(1024)
+	if builder.maxPayload != 1024 {
+		t.Errorf("expected 1024, got %d", builder.maxPayload)
+	}
+
+	if err := NewPublisherBuilder("localhost:9090").WithMaxPayloadSize(-1).Validate(); err == nil {
+		t.Error("expected error for negative size")
+	}
+
+	handler := func(ctx context.Context, msg *PublishMessage) (*PublishMessage, error) { return msg, nil }
+	if err := NewPublisherBuilder("localhost:9090").WithOversizeHandler(handler).Validate(); err == nil {
+		t.Error("expected error for oversize handler without size limit")
+	}
+}
+
+func TestPublisherBuilder_WithClaimCheck(t *testing.T) {
+	claimCheck, _ := NewClaimCheck(&ClaimCheckConfig{Store: NewMemoryObjectStore(), Threshold: 4096})
+	builder := NewPublisherBuilder("localhost:9090").WithClaimCheck(claimCheck)
+	if builder.maxPayload != 4096 || builder.onOversize == nil {
+		t.Errorf("expected the claim check threshold and oversize handler, got %d", builder.maxPayload)
+	}
+	if err := builder.Validate(); err != nil {
+		t.Errorf("Validate failed: %v", err)
+	}
+}
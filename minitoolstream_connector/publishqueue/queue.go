@@ -0,0 +1,515 @@
+// Package publishqueue puts a write-ahead log in front of a domain.IngressClient.
+//
+// Enqueue appends the message to a log file and returns; a background pump
+// publishes queued messages in order, retrying failed publishes with backoff.
+// Acknowledgements are appended to the same log, so messages that were not
+// acknowledged before a crash are published again when the queue is reopened.
+// A message the server rejects, or that still fails after MaxAttempts tries, is
+// moved to a failed log next to the queue log so it does not block the queue.
+// Every message gets an idempotency key, letting the ingest layer drop the
+// duplicates such a replay can produce. A Queue assumes it is the only user of
+// its directory.
+package publishqueue
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+const (
+	logFile    = "queue.log"
+	failedFile = "failed.log"
+)
+
+// Log record operations
+const (
+	opPut = "put"
+	opAck = "ack"
+)
+
+// Config represents publish queue configuration
+type Config struct {
+	// Dir holds the queue log; it is created if missing
+	Dir    string
+	Client domain.IngressClient
+	// RetryBackoff is the initial delay after a failed publish, doubled up to
+	// MaxBackoff (default 500ms)
+	RetryBackoff time.Duration
+	// MaxBackoff caps the delay between retries (default 30s)
+	MaxBackoff time.Duration
+	// CompactAfter is the number of acknowledged entries after which the log is
+	// rewritten without them (default 1000)
+	CompactAfter int
+	// MaxAttempts is the number of publishes tried before a message is moved to
+	// the failed log (default 0, retry until published). Messages the server
+	// rejects are moved there on the first attempt.
+	MaxAttempts int
+	Logger      Logger
+	// Clock stamps generated idempotency keys and times retry backoff
+	// (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// Logger defines the logging interface
+type Logger = domain.Logger
+
+// entry is a queued message
+type entry struct {
+	ID      uint64            `json:"id"`
+	Subject string            `json:"subject,omitempty"`
+	Data    []byte            `json:"data,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// record is the on-disk form of a log line
+type record struct {
+	Op string `json:"op"`
+	entry
+}
+
+// FailedMessage is a message that was given up on and moved to the failed log
+type FailedMessage struct {
+	Message  *domain.PublishMessage
+	Error    string
+	FailedAt time.Time
+}
+
+// failedRecord is the on-disk form of a failed log line
+type failedRecord struct {
+	entry
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// Queue is a persistent publish queue
+type Queue struct {
+	client       domain.IngressClient
+	path         string
+	failedPath   string
+	retryBackoff time.Duration
+	maxBackoff   time.Duration
+	compactAfter int
+	maxAttempts  int
+	logger       Logger
+	clock        domain.Clock
+
+	mu      sync.Mutex
+	file    *os.File
+	pending []entry
+	nextID  uint64
+	acked   int
+	wake    chan struct{}
+}
+
+// New opens the queue in config.Dir, recovering entries that were not acknowledged
+func New(config *Config) (*Queue, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.Client == nil {
+		return nil, fmt.Errorf("client cannot be nil")
+	}
+
+	if config.Dir == "" {
+		return nil, fmt.Errorf("directory is required")
+	}
+
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = domain.DefaultLogger{}
+	}
+
+	retryBackoff := config.RetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = 500 * time.Millisecond
+	}
+
+	maxBackoff := config.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+
+	compactAfter := config.CompactAfter
+	if compactAfter <= 0 {
+		compactAfter = 1000
+	}
+
+	if config.MaxAttempts < 0 {
+		return nil, fmt.Errorf("max attempts cannot be negative")
+	}
+
+	q := &Queue{
+		client:       config.Client,
+		path:         filepath.Join(config.Dir, logFile),
+		failedPath:   filepath.Join(config.Dir, failedFile),
+		retryBackoff: retryBackoff,
+		maxBackoff:   maxBackoff,
+		compactAfter: compactAfter,
+		maxAttempts:  config.MaxAttempts,
+		logger:       logger,
+		clock:        domain.ClockOrSystem(config.Clock),
+		nextID:       1,
+		wake:         make(chan struct{}, 1),
+	}
+
+	if err := q.recover(); err != nil {
+		return nil, err
+	}
+	if len(q.pending) > 0 {
+		logger.Printf("✓ Recovered %d queued messages", len(q.pending))
+	}
+	return q, nil
+}
+
+// Enqueue durably stores a message for publishing and returns without waiting
+// for the server
+func (q *Queue) Enqueue(msg *domain.PublishMessage) error {
+	if msg == nil {
+		return fmt.Errorf("message cannot be nil")
+	}
+
+	wire := msg.WireHeaders()
+	headers := make(map[string]string, len(wire)+1)
+	for k, v := range wire {
+		headers[k] = v
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.file == nil {
+		return fmt.Errorf("queue is closed")
+	}
+
+	e := entry{ID: q.nextID, Subject: msg.Subject, Data: msg.Data, Headers: headers}
+	if e.Headers[domain.HeaderIdempotencyKey] == "" {
+		e.Headers[domain.HeaderIdempotencyKey] = fmt.Sprintf("queue-%d-%d", q.clock.Now().UnixNano(), e.ID)
+	}
+
+	if err := q.append(record{Op: opPut, entry: e}); err != nil {
+		return err
+	}
+	q.nextID++
+	q.pending = append(q.pending, e)
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Len returns the number of messages waiting to be published
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// Run publishes queued messages until ctx is cancelled
+func (q *Queue) Run(ctx context.Context) error {
+	backoff := q.retryBackoff
+	attempts := 0
+	for {
+		e, ok := q.head()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-q.wake:
+			}
+			continue
+		}
+
+		if err := q.publish(ctx, e); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			attempts++
+			if isPermanent(err) || (q.maxAttempts > 0 && attempts >= q.maxAttempts) {
+				q.logger.Printf("✗ Giving up on queued message %d after %d attempts: %v", e.ID, attempts, err)
+				if err := q.fail(e, err); err != nil {
+					return err
+				}
+				backoff = q.retryBackoff
+				attempts = 0
+				continue
+			}
+			q.logger.Printf("✗ Failed to publish queued message %d: %v (retrying in %v)", e.ID, err, backoff)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-q.clock.After(backoff):
+			}
+			backoff = min(backoff*2, q.maxBackoff)
+			continue
+		}
+		backoff = q.retryBackoff
+		attempts = 0
+
+		if err := q.ack(e.ID); err != nil {
+			return err
+		}
+	}
+}
+
+// Failed returns the messages moved to the failed log, oldest first
+func (q *Queue) Failed() ([]FailedMessage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	file, err := os.Open(q.failedPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open failed log: %w", err)
+	}
+	defer file.Close()
+
+	var failed []FailedMessage
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var rec failedRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			q.logger.Printf("✗ Skipping unreadable failed record: %v", err)
+			continue
+		}
+		failed = append(failed, FailedMessage{
+			Message:  &domain.PublishMessage{Subject: rec.Subject, Data: rec.Data, Headers: rec.Headers},
+			Error:    rec.Error,
+			FailedAt: rec.FailedAt,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read failed log: %w", err)
+	}
+	return failed, nil
+}
+
+// Close closes the queue log; unacknowledged messages stay queued
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.file == nil {
+		return nil
+	}
+	err := q.file.Close()
+	q.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close queue log: %w", err)
+	}
+	return nil
+}
+
+// publish sends one entry to the server
+func (q *Queue) publish(ctx context.Context, e entry) error {
+	result, err := q.client.Publish(ctx, &domain.PublishMessage{
+		Subject: e.Subject,
+		Data:    e.Data,
+		Headers: e.Headers,
+	})
+	if err != nil {
+		return err
+	}
+	if result.StatusCode != 0 {
+		return fmt.Errorf("%w: %s", errRejected, result.ErrorMessage)
+	}
+	return nil
+}
+
+// errRejected marks a publish the server answered with an error status
+var errRejected = errors.New("server error")
+
+// isPermanent reports whether retrying a publish that failed with err cannot succeed
+func isPermanent(err error) bool {
+	return errors.Is(err, errRejected) || errors.Is(err, domain.ErrPayloadTooLarge) || errors.Is(err, domain.ErrNotFound)
+}
+
+// fail moves the oldest entry to the failed log and acknowledges it
+func (q *Queue) fail(e entry, cause error) error {
+	line, err := json.Marshal(failedRecord{entry: e, Error: cause.Error(), FailedAt: q.clock.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to encode failed record: %w", err)
+	}
+	line = append(line, '\n')
+
+	q.mu.Lock()
+	file, err := os.OpenFile(q.failedPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err == nil {
+		_, err = file.Write(line)
+		if err == nil {
+			err = file.Sync()
+		}
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	q.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to write failed log: %w", err)
+	}
+	return q.ack(e.ID)
+}
+
+// head returns the oldest pending entry
+func (q *Queue) head() (entry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return entry{}, false
+	}
+	return q.pending[0], true
+}
+
+// ack records that the oldest entry was published and compacts the log when due
+func (q *Queue) ack(id uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.file == nil {
+		return fmt.Errorf("queue is closed")
+	}
+
+	if err := q.append(record{Op: opAck, entry: entry{ID: id}}); err != nil {
+		return err
+	}
+	q.pending = q.pending[1:]
+	q.acked++
+
+	if q.acked >= q.compactAfter {
+		return q.compact()
+	}
+	return nil
+}
+
+// append writes a record to the log and syncs it. The caller holds q.mu.
+func (q *Queue) append(rec record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode queue record: %w", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := q.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write queue log: %w", err)
+	}
+	if err := q.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync queue log: %w", err)
+	}
+	return nil
+}
+
+// recover replays the log and opens it for appending
+func (q *Queue) recover() error {
+	file, err := os.Open(q.path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to open queue log: %w", err)
+	}
+
+	if file != nil {
+		defer file.Close()
+
+		acked := make(map[uint64]bool)
+		var entries []entry
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		for scanner.Scan() {
+			var rec record
+			if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+				// A torn write at the end of the log is dropped
+				q.logger.Printf("✗ Skipping unreadable queue record: %v", err)
+				continue
+			}
+			switch rec.Op {
+			case opPut:
+				entries = append(entries, rec.entry)
+			case opAck:
+				acked[rec.ID] = true
+				q.acked++
+			}
+			if rec.ID >= q.nextID {
+				q.nextID = rec.ID + 1
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read queue log: %w", err)
+		}
+
+		for _, e := range entries {
+			if !acked[e.ID] {
+				q.pending = append(q.pending, e)
+			}
+		}
+	}
+
+	if q.acked > 0 {
+		return q.compact()
+	}
+	return q.openLog()
+}
+
+// compact rewrites the log with only the pending entries. The caller holds q.mu.
+func (q *Queue) compact() error {
+	tmp := q.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to compact queue log: %w", err)
+	}
+
+	w := bufio.NewWriter(file)
+	for _, e := range q.pending {
+		line, err := json.Marshal(record{Op: opPut, entry: e})
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("failed to encode queue record: %w", err)
+		}
+		w.Write(line)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to compact queue log: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to compact queue log: %w", err)
+	}
+	file.Close()
+
+	if q.file != nil {
+		q.file.Close()
+		q.file = nil
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		return fmt.Errorf("failed to compact queue log: %w", err)
+	}
+	q.acked = 0
+	return q.openLog()
+}
+
+// openLog opens the log for appending
+func (q *Queue) openLog() error {
+	file, err := os.OpenFile(q.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open queue log: %w", err)
+	}
+	q.file = file
+	return nil
+}
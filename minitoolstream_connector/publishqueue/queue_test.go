@@ -0,0 +1,310 @@
+package publishqueue
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type testLogger struct{}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {}
+
+// mockIngressClient records published messages, fails the first failures calls
+// and rejects messages on the rejected subjects
+type mockIngressClient struct {
+	mu        sync.Mutex
+	failures  int
+	rejected  map[string]bool
+	published []*domain.PublishMessage
+	notify    chan struct{}
+}
+
+func newMockIngressClient(failures int) *mockIngressClient {
+	return &mockIngressClient{failures: failures, notify: make(chan struct{}, 100)}
+}
+
+func (m *mockIngressClient) Publish(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failures > 0 {
+		m.failures--
+		return nil, errors.New("unavailable")
+	}
+	if m.rejected[msg.Subject] {
+		return &domain.PublishResult{StatusCode: 1, ErrorMessage: "subject not allowed"}, nil
+	}
+	m.published = append(m.published, msg)
+	m.notify <- struct{}{}
+	return &domain.PublishResult{Sequence: uint64(len(m.published))}, nil
+}
+
+func (m *mockIngressClient) Close() error { return nil }
+
+func (m *mockIngressClient) subjects() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var subjects []string
+	for _, msg := range m.published {
+		subjects = append(subjects, msg.Subject)
+	}
+	return subjects
+}
+
+func waitPublished(t *testing.T, client *mockIngressClient, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-client.notify:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %d publishes, got %d", n, i)
+		}
+	}
+}
+
+func newTestQueue(t *testing.T, dir string, client domain.IngressClient) *Queue {
+	t.Helper()
+	q, err := New(&Config{Dir: dir, Client: client, RetryBackoff: time.Millisecond, Logger: &testLogger{}})
+	if err != nil {
+		t.Fatalf("failed to open queue: %v", err)
+	}
+	return q
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Error("expected error for nil config")
+	}
+	if _, err := New(&Config{Dir: t.TempDir()}); err == nil {
+		t.Error("expected error for nil client")
+	}
+	if _, err := New(&Config{Client: newMockIngressClient(0)}); err == nil {
+		t.Error("expected error for missing directory")
+	}
+}
+
+func TestQueue_Run(t *testing.T) {
+	client := newMockIngressClient(2)
+	q := newTestQueue(t, t.TempDir(), client)
+	defer q.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+
+	for _, subject := range []string{"a", "b", "c"} {
+		if err := q.Enqueue(&domain.PublishMessage{Subject: subject}); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+	waitPublished(t, client, 3)
+
+	subjects := client.subjects()
+	if len(subjects) != 3 || subjects[0] != "a" || subjects[1] != "b" || subjects[2] != "c" {
+		t.Errorf("expected in-order publishes, got %v", subjects)
+	}
+	if client.published[0].Headers[domain.HeaderIdempotencyKey] == "" {
+		t.Error("expected idempotency key")
+	}
+}
+
+func TestQueue_Clock(t *testing.T) {
+	client := newMockIngressClient(2)
+	clock := connectortest.NewFakeClock(time.Unix(100, 0))
+	q, err := New(&Config{Dir: t.TempDir(), Client: client, RetryBackoff: time.Minute, MaxBackoff: time.Hour, Logger: &testLogger{}, Clock: clock})
+	if err != nil {
+		t.Fatalf("failed to open queue: %v", err)
+	}
+	defer q.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+
+	if err := q.Enqueue(&domain.PublishMessage{Subject: "a"}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	clock.WaitForWaiters(t, 1, 2*time.Second)
+	clock.Advance(time.Minute)
+	clock.WaitForWaiters(t, 1, 2*time.Second)
+	clock.AdvanceToNext()
+	if want := time.Unix(100, 0).Add(3 * time.Minute); !clock.Now().Equal(want) {
+		t.Errorf("expected the backoff to double to 2m, now %v", clock.Now())
+	}
+	waitPublished(t, client, 1)
+
+	if key := client.published[0].Headers[domain.HeaderIdempotencyKey]; key != "queue-100000000000-1" {
+		t.Errorf("expected key stamped by the clock, got %q", key)
+	}
+}
+
+func TestQueue_Failed(t *testing.T) {
+	t.Run("rejected messages do not block the queue", func(t *testing.T) {
+		dir := t.TempDir()
+		client := newMockIngressClient(0)
+		client.rejected = map[string]bool{"bad": true}
+		q := newTestQueue(t, dir, client)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go q.Run(ctx)
+
+		for _, subject := range []string{"bad", "good"} {
+			if err := q.Enqueue(&domain.PublishMessage{Subject: subject, Data: []byte(subject)}); err != nil {
+				t.Fatalf("Enqueue failed: %v", err)
+			}
+		}
+		waitPublished(t, client, 1)
+		cancel()
+		q.Close()
+
+		if subjects := client.subjects(); len(subjects) != 1 || subjects[0] != "good" {
+			t.Errorf("expected only good to be published, got %v", subjects)
+		}
+
+		reopened := newTestQueue(t, dir, client)
+		defer reopened.Close()
+		if reopened.Len() != 0 {
+			t.Errorf("expected no pending messages after reopening, got %d", reopened.Len())
+		}
+		failed, err := reopened.Failed()
+		if err != nil {
+			t.Fatalf("Failed returned error: %v", err)
+		}
+		if len(failed) != 1 || failed[0].Message.Subject != "bad" || string(failed[0].Message.Data) != "bad" {
+			t.Fatalf("expected bad in the failed log, got %+v", failed)
+		}
+		if !strings.Contains(failed[0].Error, "subject not allowed") {
+			t.Errorf("expected the server error to be recorded, got %q", failed[0].Error)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		client := newMockIngressClient(2)
+		q, err := New(&Config{Dir: t.TempDir(), Client: client, RetryBackoff: time.Millisecond, MaxAttempts: 2, Logger: &testLogger{}})
+		if err != nil {
+			t.Fatalf("failed to open queue: %v", err)
+		}
+		defer q.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go q.Run(ctx)
+
+		for _, subject := range []string{"a", "b"} {
+			if err := q.Enqueue(&domain.PublishMessage{Subject: subject}); err != nil {
+				t.Fatalf("Enqueue failed: %v", err)
+			}
+		}
+		waitPublished(t, client, 1)
+
+		if subjects := client.subjects(); len(subjects) != 1 || subjects[0] != "b" {
+			t.Errorf("expected only b to be published, got %v", subjects)
+		}
+		failed, err := q.Failed()
+		if err != nil {
+			t.Fatalf("Failed returned error: %v", err)
+		}
+		if len(failed) != 1 || failed[0].Message.Subject != "a" {
+			t.Errorf("expected a in the failed log, got %+v", failed)
+		}
+	})
+
+	t.Run("rejects negative max attempts", func(t *testing.T) {
+		if _, err := New(&Config{Dir: t.TempDir(), Client: newMockIngressClient(0), MaxAttempts: -1}); err == nil {
+			t.Error("expected error for negative max attempts")
+		}
+	})
+}
+
+func TestQueue_Recovery(t *testing.T) {
+	dir := t.TempDir()
+
+	// Publish one message, leave two unpublished
+	first := newMockIngressClient(0)
+	q := newTestQueue(t, dir, first)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+
+	q.Enqueue(&domain.PublishMessage{Subject: "a"})
+	waitPublished(t, first, 1)
+	cancel()
+	<-done
+
+	q.Enqueue(&domain.PublishMessage{Subject: "b", Headers: map[string]string{"k": "v"}})
+	q.Enqueue(&domain.PublishMessage{Subject: "c"})
+	q.Close()
+
+	// Reopen, as after a restart
+	second := newMockIngressClient(0)
+	q = newTestQueue(t, dir, second)
+	defer q.Close()
+
+	if q.Len() != 2 {
+		t.Fatalf("expected 2 recovered messages, got %d", q.Len())
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx)
+	waitPublished(t, second, 2)
+
+	subjects := second.subjects()
+	if subjects[0] != "b" || subjects[1] != "c" {
+		t.Errorf("expected recovered messages in order, got %v", subjects)
+	}
+	if second.published[0].Headers["k"] != "v" {
+		t.Errorf("expected headers to survive recovery, got %v", second.published[0].Headers)
+	}
+	if err := q.Enqueue(&domain.PublishMessage{Subject: "d"}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	waitPublished(t, second, 1)
+}
+
+func TestQueue_Compaction(t *testing.T) {
+	dir := t.TempDir()
+	client := newMockIngressClient(0)
+	q, err := New(&Config{Dir: dir, Client: client, CompactAfter: 2, Logger: &testLogger{}})
+	if err != nil {
+		t.Fatalf("failed to open queue: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+	for i := 0; i < 5; i++ {
+		q.Enqueue(&domain.PublishMessage{Subject: "a"})
+	}
+	waitPublished(t, client, 5)
+	cancel()
+	<-done
+	q.Close()
+
+	q = newTestQueue(t, dir, newMockIngressClient(0))
+	defer q.Close()
+	if q.Len() != 0 {
+		t.Errorf("expected empty queue after compaction, got %d", q.Len())
+	}
+}
+
+func TestQueue_Closed(t *testing.T) {
+	q := newTestQueue(t, t.TempDir(), newMockIngressClient(0))
+	q.Close()
+	if err := q.Enqueue(&domain.PublishMessage{Subject: "a"}); err == nil {
+		t.Error("expected error after Close")
+	}
+}
@@ -0,0 +1,68 @@
+package minitoolstream_connector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// DefaultDrainTimeout bounds how long Run waits for buffered messages to be
+// handled after shutdown is requested
+const DefaultDrainTimeout = 30 * time.Second
+
+// Run starts the subscriber and blocks until ctx is cancelled or the process
+// receives SIGINT or SIGTERM, then drains it. A second signal during the drain
+// stops the subscriber immediately. Run returns nil after a clean shutdown and
+// an error if the subscriber fails to start, its subscriptions end on their
+// own or the drain does not finish in DefaultDrainTimeout.
+func Run(ctx context.Context, sub Subscriber) error {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	return runSubscriber(ctx, sub, signals, DefaultDrainTimeout)
+}
+
+// runSubscriber implements Run with the signal channel and drain timeout
+// supplied by the caller
+func runSubscriber(ctx context.Context, sub Subscriber, signals <-chan os.Signal, drainTimeout time.Duration) error {
+	if sub == nil {
+		return fmt.Errorf("subscriber is required")
+	}
+
+	// Shutdown goes through Drain below, so ctx must not stop the
+	// subscriptions on its own
+	if err := sub.Start(context.WithoutCancel(ctx)); err != nil {
+		sub.Stop()
+		return fmt.Errorf("failed to start subscriber: %w", err)
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		sub.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		sub.Stop()
+		return fmt.Errorf("subscriptions ended before shutdown was requested")
+	case <-ctx.Done():
+	case <-signals:
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-drainCtx.Done():
+		}
+	}()
+
+	return sub.Drain(drainCtx)
+}
@@ -0,0 +1,156 @@
+package minitoolstream_connector
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// runTestSubscriber is a Subscriber whose subscriptions run until Drain or
+// Stop, with Drain blocking until drainDone is closed
+type runTestSubscriber struct {
+	Subscriber
+	startErr  error
+	drainDone chan struct{}
+
+	mu      sync.Mutex
+	started bool
+	drained bool
+	stopped bool
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newRunTestSubscriber() *runTestSubscriber {
+	return &runTestSubscriber{drainDone: make(chan struct{}), done: make(chan struct{})}
+}
+
+func (s *runTestSubscriber) Start(ctx context.Context) error {
+	if s.startErr != nil {
+		return s.startErr
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	s.mu.Lock()
+	s.started = true
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *runTestSubscriber) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	s.drained = true
+	s.mu.Unlock()
+	defer s.finish()
+
+	select {
+	case <-s.drainDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *runTestSubscriber) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.finish()
+}
+
+func (s *runTestSubscriber) Wait() {
+	<-s.done
+}
+
+func (s *runTestSubscriber) finish() {
+	s.once.Do(func() { close(s.done) })
+}
+
+func TestRun(t *testing.T) {
+	t.Run("drains on signal", func(t *testing.T) {
+		sub := newRunTestSubscriber()
+		close(sub.drainDone)
+		signals := make(chan os.Signal, 1)
+		signals <- os.Interrupt
+
+		if err := runSubscriber(context.Background(), sub, signals, time.Second); err != nil {
+			t.Fatalf("expected clean shutdown, got %v", err)
+		}
+		if !sub.started || !sub.drained {
+			t.Errorf("expected the subscriber to be started and drained, got started=%v drained=%v", sub.started, sub.drained)
+		}
+	})
+
+	t.Run("drains when ctx is cancelled", func(t *testing.T) {
+		sub := newRunTestSubscriber()
+		close(sub.drainDone)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := runSubscriber(ctx, sub, nil, time.Second); err != nil {
+			t.Fatalf("expected clean shutdown, got %v", err)
+		}
+		if !sub.started || !sub.drained {
+			t.Errorf("expected the subscriber to be started and drained, got started=%v drained=%v", sub.started, sub.drained)
+		}
+	})
+
+	t.Run("second signal cuts the drain short", func(t *testing.T) {
+		sub := newRunTestSubscriber()
+		signals := make(chan os.Signal, 2)
+		signals <- os.Interrupt
+		signals <- os.Interrupt
+
+		err := runSubscriber(context.Background(), sub, signals, time.Minute)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the drain to be cancelled, got %v", err)
+		}
+	})
+
+	t.Run("drain timeout", func(t *testing.T) {
+		sub := newRunTestSubscriber()
+		signals := make(chan os.Signal, 1)
+		signals <- os.Interrupt
+
+		err := runSubscriber(context.Background(), sub, signals, 10*time.Millisecond)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+	})
+
+	t.Run("start failure", func(t *testing.T) {
+		sub := newRunTestSubscriber()
+		sub.startErr = errors.New("no handlers registered")
+
+		err := runSubscriber(context.Background(), sub, nil, time.Second)
+		if err == nil || !strings.Contains(err.Error(), "no handlers registered") {
+			t.Errorf("expected start error, got %v", err)
+		}
+		if !sub.stopped {
+			t.Error("expected the subscriber to be stopped")
+		}
+	})
+
+	t.Run("subscriptions ending is an error", func(t *testing.T) {
+		sub := newRunTestSubscriber()
+		sub.finish()
+
+		if err := runSubscriber(context.Background(), sub, nil, time.Second); err == nil {
+			t.Error("expected error when subscriptions end on their own")
+		}
+		if !sub.stopped {
+			t.Error("expected the subscriber to be stopped")
+		}
+	})
+
+	t.Run("nil subscriber", func(t *testing.T) {
+		if err := Run(context.Background(), nil); err == nil {
+			t.Error("expected error for nil subscriber")
+		}
+	})
+}
@@ -1,11 +1,21 @@
 package minitoolstream_connector
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"regexp"
+	"time"
 
+	"github.com/nats-io/nats.go"
+	"golang.org/x/oauth2"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/endpoints"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/checkpoint"
 	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
 	subscriberUsecase "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/usecase/subscriber"
 )
@@ -25,6 +35,86 @@ type MessageHandler = domain.MessageHandler
 // MessageHandlerFunc re-exports domain.MessageHandlerFunc
 type MessageHandlerFunc = domain.MessageHandlerFunc
 
+// FailureSink re-exports domain.FailureSink
+type FailureSink = domain.FailureSink
+
+// FailureSinkFunc re-exports domain.FailureSinkFunc
+type FailureSinkFunc = domain.FailureSinkFunc
+
+// DeliveryAttempt returns the delivery attempt number of the message being handled
+var DeliveryAttempt = domain.DeliveryAttempt
+
+// WithCorrelationID returns a context whose publishes carry the correlation ID
+var WithCorrelationID = domain.WithCorrelationID
+
+// CorrelationID returns the correlation ID of the message being handled
+var CorrelationID = domain.CorrelationID
+
+// Backoff returns a handler error that pauses the subject for the given duration
+var Backoff = domain.Backoff
+
+// SubscriberSettings re-exports the subscriber settings changed by Reconfigure
+type SubscriberSettings = subscriberUsecase.Settings
+
+// SubscriberReconfigurer is implemented by subscribers whose settings can be
+// changed while they run
+type SubscriberReconfigurer interface {
+	Settings() SubscriberSettings
+	Reconfigure(settings SubscriberSettings) error
+}
+
+// EgressClient re-exports domain.EgressClient
+type EgressClient = domain.EgressClient
+
+// SubscriptionConfig re-exports domain.SubscriptionConfig
+type SubscriptionConfig = domain.SubscriptionConfig
+
+// NotificationStream re-exports domain.NotificationStream
+type NotificationStream = domain.NotificationStream
+
+// MessageStream re-exports domain.MessageStream
+type MessageStream = domain.MessageStream
+
+// MessageIterator re-exports domain.MessageIterator
+type MessageIterator = domain.MessageIterator
+
+// SubjectLister re-exports domain.SubjectLister
+type SubjectLister = domain.SubjectLister
+
+// OverflowPolicy re-exports domain.OverflowPolicy
+type OverflowPolicy = domain.OverflowPolicy
+
+// Notification queue overflow policies
+const (
+	OverflowDropNewest  = domain.OverflowDropNewest
+	OverflowBlock       = domain.OverflowBlock
+	OverflowDropOldest  = domain.OverflowDropOldest
+	OverflowSpillToDisk = domain.OverflowSpillToDisk
+)
+
+// ParseOverflowPolicy parses "drop-newest", "block", "drop-oldest" or "spill-to-disk"
+var ParseOverflowPolicy = domain.ParseOverflowPolicy
+
+// StartPosition re-exports domain.StartPosition
+type StartPosition = domain.StartPosition
+
+// Start position constructors
+var (
+	Earliest     = domain.Earliest
+	Latest       = domain.Latest
+	FromSequence = domain.FromSequence
+	FromTime     = domain.FromTime
+)
+
+// CheckpointStore re-exports domain.CheckpointStore
+type CheckpointStore = domain.CheckpointStore
+
+// Checkpoint stores
+var (
+	NewFileCheckpointStore   = checkpoint.NewFileStore
+	NewMemoryCheckpointStore = checkpoint.NewMemoryStore
+)
+
 // NewSubscriber creates a new subscriber with default configuration
 func NewSubscriber(serverAddr string, durableName string, opts ...grpc.DialOption) (Subscriber, error) {
 	if serverAddr == "" {
@@ -55,14 +145,72 @@ func NewSubscriber(serverAddr string, durableName string, opts ...grpc.DialOptio
 	return sub, nil
 }
 
+// NewSubscriberWithClient creates a new subscriber on top of a caller-supplied
+// client, e.g. an instrumented, pooled or fake implementation
+func NewSubscriberWithClient(client EgressClient, durableName string) (Subscriber, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client is required")
+	}
+
+	if durableName == "" {
+		durableName = "default-subscriber"
+	}
+
+	sub, err := subscriberUsecase.New(&subscriberUsecase.Config{
+		Client:      client,
+		DurableName: durableName,
+		BatchSize:   10,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscriber: %w", err)
+	}
+
+	return sub, nil
+}
+
 // SubscriberBuilder provides a fluent interface for building subscribers
 type SubscriberBuilder struct {
-	serverAddr  string
-	durableName string
-	batchSize   int32
-	dialOpts    []grpc.DialOption
-	logger      subscriberUsecase.Logger
-	err         error
+	dialSettings
+	transportSettings
+	endpointSettings
+	serverAddr   string
+	durableName  string
+	batchSize    int32
+	logger       subscriberUsecase.Logger
+	startPos     domain.StartPosition
+	startSeq     *uint64
+	subjectSeqs  map[string]uint64
+	checkpoints  domain.CheckpointStore
+	strict       bool
+	maxDeliver   int
+	failureSink  domain.FailureSink
+	dedupWindow  int
+	fanOut       bool
+	resubscribe  time.Duration
+	discovery    time.Duration
+	maxHandlers  int
+	maxInFlight  int
+	overflow     domain.OverflowPolicy
+	decompressor domain.Decompressor
+	fetcher      domain.PayloadFetcher
+	priorities   map[string]int
+	patterns     []patternRoute
+	onStarted    func(subject string)
+	onStopped    func(subject string, err error)
+	onResub      func(subject string, attempt int)
+	logLevel     domain.LogLevel
+	logSample    int
+	logSummary   time.Duration
+	logFormatter domain.LogFormatter
+	clock        domain.Clock
+	liveWindow   time.Duration
+	err          error
+}
+
+// patternRoute is a pattern handler registered on the builder
+type patternRoute struct {
+	pattern *regexp.Regexp
+	handler MessageHandler
 }
 
 // NewSubscriberBuilder creates a new subscriber builder
@@ -91,43 +239,413 @@ func (b *SubscriberBuilder) WithDialOptions(opts ...grpc.DialOption) *Subscriber
 	return b
 }
 
+// WithTransportCredentials sets the transport credentials (TLS) for the connection
+func (b *SubscriberBuilder) WithTransportCredentials(creds credentials.TransportCredentials) *SubscriberBuilder {
+	b.transportCreds = creds
+	return b
+}
+
+// WithPerRPCCredentials sets credentials attached to every RPC (tokens, API keys)
+func (b *SubscriberBuilder) WithPerRPCCredentials(creds credentials.PerRPCCredentials) *SubscriberBuilder {
+	b.perRPCCreds = creds
+	return b
+}
+
+// WithOAuth2 attaches bearer tokens from the token source to every RPC, e.g. for
+// services behind an identity-aware proxy. Tokens are refreshed when they expire
+// and require transport security.
+func (b *SubscriberBuilder) WithOAuth2(tokenSource oauth2.TokenSource) *SubscriberBuilder {
+	b.perRPCCreds = oauth2Credentials(tokenSource)
+	return b
+}
+
+// WithContextDialer sets the function used to open connections to the server,
+// e.g. through a SOCKS/HTTP proxy, an SSH tunnel or a service mesh. The dialer
+// receives the resolved address; prefix the server address with passthrough:///
+// to hand it the address unresolved.
+func (b *SubscriberBuilder) WithContextDialer(dialer func(ctx context.Context, addr string) (net.Conn, error)) *SubscriberBuilder {
+	b.contextDialer = dialer
+	return b
+}
+
+// WithUnaryInterceptor adds interceptors to unary calls, e.g. for auth or tracing.
+// Interceptors run in the order they were added, after any set via dial options.
+func (b *SubscriberBuilder) WithUnaryInterceptor(interceptors ...grpc.UnaryClientInterceptor) *SubscriberBuilder {
+	b.unary = append(b.unary, interceptors...)
+	return b
+}
+
+// WithStreamInterceptor adds interceptors to streaming calls, e.g. for auth or tracing.
+// Interceptors run in the order they were added, after any set via dial options.
+func (b *SubscriberBuilder) WithStreamInterceptor(interceptors ...grpc.StreamClientInterceptor) *SubscriberBuilder {
+	b.stream = append(b.stream, interceptors...)
+	return b
+}
+
+// WithLoadBalancingPolicy sets the client-side load balancing policy, e.g.
+// "round_robin" to spread calls over all addresses the server name resolves to
+// (use a dns:/// address to re-resolve as backends scale). It replaces a default
+// service config set via dial options; combine both with WithServiceConfig.
+func (b *SubscriberBuilder) WithLoadBalancingPolicy(policy string) *SubscriberBuilder {
+	b.lbPolicy = policy
+	return b
+}
+
+// WithServiceConfig sets the default gRPC service config JSON, e.g. retry policies
+func (b *SubscriberBuilder) WithServiceConfig(config string) *SubscriberBuilder {
+	b.serviceConfig = config
+	return b
+}
+
+// WithDeadlines sets the timeouts applied to gRPC calls whose context has no
+// deadline, replacing DefaultDeadlines; zero fields disable a timeout
+func (b *SubscriberBuilder) WithDeadlines(deadlines Deadlines) *SubscriberBuilder {
+	b.deadlines = &deadlines
+	return b
+}
+
+// WithTenant scopes the subscriber to a tenant: subjects are prefixed with the
+// tenant on the server ("orders" becomes "acme.orders") and handlers never see
+// another tenant's subjects
+func (b *SubscriberBuilder) WithTenant(tenant string) *SubscriberBuilder {
+	b.tenant = tenant
+	return b
+}
+
+// WithJetStream connects directly to NATS JetStream instead of the gRPC services.
+// The server address is then a NATS URL and gRPC dial options are ignored.
+func (b *SubscriberBuilder) WithJetStream(opts ...nats.Option) *SubscriberBuilder {
+	b.jetStream = true
+	b.natsOpts = append(b.natsOpts, opts...)
+	return b
+}
+
+// WithHTTPEndpoints serves /healthz, /readyz and /metrics for the subscriber
+// on addr, e.g. ":8081", until it is stopped. Readiness requires the
+// notification stream of every subject to be open; liveness fails once a
+// stream has been down for longer than the liveness window.
+func (b *SubscriberBuilder) WithHTTPEndpoints(addr string) *SubscriberBuilder {
+	b.httpAddr = addr
+	return b
+}
+
+// WithLivenessWindow sets how long a subject's stream may be down, e.g. while
+// resubscribing, before /healthz reports the subscriber as not live
+// (default 60s)
+func (b *SubscriberBuilder) WithLivenessWindow(window time.Duration) *SubscriberBuilder {
+	b.liveWindow = window
+	return b
+}
+
 // WithLogger sets a custom logger
 func (b *SubscriberBuilder) WithLogger(logger subscriberUsecase.Logger) *SubscriberBuilder {
 	b.logger = logger
 	return b
 }
 
+// WithStartPosition sets where subscriptions start reading (Earliest, Latest, FromSequence, FromTime)
+func (b *SubscriberBuilder) WithStartPosition(pos StartPosition) *SubscriberBuilder {
+	b.startPos = pos
+	return b
+}
+
+// WithStartSequence sets the sequence all subjects start reading from
+func (b *SubscriberBuilder) WithStartSequence(seq uint64) *SubscriberBuilder {
+	b.startSeq = &seq
+	return b
+}
+
+// WithSubjectStartSequence sets the sequence a single subject starts reading from
+func (b *SubscriberBuilder) WithSubjectStartSequence(subject string, seq uint64) *SubscriberBuilder {
+	if b.subjectSeqs == nil {
+		b.subjectSeqs = make(map[string]uint64)
+	}
+	b.subjectSeqs[subject] = seq
+	return b
+}
+
+// WithCheckpointStore enables automatic resume from locally stored checkpoints
+func (b *SubscriberBuilder) WithCheckpointStore(store CheckpointStore) *SubscriberBuilder {
+	b.checkpoints = store
+	return b
+}
+
+// WithMaxDeliveries sets how many times a failing message is delivered to its handler
+func (b *SubscriberBuilder) WithMaxDeliveries(maxDeliveries int) *SubscriberBuilder {
+	b.maxDeliver = maxDeliveries
+	return b
+}
+
+// WithFailureSink sets the sink for messages that failed all delivery attempts
+func (b *SubscriberBuilder) WithFailureSink(sink domain.FailureSink) *SubscriberBuilder {
+	b.failureSink = sink
+	return b
+}
+
+// WithDedupWindow sets how many processed sequences per subject are remembered
+// to suppress duplicate deliveries from overlapping fetches
+func (b *SubscriberBuilder) WithDedupWindow(size int) *SubscriberBuilder {
+	b.dedupWindow = size
+	return b
+}
+
+// WithStrictMode makes handler errors abort the batch and retry the notification,
+// redelivering the failed message and the rest of its batch first
+func (b *SubscriberBuilder) WithStrictMode() *SubscriberBuilder {
+	b.strict = true
+	return b
+}
+
+// WithHandlerFanOut runs several handlers registered for one subject concurrently
+func (b *SubscriberBuilder) WithHandlerFanOut() *SubscriberBuilder {
+	b.fanOut = true
+	return b
+}
+
+// WithResubscribeDelay resubscribes a subject after its stream ends, waiting delay between attempts
+func (b *SubscriberBuilder) WithResubscribeDelay(delay time.Duration) *SubscriberBuilder {
+	b.resubscribe = delay
+	return b
+}
+
+// WithMaxInFlight caps the messages fetched but not yet handled across all
+// subjects; fetching pauses while the limit is reached
+func (b *SubscriberBuilder) WithMaxInFlight(n int) *SubscriberBuilder {
+	b.maxInFlight = n
+	return b
+}
+
+// WithMaxConcurrentHandlers bounds how many handlers run at once across all subjects
+func (b *SubscriberBuilder) WithMaxConcurrentHandlers(n int) *SubscriberBuilder {
+	b.maxHandlers = n
+	return b
+}
+
+// WithSubjectPriority sets the dispatch priority of a subject; higher priorities
+// are handled first when the concurrent handler limit is reached
+func (b *SubscriberBuilder) WithSubjectPriority(subject string, priority int) *SubscriberBuilder {
+	if b.priorities == nil {
+		b.priorities = make(map[string]int)
+	}
+	b.priorities[subject] = priority
+	return b
+}
+
+// WithPatternHandler registers handler for every subject matching pattern.
+// Subjects are discovered by listing them, which needs the JetStream transport.
+func (b *SubscriberBuilder) WithPatternHandler(pattern *regexp.Regexp, handler MessageHandler) *SubscriberBuilder {
+	b.patterns = append(b.patterns, patternRoute{pattern: pattern, handler: handler})
+	return b
+}
+
+// WithDiscoveryInterval sets how often subjects are listed for pattern handlers
+func (b *SubscriberBuilder) WithDiscoveryInterval(interval time.Duration) *SubscriberBuilder {
+	b.discovery = interval
+	return b
+}
+
+// WithLifecycleHooks sets callbacks for subjects starting, stopping and being resubscribed; any may be nil
+func (b *SubscriberBuilder) WithLifecycleHooks(started func(subject string), stopped func(subject string, err error), resubscribed func(subject string, attempt int)) *SubscriberBuilder {
+	b.onStarted = started
+	b.onStopped = stopped
+	b.onResub = resubscribed
+	return b
+}
+
+// WithOverflowPolicy sets what happens to notifications when a subject's
+// notification queue is full (default OverflowDropNewest)
+func (b *SubscriberBuilder) WithOverflowPolicy(policy OverflowPolicy) *SubscriberBuilder {
+	b.overflow = policy
+	return b
+}
+
+// WithClaimCheck fetches payloads published by reference, e.g. by a
+// ClaimCheck, before they reach handlers
+func (b *SubscriberBuilder) WithClaimCheck(fetcher PayloadFetcher) *SubscriberBuilder {
+	b.fetcher = fetcher
+	return b
+}
+
+// WithDecompression restores payloads published with a content-encoding
+// header before they reach handlers, e.g. with DefaultCompressors
+func (b *SubscriberBuilder) WithDecompression(decompressor Decompressor) *SubscriberBuilder {
+	b.decompressor = decompressor
+	return b
+}
+
+// WithLogLevel drops log lines below the level
+func (b *SubscriberBuilder) WithLogLevel(level LogLevel) *SubscriberBuilder {
+	b.logLevel = level
+	return b
+}
+
+// WithLogSampling logs only one in every N per-message lines and reports
+// how many were suppressed at most once per summaryInterval (0 disables the
+// summary), for high-throughput deployments
+func (b *SubscriberBuilder) WithLogSampling(every int, summaryInterval time.Duration) *SubscriberBuilder {
+	b.logSample = every
+	b.logSummary = summaryInterval
+	return b
+}
+
+// WithLogFormatter renders log lines with formatter, e.g.
+// PlainLogFormatter{} or JSONLogFormatter{} for log pipelines
+func (b *SubscriberBuilder) WithLogFormatter(formatter LogFormatter) *SubscriberBuilder {
+	b.logFormatter = formatter
+	return b
+}
+
+// WithClock sets the clock used for expiry checks, retry waits and the
+// backoff between stream recovery attempts
+func (b *SubscriberBuilder) WithClock(clock Clock) *SubscriberBuilder {
+	b.clock = clock
+	b.dialSettings.clock = clock
+	return b
+}
+
+// Validate checks the builder configuration without connecting to the server.
+// All problems found are returned together.
+func (b *SubscriberBuilder) Validate() error {
+	var errs []error
+	if err := b.validateTarget(b.serverAddr, &b.dialSettings); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateBatchSize(b.batchSize); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateDurableName(b.durableName, b.jetStream); err != nil {
+		errs = append(errs, err)
+	}
+	if b.maxDeliver < 0 {
+		errs = append(errs, fmt.Errorf("max deliveries cannot be negative, got %d", b.maxDeliver))
+	}
+	if b.dedupWindow < 0 {
+		errs = append(errs, fmt.Errorf("dedup window cannot be negative, got %d", b.dedupWindow))
+	}
+	if b.maxHandlers < 0 {
+		errs = append(errs, fmt.Errorf("max concurrent handlers cannot be negative, got %d", b.maxHandlers))
+	}
+	if b.maxInFlight < 0 {
+		errs = append(errs, fmt.Errorf("max in-flight messages cannot be negative, got %d", b.maxInFlight))
+	}
+	if len(b.patterns) > 0 && !b.jetStream {
+		errs = append(errs, fmt.Errorf("pattern handlers need subject listing, which the gRPC transport does not offer; use WithJetStream"))
+	}
+	for _, route := range b.patterns {
+		if route.pattern == nil || route.handler == nil {
+			errs = append(errs, fmt.Errorf("pattern handler needs a pattern and a handler"))
+			break
+		}
+	}
+	if b.discovery < 0 {
+		errs = append(errs, fmt.Errorf("discovery interval cannot be negative, got %v", b.discovery))
+	}
+	if b.resubscribe < 0 {
+		errs = append(errs, fmt.Errorf("resubscribe delay cannot be negative, got %v", b.resubscribe))
+	}
+	if b.overflow < OverflowDropNewest || b.overflow > OverflowSpillToDisk {
+		errs = append(errs, fmt.Errorf("unknown overflow policy %d", int(b.overflow)))
+	}
+	if err := validateLogLevel(b.logLevel); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateLogSampling(b.logSample, b.logSummary); err != nil {
+		errs = append(errs, err)
+	}
+	if err := b.endpointSettings.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if b.liveWindow < 0 {
+		errs = append(errs, fmt.Errorf("liveness window cannot be negative, got %v", b.liveWindow))
+	}
+	return errors.Join(errs...)
+}
+
 // Build creates the subscriber instance
 func (b *SubscriberBuilder) Build() (Subscriber, error) {
 	if b.err != nil {
 		return nil, b.err
 	}
 
-	if b.serverAddr == "" {
-		return nil, fmt.Errorf("server address is required")
-	}
-
 	if b.durableName == "" {
 		b.durableName = "default-subscriber"
 	}
 
-	// Create gRPC client
-	client, err := grpcClient.NewEgressClient(b.serverAddr, b.dialOpts...)
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+
+	client, err := b.egressClient(b.serverAddr, &b.dialSettings)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+		return nil, err
+	}
+
+	window := b.liveWindow
+	if window <= 0 {
+		window = defaultLivenessWindow
+	}
+	var sub *subscriberUsecase.MultiSubject
+	server, err := b.newEndpoints(
+		func() error { return sub.Alive(window) },
+		func() error { return sub.Subscribed() },
+		func() []endpoints.Metric { return subscriberMetrics(sub.Stats()) },
+		b.logger,
+	)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	if server != nil {
+		client = egressWithCloser(client, server)
 	}
 
 	// Create subscriber
-	sub, err := subscriberUsecase.New(&subscriberUsecase.Config{
-		Client:      client,
-		DurableName: b.durableName,
-		BatchSize:   b.batchSize,
-		Logger:      b.logger,
+	sub, err = subscriberUsecase.New(&subscriberUsecase.Config{
+		Client:        client,
+		DurableName:   b.durableName,
+		BatchSize:     b.batchSize,
+		Logger:        b.logger,
+		StartPosition: b.startPos,
+		StartSequence: b.startSeq,
+		Checkpoints:   b.checkpoints,
+		StrictMode:    b.strict,
+		MaxDeliveries: b.maxDeliver,
+		FailureSink:   b.failureSink,
+		DedupWindow:   b.dedupWindow,
+
+		SubjectStartSequences: b.subjectSeqs,
+		FanOutHandlers:        b.fanOut,
+		ResubscribeDelay:      b.resubscribe,
+		DiscoveryInterval:     b.discovery,
+		MaxConcurrentHandlers: b.maxHandlers,
+		MaxInFlight:           b.maxInFlight,
+		SubjectPriorities:     b.priorities,
+		OnSubjectStarted:      b.onStarted,
+		OnSubjectStopped:      b.onStopped,
+		OnResubscribed:        b.onResub,
+		OverflowPolicy:        b.overflow,
+		Decompressor:          b.decompressor,
+		PayloadFetcher:        b.fetcher,
+		LogLevel:              b.logLevel,
+		LogSampleEvery:        b.logSample,
+		LogSummaryInterval:    b.logSummary,
+		LogFormatter:          b.logFormatter,
+		Clock:                 b.clock,
 	})
 	if err != nil {
 		client.Close()
 		return nil, fmt.Errorf("failed to create subscriber: %w", err)
 	}
+	for _, route := range b.patterns {
+		sub.RegisterPatternHandler(route.pattern, route.handler)
+	}
+
+	if server != nil {
+		if err := server.Start(b.httpAddr); err != nil {
+			sub.Stop()
+			return nil, err
+		}
+	}
 
 	return sub, nil
 }
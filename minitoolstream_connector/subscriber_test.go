@@ -2,7 +2,9 @@ package minitoolstream_connector
 
 import (
 	"context"
+	"io"
 	"testing"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -205,3 +207,187 @@ func TestSubscriberBuilder_FullChain(t *testing.T) {
 		}
 	})
 }
+
+func TestSubscriberBuilder_WithStrictMode(t *testing.T) {
+	t.Run("enable strict mode", func(t *testing.T) {
+		builder := NewSubscriberBuilder("localhost:9091").WithStrictMode()
+		if !builder.strict {
+			t.Error("expected strict mode to be enabled")
+		}
+	})
+}
+
+func TestSubscriberBuilder_WithStartPosition(t *testing.T) {
+	t.Run("set start position", func(t *testing.T) {
+		builder := NewSubscriberBuilder("localhost:9091").WithStartPosition(FromSequence(100))
+		if builder.startPos != FromSequence(100) {
+			t.Errorf("expected sequence=100, got %s", builder.startPos)
+		}
+	})
+}
+
+func TestSubscriberBuilder_WithCheckpointStore(t *testing.T) {
+	t.Run("set checkpoint store", func(t *testing.T) {
+		store := NewMemoryCheckpointStore()
+		builder := NewSubscriberBuilder("localhost:9091").WithCheckpointStore(store)
+		if builder.checkpoints != store {
+			t.Error("expected checkpoint store to be set")
+		}
+	})
+}
+
+func TestSubscriberBuilder_WithMaxDeliveries(t *testing.T) {
+	t.Run("set redelivery options", func(t *testing.T) {
+		sink := FailureSinkFunc(func(ctx context.Context, msg *ReceivedMessage, cause error) error {
+			return nil
+		})
+		builder := NewSubscriberBuilder("localhost:9091").
+			WithMaxDeliveries(5).
+			WithFailureSink(sink)
+
+		if builder.maxDeliver != 5 {
+			t.Errorf("expected 5 deliveries, got %d", builder.maxDeliver)
+		}
+		if builder.failureSink == nil {
+			t.Error("expected failure sink to be set")
+		}
+	})
+}
+
+func TestSubscriberBuilder_WithDedupWindow(t *testing.T) {
+	t.Run("set dedup window", func(t *testing.T) {
+		builder := NewSubscriberBuilder("localhost:9091").WithDedupWindow(500)
+		if builder.dedupWindow != 500 {
+			t.Errorf("expected window 500, got %d", builder.dedupWindow)
+		}
+	})
+}
+
+func TestSubscriberBuilder_WithStartSequence(t *testing.T) {
+	t.Run("global and per-subject sequences", func(t *testing.T) {
+		builder := NewSubscriberBuilder("localhost:9091").
+			WithStartSequence(10).
+			WithSubjectStartSequence("orders", 20)
+
+		if builder.startSeq == nil || *builder.startSeq != 10 {
+			t.Error("expected start sequence 10")
+		}
+		if builder.subjectSeqs["orders"] != 20 {
+			t.Errorf("expected orders start sequence 20, got %d", builder.subjectSeqs["orders"])
+		}
+	})
+}
+
+type fakeEgressClient struct {
+	closed bool
+}
+
+func (c *fakeEgressClient) Subscribe(ctx context.Context, config *SubscriptionConfig) (NotificationStream, error) {
+	return nil, io.EOF
+}
+
+func (c *fakeEgressClient) Fetch(ctx context.Context, config *SubscriptionConfig) (MessageStream, error) {
+	return nil, io.EOF
+}
+
+func (c *fakeEgressClient) GetLastSequence(ctx context.Context, subject string) (uint64, error) {
+	return 0, nil
+}
+
+func (c *fakeEgressClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestNewSubscriberWithClient(t *testing.T) {
+	t.Run("nil client", func(t *testing.T) {
+		sub, err := NewSubscriberWithClient(nil, "consumer")
+		if err == nil {
+			t.Fatal("expected error for nil client")
+		}
+		if sub != nil {
+			t.Error("expected nil subscriber")
+		}
+	})
+
+	t.Run("uses custom client", func(t *testing.T) {
+		client := &fakeEgressClient{}
+		sub, err := NewSubscriberWithClient(client, "")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		sub.Stop()
+		if !client.closed {
+			t.Error("expected client to be closed")
+		}
+	})
+}
+
+func TestSubscriberBuilder_WithLifecycleHooks(t *testing.T) {
+	t.Run("set hooks and resubscribe delay", func(t *testing.T) {
+		builder := NewSubscriberBuilder("localhost:9091").
+			WithResubscribeDelay(time.Second).
+			WithLifecycleHooks(func(subject string) {}, nil, nil)
+
+		if builder.resubscribe != time.Second {
+			t.Errorf("expected resubscribe delay 1s, got %v", builder.resubscribe)
+		}
+		if builder.onStarted == nil || builder.onStopped != nil {
+			t.Error("expected only the started hook to be set")
+		}
+	})
+
+	t.Run("negative delay", func(t *testing.T) {
+		if err := NewSubscriberBuilder("localhost:9091").WithResubscribeDelay(-time.Second).Validate(); err == nil {
+			t.Fatal("expected error for negative delay")
+		}
+	})
+}
+
+func TestSubscriberBuilder_WithHandlerFanOut(t *testing.T) {
+	builder := NewSubscriberBuilder("localhost:9091").WithHandlerFanOut()
+	if !builder.fanOut {
+		t.Error("expected handler fan-out to be enabled")
+	}
+}
+
+func TestSubscriberBuilder_WithDiscoveryInterval(t *testing.T) {
+	builder := NewSubscriberBuilder("localhost:9091").WithDiscoveryInterval(time.Minute)
+	if builder.discovery != time.Minute {
+		t.Errorf("expected discovery interval 1m, got %v", builder.discovery)
+	}
+
+	if err := NewSubscriberBuilder("localhost:9091").WithDiscoveryInterval(-time.Second).Validate(); err == nil {
+		t.Error("expected error for negative discovery interval")
+	}
+}
+
+func TestSubscriberBuilder_WithSubjectPriority(t *testing.T) {
+	builder := NewSubscriberBuilder("localhost:9091").
+		WithMaxConcurrentHandlers(4).
+		WithSubjectPriority("control", 10).
+		WithSubjectPriority("bulk", -1)
+
+	if builder.maxHandlers != 4 {
+		t.Errorf("expected 4 concurrent handlers, got %d", builder.maxHandlers)
+	}
+	if builder.priorities["control"] != 10 || builder.priorities["bulk"] != -1 {
+		t.Errorf("unexpected priorities: %v", builder.priorities)
+	}
+
+	if err := NewSubscriberBuilder("localhost:9091").WithMaxConcurrentHandlers(-1).Validate(); err == nil {
+		t.Error("expected error for negative handler limit")
+	}
+}
+
+func TestSubscriberBuilder_WithMaxInFlight(t *testing.T) {
+	builder := NewSubscriberBuilder("localhost:9091").WithMaxInFlight(100)
+	if builder.maxInFlight != 100 {
+		t.Errorf("expected 100 in-flight messages, got %d", builder.maxInFlight)
+	}
+
+	if err := NewSubscriberBuilder("localhost:9091").WithMaxInFlight(-1).Validate(); err == nil {
+		t.Error("expected error for negative in-flight limit")
+	}
+}
@@ -0,0 +1,263 @@
+// Package tenant lets several tenants share a MiniToolStream server by
+// namespacing their subjects.
+//
+// The client wrappers prefix the tenant to every subject on the way out
+// ("orders" becomes "acme.orders") and strip it from subjects on the way in,
+// so publishers, subscribers and handlers only ever see their own,
+// unprefixed subjects. Messages and notifications whose subject is outside
+// the tenant are rejected with ErrCrossTenant instead of reaching a handler,
+// and subject listings only include the tenant's own subjects.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Separator joins the tenant and the subject
+const Separator = "."
+
+// ErrCrossTenant is returned for subjects outside the tenant's namespace
+var ErrCrossTenant = errors.New("subject crosses tenant boundary")
+
+// namePattern lists the characters allowed in tenant names; the separator and
+// wildcards are excluded so one tenant can't address another's subjects
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidateName checks that name can be used as a tenant
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("tenant is required")
+	}
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid tenant %q: only letters, digits, '_' and '-' are allowed", name)
+	}
+	return nil
+}
+
+// Config represents tenant configuration
+type Config struct {
+	// Tenant is the namespace prefixed to subjects
+	Tenant string
+}
+
+// Namespace maps the subjects of one tenant to and from server subjects
+type Namespace struct {
+	tenant string
+	prefix string
+}
+
+// New creates a new tenant namespace
+func New(config *Config) (*Namespace, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if err := ValidateName(config.Tenant); err != nil {
+		return nil, err
+	}
+
+	return &Namespace{
+		tenant: config.Tenant,
+		prefix: config.Tenant + Separator,
+	}, nil
+}
+
+// Tenant returns the tenant name
+func (n *Namespace) Tenant() string {
+	return n.tenant
+}
+
+// Qualify returns the server subject of a tenant subject
+func (n *Namespace) Qualify(subject string) (string, error) {
+	if subject == "" {
+		return "", fmt.Errorf("subject is required")
+	}
+	if strings.HasPrefix(subject, Separator) {
+		return "", fmt.Errorf("%w: invalid subject %q", ErrCrossTenant, subject)
+	}
+	return n.prefix + subject, nil
+}
+
+// Strip returns the tenant subject of a server subject, or ErrCrossTenant
+// if the subject belongs to another tenant
+func (n *Namespace) Strip(subject string) (string, error) {
+	stripped, ok := strings.CutPrefix(subject, n.prefix)
+	if !ok || stripped == "" {
+		return "", fmt.Errorf("%w: subject %q is not in tenant %s", ErrCrossTenant, subject, n.tenant)
+	}
+	return stripped, nil
+}
+
+// Ingress wraps a client so published messages go to the tenant's subjects
+func (n *Namespace) Ingress(client domain.IngressClient) domain.IngressClient {
+	return &ingressClient{client: client, ns: n}
+}
+
+// Egress wraps a client so it only subscribes to and receives the tenant's
+// subjects. The wrapper implements domain.SubjectLister if client does.
+func (n *Namespace) Egress(client domain.EgressClient) domain.EgressClient {
+	egress := &egressClient{client: client, ns: n}
+	if lister, ok := client.(domain.SubjectLister); ok {
+		return &listingEgressClient{egressClient: egress, lister: lister}
+	}
+	return egress
+}
+
+// ingressClient prefixes the subjects of published messages
+type ingressClient struct {
+	client domain.IngressClient
+	ns     *Namespace
+}
+
+// Publish publishes a copy of msg with the qualified subject, leaving msg
+// untouched so retries don't prefix it twice
+func (c *ingressClient) Publish(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message cannot be nil")
+	}
+
+	subject, err := c.ns.Qualify(msg.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	qualified := *msg
+	qualified.Subject = subject
+	return c.client.Publish(ctx, &qualified)
+}
+
+func (c *ingressClient) Close() error {
+	return c.client.Close()
+}
+
+// egressClient prefixes subscribed subjects and strips received ones
+type egressClient struct {
+	client domain.EgressClient
+	ns     *Namespace
+}
+
+func (c *egressClient) Subscribe(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+	qualified, err := c.qualifyConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.client.Subscribe(ctx, qualified)
+	if err != nil {
+		return nil, err
+	}
+	return &notificationStream{stream: stream, ns: c.ns}, nil
+}
+
+func (c *egressClient) Fetch(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+	qualified, err := c.qualifyConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.client.Fetch(ctx, qualified)
+	if err != nil {
+		return nil, err
+	}
+	return &messageStream{stream: stream, ns: c.ns}, nil
+}
+
+func (c *egressClient) GetLastSequence(ctx context.Context, subject string) (uint64, error) {
+	qualified, err := c.ns.Qualify(subject)
+	if err != nil {
+		return 0, err
+	}
+	return c.client.GetLastSequence(ctx, qualified)
+}
+
+func (c *egressClient) Close() error {
+	return c.client.Close()
+}
+
+// qualifyConfig returns a copy of config with the qualified subject
+func (c *egressClient) qualifyConfig(config *domain.SubscriptionConfig) (*domain.SubscriptionConfig, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	subject, err := c.ns.Qualify(config.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	qualified := *config
+	qualified.Subject = subject
+	return &qualified, nil
+}
+
+// listingEgressClient is an egressClient over a client that lists subjects
+type listingEgressClient struct {
+	*egressClient
+	lister domain.SubjectLister
+}
+
+// ListSubjects returns the tenant's subjects, unprefixed
+func (c *listingEgressClient) ListSubjects(ctx context.Context) ([]string, error) {
+	subjects, err := c.lister.ListSubjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	own := make([]string, 0, len(subjects))
+	for _, subject := range subjects {
+		if stripped, err := c.ns.Strip(subject); err == nil {
+			own = append(own, stripped)
+		}
+	}
+	return own, nil
+}
+
+// notificationStream strips the tenant from notification subjects
+type notificationStream struct {
+	stream domain.NotificationStream
+	ns     *Namespace
+}
+
+func (s *notificationStream) Recv() (*domain.Notification, error) {
+	notification, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := s.ns.Strip(notification.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	stripped := *notification
+	stripped.Subject = subject
+	return &stripped, nil
+}
+
+// messageStream strips the tenant from message subjects
+type messageStream struct {
+	stream domain.MessageStream
+	ns     *Namespace
+}
+
+func (s *messageStream) Recv() (*domain.ReceivedMessage, error) {
+	msg, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := s.ns.Strip(msg.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	stripped := *msg
+	stripped.Subject = subject
+	return &stripped, nil
+}
@@ -0,0 +1,138 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domaintest"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/localbroker"
+)
+
+func newBroker(t *testing.T) *localbroker.Broker {
+	t.Helper()
+	b, err := localbroker.New(&localbroker.Config{Dir: t.TempDir(), PollInterval: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to create broker: %v", err)
+	}
+	return b
+}
+
+func newNamespace(t *testing.T, tenant string) *Namespace {
+	t.Helper()
+	ns, err := New(&Config{Tenant: tenant})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return ns
+}
+
+func TestNew(t *testing.T) {
+	for _, config := range []*Config{nil, {}, {Tenant: "a.b"}, {Tenant: "a*"}, {Tenant: ">"}} {
+		if _, err := New(config); err == nil {
+			t.Errorf("expected error for %+v", config)
+		}
+	}
+
+	ns := newNamespace(t, "acme")
+	if ns.Tenant() != "acme" {
+		t.Errorf("expected tenant acme, got %s", ns.Tenant())
+	}
+}
+
+func TestNamespace_Subjects(t *testing.T) {
+	ns := newNamespace(t, "acme")
+
+	if subject, err := ns.Qualify("orders.created"); err != nil || subject != "acme.orders.created" {
+		t.Errorf("expected acme.orders.created, got %q (%v)", subject, err)
+	}
+	if _, err := ns.Qualify(""); err == nil {
+		t.Error("expected error for empty subject")
+	}
+	if _, err := ns.Qualify(".orders"); !errors.Is(err, ErrCrossTenant) {
+		t.Errorf("expected ErrCrossTenant, got %v", err)
+	}
+
+	if subject, err := ns.Strip("acme.orders"); err != nil || subject != "orders" {
+		t.Errorf("expected orders, got %q (%v)", subject, err)
+	}
+	for _, subject := range []string{"globex.orders", "acmeorders", "acme.", "orders"} {
+		if _, err := ns.Strip(subject); !errors.Is(err, ErrCrossTenant) {
+			t.Errorf("expected ErrCrossTenant for %q, got %v", subject, err)
+		}
+	}
+}
+
+func TestNamespace_Isolation(t *testing.T) {
+	ctx := context.Background()
+	broker := newBroker(t)
+	acme := newNamespace(t, "acme")
+	globex := newNamespace(t, "globex")
+
+	msg := &domain.PublishMessage{Subject: "orders", Data: []byte("a1")}
+	if _, err := acme.Ingress(broker).Publish(ctx, msg); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if msg.Subject != "orders" {
+		t.Errorf("expected the caller's message to be unchanged, got subject %s", msg.Subject)
+	}
+	if _, err := globex.Ingress(broker).Publish(ctx, &domain.PublishMessage{Subject: "orders", Data: []byte("g1")}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	egress := acme.Egress(broker)
+	stream, err := egress.Fetch(ctx, &domain.SubscriptionConfig{Subject: "orders", DurableName: "d", StartPosition: domain.Earliest(), BatchSize: 10})
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	received, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	if received.Subject != "orders" || string(received.Data) != "a1" {
+		t.Errorf("unexpected message %s %q", received.Subject, received.Data)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("expected only the tenant's message, got %v", err)
+	}
+
+	lister, ok := egress.(domain.SubjectLister)
+	if !ok {
+		t.Fatal("expected the wrapper to list subjects")
+	}
+	subjects, err := lister.ListSubjects(ctx)
+	if err != nil || !reflect.DeepEqual(subjects, []string{"orders"}) {
+		t.Errorf("expected [orders], got %v (%v)", subjects, err)
+	}
+}
+
+func TestNamespace_RejectsForeignMessages(t *testing.T) {
+	ns := newNamespace(t, "acme")
+
+	// A misbehaving server delivers another tenant's message
+	stream := &messageStream{stream: foreignStream{}, ns: ns}
+	if _, err := stream.Recv(); !errors.Is(err, ErrCrossTenant) {
+		t.Errorf("expected ErrCrossTenant, got %v", err)
+	}
+}
+
+type foreignStream struct{}
+
+func (foreignStream) Recv() (*domain.ReceivedMessage, error) {
+	return &domain.ReceivedMessage{Subject: "globex.orders", Sequence: 1}, nil
+}
+
+func TestNamespace_Contract(t *testing.T) {
+	domaintest.RunIngressClientTests(t, func(t *testing.T) domain.IngressClient {
+		return newNamespace(t, "acme").Ingress(newBroker(t))
+	})
+	domaintest.RunEgressClientTests(t, func(t *testing.T) domaintest.Clients {
+		ns := newNamespace(t, "acme")
+		b := newBroker(t)
+		return domaintest.Clients{Ingress: ns.Ingress(b), Egress: ns.Egress(b)}
+	})
+}
@@ -0,0 +1,115 @@
+package minitoolstream_connector
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/jetstream"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/tenant"
+)
+
+// transportSettings selects the backend the builders connect to
+type transportSettings struct {
+	jetStream bool
+	natsOpts  []nats.Option
+	tenant    string
+}
+
+// validateTarget checks the server address and connection settings for the selected backend
+func (b *transportSettings) validateTarget(addr string, dial *dialSettings) error {
+	var tenantErr error
+	if b.tenant != "" {
+		tenantErr = tenant.ValidateName(b.tenant)
+	}
+
+	if b.jetStream {
+		if addr == "" {
+			return errors.Join(fmt.Errorf("server address is required"), tenantErr)
+		}
+		return tenantErr
+	}
+	return errors.Join(validateAddress(addr), dial.validate(), tenantErr)
+}
+
+// namespace returns the tenant namespace clients are wrapped in, or nil
+func (b *transportSettings) namespace() (*tenant.Namespace, error) {
+	if b.tenant == "" {
+		return nil, nil
+	}
+	return tenant.New(&tenant.Config{Tenant: b.tenant})
+}
+
+// ingressClient connects the publishing client of the selected backend,
+// scoped to the tenant if one is set
+func (b *transportSettings) ingressClient(addr string, dial *dialSettings) (domain.IngressClient, error) {
+	ns, err := b.namespace()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := b.dialIngress(addr, dial)
+	if err != nil || ns == nil {
+		return client, err
+	}
+	return ns.Ingress(client), nil
+}
+
+// egressClient connects the consuming client of the selected backend,
+// scoped to the tenant if one is set
+func (b *transportSettings) egressClient(addr string, dial *dialSettings) (domain.EgressClient, error) {
+	ns, err := b.namespace()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := b.dialEgress(addr, dial)
+	if err != nil || ns == nil {
+		return client, err
+	}
+	return ns.Egress(client), nil
+}
+
+// dialIngress connects the publishing client of the selected backend
+func (b *transportSettings) dialIngress(addr string, dial *dialSettings) (domain.IngressClient, error) {
+	if b.jetStream {
+		client, err := jetstream.NewIngressClient(addr, b.natsOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create JetStream client: %w", err)
+		}
+		return client, nil
+	}
+
+	client, err := grpcClient.NewIngressClient(addr, dial.options()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	if dial.deadlines != nil {
+		client.SetDeadlines(*dial.deadlines)
+	}
+	return client, nil
+}
+
+// dialEgress connects the consuming client of the selected backend
+func (b *transportSettings) dialEgress(addr string, dial *dialSettings) (domain.EgressClient, error) {
+	if b.jetStream {
+		client, err := jetstream.NewEgressClient(addr, b.natsOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create JetStream client: %w", err)
+		}
+		return client, nil
+	}
+
+	client, err := grpcClient.NewEgressClient(addr, dial.options()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	if dial.deadlines != nil {
+		client.SetDeadlines(*dial.deadlines)
+	}
+	client.SetClock(dial.clock)
+	return client, nil
+}
@@ -0,0 +1,96 @@
+package minitoolstream_connector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	grpcClient "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/grpc"
+)
+
+func TestTransportSettings_ValidateTarget(t *testing.T) {
+	t.Run("gRPC rejects NATS URL", func(t *testing.T) {
+		var settings transportSettings
+		if err := settings.validateTarget("nats://localhost:4222", &dialSettings{}); err == nil {
+			t.Fatal("expected error for NATS URL with gRPC backend")
+		}
+	})
+
+	t.Run("JetStream accepts NATS URL", func(t *testing.T) {
+		settings := transportSettings{jetStream: true}
+		if err := settings.validateTarget("nats://localhost:4222", &dialSettings{}); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("JetStream requires address", func(t *testing.T) {
+		settings := transportSettings{jetStream: true}
+		if err := settings.validateTarget("", &dialSettings{}); err == nil {
+			t.Fatal("expected error for empty address")
+		}
+	})
+}
+
+func TestBuilders_WithJetStream(t *testing.T) {
+	t.Run("publisher", func(t *testing.T) {
+		builder := NewPublisherBuilder("nats://localhost:4222").WithJetStream(nats.Name("orders"))
+		if !builder.jetStream || len(builder.natsOpts) != 1 {
+			t.Error("expected JetStream backend with one option")
+		}
+		if err := builder.Validate(); err != nil {
+			t.Errorf("expected valid builder, got %v", err)
+		}
+	})
+
+	t.Run("subscriber", func(t *testing.T) {
+		builder := NewSubscriberBuilder("nats://localhost:4222").WithJetStream()
+		if !builder.jetStream {
+			t.Error("expected JetStream backend")
+		}
+		if err := builder.Validate(); err != nil {
+			t.Errorf("expected valid builder, got %v", err)
+		}
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		_, err := NewPublisherBuilder("nats://127.0.0.1:1").
+			WithJetStream(nats.Timeout(100 * time.Millisecond)).
+			Build()
+		if err == nil {
+			t.Fatal("expected connection error")
+		}
+	})
+}
+
+func TestBuilders_WithTenant(t *testing.T) {
+	t.Run("invalid tenant", func(t *testing.T) {
+		if err := NewPublisherBuilder("localhost:50051").WithTenant("acme.eu").Validate(); err == nil {
+			t.Error("expected error for tenant containing a separator")
+		}
+		if err := NewSubscriberBuilder("nats://localhost:4222").WithJetStream().WithTenant("*").Validate(); err == nil {
+			t.Error("expected error for wildcard tenant")
+		}
+	})
+
+	t.Run("clients are scoped to the tenant", func(t *testing.T) {
+		settings := transportSettings{tenant: "acme"}
+		ingress, err := settings.ingressClient("localhost:50051", &dialSettings{})
+		if err != nil {
+			t.Fatalf("ingressClient failed: %v", err)
+		}
+		defer ingress.Close()
+		if _, ok := ingress.(*grpcClient.IngressClient); ok {
+			t.Error("expected the client to be wrapped")
+		}
+
+		egress, err := settings.egressClient("localhost:50051", &dialSettings{})
+		if err != nil {
+			t.Fatalf("egressClient failed: %v", err)
+		}
+		defer egress.Close()
+		if _, ok := egress.(*grpcClient.EgressClient); ok {
+			t.Error("expected the client to be wrapped")
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package minitoolstream_connector
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/codec"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/infrastructure/handler"
+)
+
+// ErrDecode re-exports domain.ErrDecode
+var ErrDecode = domain.ErrDecode
+
+// Codec re-exports domain.Codec
+type Codec = domain.Codec
+
+// CodecRegistry re-exports codec.Registry
+type CodecRegistry = codec.Registry
+
+// Codecs
+type (
+	JSONCodec    = codec.JSON
+	ProtoCodec   = codec.Proto
+	MsgPackCodec = codec.MsgPack
+	CBORCodec    = codec.CBOR
+)
+
+// TypeRegistry re-exports codec.TypeRegistry
+type TypeRegistry = codec.TypeRegistry
+
+// Codec registries
+var (
+	// DefaultCodecs is the registry used by SubscribeTyped and PublishEncoded
+	DefaultCodecs    = codec.Default
+	NewCodecRegistry = codec.NewRegistry
+	RegisterCodec    = codec.Register
+	NewTypeRegistry  = codec.NewTypeRegistry
+)
+
+// Avro types
+type (
+	AvroCodec          = codec.Avro
+	AvroConfig         = codec.AvroConfig
+	AvroSchema         = codec.AvroSchema
+	AvroSchemaRegistry = codec.AvroSchemaRegistry
+)
+
+// Avro constructors
+var (
+	NewAvroCodec        = codec.NewAvro
+	ParseAvroSchema     = codec.ParseAvroSchema
+	MustParseAvroSchema = codec.MustParseAvroSchema
+	NewAvroSchemas      = codec.NewAvroSchemas
+)
+
+// Header is a message header passed to the typed publish helpers
+type Header struct {
+	Name  string
+	Value string
+}
+
+// PublishJSON publishes v encoded as JSON to subject with an application/json
+// content type and the given headers
+func PublishJSON[T any](ctx context.Context, pub Publisher, subject string, v T, headers ...Header) error {
+	return pub.Publish(ctx, handler.NewJSONPreparer(subject, v).WithHeaders(headerMap(headers)))
+}
+
+// PublishEncoded publishes v encoded with the codec registered in
+// DefaultCodecs for contentType, setting that content type
+func PublishEncoded(ctx context.Context, pub Publisher, subject, contentType string, v interface{}, headers ...Header) error {
+	return pub.Publish(ctx, MessagePreparerFunc(func(ctx context.Context) (*PublishMessage, error) {
+		data, err := codec.Default.Encode(contentType, v)
+		if err != nil {
+			return nil, err
+		}
+
+		msgHeaders := map[string]string{"content-type": contentType}
+		for name, value := range headerMap(headers) {
+			msgHeaders[name] = value
+		}
+		return &PublishMessage{Subject: subject, Data: data, Headers: msgHeaders}, nil
+	}))
+}
+
+// PublishProto publishes m encoded as protobuf to subject with an
+// application/x-protobuf content type, the message type name in the
+// proto-message header and the given headers
+func PublishProto(ctx context.Context, pub Publisher, subject string, m proto.Message, headers ...Header) error {
+	return pub.Publish(ctx, MessagePreparerFunc(func(ctx context.Context) (*PublishMessage, error) {
+		data, err := proto.Marshal(m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %T as protobuf: %w", m, err)
+		}
+
+		msgHeaders := map[string]string{
+			"content-type":            "application/x-protobuf",
+			domain.HeaderProtoMessage: string(m.ProtoReflect().Descriptor().FullName()),
+		}
+		for name, value := range headerMap(headers) {
+			msgHeaders[name] = value
+		}
+		return &PublishMessage{Subject: subject, Data: data, Headers: msgHeaders}, nil
+	}))
+}
+
+// PublishAny publishes the message packed in a to subject with an
+// application/x-protobuf content type, its type URL in the proto-type-url
+// header and the given headers, so subscribers dispatch on the type with a
+// TypeRegistry without unpacking the payload
+func PublishAny(ctx context.Context, pub Publisher, subject string, a *anypb.Any, headers ...Header) error {
+	return pub.Publish(ctx, MessagePreparerFunc(func(ctx context.Context) (*PublishMessage, error) {
+		if a.GetTypeUrl() == "" {
+			return nil, fmt.Errorf("type URL is required")
+		}
+
+		msgHeaders := map[string]string{
+			"content-type":            "application/x-protobuf",
+			domain.HeaderProtoTypeURL: a.TypeUrl,
+			domain.HeaderProtoMessage: string(a.MessageName()),
+		}
+		for name, value := range headerMap(headers) {
+			msgHeaders[name] = value
+		}
+		return &PublishMessage{Subject: subject, Data: a.Value, Headers: msgHeaders}, nil
+	}))
+}
+
+// headerMap converts headers to a map; later headers win
+func headerMap(headers []Header) map[string]string {
+	m := make(map[string]string, len(headers))
+	for _, h := range headers {
+		m[h.Name] = h.Value
+	}
+	return m
+}
+
+// SubscribeJSON registers fn for subject, decoding every payload into a T
+// before calling it. Messages without a content-type header or with a JSON
+// one are decoded; other content types and malformed payloads fail the
+// message with an error matching ErrDecode.
+func SubscribeJSON[T any](sub Subscriber, subject string, fn func(ctx context.Context, v T, msg *ReceivedMessage) error) {
+	sub.RegisterHandler(subject, JSONHandler(fn))
+}
+
+// JSONHandler returns a handler decoding payloads into a T before calling fn,
+// for use with handler chains and RegisterHandlers
+func JSONHandler[T any](fn func(ctx context.Context, v T, msg *ReceivedMessage) error) MessageHandler {
+	return codec.Handler(jsonCodecs, fn)
+}
+
+// jsonCodecs only decodes JSON
+var jsonCodecs = codec.NewRegistry(codec.JSON{})
+
+// SubscribeTyped registers fn for subject, decoding every payload into a T
+// with the codec registered in DefaultCodecs for its content type, so
+// subjects mixing formats need no switch in the handler. Undecodable
+// messages fail with an error matching ErrDecode.
+func SubscribeTyped[T any](sub Subscriber, subject string, fn func(ctx context.Context, v T, msg *ReceivedMessage) error) {
+	sub.RegisterHandler(subject, TypedHandler(fn))
+}
+
+// TypedHandler returns a handler decoding payloads into a T with
+// DefaultCodecs before calling fn
+func TypedHandler[T any](fn func(ctx context.Context, v T, msg *ReceivedMessage) error) MessageHandler {
+	return codec.Handler(codec.Default, fn)
+}
+
+// HandleProtoType registers fn for protobuf messages of type T in r, e.g.
+// for subjects fed by PublishAny
+func HandleProtoType[T proto.Message](r *TypeRegistry, fn func(ctx context.Context, m T, msg *ReceivedMessage) error) {
+	codec.HandleProto(r, fn)
+}
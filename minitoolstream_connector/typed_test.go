@@ -0,0 +1,188 @@
+package minitoolstream_connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// preparedRecorder is a Publisher recording the prepared messages
+type preparedRecorder struct {
+	Publisher
+	messages []*PublishMessage
+}
+
+func (r *preparedRecorder) Publish(ctx context.Context, preparer MessagePreparer) error {
+	msg, err := preparer.Prepare(ctx)
+	if err != nil {
+		return err
+	}
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+// handlerRecorder is a Subscriber recording the registered handlers
+type handlerRecorder struct {
+	Subscriber
+	handlers map[string]MessageHandler
+}
+
+func (r *handlerRecorder) RegisterHandler(subject string, handler MessageHandler) {
+	if r.handlers == nil {
+		r.handlers = make(map[string]MessageHandler)
+	}
+	r.handlers[subject] = handler
+}
+
+type typedOrder struct {
+	ID    string `json:"id"`
+	Total int    `json:"total"`
+}
+
+func TestSubscribeJSON(t *testing.T) {
+	sub := &handlerRecorder{}
+	var got []typedOrder
+	SubscribeJSON(sub, "orders", func(ctx context.Context, order typedOrder, msg *ReceivedMessage) error {
+		got = append(got, order)
+		return nil
+	})
+	handler := sub.handlers["orders"]
+	if handler == nil {
+		t.Fatal("expected a handler for orders")
+	}
+
+	for _, contentType := range []string{"", "application/json", "application/json; charset=utf-8", "application/vnd.orders+json"} {
+		msg := &ReceivedMessage{Subject: "orders", Data: []byte(`{"id":"a-1","total":42}`), Headers: map[string]string{"content-type": contentType}}
+		if err := handler.Handle(context.Background(), msg); err != nil {
+			t.Errorf("content-type %q: expected the payload to decode, got %v", contentType, err)
+		}
+	}
+	if len(got) != 4 || got[0] != (typedOrder{ID: "a-1", Total: 42}) {
+		t.Errorf("unexpected decoded values: %+v", got)
+	}
+
+	for name, msg := range map[string]*ReceivedMessage{
+		"malformed":    {Subject: "orders", Data: []byte(`{"id":`)},
+		"content type": {Subject: "orders", Data: []byte(`{}`), Headers: map[string]string{"content-type": "image/png"}},
+	} {
+		if err := handler.Handle(context.Background(), msg); !errors.Is(err, ErrDecode) {
+			t.Errorf("%s: expected ErrDecode, got %v", name, err)
+		}
+	}
+	if len(got) != 4 {
+		t.Errorf("expected the callback not to run for undecodable messages, got %d calls", len(got))
+	}
+}
+
+func TestPublishJSON(t *testing.T) {
+	pub := &preparedRecorder{}
+	order := typedOrder{ID: "a-1", Total: 42}
+	if err := PublishJSON(context.Background(), pub, "orders", order, Header{Name: "tenant", Value: "acme"}); err != nil {
+		t.Fatalf("PublishJSON failed: %v", err)
+	}
+
+	msg := pub.messages[0]
+	var decoded typedOrder
+	if err := json.Unmarshal(msg.Data, &decoded); err != nil || decoded != order {
+		t.Errorf("expected the order as JSON, got %s (%v)", msg.Data, err)
+	}
+	if msg.Subject != "orders" || msg.Headers["content-type"] != "application/json" || msg.Headers["tenant"] != "acme" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+
+	if err := PublishJSON(context.Background(), pub, "orders", func() {}); err == nil {
+		t.Error("expected an error for a value JSON cannot encode")
+	}
+}
+
+func TestPublishProto(t *testing.T) {
+	pub := &preparedRecorder{}
+	if err := PublishProto(context.Background(), pub, "names", wrapperspb.String("ada"), Header{Name: "tenant", Value: "acme"}); err != nil {
+		t.Fatalf("PublishProto failed: %v", err)
+	}
+
+	msg := pub.messages[0]
+	var decoded wrapperspb.StringValue
+	if err := proto.Unmarshal(msg.Data, &decoded); err != nil || decoded.GetValue() != "ada" {
+		t.Errorf("expected the message as protobuf, got %x (%v)", msg.Data, err)
+	}
+	if msg.Headers["content-type"] != "application/x-protobuf" || msg.Headers[domain.HeaderProtoMessage] != "google.protobuf.StringValue" || msg.Headers["tenant"] != "acme" {
+		t.Errorf("unexpected headers: %v", msg.Headers)
+	}
+}
+
+func TestTypedHelpers_Codecs(t *testing.T) {
+	pub := &preparedRecorder{}
+	if err := PublishEncoded(context.Background(), pub, "names", "application/x-protobuf", wrapperspb.String("ada")); err != nil {
+		t.Fatalf("PublishEncoded failed: %v", err)
+	}
+	if err := PublishEncoded(context.Background(), pub, "names", "application/json", map[string]string{"value": "grace"}); err != nil {
+		t.Fatalf("PublishEncoded failed: %v", err)
+	}
+	if err := PublishEncoded(context.Background(), pub, "names", "image/png", "x"); err == nil {
+		t.Error("expected error for a content type without a codec")
+	}
+
+	sub := &handlerRecorder{}
+	var got []string
+	SubscribeTyped(sub, "names", func(ctx context.Context, v *wrapperspb.StringValue, msg *ReceivedMessage) error {
+		got = append(got, v.GetValue())
+		return nil
+	})
+	for _, msg := range pub.messages {
+		received := &ReceivedMessage{Subject: msg.Subject, Data: msg.Data, Headers: msg.Headers}
+		if err := sub.handlers["names"].Handle(context.Background(), received); err != nil {
+			t.Errorf("content-type %s: Handle failed: %v", msg.Headers["content-type"], err)
+		}
+	}
+	if len(got) != 2 || got[0] != "ada" || got[1] != "grace" {
+		t.Errorf("expected both formats to decode, got %q", got)
+	}
+}
+
+func TestPublishAny_TypeRegistry(t *testing.T) {
+	pub := &preparedRecorder{}
+	for _, m := range []proto.Message{wrapperspb.String("ada"), wrapperspb.Int64(42)} {
+		packed, err := anypb.New(m)
+		if err != nil {
+			t.Fatalf("anypb.New failed: %v", err)
+		}
+		if err := PublishAny(context.Background(), pub, "events", packed); err != nil {
+			t.Fatalf("PublishAny failed: %v", err)
+		}
+	}
+	if err := PublishAny(context.Background(), pub, "events", &anypb.Any{}); err == nil {
+		t.Error("expected error for an Any without type URL")
+	}
+	if got := pub.messages[0].Headers[domain.HeaderProtoTypeURL]; got != "type.googleapis.com/google.protobuf.StringValue" {
+		t.Errorf("unexpected type URL header %q", got)
+	}
+
+	types := NewTypeRegistry()
+	var got []string
+	HandleProtoType(types, func(ctx context.Context, m *wrapperspb.StringValue, msg *ReceivedMessage) error {
+		got = append(got, m.GetValue())
+		return nil
+	})
+	HandleProtoType(types, func(ctx context.Context, m *wrapperspb.Int64Value, msg *ReceivedMessage) error {
+		got = append(got, fmt.Sprint(m.GetValue()))
+		return nil
+	})
+	for _, msg := range pub.messages {
+		received := &ReceivedMessage{Subject: msg.Subject, Data: msg.Data, Headers: msg.Headers}
+		if err := types.Handle(context.Background(), received); err != nil {
+			t.Errorf("Handle failed: %v", err)
+		}
+	}
+	if len(got) != 2 || got[0] != "ada" || got[1] != "42" {
+		t.Errorf("expected dispatch by type, got %q", got)
+	}
+}
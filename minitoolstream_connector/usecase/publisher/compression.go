@@ -0,0 +1,71 @@
+package publisher
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/compression"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// CompressionRule sets how the payloads of matching subjects are compressed
+type CompressionRule struct {
+	// Subject is a path.Match pattern such as "telemetry.*"; empty matches
+	// every subject
+	Subject string
+	// Compressor compresses matching payloads; nil leaves them uncompressed,
+	// e.g. to exempt subjects from a later catch-all rule
+	Compressor domain.Compressor
+	// MinSize leaves payloads smaller than this many bytes uncompressed
+	MinSize int
+}
+
+// matches reports whether the rule applies to subject
+func (r CompressionRule) matches(subject string) bool {
+	if r.Subject == "" {
+		return true
+	}
+	ok, _ := path.Match(r.Subject, subject)
+	return ok
+}
+
+// ValidateCompression checks the subject patterns and sizes of rules
+func ValidateCompression(rules []CompressionRule) error {
+	for _, rule := range rules {
+		if _, err := path.Match(rule.Subject, ""); err != nil {
+			return fmt.Errorf("invalid compression subject pattern %q: %w", rule.Subject, err)
+		}
+		if rule.MinSize < 0 {
+			return fmt.Errorf("compression min size cannot be negative, got %d", rule.MinSize)
+		}
+	}
+	return nil
+}
+
+// compress applies the first compression rule matching the subject of msg.
+// Messages that already carry a content-encoding are left as they are, as
+// are payloads compression would not shrink.
+func (p *SimplePublisher) compress(idx int, msg *domain.PublishMessage) error {
+	if msg.Headers[domain.HeaderContentEncoding] != "" {
+		return nil
+	}
+	for _, rule := range p.compression {
+		if !rule.matches(msg.Subject) {
+			continue
+		}
+		if rule.Compressor == nil || len(msg.Data) < rule.MinSize {
+			return nil
+		}
+
+		size := len(msg.Data)
+		compressed, err := compression.Compress(rule.Compressor, msg)
+		if err != nil {
+			return err
+		}
+		if compressed {
+			p.logf(domain.LogLevelDebug, "[%d] Compressed payload with %s: %d -> %d bytes", idx, rule.Compressor.Encoding(), size, len(msg.Data))
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/compression"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestSimplePublisher_Compression(t *testing.T) {
+	published := make(map[string]*domain.PublishMessage)
+	client := &mockIngressClient{
+		publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+			published[msg.Subject] = msg
+			return &domain.PublishResult{}, nil
+		},
+	}
+	pub, err := New(&Config{
+		Client: client,
+		Logger: discardLogger{},
+		Compression: []CompressionRule{
+			{Subject: "telemetry.*", Compressor: compression.Zstd{}},
+			{Subject: "images.*"},
+			{Compressor: compression.Gzip{}, MinSize: 100},
+		},
+		// Compression brings the payloads under the limit
+		MaxPayloadSize: 1000,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	payload := []byte(strings.Repeat("sample ", 200))
+	for _, subject := range []string{"telemetry.cpu", "images.png", "orders", "tiny"} {
+		data := payload
+		if subject == "tiny" {
+			data = []byte("small")
+		}
+		err := pub.Publish(context.Background(), domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: subject, Data: data}, nil
+		}))
+		if subject == "images.png" {
+			if err == nil {
+				t.Error("expected the uncompressed image to exceed the payload limit")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: Publish failed: %v", subject, err)
+		}
+	}
+
+	want := map[string]string{"telemetry.cpu": "zstd", "orders": "gzip", "tiny": ""}
+	for subject, encoding := range want {
+		msg := published[subject]
+		if got := msg.Headers[domain.HeaderContentEncoding]; got != encoding {
+			t.Errorf("%s: content-encoding = %q, want %q", subject, got, encoding)
+		}
+		if encoding == "" {
+			continue
+		}
+		restored, err := compression.Default.Decompress(&domain.ReceivedMessage{Data: msg.Data, Headers: msg.Headers})
+		if err != nil || !bytes.Equal(restored.Data, payload) {
+			t.Errorf("%s: payload does not decompress: %v", subject, err)
+		}
+	}
+}
+
+func TestNew_InvalidCompression(t *testing.T) {
+	for _, rule := range []CompressionRule{{Subject: "["}, {MinSize: -1}} {
+		if _, err := New(&Config{Client: &mockIngressClient{}, Compression: []CompressionRule{rule}}); err == nil {
+			t.Errorf("expected error for rule %+v", rule)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// ensureCorrelationID sets the correlation-id header of a message that lacks
+// one, taking the ID from ctx or, with CorrelationIDs enabled, generating it.
+// It returns the ID in use, which may be empty.
+func (p *SimplePublisher) ensureCorrelationID(ctx context.Context, msg *domain.PublishMessage) string {
+	if id := msg.Headers[domain.HeaderCorrelationID]; id != "" {
+		return id
+	}
+
+	id := domain.CorrelationID(ctx)
+	if id == "" && p.correlationIDs {
+		id = newUUID()
+	}
+	if id != "" {
+		msg.SetCorrelationID(id)
+	}
+	return id
+}
+
+// correlationSuffix formats a correlation ID for log lines
+func correlationSuffix(id string) string {
+	if id == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (correlation-id=%s)", id)
+}
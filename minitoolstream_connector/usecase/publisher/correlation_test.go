@@ -0,0 +1,63 @@
+package publisher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestSimplePublisher_CorrelationIDs(t *testing.T) {
+	publish := func(t *testing.T, config *Config, ctx context.Context, headers map[string]string) string {
+		t.Helper()
+		var sent string
+		config.Client = &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				sent = msg.Headers[domain.HeaderCorrelationID]
+				return &domain.PublishResult{Sequence: 1}, nil
+			},
+		}
+		config.Logger = &testLogger{}
+		pub, _ := New(config)
+
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "test", Headers: headers}, nil
+		})
+		if err := pub.Publish(ctx, preparer); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return sent
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		if id := publish(t, &Config{}, context.Background(), nil); id != "" {
+			t.Errorf("expected no correlation ID, got %q", id)
+		}
+	})
+
+	t.Run("generated when enabled", func(t *testing.T) {
+		first := publish(t, &Config{CorrelationIDs: true}, context.Background(), nil)
+		second := publish(t, &Config{CorrelationIDs: true}, context.Background(), nil)
+		if first == "" || first == second {
+			t.Errorf("expected distinct generated IDs, got %q and %q", first, second)
+		}
+	})
+
+	t.Run("inherited from context", func(t *testing.T) {
+		ctx := domain.WithCorrelationID(context.Background(), "req-1")
+		if id := publish(t, &Config{CorrelationIDs: true}, ctx, nil); id != "req-1" {
+			t.Errorf("expected req-1, got %q", id)
+		}
+		if id := publish(t, &Config{}, ctx, nil); id != "req-1" {
+			t.Errorf("expected req-1 without CorrelationIDs, got %q", id)
+		}
+	})
+
+	t.Run("existing header is kept", func(t *testing.T) {
+		ctx := domain.WithCorrelationID(context.Background(), "req-1")
+		headers := map[string]string{domain.HeaderCorrelationID: "req-2"}
+		if id := publish(t, &Config{CorrelationIDs: true}, ctx, headers); id != "req-2" {
+			t.Errorf("expected req-2, got %q", id)
+		}
+	})
+}
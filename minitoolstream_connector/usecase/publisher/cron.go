@@ -0,0 +1,221 @@
+package publisher
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// intervalSchedule runs at a fixed interval
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Every returns a schedule that runs once per interval
+func Every(interval time.Duration) domain.Schedule {
+	return intervalSchedule{interval: interval}
+}
+
+// Next implements domain.Schedule
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(s.interval)
+}
+
+// cronSchedule is a parsed cron expression. Each field is a bit set of the
+// values it matches.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// anyDom and anyDow record unrestricted day fields: when both day fields
+	// are restricted, a day matching either of them matches
+	anyDom, anyDow bool
+	location       *time.Location
+}
+
+// cronField describes the value range of a cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 6},
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a standard five-field cron expression (minute, hour, day of
+// month, month, day of week) evaluated in local time. Fields accept *, values,
+// ranges (1-5), lists (1,15) and steps (*/10, 0-30/5); day of week 7 is Sunday.
+// The descriptors @yearly, @monthly, @weekly, @daily, @hourly and
+// "@every <duration>" are also accepted.
+func ParseCron(expr string) (domain.Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid @every duration: %w", err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("@every duration must be positive, got %v", interval)
+		}
+		return Every(interval), nil
+	}
+
+	if descriptor, ok := cronDescriptors[expr]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression must have %d fields, got %d", len(cronFields), len(fields))
+	}
+
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+
+	// Day of week 7 is an alias for Sunday
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &cronSchedule{
+		minute:   sets[0],
+		hour:     sets[1],
+		dom:      sets[2],
+		month:    sets[3],
+		dow:      sets[4],
+		anyDom:   fields[2] == "*",
+		anyDow:   fields[4] == "*",
+		location: time.Local,
+	}, nil
+}
+
+// parseCronField parses one comma-separated cron field into a bit set
+func parseCronField(field string, spec cronField) (uint64, error) {
+	max := spec.max
+	if spec.name == "day of week" {
+		max = 7
+	}
+
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepPart, spec.name)
+			}
+			step = n
+		}
+
+		lo, hi := spec.min, spec.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = cronValue(from, spec, max); err != nil {
+				return 0, err
+			}
+			if hi, err = cronValue(to, spec, max); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangePart, spec.name)
+			}
+		default:
+			v, err := cronValue(rangePart, spec, max)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			hi = v
+			if hasStep {
+				hi = spec.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+// cronValue parses a single cron field value
+func cronValue(s string, spec cronField, max int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < spec.min || v > max {
+		return 0, fmt.Errorf("invalid value %q in %s field (allowed %d-%d)", s, spec.name, spec.min, max)
+	}
+	return v, nil
+}
+
+// Next implements domain.Schedule
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	after = after.In(s.location)
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute()+1, 0, 0, s.location)
+
+	// Give up after five years, e.g. for 30 February
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron rule for combining day of month and day of week
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDom && s.anyDow:
+		return true
+	case s.anyDom:
+		return dowMatch
+	case s.anyDow:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
@@ -0,0 +1,57 @@
+package publisher
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	base := time.Date(2024, time.March, 15, 10, 7, 30, 0, time.Local) // a Friday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.March, 15, 10, 8, 0, 0, time.Local)},
+		{"*/15 * * * *", time.Date(2024, time.March, 15, 10, 15, 0, 0, time.Local)},
+		{"0 9-17 * * *", time.Date(2024, time.March, 15, 11, 0, 0, 0, time.Local)},
+		{"30 2 * * *", time.Date(2024, time.March, 16, 2, 30, 0, 0, time.Local)},
+		{"0 0 1 * *", time.Date(2024, time.April, 1, 0, 0, 0, 0, time.Local)},
+		{"0 12 * * 1,3", time.Date(2024, time.March, 18, 12, 0, 0, 0, time.Local)},
+		{"0 0 * * 7", time.Date(2024, time.March, 17, 0, 0, 0, 0, time.Local)},
+		{"0 0 13 * 5", time.Date(2024, time.March, 22, 0, 0, 0, 0, time.Local)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.Local)},
+		{"@hourly", time.Date(2024, time.March, 15, 11, 0, 0, 0, time.Local)},
+		{"@every 90s", base.Add(90 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron failed: %v", err)
+			}
+			if got := schedule.Next(base); !got.Equal(tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("impossible date", func(t *testing.T) {
+		schedule, err := ParseCron("0 0 30 2 *")
+		if err != nil {
+			t.Fatalf("ParseCron failed: %v", err)
+		}
+		if got := schedule.Next(base); !got.IsZero() {
+			t.Errorf("expected no run, got %v", got)
+		}
+	})
+
+	t.Run("invalid expressions", func(t *testing.T) {
+		for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "@every -1s", "@sometimes"} {
+			if _, err := ParseCron(expr); err == nil {
+				t.Errorf("expected error for %q", expr)
+			}
+		}
+	})
+}
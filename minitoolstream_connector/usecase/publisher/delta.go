@@ -0,0 +1,136 @@
+package publisher
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"path"
+	"strconv"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// defaultSnapshotEvery is how many deltas follow a full snapshot by default
+const defaultSnapshotEvery = 100
+
+// DeltaRule sends the payloads of matching subjects as deltas against the
+// last full snapshot of the subject
+type DeltaRule struct {
+	// Subject is a path.Match pattern such as "documents.*"; empty matches
+	// every subject
+	Subject string
+	// Differ computes the deltas; nil sends matching payloads in full, e.g.
+	// to exempt subjects from a later catch-all rule
+	Differ domain.Differ
+	// SnapshotEvery sends a full snapshot after this many deltas, bounding
+	// how long a new subscriber waits for a snapshot (default 100)
+	SnapshotEvery int
+}
+
+// matches reports whether the rule applies to subject
+func (r DeltaRule) matches(subject string) bool {
+	if r.Subject == "" {
+		return true
+	}
+	ok, _ := path.Match(r.Subject, subject)
+	return ok
+}
+
+// ValidateDelta checks the subject patterns and snapshot intervals of rules
+func ValidateDelta(rules []DeltaRule) error {
+	for _, rule := range rules {
+		if _, err := path.Match(rule.Subject, ""); err != nil {
+			return fmt.Errorf("invalid delta subject pattern %q: %w", rule.Subject, err)
+		}
+		if rule.SnapshotEvery < 0 {
+			return fmt.Errorf("delta snapshot interval cannot be negative, got %d", rule.SnapshotEvery)
+		}
+	}
+	return nil
+}
+
+// snapshot is the last full payload published on a subject
+type snapshot struct {
+	sequence uint64
+	data     []byte
+	deltas   int
+}
+
+// pendingSnapshot is a full payload that becomes the snapshot of its subject
+// once published
+type pendingSnapshot struct {
+	msg  *domain.PublishMessage
+	data []byte
+}
+
+// applyDelta replaces the payload of msg with a delta against the snapshot
+// of its subject when a delta rule matches. Messages sent in full instead,
+// because there is no snapshot yet, one is due or the delta would not be
+// smaller, are returned as the pending snapshot of the subject.
+func (p *SimplePublisher) applyDelta(idx int, msg *domain.PublishMessage) *pendingSnapshot {
+	if msg.Headers[domain.HeaderDeltaFormat] != "" || msg.Headers[domain.HeaderContentEncoding] != "" {
+		return nil
+	}
+	var rule DeltaRule
+	for _, r := range p.delta {
+		if r.matches(msg.Subject) {
+			rule = r
+			break
+		}
+	}
+	if rule.Differ == nil {
+		return nil
+	}
+	every := rule.SnapshotEvery
+	if every == 0 {
+		every = defaultSnapshotEvery
+	}
+	pending := &pendingSnapshot{msg: msg, data: bytes.Clone(msg.Data)}
+
+	p.snapshotsMu.Lock()
+	snap, ok := p.snapshots[msg.Subject]
+	p.snapshotsMu.Unlock()
+	if !ok || snap.deltas >= every {
+		return pending
+	}
+
+	delta, err := rule.Differ.Diff(snap.data, msg.Data)
+	if err != nil {
+		p.logf(domain.LogLevelDebug, "[%d] Sending full payload, %s diff failed: %v", idx, rule.Differ.Format(), err)
+		return pending
+	}
+	if len(delta) >= len(msg.Data) {
+		return pending
+	}
+
+	p.snapshotsMu.Lock()
+	if current, ok := p.snapshots[msg.Subject]; ok && current.sequence == snap.sequence {
+		current.deltas++
+		p.snapshots[msg.Subject] = current
+	}
+	p.snapshotsMu.Unlock()
+
+	p.logf(domain.LogLevelDebug, "[%d] Sending %s delta against sequence %d: %d -> %d bytes", idx, rule.Differ.Format(), snap.sequence, len(msg.Data), len(delta))
+	msg.Headers = maps.Clone(msg.Headers)
+	if msg.Headers == nil {
+		msg.Headers = make(map[string]string, 2)
+	}
+	msg.Headers[domain.HeaderDeltaFormat] = rule.Differ.Format()
+	msg.Headers[domain.HeaderDeltaBase] = strconv.FormatUint(snap.sequence, 10)
+	msg.Data = delta
+	return nil
+}
+
+// recordSnapshot makes a published full payload the snapshot of its subject,
+// unless the message was replaced before sending or a later snapshot exists
+func (p *SimplePublisher) recordSnapshot(pending *pendingSnapshot, sent *domain.PublishMessage, result *domain.PublishResult) {
+	if pending == nil || pending.msg != sent || result.StatusCode != 0 {
+		return
+	}
+	p.snapshotsMu.Lock()
+	defer p.snapshotsMu.Unlock()
+	if snap, ok := p.snapshots[sent.Subject]; ok && snap.sequence > result.Sequence {
+		return
+	}
+	p.snapshots[sent.Subject] = snapshot{sequence: result.Sequence, data: pending.data}
+}
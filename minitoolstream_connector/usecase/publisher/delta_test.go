@@ -0,0 +1,73 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/delta"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestSimplePublisher_Delta(t *testing.T) {
+	var published []*domain.PublishMessage
+	client := &mockIngressClient{
+		publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+			published = append(published, msg)
+			return &domain.PublishResult{Sequence: uint64(len(published))}, nil
+		},
+	}
+	pub, err := New(&Config{
+		Client: client,
+		Logger: discardLogger{},
+		Delta: []DeltaRule{
+			{Subject: "raw.*"},
+			{Differ: delta.JSONPatch{}, SnapshotEvery: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	document := func(version int) []byte {
+		return []byte(fmt.Sprintf(`{"items":[%s],"version":%d}`, strings.Repeat(`"item",`, 50)+`"last"`, version))
+	}
+	publish := func(subject string, data []byte) {
+		err := pub.Publish(context.Background(), domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: subject, Data: data}, nil
+		}))
+		if err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	for version := 1; version <= 4; version++ {
+		publish("documents", document(version))
+	}
+	publish("raw.documents", document(5))
+	publish("documents", []byte("not json"))
+
+	// Snapshot, two deltas, then the next snapshot
+	wantBases := []string{"", "1", "1", "", "", ""}
+	r := delta.NewReconstructor()
+	for i, msg := range published {
+		if got := msg.Headers[domain.HeaderDeltaBase]; got != wantBases[i] {
+			t.Errorf("message %d: delta-base = %q, want %q", i+1, got, wantBases[i])
+		}
+		full, err := r.Reconstruct(&domain.ReceivedMessage{Subject: msg.Subject, Sequence: uint64(i + 1), Data: msg.Data, Headers: msg.Headers})
+		if err != nil {
+			t.Fatalf("message %d: Reconstruct failed: %v", i+1, err)
+		}
+		if i < 4 && string(full.Data) != string(document(i+1)) {
+			t.Errorf("message %d: reconstructed %s", i+1, full.Data)
+		}
+	}
+}
+
+func TestNew_InvalidDelta(t *testing.T) {
+	for _, rule := range []DeltaRule{{Subject: "["}, {SnapshotEvery: -1}} {
+		if _, err := New(&Config{Client: &mockIngressClient{}, Delta: []DeltaRule{rule}}); err == nil {
+			t.Errorf("expected error for rule %+v", rule)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// PublishFanOut publishes the same data and headers to every subject
+// concurrently. An idempotency key in headers is suffixed with ":<subject>",
+// so each subject's copy is deduplicated on its own. The results are returned
+// in subject order; the error joins the failures of all subjects.
+func (p *SimplePublisher) PublishFanOut(ctx context.Context, subjects []string, data []byte, headers map[string]string) ([]domain.FanOutResult, error) {
+	if len(subjects) == 0 {
+		return nil, fmt.Errorf("no subjects to publish to")
+	}
+
+	p.logf(domain.LogLevelInfo, "Publishing to %d subjects...", len(subjects))
+
+	results := make([]domain.FanOutResult, len(subjects))
+	var wg sync.WaitGroup
+	for i, subject := range subjects {
+		wg.Add(1)
+		go func(idx int, subject string) {
+			defer wg.Done()
+
+			msg := &domain.PublishMessage{
+				Subject: subject,
+				Data:    data,
+				Headers: make(map[string]string, len(headers)),
+			}
+			for k, v := range headers {
+				msg.Headers[k] = v
+			}
+			if key := headers[domain.HeaderIdempotencyKey]; key != "" {
+				msg.Headers[domain.HeaderIdempotencyKey] = key + ":" + subject
+			}
+
+			result, err := p.publishMessage(ctx, idx+1, msg)
+			if err != nil {
+				err = fmt.Errorf("subject %s: %w", subject, err)
+			}
+			results[idx] = domain.FanOutResult{Subject: subject, Result: result, Err: err}
+		}(i, subject)
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	if len(errs) > 0 {
+		return results, errors.Join(errs...)
+	}
+
+	p.logf(domain.LogLevelInfo, "✓ Published to all %d subjects", len(subjects))
+	return results, nil
+}
@@ -0,0 +1,102 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestSimplePublisher_PublishFanOut(t *testing.T) {
+	t.Run("publishes to every subject", func(t *testing.T) {
+		var mu sync.Mutex
+		received := make(map[string]*domain.PublishMessage)
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				received[msg.Subject] = msg
+				return &domain.PublishResult{Sequence: uint64(len(msg.Subject))}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+
+		results, err := pub.PublishFanOut(context.Background(), []string{"a", "bb"}, []byte("x"), map[string]string{"k": "v"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(results) != 2 || results[0].Subject != "a" || results[1].Result.Sequence != 2 {
+			t.Errorf("unexpected results: %+v", results)
+		}
+		if received["bb"].Headers["k"] != "v" || string(received["a"].Data) != "x" {
+			t.Errorf("unexpected messages: %v", received)
+		}
+	})
+
+	t.Run("idempotency keys are per subject", func(t *testing.T) {
+		var mu sync.Mutex
+		keys := make(map[string]string)
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				keys[msg.Subject] = msg.Headers[domain.HeaderIdempotencyKey]
+				return &domain.PublishResult{Sequence: uint64(len(keys))}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, IdempotencyKeys: true, Logger: discardLogger{}})
+
+		headers := map[string]string{domain.HeaderIdempotencyKey: "order-7"}
+		if _, err := pub.PublishFanOut(context.Background(), []string{"orders", "audit"}, []byte("x"), headers); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if keys["orders"] != "order-7:orders" || keys["audit"] != "order-7:audit" {
+			t.Errorf("expected every subject to be published with its own key, got %v", keys)
+		}
+		if headers[domain.HeaderIdempotencyKey] != "order-7" {
+			t.Error("expected the caller's headers to be left unchanged")
+		}
+
+		// Retrying the fan-out hits the cache for every subject
+		results, err := pub.PublishFanOut(context.Background(), []string{"orders", "audit"}, []byte("x"), headers)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(keys) != 2 || results[0].Result == nil || results[1].Result == nil {
+			t.Errorf("expected cached results without republishing, got %+v", results)
+		}
+	})
+
+	t.Run("reports per-subject failures", func(t *testing.T) {
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				if msg.Subject == "down" {
+					return nil, errors.New("unavailable")
+				}
+				return &domain.PublishResult{Sequence: 1}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+
+		results, err := pub.PublishFanOut(context.Background(), []string{"up", "down"}, nil, nil)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if results[0].Err != nil || results[0].Result == nil {
+			t.Errorf("expected success for 'up', got %+v", results[0])
+		}
+		if results[1].Err == nil {
+			t.Errorf("expected failure for 'down', got %+v", results[1])
+		}
+	})
+
+	t.Run("no subjects", func(t *testing.T) {
+		pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: discardLogger{}})
+		if _, err := pub.PublishFanOut(context.Background(), nil, nil, nil); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
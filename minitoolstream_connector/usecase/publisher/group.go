@@ -0,0 +1,92 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// PublishGroup publishes the preparers as one all-or-nothing group. Every
+// message is prepared before anything is published, so a preparer error
+// publishes nothing. The messages are then published in order with shared
+// group-id, group-size and group-index headers. If a publish fails, a
+// compensating message with the group-aborted header is sent to every subject
+// that already received part of the group so consumers can discard it.
+func (p *SimplePublisher) PublishGroup(ctx context.Context, preparers []domain.MessagePreparer) error {
+	if len(preparers) == 0 {
+		return fmt.Errorf("no message preparers to publish")
+	}
+
+	groupID := newUUID()
+	messages := make([]*domain.PublishMessage, len(preparers))
+	for i, preparer := range preparers {
+		msg, err := preparer.Prepare(ctx)
+		if err != nil {
+			return fmt.Errorf("message %d: failed to prepare message: %w", i+1, err)
+		}
+		if msg == nil {
+			return fmt.Errorf("message %d: preparer returned nil message", i+1)
+		}
+		if msg.Headers == nil {
+			msg.Headers = make(map[string]string)
+		}
+		msg.Headers[domain.HeaderGroupID] = groupID
+		msg.Headers[domain.HeaderGroupSize] = strconv.Itoa(len(preparers))
+		msg.Headers[domain.HeaderGroupIndex] = strconv.Itoa(i)
+		messages[i] = msg
+	}
+
+	p.logf(domain.LogLevelInfo, "Publishing group %s of %d messages...", groupID, len(messages))
+
+	var published []string
+	for i, msg := range messages {
+		if _, err := p.publishMessage(ctx, i+1, msg); err != nil {
+			err = fmt.Errorf("group %s: message %d: %w", groupID, i+1, err)
+			if abortErr := p.abortGroup(ctx, groupID, published); abortErr != nil {
+				return errors.Join(err, abortErr)
+			}
+			return err
+		}
+		published = appendSubject(published, msg.Subject)
+	}
+
+	p.logf(domain.LogLevelInfo, "✓ Group %s published (%d messages)", groupID, len(messages))
+	return nil
+}
+
+// abortGroup sends the group-aborted message to each subject
+func (p *SimplePublisher) abortGroup(ctx context.Context, groupID string, subjects []string) error {
+	if len(subjects) == 0 {
+		return nil
+	}
+
+	p.logf(domain.LogLevelError, "✗ Aborting group %s on %d subjects", groupID, len(subjects))
+
+	var errs []error
+	for _, subject := range subjects {
+		msg := &domain.PublishMessage{
+			Subject: subject,
+			Headers: map[string]string{
+				domain.HeaderGroupID:      groupID,
+				domain.HeaderGroupAborted: "true",
+			},
+		}
+		if _, err := p.publishWithRetry(ctx, 0, msg); err != nil {
+			errs = append(errs, fmt.Errorf("failed to abort group on subject %s: %w", subject, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// appendSubject appends subject unless it is already present
+func appendSubject(subjects []string, subject string) []string {
+	for _, s := range subjects {
+		if s == subject {
+			return subjects
+		}
+	}
+	return append(subjects, subject)
+}
@@ -0,0 +1,106 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func groupPreparers(subjects ...string) []domain.MessagePreparer {
+	preparers := make([]domain.MessagePreparer, len(subjects))
+	for i, subject := range subjects {
+		subject := subject
+		preparers[i] = domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: subject, Data: []byte(subject)}, nil
+		})
+	}
+	return preparers
+}
+
+func TestSimplePublisher_PublishGroup(t *testing.T) {
+	t.Run("publishes with shared group headers", func(t *testing.T) {
+		var published []*domain.PublishMessage
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				published = append(published, msg)
+				return &domain.PublishResult{Sequence: uint64(len(published))}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+
+		if err := pub.PublishGroup(context.Background(), groupPreparers("orders", "payments")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(published) != 2 {
+			t.Fatalf("expected 2 messages, got %d", len(published))
+		}
+		groupID := published[0].Headers[domain.HeaderGroupID]
+		if groupID == "" || published[1].Headers[domain.HeaderGroupID] != groupID {
+			t.Errorf("expected shared group id, got %v", published)
+		}
+		if published[1].Headers[domain.HeaderGroupIndex] != "1" || published[1].Headers[domain.HeaderGroupSize] != "2" {
+			t.Errorf("unexpected group headers: %v", published[1].Headers)
+		}
+	})
+
+	t.Run("prepare failure publishes nothing", func(t *testing.T) {
+		calls := 0
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				calls++
+				return &domain.PublishResult{}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+
+		preparers := append(groupPreparers("orders"), domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return nil, errors.New("boom")
+		}))
+		if err := pub.PublishGroup(context.Background(), preparers); err == nil {
+			t.Fatal("expected error")
+		}
+		if calls != 0 {
+			t.Errorf("expected no publishes, got %d", calls)
+		}
+	})
+
+	t.Run("publish failure aborts the group", func(t *testing.T) {
+		var published []*domain.PublishMessage
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				if msg.Subject == "payments" && msg.Headers[domain.HeaderGroupAborted] == "" {
+					return nil, errors.New("unavailable")
+				}
+				published = append(published, msg)
+				return &domain.PublishResult{}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+
+		err := pub.PublishGroup(context.Background(), groupPreparers("orders", "orders", "payments"))
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		if len(published) != 3 {
+			t.Fatalf("expected 2 messages and 1 abort, got %d", len(published))
+		}
+		abort := published[2]
+		if abort.Subject != "orders" || abort.Headers[domain.HeaderGroupAborted] != "true" {
+			t.Errorf("unexpected abort message: %+v", abort)
+		}
+		if abort.Headers[domain.HeaderGroupID] != published[0].Headers[domain.HeaderGroupID] {
+			t.Error("expected abort message to carry the group id")
+		}
+	})
+
+	t.Run("no preparers", func(t *testing.T) {
+		pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: &testLogger{}})
+		if err := pub.PublishGroup(context.Background(), nil); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package publisher
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// defaultAckCacheSize is the number of acknowledged idempotency keys remembered
+const defaultAckCacheSize = 1024
+
+// newUUID generates a random (version 4) UUID string
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// ContentDedupKey derives a dedup key from the subject and data of a message,
+// so publishing identical content twice yields the same key
+func ContentDedupKey(msg *domain.PublishMessage) string {
+	h := sha256.New()
+	h.Write([]byte(msg.Subject))
+	h.Write([]byte{0})
+	h.Write(msg.Data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ensureIdempotencyKey sets an idempotency key header on the message if absent,
+// preferring the message's DedupKey, and returns the key in use
+func ensureIdempotencyKey(msg *domain.PublishMessage) string {
+	if msg.Headers == nil {
+		msg.Headers = make(map[string]string)
+	}
+	if msg.DedupKey != "" {
+		msg.Headers[domain.HeaderIdempotencyKey] = msg.DedupKey
+	}
+	key := msg.Headers[domain.HeaderIdempotencyKey]
+	if key == "" {
+		key = newUUID()
+		msg.Headers[domain.HeaderIdempotencyKey] = key
+	}
+	return key
+}
+
+// ackCache remembers results of acknowledged publishes by subject and
+// idempotency key. It is bounded and evicts the oldest keys first.
+type ackCache struct {
+	mu      sync.Mutex
+	size    int
+	order   []string
+	results map[string]*domain.PublishResult
+}
+
+// newAckCache creates a new bounded acknowledgement cache
+func newAckCache(size int) *ackCache {
+	if size <= 0 {
+		size = defaultAckCacheSize
+	}
+	return &ackCache{
+		size:    size,
+		results: make(map[string]*domain.PublishResult),
+	}
+}
+
+// get returns the cached result for a key on a subject
+func (c *ackCache) get(subject, key string) (*domain.PublishResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.results[subject+"\x00"+key]
+	return result, ok
+}
+
+// put stores the result for a key on a subject, evicting the oldest entry
+// when full
+func (c *ackCache) put(subject, key string, result *domain.PublishResult) {
+	key = subject + "\x00" + key
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.results[key]; ok {
+		c.results[key] = result
+		return
+	}
+	if len(c.order) >= c.size {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		delete(c.results, oldest)
+	}
+	c.order = append(c.order, key)
+	c.results[key] = result
+}
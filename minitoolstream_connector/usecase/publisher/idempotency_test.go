@@ -0,0 +1,260 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestNewUUID(t *testing.T) {
+	t.Run("format", func(t *testing.T) {
+		pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+		id := newUUID()
+		if !pattern.MatchString(id) {
+			t.Errorf("unexpected uuid format: %s", id)
+		}
+	})
+
+	t.Run("unique", func(t *testing.T) {
+		if newUUID() == newUUID() {
+			t.Error("expected different uuids")
+		}
+	})
+}
+
+func TestEnsureIdempotencyKey(t *testing.T) {
+	t.Run("generates key when absent", func(t *testing.T) {
+		msg := &domain.PublishMessage{Subject: "test"}
+		key := ensureIdempotencyKey(msg)
+		if key == "" {
+			t.Fatal("expected generated key")
+		}
+		if msg.Headers[domain.HeaderIdempotencyKey] != key {
+			t.Error("expected key to be set in headers")
+		}
+	})
+
+	t.Run("keeps existing key", func(t *testing.T) {
+		msg := &domain.PublishMessage{
+			Subject: "test",
+			Headers: map[string]string{domain.HeaderIdempotencyKey: "order-42"},
+		}
+		if key := ensureIdempotencyKey(msg); key != "order-42" {
+			t.Errorf("expected 'order-42', got %s", key)
+		}
+	})
+
+	t.Run("uses dedup key", func(t *testing.T) {
+		msg := &domain.PublishMessage{Subject: "test", DedupKey: "order-43"}
+		if key := ensureIdempotencyKey(msg); key != "order-43" {
+			t.Errorf("expected 'order-43', got %s", key)
+		}
+	})
+}
+
+func TestContentDedupKey(t *testing.T) {
+	a := ContentDedupKey(&domain.PublishMessage{Subject: "orders", Data: []byte("1")})
+	b := ContentDedupKey(&domain.PublishMessage{Subject: "orders", Data: []byte("1")})
+	c := ContentDedupKey(&domain.PublishMessage{Subject: "orders", Data: []byte("2")})
+	if a == "" || a != b {
+		t.Errorf("expected equal keys for equal content, got %q and %q", a, b)
+	}
+	if a == c {
+		t.Error("expected different keys for different content")
+	}
+}
+
+func TestSimplePublisher_DedupKeyFunc(t *testing.T) {
+	var got *domain.PublishMessage
+	client := &mockIngressClient{
+		publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+			got = msg
+			return &domain.PublishResult{}, nil
+		},
+	}
+	pub, _ := New(&Config{Client: client, Logger: &testLogger{}, DedupKeyFunc: ContentDedupKey})
+
+	pub.Publish(context.Background(), domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{Subject: "orders", Data: []byte("1")}, nil
+	}))
+	if got.DedupKey == "" {
+		t.Error("expected derived dedup key")
+	}
+
+	pub.Publish(context.Background(), domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{Subject: "orders", DedupKey: "explicit"}, nil
+	}))
+	if got.DedupKey != "explicit" {
+		t.Errorf("expected explicit dedup key kept, got %q", got.DedupKey)
+	}
+}
+
+func TestAckCache(t *testing.T) {
+	t.Run("evicts oldest", func(t *testing.T) {
+		cache := newAckCache(2)
+		cache.put("orders", "a", &domain.PublishResult{Sequence: 1})
+		cache.put("orders", "b", &domain.PublishResult{Sequence: 2})
+		cache.put("orders", "c", &domain.PublishResult{Sequence: 3})
+
+		if _, ok := cache.get("orders", "a"); ok {
+			t.Error("expected 'a' to be evicted")
+		}
+		if result, ok := cache.get("orders", "c"); !ok || result.Sequence != 3 {
+			t.Error("expected 'c' to be cached")
+		}
+	})
+
+	t.Run("keys are per subject", func(t *testing.T) {
+		cache := newAckCache(0)
+		cache.put("orders", "a", &domain.PublishResult{Sequence: 1})
+
+		if _, ok := cache.get("payments", "a"); ok {
+			t.Error("expected the key of another subject not to match")
+		}
+	})
+}
+
+func TestSimplePublisher_Idempotency(t *testing.T) {
+	t.Run("key stays stable across retries", func(t *testing.T) {
+		var keys []string
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				keys = append(keys, msg.Headers[domain.HeaderIdempotencyKey])
+				if len(keys) < 3 {
+					return nil, errors.New("connection reset")
+				}
+				return &domain.PublishResult{Sequence: 7}, nil
+			},
+		}
+		pub, _ := New(&Config{
+			Client:          client,
+			Logger:          &testLogger{},
+			IdempotencyKeys: true,
+			MaxRetries:      2,
+			RetryBackoff:    time.Millisecond,
+		})
+
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "test", Data: []byte("data")}, nil
+		})
+
+		if err := pub.Publish(context.Background(), preparer); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(keys) != 3 {
+			t.Fatalf("expected 3 attempts, got %d", len(keys))
+		}
+		if keys[0] == "" || keys[0] != keys[1] || keys[1] != keys[2] {
+			t.Errorf("expected the same key on every attempt, got %v", keys)
+		}
+	})
+
+	t.Run("acknowledged key is not published again", func(t *testing.T) {
+		publishCount := 0
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				publishCount++
+				return &domain.PublishResult{Sequence: 1}, nil
+			},
+		}
+		pub, _ := New(&Config{
+			Client:          client,
+			Logger:          &testLogger{},
+			IdempotencyKeys: true,
+		})
+
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{
+				Subject: "test",
+				Headers: map[string]string{domain.HeaderIdempotencyKey: "fixed"},
+			}, nil
+		})
+
+		_ = pub.Publish(context.Background(), preparer)
+		_ = pub.Publish(context.Background(), preparer)
+
+		if publishCount != 1 {
+			t.Errorf("expected 1 publish, got %d", publishCount)
+		}
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		attempts := 0
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				attempts++
+				return nil, errors.New("unavailable")
+			},
+		}
+		pub, _ := New(&Config{
+			Client:       client,
+			Logger:       &testLogger{},
+			MaxRetries:   1,
+			RetryBackoff: time.Millisecond,
+		})
+
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "test"}, nil
+		})
+
+		if err := pub.Publish(context.Background(), preparer); err == nil {
+			t.Fatal("expected error")
+		}
+		if attempts != 2 {
+			t.Errorf("expected 2 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("permanent errors are not retried", func(t *testing.T) {
+		attempts := 0
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				attempts++
+				return nil, fmt.Errorf("publish failed: %w", domain.ErrUnauthenticated)
+			},
+		}
+		pub, _ := New(&Config{
+			Client:       client,
+			Logger:       &testLogger{},
+			MaxRetries:   3,
+			RetryBackoff: time.Millisecond,
+		})
+
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "test"}, nil
+		})
+
+		err := pub.Publish(context.Background(), preparer)
+		if !errors.Is(err, domain.ErrUnauthenticated) {
+			t.Fatalf("expected ErrUnauthenticated, got %v", err)
+		}
+		if attempts != 1 {
+			t.Errorf("expected 1 attempt, got %d", attempts)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		var headers map[string]string
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				headers = msg.Headers
+				return &domain.PublishResult{}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "test"}, nil
+		})
+		_ = pub.Publish(context.Background(), preparer)
+
+		if _, ok := headers[domain.HeaderIdempotencyKey]; ok {
+			t.Error("expected no idempotency key header")
+		}
+	})
+}
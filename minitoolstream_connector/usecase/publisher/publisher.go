@@ -2,9 +2,12 @@ package publisher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
 )
@@ -14,6 +17,56 @@ type Config struct {
 	Client        domain.IngressClient
 	ResultHandler domain.ResultHandler
 	Logger        Logger
+
+	// IdempotencyKeys attaches an idempotency-key header (a generated UUID
+	// unless the preparer already set one) to every message and skips
+	// re-publishing keys that were already acknowledged
+	IdempotencyKeys bool
+	// CorrelationIDs attaches a generated correlation-id header to messages
+	// that carry none. A correlation ID in the publish context (see
+	// domain.WithCorrelationID) is always attached.
+	CorrelationIDs bool
+	// MaxRetries is the number of additional attempts after a failed Publish call
+	MaxRetries int
+	// RetryBackoff is the initial delay between retries, doubled on each attempt
+	RetryBackoff time.Duration
+	// MessageTTL sets an expires-at header on messages that don't carry one
+	MessageTTL time.Duration
+	// DedupKeyFunc derives the dedup key of messages that don't carry one
+	// (see ContentDedupKey)
+	DedupKeyFunc func(msg *domain.PublishMessage) string
+
+	// MaxPayloadSize rejects messages whose data is larger than this many bytes
+	// with domain.ErrPayloadTooLarge before they are sent (0 disables)
+	MaxPayloadSize int
+	// OnOversize, when set, receives messages over MaxPayloadSize instead of
+	// rejecting them and returns the message to publish in their place, e.g.
+	// a reference to the payload stored elsewhere
+	OnOversize func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishMessage, error)
+
+	// Compression compresses payloads by subject; the first rule matching a
+	// subject decides, and subjects matching none are sent uncompressed
+	Compression []CompressionRule
+	// Delta sends payloads as deltas against the last full snapshot of their
+	// subject; the first rule matching a subject decides
+	Delta []DeltaRule
+
+	// RateLimit caps publishes per second (0 is unlimited)
+	RateLimit float64
+	// LogLevel drops log lines below the level (default domain.LogLevelDebug)
+	LogLevel domain.LogLevel
+	// LogSampleEvery logs only one in every N per-message (debug) lines
+	// (0 logs all)
+	LogSampleEvery int
+	// LogSummaryInterval reports how many per-message lines sampling
+	// suppressed at most this often (0 disables the summary)
+	LogSummaryInterval time.Duration
+	// LogFormatter renders log lines, e.g. as plain text or JSON (default
+	// logs them as written)
+	LogFormatter domain.LogFormatter
+
+	// Clock is used for TTLs, retry backoff and schedules (default domain.SystemClock)
+	Clock domain.Clock
 }
 
 // Logger defines the logging interface
@@ -30,11 +83,28 @@ func (l *defaultLogger) Printf(format string, v ...interface{}) {
 
 // SimplePublisher implements domain.Publisher
 type SimplePublisher struct {
-	client        domain.IngressClient
-	resultHandler domain.ResultHandler
-	logger        Logger
-	preparers     []domain.MessagePreparer
-	mu            sync.RWMutex
+	client         domain.IngressClient
+	resultHandler  domain.ResultHandler
+	logger         Logger
+	preparers      []domain.MessagePreparer
+	idempotency    bool
+	correlationIDs bool
+	acked          *ackCache
+	settings       Settings
+	nextPublish    time.Time
+	settingsMu     sync.RWMutex
+	sampler        *domain.LogSampler
+	formatter      domain.LogFormatter
+	dedupKeyFunc   func(msg *domain.PublishMessage) string
+	onOversize     func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishMessage, error)
+	compression    []CompressionRule
+	delta          []DeltaRule
+	snapshots      map[string]snapshot
+	snapshotsMu    sync.Mutex
+	clock          domain.Clock
+	counters       counters
+	closed         atomic.Bool
+	mu             sync.RWMutex
 }
 
 // New creates a new publisher instance
@@ -47,22 +117,54 @@ func New(config *Config) (*SimplePublisher, error) {
 		return nil, fmt.Errorf("client cannot be nil")
 	}
 
+	if err := ValidateCompression(config.Compression); err != nil {
+		return nil, err
+	}
+	if err := ValidateDelta(config.Delta); err != nil {
+		return nil, err
+	}
+
 	logger := config.Logger
 	if logger == nil {
 		logger = &defaultLogger{}
 	}
 
-	resultHandler := config.ResultHandler
-	if resultHandler == nil {
-		resultHandler = NewLoggingResultHandler(logger, true)
+	settings := Settings{
+		MaxRetries:     config.MaxRetries,
+		RetryBackoff:   config.RetryBackoff,
+		MessageTTL:     config.MessageTTL,
+		MaxPayloadSize: config.MaxPayloadSize,
+		RateLimit:      config.RateLimit,
+		LogLevel:       config.LogLevel,
+	}
+	if settings.RetryBackoff <= 0 {
+		settings.RetryBackoff = 100 * time.Millisecond
 	}
 
-	return &SimplePublisher{
-		client:        config.Client,
-		resultHandler: resultHandler,
-		logger:        logger,
-		preparers:     make([]domain.MessagePreparer, 0),
-	}, nil
+	p := &SimplePublisher{
+		client:         config.Client,
+		resultHandler:  config.ResultHandler,
+		logger:         logger,
+		preparers:      make([]domain.MessagePreparer, 0),
+		idempotency:    config.IdempotencyKeys,
+		correlationIDs: config.CorrelationIDs,
+		acked:          newAckCache(defaultAckCacheSize),
+		settings:       settings,
+		dedupKeyFunc:   config.DedupKeyFunc,
+		onOversize:     config.OnOversize,
+		compression:    append([]CompressionRule(nil), config.Compression...),
+		delta:          append([]DeltaRule(nil), config.Delta...),
+		snapshots:      make(map[string]snapshot),
+		formatter:      config.LogFormatter,
+		clock:          domain.ClockOrSystem(config.Clock),
+	}
+	if config.LogSampleEvery > 1 {
+		p.sampler = domain.NewLogSampler(config.LogSampleEvery, config.LogSummaryInterval, p.clock)
+	}
+	if p.resultHandler == nil {
+		p.resultHandler = NewLoggingResultHandler(&levelLogger{p: p, level: domain.LogLevelDebug}, true)
+	}
+	return p, nil
 }
 
 // RegisterHandler registers a message preparer
@@ -70,7 +172,7 @@ func (p *SimplePublisher) RegisterHandler(preparer domain.MessagePreparer) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.preparers = append(p.preparers, preparer)
-	p.logger.Printf("✓ Registered message preparer (total: %d)", len(p.preparers))
+	p.logf(domain.LogLevelInfo, "✓ Registered message preparer (total: %d)", len(p.preparers))
 }
 
 // RegisterHandlers registers multiple message preparers
@@ -80,7 +182,12 @@ func (p *SimplePublisher) RegisterHandlers(preparers []domain.MessagePreparer) {
 	for _, preparer := range preparers {
 		p.preparers = append(p.preparers, preparer)
 	}
-	p.logger.Printf("✓ Registered %d message preparers (total: %d)", len(preparers), len(p.preparers))
+	p.logf(domain.LogLevelInfo, "✓ Registered %d message preparers (total: %d)", len(preparers), len(p.preparers))
+}
+
+// RegisterPreparerFunc registers a function as a message preparer
+func (p *SimplePublisher) RegisterPreparerFunc(fn func(ctx context.Context) (*domain.PublishMessage, error)) {
+	p.RegisterHandler(domain.MessagePreparerFunc(fn))
 }
 
 // SetResultHandler sets a custom result handler
@@ -88,16 +195,25 @@ func (p *SimplePublisher) SetResultHandler(handler domain.ResultHandler) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.resultHandler = handler
-	p.logger.Printf("✓ Custom result handler set")
+	p.logf(domain.LogLevelInfo, "✓ Custom result handler set")
 }
 
 // Publish publishes a single message
 func (p *SimplePublisher) Publish(ctx context.Context, preparer domain.MessagePreparer) error {
-	return p.publishOne(ctx, 1, preparer)
+	_, _, err := p.publishOne(ctx, 1, preparer)
+	return err
 }
 
-// PublishAll publishes all registered message preparers concurrently
+// PublishAll publishes all registered message preparers concurrently. When
+// some fail, the error is a *domain.PublishAllError listing them.
 func (p *SimplePublisher) PublishAll(ctx context.Context, preparers []domain.MessagePreparer) error {
+	_, err := p.PublishAllResults(ctx, preparers)
+	return err
+}
+
+// PublishAllResults is like PublishAll and also returns the outcome of every
+// preparer in preparer order, so callers can act on partial success
+func (p *SimplePublisher) PublishAllResults(ctx context.Context, preparers []domain.MessagePreparer) ([]domain.PublishOutcome, error) {
 	if len(preparers) == 0 {
 		p.mu.RLock()
 		preparers = p.preparers
@@ -105,83 +221,201 @@ func (p *SimplePublisher) PublishAll(ctx context.Context, preparers []domain.Mes
 	}
 
 	if len(preparers) == 0 {
-		return fmt.Errorf("no message preparers to publish")
+		return nil, fmt.Errorf("no message preparers to publish")
 	}
 
-	p.logger.Printf("Publishing %d messages...", len(preparers))
+	p.logf(domain.LogLevelInfo, "Publishing %d messages...", len(preparers))
 
+	outcomes := make([]domain.PublishOutcome, len(preparers))
 	var wg sync.WaitGroup
-	errChan := make(chan error, len(preparers))
-
 	for i, preparer := range preparers {
 		wg.Add(1)
 		go func(idx int, prep domain.MessagePreparer) {
 			defer wg.Done()
-			if err := p.publishOne(ctx, idx+1, prep); err != nil {
-				errChan <- fmt.Errorf("message %d: %w", idx+1, err)
+			msg, result, err := p.publishOne(ctx, idx+1, prep)
+			outcomes[idx] = domain.PublishOutcome{Index: idx, Result: result, Err: err}
+			if msg != nil {
+				outcomes[idx].Subject = msg.Subject
 			}
 		}(i, preparer)
 	}
-
 	wg.Wait()
-	close(errChan)
 
-	// Collect errors
-	var errs []error
-	for err := range errChan {
-		errs = append(errs, err)
+	// Collect failures in preparer order
+	allErr := &domain.PublishAllError{Total: len(preparers)}
+	for _, o := range outcomes {
+		if o.Err != nil {
+			allErr.Failures = append(allErr.Failures, domain.PublishFailure{Index: o.Index, Subject: o.Subject, Err: o.Err})
+		}
 	}
-
-	if len(errs) > 0 {
-		return fmt.Errorf("failed to publish %d messages: %v", len(errs), errs)
+	if len(allErr.Failures) > 0 {
+		return outcomes, allErr
 	}
 
-	p.logger.Printf("✓ All %d messages published successfully", len(preparers))
-	return nil
+	p.logf(domain.LogLevelInfo, "✓ All %d messages published successfully", len(preparers))
+	return outcomes, nil
 }
 
-// publishOne publishes a single message
-func (p *SimplePublisher) publishOne(ctx context.Context, idx int, preparer domain.MessagePreparer) error {
-	p.logger.Printf("[%d] Preparing message...", idx)
+// publishOne publishes a single message and returns it, or nil when it could
+// not be prepared, along with the server's result
+func (p *SimplePublisher) publishOne(ctx context.Context, idx int, preparer domain.MessagePreparer) (*domain.PublishMessage, *domain.PublishResult, error) {
+	p.logf(domain.LogLevelDebug, "[%d] Preparing message...", idx)
 
 	// Prepare message
 	msg, err := preparer.Prepare(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to prepare message: %w", err)
+		return nil, nil, fmt.Errorf("failed to prepare message: %w", err)
 	}
 
 	if msg == nil {
-		return fmt.Errorf("preparer returned nil message")
+		return nil, nil, fmt.Errorf("preparer returned nil message")
+	}
+
+	result, err := p.publishMessage(ctx, idx, msg)
+	return msg, result, err
+}
+
+// publishMessage publishes a prepared message, applying the delta, compression, TTL,
+// idempotency and retry settings and passing the result to the result handler. Skipped
+// duplicates return the cached result.
+func (p *SimplePublisher) publishMessage(ctx context.Context, idx int, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+	settings := p.Settings()
+	pending := p.applyDelta(idx, msg)
+	if err := p.compress(idx, msg); err != nil {
+		p.counters.failed.Add(1)
+		return nil, err
+	}
+	msg, err := p.checkPayloadSize(ctx, idx, msg, settings.MaxPayloadSize)
+	if err != nil {
+		p.counters.failed.Add(1)
+		return nil, err
+	}
+
+	if msg.DedupKey == "" && p.dedupKeyFunc != nil {
+		msg.DedupKey = p.dedupKeyFunc(msg)
+	}
+
+	if settings.MessageTTL > 0 && msg.Headers[domain.HeaderExpiresAt] == "" {
+		msg.SetExpiresAt(p.clock.Now().Add(settings.MessageTTL))
+	}
+
+	correlationID := p.ensureCorrelationID(ctx, msg)
+
+	var key string
+	if p.idempotency {
+		key = ensureIdempotencyKey(msg)
+		if cached, ok := p.acked.get(msg.Subject, key); ok {
+			p.logf(domain.LogLevelDebug, "[%d] Skipping already acknowledged message (idempotency-key=%s)", idx, key)
+			return cached, nil
+		}
 	}
 
 	// Publish message
-	p.logger.Printf("[%d] Publishing to subject '%s'...", idx, msg.Subject)
-	result, err := p.client.Publish(ctx, msg)
+	p.logf(domain.LogLevelDebug, "[%d] Publishing to subject '%s'...%s", idx, msg.Subject, correlationSuffix(correlationID))
+	result, err := p.publishWithRetry(ctx, idx, msg)
 	if err != nil {
-		return fmt.Errorf("publish failed: %w", err)
+		p.counters.failed.Add(1)
+		return nil, fmt.Errorf("publish failed: %w", err)
+	}
+	if result.StatusCode != 0 {
+		p.counters.failed.Add(1)
+	} else {
+		p.counters.published.Add(1)
+	}
+
+	if p.idempotency && result.StatusCode == 0 {
+		p.acked.put(msg.Subject, key, result)
 	}
+	p.recordSnapshot(pending, msg, result)
 
 	// Handle result
-	if p.resultHandler != nil {
-		if err := p.resultHandler.Handle(ctx, result); err != nil {
-			p.logger.Printf("[%d] Result handler error: %v", idx, err)
-		}
+	if err := p.handleResult(ctx, msg, result); err != nil {
+		p.logf(domain.LogLevelError, "[%d] Result handler error: %v%s", idx, err, correlationSuffix(correlationID))
 	}
 
 	if result.StatusCode != 0 {
-		return fmt.Errorf("server error: %s", result.ErrorMessage)
+		return result, fmt.Errorf("server error: %s", result.ErrorMessage)
 	}
 
-	return nil
+	return result, nil
+}
+
+// checkPayloadSize enforces MaxPayloadSize, handing oversized messages to
+// OnOversize when it is set
+func (p *SimplePublisher) checkPayloadSize(ctx context.Context, idx int, msg *domain.PublishMessage, maxPayload int) (*domain.PublishMessage, error) {
+	if maxPayload <= 0 || len(msg.Data) <= maxPayload {
+		return msg, nil
+	}
+
+	if p.onOversize == nil {
+		return nil, fmt.Errorf("%w: subject %s has %d bytes, limit is %d", domain.ErrPayloadTooLarge, msg.Subject, len(msg.Data), maxPayload)
+	}
+
+	p.logf(domain.LogLevelDebug, "[%d] Payload of %d bytes exceeds limit of %d, handing off", idx, len(msg.Data), maxPayload)
+	replacement, err := p.onOversize(ctx, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to handle oversized payload: %w", err)
+	}
+	if replacement == nil || len(replacement.Data) > maxPayload {
+		return nil, fmt.Errorf("%w: oversize handler did not reduce the payload of subject %s", domain.ErrPayloadTooLarge, msg.Subject)
+	}
+	return replacement, nil
+}
+
+// handleResult passes the result to the result handler, along with the message
+// when the handler implements domain.MessageResultHandler
+func (p *SimplePublisher) handleResult(ctx context.Context, msg *domain.PublishMessage, result *domain.PublishResult) error {
+	switch handler := p.resultHandler.(type) {
+	case nil:
+		return nil
+	case domain.MessageResultHandler:
+		return handler.HandleMessage(ctx, msg, result)
+	default:
+		return handler.Handle(ctx, result)
+	}
+}
+
+// publishWithRetry calls the client, retrying failed calls with exponential backoff.
+// The message is reused as is, so an idempotency key stays stable across attempts.
+func (p *SimplePublisher) publishWithRetry(ctx context.Context, idx int, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+	settings := p.Settings()
+	backoff := settings.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		if err := p.waitForRate(ctx); err != nil {
+			return nil, err
+		}
+		result, err := p.client.Publish(ctx, msg)
+		if err == nil {
+			return result, nil
+		}
+		if attempt >= settings.MaxRetries || isPermanent(err) {
+			return nil, err
+		}
+		p.counters.retried.Add(1)
+
+		p.logf(domain.LogLevelInfo, "[%d] Publish attempt %d failed: %v (retrying in %v)%s", idx, attempt+1, err, backoff, correlationSuffix(msg.Headers[domain.HeaderCorrelationID]))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-p.clock.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isPermanent reports whether retrying a publish that failed with err cannot succeed
+func isPermanent(err error) bool {
+	return errors.Is(err, domain.ErrUnauthenticated) || errors.Is(err, domain.ErrNotFound)
 }
 
 // Close closes the publisher and underlying client
 func (p *SimplePublisher) Close() error {
+	p.closed.Store(true)
 	if p.client != nil {
 		if err := p.client.Close(); err != nil {
 			return fmt.Errorf("failed to close client: %w", err)
 		}
-		p.logger.Printf("✓ Publisher closed")
+		p.logf(domain.LogLevelInfo, "✓ Publisher closed")
 	}
 	return nil
 }
@@ -3,7 +3,10 @@ package publisher
 import (
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
 )
@@ -40,6 +43,11 @@ func (l *testLogger) Printf(format string, v ...interface{}) {
 	l.messages = append(l.messages, format)
 }
 
+// discardLogger drops log output; safe for concurrent use
+type discardLogger struct{}
+
+func (discardLogger) Printf(format string, v ...interface{}) {}
+
 func TestNew(t *testing.T) {
 	t.Run("successful creation", func(t *testing.T) {
 		client := &mockIngressClient{}
@@ -463,6 +471,144 @@ func TestSimplePublisher_PublishAll(t *testing.T) {
 			t.Fatal("expected error when some preparers fail")
 		}
 	})
+
+	t.Run("failures are reported per preparer", func(t *testing.T) {
+		errServer := errors.New("connection reset")
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				if msg.Subject == "bad" {
+					return nil, errServer
+				}
+				return &domain.PublishResult{}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+
+		errPrepare := errors.New("prepare error")
+		subjects := []string{"ok", "bad", "", "ok"}
+		preparers := make([]domain.MessagePreparer, len(subjects))
+		for i, subject := range subjects {
+			preparers[i] = domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+				if subject == "" {
+					return nil, errPrepare
+				}
+				return &domain.PublishMessage{Subject: subject}, nil
+			})
+		}
+
+		err := pub.PublishAll(context.Background(), preparers)
+		var allErr *domain.PublishAllError
+		if !errors.As(err, &allErr) {
+			t.Fatalf("expected a PublishAllError, got %v", err)
+		}
+		if allErr.Total != 4 || len(allErr.Failures) != 2 {
+			t.Fatalf("expected 2 of 4 failures, got %+v", allErr)
+		}
+		if f := allErr.Failures[0]; f.Index != 1 || f.Subject != "bad" || !errors.Is(f.Err, errServer) {
+			t.Errorf("unexpected first failure: %+v", f)
+		}
+		if f := allErr.Failures[1]; f.Index != 2 || f.Subject != "" || !errors.Is(f.Err, errPrepare) {
+			t.Errorf("unexpected second failure: %+v", f)
+		}
+		if got := allErr.Indexes(); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+			t.Errorf("expected failed indexes [1 2], got %v", got)
+		}
+		if !errors.Is(err, errServer) || !errors.Is(err, errPrepare) {
+			t.Error("expected errors.Is to match every cause")
+		}
+		if want := "failed to publish 2 of 4 messages: message 2 (bad): "; !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("expected message starting with %q, got %q", want, err.Error())
+		}
+	})
+}
+
+func TestSimplePublisher_PublishAllResults(t *testing.T) {
+	client := &mockIngressClient{
+		publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+			if msg.Subject == "rejected" {
+				return &domain.PublishResult{StatusCode: 1, ErrorMessage: "quota exceeded"}, nil
+			}
+			return &domain.PublishResult{Sequence: uint64(len(msg.Data))}, nil
+		},
+	}
+	pub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+
+	subjects := []string{"a", "rejected", "b"}
+	preparers := make([]domain.MessagePreparer, len(subjects))
+	for i, subject := range subjects {
+		preparers[i] = domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: subject, Data: make([]byte, i+1)}, nil
+		})
+	}
+
+	outcomes, err := pub.PublishAllResults(context.Background(), preparers)
+	var allErr *domain.PublishAllError
+	if !errors.As(err, &allErr) || len(allErr.Failures) != 1 {
+		t.Fatalf("expected one failure, got %v", err)
+	}
+	if len(outcomes) != 3 {
+		t.Fatalf("expected an outcome per preparer, got %d", len(outcomes))
+	}
+
+	for i, o := range outcomes {
+		if o.Index != i || o.Subject != subjects[i] || o.Result == nil {
+			t.Errorf("unexpected outcome %d: %+v", i, o)
+		}
+	}
+	if outcomes[0].Err != nil || outcomes[0].Result.Sequence != 1 || outcomes[2].Result.Sequence != 3 {
+		t.Errorf("expected successful outcomes with their results, got %+v and %+v", outcomes[0], outcomes[2])
+	}
+	if outcomes[1].Err == nil || outcomes[1].Result.ErrorMessage != "quota exceeded" {
+		t.Errorf("expected the rejected outcome to carry the server result, got %+v", outcomes[1])
+	}
+
+	// Only the failed preparers are published again
+	var retry []domain.MessagePreparer
+	for _, o := range outcomes {
+		if o.Err != nil {
+			retry = append(retry, preparers[o.Index])
+		}
+	}
+	if len(retry) != 1 {
+		t.Errorf("expected one preparer to retry, got %d", len(retry))
+	}
+}
+
+func TestSimplePublisher_MessageResultHandler(t *testing.T) {
+	client := &mockIngressClient{
+		publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+			return &domain.PublishResult{Sequence: uint64(len(msg.Data))}, nil
+		},
+	}
+
+	var mu sync.Mutex
+	results := make(map[string]uint64)
+	pub, _ := New(&Config{
+		Client: client,
+		Logger: &testLogger{},
+		ResultHandler: domain.MessageResultHandlerFunc(func(ctx context.Context, msg *domain.PublishMessage, result *domain.PublishResult) error {
+			mu.Lock()
+			defer mu.Unlock()
+			results[msg.Subject] = result.Sequence
+			return nil
+		}),
+	})
+
+	preparers := []domain.MessagePreparer{
+		domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "a", Data: []byte("1")}, nil
+		}),
+		domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "b", Data: []byte("22")}, nil
+		}),
+	}
+	if err := pub.PublishAll(context.Background(), preparers); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if results["a"] != 1 || results["b"] != 2 {
+		t.Errorf("results not matched to their messages: %v", results)
+	}
 }
 
 func TestSimplePublisher_Close(t *testing.T) {
@@ -508,3 +654,153 @@ func TestSimplePublisher_Close(t *testing.T) {
 		}
 	})
 }
+
+func TestSimplePublisher_MessageTTL(t *testing.T) {
+	t.Run("sets expires-at when absent", func(t *testing.T) {
+		var headers map[string]string
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				headers = msg.Headers
+				return &domain.PublishResult{}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: &testLogger{}, MessageTTL: time.Minute})
+
+		before := time.Now()
+		err := pub.Publish(context.Background(), domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "alerts"}, nil
+		}))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		expiresAt, err := time.Parse(time.RFC3339Nano, headers[domain.HeaderExpiresAt])
+		if err != nil {
+			t.Fatalf("expected valid expires-at header, got %q", headers[domain.HeaderExpiresAt])
+		}
+		if expiresAt.Before(before.Add(time.Minute)) || expiresAt.After(time.Now().Add(time.Minute)) {
+			t.Errorf("unexpected expiry: %v", expiresAt)
+		}
+	})
+
+	t.Run("keeps expires-at set by preparer", func(t *testing.T) {
+		var headers map[string]string
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				headers = msg.Headers
+				return &domain.PublishResult{}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: &testLogger{}, MessageTTL: time.Minute})
+
+		_ = pub.Publish(context.Background(), domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{
+				Subject: "alerts",
+				Headers: map[string]string{domain.HeaderExpiresAt: "2030-01-01T00:00:00Z"},
+			}, nil
+		}))
+
+		if headers[domain.HeaderExpiresAt] != "2030-01-01T00:00:00Z" {
+			t.Errorf("expected preparer expiry to be kept, got %s", headers[domain.HeaderExpiresAt])
+		}
+	})
+}
+
+func TestSimplePublisher_RegisterPreparerFunc(t *testing.T) {
+	t.Run("register function", func(t *testing.T) {
+		publishCount := 0
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				publishCount++
+				return &domain.PublishResult{}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+
+		pub.RegisterPreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "test", Data: []byte("data")}, nil
+		})
+
+		if len(pub.preparers) != 1 {
+			t.Fatalf("expected 1 preparer, got %d", len(pub.preparers))
+		}
+		if err := pub.PublishAll(context.Background(), nil); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if publishCount != 1 {
+			t.Errorf("expected 1 publish, got %d", publishCount)
+		}
+	})
+}
+
+func TestSimplePublisher_MaxPayloadSize(t *testing.T) {
+	preparer := func(size int) domain.MessagePreparer {
+		return domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "blobs", Data: make([]byte, size)}, nil
+		})
+	}
+
+	t.Run("rejects oversized payload", func(t *testing.T) {
+		called := false
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				called = true
+				return &domain.PublishResult{}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: &testLogger{}, MaxPayloadSize: 4})
+
+		if err := pub.Publish(context.Background(), preparer(4)); err != nil {
+			t.Fatalf("expected payload at the limit to pass, got %v", err)
+		}
+		called = false
+
+		err := pub.Publish(context.Background(), preparer(5))
+		if !errors.Is(err, domain.ErrPayloadTooLarge) {
+			t.Fatalf("expected ErrPayloadTooLarge, got %v", err)
+		}
+		if called {
+			t.Error("oversized message should not reach the client")
+		}
+	})
+
+	t.Run("hands oversized payload to handler", func(t *testing.T) {
+		var published *domain.PublishMessage
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				published = msg
+				return &domain.PublishResult{}, nil
+			},
+		}
+		pub, _ := New(&Config{
+			Client:         client,
+			Logger:         &testLogger{},
+			MaxPayloadSize: 4,
+			OnOversize: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishMessage, error) {
+				return &domain.PublishMessage{Subject: msg.Subject, Data: []byte("ref")}, nil
+			},
+		})
+
+		if err := pub.Publish(context.Background(), preparer(10)); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if string(published.Data) != "ref" {
+			t.Errorf("expected replacement message, got %q", published.Data)
+		}
+	})
+
+	t.Run("handler must shrink the payload", func(t *testing.T) {
+		pub, _ := New(&Config{
+			Client:         &mockIngressClient{},
+			Logger:         &testLogger{},
+			MaxPayloadSize: 4,
+			OnOversize: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishMessage, error) {
+				return msg, nil
+			},
+		})
+
+		if err := pub.Publish(context.Background(), preparer(10)); !errors.Is(err, domain.ErrPayloadTooLarge) {
+			t.Errorf("expected ErrPayloadTooLarge, got %v", err)
+		}
+	})
+}
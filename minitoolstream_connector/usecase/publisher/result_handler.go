@@ -7,7 +7,8 @@ import (
 	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
 )
 
-// LoggingResultHandler logs publish results
+// LoggingResultHandler logs publish results. It implements
+// domain.MessageResultHandler, so the subject is logged when the message is known.
 type LoggingResultHandler struct {
 	logger  Logger
 	verbose bool
@@ -26,20 +27,30 @@ func NewLoggingResultHandler(logger Logger, verbose bool) *LoggingResultHandler
 
 // Handle logs the publish result
 func (h *LoggingResultHandler) Handle(ctx context.Context, result *domain.PublishResult) error {
+	return h.HandleMessage(ctx, nil, result)
+}
+
+// HandleMessage logs the publish result along with the message's subject
+func (h *LoggingResultHandler) HandleMessage(ctx context.Context, msg *domain.PublishMessage, result *domain.PublishResult) error {
 	if result == nil {
 		return fmt.Errorf("result cannot be nil")
 	}
 
+	subject := ""
+	if msg != nil {
+		subject = fmt.Sprintf("subject=%s, ", msg.Subject)
+	}
+
 	if result.StatusCode != 0 {
-		h.logger.Printf("✗ Publish failed: error=%s", result.ErrorMessage)
+		h.logger.Printf("✗ Publish failed: %serror=%s", subject, result.ErrorMessage)
 		return nil
 	}
 
 	if h.verbose {
-		h.logger.Printf("✓ Published: sequence=%d, object=%s",
-			result.Sequence, result.ObjectName)
+		h.logger.Printf("✓ Published: %ssequence=%d, object=%s",
+			subject, result.Sequence, result.ObjectName)
 	} else {
-		h.logger.Printf("✓ Published: sequence=%d", result.Sequence)
+		h.logger.Printf("✓ Published: %ssequence=%d", subject, result.Sequence)
 	}
 
 	return nil
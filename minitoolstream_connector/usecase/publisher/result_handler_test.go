@@ -2,6 +2,7 @@ package publisher
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
@@ -156,3 +157,38 @@ func TestLoggingResultHandler_Handle(t *testing.T) {
 		}
 	})
 }
+
+// formattingLogger records formatted log lines
+type formattingLogger struct {
+	lines []string
+}
+
+func (l *formattingLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestLoggingResultHandler_HandleMessage(t *testing.T) {
+	logger := &formattingLogger{}
+	var handler domain.MessageResultHandler = NewLoggingResultHandler(logger, false)
+
+	msg := &domain.PublishMessage{Subject: "orders"}
+	if err := handler.HandleMessage(context.Background(), msg, &domain.PublishResult{Sequence: 7}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := handler.HandleMessage(context.Background(), msg, &domain.PublishResult{StatusCode: 1, ErrorMessage: "denied"}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := []string{
+		"✓ Published: subject=orders, sequence=7",
+		"✗ Publish failed: subject=orders, error=denied",
+	}
+	if len(logger.lines) != len(want) {
+		t.Fatalf("expected %d log lines, got %v", len(want), logger.lines)
+	}
+	for i, line := range want {
+		if logger.lines[i] != line {
+			t.Errorf("expected %q, got %q", line, logger.lines[i])
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package publisher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// PublishEvery publishes the preparer immediately and then once per interval
+// until ctx is cancelled. Failed runs are logged and do not stop the loop.
+func (p *SimplePublisher) PublishEvery(ctx context.Context, preparer domain.MessagePreparer, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %v", interval)
+	}
+
+	next := p.clock.Now()
+	for run := 1; ; run++ {
+		p.publishScheduled(ctx, run, preparer)
+
+		// Keep the cadence independent of how long the publish took, skipping
+		// runs that were missed entirely like a time.Ticker does
+		now := p.clock.Now()
+		for next = next.Add(interval); !next.After(now); next = next.Add(interval) {
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.clock.After(next.Sub(now)):
+		}
+	}
+}
+
+// PublishSchedule publishes the preparer at every time produced by the schedule
+// (see Every and ParseCron) until ctx is cancelled or the schedule ends
+func (p *SimplePublisher) PublishSchedule(ctx context.Context, preparer domain.MessagePreparer, schedule domain.Schedule) error {
+	if schedule == nil {
+		return fmt.Errorf("schedule cannot be nil")
+	}
+
+	for run := 1; ; run++ {
+		now := p.clock.Now()
+		next := schedule.Next(now)
+		if next.IsZero() {
+			p.logf(domain.LogLevelInfo, "Schedule has no further runs")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.clock.After(next.Sub(now)):
+		}
+
+		p.publishScheduled(ctx, run, preparer)
+	}
+}
+
+// publishScheduled runs one scheduled publish, logging failures
+func (p *SimplePublisher) publishScheduled(ctx context.Context, run int, preparer domain.MessagePreparer) {
+	if _, _, err := p.publishOne(ctx, run, preparer); err != nil && ctx.Err() == nil {
+		p.logf(domain.LogLevelError, "[%d] ✗ Scheduled publish failed: %v", run, err)
+	}
+}
@@ -0,0 +1,105 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// stepSchedule runs a fixed number of times at a short interval
+type stepSchedule struct {
+	remaining int
+}
+
+func (s *stepSchedule) Next(after time.Time) time.Time {
+	if s.remaining == 0 {
+		return time.Time{}
+	}
+	s.remaining--
+	return after.Add(time.Millisecond)
+}
+
+func TestSimplePublisher_PublishEvery(t *testing.T) {
+	t.Run("publishes until cancelled", func(t *testing.T) {
+		var published atomic.Int32
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				published.Add(1)
+				return &domain.PublishResult{Sequence: 1}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "heartbeat"}, nil
+		})
+		err := pub.PublishEvery(ctx, preparer, 5*time.Millisecond)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+		if published.Load() < 2 {
+			t.Errorf("expected several publishes, got %d", published.Load())
+		}
+	})
+
+	t.Run("failures do not stop the loop", func(t *testing.T) {
+		var attempts atomic.Int32
+		pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: &testLogger{}})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+		defer cancel()
+
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			attempts.Add(1)
+			return nil, errors.New("snapshot unavailable")
+		})
+		pub.PublishEvery(ctx, preparer, 5*time.Millisecond)
+		if attempts.Load() < 2 {
+			t.Errorf("expected several attempts, got %d", attempts.Load())
+		}
+	})
+
+	t.Run("invalid interval", func(t *testing.T) {
+		pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: &testLogger{}})
+		if err := pub.PublishEvery(context.Background(), nil, 0); err == nil {
+			t.Error("expected error for zero interval")
+		}
+	})
+}
+
+func TestSimplePublisher_PublishSchedule(t *testing.T) {
+	t.Run("stops when schedule ends", func(t *testing.T) {
+		var published atomic.Int32
+		client := &mockIngressClient{
+			publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+				published.Add(1)
+				return &domain.PublishResult{Sequence: 1}, nil
+			},
+		}
+		pub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "snapshot"}, nil
+		})
+		if err := pub.PublishSchedule(context.Background(), preparer, &stepSchedule{remaining: 3}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if published.Load() != 3 {
+			t.Errorf("expected 3 publishes, got %d", published.Load())
+		}
+	})
+
+	t.Run("nil schedule", func(t *testing.T) {
+		pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: &testLogger{}})
+		if err := pub.PublishSchedule(context.Background(), nil, nil); err == nil {
+			t.Error("expected error for nil schedule")
+		}
+	})
+}
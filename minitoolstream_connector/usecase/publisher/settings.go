@@ -0,0 +1,135 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Settings are the publisher settings that can be changed while it runs
+type Settings struct {
+	MaxRetries     int
+	RetryBackoff   time.Duration
+	MessageTTL     time.Duration
+	MaxPayloadSize int
+	// RateLimit caps publishes per second (0 is unlimited)
+	RateLimit float64
+	LogLevel  domain.LogLevel
+}
+
+// validate checks that the settings can be applied
+func (s Settings) validate() error {
+	var errs []error
+	if s.MaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("max retries cannot be negative, got %d", s.MaxRetries))
+	}
+	if s.RetryBackoff <= 0 {
+		errs = append(errs, fmt.Errorf("retry backoff must be positive, got %v", s.RetryBackoff))
+	}
+	if s.MessageTTL < 0 {
+		errs = append(errs, fmt.Errorf("message TTL cannot be negative, got %v", s.MessageTTL))
+	}
+	if s.MaxPayloadSize < 0 {
+		errs = append(errs, fmt.Errorf("max payload size cannot be negative, got %d", s.MaxPayloadSize))
+	}
+	if s.RateLimit < 0 {
+		errs = append(errs, fmt.Errorf("rate limit cannot be negative, got %v", s.RateLimit))
+	}
+	return errors.Join(errs...)
+}
+
+// Settings returns the settings currently in effect
+func (p *SimplePublisher) Settings() Settings {
+	p.settingsMu.RLock()
+	defer p.settingsMu.RUnlock()
+	return p.settings
+}
+
+// Reconfigure applies new settings to subsequent publishes. Publishes in
+// progress finish with the settings they started with. Start from Settings
+// to change only some of them.
+func (p *SimplePublisher) Reconfigure(settings Settings) error {
+	if err := settings.validate(); err != nil {
+		return fmt.Errorf("invalid settings: %w", err)
+	}
+
+	p.settingsMu.Lock()
+	p.settings = settings
+	p.settingsMu.Unlock()
+
+	p.logf(domain.LogLevelInfo, "✓ Publisher reconfigured (retries=%d, ttl=%v, max_payload=%d, rate=%v, log=%s)",
+		settings.MaxRetries, settings.MessageTTL, settings.MaxPayloadSize, settings.RateLimit, settings.LogLevel)
+	return nil
+}
+
+// waitForRate waits for the next publish slot allowed by RateLimit
+func (p *SimplePublisher) waitForRate(ctx context.Context) error {
+	p.settingsMu.Lock()
+	rate := p.settings.RateLimit
+	if rate <= 0 {
+		p.settingsMu.Unlock()
+		return nil
+	}
+	now := p.clock.Now()
+	at := p.nextPublish
+	if at.Before(now) {
+		at = now
+	}
+	p.nextPublish = at.Add(time.Duration(float64(time.Second) / rate))
+	p.settingsMu.Unlock()
+
+	wait := at.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.clock.After(wait):
+		return nil
+	}
+}
+
+// logf logs at the given level if the configured log level allows it.
+// Per-message (debug) lines are sampled when sampling is configured.
+func (p *SimplePublisher) logf(level domain.LogLevel, format string, v ...interface{}) {
+	p.settingsMu.RLock()
+	configured := p.settings.LogLevel
+	p.settingsMu.RUnlock()
+	if !configured.Enabled(level) {
+		return
+	}
+
+	if level == domain.LogLevelDebug && p.sampler != nil {
+		pass, summary := p.sampler.Sample()
+		if summary != "" && configured.Enabled(domain.LogLevelInfo) {
+			p.print(domain.LogLevelInfo, "%s", summary)
+		}
+		if !pass {
+			return
+		}
+	}
+	p.print(level, format, v...)
+}
+
+// print hands a line to the logger, through the formatter if one is set
+func (p *SimplePublisher) print(level domain.LogLevel, format string, v ...interface{}) {
+	if p.formatter == nil {
+		p.logger.Printf(format, v...)
+		return
+	}
+	p.logger.Printf("%s", p.formatter.Format(level, fmt.Sprintf(format, v...)))
+}
+
+// levelLogger logs through the publisher at a fixed level
+type levelLogger struct {
+	p     *SimplePublisher
+	level domain.LogLevel
+}
+
+func (l *levelLogger) Printf(format string, v ...interface{}) {
+	l.p.logf(l.level, format, v...)
+}
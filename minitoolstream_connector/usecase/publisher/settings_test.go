@@ -0,0 +1,199 @@
+package publisher
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/connectortest"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestSimplePublisher_Reconfigure(t *testing.T) {
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: &testLogger{}})
+		before := pub.Settings()
+
+		for _, settings := range []Settings{
+			{MaxRetries: -1, RetryBackoff: time.Second},
+			{RetryBackoff: 0},
+			{RetryBackoff: time.Second, RateLimit: -1},
+			{RetryBackoff: time.Second, MaxPayloadSize: -1},
+		} {
+			if err := pub.Reconfigure(settings); err == nil {
+				t.Errorf("expected error for %+v", settings)
+			}
+		}
+		if pub.Settings() != before {
+			t.Error("expected settings to be unchanged after a failed Reconfigure")
+		}
+	})
+
+	t.Run("applies payload limit to later publishes", func(t *testing.T) {
+		pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: &testLogger{}})
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "test", Data: []byte("too large")}, nil
+		})
+		if err := pub.Publish(context.Background(), preparer); err != nil {
+			t.Fatalf("expected publish to succeed, got %v", err)
+		}
+
+		settings := pub.Settings()
+		settings.MaxPayloadSize = 4
+		if err := pub.Reconfigure(settings); err != nil {
+			t.Fatalf("Reconfigure failed: %v", err)
+		}
+		if err := pub.Publish(context.Background(), preparer); err == nil {
+			t.Error("expected the new payload limit to reject the message")
+		}
+	})
+
+	t.Run("log level filters logs", func(t *testing.T) {
+		logger := &testLogger{}
+		pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: logger})
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: "test"}, nil
+		})
+
+		settings := pub.Settings()
+		settings.LogLevel = domain.LogLevelError
+		if err := pub.Reconfigure(settings); err != nil {
+			t.Fatalf("Reconfigure failed: %v", err)
+		}
+		logged := len(logger.messages)
+		if err := pub.Publish(context.Background(), preparer); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+		if len(logger.messages) != logged {
+			t.Errorf("expected no logs below error level, got %v", logger.messages[logged:])
+		}
+
+		settings.LogLevel = domain.LogLevelDebug
+		if err := pub.Reconfigure(settings); err != nil {
+			t.Fatalf("Reconfigure failed: %v", err)
+		}
+		if !strings.Contains(logger.messages[len(logger.messages)-1], "reconfigured") {
+			t.Errorf("expected the reconfiguration to be logged, got %v", logger.messages)
+		}
+	})
+}
+
+func TestSimplePublisher_LogSampling(t *testing.T) {
+	preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{Subject: "test"}, nil
+	})
+	countPerMessage := func(logger *formattingLogger) int {
+		n := 0
+		for _, m := range logger.lines {
+			if strings.Contains(m, "Preparing message") {
+				n++
+			}
+		}
+		return n
+	}
+
+	t.Run("samples per-message lines", func(t *testing.T) {
+		logger := &formattingLogger{}
+		clock := connectortest.NewFakeClock(time.Time{})
+		pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: logger, Clock: clock, LogSampleEvery: 5, LogSummaryInterval: time.Minute})
+
+		for i := 0; i < 10; i++ {
+			if err := pub.Publish(context.Background(), preparer); err != nil {
+				t.Fatalf("Publish failed: %v", err)
+			}
+		}
+		if n := countPerMessage(logger); n == 0 || n >= 10 {
+			t.Errorf("expected only a sample of the per-message lines, got %d of 10", n)
+		}
+
+		clock.Advance(time.Minute)
+		pub.Publish(context.Background(), preparer)
+		if !strings.Contains(strings.Join(logger.lines, "\n"), "Log sampling: suppressed") {
+			t.Errorf("expected a sampling summary, got %v", logger.lines)
+		}
+	})
+
+	t.Run("off suppresses everything", func(t *testing.T) {
+		logger := &testLogger{}
+		pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: logger, LogLevel: domain.LogLevelOff})
+		if err := pub.Publish(context.Background(), preparer); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+		if len(logger.messages) != 0 {
+			t.Errorf("expected no logs, got %v", logger.messages)
+		}
+	})
+}
+
+func TestSimplePublisher_LogFormatter(t *testing.T) {
+	logger := &formattingLogger{}
+	pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: logger, LogFormatter: domain.PlainLogFormatter{}})
+	pub.RegisterHandler(domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{Subject: "orders"}, nil
+	}))
+
+	for _, line := range logger.lines {
+		if line != "Registered message preparer (total: 1)" {
+			t.Errorf("expected a plain formatted line, got %q", line)
+		}
+	}
+	if len(logger.lines) != 1 {
+		t.Errorf("expected one line, got %v", logger.lines)
+	}
+}
+
+func TestSimplePublisher_RateLimit(t *testing.T) {
+	var published atomic.Int32
+	client := &mockIngressClient{
+		publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+			published.Add(1)
+			return &domain.PublishResult{Sequence: 1}, nil
+		},
+	}
+	clock := connectortest.NewFakeClock(time.Time{})
+	pub, _ := New(&Config{Client: client, Logger: &testLogger{}, Clock: clock, RateLimit: 10})
+	preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+		return &domain.PublishMessage{Subject: "test"}, nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 2; i++ {
+			if err := pub.Publish(context.Background(), preparer); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	// The first publish goes out at once; the second waits 100ms
+	clock.WaitForWaiters(t, 1, 2*time.Second)
+	if published.Load() != 1 {
+		t.Fatalf("expected 1 publish before the rate limit wait, got %d", published.Load())
+	}
+	clock.Advance(100 * time.Millisecond)
+	if err := <-done; err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if published.Load() != 2 {
+		t.Errorf("expected 2 publishes, got %d", published.Load())
+	}
+
+	// Lifting the limit lets publishes through without waiting
+	settings := pub.Settings()
+	settings.RateLimit = 0
+	if err := pub.Reconfigure(settings); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := pub.Publish(context.Background(), preparer); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	if clock.Waiters() != 0 {
+		t.Errorf("expected no rate limit waits, got %d", clock.Waiters())
+	}
+}
@@ -0,0 +1,40 @@
+package publisher
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Stats is a snapshot of publisher counters
+type Stats struct {
+	// Published is the number of messages acknowledged by the server
+	Published uint64
+	// Failed is the number of messages that could not be published
+	Failed uint64
+	// Retried is the number of extra publish attempts made after client errors
+	Retried uint64
+}
+
+// counters holds the live publisher counters
+type counters struct {
+	published atomic.Uint64
+	failed    atomic.Uint64
+	retried   atomic.Uint64
+}
+
+// Stats returns a snapshot of the publisher counters
+func (p *SimplePublisher) Stats() Stats {
+	return Stats{
+		Published: p.counters.published.Load(),
+		Failed:    p.counters.failed.Load(),
+		Retried:   p.counters.retried.Load(),
+	}
+}
+
+// Ready returns nil until the publisher is closed
+func (p *SimplePublisher) Ready() error {
+	if p.closed.Load() {
+		return fmt.Errorf("publisher closed")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestSimplePublisher_Stats(t *testing.T) {
+	attempts := 0
+	client := &mockIngressClient{
+		publishFunc: func(ctx context.Context, msg *domain.PublishMessage) (*domain.PublishResult, error) {
+			attempts++
+			switch msg.Subject {
+			case "flaky":
+				if attempts == 1 {
+					return nil, errors.New("connection reset")
+				}
+			case "rejected":
+				return &domain.PublishResult{StatusCode: 1, ErrorMessage: "rejected"}, nil
+			}
+			return &domain.PublishResult{Sequence: 1}, nil
+		},
+	}
+	pub, _ := New(&Config{Client: client, Logger: &testLogger{}, MaxRetries: 1, RetryBackoff: time.Millisecond})
+
+	for _, subject := range []string{"flaky", "ok", "rejected"} {
+		preparer := domain.MessagePreparerFunc(func(ctx context.Context) (*domain.PublishMessage, error) {
+			return &domain.PublishMessage{Subject: subject}, nil
+		})
+		_ = pub.Publish(context.Background(), preparer)
+	}
+
+	stats := pub.Stats()
+	if stats.Published != 2 || stats.Failed != 1 || stats.Retried != 1 {
+		t.Errorf("expected 2 published, 1 failed and 1 retried, got %+v", stats)
+	}
+}
+
+func TestSimplePublisher_Ready(t *testing.T) {
+	pub, _ := New(&Config{Client: &mockIngressClient{}, Logger: &testLogger{}})
+	if err := pub.Ready(); err != nil {
+		t.Errorf("expected ready, got %v", err)
+	}
+	pub.Close()
+	if err := pub.Ready(); err == nil {
+		t.Error("expected not ready after Close")
+	}
+}
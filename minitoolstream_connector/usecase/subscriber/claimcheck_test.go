@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/claimcheck"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/compression"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestMultiSubject_PayloadFetcher(t *testing.T) {
+	ctx := context.Background()
+	cc, _ := claimcheck.New(&claimcheck.Config{Store: claimcheck.NewMemoryStore(), Threshold: 1})
+
+	// The stored payload is compressed, as publishers compress before offloading
+	data, _ := compression.Gzip{}.Compress([]byte("hello"))
+	pointer, err := cc.Offload(ctx, &domain.PublishMessage{
+		Subject: "files",
+		Data:    data,
+		Headers: map[string]string{domain.HeaderContentEncoding: "gzip"},
+	})
+	if err != nil {
+		t.Fatalf("Offload failed: %v", err)
+	}
+
+	sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: discardLogger{}, Decompressor: compression.Default, PayloadFetcher: cc})
+	var got string
+	handler := sub.fetchingPayloads(sub.decompressing(domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		got = string(msg.Data)
+		return nil
+	})))
+	if err := handler.Handle(ctx, &domain.ReceivedMessage{Subject: "files", Headers: pointer.Headers}); err != nil || got != "hello" {
+		t.Errorf("expected the fetched payload, got %q (%v)", got, err)
+	}
+}
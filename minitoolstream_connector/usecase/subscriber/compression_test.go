@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/compression"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestMultiSubject_Decompression(t *testing.T) {
+	sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: discardLogger{}, Decompressor: compression.Default})
+
+	var got string
+	handler := sub.decompressing(domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		got = string(msg.Data)
+		return nil
+	}))
+
+	data, _ := compression.Snappy{}.Compress([]byte("hello"))
+	msg := &domain.ReceivedMessage{Data: data, Headers: map[string]string{domain.HeaderContentEncoding: "snappy"}}
+	if err := handler.Handle(context.Background(), msg); err != nil || got != "hello" {
+		t.Errorf("expected the decompressed payload, got %q (%v)", got, err)
+	}
+
+	unknown := &domain.ReceivedMessage{Data: data, Headers: map[string]string{domain.HeaderContentEncoding: "br"}}
+	if err := handler.Handle(context.Background(), unknown); !errors.Is(err, domain.ErrDecode) {
+		t.Errorf("expected ErrDecode for an unknown encoding, got %v", err)
+	}
+
+	plain, _ := New(&Config{Client: &mockEgressClient{}, Logger: discardLogger{}})
+	if err := plain.decompressing(handler).Handle(context.Background(), msg); err != nil {
+		t.Errorf("Handle failed: %v", err)
+	}
+}
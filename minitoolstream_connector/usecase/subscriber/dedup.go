@@ -0,0 +1,78 @@
+package usecase
+
+// sequenceWindow remembers the most recently processed sequences of a subject.
+// It is bounded: once full, the oldest sequence is forgotten.
+type sequenceWindow struct {
+	size int
+	ring []uint64
+	next int
+	seen map[uint64]struct{}
+}
+
+// newSequenceWindow creates a window holding up to size sequences
+func newSequenceWindow(size int) *sequenceWindow {
+	return &sequenceWindow{
+		size: size,
+		ring: make([]uint64, 0, size),
+		seen: make(map[uint64]struct{}, size),
+	}
+}
+
+// contains reports whether the sequence is in the window
+func (w *sequenceWindow) contains(sequence uint64) bool {
+	_, ok := w.seen[sequence]
+	return ok
+}
+
+// add records a sequence, evicting the oldest one when the window is full
+func (w *sequenceWindow) add(sequence uint64) {
+	if w.contains(sequence) {
+		return
+	}
+	if len(w.ring) < w.size {
+		w.ring = append(w.ring, sequence)
+	} else {
+		delete(w.seen, w.ring[w.next])
+		w.ring[w.next] = sequence
+		w.next = (w.next + 1) % w.size
+	}
+	w.seen[sequence] = struct{}{}
+}
+
+// window returns the dedup window of a subject, or nil when dedup is disabled
+func (s *MultiSubject) window(subject string) *sequenceWindow {
+	if s.dedupWindow <= 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.windows[subject]
+	if !ok {
+		w = newSequenceWindow(s.dedupWindow)
+		s.windows[subject] = w
+	}
+	return w
+}
+
+// isDuplicate reports whether the sequence was already processed recently
+func (s *MultiSubject) isDuplicate(subject string, sequence uint64) bool {
+	w := s.window(subject)
+	if w == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return w.contains(sequence)
+}
+
+// markProcessed records the sequence in the subject's dedup window
+func (s *MultiSubject) markProcessed(subject string, sequence uint64) {
+	w := s.window(subject)
+	if w == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.add(sequence)
+}
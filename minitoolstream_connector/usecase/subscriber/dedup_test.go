@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestSequenceWindow(t *testing.T) {
+	t.Run("evicts oldest sequence", func(t *testing.T) {
+		w := newSequenceWindow(2)
+		w.add(1)
+		w.add(2)
+		w.add(3)
+
+		if w.contains(1) {
+			t.Error("expected sequence 1 to be evicted")
+		}
+		if !w.contains(2) || !w.contains(3) {
+			t.Error("expected sequences 2 and 3 to be kept")
+		}
+	})
+
+	t.Run("adding twice keeps one slot", func(t *testing.T) {
+		w := newSequenceWindow(2)
+		w.add(1)
+		w.add(1)
+		w.add(2)
+
+		if !w.contains(1) || !w.contains(2) {
+			t.Error("expected both sequences to be kept")
+		}
+	})
+}
+
+func TestMultiSubject_DedupWindow(t *testing.T) {
+	t.Run("overlapping batches are handled once", func(t *testing.T) {
+		client := &mockEgressClient{
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return &mockMessageStream{
+					messages: []*domain.ReceivedMessage{
+						{Subject: "orders", Sequence: 1},
+						{Subject: "orders", Sequence: 2},
+					},
+				}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: &testLogger{}, DedupWindow: 10})
+
+		handled := 0
+		handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			handled++
+			return nil
+		})
+
+		notification := &domain.Notification{Subject: "orders"}
+		_ = sub.processNotification("orders", notification, handler)
+		_ = sub.processNotification("orders", notification, handler)
+
+		if handled != 2 {
+			t.Errorf("expected 2 handled messages, got %d", handled)
+		}
+		if stats := sub.Stats(); stats.Duplicates != 2 {
+			t.Errorf("expected 2 duplicates, got %d", stats.Duplicates)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: &testLogger{}})
+		sub.markProcessed("orders", 1)
+		if sub.isDuplicate("orders", 1) {
+			t.Error("expected dedup to be disabled")
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Drain stops receiving new notifications, processes the notifications already
+// received and then closes the client. If ctx ends first, the remaining work is
+// cancelled as in Stop and ctx's error is returned.
+func (s *MultiSubject) Drain(ctx context.Context) error {
+	s.logf(domain.LogLevelInfo, "Draining subscriber...")
+	s.stopRecv()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.logf(domain.LogLevelError, "Drain interrupted, stopping: %v", ctx.Err())
+		err = fmt.Errorf("drain interrupted: %w", ctx.Err())
+		s.cancel()
+		<-done
+	}
+
+	s.cancel()
+	if closeErr := s.client.Close(); closeErr != nil {
+		s.logf(domain.LogLevelError, "Error closing client: %v", closeErr)
+	}
+	if err == nil {
+		s.logf(domain.LogLevelInfo, "✓ Subscriber drained")
+	}
+	return err
+}
+
+// drainNotifications processes the notifications buffered for a subject
+// without waiting for new ones
+func (s *MultiSubject) drainNotifications(subject string, notifications <-chan *domain.Notification, handler domain.MessageHandler) {
+	for {
+		select {
+		case notification, ok := <-notifications:
+			if !ok {
+				return
+			}
+			s.logf(domain.LogLevelDebug, "[%s] Draining notification: sequence=%d", subject, notification.Sequence)
+			s.handleNotification(subject, notification, handler)
+		default:
+			return
+		}
+	}
+}
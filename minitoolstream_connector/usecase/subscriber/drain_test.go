@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// drainClient announces a fixed number of notifications and serves one message per fetch
+type drainClient struct {
+	mockEgressClient
+	seq    atomic.Uint64
+	closed atomic.Bool
+}
+
+func newDrainClient(notifications int) *drainClient {
+	c := &drainClient{}
+	c.subscribeFunc = func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+		sent := 0
+		return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+			if sent < notifications {
+				sent++
+				return &domain.Notification{Subject: config.Subject, Sequence: uint64(sent)}, nil
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}}, nil
+	}
+	c.fetchFunc = func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+		seq := c.seq.Add(1)
+		return &mockMessageStream{messages: []*domain.ReceivedMessage{{Subject: config.Subject, Sequence: seq}}}, nil
+	}
+	c.closeFunc = func() error {
+		c.closed.Store(true)
+		return nil
+	}
+	return c
+}
+
+func TestMultiSubject_Drain(t *testing.T) {
+	t.Run("processes announced messages before closing", func(t *testing.T) {
+		client := newDrainClient(3)
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+
+		started := make(chan struct{}, 3)
+		release := make(chan struct{})
+		var mu sync.Mutex
+		var handled []uint64
+		sub.RegisterHandlerFunc("test.subject", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			started <- struct{}{}
+			<-release
+			mu.Lock()
+			handled = append(handled, msg.Sequence)
+			mu.Unlock()
+			return nil
+		})
+		if err := sub.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+
+		<-started
+		// Let the receiver buffer the remaining notifications
+		time.Sleep(20 * time.Millisecond)
+
+		drained := make(chan error, 1)
+		go func() { drained <- sub.Drain(context.Background()) }()
+
+		select {
+		case <-drained:
+			t.Fatal("Drain returned while a message was being handled")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(release)
+
+		select {
+		case err := <-drained:
+			if err != nil {
+				t.Fatalf("Drain failed: %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Drain did not return")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(handled) != 3 {
+			t.Errorf("expected 3 handled messages, got %v", handled)
+		}
+		if !client.closed.Load() {
+			t.Error("expected client to be closed")
+		}
+	})
+
+	t.Run("context ends first", func(t *testing.T) {
+		client := newDrainClient(1)
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+
+		started := make(chan struct{})
+		sub.RegisterHandlerFunc("test.subject", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		sub.Start(context.Background())
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := sub.Drain(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+		if !client.closed.Load() {
+			t.Error("expected client to be closed")
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// handlerGroup delivers every message to all handlers registered for a subject.
+// Handlers run in registration order, or concurrently when fanOut is set; in
+// that case they share the message and must not modify it.
+type handlerGroup struct {
+	handlers []domain.MessageHandler
+	fanOut   bool
+}
+
+// Handle runs every handler and joins their errors
+func (g *handlerGroup) Handle(ctx context.Context, msg *domain.ReceivedMessage) error {
+	errs := make([]error, len(g.handlers))
+
+	if !g.fanOut {
+		for i, handler := range g.handlers {
+			errs[i] = handler.Handle(ctx, msg)
+		}
+		return errors.Join(errs...)
+	}
+
+	var wg sync.WaitGroup
+	for i, handler := range g.handlers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = handler.Handle(ctx, msg)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// addHandler adds handler to the subject's existing handler, grouping them
+// when there is more than one. Groups are replaced rather than modified, so a
+// running subscription never sees a group change under it. The caller holds s.mu.
+func (s *MultiSubject) addHandler(subject string, handler domain.MessageHandler) {
+	existing, ok := s.handlers[subject]
+	if !ok {
+		s.handlers[subject] = handler
+		return
+	}
+
+	handlers := []domain.MessageHandler{existing}
+	if group, ok := existing.(*handlerGroup); ok {
+		handlers = append([]domain.MessageHandler(nil), group.handlers...)
+	}
+	s.handlers[subject] = &handlerGroup{handlers: append(handlers, handler), fanOut: s.fanOut}
+}
+
+// subjectHandler returns a handler that dispatches to the handlers currently
+// registered for subject, so handlers added after Start take effect on the
+// running subscription. fallback handles the subject while it has no explicit
+// handler, as for subjects discovered through a pattern.
+func (s *MultiSubject) subjectHandler(subject string, fallback domain.MessageHandler) domain.MessageHandler {
+	return domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		s.mu.RLock()
+		handler, ok := s.handlers[subject]
+		s.mu.RUnlock()
+		if !ok {
+			handler = fallback
+		}
+		return handler.Handle(ctx, msg)
+	})
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestMultiSubject_MultipleHandlers(t *testing.T) {
+	t.Run("every handler receives every message", func(t *testing.T) {
+		client := &mockEgressClient{
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return &mockMessageStream{
+					messages: []*domain.ReceivedMessage{
+						{Subject: "orders", Sequence: 1},
+						{Subject: "orders", Sequence: 2},
+					},
+				}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+
+		var order []string
+		sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			order = append(order, "log")
+			return nil
+		})
+		sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			order = append(order, "store")
+			return nil
+		})
+
+		if len(sub.handlers) != 1 {
+			t.Fatalf("expected handlers grouped under one subject, got %d", len(sub.handlers))
+		}
+
+		sub.processNotification("orders", &domain.Notification{Subject: "orders"}, sub.handlers["orders"])
+
+		want := []string{"log", "store", "log", "store"}
+		if len(order) != len(want) {
+			t.Fatalf("expected %v, got %v", want, order)
+		}
+		for i := range want {
+			if order[i] != want[i] {
+				t.Errorf("expected %v, got %v", want, order)
+				break
+			}
+		}
+	})
+
+	t.Run("errors are joined and later handlers still run", func(t *testing.T) {
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: &testLogger{}})
+
+		errFirst := errors.New("first failed")
+		secondCalled := false
+		sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			return errFirst
+		})
+		sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			secondCalled = true
+			return nil
+		})
+
+		err := sub.handlers["orders"].Handle(context.Background(), &domain.ReceivedMessage{})
+		if !errors.Is(err, errFirst) {
+			t.Errorf("expected joined error to contain first error, got %v", err)
+		}
+		if !secondCalled {
+			t.Error("expected second handler to run")
+		}
+	})
+
+	t.Run("fan out runs handlers concurrently", func(t *testing.T) {
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: &testLogger{}, FanOutHandlers: true})
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		for i := 0; i < 2; i++ {
+			sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+				// Each handler waits for the other, which only completes when both run at once
+				wg.Done()
+				wg.Wait()
+				return nil
+			})
+		}
+
+		done := make(chan error, 1)
+		go func() {
+			done <- sub.handlers["orders"].Handle(context.Background(), &domain.ReceivedMessage{})
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected handlers to run concurrently")
+		}
+	})
+}
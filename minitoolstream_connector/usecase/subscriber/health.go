@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// streamState is whether a subject's notification stream is open and since when
+type streamState struct {
+	open  bool
+	since time.Time
+}
+
+// streamStates tracks the notification stream of every consumed subject
+type streamStates struct {
+	mu     sync.Mutex
+	states map[string]*streamState
+}
+
+// add starts tracking a subject whose stream is not open yet
+func (t *streamStates) add(subject string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.states == nil {
+		t.states = make(map[string]*streamState)
+	}
+	if _, ok := t.states[subject]; !ok {
+		t.states[subject] = &streamState{since: now}
+	}
+}
+
+// opened records that the stream of subject is open
+func (t *streamStates) opened(subject string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.states[subject] = &streamState{open: true, since: now}
+}
+
+// closed records that the stream of subject ended. A failed resubscribe keeps
+// the time the stream went down, so a subject that never comes back goes stale.
+func (t *streamStates) closed(subject string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if state, ok := t.states[subject]; !ok || state.open {
+		t.states[subject] = &streamState{since: now}
+	}
+}
+
+// subjects returns the sorted subjects matching fn
+func (t *streamStates) subjects(fn func(state *streamState) bool) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var subjects []string
+	for subject, state := range t.states {
+		if fn(state) {
+			subjects = append(subjects, subject)
+		}
+	}
+	sort.Strings(subjects)
+	return subjects
+}
+
+// Subscribed returns nil while the subscriber is started and the notification
+// stream of every consumed subject is open, e.g. for a readiness probe
+func (s *MultiSubject) Subscribed() error {
+	if err := s.Ready(); err != nil {
+		return err
+	}
+	pending := s.streams.subjects(func(state *streamState) bool { return !state.open })
+	if len(pending) > 0 {
+		return fmt.Errorf("subjects not subscribed: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Alive returns an error when the notification stream of a subject has been
+// down for longer than window, e.g. for a liveness probe. Streams that are
+// resubscribing within window, and a subscriber that is not started or is
+// shutting down, count as alive.
+func (s *MultiSubject) Alive(window time.Duration) error {
+	if s.recvCtx.Err() != nil {
+		return nil
+	}
+	now := s.clock.Now()
+	stale := s.streams.subjects(func(state *streamState) bool {
+		return !state.open && now.Sub(state.since) > window
+	})
+	if len(stale) > 0 {
+		return fmt.Errorf("subjects without a stream for over %v: %s", window, strings.Join(stale, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestMultiSubject_Health(t *testing.T) {
+	// orders keeps its stream open until the subscriber stops; payments'
+	// stream breaks right away and is not resubscribed
+	client := &mockEgressClient{
+		subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+			if config.Subject == "payments" {
+				return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+					return nil, errors.New("connection reset")
+				}}, nil
+			}
+			return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}}, nil
+		},
+	}
+	sub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+	noop := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil })
+	sub.RegisterHandler("orders", noop)
+	sub.RegisterHandler("payments", noop)
+
+	if err := sub.Subscribed(); err == nil || !strings.Contains(err.Error(), "not started") {
+		t.Errorf("expected not started before Start, got %v", err)
+	}
+	if err := sub.Alive(time.Minute); err != nil {
+		t.Errorf("expected alive before Start, got %v", err)
+	}
+
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer sub.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		err := sub.Subscribed()
+		if err != nil && strings.Contains(err.Error(), "subjects not subscribed: payments") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected payments to be reported, got %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := sub.Alive(time.Minute); err != nil {
+		t.Errorf("expected alive within the window, got %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := sub.Alive(time.Millisecond); err == nil || !strings.Contains(err.Error(), "payments") || strings.Contains(err.Error(), "orders") {
+		t.Errorf("expected only payments to be stale, got %v", err)
+	}
+
+	sub.Stop()
+	if err := sub.Alive(time.Millisecond); err != nil {
+		t.Errorf("expected a stopped subscriber to count as alive, got %v", err)
+	}
+}
+
+func TestMultiSubject_Subscribed(t *testing.T) {
+	sub, _ := New(&Config{Client: &mockEgressClient{
+		subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+			return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}}, nil
+		},
+	}, Logger: discardLogger{}})
+	sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil })
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer sub.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for sub.Subscribed() != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected every subject to be subscribed, got %v", sub.Subscribed())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
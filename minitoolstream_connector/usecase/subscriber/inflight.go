@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"sync"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// inFlightLimiter caps the number of messages fetched but not yet handled
+// across all subjects. A fetch reserves room for its batch up front and each
+// handled message gives its share back. A limit of 0 only counts messages.
+type inFlightLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	used    int
+	changed chan struct{}
+}
+
+// newInFlightLimiter creates a limiter allowing limit messages in flight
+func newInFlightLimiter(limit int) *inFlightLimiter {
+	return &inFlightLimiter{limit: limit, changed: make(chan struct{})}
+}
+
+// tryReserve reserves up to want messages and returns how many were reserved.
+// When none are free it returns 0 and a channel closed on the next release.
+func (l *inFlightLimiter) tryReserve(want int) (int, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := want
+	if l.limit > 0 {
+		n = min(want, l.limit-l.used)
+	}
+	if n <= 0 {
+		return 0, l.changed
+	}
+	l.used += n
+	return n, nil
+}
+
+// release gives back n reserved messages and wakes waiting fetches
+func (l *inFlightLimiter) release(n int) {
+	if n <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used -= n
+	l.notify()
+}
+
+// setLimit changes the limit and wakes waiting fetches
+func (l *inFlightLimiter) setLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	l.notify()
+}
+
+// notify wakes waiting fetches. The caller holds l.mu.
+func (l *inFlightLimiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// inFlight returns the number of reserved messages
+func (l *inFlightLimiter) inFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.used
+}
+
+// reserveInFlight waits until messages may be fetched for the subject and
+// returns the batch size to fetch with. Fetching pauses while the MaxInFlight
+// limit is reached.
+func (s *MultiSubject) reserveInFlight(subject string) (int32, error) {
+	for paused := false; ; paused = true {
+		n, changed := s.inFlight.tryReserve(int(s.Settings().BatchSize))
+		if n > 0 {
+			return int32(n), nil
+		}
+		if !paused {
+			s.logf(domain.LogLevelInfo, "[%s] Max in-flight messages reached, pausing fetch", subject)
+		}
+		select {
+		case <-s.ctx.Done():
+			return 0, s.ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// releaseInFlight gives back the share of one handled message and returns
+// the number still reserved
+func (s *MultiSubject) releaseInFlight(reserved int32) int32 {
+	if reserved <= 0 {
+		return 0
+	}
+	s.inFlight.release(1)
+	return reserved - 1
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestInFlightLimiter(t *testing.T) {
+	l := newInFlightLimiter(5)
+
+	if n, _ := l.tryReserve(3); n != 3 {
+		t.Fatalf("expected 3 reserved, got %d", n)
+	}
+	if n, _ := l.tryReserve(3); n != 2 {
+		t.Fatalf("expected the remaining 2 reserved, got %d", n)
+	}
+
+	n, changed := l.tryReserve(1)
+	if n != 0 || changed == nil {
+		t.Fatalf("expected nothing reserved and a wait channel, got %d", n)
+	}
+
+	l.release(1)
+	select {
+	case <-changed:
+	default:
+		t.Fatal("expected release to wake waiters")
+	}
+	if l.inFlight() != 4 {
+		t.Errorf("expected 4 in flight, got %d", l.inFlight())
+	}
+}
+
+func TestMultiSubject_MaxInFlight(t *testing.T) {
+	var mu sync.Mutex
+	var batches []int32
+	client := &mockEgressClient{
+		fetchFunc: func(ctx context.Context, c *domain.SubscriptionConfig) (domain.MessageStream, error) {
+			mu.Lock()
+			batches = append(batches, c.BatchSize)
+			mu.Unlock()
+
+			messages := make([]*domain.ReceivedMessage, c.BatchSize)
+			for i := range messages {
+				messages[i] = &domain.ReceivedMessage{Subject: c.Subject, Sequence: uint64(i + 1)}
+			}
+			return &mockMessageStream{messages: messages}, nil
+		},
+	}
+	fetches := func() []int32 {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int32(nil), batches...)
+	}
+
+	sub, _ := New(&Config{Client: client, BatchSize: 10, MaxInFlight: 3, Logger: discardLogger{}})
+	defer sub.cancel()
+
+	gate := make(chan struct{})
+	handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		<-gate
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	process := func(subject string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sub.processNotification(subject, &domain.Notification{Subject: subject}, handler); err != nil {
+				t.Errorf("processNotification(%s) failed: %v", subject, err)
+			}
+		}()
+	}
+
+	process("a")
+	waitFor(t, func() bool { return len(fetches()) == 1 })
+	if got := fetches(); got[0] != 3 {
+		t.Fatalf("expected the batch to be capped at 3, got %d", got[0])
+	}
+
+	// The limit is reached, so the second subject must not fetch
+	process("b")
+	time.Sleep(20 * time.Millisecond)
+	if got := fetches(); len(got) != 1 {
+		t.Fatalf("expected fetching to pause, got fetches %v", got)
+	}
+	if stats := sub.Stats(); stats.InFlight != 3 {
+		t.Errorf("expected 3 messages in flight, got %d", stats.InFlight)
+	}
+
+	// Completing one handler frees room for one message
+	gate <- struct{}{}
+	waitFor(t, func() bool { return len(fetches()) == 2 })
+	if got := fetches(); got[1] != 1 {
+		t.Errorf("expected a batch of 1 after one message completed, got %d", got[1])
+	}
+
+	close(gate)
+	wg.Wait()
+	if stats := sub.Stats(); stats.InFlight != 0 {
+		t.Errorf("expected nothing in flight, got %d", stats.InFlight)
+	}
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// MessageIterator pulls messages of one subject on demand. The notification
+// stream runs on a context of its own: when the context of a waiting Next is
+// done the stream is cancelled, and the next call subscribes again. A message
+// counts as processed once Next is called again.
+type MessageIterator struct {
+	s       *MultiSubject
+	subject string
+	config  *domain.SubscriptionConfig
+	// ctx bounds the subscription
+	ctx           context.Context
+	notifications domain.NotificationStream
+	batch         domain.MessageStream
+	// received counts the messages of the current batch
+	received int
+	last     *domain.ReceivedMessage
+
+	mu sync.Mutex
+	// cancel ends the current notification stream
+	cancel context.CancelFunc
+	closed bool
+}
+
+// Messages subscribes to a subject and returns a pull-based iterator over its
+// messages. The subscription lives until ctx is cancelled or the iterator is
+// closed; registered handlers are not involved.
+func (s *MultiSubject) Messages(ctx context.Context, subject string) (domain.MessageIterator, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("subject cannot be empty")
+	}
+
+	it := &MessageIterator{
+		s:       s,
+		subject: subject,
+		config:  s.subscriptionConfig(subject),
+		ctx:     ctx,
+	}
+	if err := it.subscribe(); err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+// Next returns the next message, fetching a new batch when the current one is
+// drained and waiting for a notification when nothing is pending.
+// It returns io.EOF when the subscription stream ends or the iterator is
+// closed, and ctx.Err() when ctx is done first.
+func (it *MessageIterator) Next(ctx context.Context) (*domain.ReceivedMessage, error) {
+	it.commit()
+
+	for {
+		if it.isClosed() {
+			return nil, io.EOF
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if it.batch == nil {
+			if err := it.fetch(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		msg, err := it.batch.Recv()
+		if err == io.EOF {
+			it.batch = nil
+			if it.received == 0 {
+				if err := it.wait(ctx); err != nil {
+					if it.isClosed() {
+						return nil, io.EOF
+					}
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err != nil {
+			it.batch = nil
+			return nil, fmt.Errorf("fetch error: %w", err)
+		}
+
+		it.received++
+		if it.s.skipMessage(it.subject, msg) {
+			continue
+		}
+
+		it.last = msg
+		return msg, nil
+	}
+}
+
+// fetch starts a new batch
+func (it *MessageIterator) fetch(ctx context.Context) error {
+	batch, err := it.s.client.Fetch(ctx, &domain.SubscriptionConfig{
+		Subject:     it.subject,
+		DurableName: it.config.DurableName,
+		BatchSize:   it.config.BatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch: %w", err)
+	}
+
+	it.batch = batch
+	it.received = 0
+	return nil
+}
+
+// Close ends the subscription. A Next waiting for a notification returns
+// io.EOF, as do later calls.
+func (it *MessageIterator) Close() error {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	it.closed = true
+	if it.cancel != nil {
+		it.cancel()
+	}
+	return nil
+}
+
+// subscribe opens a notification stream on a context that interrupt cancels
+func (it *MessageIterator) subscribe() error {
+	streamCtx, cancel := context.WithCancel(it.ctx)
+	notifications, err := it.s.client.Subscribe(streamCtx, it.config)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to subscribe to %s: %w", it.subject, err)
+	}
+
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if it.closed {
+		cancel()
+		return io.EOF
+	}
+	it.notifications = notifications
+	it.cancel = cancel
+	return nil
+}
+
+// wait blocks until the server announces new messages or ctx is done. When
+// ctx ends first the stream is cancelled to unblock the receive and dropped;
+// the next wait subscribes again.
+func (it *MessageIterator) wait(ctx context.Context) error {
+	if it.notifications == nil {
+		if err := it.subscribe(); err != nil {
+			return err
+		}
+	}
+
+	stop := context.AfterFunc(ctx, it.interrupt)
+	_, err := it.notifications.Recv()
+	if !stop() {
+		it.notifications = nil
+		return ctx.Err()
+	}
+	return err
+}
+
+// interrupt cancels the current notification stream
+func (it *MessageIterator) interrupt() {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if it.cancel != nil {
+		it.cancel()
+	}
+}
+
+// isClosed reports whether Close was called
+func (it *MessageIterator) isClosed() bool {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.closed
+}
+
+// commit marks the previously returned message as processed
+func (it *MessageIterator) commit() {
+	if it.last == nil {
+		return
+	}
+	it.s.markProcessed(it.subject, it.last.Sequence)
+	it.s.saveCheckpoint(it.subject, it.last.Sequence)
+	it.last = nil
+}
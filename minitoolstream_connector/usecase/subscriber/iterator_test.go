@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestMultiSubject_Messages(t *testing.T) {
+	t.Run("empty subject", func(t *testing.T) {
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: &testLogger{}})
+		if _, err := sub.Messages(context.Background(), ""); err == nil {
+			t.Fatal("expected error for empty subject")
+		}
+	})
+
+	t.Run("subscribe error", func(t *testing.T) {
+		client := &mockEgressClient{
+			subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+				return nil, errors.New("unavailable")
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+		if _, err := sub.Messages(context.Background(), "orders"); err == nil {
+			t.Fatal("expected subscribe error")
+		}
+	})
+
+	t.Run("pulls batches and waits for notifications", func(t *testing.T) {
+		batches := [][]*domain.ReceivedMessage{
+			{{Subject: "orders", Sequence: 1}, {Subject: "orders", Sequence: 2}},
+			{},
+			{{Subject: "orders", Sequence: 3}},
+		}
+		fetches := 0
+		client := &mockEgressClient{
+			subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+				return &mockNotificationStream{notifications: []*domain.Notification{{Subject: "orders", Sequence: 3}}}, nil
+			},
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				if fetches >= len(batches) {
+					return &mockMessageStream{}, nil
+				}
+				batch := batches[fetches]
+				fetches++
+				return &mockMessageStream{messages: batch}, nil
+			},
+		}
+		store := &mapCheckpointStore{checkpoints: map[string]uint64{}}
+		sub, _ := New(&Config{Client: client, DurableName: "batch", Checkpoints: store, Logger: &testLogger{}})
+
+		ctx := context.Background()
+		it, err := sub.Messages(ctx, "orders")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		for want := uint64(1); want <= 3; want++ {
+			msg, err := it.Next(ctx)
+			if err != nil {
+				t.Fatalf("expected message %d, got %v", want, err)
+			}
+			if msg.Sequence != want {
+				t.Errorf("expected sequence %d, got %d", want, msg.Sequence)
+			}
+		}
+
+		if seq, _, _ := store.Load(ctx, "batch", "orders"); seq != 2 {
+			t.Errorf("expected checkpoint 2 before the last message is committed, got %d", seq)
+		}
+
+		if _, err := it.Next(ctx); err != io.EOF {
+			t.Errorf("expected io.EOF when the stream ends, got %v", err)
+		}
+		if seq, _, _ := store.Load(ctx, "batch", "orders"); seq != 3 {
+			t.Errorf("expected checkpoint 3, got %d", seq)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: &testLogger{}})
+		it, _ := sub.Messages(context.Background(), "orders")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := it.Next(ctx); err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("context cancelled while waiting", func(t *testing.T) {
+		notify := make(chan *domain.Notification)
+		var notified atomic.Bool
+		var subscribes atomic.Int32
+		streamDone := make(chan struct{}, 2)
+		client := &mockEgressClient{
+			subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+				subscribes.Add(1)
+				return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+					select {
+					case n := <-notify:
+						notified.Store(true)
+						return n, nil
+					case <-ctx.Done():
+						streamDone <- struct{}{}
+						return nil, ctx.Err()
+					}
+				}}, nil
+			},
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				if !notified.Load() {
+					return &mockMessageStream{}, nil
+				}
+				return &mockMessageStream{messages: []*domain.ReceivedMessage{{Subject: "orders", Sequence: 1}}}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+		it, _ := sub.Messages(context.Background(), "orders")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		done := make(chan error, 1)
+		go func() {
+			_, err := it.Next(ctx)
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if err != context.DeadlineExceeded {
+				t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Next did not return when its context expired")
+		}
+		select {
+		case <-streamDone:
+		default:
+			t.Fatal("expected the notification stream to be cancelled")
+		}
+
+		// The next call subscribes again instead of leaving a receive behind
+		go func() { notify <- &domain.Notification{Subject: "orders", Sequence: 1} }()
+		msg, err := it.Next(context.Background())
+		if err != nil || msg.Sequence != 1 {
+			t.Fatalf("expected sequence 1 after the notification, got %v, %v", msg, err)
+		}
+		if n := subscribes.Load(); n != 2 {
+			t.Errorf("expected a second subscribe, got %d", n)
+		}
+	})
+
+	t.Run("close ends a waiting Next", func(t *testing.T) {
+		client := &mockEgressClient{
+			subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+				return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}}, nil
+			},
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return &mockMessageStream{}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+		it, _ := sub.Messages(context.Background(), "orders")
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := it.Next(context.Background())
+			done <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		if err := it.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		select {
+		case err := <-done:
+			if err != io.EOF {
+				t.Errorf("expected io.EOF after Close, got %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Next did not return after Close")
+		}
+		if _, err := it.Next(context.Background()); err != io.EOF {
+			t.Errorf("expected io.EOF from a closed iterator, got %v", err)
+		}
+	})
+}
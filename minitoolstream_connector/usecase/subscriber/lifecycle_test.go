@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// hookRecorder records lifecycle callbacks
+type hookRecorder struct {
+	mu      sync.Mutex
+	events  []string
+	stopErr error
+	stopped chan struct{}
+}
+
+func newHookRecorder() *hookRecorder {
+	return &hookRecorder{stopped: make(chan struct{})}
+}
+
+func (r *hookRecorder) config(client domain.EgressClient) *Config {
+	return &Config{
+		Client: client,
+		Logger: discardLogger{},
+		OnSubjectStarted: func(subject string) {
+			r.record("started " + subject)
+		},
+		OnSubjectStopped: func(subject string, err error) {
+			r.mu.Lock()
+			r.stopErr = err
+			r.mu.Unlock()
+			r.record("stopped " + subject)
+			close(r.stopped)
+		},
+		OnResubscribed: func(subject string, attempt int) {
+			r.record("resubscribed " + subject)
+		},
+	}
+}
+
+func (r *hookRecorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *hookRecorder) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func waitStopped(t *testing.T, r *hookRecorder) {
+	t.Helper()
+	select {
+	case <-r.stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected OnSubjectStopped to be called")
+	}
+}
+
+func TestMultiSubject_LifecycleHooks(t *testing.T) {
+	noop := func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil }
+
+	t.Run("started and stopped on stream error", func(t *testing.T) {
+		client := &mockEgressClient{
+			subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+				return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+					return nil, errors.New("connection reset")
+				}}, nil
+			},
+		}
+		recorder := newHookRecorder()
+		sub, _ := New(recorder.config(client))
+		sub.RegisterHandlerFunc("orders", noop)
+		sub.Start(context.Background())
+		defer sub.Stop()
+
+		waitStopped(t, recorder)
+
+		events := recorder.snapshot()
+		if len(events) != 2 || events[0] != "started orders" || events[1] != "stopped orders" {
+			t.Errorf("unexpected events: %v", events)
+		}
+		if recorder.stopErr == nil {
+			t.Error("expected stream error to be passed to OnSubjectStopped")
+		}
+	})
+
+	t.Run("stopped without error on Stop", func(t *testing.T) {
+		client := &mockEgressClient{
+			subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+				return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}}, nil
+			},
+		}
+		recorder := newHookRecorder()
+		sub, _ := New(recorder.config(client))
+		sub.RegisterHandlerFunc("orders", noop)
+		sub.Start(context.Background())
+		sub.Stop()
+
+		waitStopped(t, recorder)
+		if recorder.stopErr != nil {
+			t.Errorf("expected nil error, got %v", recorder.stopErr)
+		}
+	})
+
+	t.Run("resubscribes after stream ends", func(t *testing.T) {
+		var mu sync.Mutex
+		var configs []*domain.SubscriptionConfig
+		start := uint64(5)
+		client := &mockEgressClient{
+			subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+				mu.Lock()
+				configs = append(configs, config)
+				n := len(configs)
+				mu.Unlock()
+				if n < 3 {
+					return &mockNotificationStream{}, nil
+				}
+				return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}}, nil
+			},
+		}
+		recorder := newHookRecorder()
+		config := recorder.config(client)
+		config.ResubscribeDelay = time.Millisecond
+		config.StartSequence = &start
+		sub, _ := New(config)
+		sub.RegisterHandlerFunc("orders", noop)
+		sub.Start(context.Background())
+
+		deadline := time.Now().Add(2 * time.Second)
+		for len(recorder.snapshot()) < 3 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		sub.Stop()
+		waitStopped(t, recorder)
+
+		events := recorder.snapshot()
+		want := []string{"started orders", "resubscribed orders", "resubscribed orders", "stopped orders"}
+		if len(events) != len(want) {
+			t.Fatalf("expected events %v, got %v", want, events)
+		}
+		for i := range want {
+			if events[i] != want[i] {
+				t.Errorf("expected events %v, got %v", want, events)
+				break
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if configs[0].StartSequence == nil || configs[1].StartSequence != nil {
+			t.Error("expected only the first subscription to use the start sequence")
+		}
+	})
+
+	t.Run("stopped without error during the resubscribe delay", func(t *testing.T) {
+		subscribed := make(chan struct{}, 1)
+		client := &mockEgressClient{
+			subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+				subscribed <- struct{}{}
+				return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+					return nil, errors.New("connection reset")
+				}}, nil
+			},
+		}
+		recorder := newHookRecorder()
+		config := recorder.config(client)
+		config.ResubscribeDelay = time.Hour
+		sub, _ := New(config)
+		sub.RegisterHandlerFunc("orders", noop)
+		sub.Start(context.Background())
+
+		// The stream breaks and the subscriber waits an hour to resubscribe
+		<-subscribed
+		deadline := time.Now().Add(2 * time.Second)
+		for sub.Subscribed() == nil && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		sub.Stop()
+
+		waitStopped(t, recorder)
+		if recorder.stopErr != nil {
+			t.Errorf("expected nil error, got %v", recorder.stopErr)
+		}
+	})
+}
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// notificationQueueSize is the number of notifications buffered per subject
+const notificationQueueSize = 100
+
+// notificationQueue buffers the notifications of one subscription between the
+// stream receiver and the processing loop, applying the overflow policy when
+// the buffer is full
+type notificationQueue struct {
+	ch       chan *domain.Notification
+	policy   domain.OverflowPolicy
+	spill    *spillFile
+	counters *counters
+	// dropped is set when a notification was dropped, so the processing loop
+	// fetches to catch up
+	dropped atomic.Bool
+}
+
+// newNotificationQueue creates a queue applying policy
+func newNotificationQueue(policy domain.OverflowPolicy, counters *counters) *notificationQueue {
+	q := &notificationQueue{
+		ch:       make(chan *domain.Notification, notificationQueueSize),
+		policy:   policy,
+		counters: counters,
+	}
+	if policy == domain.OverflowSpillToDisk {
+		q.spill = &spillFile{}
+	}
+	return q
+}
+
+// push queues a notification. It returns false if ctx ended while blocked.
+func (q *notificationQueue) push(ctx context.Context, notification *domain.Notification) bool {
+	switch q.policy {
+	case domain.OverflowBlock:
+		select {
+		case q.ch <- notification:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+
+	case domain.OverflowDropOldest:
+		for {
+			select {
+			case q.ch <- notification:
+				return true
+			default:
+			}
+			select {
+			case <-q.ch:
+				q.drop()
+			default:
+			}
+		}
+
+	case domain.OverflowSpillToDisk:
+		// Once spilling, keep spilling until the file is drained to stay in order
+		spilled, err := q.spill.pushIf(notification, func() bool {
+			select {
+			case q.ch <- notification:
+				return false
+			default:
+				return true
+			}
+		})
+		if err != nil {
+			q.drop()
+			return true
+		}
+		if spilled {
+			q.counters.spilled.Add(1)
+		}
+		return true
+
+	default:
+		select {
+		case q.ch <- notification:
+		default:
+			q.drop()
+		}
+		return true
+	}
+}
+
+// drop records a dropped notification
+func (q *notificationQueue) drop() {
+	q.counters.dropped.Add(1)
+	q.dropped.Store(true)
+}
+
+// popSpilled returns the next spilled notification, if any
+func (q *notificationQueue) popSpilled() (*domain.Notification, bool) {
+	if q.spill == nil {
+		return nil, false
+	}
+	notification, err := q.spill.pop()
+	if err != nil {
+		// The spilled notifications are lost; a fetch catches up instead
+		q.dropped.Store(true)
+		return nil, false
+	}
+	return notification, notification != nil
+}
+
+// close releases the spill file. Notifications pushed afterwards are dropped.
+func (q *notificationQueue) close() error {
+	if q.spill == nil {
+		return nil
+	}
+	return q.spill.close()
+}
+
+// spillFile is a FIFO of notifications in a temp file, created on the first
+// push and truncated whenever it is drained
+type spillFile struct {
+	mu     sync.Mutex
+	file   *os.File
+	read   int64
+	write  int64
+	closed bool
+}
+
+// pushIf appends the notification unless the file is empty and direct
+// returns false, i.e. the notification was queued in memory instead. It
+// reports whether the notification was spilled.
+func (s *spillFile) pushIf(notification *domain.Notification, direct func() bool) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.read == s.write && !direct() {
+		return false, nil
+	}
+	if s.closed {
+		return false, fmt.Errorf("spill file closed")
+	}
+
+	if s.file == nil {
+		file, err := os.CreateTemp("", "minitoolstream-spill-*")
+		if err != nil {
+			return false, fmt.Errorf("failed to create spill file: %w", err)
+		}
+		s.file = file
+	}
+
+	record := make([]byte, 10+len(notification.Subject))
+	binary.BigEndian.PutUint64(record, notification.Sequence)
+	binary.BigEndian.PutUint16(record[8:], uint16(len(notification.Subject)))
+	copy(record[10:], notification.Subject)
+	if _, err := s.file.WriteAt(record, s.write); err != nil {
+		return false, fmt.Errorf("failed to spill notification: %w", err)
+	}
+	s.write += int64(len(record))
+	return true, nil
+}
+
+// pop removes and returns the oldest spilled notification, or nil if there
+// is none
+func (s *spillFile) pop() (*domain.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil || s.read == s.write {
+		return nil, nil
+	}
+
+	header := make([]byte, 10)
+	if _, err := s.file.ReadAt(header, s.read); err != nil {
+		return nil, s.reset(fmt.Errorf("failed to read spilled notification: %w", err))
+	}
+	subject := make([]byte, binary.BigEndian.Uint16(header[8:]))
+	if _, err := s.file.ReadAt(subject, s.read+10); err != nil && err != io.EOF {
+		return nil, s.reset(fmt.Errorf("failed to read spilled notification: %w", err))
+	}
+	s.read += int64(10 + len(subject))
+
+	if s.read == s.write {
+		if err := s.reset(nil); err != nil {
+			return nil, err
+		}
+	}
+	return &domain.Notification{Subject: string(subject), Sequence: binary.BigEndian.Uint64(header)}, nil
+}
+
+// reset empties the file, returning err or the truncation error. The caller
+// holds s.mu.
+func (s *spillFile) reset(err error) error {
+	s.read, s.write = 0, 0
+	if truncErr := s.file.Truncate(0); err == nil && truncErr != nil {
+		err = fmt.Errorf("failed to truncate spill file: %w", truncErr)
+	}
+	return err
+}
+
+// close removes the file
+func (s *spillFile) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.file == nil {
+		return nil
+	}
+	s.file.Close()
+	return os.Remove(s.file.Name())
+}
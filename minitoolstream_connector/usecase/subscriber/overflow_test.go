@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// fillQueue pushes notifications with sequences 1..n
+func fillQueue(q *notificationQueue, n int) {
+	for seq := 1; seq <= n; seq++ {
+		q.push(context.Background(), &domain.Notification{Subject: "orders", Sequence: uint64(seq)})
+	}
+}
+
+func TestNotificationQueue_Policies(t *testing.T) {
+	t.Run("drop newest keeps the first notifications", func(t *testing.T) {
+		c := &counters{}
+		q := newNotificationQueue(domain.OverflowDropNewest, c)
+		fillQueue(q, notificationQueueSize+5)
+
+		if first := <-q.ch; first.Sequence != 1 {
+			t.Errorf("expected the oldest notification first, got %d", first.Sequence)
+		}
+		if c.dropped.Load() != 5 || !q.dropped.Load() {
+			t.Errorf("expected 5 dropped notifications, got %d", c.dropped.Load())
+		}
+	})
+
+	t.Run("drop oldest keeps the latest notifications", func(t *testing.T) {
+		c := &counters{}
+		q := newNotificationQueue(domain.OverflowDropOldest, c)
+		fillQueue(q, notificationQueueSize+5)
+
+		if first := <-q.ch; first.Sequence != 6 {
+			t.Errorf("expected sequence 6 first, got %d", first.Sequence)
+		}
+		if c.dropped.Load() != 5 || !q.dropped.Load() {
+			t.Errorf("expected 5 dropped notifications, got %d", c.dropped.Load())
+		}
+	})
+
+	t.Run("block waits for room", func(t *testing.T) {
+		c := &counters{}
+		q := newNotificationQueue(domain.OverflowBlock, c)
+		fillQueue(q, notificationQueueSize)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if q.push(ctx, &domain.Notification{Subject: "orders"}) {
+			t.Error("expected push to give up when the context ends")
+		}
+		if c.dropped.Load() != 0 {
+			t.Errorf("expected no drops, got %d", c.dropped.Load())
+		}
+	})
+
+	t.Run("spill to disk keeps every notification in order", func(t *testing.T) {
+		c := &counters{}
+		q := newNotificationQueue(domain.OverflowSpillToDisk, c)
+		fillQueue(q, notificationQueueSize+5)
+
+		if c.spilled.Load() != 5 || c.dropped.Load() != 0 {
+			t.Fatalf("expected 5 spilled and no dropped notifications, got %d and %d", c.spilled.Load(), c.dropped.Load())
+		}
+		path := q.spill.file.Name()
+
+		// Once spilling, new notifications go to the file even if there is room
+		<-q.ch
+		q.push(context.Background(), &domain.Notification{Subject: "orders", Sequence: 200})
+
+		for len(q.ch) > 0 {
+			<-q.ch
+		}
+		var spilled []uint64
+		for {
+			notification, ok := q.popSpilled()
+			if !ok {
+				break
+			}
+			if notification.Subject != "orders" {
+				t.Errorf("unexpected subject %q", notification.Subject)
+			}
+			spilled = append(spilled, notification.Sequence)
+		}
+		want := []uint64{101, 102, 103, 104, 105, 200}
+		if len(spilled) != len(want) {
+			t.Fatalf("expected spilled sequences %v, got %v", want, spilled)
+		}
+		for i := range want {
+			if spilled[i] != want[i] {
+				t.Fatalf("expected spilled sequences %v, got %v", want, spilled)
+			}
+		}
+
+		if err := q.close(); err != nil {
+			t.Fatalf("close failed: %v", err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected the spill file to be removed, got %v", err)
+		}
+	})
+}
+
+func TestMultiSubject_SpillToDisk(t *testing.T) {
+	const total = 300
+	client := newCursorEgressClient("orders", total)
+	notifications := make(chan *domain.Notification, total)
+	for seq := 1; seq <= total; seq++ {
+		notifications <- &domain.Notification{Subject: "orders", Sequence: uint64(seq)}
+	}
+	client.subscribeFunc = func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+		return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+			select {
+			case n := <-notifications:
+				return n, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}}, nil
+	}
+	sub, _ := New(&Config{Client: client, Logger: discardLogger{}, BatchSize: 1, OverflowPolicy: domain.OverflowSpillToDisk})
+
+	release := make(chan struct{})
+	handled := make(chan uint64, total)
+	sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		<-release
+		handled <- msg.Sequence
+		return nil
+	})
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer sub.Stop()
+
+	waitFor(t, func() bool { return len(notifications) == 0 })
+	close(release)
+	for i := 0; i < total; i++ {
+		select {
+		case <-handled:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected %d messages to be handled, got %d", total, i)
+		}
+	}
+
+	stats := sub.Stats()
+	if stats.SpilledNotifications == 0 || stats.DroppedNotifications != 0 || stats.CompensatingFetches != 0 {
+		t.Errorf("expected notifications to be spilled rather than dropped, got %+v", stats)
+	}
+}
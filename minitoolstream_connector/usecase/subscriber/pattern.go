@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// patternHandler routes subjects matching a pattern to a handler
+type patternHandler struct {
+	pattern *regexp.Regexp
+	handler domain.MessageHandler
+}
+
+// RegisterPatternHandler registers a handler for every subject matching pattern.
+// Matching subjects are discovered by listing subjects periodically, which
+// requires a client implementing domain.SubjectLister, such as the JetStream or
+// local broker clients; the gRPC client cannot list subjects. Subjects with an explicit
+// handler are not routed to pattern handlers. After Start, the pattern applies
+// to subjects discovered from then on.
+func (s *MultiSubject) RegisterPatternHandler(pattern *regexp.Regexp, handler domain.MessageHandler) {
+	s.mu.Lock()
+	s.patterns = append(s.patterns, patternHandler{pattern: pattern, handler: handler})
+	s.logf(domain.LogLevelInfo, "✓ Registered handler for subject pattern: %s", pattern)
+
+	var err error
+	if s.started && s.recvCtx.Err() == nil {
+		err = s.startDiscovery()
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		s.logf(domain.LogLevelError, "✗ Pattern %s will not match any subject: %v", pattern, err)
+		s.reportError("", nil, err)
+	}
+}
+
+// startDiscovery starts the discovery loop when pattern handlers are registered
+// and it is not running yet. The caller holds s.mu.
+func (s *MultiSubject) startDiscovery() error {
+	if len(s.patterns) == 0 || s.discovering {
+		return nil
+	}
+
+	lister, ok := s.client.(domain.SubjectLister)
+	if !ok {
+		return fmt.Errorf("pattern handlers require a client that can list subjects")
+	}
+
+	s.discovering = true
+	s.wg.Add(1)
+	go s.discover(lister)
+	return nil
+}
+
+// discover lists subjects until the subscriber stops, subscribing to new
+// subjects that match a pattern
+func (s *MultiSubject) discover(lister domain.SubjectLister) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.discoverEvery)
+	defer ticker.Stop()
+
+	for {
+		s.discoverOnce(s.recvCtx, lister)
+
+		select {
+		case <-s.recvCtx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// discoverOnce lists subjects once and starts subscriptions for new matches
+func (s *MultiSubject) discoverOnce(ctx context.Context, lister domain.SubjectLister) {
+	subjects, err := lister.ListSubjects(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logf(domain.LogLevelError, "Subject discovery failed: %v", err)
+			s.reportError("", nil, fmt.Errorf("subject discovery failed: %w", err))
+		}
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subject := range subjects {
+		if s.active[subject] || s.recvCtx.Err() != nil {
+			continue
+		}
+
+		handler := s.matchPatterns(subject)
+		if handler == nil {
+			continue
+		}
+
+		s.logf(domain.LogLevelInfo, "[%s] Discovered subject matching a pattern", subject)
+		s.startSubject(subject, handler)
+	}
+}
+
+// matchPatterns returns the handler for a subject from the matching pattern
+// handlers, or nil when none match. The caller holds s.mu.
+func (s *MultiSubject) matchPatterns(subject string) domain.MessageHandler {
+	var matched []domain.MessageHandler
+	for _, p := range s.patterns {
+		if p.pattern.MatchString(subject) {
+			matched = append(matched, p.handler)
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return nil
+	case 1:
+		return matched[0]
+	default:
+		return &handlerGroup{handlers: matched, fanOut: s.fanOut}
+	}
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// listingEgressClient adds subject listing to mockEgressClient
+type listingEgressClient struct {
+	mockEgressClient
+	mu       sync.Mutex
+	subjects []string
+	err      error
+}
+
+func (c *listingEgressClient) ListSubjects(ctx context.Context) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.subjects...), c.err
+}
+
+func (c *listingEgressClient) add(subject string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subjects = append(c.subjects, subject)
+}
+
+func TestMultiSubject_RegisterPatternHandler(t *testing.T) {
+	t.Run("requires subject listing", func(t *testing.T) {
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: discardLogger{}})
+		sub.RegisterPatternHandler(regexp.MustCompile(`^tenant\.`), domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			return nil
+		}))
+
+		if err := sub.Start(context.Background()); err == nil {
+			t.Error("expected error for client without subject listing")
+		}
+	})
+
+	t.Run("subscribes to discovered subjects", func(t *testing.T) {
+		client := &listingEgressClient{subjects: []string{"tenant.a", "orders"}}
+
+		var mu sync.Mutex
+		subscribed := make(map[string]int)
+		client.subscribeFunc = func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+			mu.Lock()
+			subscribed[config.Subject]++
+			mu.Unlock()
+			return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}}, nil
+		}
+
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}, DiscoveryInterval: 5 * time.Millisecond})
+		noop := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil })
+		sub.RegisterHandler("orders", noop)
+		sub.RegisterPatternHandler(regexp.MustCompile(`^tenant\.`), noop)
+		sub.RegisterPatternHandler(regexp.MustCompile(`^orders$`), noop)
+
+		if err := sub.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		client.add("tenant.b")
+
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			mu.Lock()
+			done := subscribed["tenant.a"] > 0 && subscribed["tenant.b"] > 0
+			mu.Unlock()
+			if done || time.Now().After(deadline) {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		sub.Stop()
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, subject := range []string{"tenant.a", "tenant.b", "orders"} {
+			if subscribed[subject] != 1 {
+				t.Errorf("expected one subscription to %s, got %d", subject, subscribed[subject])
+			}
+		}
+	})
+
+	t.Run("reports discovery errors", func(t *testing.T) {
+		client := &listingEgressClient{err: errors.New("unavailable")}
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}, DiscoveryInterval: time.Hour})
+
+		reports := make(chan error, 1)
+		sub.SetErrorHandler(func(subject string, msg *domain.ReceivedMessage, err error) {
+			select {
+			case reports <- err:
+			default:
+			}
+		})
+		sub.RegisterPatternHandler(regexp.MustCompile(`.*`), domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			return nil
+		}))
+		if err := sub.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		defer sub.Stop()
+
+		select {
+		case err := <-reports:
+			if err == nil {
+				t.Error("expected discovery error")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected discovery error to be reported")
+		}
+	})
+}
+
+func TestMultiSubject_matchPatterns(t *testing.T) {
+	sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: discardLogger{}})
+	noop := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil })
+	sub.RegisterPatternHandler(regexp.MustCompile(`^tenant\.`), noop)
+	sub.RegisterPatternHandler(regexp.MustCompile(`\.orders$`), noop)
+
+	if h := sub.matchPatterns("billing"); h != nil {
+		t.Error("expected no handler for non-matching subject")
+	}
+	if _, ok := sub.matchPatterns("tenant.a").(domain.MessageHandlerFunc); !ok {
+		t.Error("expected single handler for one matching pattern")
+	}
+	if _, ok := sub.matchPatterns("tenant.orders").(*handlerGroup); !ok {
+		t.Error("expected handler group for several matching patterns")
+	}
+}
+
+func TestMultiSubject_RegisterPatternHandlerAfterStart(t *testing.T) {
+	noop := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil })
+
+	t.Run("starts discovery", func(t *testing.T) {
+		client := &listingEgressClient{subjects: []string{"orders", "tenant.a"}}
+		subscribed := make(chan string, 10)
+		client.subscribeFunc = func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+			subscribed <- config.Subject
+			return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}}, nil
+		}
+
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}, DiscoveryInterval: 5 * time.Millisecond})
+		sub.RegisterHandler("orders", noop)
+		if err := sub.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		defer sub.Stop()
+
+		sub.RegisterPatternHandler(regexp.MustCompile(`^tenant\.`), noop)
+		for {
+			select {
+			case subject := <-subscribed:
+				if subject == "tenant.a" {
+					return
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("expected tenant.a to be discovered after registering the pattern")
+			}
+		}
+	})
+
+	t.Run("reports a client without subject listing", func(t *testing.T) {
+		sub, _ := New(&Config{Client: &mockEgressClient{subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+			return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}}, nil
+		}}, Logger: discardLogger{}})
+		sub.RegisterHandler("orders", noop)
+		if err := sub.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		defer sub.Stop()
+
+		var reported error
+		sub.SetErrorHandler(func(subject string, msg *domain.ReceivedMessage, err error) { reported = err })
+		sub.RegisterPatternHandler(regexp.MustCompile(`^tenant\.`), noop)
+		if reported == nil {
+			t.Error("expected the error handler to be called")
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// priorityLimiter bounds how many handlers run at once. When all slots are
+// taken, waiting deliveries are admitted highest priority first, in arrival
+// order within a priority. A limit of 0 admits everything.
+type priorityLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	used    int
+	seq     uint64
+	waiters waiterQueue
+}
+
+// newPriorityLimiter creates a limiter with the given number of slots
+func newPriorityLimiter(slots int) *priorityLimiter {
+	return &priorityLimiter{limit: slots}
+}
+
+// hasRoom reports whether another slot may be granted. The caller holds l.mu.
+func (l *priorityLimiter) hasRoom() bool {
+	return l.limit <= 0 || l.used < l.limit
+}
+
+// acquire blocks until a slot is granted or ctx is done
+func (l *priorityLimiter) acquire(ctx context.Context, priority int) error {
+	l.mu.Lock()
+	if l.hasRoom() && l.waiters.Len() == 0 {
+		l.used++
+		l.mu.Unlock()
+		return nil
+	}
+
+	l.seq++
+	w := &waiter{priority: priority, seq: l.seq, ready: make(chan struct{})}
+	heap.Push(&l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		if w.index >= 0 {
+			heap.Remove(&l.waiters, w.index)
+			l.mu.Unlock()
+			return ctx.Err()
+		}
+		l.mu.Unlock()
+		// The slot was granted while giving up; hand it on
+		l.release()
+		return ctx.Err()
+	}
+}
+
+// release frees a slot, passing it to the highest priority waiter
+func (l *priorityLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used--
+	l.admit()
+}
+
+// setLimit changes the number of slots. Shrinking takes effect as running
+// handlers finish; growing admits waiters right away.
+func (l *priorityLimiter) setLimit(slots int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = slots
+	l.admit()
+}
+
+// admit grants free slots to waiters, highest priority first. The caller holds l.mu.
+func (l *priorityLimiter) admit() {
+	for l.waiters.Len() > 0 && l.hasRoom() {
+		w := heap.Pop(&l.waiters).(*waiter)
+		l.used++
+		close(w.ready)
+	}
+}
+
+// waiter is a delivery waiting for a slot
+type waiter struct {
+	priority int
+	seq      uint64
+	ready    chan struct{}
+	index    int
+}
+
+// waiterQueue is a heap of waiters ordered by priority, then arrival
+type waiterQueue []*waiter
+
+func (q waiterQueue) Len() int { return len(q) }
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waiterQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	w := old[n-1]
+	old[n-1] = nil
+	w.index = -1
+	*q = old[:n-1]
+	return w
+}
+
+// SetSubjectPriority sets the dispatch priority of a subject; higher values are
+// handled first when MaxConcurrentHandlers is reached (default 0)
+func (s *MultiSubject) SetSubjectPriority(subject string, priority int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priorities[subject] = priority
+}
+
+// acquireSlot waits for a handler slot for the subject; release must be
+// called when the handler is done
+func (s *MultiSubject) acquireSlot(subject string) (release func(), err error) {
+	s.mu.RLock()
+	priority := s.priorities[subject]
+	s.mu.RUnlock()
+
+	if err := s.limiter.acquire(s.ctx, priority); err != nil {
+		return nil, err
+	}
+	return s.limiter.release, nil
+}
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestPriorityLimiter(t *testing.T) {
+	t.Run("admits highest priority first", func(t *testing.T) {
+		l := newPriorityLimiter(1)
+		if err := l.acquire(context.Background(), 0); err != nil {
+			t.Fatalf("acquire failed: %v", err)
+		}
+
+		var mu sync.Mutex
+		var order []int
+		var wg sync.WaitGroup
+		for i, priority := range []int{0, 5, 1} {
+			wg.Add(1)
+			go func(priority int) {
+				defer wg.Done()
+				if err := l.acquire(context.Background(), priority); err != nil {
+					t.Errorf("acquire failed: %v", err)
+					return
+				}
+				mu.Lock()
+				order = append(order, priority)
+				mu.Unlock()
+				l.release()
+			}(priority)
+			waitWaiters(t, l, i+1)
+		}
+
+		l.release()
+		wg.Wait()
+
+		if len(order) != 3 || order[0] != 5 || order[1] != 1 || order[2] != 0 {
+			t.Errorf("unexpected admission order: %v", order)
+		}
+	})
+
+	t.Run("cancelled waiter leaves the queue", func(t *testing.T) {
+		l := newPriorityLimiter(1)
+		l.acquire(context.Background(), 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error, 1)
+		go func() { errCh <- l.acquire(ctx, 0) }()
+		waitWaiters(t, l, 1)
+		cancel()
+
+		if err := <-errCh; err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+		l.release()
+		if err := l.acquire(context.Background(), 0); err != nil {
+			t.Errorf("expected free slot, got %v", err)
+		}
+	})
+}
+
+// waitWaiters waits until the limiter has n queued waiters
+func waitWaiters(t *testing.T, l *priorityLimiter, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		l.mu.Lock()
+		queued := l.waiters.Len()
+		l.mu.Unlock()
+		if queued >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d waiters", n)
+}
+
+func TestMultiSubject_SubjectPriorities(t *testing.T) {
+	sub, _ := New(&Config{
+		Client:                &mockEgressClient{},
+		Logger:                discardLogger{},
+		MaxConcurrentHandlers: 1,
+		SubjectPriorities:     map[string]int{"control": 10},
+	})
+	sub.SetSubjectPriority("bulk", -1)
+
+	hold, err := sub.acquireSlot("bulk")
+	if err != nil {
+		t.Fatalf("acquireSlot failed: %v", err)
+	}
+
+	msg := &domain.ReceivedMessage{Subject: "x", Sequence: 1}
+	var mu sync.Mutex
+	var order []string
+	handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		mu.Lock()
+		order = append(order, msg.Subject)
+		mu.Unlock()
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i, subject := range []string{"bulk", "control"} {
+		wg.Add(1)
+		go func(subject string) {
+			defer wg.Done()
+			m := *msg
+			m.Subject = subject
+			sub.deliver(subject, &m, handler)
+		}(subject)
+		waitWaiters(t, sub.limiter, i+1)
+	}
+
+	hold()
+	wg.Wait()
+
+	if len(order) != 2 || order[0] != "control" || order[1] != "bulk" {
+		t.Errorf("expected control before bulk, got %v", order)
+	}
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import "github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+
+// resumePosition picks the start position for a subject. An explicit start
+// position always wins; otherwise the local checkpoint is checked against the
+// server's last sequence so a restart neither replays nor skips messages.
+func (s *MultiSubject) resumePosition(subject string) domain.StartPosition {
+	if s.checkpoints == nil || !s.startPos.IsDefault() || s.startSequence(subject) != nil {
+		return s.startPos
+	}
+
+	checkpoint, ok, err := s.checkpoints.Load(s.ctx, s.durableName, subject)
+	if err != nil {
+		s.logf(domain.LogLevelError, "[%s] Failed to load checkpoint: %v", subject, err)
+		return s.startPos
+	}
+	if !ok {
+		return s.startPos
+	}
+
+	s.mu.Lock()
+	s.resumed[subject] = checkpoint
+	s.mu.Unlock()
+
+	last, err := s.client.GetLastSequence(s.ctx, subject)
+	if err != nil {
+		s.logf(domain.LogLevelError, "[%s] Failed to get last sequence, resuming from checkpoint: %v", subject, err)
+		return domain.FromSequence(checkpoint + 1)
+	}
+
+	if checkpoint > last {
+		s.logf(domain.LogLevelInfo, "[%s] Checkpoint %d is ahead of server sequence %d, starting from earliest", subject, checkpoint, last)
+		s.mu.Lock()
+		delete(s.resumed, subject)
+		s.mu.Unlock()
+		return domain.Earliest()
+	}
+
+	s.logf(domain.LogLevelInfo, "[%s] Resuming after checkpoint %d (server last sequence %d)", subject, checkpoint, last)
+	return domain.FromSequence(checkpoint + 1)
+}
+
+// startSequence returns the explicit start sequence for a subject, if any
+func (s *MultiSubject) startSequence(subject string) *uint64 {
+	if seq, ok := s.subjectSeqs[subject]; ok {
+		return &seq
+	}
+	if s.startSeq != nil {
+		seq := *s.startSeq
+		return &seq
+	}
+	return nil
+}
+
+// alreadyProcessed reports whether a sequence is covered by the checkpoint the
+// subject was resumed from
+func (s *MultiSubject) alreadyProcessed(subject string, sequence uint64) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	checkpoint, ok := s.resumed[subject]
+	return ok && sequence <= checkpoint
+}
+
+// saveCheckpoint records a processed sequence in the checkpoint store
+func (s *MultiSubject) saveCheckpoint(subject string, sequence uint64) {
+	if s.checkpoints == nil {
+		return
+	}
+	if err := s.checkpoints.Save(s.ctx, s.durableName, subject, sequence); err != nil {
+		s.logf(domain.LogLevelError, "[%s] Failed to save checkpoint %d: %v", subject, sequence, err)
+	}
+}
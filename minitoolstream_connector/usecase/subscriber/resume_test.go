@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+type mapCheckpointStore struct {
+	checkpoints map[string]uint64
+	loadErr     error
+}
+
+func (m *mapCheckpointStore) Load(ctx context.Context, durableName, subject string) (uint64, bool, error) {
+	if m.loadErr != nil {
+		return 0, false, m.loadErr
+	}
+	seq, ok := m.checkpoints[subject]
+	return seq, ok, nil
+}
+
+func (m *mapCheckpointStore) Save(ctx context.Context, durableName, subject string, sequence uint64) error {
+	m.checkpoints[subject] = sequence
+	return nil
+}
+
+func TestMultiSubject_ResumePosition(t *testing.T) {
+	newSub := func(store domain.CheckpointStore, last uint64, startPos domain.StartPosition) *MultiSubject {
+		client := &mockEgressClient{
+			getLastSequenceFunc: func(ctx context.Context, subject string) (uint64, error) {
+				return last, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: &testLogger{}, Checkpoints: store, StartPosition: startPos})
+		return sub
+	}
+
+	t.Run("no checkpoint store", func(t *testing.T) {
+		sub := newSub(nil, 10, domain.StartPosition{})
+		if pos := sub.resumePosition("orders"); !pos.IsDefault() {
+			t.Errorf("expected default position, got %s", pos)
+		}
+	})
+
+	t.Run("no checkpoint for subject", func(t *testing.T) {
+		sub := newSub(&mapCheckpointStore{checkpoints: map[string]uint64{}}, 10, domain.StartPosition{})
+		if pos := sub.resumePosition("orders"); !pos.IsDefault() {
+			t.Errorf("expected default position, got %s", pos)
+		}
+	})
+
+	t.Run("resume after checkpoint", func(t *testing.T) {
+		sub := newSub(&mapCheckpointStore{checkpoints: map[string]uint64{"orders": 5}}, 10, domain.StartPosition{})
+		if pos := sub.resumePosition("orders"); pos != domain.FromSequence(6) {
+			t.Errorf("expected sequence=6, got %s", pos)
+		}
+		if !sub.alreadyProcessed("orders", 5) || sub.alreadyProcessed("orders", 6) {
+			t.Error("expected sequences up to the checkpoint to be treated as processed")
+		}
+	})
+
+	t.Run("checkpoint ahead of server", func(t *testing.T) {
+		sub := newSub(&mapCheckpointStore{checkpoints: map[string]uint64{"orders": 50}}, 10, domain.StartPosition{})
+		if pos := sub.resumePosition("orders"); pos != domain.Earliest() {
+			t.Errorf("expected earliest, got %s", pos)
+		}
+		if sub.alreadyProcessed("orders", 1) {
+			t.Error("expected no sequences to be skipped after a reset")
+		}
+	})
+
+	t.Run("explicit start position wins", func(t *testing.T) {
+		sub := newSub(&mapCheckpointStore{checkpoints: map[string]uint64{"orders": 5}}, 10, domain.Latest())
+		if pos := sub.resumePosition("orders"); pos != domain.Latest() {
+			t.Errorf("expected latest, got %s", pos)
+		}
+	})
+
+	t.Run("load error falls back", func(t *testing.T) {
+		sub := newSub(&mapCheckpointStore{loadErr: errors.New("disk error")}, 10, domain.StartPosition{})
+		if pos := sub.resumePosition("orders"); !pos.IsDefault() {
+			t.Errorf("expected default position, got %s", pos)
+		}
+	})
+}
+
+func TestMultiSubject_SaveCheckpoint(t *testing.T) {
+	t.Run("processed messages are checkpointed", func(t *testing.T) {
+		store := &mapCheckpointStore{checkpoints: map[string]uint64{}}
+		client := &mockEgressClient{
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return &mockMessageStream{
+					messages: []*domain.ReceivedMessage{
+						{Subject: "orders", Sequence: 1},
+						{Subject: "orders", Sequence: 2},
+					},
+				}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: &testLogger{}, Checkpoints: store})
+		handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			return nil
+		})
+
+		if err := sub.processNotification("orders", &domain.Notification{Subject: "orders"}, handler); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if store.checkpoints["orders"] != 2 {
+			t.Errorf("expected checkpoint 2, got %d", store.checkpoints["orders"])
+		}
+	})
+
+	t.Run("strict failure is not checkpointed", func(t *testing.T) {
+		store := &mapCheckpointStore{checkpoints: map[string]uint64{}}
+		client := &mockEgressClient{
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return &mockMessageStream{
+					messages: []*domain.ReceivedMessage{{Subject: "orders", Sequence: 1}},
+				}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: &testLogger{}, Checkpoints: store, StrictMode: true})
+		handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			return errors.New("handler error")
+		})
+
+		_ = sub.processNotification("orders", &domain.Notification{Subject: "orders"}, handler)
+		if _, ok := store.checkpoints["orders"]; ok {
+			t.Error("expected no checkpoint after strict failure")
+		}
+	})
+}
+
+func TestMultiSubject_StartSequence(t *testing.T) {
+	seq := uint64(100)
+
+	t.Run("no explicit sequence", func(t *testing.T) {
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: &testLogger{}})
+		if got := sub.startSequence("orders"); got != nil {
+			t.Errorf("expected no start sequence, got %d", *got)
+		}
+	})
+
+	t.Run("global and per-subject sequences", func(t *testing.T) {
+		sub, _ := New(&Config{
+			Client:                &mockEgressClient{},
+			Logger:                &testLogger{},
+			StartSequence:         &seq,
+			SubjectStartSequences: map[string]uint64{"payments": 7},
+		})
+
+		if got := sub.startSequence("orders"); got == nil || *got != 100 {
+			t.Error("expected global start sequence 100")
+		}
+		if got := sub.startSequence("payments"); got == nil || *got != 7 {
+			t.Error("expected per-subject start sequence 7")
+		}
+	})
+
+	t.Run("explicit sequence wins over checkpoint", func(t *testing.T) {
+		store := &mapCheckpointStore{checkpoints: map[string]uint64{"orders": 5}}
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: &testLogger{}, Checkpoints: store, StartSequence: &seq})
+		if pos := sub.resumePosition("orders"); !pos.IsDefault() {
+			t.Errorf("expected checkpoint to be ignored, got %s", pos)
+		}
+	})
+
+	t.Run("sequence flows to subscription config", func(t *testing.T) {
+		configs := make(chan *domain.SubscriptionConfig, 1)
+		client := &mockEgressClient{
+			subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+				configs <- config
+				return &mockNotificationStream{}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}, SubjectStartSequences: map[string]uint64{"orders": 42}})
+		sub.RegisterHandler("orders", domain.MessageHandlerFunc(
+			func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil }))
+
+		_ = sub.Start(context.Background())
+		config := <-configs
+		sub.Stop()
+
+		if config.StartSequence == nil || *config.StartSequence != 42 {
+			t.Error("expected start sequence 42 in subscription config")
+		}
+	})
+}
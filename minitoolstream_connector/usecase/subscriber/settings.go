@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+// Settings are the subscriber settings that can be changed while it runs
+type Settings struct {
+	BatchSize int32
+	// MaxConcurrentHandlers bounds how many handlers run at once (0 means unbounded)
+	MaxConcurrentHandlers int
+	// MaxInFlight caps the messages fetched but not yet handled (0 means unbounded)
+	MaxInFlight     int
+	RedeliveryDelay time.Duration
+	LogLevel        domain.LogLevel
+}
+
+// validate checks that the settings can be applied
+func (s Settings) validate() error {
+	var errs []error
+	if s.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("batch size must be positive, got %d", s.BatchSize))
+	}
+	if s.MaxConcurrentHandlers < 0 {
+		errs = append(errs, fmt.Errorf("max concurrent handlers cannot be negative, got %d", s.MaxConcurrentHandlers))
+	}
+	if s.MaxInFlight < 0 {
+		errs = append(errs, fmt.Errorf("max in-flight messages cannot be negative, got %d", s.MaxInFlight))
+	}
+	if s.RedeliveryDelay < 0 {
+		errs = append(errs, fmt.Errorf("redelivery delay cannot be negative, got %v", s.RedeliveryDelay))
+	}
+	return errors.Join(errs...)
+}
+
+// Settings returns the settings currently in effect
+func (s *MultiSubject) Settings() Settings {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	return s.settings
+}
+
+// Reconfigure applies new settings without restarting subscriptions: the
+// batch size applies to the next fetch and lowered limits take effect as
+// running handlers finish. Start from Settings to change only some of them.
+func (s *MultiSubject) Reconfigure(settings Settings) error {
+	if err := settings.validate(); err != nil {
+		return fmt.Errorf("invalid settings: %w", err)
+	}
+
+	s.settingsMu.Lock()
+	s.settings = settings
+	s.settingsMu.Unlock()
+
+	s.limiter.setLimit(settings.MaxConcurrentHandlers)
+	s.inFlight.setLimit(settings.MaxInFlight)
+
+	s.logf(domain.LogLevelInfo, "✓ Subscriber reconfigured (batch=%d, handlers=%d, in_flight=%d, log=%s)",
+		settings.BatchSize, settings.MaxConcurrentHandlers, settings.MaxInFlight, settings.LogLevel)
+	return nil
+}
+
+// logf logs at the given level if the configured log level allows it.
+// Per-message (debug) lines are sampled when sampling is configured.
+func (s *MultiSubject) logf(level domain.LogLevel, format string, v ...interface{}) {
+	configured := s.Settings().LogLevel
+	if !configured.Enabled(level) {
+		return
+	}
+
+	if level == domain.LogLevelDebug && s.sampler != nil {
+		pass, summary := s.sampler.Sample()
+		if summary != "" && configured.Enabled(domain.LogLevelInfo) {
+			s.print(domain.LogLevelInfo, "%s", summary)
+		}
+		if !pass {
+			return
+		}
+	}
+	s.print(level, format, v...)
+}
+
+// print hands a line to the logger, through the formatter if one is set
+func (s *MultiSubject) print(level domain.LogLevel, format string, v ...interface{}) {
+	if s.formatter == nil {
+		s.logger.Printf(format, v...)
+		return
+	}
+	s.logger.Printf("%s", s.formatter.Format(level, fmt.Sprintf(format, v...)))
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestMultiSubject_Reconfigure(t *testing.T) {
+	t.Run("rejects invalid settings", func(t *testing.T) {
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: discardLogger{}})
+		before := sub.Settings()
+
+		for _, settings := range []Settings{
+			{BatchSize: 0},
+			{BatchSize: 10, MaxConcurrentHandlers: -1},
+			{BatchSize: 10, MaxInFlight: -1},
+			{BatchSize: 10, RedeliveryDelay: -time.Second},
+		} {
+			if err := sub.Reconfigure(settings); err == nil {
+				t.Errorf("expected error for %+v", settings)
+			}
+		}
+		if sub.Settings() != before {
+			t.Error("expected settings to be unchanged after a failed Reconfigure")
+		}
+	})
+
+	t.Run("batch size applies to the next fetch", func(t *testing.T) {
+		var batches []int32
+		client := &mockEgressClient{
+			fetchFunc: func(ctx context.Context, c *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				batches = append(batches, c.BatchSize)
+				return &mockMessageStream{}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, BatchSize: 10, Logger: discardLogger{}})
+		defer sub.cancel()
+		handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil })
+
+		if err := sub.processNotification("a", &domain.Notification{Subject: "a"}, handler); err != nil {
+			t.Fatalf("processNotification failed: %v", err)
+		}
+		settings := sub.Settings()
+		settings.BatchSize = 25
+		if err := sub.Reconfigure(settings); err != nil {
+			t.Fatalf("Reconfigure failed: %v", err)
+		}
+		if err := sub.processNotification("a", &domain.Notification{Subject: "a"}, handler); err != nil {
+			t.Fatalf("processNotification failed: %v", err)
+		}
+
+		if len(batches) != 2 || batches[0] != 10 || batches[1] != 25 {
+			t.Errorf("expected batches [10 25], got %v", batches)
+		}
+	})
+
+	t.Run("log level filters logs", func(t *testing.T) {
+		logger := &testLogger{}
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: logger, LogLevel: domain.LogLevelError})
+
+		sub.RegisterHandler("a", domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil }))
+		if len(logger.messages) != 0 {
+			t.Errorf("expected no logs below error level, got %v", logger.messages)
+		}
+	})
+}
+
+func TestPriorityLimiter_SetLimit(t *testing.T) {
+	l := newPriorityLimiter(1)
+	if err := l.acquire(context.Background(), 0); err != nil {
+		t.Fatalf("acquire failed: %v", err)
+	}
+
+	acquired := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			if err := l.acquire(context.Background(), 0); err == nil {
+				acquired <- struct{}{}
+			}
+		}()
+	}
+	waitFor(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.waiters.Len() == 2
+	})
+
+	// Growing the limit admits the waiters without any release
+	l.setLimit(3)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-acquired:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected waiters to be admitted after the limit grew")
+		}
+	}
+
+	// Shrinking takes effect as slots are released
+	l.setLimit(1)
+	l.release()
+	l.release()
+	l.mu.Lock()
+	room := l.hasRoom()
+	l.mu.Unlock()
+	if room {
+		t.Error("expected no room while the remaining slot is held")
+	}
+}
+
+func TestMultiSubject_ReconfigureMaxInFlight(t *testing.T) {
+	var mu sync.Mutex
+	fetched := 0
+	client := &mockEgressClient{
+		fetchFunc: func(ctx context.Context, c *domain.SubscriptionConfig) (domain.MessageStream, error) {
+			mu.Lock()
+			fetched++
+			mu.Unlock()
+			messages := make([]*domain.ReceivedMessage, c.BatchSize)
+			for i := range messages {
+				messages[i] = &domain.ReceivedMessage{Subject: c.Subject, Sequence: uint64(i + 1)}
+			}
+			return &mockMessageStream{messages: messages}, nil
+		},
+	}
+	fetches := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return fetched
+	}
+
+	sub, _ := New(&Config{Client: client, BatchSize: 2, MaxInFlight: 2, Logger: discardLogger{}})
+	defer sub.cancel()
+
+	gate := make(chan struct{})
+	handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		<-gate
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for _, subject := range []string{"a", "b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := sub.processNotification(subject, &domain.Notification{Subject: subject}, handler); err != nil {
+				t.Errorf("processNotification(%s) failed: %v", subject, err)
+			}
+		}()
+	}
+	waitFor(t, func() bool { return fetches() == 1 })
+
+	// Raising the limit lets the paused fetch through while handlers still block
+	settings := sub.Settings()
+	settings.MaxInFlight = 4
+	if err := sub.Reconfigure(settings); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	waitFor(t, func() bool { return fetches() == 2 })
+
+	close(gate)
+	wg.Wait()
+}
@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Stats is a snapshot of subscriber counters
+type Stats struct {
+	// Expired is the number of fetched messages skipped because their expires-at passed
+	Expired uint64
+	// Redelivered is the number of extra delivery attempts made after handler failures
+	Redelivered uint64
+	// Failed is the number of messages handed to the failure sink
+	Failed uint64
+	// Duplicates is the number of fetched messages skipped by the dedup window
+	Duplicates uint64
+	// Backoffs is the number of times a handler paused its subject with domain.Backoff
+	Backoffs uint64
+	// DroppedNotifications is the number of notifications dropped because the
+	// subject's notification queue was full
+	DroppedNotifications uint64
+	// SpilledNotifications is the number of notifications written to disk by
+	// domain.OverflowSpillToDisk
+	SpilledNotifications uint64
+	// StreamGaps is the number of times a notification stream ended while the
+	// subscriber was running, so notifications could be missed until it resubscribed
+	StreamGaps uint64
+	// CompensatingFetches is the number of fetches made to catch up after
+	// dropped or missed notifications
+	CompensatingFetches uint64
+	// InFlight is the number of messages fetched but not yet handled
+	InFlight int
+}
+
+// counters holds the live subscriber counters
+type counters struct {
+	expired     atomic.Uint64
+	redelivered atomic.Uint64
+	failed      atomic.Uint64
+	duplicates  atomic.Uint64
+	backoffs    atomic.Uint64
+	dropped     atomic.Uint64
+	spilled     atomic.Uint64
+	streamGaps  atomic.Uint64
+	compensated atomic.Uint64
+}
+
+// snapshot returns the current counter values
+func (c *counters) snapshot() Stats {
+	return Stats{
+		Expired:     c.expired.Load(),
+		Redelivered: c.redelivered.Load(),
+		Failed:      c.failed.Load(),
+		Duplicates:  c.duplicates.Load(),
+		Backoffs:    c.backoffs.Load(),
+
+		DroppedNotifications: c.dropped.Load(),
+		SpilledNotifications: c.spilled.Load(),
+		StreamGaps:           c.streamGaps.Load(),
+		CompensatingFetches:  c.compensated.Load(),
+	}
+}
+
+// Stats returns a snapshot of the subscriber counters
+func (s *MultiSubject) Stats() Stats {
+	stats := s.counters.snapshot()
+	stats.InFlight = s.inFlight.inFlight()
+	return stats
+}
+
+// Ready returns nil while the subscriber is started and receiving notifications
+func (s *MultiSubject) Ready() error {
+	s.mu.RLock()
+	started := s.started
+	s.mu.RUnlock()
+
+	switch {
+	case !started:
+		return fmt.Errorf("subscriber not started")
+	case s.recvCtx.Err() != nil:
+		return fmt.Errorf("subscriber stopped")
+	default:
+		return nil
+	}
+}
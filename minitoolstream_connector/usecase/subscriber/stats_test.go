@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+)
+
+func TestMultiSubject_Stats(t *testing.T) {
+	t.Run("expired messages are skipped and counted", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute).Format(time.RFC3339Nano)
+		future := time.Now().Add(time.Hour).Format(time.RFC3339Nano)
+		client := &mockEgressClient{
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return &mockMessageStream{
+					messages: []*domain.ReceivedMessage{
+						{Subject: "alerts", Sequence: 1, Headers: map[string]string{domain.HeaderExpiresAt: past}},
+						{Subject: "alerts", Sequence: 2, Headers: map[string]string{domain.HeaderExpiresAt: future}},
+						{Subject: "alerts", Sequence: 3},
+					},
+				}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+
+		var handled []uint64
+		handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			handled = append(handled, msg.Sequence)
+			return nil
+		})
+
+		if err := sub.processNotification("alerts", &domain.Notification{Subject: "alerts"}, handler); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(handled) != 2 || handled[0] != 2 || handled[1] != 3 {
+			t.Errorf("expected sequences 2 and 3 to be handled, got %v", handled)
+		}
+		if stats := sub.Stats(); stats.Expired != 1 {
+			t.Errorf("expected 1 expired message, got %d", stats.Expired)
+		}
+	})
+}
+
+// cursorEgressClient serves messages from a durable cursor like the server:
+// every fetch returns the next batch
+type cursorEgressClient struct {
+	mockEgressClient
+	mu       sync.Mutex
+	messages []*domain.ReceivedMessage
+	cursor   int
+}
+
+func (c *cursorEgressClient) Fetch(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	end := min(c.cursor+int(config.BatchSize), len(c.messages))
+	batch := c.messages[c.cursor:end]
+	c.cursor = end
+	return &mockMessageStream{messages: batch}, nil
+}
+
+func newCursorEgressClient(subject string, count int) *cursorEgressClient {
+	c := &cursorEgressClient{}
+	for seq := 1; seq <= count; seq++ {
+		c.messages = append(c.messages, &domain.ReceivedMessage{Subject: subject, Sequence: uint64(seq)})
+	}
+	return c
+}
+
+func TestMultiSubject_DroppedNotifications(t *testing.T) {
+	const total = 300
+	client := newCursorEgressClient("orders", total)
+	var sent atomic.Int32
+	client.subscribeFunc = func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+		return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+			if seq := sent.Add(1); seq <= total {
+				return &domain.Notification{Subject: "orders", Sequence: uint64(seq)}, nil
+			}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}}, nil
+	}
+	sub, _ := New(&Config{Client: client, Logger: discardLogger{}, BatchSize: 1})
+
+	release := make(chan struct{})
+	var handled atomic.Int32
+	sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		<-release
+		handled.Add(1)
+		return nil
+	})
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer sub.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for sent.Load() <= total && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	for handled.Load() < total && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := handled.Load(); n != total {
+		t.Errorf("expected all %d messages to be handled, got %d", total, n)
+	}
+	stats := sub.Stats()
+	if stats.DroppedNotifications == 0 || stats.CompensatingFetches == 0 {
+		t.Errorf("expected dropped notifications and a compensating fetch, got %+v", stats)
+	}
+}
+
+func TestMultiSubject_StreamGapCompensation(t *testing.T) {
+	client := newCursorEgressClient("orders", 3)
+	var subscribes atomic.Int32
+	client.subscribeFunc = func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+		if subscribes.Add(1) == 1 {
+			// The first stream closes before any notification arrives
+			return &mockNotificationStream{}, nil
+		}
+		return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}}, nil
+	}
+	sub, _ := New(&Config{Client: client, Logger: discardLogger{}, ResubscribeDelay: time.Millisecond})
+
+	var handled atomic.Int32
+	sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		handled.Add(1)
+		return nil
+	})
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer sub.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for handled.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if n := handled.Load(); n != 3 {
+		t.Errorf("expected the messages to be fetched after resubscribing, got %d", n)
+	}
+	if stats := sub.Stats(); stats.StreamGaps != 1 || stats.CompensatingFetches != 1 {
+		t.Errorf("expected 1 stream gap and 1 compensating fetch, got %+v", stats)
+	}
+}
+
+func TestMultiSubject_Ready(t *testing.T) {
+	sub, _ := New(&Config{Client: newChannelEgressClient(), Logger: discardLogger{}})
+	sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil })
+
+	if err := sub.Ready(); err == nil {
+		t.Error("expected not ready before Start")
+	}
+	if err := sub.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := sub.Ready(); err != nil {
+		t.Errorf("expected ready after Start, got %v", err)
+	}
+	sub.Stop()
+	if err := sub.Ready(); err == nil {
+		t.Error("expected not ready after Stop")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
 )
@@ -16,6 +17,101 @@ type Config struct {
 	DurableName string
 	BatchSize   int32
 	Logger      Logger
+
+	// StartPosition controls where new subscriptions start reading
+	StartPosition domain.StartPosition
+	// StartSequence is an explicit start sequence for all subjects; it takes
+	// precedence over StartPosition and checkpoints
+	StartSequence *uint64
+	// SubjectStartSequences overrides the start sequence for individual subjects
+	SubjectStartSequences map[string]uint64
+	// Checkpoints persists processed sequences; when set and no explicit
+	// StartPosition is given, subscriptions resume after the last checkpoint
+	Checkpoints domain.CheckpointStore
+
+	// StrictMode makes a handler error abort the current batch and retry the
+	// notification instead of logging the error and moving on. The failed
+	// message and the rest of its batch are kept and redelivered first.
+	StrictMode bool
+	// RetryInterval is the delay before a failed notification is retried in strict mode
+	RetryInterval time.Duration
+
+	// MaxDeliveries is how many times a message is handed to its handler before
+	// it is considered failed (default 1, no redelivery)
+	MaxDeliveries int
+	// RedeliveryDelay is the pause between delivery attempts
+	RedeliveryDelay time.Duration
+	// FailureSink receives messages that failed all delivery attempts
+	FailureSink domain.FailureSink
+
+	// DedupWindow is the number of recently processed sequences remembered per
+	// subject so overlapping fetches don't invoke handlers twice (0 disables)
+	DedupWindow int
+
+	// FanOutHandlers runs the handlers of a subject with several handlers
+	// concurrently instead of in registration order
+	FanOutHandlers bool
+
+	// MaxConcurrentHandlers bounds how many handlers run at once across all
+	// subjects (0 means unbounded). When the limit is reached, subjects with a
+	// higher priority are dispatched first.
+	MaxConcurrentHandlers int
+	// SubjectPriorities sets the dispatch priority of subjects (default 0)
+	SubjectPriorities map[string]int
+	// MaxInFlight caps the messages fetched but not yet handled across all
+	// subjects (0 means unbounded). When it is reached, fetching pauses until
+	// handlers complete.
+	MaxInFlight int
+
+	// DiscoveryInterval is how often subjects are listed to find matches for
+	// pattern handlers (default 30s)
+	DiscoveryInterval time.Duration
+
+	// ResubscribeDelay enables resubscribing after a subject's stream ends,
+	// waiting this long between attempts (0 disables)
+	ResubscribeDelay time.Duration
+
+	// OnSubjectStarted is called once a subject's first subscription is established
+	OnSubjectStarted func(subject string)
+	// OnSubjectStopped is called when a subject stops being consumed; err is nil
+	// when the subscriber was stopped or the stream closed cleanly
+	OnSubjectStopped func(subject string, err error)
+	// OnResubscribed is called after a subject was subscribed again
+	OnResubscribed func(subject string, attempt int)
+
+	// Decompressor restores payloads published with a content-encoding
+	// header before they reach handlers, e.g. compression.Default. Without
+	// one, handlers receive payloads as published.
+	Decompressor domain.Decompressor
+	// PayloadFetcher fetches payloads published by reference, e.g. by a
+	// claim check, before they are decompressed and reach handlers
+	PayloadFetcher domain.PayloadFetcher
+
+	// OverflowPolicy decides what happens to notifications when a subject's
+	// notification queue is full (default domain.OverflowDropNewest)
+	OverflowPolicy domain.OverflowPolicy
+
+	// LogLevel drops log lines below the level (default domain.LogLevelDebug)
+	LogLevel domain.LogLevel
+	// LogSampleEvery logs only one in every N per-message (debug) lines
+	// (0 logs all)
+	LogSampleEvery int
+	// LogSummaryInterval reports how many per-message lines sampling
+	// suppressed at most this often (0 disables the summary)
+	LogSummaryInterval time.Duration
+	// LogFormatter renders log lines, e.g. as plain text or JSON (default
+	// logs them as written)
+	LogFormatter domain.LogFormatter
+
+	// Clock is used for expiry checks and retry waits (default domain.SystemClock)
+	Clock domain.Clock
+}
+
+// lifecycleHooks groups the subscription lifecycle callbacks
+type lifecycleHooks struct {
+	OnSubjectStarted func(subject string)
+	OnSubjectStopped func(subject string, err error)
+	OnResubscribed   func(subject string, attempt int)
 }
 
 // Logger defines the logging interface
@@ -32,15 +128,51 @@ func (l *defaultLogger) Printf(format string, v ...interface{}) {
 
 // MultiSubject implements domain.Subscriber for multiple subjects
 type MultiSubject struct {
-	client      domain.EgressClient
-	durableName string
-	batchSize   int32
-	logger      Logger
-	handlers    map[string]domain.MessageHandler
-	mu          sync.RWMutex
-	ctx         context.Context
-	cancel      context.CancelFunc
-	wg          sync.WaitGroup
+	client        domain.EgressClient
+	durableName   string
+	logger        Logger
+	handlers      map[string]domain.MessageHandler
+	startPos      domain.StartPosition
+	startSeq      *uint64
+	subjectSeqs   map[string]uint64
+	checkpoints   domain.CheckpointStore
+	resumed       map[string]uint64
+	strict        bool
+	retryEvery    time.Duration
+	held          map[string][]*domain.ReceivedMessage
+	maxDeliver    int
+	failureSink   domain.FailureSink
+	dedupWindow   int
+	windows       map[string]*sequenceWindow
+	onError       func(subject string, msg *domain.ReceivedMessage, err error)
+	fanOut        bool
+	patterns      []patternHandler
+	active        map[string]bool
+	started       bool
+	discovering   bool
+	settings      Settings
+	settingsMu    sync.RWMutex
+	sampler       *domain.LogSampler
+	formatter     domain.LogFormatter
+	overflow      domain.OverflowPolicy
+	decompressor  domain.Decompressor
+	fetcher       domain.PayloadFetcher
+	limiter       *priorityLimiter
+	inFlight      *inFlightLimiter
+	priorities    map[string]int
+	discoverEvery time.Duration
+	resubscribeIn time.Duration
+	hooks         lifecycleHooks
+	counters      counters
+	streams       streamStates
+	clock         domain.Clock
+	mu            sync.RWMutex
+	ctx           context.Context
+	cancel        context.CancelFunc
+	recvCtx       context.Context
+	stopRecv      context.CancelFunc
+	stopOnDone    func() bool
+	wg            sync.WaitGroup
 }
 
 // New creates a new multi-subject subscriber
@@ -53,6 +185,10 @@ func New(config *Config) (*MultiSubject, error) {
 		return nil, fmt.Errorf("client cannot be nil")
 	}
 
+	if acker, ok := config.Client.(domain.FetchAcker); ok && acker.AcksOnFetch() && config.StrictMode {
+		return nil, fmt.Errorf("strict mode needs a client that acknowledges after handling; this client acknowledges on fetch")
+	}
+
 	logger := config.Logger
 	if logger == nil {
 		logger = &defaultLogger{}
@@ -63,25 +199,92 @@ func New(config *Config) (*MultiSubject, error) {
 		batchSize = 10
 	}
 
+	retryInterval := config.RetryInterval
+	if retryInterval <= 0 {
+		retryInterval = time.Second
+	}
+
+	maxDeliveries := config.MaxDeliveries
+	if maxDeliveries <= 0 {
+		maxDeliveries = 1
+	}
+
+	discoveryInterval := config.DiscoveryInterval
+	if discoveryInterval <= 0 {
+		discoveryInterval = 30 * time.Second
+	}
+
+	priorities := make(map[string]int, len(config.SubjectPriorities))
+	for subject, priority := range config.SubjectPriorities {
+		priorities[subject] = priority
+	}
+
+	clock := domain.ClockOrSystem(config.Clock)
+	var sampler *domain.LogSampler
+	if config.LogSampleEvery > 1 {
+		sampler = domain.NewLogSampler(config.LogSampleEvery, config.LogSummaryInterval, clock)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
+	recvCtx, stopRecv := context.WithCancel(ctx)
 
 	return &MultiSubject{
 		client:      config.Client,
 		durableName: config.DurableName,
-		batchSize:   batchSize,
 		logger:      logger,
 		handlers:    make(map[string]domain.MessageHandler),
-		ctx:         ctx,
-		cancel:      cancel,
+		startPos:    config.StartPosition,
+		startSeq:    config.StartSequence,
+		subjectSeqs: config.SubjectStartSequences,
+		checkpoints: config.Checkpoints,
+		resumed:     make(map[string]uint64),
+		strict:      config.StrictMode,
+		retryEvery:  retryInterval,
+		held:        make(map[string][]*domain.ReceivedMessage),
+		maxDeliver:  maxDeliveries,
+		failureSink: config.FailureSink,
+		dedupWindow: config.DedupWindow,
+		windows:     make(map[string]*sequenceWindow),
+		fanOut:      config.FanOutHandlers,
+		active:      make(map[string]bool),
+		limiter:     newPriorityLimiter(config.MaxConcurrentHandlers),
+		inFlight:    newInFlightLimiter(config.MaxInFlight),
+		settings: Settings{
+			BatchSize:             batchSize,
+			MaxConcurrentHandlers: config.MaxConcurrentHandlers,
+			MaxInFlight:           config.MaxInFlight,
+			RedeliveryDelay:       config.RedeliveryDelay,
+			LogLevel:              config.LogLevel,
+		},
+		priorities:    priorities,
+		discoverEvery: discoveryInterval,
+		resubscribeIn: config.ResubscribeDelay,
+		hooks: lifecycleHooks{
+			OnSubjectStarted: config.OnSubjectStarted,
+			OnSubjectStopped: config.OnSubjectStopped,
+			OnResubscribed:   config.OnResubscribed,
+		},
+		sampler:      sampler,
+		formatter:    config.LogFormatter,
+		overflow:     config.OverflowPolicy,
+		decompressor: config.Decompressor,
+		fetcher:      config.PayloadFetcher,
+		clock:        clock,
+		ctx:          ctx,
+		cancel:       cancel,
+		recvCtx:      recvCtx,
+		stopRecv:     stopRecv,
 	}, nil
 }
 
-// RegisterHandler registers a message handler for a subject
+// RegisterHandler registers a message handler for a subject.
+// Registering several handlers for a subject delivers every message to each of them.
+// After Start, a new subject is subscribed right away and a handler for a
+// subject that is already consumed receives its next messages.
 func (s *MultiSubject) RegisterHandler(subject string, handler domain.MessageHandler) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.handlers[subject] = handler
-	s.logger.Printf("✓ Registered handler for subject: %s", subject)
+	s.registerLocked(subject, handler)
 }
 
 // RegisterHandlers registers multiple handlers at once
@@ -89,149 +292,574 @@ func (s *MultiSubject) RegisterHandlers(handlers map[string]domain.MessageHandle
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for subject, handler := range handlers {
-		s.handlers[subject] = handler
-		s.logger.Printf("✓ Registered handler for subject: %s", subject)
+		s.registerLocked(subject, handler)
+	}
+}
+
+// registerLocked adds a handler and, once started, subscribes to a new
+// subject. The caller holds s.mu.
+func (s *MultiSubject) registerLocked(subject string, handler domain.MessageHandler) {
+	s.addHandler(subject, handler)
+	s.logf(domain.LogLevelInfo, "✓ Registered handler for subject: %s", subject)
+
+	if !s.started || s.active[subject] {
+		return
+	}
+	if s.recvCtx.Err() != nil {
+		s.logf(domain.LogLevelError, "[%s] ✗ Subscriber is stopped, the handler will not receive messages", subject)
+		return
 	}
+	s.startSubject(subject, nil)
+}
+
+// RegisterHandlerFunc registers a function as the message handler for a subject
+func (s *MultiSubject) RegisterHandlerFunc(subject string, fn func(ctx context.Context, msg *domain.ReceivedMessage) error) {
+	s.RegisterHandler(subject, domain.MessageHandlerFunc(fn))
 }
 
-// Start starts all subscriptions
-func (s *MultiSubject) Start() error {
+// SetErrorHandler sets a callback invoked when a handler fails for good or a
+// subscription stream breaks; msg is nil for stream errors
+func (s *MultiSubject) SetErrorHandler(handler func(subject string, msg *domain.ReceivedMessage, err error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onError = handler
+}
+
+// reportError passes an error to the error handler, if one is set
+func (s *MultiSubject) reportError(subject string, msg *domain.ReceivedMessage, err error) {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
+	onError := s.onError
+	s.mu.RUnlock()
+
+	if onError != nil {
+		onError(subject, msg, err)
+	}
+}
+
+// Start starts all subscriptions. Cancelling ctx stops them as Stop does,
+// but leaves closing the client to Stop.
+func (s *MultiSubject) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("subscriber already started")
+	}
 
-	if len(s.handlers) == 0 {
+	if len(s.handlers) == 0 && len(s.patterns) == 0 {
 		return fmt.Errorf("no handlers registered")
 	}
 
-	s.logger.Printf("Starting subscriptions for %d subjects...", len(s.handlers))
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to start subscriber: %w", err)
+	}
+	s.stopOnDone = context.AfterFunc(ctx, s.cancel)
+
+	if err := s.startDiscovery(); err != nil {
+		return err
+	}
+
+	s.logf(domain.LogLevelInfo, "Starting subscriptions for %d subjects...", len(s.handlers))
 
 	// Start a goroutine for each subject
-	for subject, handler := range s.handlers {
-		s.wg.Add(1)
-		go s.subscribeToSubject(subject, handler)
+	for subject := range s.handlers {
+		s.startSubject(subject, nil)
 	}
+	s.started = true
 
 	return nil
 }
 
-// subscribeToSubject handles subscription for a single subject
+// startSubject starts consuming a subject; fallback handles it while it has
+// no explicit handler. The caller holds s.mu.
+func (s *MultiSubject) startSubject(subject string, fallback domain.MessageHandler) {
+	s.active[subject] = true
+	s.streams.add(subject, s.clock.Now())
+	s.wg.Add(1)
+	go s.subscribeToSubject(subject, s.subjectHandler(subject, fallback))
+}
+
+// subscribeToSubject handles subscription for a single subject, resubscribing
+// after the stream ends when a resubscribe delay is configured
 func (s *MultiSubject) subscribeToSubject(subject string, handler domain.MessageHandler) {
 	defer s.wg.Done()
 
-	s.logger.Printf("[%s] Starting subscription...", subject)
+	var err error
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			s.logf(domain.LogLevelInfo, "[%s] Resubscribing in %v (attempt %d)", subject, s.resubscribeIn, attempt)
+			select {
+			case <-s.recvCtx.Done():
+			case <-s.clock.After(s.resubscribeIn):
+			}
+			if s.recvCtx.Err() != nil {
+				err = nil
+				break
+			}
+		}
 
-	config := &domain.SubscriptionConfig{
-		Subject:     subject,
-		DurableName: s.durableName,
-		BatchSize:   s.batchSize,
+		err = s.runSubscription(subject, handler, attempt)
+		if s.recvCtx.Err() != nil {
+			err = nil
+			break
+		}
+		if s.resubscribeIn <= 0 {
+			break
+		}
+	}
+
+	if s.hooks.OnSubjectStopped != nil {
+		s.hooks.OnSubjectStopped(subject, err)
 	}
+}
+
+// runSubscription subscribes once and processes notifications until the stream
+// ends or the subscriber stops. It returns the error that ended the stream.
+func (s *MultiSubject) runSubscription(subject string, handler domain.MessageHandler, attempt int) error {
+	config := s.subscriptionConfig(subject)
+	if attempt > 0 {
+		// The durable consumer already holds the position
+		config = &domain.SubscriptionConfig{
+			Subject:     subject,
+			DurableName: s.durableName,
+			BatchSize:   s.Settings().BatchSize,
+		}
+	}
+	s.logf(domain.LogLevelInfo, "[%s] Starting subscription (start=%s)...", subject, config.StartPosition)
 
-	// Subscribe to notifications
-	notificationStream, err := s.client.Subscribe(s.ctx, config)
+	// Subscribe to notifications; draining cancels only the subscription
+	notificationStream, err := s.client.Subscribe(s.recvCtx, config)
 	if err != nil {
-		s.logger.Printf("[%s] Failed to subscribe: %v", subject, err)
-		return
+		s.logf(domain.LogLevelError, "[%s] Failed to subscribe: %v", subject, err)
+		err = fmt.Errorf("failed to subscribe: %w", err)
+		s.reportError(subject, nil, err)
+		return err
+	}
+	s.streams.opened(subject, s.clock.Now())
+	defer func() { s.streams.closed(subject, s.clock.Now()) }()
+
+	if attempt == 0 && s.hooks.OnSubjectStarted != nil {
+		s.hooks.OnSubjectStarted(subject)
+	}
+	if attempt > 0 && s.hooks.OnResubscribed != nil {
+		s.hooks.OnResubscribed(subject, attempt)
 	}
 
-	// Create notification channel
-	notificationChan := make(chan *domain.Notification, 100)
+	// Create the notification queue; the overflow policy decides what happens
+	// when it is full
+	queue := newNotificationQueue(s.overflow, &s.counters)
+	defer queue.close()
+	var streamErr error
 
 	// Start notification receiver goroutine
 	go func() {
-		defer close(notificationChan)
+		defer close(queue.ch)
 		for {
 			notification, err := notificationStream.Recv()
 			if err == io.EOF {
-				s.logger.Printf("[%s] Subscribe stream closed", subject)
+				s.logf(domain.LogLevelInfo, "[%s] Subscribe stream closed", subject)
 				return
 			}
 			if err != nil {
 				select {
-				case <-s.ctx.Done():
+				case <-s.recvCtx.Done():
 					return
 				default:
-					s.logger.Printf("[%s] Subscribe error: %v", subject, err)
+					s.logf(domain.LogLevelError, "[%s] Subscribe error: %v", subject, err)
+					streamErr = fmt.Errorf("subscribe stream error: %w", err)
+					s.reportError(subject, nil, streamErr)
 					return
 				}
 			}
-			s.logger.Printf("[%s] 📬 Notification received: sequence=%d", subject, notification.Sequence)
-			select {
-			case notificationChan <- notification:
-			case <-s.ctx.Done():
+			s.logf(domain.LogLevelDebug, "[%s] 📬 Notification received: sequence=%d", subject, notification.Sequence)
+			if !queue.push(s.ctx, notification) {
 				return
 			}
 		}
 	}()
 
+	// Messages published while the previous stream was down were never notified
+	if attempt > 0 {
+		s.compensate(subject, handler)
+	}
+
 	// Process notifications
-	s.logger.Printf("[%s] Waiting for notifications...", subject)
+	s.logf(domain.LogLevelInfo, "[%s] Waiting for notifications...", subject)
 	for {
 		select {
 		case <-s.ctx.Done():
-			s.logger.Printf("[%s] Context cancelled, stopping subscription", subject)
-			return
+			s.logf(domain.LogLevelInfo, "[%s] Context cancelled, stopping subscription", subject)
+			return nil
 
-		case notification, ok := <-notificationChan:
-			if !ok {
-				s.logger.Printf("[%s] Notification channel closed", subject)
-				return
+		case <-s.recvCtx.Done():
+			if s.ctx.Err() == nil {
+				s.drainNotifications(subject, queue.ch, handler)
+				s.catchUp(subject, queue, handler)
 			}
+			return nil
 
-			if err := s.processNotification(subject, notification, handler); err != nil {
-				s.logger.Printf("[%s] Error processing notification: %v", subject, err)
+		case notification, ok := <-queue.ch:
+			if !ok {
+				s.logf(domain.LogLevelInfo, "[%s] Notification channel closed", subject)
+				if s.recvCtx.Err() == nil {
+					s.counters.streamGaps.Add(1)
+				}
+				s.catchUp(subject, queue, handler)
+				return streamErr
 			}
+
+			s.handleNotification(subject, notification, handler)
+			s.catchUp(subject, queue, handler)
+		}
+	}
+}
+
+// catchUp runs whenever the in-memory queue is empty: it processes spilled
+// notifications and fetches to make up for dropped ones
+func (s *MultiSubject) catchUp(subject string, queue *notificationQueue, handler domain.MessageHandler) {
+	for len(queue.ch) == 0 && s.ctx.Err() == nil {
+		notification, ok := queue.popSpilled()
+		if !ok {
+			break
+		}
+		s.handleNotification(subject, notification, handler)
+	}
+	if len(queue.ch) == 0 && queue.dropped.Swap(false) {
+		s.compensate(subject, handler)
+	}
+}
+
+// subscriptionConfig builds the subscription config of a subject, resolving
+// where it starts
+func (s *MultiSubject) subscriptionConfig(subject string) *domain.SubscriptionConfig {
+	return &domain.SubscriptionConfig{
+		Subject:       subject,
+		DurableName:   s.durableName,
+		StartSequence: s.startSequence(subject),
+		StartPosition: s.resumePosition(subject),
+		BatchSize:     s.Settings().BatchSize,
+	}
+}
+
+// handleNotification processes a notification, retrying it in strict mode until
+// it succeeds or the subscriber is stopped
+func (s *MultiSubject) handleNotification(subject string, notification *domain.Notification, handler domain.MessageHandler) {
+	for {
+		err := s.processNotification(subject, notification, handler)
+		if err == nil {
+			return
+		}
+
+		s.logf(domain.LogLevelError, "[%s] Error processing notification: %v", subject, err)
+		if !s.strict {
+			return
+		}
+
+		s.logf(domain.LogLevelInfo, "[%s] Retrying notification sequence=%d in %v", subject, notification.Sequence, s.retryEvery)
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-s.clock.After(s.retryEvery):
+		}
+	}
+}
+
+// compensate fetches until the subject is caught up, covering notifications
+// that were dropped or never sent while the stream was down
+func (s *MultiSubject) compensate(subject string, handler domain.MessageHandler) {
+	s.counters.compensated.Add(1)
+	s.logf(domain.LogLevelInfo, "[%s] Fetching to catch up on dropped or missed notifications", subject)
+
+	notification := &domain.Notification{Subject: subject}
+	for s.ctx.Err() == nil {
+		fetched, err := s.fetchBatch(subject, notification, handler)
+		if err != nil {
+			s.logf(domain.LogLevelError, "[%s] Compensating fetch failed: %v", subject, err)
+			return
+		}
+		if fetched == 0 {
+			return
 		}
 	}
 }
 
 // processNotification fetches and processes messages for a notification
 func (s *MultiSubject) processNotification(subject string, notification *domain.Notification, handler domain.MessageHandler) error {
+	_, err := s.fetchBatch(subject, notification, handler)
+	return err
+}
+
+// fetchBatch fetches one batch for a notification and processes it. It
+// returns the number of messages fetched, including skipped ones.
+// A batch held back by an earlier strict mode failure is redelivered instead
+// of fetching, since the server has already moved past it.
+func (s *MultiSubject) fetchBatch(subject string, notification *domain.Notification, handler domain.MessageHandler) (int, error) {
+	if held := s.takeHeld(subject); held != nil {
+		return s.redeliverHeld(subject, held, handler)
+	}
+
+	reserved, err := s.reserveInFlight(subject)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { s.inFlight.release(int(reserved)) }()
+
 	config := &domain.SubscriptionConfig{
 		Subject:     notification.Subject,
 		DurableName: s.durableName,
-		BatchSize:   s.batchSize,
+		BatchSize:   reserved,
 	}
 
 	// Fetch messages
 	messageStream, err := s.client.Fetch(s.ctx, config)
 	if err != nil {
-		return fmt.Errorf("failed to fetch: %w", err)
+		err = fmt.Errorf("failed to fetch: %w", err)
+		s.reportError(subject, nil, err)
+		return 0, err
 	}
 
-	messageCount := 0
+	fetched, messageCount := 0, 0
 	for {
 		msg, err := messageStream.Recv()
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return fmt.Errorf("fetch error: %w", err)
+			err = fmt.Errorf("fetch error: %w", err)
+			s.reportError(subject, nil, err)
+			return fetched, err
+		}
+
+		fetched++
+		if s.skipMessage(subject, msg) {
+			reserved = s.releaseInFlight(reserved)
+			continue
 		}
 
 		messageCount++
-		s.logger.Printf("[%s] 📨 Message received: sequence=%d, data_size=%d",
-			subject, msg.Sequence, len(msg.Data))
+		s.logf(domain.LogLevelDebug, "[%s] 📨 Message received: sequence=%d, data_size=%d%s",
+			subject, msg.Sequence, len(msg.Data), correlationField(msg))
+
+		err = s.deliver(subject, msg, handler)
+		reserved = s.releaseInFlight(reserved)
+		if err != nil {
+			if s.strict {
+				s.hold(subject, msg, messageStream)
+			}
+			return fetched, err
+		}
+	}
+
+	s.logf(domain.LogLevelDebug, "[%s] Processed %d messages", subject, messageCount)
+	return fetched, nil
+}
 
-		// Handle message
-		if err := handler.Handle(s.ctx, msg); err != nil {
-			s.logger.Printf("[%s] Handler error for sequence %d: %v", subject, msg.Sequence, err)
-			// Continue processing other messages even if one fails
+// hold keeps a message that failed in strict mode, followed by the rest of its
+// batch, for redelivery
+func (s *MultiSubject) hold(subject string, failed *domain.ReceivedMessage, rest domain.MessageStream) {
+	held := []*domain.ReceivedMessage{failed}
+	for {
+		msg, err := rest.Recv()
+		if err != nil {
+			break
+		}
+		if !s.skipMessage(subject, msg) {
+			held = append(held, msg)
+		}
+	}
+
+	s.mu.Lock()
+	s.held[subject] = held
+	s.mu.Unlock()
+}
+
+// takeHeld removes and returns the messages held for a subject
+func (s *MultiSubject) takeHeld(subject string) []*domain.ReceivedMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	held := s.held[subject]
+	delete(s.held, subject)
+	return held
+}
+
+// redeliverHeld delivers held messages in order. Messages from the first
+// failure on are held again.
+func (s *MultiSubject) redeliverHeld(subject string, held []*domain.ReceivedMessage, handler domain.MessageHandler) (int, error) {
+	s.logf(domain.LogLevelDebug, "[%s] Redelivering %d held messages from sequence %d", subject, len(held), held[0].Sequence)
+	for i, msg := range held {
+		if err := s.deliver(subject, msg, handler); err != nil {
+			s.mu.Lock()
+			s.held[subject] = held[i:]
+			s.mu.Unlock()
+			return i, err
 		}
 	}
+	return len(held), nil
+}
+
+// skipMessage reports whether a fetched message must not reach the handler:
+// it is before the start position, already processed, a duplicate or expired
+func (s *MultiSubject) skipMessage(subject string, msg *domain.ReceivedMessage) bool {
+	if s.startPos.Skip(msg) || s.alreadyProcessed(subject, msg.Sequence) {
+		return true
+	}
+
+	if s.isDuplicate(subject, msg.Sequence) {
+		s.counters.duplicates.Add(1)
+		s.logf(domain.LogLevelDebug, "[%s] Skipping duplicate message: sequence=%d", subject, msg.Sequence)
+		return true
+	}
+
+	if msg.Expired(s.clock.Now()) {
+		s.counters.expired.Add(1)
+		s.logf(domain.LogLevelDebug, "[%s] Skipping expired message: sequence=%d", subject, msg.Sequence)
+		s.saveCheckpoint(subject, msg.Sequence)
+		return true
+	}
+
+	return false
+}
+
+// deliver hands a message to the handler, redelivering it up to MaxDeliveries
+// times and passing it to the failure sink when all attempts fail. When the
+// handler returns a domain.BackoffError, the subject pauses for the requested
+// delay and the message is delivered again.
+// A non-nil error aborts the current batch.
+func (s *MultiSubject) deliver(subject string, msg *domain.ReceivedMessage, handler domain.MessageHandler) error {
+	handler = s.fetchingPayloads(s.decompressing(handler))
 
-	s.logger.Printf("[%s] Processed %d messages", subject, messageCount)
+	var err error
+	for {
+		var release func()
+		release, err = s.acquireSlot(subject)
+		if err != nil {
+			return err
+		}
+		err = s.handleWithRedelivery(subject, msg, handler)
+		release()
+
+		delay, ok := domain.BackoffDelay(err)
+		if !ok {
+			break
+		}
+		s.counters.backoffs.Add(1)
+		s.logf(domain.LogLevelInfo, "[%s] Handler asked to back off, pausing subject for %v (sequence %d%s)", subject, delay, msg.Sequence, correlationField(msg))
+		select {
+		case <-s.ctx.Done():
+			return s.ctx.Err()
+		case <-s.clock.After(delay):
+		}
+	}
+
+	if err != nil {
+		s.reportError(subject, msg, err)
+		if s.strict {
+			return fmt.Errorf("handler error for sequence %d: %w", msg.Sequence, err)
+		}
+		s.logf(domain.LogLevelError, "[%s] Handler error for sequence %d%s: %v", subject, msg.Sequence, correlationField(msg), err)
+		// Continue processing other messages even if one fails
+	}
+
+	s.markProcessed(subject, msg.Sequence)
+	s.saveCheckpoint(subject, msg.Sequence)
 	return nil
 }
 
+// decompressing wraps handler to restore compressed payloads with the
+// Decompressor first. Payloads that cannot be restored fail like handler
+// errors, with the message as received.
+func (s *MultiSubject) decompressing(handler domain.MessageHandler) domain.MessageHandler {
+	if s.decompressor == nil {
+		return handler
+	}
+	return domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		decompressed, err := s.decompressor.Decompress(msg)
+		if err != nil {
+			return err
+		}
+		return handler.Handle(ctx, decompressed)
+	})
+}
+
+// fetchingPayloads wraps handler to fetch payloads published by reference
+// with the PayloadFetcher first. Fetch failures are retried like handler
+// errors.
+func (s *MultiSubject) fetchingPayloads(handler domain.MessageHandler) domain.MessageHandler {
+	if s.fetcher == nil {
+		return handler
+	}
+	return domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		fetched, err := s.fetcher.Fetch(ctx, msg)
+		if err != nil {
+			return err
+		}
+		return handler.Handle(ctx, fetched)
+	})
+}
+
+// handleWithRedelivery runs the handler until it succeeds or the delivery
+// attempts are used up, then falls back to the failure sink
+func (s *MultiSubject) handleWithRedelivery(subject string, msg *domain.ReceivedMessage, handler domain.MessageHandler) error {
+	ctx := s.ctx
+	if id := msg.CorrelationID(); id != "" {
+		ctx = domain.WithCorrelationID(ctx, id)
+	}
+
+	var err error
+	for attempt := 1; attempt <= s.maxDeliver; attempt++ {
+		if attempt > 1 {
+			s.counters.redelivered.Add(1)
+			s.logf(domain.LogLevelDebug, "[%s] Redelivering sequence %d%s (attempt %d/%d)", subject, msg.Sequence, correlationField(msg), attempt, s.maxDeliver)
+			select {
+			case <-s.ctx.Done():
+				return s.ctx.Err()
+			case <-s.clock.After(s.Settings().RedeliveryDelay):
+			}
+		}
+
+		err = handler.Handle(domain.WithDeliveryAttempt(ctx, attempt), msg)
+		if err == nil {
+			return nil
+		}
+		if _, ok := domain.BackoffDelay(err); ok {
+			return err
+		}
+	}
+
+	if s.failureSink == nil {
+		return err
+	}
+
+	s.counters.failed.Add(1)
+	s.logf(domain.LogLevelError, "[%s] Sequence %d%s failed after %d attempts, sending to failure sink", subject, msg.Sequence, correlationField(msg), s.maxDeliver)
+	if sinkErr := s.failureSink.Handle(ctx, msg, err); sinkErr != nil {
+		return fmt.Errorf("failure sink error: %w (handler error: %v)", sinkErr, err)
+	}
+	return nil
+}
+
+// correlationField formats the correlation ID of a message for log lines
+func correlationField(msg *domain.ReceivedMessage) string {
+	if id := msg.CorrelationID(); id != "" {
+		return ", correlation-id=" + id
+	}
+	return ""
+}
+
 // Stop gracefully stops all subscriptions
 func (s *MultiSubject) Stop() {
-	s.logger.Printf("Stopping subscriber...")
+	s.logf(domain.LogLevelInfo, "Stopping subscriber...")
 	s.cancel()
+	s.mu.RLock()
+	if s.stopOnDone != nil {
+		s.stopOnDone()
+	}
+	s.mu.RUnlock()
 	s.wg.Wait()
 	if err := s.client.Close(); err != nil {
-		s.logger.Printf("Error closing client: %v", err)
+		s.logf(domain.LogLevelError, "Error closing client: %v", err)
 	}
-	s.logger.Printf("✓ Subscriber stopped")
+	s.logf(domain.LogLevelInfo, "✓ Subscriber stopped")
 }
 
 // Wait blocks until all subscriptions finish
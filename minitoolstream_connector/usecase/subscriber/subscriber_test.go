@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"io"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/domain"
+	"github.com/moroshma/MiniToolStreamConnector/minitoolstream_connector/localbroker"
 )
 
 type mockEgressClient struct {
@@ -45,6 +48,13 @@ func (m *mockEgressClient) Close() error {
 	return nil
 }
 
+// fetchAckingClient is a mock client that acknowledges messages on fetch
+type fetchAckingClient struct {
+	mockEgressClient
+}
+
+func (c *fetchAckingClient) AcksOnFetch() bool { return true }
+
 type mockNotificationStream struct {
 	notifications []*domain.Notification
 	index         int
@@ -108,8 +118,8 @@ func TestNew(t *testing.T) {
 		if sub.durableName != "test-consumer" {
 			t.Errorf("expected durable name 'test-consumer', got %s", sub.durableName)
 		}
-		if sub.batchSize != 10 {
-			t.Errorf("expected batch size 10, got %d", sub.batchSize)
+		if sub.Settings().BatchSize != 10 {
+			t.Errorf("expected batch size 10, got %d", sub.Settings().BatchSize)
 		}
 	})
 
@@ -137,6 +147,16 @@ func TestNew(t *testing.T) {
 		}
 	})
 
+	t.Run("strict mode with a client acking on fetch", func(t *testing.T) {
+		client := &fetchAckingClient{}
+		if _, err := New(&Config{Client: client, StrictMode: true}); err == nil {
+			t.Errorf("expected strict mode to be refused, got %v", err)
+		}
+		if _, err := New(&Config{Client: client}); err != nil {
+			t.Errorf("expected no error without strict mode, got %v", err)
+		}
+	})
+
 	t.Run("with custom logger", func(t *testing.T) {
 		logger := &testLogger{}
 		client := &mockEgressClient{}
@@ -165,8 +185,8 @@ func TestNew(t *testing.T) {
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if sub.batchSize != 10 {
-			t.Errorf("expected default batch size 10, got %d", sub.batchSize)
+		if sub.Settings().BatchSize != 10 {
+			t.Errorf("expected default batch size 10, got %d", sub.Settings().BatchSize)
 		}
 	})
 
@@ -181,8 +201,8 @@ func TestNew(t *testing.T) {
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
-		if sub.batchSize != 10 {
-			t.Errorf("expected default batch size 10, got %d", sub.batchSize)
+		if sub.Settings().BatchSize != 10 {
+			t.Errorf("expected default batch size 10, got %d", sub.Settings().BatchSize)
 		}
 	})
 }
@@ -265,7 +285,7 @@ func TestMultiSubject_Start(t *testing.T) {
 
 		sub, _ := New(config)
 
-		err := sub.Start()
+		err := sub.Start(context.Background())
 		if err == nil {
 			t.Fatal("expected error when no handlers registered")
 		}
@@ -305,7 +325,7 @@ func TestMultiSubject_Start(t *testing.T) {
 
 		sub.RegisterHandler("test.subject", handler)
 
-		err := sub.Start()
+		err := sub.Start(context.Background())
 		if err != nil {
 			t.Fatalf("expected no error, got %v", err)
 		}
@@ -344,7 +364,7 @@ func TestMultiSubject_Stop(t *testing.T) {
 		})
 
 		sub.RegisterHandler("test.subject", handler)
-		sub.Start()
+		sub.Start(context.Background())
 
 		time.Sleep(50 * time.Millisecond)
 		sub.Stop()
@@ -471,7 +491,7 @@ func TestMultiSubject_Wait(t *testing.T) {
 		})
 
 		sub.RegisterHandler("test.subject", handler)
-		sub.Start()
+		sub.Start(context.Background())
 
 		// Stop in a goroutine
 		go func() {
@@ -483,3 +503,672 @@ func TestMultiSubject_Wait(t *testing.T) {
 		sub.Wait()
 	})
 }
+
+func TestMultiSubject_StartContext(t *testing.T) {
+	handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		return nil
+	})
+
+	t.Run("cancelling the context stops subscriptions", func(t *testing.T) {
+		client := newChannelEgressClient()
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+		sub.RegisterHandler("test.subject", handler)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := sub.Start(ctx); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		client.awaitSubscribe(t, "test.subject")
+
+		cancel()
+		done := make(chan struct{})
+		go func() {
+			sub.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("subscriptions did not stop after the context was cancelled")
+		}
+		sub.Stop()
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: &testLogger{}})
+		sub.RegisterHandler("test.subject", handler)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := sub.Start(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestMultiSubject_StrictMode(t *testing.T) {
+	t.Run("failed batch is redelivered before fetching more", func(t *testing.T) {
+		broker, err := localbroker.New(&localbroker.Config{Dir: t.TempDir(), PollInterval: 5 * time.Millisecond})
+		if err != nil {
+			t.Fatalf("failed to create broker: %v", err)
+		}
+		ctx := context.Background()
+		for i := 1; i <= 4; i++ {
+			if _, err := broker.Publish(ctx, &domain.PublishMessage{Subject: "orders", Data: []byte{byte(i)}}); err != nil {
+				t.Fatalf("Publish failed: %v", err)
+			}
+		}
+
+		sub, _ := New(&Config{
+			Client:        broker,
+			Logger:        discardLogger{},
+			DurableName:   "strict",
+			BatchSize:     2,
+			StrictMode:    true,
+			RetryInterval: time.Millisecond,
+		})
+
+		var mu sync.Mutex
+		var seen []uint64
+		done := make(chan struct{})
+		sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			mu.Lock()
+			defer mu.Unlock()
+			seen = append(seen, msg.Sequence)
+			if len(seen) == 1 {
+				return errors.New("transient failure")
+			}
+			if msg.Sequence == 4 {
+				close(done)
+			}
+			return nil
+		})
+		if err := sub.Start(ctx); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		defer sub.Stop()
+
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("messages were not all handled")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if want := []uint64{1, 1, 2, 3, 4}; !slices.Equal(seen, want) {
+			t.Errorf("expected %v, got %v", want, seen)
+		}
+	})
+
+	t.Run("notification is retried until success", func(t *testing.T) {
+		fetches := 0
+		client := &mockEgressClient{
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				fetches++
+				return &mockMessageStream{
+					messages: []*domain.ReceivedMessage{{Subject: "test.subject", Sequence: 1}},
+				}, nil
+			},
+		}
+		sub, _ := New(&Config{
+			Client:        client,
+			Logger:        &testLogger{},
+			StrictMode:    true,
+			RetryInterval: time.Millisecond,
+		})
+
+		calls := 0
+		handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			calls++
+			if calls < 3 {
+				return errors.New("temporary failure")
+			}
+			return nil
+		})
+
+		sub.handleNotification("test.subject", &domain.Notification{Subject: "test.subject", Sequence: 1}, handler)
+
+		if calls != 3 {
+			t.Errorf("expected 3 deliveries, got %d", calls)
+		}
+		if fetches != 1 {
+			t.Errorf("expected the failed message to be redelivered without fetching again, got %d fetches", fetches)
+		}
+	})
+
+	t.Run("retry stops when subscriber is stopped", func(t *testing.T) {
+		client := &mockEgressClient{
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return nil, errors.New("fetch error")
+			},
+		}
+		sub, _ := New(&Config{
+			Client:        client,
+			Logger:        &testLogger{},
+			StrictMode:    true,
+			RetryInterval: time.Hour,
+		})
+		sub.cancel()
+
+		done := make(chan struct{})
+		go func() {
+			sub.handleNotification("test.subject", &domain.Notification{Subject: "test.subject"}, domain.MessageHandlerFunc(
+				func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil }))
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("expected retry loop to stop after cancellation")
+		}
+	})
+}
+
+func TestMultiSubject_StartPosition(t *testing.T) {
+	t.Run("start position is passed to subscribe", func(t *testing.T) {
+		configs := make(chan *domain.SubscriptionConfig, 1)
+		client := &mockEgressClient{
+			subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+				configs <- config
+				return &mockNotificationStream{}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}, StartPosition: domain.FromSequence(9)})
+		sub.RegisterHandler("test.subject", domain.MessageHandlerFunc(
+			func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil }))
+
+		if err := sub.Start(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		config := <-configs
+		sub.Stop()
+
+		if config.StartPosition != domain.FromSequence(9) {
+			t.Errorf("expected start position sequence=9, got %s", config.StartPosition)
+		}
+	})
+
+	t.Run("time position skips older messages", func(t *testing.T) {
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		client := &mockEgressClient{
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return &mockMessageStream{
+					messages: []*domain.ReceivedMessage{
+						{Subject: "test.subject", Sequence: 1, Timestamp: start.Add(-time.Hour)},
+						{Subject: "test.subject", Sequence: 2, Timestamp: start.Add(time.Hour)},
+					},
+				}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}, StartPosition: domain.FromTime(start)})
+
+		var handled []uint64
+		handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			handled = append(handled, msg.Sequence)
+			return nil
+		})
+
+		if err := sub.processNotification("test.subject", &domain.Notification{Subject: "test.subject"}, handler); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(handled) != 1 || handled[0] != 2 {
+			t.Errorf("expected only sequence 2 to be handled, got %v", handled)
+		}
+	})
+}
+
+func TestMultiSubject_Redelivery(t *testing.T) {
+	newSub := func(config *Config) *MultiSubject {
+		config.Client = &mockEgressClient{
+			fetchFunc: func(ctx context.Context, c *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return &mockMessageStream{
+					messages: []*domain.ReceivedMessage{{Subject: "jobs", Sequence: 1}},
+				}, nil
+			},
+		}
+		config.Logger = &testLogger{}
+		sub, _ := New(config)
+		return sub
+	}
+
+	t.Run("attempt is surfaced to handler", func(t *testing.T) {
+		sub := newSub(&Config{MaxDeliveries: 3})
+
+		var attempts []int
+		handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			attempts = append(attempts, domain.DeliveryAttempt(ctx))
+			if len(attempts) < 3 {
+				return errors.New("temporary failure")
+			}
+			return nil
+		})
+
+		if err := sub.processNotification("jobs", &domain.Notification{Subject: "jobs"}, handler); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(attempts) != 3 || attempts[0] != 1 || attempts[2] != 3 {
+			t.Errorf("expected attempts [1 2 3], got %v", attempts)
+		}
+		if stats := sub.Stats(); stats.Redelivered != 2 {
+			t.Errorf("expected 2 redeliveries, got %d", stats.Redelivered)
+		}
+	})
+
+	t.Run("exhausted message goes to failure sink", func(t *testing.T) {
+		var sunk *domain.ReceivedMessage
+		sink := domain.FailureSinkFunc(func(ctx context.Context, msg *domain.ReceivedMessage, cause error) error {
+			sunk = msg
+			return nil
+		})
+		sub := newSub(&Config{MaxDeliveries: 2, FailureSink: sink, StrictMode: true})
+
+		handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			return errors.New("permanent failure")
+		})
+
+		if err := sub.processNotification("jobs", &domain.Notification{Subject: "jobs"}, handler); err != nil {
+			t.Fatalf("expected sink to absorb the failure, got %v", err)
+		}
+		if sunk == nil || sunk.Sequence != 1 {
+			t.Error("expected message to be sent to the failure sink")
+		}
+		if stats := sub.Stats(); stats.Failed != 1 {
+			t.Errorf("expected 1 failed message, got %d", stats.Failed)
+		}
+	})
+
+	t.Run("failure sink error propagates in strict mode", func(t *testing.T) {
+		sink := domain.FailureSinkFunc(func(ctx context.Context, msg *domain.ReceivedMessage, cause error) error {
+			return errors.New("sink unavailable")
+		})
+		sub := newSub(&Config{FailureSink: sink, StrictMode: true})
+
+		handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			return errors.New("permanent failure")
+		})
+
+		if err := sub.processNotification("jobs", &domain.Notification{Subject: "jobs"}, handler); err == nil {
+			t.Fatal("expected error when failure sink fails")
+		}
+	})
+}
+
+// instantClock fires timers immediately and records the requested delays
+type instantClock struct {
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (c *instantClock) Now() time.Time { return time.Now() }
+
+func (c *instantClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.delays = append(c.delays, d)
+	c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	ch <- time.Now()
+	return ch
+}
+
+func TestMultiSubject_Backoff(t *testing.T) {
+	clock := &instantClock{}
+	var sunk int
+	sub, _ := New(&Config{
+		Client: &mockEgressClient{
+			fetchFunc: func(ctx context.Context, c *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return &mockMessageStream{
+					messages: []*domain.ReceivedMessage{{Subject: "jobs", Sequence: 1}, {Subject: "jobs", Sequence: 2}},
+				}, nil
+			},
+		},
+		Logger:        &testLogger{},
+		MaxDeliveries: 2,
+		FailureSink: domain.FailureSinkFunc(func(ctx context.Context, msg *domain.ReceivedMessage, cause error) error {
+			sunk++
+			return nil
+		}),
+		Clock: clock,
+	})
+
+	var calls []uint64
+	handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		calls = append(calls, msg.Sequence)
+		if len(calls) <= 3 {
+			return domain.Backoff(time.Duration(len(calls)) * time.Second)
+		}
+		return nil
+	})
+
+	if err := sub.processNotification("jobs", &domain.Notification{Subject: "jobs"}, handler); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(calls) != 5 || calls[3] != 1 || calls[4] != 2 {
+		t.Errorf("expected sequence 1 to be held back until the handler stopped backing off, got %v", calls)
+	}
+	if len(clock.delays) != 3 || clock.delays[0] != time.Second || clock.delays[2] != 3*time.Second {
+		t.Errorf("expected pauses of 1s, 2s and 3s, got %v", clock.delays)
+	}
+	stats := sub.Stats()
+	if stats.Backoffs != 3 || stats.Redelivered != 0 || sunk != 0 {
+		t.Errorf("expected backoffs not to count as failed deliveries, got %+v (sunk %d)", stats, sunk)
+	}
+}
+
+func TestMultiSubject_CorrelationID(t *testing.T) {
+	sub, _ := New(&Config{
+		Client: &mockEgressClient{
+			fetchFunc: func(ctx context.Context, c *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return &mockMessageStream{
+					messages: []*domain.ReceivedMessage{
+						{Subject: "jobs", Sequence: 1, Headers: map[string]string{domain.HeaderCorrelationID: "req-1"}},
+						{Subject: "jobs", Sequence: 2},
+					},
+				}, nil
+			},
+		},
+		Logger: &testLogger{},
+	})
+
+	var ids []string
+	handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+		ids = append(ids, domain.CorrelationID(ctx))
+		return nil
+	})
+
+	if err := sub.processNotification("jobs", &domain.Notification{Subject: "jobs"}, handler); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "req-1" || ids[1] != "" {
+		t.Errorf("expected correlation IDs [req-1 \"\"], got %q", ids)
+	}
+}
+
+func TestMultiSubject_RegisterHandlerFunc(t *testing.T) {
+	t.Run("register function", func(t *testing.T) {
+		sub, _ := New(&Config{Client: &mockEgressClient{}, Logger: &testLogger{}})
+
+		called := false
+		sub.RegisterHandlerFunc("test.subject", func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			called = true
+			return nil
+		})
+
+		handler, ok := sub.handlers["test.subject"]
+		if !ok {
+			t.Fatal("expected handler to be registered")
+		}
+		handler.Handle(context.Background(), &domain.ReceivedMessage{})
+		if !called {
+			t.Error("expected registered function to be called")
+		}
+	})
+}
+
+// discardLogger drops log output; it is safe for concurrent use
+type discardLogger struct{}
+
+func (discardLogger) Printf(format string, v ...interface{}) {}
+
+func TestMultiSubject_SetErrorHandler(t *testing.T) {
+	type reported struct {
+		subject string
+		msg     *domain.ReceivedMessage
+		err     error
+	}
+
+	t.Run("handler failure", func(t *testing.T) {
+		client := &mockEgressClient{
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return &mockMessageStream{
+					messages: []*domain.ReceivedMessage{
+						{Subject: "test.subject", Sequence: 1},
+						{Subject: "test.subject", Sequence: 2},
+					},
+				}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+
+		var errs []reported
+		sub.SetErrorHandler(func(subject string, msg *domain.ReceivedMessage, err error) {
+			errs = append(errs, reported{subject, msg, err})
+		})
+
+		handler := domain.MessageHandlerFunc(func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			if msg.Sequence == 2 {
+				return errors.New("bad message")
+			}
+			return nil
+		})
+
+		if err := sub.processNotification("test.subject", &domain.Notification{Subject: "test.subject"}, handler); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(errs) != 1 {
+			t.Fatalf("expected 1 reported error, got %d", len(errs))
+		}
+		if errs[0].subject != "test.subject" || errs[0].msg.Sequence != 2 {
+			t.Errorf("unexpected report: %+v", errs[0])
+		}
+	})
+
+	t.Run("fetch failure", func(t *testing.T) {
+		client := &mockEgressClient{
+			fetchFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+				return nil, errors.New("unavailable")
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: &testLogger{}})
+
+		var errs []reported
+		sub.SetErrorHandler(func(subject string, msg *domain.ReceivedMessage, err error) {
+			errs = append(errs, reported{subject, msg, err})
+		})
+
+		sub.processNotification("test.subject", &domain.Notification{Subject: "test.subject"}, domain.MessageHandlerFunc(
+			func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil }))
+
+		if len(errs) != 1 || errs[0].msg != nil {
+			t.Errorf("expected one stream error without message, got %+v", errs)
+		}
+	})
+
+	t.Run("broken subscribe stream", func(t *testing.T) {
+		client := &mockEgressClient{
+			subscribeFunc: func(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+				return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+					return nil, errors.New("connection reset")
+				}}, nil
+			},
+		}
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+
+		reports := make(chan reported, 1)
+		sub.SetErrorHandler(func(subject string, msg *domain.ReceivedMessage, err error) {
+			reports <- reported{subject, msg, err}
+		})
+		sub.RegisterHandlerFunc("test.subject", func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil })
+		sub.Start(context.Background())
+		defer sub.Stop()
+
+		select {
+		case r := <-reports:
+			if r.subject != "test.subject" || r.err == nil {
+				t.Errorf("unexpected report: %+v", r)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected stream error to be reported")
+		}
+	})
+}
+
+// channelEgressClient notifies a subject whenever a message is sent on its channel
+type channelEgressClient struct {
+	mu         sync.Mutex
+	subjects   map[string]chan *domain.ReceivedMessage
+	pending    map[string][]*domain.ReceivedMessage
+	subscribed chan string
+}
+
+func newChannelEgressClient() *channelEgressClient {
+	return &channelEgressClient{
+		subjects:   make(map[string]chan *domain.ReceivedMessage),
+		pending:    make(map[string][]*domain.ReceivedMessage),
+		subscribed: make(chan string, 10),
+	}
+}
+
+func (c *channelEgressClient) channel(subject string) chan *domain.ReceivedMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subjects[subject] == nil {
+		c.subjects[subject] = make(chan *domain.ReceivedMessage, 10)
+	}
+	return c.subjects[subject]
+}
+
+func (c *channelEgressClient) Subscribe(ctx context.Context, config *domain.SubscriptionConfig) (domain.NotificationStream, error) {
+	ch := c.channel(config.Subject)
+	c.subscribed <- config.Subject
+	return &mockNotificationStream{recvFunc: func() (*domain.Notification, error) {
+		select {
+		case msg := <-ch:
+			c.mu.Lock()
+			c.pending[msg.Subject] = append(c.pending[msg.Subject], msg)
+			c.mu.Unlock()
+			return &domain.Notification{Subject: msg.Subject, Sequence: msg.Sequence}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}}, nil
+}
+
+func (c *channelEgressClient) Fetch(ctx context.Context, config *domain.SubscriptionConfig) (domain.MessageStream, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	messages := c.pending[config.Subject]
+	c.pending[config.Subject] = nil
+	return &mockMessageStream{messages: messages}, nil
+}
+
+func (c *channelEgressClient) GetLastSequence(ctx context.Context, subject string) (uint64, error) {
+	return 0, nil
+}
+
+func (c *channelEgressClient) Close() error { return nil }
+
+func (c *channelEgressClient) awaitSubscribe(t *testing.T, subject string) {
+	t.Helper()
+	for {
+		select {
+		case got := <-c.subscribed:
+			if got == subject {
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no subscription for %s", subject)
+		}
+	}
+}
+
+func TestMultiSubject_RegisterAfterStart(t *testing.T) {
+	received := func() (chan string, func(name string) func(ctx context.Context, msg *domain.ReceivedMessage) error) {
+		ch := make(chan string, 10)
+		return ch, func(name string) func(ctx context.Context, msg *domain.ReceivedMessage) error {
+			return func(ctx context.Context, msg *domain.ReceivedMessage) error {
+				ch <- name
+				return nil
+			}
+		}
+	}
+	await := func(t *testing.T, ch chan string, want ...string) {
+		t.Helper()
+		got := make(map[string]bool)
+		for range want {
+			select {
+			case name := <-ch:
+				got[name] = true
+			case <-time.After(time.Second):
+				t.Fatalf("expected handlers %v, got %v", want, got)
+			}
+		}
+		for _, name := range want {
+			if !got[name] {
+				t.Errorf("expected handler %s to run, got %v", name, got)
+			}
+		}
+	}
+
+	t.Run("new subject is subscribed", func(t *testing.T) {
+		client := newChannelEgressClient()
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+		ch, handler := received()
+
+		sub.RegisterHandlerFunc("orders", handler("orders"))
+		if err := sub.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		defer sub.Stop()
+		client.awaitSubscribe(t, "orders")
+
+		sub.RegisterHandlerFunc("payments", handler("payments"))
+		client.awaitSubscribe(t, "payments")
+
+		client.channel("payments") <- &domain.ReceivedMessage{Subject: "payments", Sequence: 1}
+		await(t, ch, "payments")
+	})
+
+	t.Run("handler added to a running subject", func(t *testing.T) {
+		client := newChannelEgressClient()
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+		ch, handler := received()
+
+		sub.RegisterHandlerFunc("orders", handler("first"))
+		if err := sub.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		defer sub.Stop()
+		client.awaitSubscribe(t, "orders")
+
+		sub.RegisterHandlers(map[string]domain.MessageHandler{"orders": domain.MessageHandlerFunc(handler("second"))})
+
+		client.channel("orders") <- &domain.ReceivedMessage{Subject: "orders", Sequence: 1}
+		await(t, ch, "first", "second")
+
+		select {
+		case got := <-client.subscribed:
+			t.Errorf("expected no second subscription, got one for %s", got)
+		default:
+		}
+	})
+
+	t.Run("start twice", func(t *testing.T) {
+		sub, _ := New(&Config{Client: newChannelEgressClient(), Logger: discardLogger{}})
+		sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil })
+		if err := sub.Start(context.Background()); err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+		defer sub.Stop()
+		if err := sub.Start(context.Background()); err == nil {
+			t.Error("expected an error when starting twice")
+		}
+	})
+
+	t.Run("after stop", func(t *testing.T) {
+		client := newChannelEgressClient()
+		sub, _ := New(&Config{Client: client, Logger: discardLogger{}})
+		sub.RegisterHandlerFunc("orders", func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil })
+		sub.Start(context.Background())
+		client.awaitSubscribe(t, "orders")
+		sub.Stop()
+
+		sub.RegisterHandlerFunc("payments", func(ctx context.Context, msg *domain.ReceivedMessage) error { return nil })
+		select {
+		case got := <-client.subscribed:
+			t.Errorf("expected no subscription after Stop, got one for %s", got)
+		case <-time.After(20 * time.Millisecond):
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package minitoolstream_connector
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxBatchSize is the largest batch size accepted by the builders
+const maxBatchSize = 10000
+
+// durableNamePattern lists the characters allowed in durable names
+var durableNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// validateAddress checks that a server address is a host:port pair, a
+// supported gRPC target URI (dns:///, passthrough:///, unix:) or an absolute
+// unix socket path
+func validateAddress(addr string) error {
+	if addr == "" {
+		return fmt.Errorf("server address is required")
+	}
+
+	if strings.HasPrefix(addr, "/") {
+		return nil
+	}
+
+	target := addr
+	if scheme, rest, ok := strings.Cut(addr, ":"); ok && isTargetScheme(scheme) {
+		if scheme == "unix" || scheme == "unix-abstract" {
+			if strings.TrimLeft(rest, "/") == "" {
+				return fmt.Errorf("invalid server address %q: socket path is required", addr)
+			}
+			return nil
+		}
+		target = strings.TrimPrefix(rest, "//")
+		if i := strings.Index(target, "/"); i >= 0 {
+			target = target[i+1:]
+		}
+	}
+
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		return fmt.Errorf("invalid server address %q: %w", addr, err)
+	}
+	if host == "" && target != addr {
+		return fmt.Errorf("invalid server address %q: host is required", addr)
+	}
+	n, err := strconv.Atoi(port)
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid server address %q: port must be between 1 and 65535", addr)
+	}
+	return nil
+}
+
+// isTargetScheme reports whether scheme is a gRPC name resolver scheme
+func isTargetScheme(scheme string) bool {
+	switch scheme {
+	case "dns", "passthrough", "unix", "unix-abstract":
+		return true
+	default:
+		return false
+	}
+}
+
+// validateBatchSize checks that a batch size is within the accepted range
+func validateBatchSize(batchSize int32) error {
+	if batchSize < 1 || batchSize > maxBatchSize {
+		return fmt.Errorf("batch size must be between 1 and %d, got %d", maxBatchSize, batchSize)
+	}
+	return nil
+}
+
+// validateDurableName checks that a durable name only uses allowed characters.
+// An empty name is accepted because the builder falls back to a default.
+// JetStream consumer names cannot contain '.', so it is rejected there.
+func validateDurableName(name string, jetStream bool) error {
+	if name == "" {
+		return nil
+	}
+	if jetStream {
+		if !durableNamePattern.MatchString(name) || strings.Contains(name, ".") {
+			return fmt.Errorf("durable name %q may only contain letters, digits, '_' and '-' with JetStream", name)
+		}
+		return nil
+	}
+	if !durableNamePattern.MatchString(name) {
+		return fmt.Errorf("durable name %q may only contain letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// validateLogLevel checks that a log level is one of the defined levels
+func validateLogLevel(level LogLevel) error {
+	if level < LogLevelDebug || level > LogLevelOff {
+		return fmt.Errorf("unknown log level %d", int(level))
+	}
+	return nil
+}
+
+// validateLogSampling checks the log sampling rate and summary interval
+func validateLogSampling(every int, summaryInterval time.Duration) error {
+	if every < 0 || summaryInterval < 0 {
+		return fmt.Errorf("log sampling rate and summary interval cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package minitoolstream_connector
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		addr  string
+		valid bool
+	}{
+		{"localhost:9090", true},
+		{"10.0.0.1:50051", true},
+		{"[::1]:9090", true},
+		{":9090", true},
+		{"dns:///stream.internal:443", true},
+		{"passthrough:///localhost:9090", true},
+		{"unix:///var/run/stream.sock", true},
+		{"unix:stream.sock", true},
+		{"/var/run/stream.sock", true},
+		{"", false},
+		{"localhost", false},
+		{"localhost:http", false},
+		{"localhost:70000", false},
+		{"dns:///:443", false},
+		{"unix://", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			err := validateAddress(tt.addr)
+			if tt.valid && err != nil {
+				t.Errorf("expected %q to be valid, got %v", tt.addr, err)
+			}
+			if !tt.valid && err == nil {
+				t.Errorf("expected %q to be invalid", tt.addr)
+			}
+		})
+	}
+}
+
+func TestValidateBatchSize(t *testing.T) {
+	for _, size := range []int32{1, 10, maxBatchSize} {
+		if err := validateBatchSize(size); err != nil {
+			t.Errorf("expected batch size %d to be valid, got %v", size, err)
+		}
+	}
+	for _, size := range []int32{0, -1, maxBatchSize + 1} {
+		if err := validateBatchSize(size); err == nil {
+			t.Errorf("expected batch size %d to be invalid", size)
+		}
+	}
+}
+
+func TestValidateDurableName(t *testing.T) {
+	for _, name := range []string{"", "consumer", "orders-v2.worker_1"} {
+		if err := validateDurableName(name, false); err != nil {
+			t.Errorf("expected %q to be valid, got %v", name, err)
+		}
+	}
+	for _, name := range []string{"my consumer", "a/b", "name*"} {
+		if err := validateDurableName(name, false); err == nil {
+			t.Errorf("expected %q to be invalid", name)
+		}
+	}
+
+	t.Run("jetstream rejects dots", func(t *testing.T) {
+		if err := validateDurableName("orders-v2_worker", true); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+		for _, name := range []string{"orders-v2.worker_1", "a/b"} {
+			if err := validateDurableName(name, true); err == nil {
+				t.Errorf("expected %q to be invalid with JetStream", name)
+			}
+		}
+	})
+}
+
+func TestPublisherBuilder_Validate(t *testing.T) {
+	t.Run("valid configuration", func(t *testing.T) {
+		if err := NewPublisherBuilder("localhost:9090").Validate(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("aggregates errors", func(t *testing.T) {
+		err := NewPublisherBuilder("localhost").
+			WithRetry(-1, 0).
+			WithPerRPCCredentials(&testPerRPCCredentials{secure: true}).
+			WithRateLimit(-1).
+			WithLogLevel(LogLevel(7)).
+			Validate()
+		if err == nil {
+			t.Fatal("expected validation error")
+		}
+		for _, part := range []string{"invalid server address", "max retries", "transport security", "rate limit", "log level"} {
+			if !strings.Contains(err.Error(), part) {
+				t.Errorf("expected error to mention %q, got %v", part, err)
+			}
+		}
+	})
+
+	t.Run("build fails before connecting", func(t *testing.T) {
+		pub, err := NewPublisherBuilder("not-an-address").Build()
+		if err == nil {
+			t.Fatal("expected build error")
+		}
+		if pub != nil {
+			t.Error("expected nil publisher")
+		}
+	})
+}
+
+func TestSubscriberBuilder_Validate(t *testing.T) {
+	t.Run("valid configuration", func(t *testing.T) {
+		if err := NewSubscriberBuilder("localhost:9091").WithDurableName("orders").Validate(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("aggregates errors", func(t *testing.T) {
+		err := NewSubscriberBuilder("localhost:9091").
+			WithBatchSize(0).
+			WithDurableName("bad name").
+			WithLogLevel(LogLevel(-1)).
+			WithLogSampling(-1, 0).
+			WithOverflowPolicy(OverflowPolicy(9)).
+			Validate()
+		if err == nil {
+			t.Fatal("expected validation error")
+		}
+		for _, part := range []string{"batch size", "durable name", "log level", "log sampling", "overflow policy"} {
+			if !strings.Contains(err.Error(), part) {
+				t.Errorf("expected error to mention %q, got %v", part, err)
+			}
+		}
+	})
+
+	t.Run("jetstream durable name", func(t *testing.T) {
+		b := NewSubscriberBuilder("nats://localhost:4222").WithJetStream().WithDurableName("orders.worker")
+		if err := b.Validate(); err == nil || !strings.Contains(err.Error(), "JetStream") {
+			t.Errorf("expected JetStream durable name error, got %v", err)
+		}
+		if err := b.WithDurableName("orders-worker").Validate(); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+	t.Run("pattern handlers need jetstream", func(t *testing.T) {
+		handler := MessageHandlerFunc(func(ctx context.Context, msg *ReceivedMessage) error { return nil })
+		pattern := regexp.MustCompile(`^orders\.`)
+
+		err := NewSubscriberBuilder("localhost:9091").WithPatternHandler(pattern, handler).Validate()
+		if err == nil || !strings.Contains(err.Error(), "pattern handlers") {
+			t.Errorf("expected pattern handler error, got %v", err)
+		}
+		err = NewSubscriberBuilder("nats://localhost:4222").WithJetStream().WithPatternHandler(pattern, handler).Validate()
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+}